@@ -0,0 +1,102 @@
+// Passkey wraps the browser's WebAuthn API for registering and signing in
+// with passkeys.  Options from the server use base64url strings for binary
+// values, so they are converted to/from ArrayBuffers here.
+window.Passkey = (function() {
+
+	function toBuffer(value) {
+		var base64 = value.replace(/-/g, "+").replace(/_/g, "/");
+		while (base64.length % 4) {
+			base64 += "=";
+		}
+		var binary = atob(base64);
+		var result = new Uint8Array(binary.length);
+		for (var i = 0; i < binary.length; i++) {
+			result[i] = binary.charCodeAt(i);
+		}
+		return result.buffer;
+	}
+
+	function fromBuffer(buffer) {
+		var bytes = new Uint8Array(buffer);
+		var binary = "";
+		for (var i = 0; i < bytes.length; i++) {
+			binary += String.fromCharCode(bytes[i]);
+		}
+		return btoa(binary).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "");
+	}
+
+	function decodeCredentials(list) {
+		return (list || []).map(function(item) {
+			return Object.assign({}, item, {id: toBuffer(item.id)});
+		});
+	}
+
+	async function post(url, body) {
+		var response = await fetch(url, {
+			method: "POST",
+			credentials: "same-origin",
+			headers: {"Content-Type": "application/json"},
+			body: body ? JSON.stringify(body) : null
+		});
+
+		if (!response.ok) {
+			throw new Error("Passkey request failed: " + response.status);
+		}
+
+		return response.json();
+	}
+
+	// register creates a new passkey for the signed-in user
+	async function register(beginURL, finishURL) {
+		var options = (await post(beginURL)).publicKey;
+		options.challenge = toBuffer(options.challenge);
+		options.user.id = toBuffer(options.user.id);
+		options.excludeCredentials = decodeCredentials(options.excludeCredentials);
+
+		var credential = await navigator.credentials.create({publicKey: options});
+
+		var result = await post(finishURL, {
+			id: credential.id,
+			rawId: fromBuffer(credential.rawId),
+			type: credential.type,
+			response: {
+				clientDataJSON: fromBuffer(credential.response.clientDataJSON),
+				attestationObject: fromBuffer(credential.response.attestationObject),
+				transports: credential.response.getTransports ? credential.response.getTransports() : []
+			}
+		});
+
+		window.location = result.next;
+	}
+
+	// signIn uses an existing passkey to sign in
+	async function signIn(beginURL, finishURL) {
+		var options = (await post(beginURL)).publicKey;
+		options.challenge = toBuffer(options.challenge);
+		options.allowCredentials = decodeCredentials(options.allowCredentials);
+
+		var credential = await navigator.credentials.get({publicKey: options});
+
+		var result = await post(finishURL, {
+			id: credential.id,
+			rawId: fromBuffer(credential.rawId),
+			type: credential.type,
+			response: {
+				clientDataJSON: fromBuffer(credential.response.clientDataJSON),
+				authenticatorData: fromBuffer(credential.response.authenticatorData),
+				signature: fromBuffer(credential.response.signature),
+				userHandle: credential.response.userHandle ? fromBuffer(credential.response.userHandle) : null
+			}
+		});
+
+		window.location = result.next;
+	}
+
+	return {
+		isSupported: function() {
+			return !!(window.PublicKeyCredential && navigator.credentials);
+		},
+		register: register,
+		signIn: signIn
+	};
+})();
@@ -0,0 +1,171 @@
+package build
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/benpate/data"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+	builder "github.com/benpate/exp-builder"
+	"github.com/benpate/rosetta/schema"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Blocklist is a builder for the admin/blocklists page
+// It can only be accessed by a Domain Owner
+type Blocklist struct {
+	_blocklist *model.Blocklist
+	CommonWithTemplate
+}
+
+// NewBlocklist returns a fully initialized `Blocklist` builder.
+func NewBlocklist(factory Factory, request *http.Request, response http.ResponseWriter, template model.Template, blocklist *model.Blocklist, actionID string) (Blocklist, error) {
+
+	const location = "build.NewBlocklist"
+
+	// Create the underlying Common builder
+	common, err := NewCommonWithTemplate(factory, request, response, template, actionID)
+
+	if err != nil {
+		return Blocklist{}, derp.Wrap(err, location, "Error creating common builder")
+	}
+
+	// Verify that the blocklist is a Domain Owner
+	if !common._authorization.DomainOwner {
+		return Blocklist{}, derp.NewForbiddenError(location, "Must be domain owner to continue")
+	}
+
+	// Return the Blocklist builder
+	return Blocklist{
+		_blocklist:         blocklist,
+		CommonWithTemplate: common,
+	}, nil
+}
+
+/******************************************
+ * Renderer Interface
+ ******************************************/
+
+// Render generates the string value for this Blocklist
+func (b Blocklist) Render() (template.HTML, error) {
+
+	var buffer bytes.Buffer
+
+	// Execute step (write HTML to buffer, update context)
+	status := Pipeline(b._action.Steps).Get(b.factory(), &b, &buffer)
+
+	if status.Error != nil {
+		err := derp.Wrap(status.Error, "build.Blocklist.Render", "Error generating HTML")
+		derp.Report(err)
+		return "", err
+	}
+
+	// Success!
+	status.Apply(b._response)
+	return template.HTML(buffer.String()), nil
+}
+
+// View executes a separate view for this Blocklist
+func (b Blocklist) View(actionID string) (template.HTML, error) {
+
+	builder, err := NewBlocklist(b._factory, b._request, b._response, b._template, b._blocklist, actionID)
+
+	if err != nil {
+		return template.HTML(""), derp.Wrap(err, "build.Blocklist.View", "Error creating builder")
+	}
+
+	return builder.Render()
+}
+
+func (b Blocklist) NavigationID() string {
+	return "admin"
+}
+
+func (b Blocklist) Token() string {
+	return "blocklists"
+}
+
+func (b Blocklist) PageTitle() string {
+	return "Settings"
+}
+
+func (b Blocklist) Permalink() string {
+	return b.Host() + "/admin/blocklists/" + b.BlocklistID()
+}
+
+func (b Blocklist) BasePath() string {
+	return "/admin/blocklists/" + b.BlocklistID()
+}
+
+func (b Blocklist) object() data.Object {
+	return b._blocklist
+}
+
+func (b Blocklist) objectID() primitive.ObjectID {
+	return b._blocklist.BlocklistID
+}
+
+func (b Blocklist) objectType() string {
+	return "Blocklist"
+}
+
+func (b Blocklist) schema() schema.Schema {
+	return schema.New(model.BlocklistSchema())
+}
+
+func (b Blocklist) service() service.ModelService {
+	return b._factory.Blocklist()
+}
+
+func (b Blocklist) clone(action string) (Builder, error) {
+	return NewBlocklist(b._factory, b._request, b._response, b._template, b._blocklist, action)
+}
+
+/******************************************
+ * Blocklist Data
+ ******************************************/
+
+func (b Blocklist) BlocklistID() string {
+	if b._blocklist == nil {
+		return ""
+	}
+	return b._blocklist.BlocklistID.Hex()
+}
+
+func (b Blocklist) Blocklist() model.Blocklist {
+	return *b._blocklist
+}
+
+/******************************************
+ * Query Builders
+ ******************************************/
+
+func (b Blocklist) Blocklists() *QueryBuilder[model.Blocklist] {
+
+	query := builder.NewBuilder().
+		String("search", builder.WithAlias("label"), builder.WithDefaultOpContains()).
+		String("label").
+		String("status")
+
+	criteria := exp.And(
+		query.Evaluate(b._request.URL.Query()),
+		exp.Equal("deleteDate", 0),
+	)
+
+	result := NewQueryBuilder[model.Blocklist](b._factory.Blocklist(), criteria)
+
+	return &result
+}
+
+/******************************************
+ * Debugging Methods
+ ******************************************/
+
+func (b Blocklist) debug() {
+	log.Debug().Interface("object", b.object()).Msg("builder_admin_blocklists")
+}
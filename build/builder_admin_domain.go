@@ -11,12 +11,14 @@ import (
 	"github.com/EmissarySocial/emissary/service/providers"
 	"github.com/EmissarySocial/emissary/tools/dataset"
 	"github.com/benpate/data"
+	"github.com/benpate/data/option"
 	"github.com/benpate/derp"
 	"github.com/benpate/form"
 	"github.com/benpate/rosetta/list"
 	"github.com/benpate/rosetta/mapof"
 	"github.com/benpate/rosetta/schema"
 	"github.com/benpate/rosetta/slice"
+	"github.com/benpate/rosetta/sliceof"
 	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -156,6 +158,105 @@ func (w Domain) ThemeID() string {
 	return w._domain.ThemeID
 }
 
+// SearchRelays returns the inbox URLs of the ActivityPub relays that this domain subscribes to
+func (w Domain) SearchRelays() sliceof.String {
+	return w._domain.SearchRelays
+}
+
+// HasOIDCClientSecret returns TRUE if a client secret has been saved for the OpenID Connect provider
+func (w Domain) HasOIDCClientSecret() bool {
+	return w._domain.OIDCClientSecret != ""
+}
+
+// SecretNames returns the names (but not the values) of all secrets stored in this domain
+func (w Domain) SecretNames() sliceof.String {
+	return w._domain.SecretNames()
+}
+
+// StorageUsed returns the number of bytes used by all attachments on this domain
+func (w Domain) StorageUsed() int64 {
+	return w._domain.StorageUsed
+}
+
+// StorageQuota returns the maximum number of bytes that attachments on this domain can use.
+// Zero means unlimited.
+func (w Domain) StorageQuota() int64 {
+	return w.factory().Domain().StorageQuota()
+}
+
+// StorageUsers returns the people who are using the most storage on this domain
+func (w Domain) StorageUsers() []model.User {
+
+	result, err := w.factory().User().QueryByStorageUsed(50)
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, "build.Domain.StorageUsers", "Error loading users"))
+	}
+
+	return result
+}
+
+// auditLogPageSize is the number of records displayed on each page of the audit log
+const auditLogPageSize = 100
+
+// AuditLogs returns the most recent audit log records that match the current query parameters
+func (w Domain) AuditLogs() []model.AuditLog {
+
+	auditLogService := w.factory().AuditLog()
+	filter := auditLogService.ParseFilter(w.request().URL.Query())
+	result, err := auditLogService.QueryByFilter(filter, option.MaxRows(auditLogPageSize))
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, "build.Domain.AuditLogs", "Error loading audit logs", filter))
+	}
+
+	return result
+}
+
+// AuditActions returns all of the actions that can be used to filter the audit log
+func (w Domain) AuditActions() []string {
+	return model.AuditActions()
+}
+
+// AuditLogURL returns a URL for the audit log (or its export) using the current
+// filter, without the paging cursor.  Additional query parameters may be appended.
+func (w Domain) AuditLogURL(path string) string {
+
+	query := w.request().URL.Query()
+	query.Del("before")
+	query.Del("format")
+
+	if encoded := query.Encode(); encoded != "" {
+		return path + "?" + encoded + "&"
+	}
+
+	return path + "?"
+}
+
+// AuditLogNextPage returns the paging cursor for the page after the provided records,
+// or zero if there are no more records.
+func (w Domain) AuditLogNextPage(auditLogs []model.AuditLog) int64 {
+
+	if len(auditLogs) < auditLogPageSize {
+		return 0
+	}
+
+	return auditLogs[len(auditLogs)-1].CreateDate
+}
+
+// UserStorageQuota returns the maximum number of bytes that a User can upload.
+// Zero means the User is only limited by the domain quota.
+func (w Domain) UserStorageQuota(user model.User) int64 {
+
+	result, err := w.factory().Attachment().UserStorageQuota(&user)
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, "build.Domain.UserStorageQuota", "Error calculating storage quota", user.UserID))
+	}
+
+	return result
+}
+
 func (w Domain) Theme(themeID string) model.Theme {
 	themeService := w._factory.Theme()
 	return themeService.GetTheme(themeID)
@@ -0,0 +1,171 @@
+package build
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/benpate/data"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+	builder "github.com/benpate/exp-builder"
+	"github.com/benpate/rosetta/schema"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SavedSearch is a builder for the admin/searches page
+// It can only be accessed by a Domain Owner
+type SavedSearch struct {
+	_savedSearch *model.SavedSearch
+	CommonWithTemplate
+}
+
+// NewSavedSearch returns a fully initialized `SavedSearch` builder.
+func NewSavedSearch(factory Factory, request *http.Request, response http.ResponseWriter, template model.Template, savedSearch *model.SavedSearch, actionID string) (SavedSearch, error) {
+
+	const location = "build.NewSavedSearch"
+
+	// Create the underlying Common builder
+	common, err := NewCommonWithTemplate(factory, request, response, template, actionID)
+
+	if err != nil {
+		return SavedSearch{}, derp.Wrap(err, location, "Error creating common builder")
+	}
+
+	// Verify that the user is a Domain Owner
+	if !common._authorization.DomainOwner {
+		return SavedSearch{}, derp.NewForbiddenError(location, "Must be domain owner to continue")
+	}
+
+	// Return the SavedSearch builder
+	return SavedSearch{
+		_savedSearch:       savedSearch,
+		CommonWithTemplate: common,
+	}, nil
+}
+
+/******************************************
+ * Renderer Interface
+ ******************************************/
+
+// Render generates the string value for this SavedSearch
+func (b SavedSearch) Render() (template.HTML, error) {
+
+	var buffer bytes.Buffer
+
+	// Execute step (write HTML to buffer, update context)
+	status := Pipeline(b._action.Steps).Get(b.factory(), &b, &buffer)
+
+	if status.Error != nil {
+		err := derp.Wrap(status.Error, "build.SavedSearch.Render", "Error generating HTML")
+		derp.Report(err)
+		return "", err
+	}
+
+	// Success!
+	status.Apply(b._response)
+	return template.HTML(buffer.String()), nil
+}
+
+// View executes a separate view for this SavedSearch
+func (b SavedSearch) View(actionID string) (template.HTML, error) {
+
+	builder, err := NewSavedSearch(b._factory, b._request, b._response, b._template, b._savedSearch, actionID)
+
+	if err != nil {
+		return template.HTML(""), derp.Wrap(err, "build.SavedSearch.View", "Error creating builder")
+	}
+
+	return builder.Render()
+}
+
+func (b SavedSearch) NavigationID() string {
+	return "admin"
+}
+
+func (b SavedSearch) Token() string {
+	return "searches"
+}
+
+func (b SavedSearch) PageTitle() string {
+	return "Settings"
+}
+
+func (b SavedSearch) Permalink() string {
+	return b.Host() + "/admin/searches/" + b.SavedSearchID()
+}
+
+func (b SavedSearch) BasePath() string {
+	return "/admin/searches/" + b.SavedSearchID()
+}
+
+func (b SavedSearch) object() data.Object {
+	return b._savedSearch
+}
+
+func (b SavedSearch) objectID() primitive.ObjectID {
+	return b._savedSearch.SavedSearchID
+}
+
+func (b SavedSearch) objectType() string {
+	return "SavedSearch"
+}
+
+func (b SavedSearch) schema() schema.Schema {
+	return schema.New(model.SavedSearchSchema())
+}
+
+func (b SavedSearch) service() service.ModelService {
+	return b._factory.SavedSearch()
+}
+
+func (b SavedSearch) clone(action string) (Builder, error) {
+	return NewSavedSearch(b._factory, b._request, b._response, b._template, b._savedSearch, action)
+}
+
+/******************************************
+ * SavedSearch Data
+ ******************************************/
+
+func (b SavedSearch) SavedSearchID() string {
+	if b._savedSearch == nil {
+		return ""
+	}
+	return b._savedSearch.SavedSearchID.Hex()
+}
+
+func (b SavedSearch) SavedSearch() model.SavedSearch {
+	return *b._savedSearch
+}
+
+/******************************************
+ * Query Builders
+ ******************************************/
+
+func (b SavedSearch) SavedSearches() *QueryBuilder[model.SavedSearch] {
+
+	query := builder.NewBuilder().
+		String("search", builder.WithAlias("name"), builder.WithDefaultOpContains()).
+		String("name").
+		String("text")
+
+	criteria := exp.And(
+		query.Evaluate(b._request.URL.Query()),
+		exp.Equal("deleteDate", 0),
+	)
+
+	result := NewQueryBuilder[model.SavedSearch](b._factory.SavedSearch(), criteria)
+
+	return &result
+}
+
+/******************************************
+ * Debugging Methods
+ ******************************************/
+
+func (b SavedSearch) debug() {
+	log.Debug().Interface("object", b.object()).Msg("builder_admin_savedSearches")
+}
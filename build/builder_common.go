@@ -34,6 +34,7 @@ type Common struct {
 
 	// Cached values, do not populate unless needed
 	domain model.Domain // This is a value because we expect to use it in every request.
+	locale *string      // This is a pointer so that copies of this builder share the negotiated locale.
 }
 
 func NewCommon(factory Factory, request *http.Request, response http.ResponseWriter) Common {
@@ -50,6 +51,7 @@ func NewCommon(factory Factory, request *http.Request, response http.ResponseWri
 		_authorization: authorization,
 		arguments:      make(mapof.String),
 		domain:         model.NewDomain(),
+		locale:         new(string),
 	}
 }
 
@@ -147,6 +149,29 @@ func (w Common) QueryString() template.URL {
 	return template.URL(w._request.URL.RawQuery)
 }
 
+// Locale returns the language that this page should be displayed in.  It is negotiated
+// from the signed-in User's preferred locale, then the request's Accept-Language header.
+func (w Common) Locale() string {
+
+	// Use the cached value if it has already been negotiated
+	if *w.locale != "" {
+		return *w.locale
+	}
+
+	preferences := make([]string, 0, 2)
+
+	if w.IsAuthenticated() {
+		if user, err := w.getUser(); err == nil {
+			preferences = append(preferences, user.Locale)
+		}
+	}
+
+	preferences = append(preferences, w._request.Header.Get("Accept-Language"))
+
+	*w.locale = w._factory.Translation().Negotiate(preferences...)
+	return *w.locale
+}
+
 // IsPartialRequest returns TRUE if this is a partial page request from htmx.
 func (w Common) IsPartialRequest() bool {
 	return w._request.Header.Get("HX-Request") != ""
@@ -510,16 +535,22 @@ func (w Common) Search() SearchBuilder {
 	b := builder.NewBuilder().String("name", builder.WithDefaultOperator(">"))
 	criteria := b.Evaluate(w._request.URL.Query())
 
-	if trimmed := strings.TrimSpace(remainder); trimmed != "" {
-		criteria = criteria.AndEqual("$fullText", trimmed)
-	}
-
 	for _, tag := range tags {
 		criteria = criteria.AndEqual("tagValues", model.ToToken(tag))
 	}
 
 	result := NewSearchBuilder(w._factory.Search(), criteria)
 
+	// Visitors can choose to search only this server ("local") or everything in the index
+	if w.QueryParam("scope") == "local" {
+		result = result.Local()
+	}
+
+	// Free text is ranked by the domain's search engine
+	if trimmed := strings.TrimSpace(remainder); trimmed != "" {
+		result = result.Match(trimmed)
+	}
+
 	return result
 }
 
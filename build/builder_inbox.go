@@ -288,6 +288,32 @@ func (w Inbox) Rules() QueryBuilder[model.Rule] {
 	return result
 }
 
+// PendingMentions returns all Mentions that are waiting for the current User to approve them
+func (w Inbox) PendingMentions() []model.Mention {
+
+	mentionService := w._factory.Mention()
+	result, err := mentionService.QueryPendingByUser(w.AuthenticatedID(), option.SortDesc("createDate"))
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, "build.Inbox.PendingMentions", "Error loading mentions"))
+	}
+
+	return result
+}
+
+// PendingMentionCount returns the number of Mentions that are waiting for the current User to approve them
+func (w Inbox) PendingMentionCount() int64 {
+
+	mentionService := w._factory.Mention()
+	result, err := mentionService.CountPendingByUser(w.AuthenticatedID())
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, "build.Inbox.PendingMentionCount", "Error counting mentions"))
+	}
+
+	return result
+}
+
 func (w Inbox) RuleByToken(token string) model.Rule {
 	ruleService := w._factory.Rule()
 	rule := model.NewRule()
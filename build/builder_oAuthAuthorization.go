@@ -6,7 +6,6 @@ import (
 	"github.com/EmissarySocial/emissary/model"
 	"github.com/EmissarySocial/emissary/service"
 	"github.com/benpate/derp"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // OAuthAuthorization is a lightweight builder that
@@ -29,15 +28,8 @@ func NewOAuthAuthorization(factory Factory, request model.OAuthAuthorizationRequ
 		_request: request,
 	}
 
-	// Convert clientID
-	clientID, err := primitive.ObjectIDFromHex(request.ClientID)
-
-	if err != nil {
-		return OAuthAuthorization{}, derp.Wrap(err, location, "Invalid ClientID")
-	}
-
-	// Try to load the OAuthClient object
-	if err := result._service.LoadByClientID(clientID, &result._client); err != nil {
+	// Try to load the OAuthClient object (IndieAuth clients are identified by URL)
+	if err := result._service.LoadByPublicID(request.ClientID, &result._client); err != nil {
 		return OAuthAuthorization{}, derp.Wrap(err, location, "Error loading OAuth Application")
 	}
 
@@ -51,7 +43,7 @@ func NewOAuthAuthorization(factory Factory, request model.OAuthAuthorizationRequ
 }
 
 func (r OAuthAuthorization) ClientID() string {
-	return r._client.ClientID.Hex()
+	return r._client.PublicID()
 }
 
 func (r OAuthAuthorization) Name() string {
@@ -62,6 +54,30 @@ func (r OAuthAuthorization) Website() string {
 	return r._client.Website
 }
 
+func (r OAuthAuthorization) LogoURL() string {
+	return r._client.LogoURL
+}
+
+func (r OAuthAuthorization) IsIndieAuth() bool {
+	return r._client.IsIndieAuth()
+}
+
+func (r OAuthAuthorization) State() string {
+	return r._request.State
+}
+
+func (r OAuthAuthorization) Me() string {
+	return r._request.Me
+}
+
+func (r OAuthAuthorization) CodeChallenge() string {
+	return r._request.CodeChallenge
+}
+
+func (r OAuthAuthorization) CodeChallengeMethod() string {
+	return r._request.CodeChallengeMethod
+}
+
 func (r OAuthAuthorization) RedirectURI() string {
 	return r._request.RedirectURI
 }
@@ -76,7 +92,11 @@ func (r OAuthAuthorization) Scope() string {
 
 func (r OAuthAuthorization) Scopes() []string {
 
+	// IndieAuth clients can request a profile-only authorization
 	if r._request.Scope == "" {
+		if r._client.IsIndieAuth() {
+			return []string{"profile"}
+		}
 		return []string{"read"}
 	}
 
@@ -595,10 +595,10 @@ func (w Stream) getFirstStream(criteria exp.Expression, sortOption option.Option
 	return Stream{}
 }
 
-// Mentions returns a slice of all Mentions for this Stream
+// Mentions returns a slice of all approved Mentions for this Stream
 func (w Stream) Mentions() ([]model.Mention, error) {
 	mentionService := w.factory().Mention()
-	return mentionService.QueryByObjectID(w._stream.StreamID)
+	return mentionService.QueryValidatedByObjectID(w._stream.StreamID)
 }
 
 // RepliesBefore returns a slice of all ActivityStreams before the specified date
@@ -620,6 +620,11 @@ func (w Stream) RepliesBefore(dateString string, maxRows int) sliceof.Object[str
 	limitedFilter := channel.Limit(maxRows, filteredResult, done)
 	result := channel.Slice(limitedFilter)
 
+	// Include approved WebMention replies in the same thread
+	mentionService := w._factory.Mention()
+	mentions := mentionService.QueryRepliesBeforeDate(w._stream.StreamID, maxDate, maxRows)
+	result = service.MergeReplies(result, mentions, maxRows)
+
 	return slice.Reverse(result)
 }
 
@@ -805,6 +810,53 @@ func (w Stream) AttachmentsByCategory(category string) (sliceof.Object[model.Att
 	return w.factory().Attachment().QueryByCategory(model.AttachmentObjectTypeStream, w._stream.StreamID, category)
 }
 
+/******************************************
+ * Revisions
+ ******************************************/
+
+// Revisions lists the revision history for this stream, newest first
+func (w Stream) Revisions() (sliceof.Object[model.StreamRevision], error) {
+	return w.factory().StreamRevision().QueryByStream(w._stream.StreamID)
+}
+
+// Revision returns a single revision of this stream
+func (w Stream) Revision(revisionID string) (model.StreamRevision, error) {
+
+	result := model.NewStreamRevision()
+
+	objectID, err := primitive.ObjectIDFromHex(revisionID)
+
+	if err != nil {
+		return result, derp.Wrap(err, "build.Stream.Revision", "Invalid revision ID", revisionID)
+	}
+
+	err = w.factory().StreamRevision().LoadByID(w._stream.StreamID, objectID, &result)
+	return result, err
+}
+
+// RevisionDiff returns the field-level changes between two revisions of this stream.
+// If toID is empty, then the "from" revision is compared to the current stream.
+func (w Stream) RevisionDiff(fromID string, toID string) (sliceof.Object[model.StreamRevisionChange], error) {
+
+	const location = "build.Stream.RevisionDiff"
+
+	from, err := w.Revision(fromID)
+
+	if err != nil {
+		return nil, derp.Wrap(err, location, "Error loading revision", fromID)
+	}
+
+	to := model.NewStreamRevisionFromStream(w._stream, "")
+
+	if toID != "" {
+		if to, err = w.Revision(toID); err != nil {
+			return nil, derp.Wrap(err, location, "Error loading revision", toID)
+		}
+	}
+
+	return from.Diff(to), nil
+}
+
 /******************************************
  * Content Actors
  ******************************************/
@@ -24,6 +24,8 @@ type Factory interface {
 	Model(string) (service.ModelService, error)
 	ActivityStream() *service.ActivityStream
 	Attachment() *service.Attachment
+	AuditLog() *service.AuditLog
+	Blocklist() *service.Blocklist
 	Connection() *service.Connection
 	Folder() *service.Folder
 	Following() *service.Following
@@ -36,16 +38,19 @@ type Factory interface {
 	Registration() *service.Registration
 	Response() *service.Response
 	Rule() *service.Rule
+	SavedSearch() *service.SavedSearch
 	Search() *service.Search
 	SearchTag() *service.SearchTag
 	Stream() *service.Stream
 	StreamArchive() *service.StreamArchive
 	StreamDraft() *service.StreamDraft
+	StreamRevision() *service.StreamRevision
 	Template() *service.Template
 	Theme() *service.Theme
 	User() *service.User
 	Webhook() *service.Webhook
 	Widget() *service.Widget
+	Translation() *service.Translation
 
 	// Other data services
 	Config() config.Domain
@@ -3,10 +3,18 @@ package build
 import (
 	"html/template"
 
+	"github.com/EmissarySocial/emissary/service"
 	"github.com/EmissarySocial/emissary/tools/templates"
 	"github.com/benpate/icon"
 )
 
-func FuncMap(icons icon.Provider) template.FuncMap {
-	return templates.FuncMap(icons)
+func FuncMap(icons icon.Provider, translationService *service.Translation) template.FuncMap {
+
+	result := templates.FuncMap(icons)
+
+	// t translates a message key into the requested locale:
+	// {{t .Locale "stream.replies" "count" 3}}
+	result["t"] = translationService.Translate
+
+	return result
 }
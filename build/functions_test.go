@@ -10,7 +10,7 @@ import (
 
 func TestFunctions_Icon(t *testing.T) {
 
-	f := FuncMap(bootstrap.Provider{})
+	f := FuncMap(bootstrap.Provider{}, nil)
 
 	icon := f["icon"].(func(string) template.HTML)
 
@@ -19,7 +19,7 @@ func TestFunctions_Icon(t *testing.T) {
 
 func TestFunctions_DollarFormat(t *testing.T) {
 
-	f := FuncMap(bootstrap.Provider{})
+	f := FuncMap(bootstrap.Provider{}, nil)
 
 	dollarFormat := f["dollarFormat"].(func(any) string)
 
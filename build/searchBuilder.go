@@ -2,6 +2,7 @@ package build
 
 import (
 	"iter"
+	"slices"
 
 	"github.com/EmissarySocial/emissary/model"
 	"github.com/EmissarySocial/emissary/service"
@@ -12,9 +13,13 @@ import (
 
 type SearchBuilder struct {
 	service       *service.Search
+	Text          string // Free-text query, ranked by the domain's search engine
 	Criteria      exp.Expression
+	LocalOnly     bool     // If TRUE, then results from other servers are excluded
+	Facets        []string // Fields to count values for in Results()
 	SortField     string
 	SortDirection string
+	Offset        int64 // Number of results to skip, used to page through results
 	MaxRows       int64
 }
 
@@ -23,6 +28,7 @@ func NewSearchBuilder(service *service.Search, criteria exp.Expression) SearchBu
 	return SearchBuilder{
 		service:       service,
 		Criteria:      criteria,
+		Facets:        []string{"type", "tagValues"},
 		SortField:     "rank",
 		SortDirection: "asc",
 		MaxRows:       60,
@@ -97,6 +103,18 @@ func (builder SearchBuilder) All() SearchBuilder {
 	return builder
 }
 
+// Skip omits the first (offset) results, so that templates can page through results
+func (builder SearchBuilder) Skip(offset int64) SearchBuilder {
+	builder.Offset = max(offset, 0)
+	return builder
+}
+
+// Page skips to a page of results (starting with 1), using the current MaxRows as the page size
+func (builder SearchBuilder) Page(page int64) SearchBuilder {
+	builder.Offset = max(page-1, 0) * builder.MaxRows
+	return builder
+}
+
 func (builder SearchBuilder) AfterRank(rank int64) SearchBuilder {
 	builder.Criteria = builder.Criteria.AndGreaterThan("rank", rank)
 	return builder
@@ -107,6 +125,40 @@ func (builder SearchBuilder) AfterShuffle(shuffle int64) SearchBuilder {
 	return builder
 }
 
+// Match adds a free-text query, which sorts results by relevance
+func (builder SearchBuilder) Match(text string) SearchBuilder {
+	builder.Text = text
+	builder.SortField = "score"
+	builder.SortDirection = option.SortDirectionAscending
+	return builder
+}
+
+// WithFacets sets the fields whose values are counted in Results()
+func (builder SearchBuilder) WithFacets(fields ...string) SearchBuilder {
+	builder.Facets = fields
+	return builder
+}
+
+// Local limits results to documents that were published on this server
+func (builder SearchBuilder) Local() SearchBuilder {
+	builder.LocalOnly = true
+	return builder
+}
+
+// Everything includes documents from other servers (if federated search is enabled)
+func (builder SearchBuilder) Everything() SearchBuilder {
+	builder.LocalOnly = false
+	return builder
+}
+
+// Scope returns "local" if this query only includes local documents, or "everything" otherwise
+func (builder SearchBuilder) Scope() string {
+	if builder.LocalOnly {
+		return "local"
+	}
+	return "everything"
+}
+
 func (builder SearchBuilder) Where(field string, value any) SearchBuilder {
 	builder.Criteria = builder.Criteria.AndEqual(field, value)
 	return builder
@@ -137,6 +189,12 @@ func (builder SearchBuilder) ByRank() SearchBuilder {
 	return builder
 }
 
+// ByRelevance sorts text queries by their relevance score (highest first)
+func (builder SearchBuilder) ByRelevance() SearchBuilder {
+	builder.SortField = "score"
+	return builder
+}
+
 func (builder SearchBuilder) ByShuffle() SearchBuilder {
 	builder.SortField = "shuffle"
 	return builder
@@ -156,26 +214,75 @@ func (builder SearchBuilder) Reverse() SearchBuilder {
  * ACTIONS
  ********************************/
 
+// Results returns the results of the query along with the total number of matches and
+// facet counts.  Text queries also include relevance scores and highlighted snippets.
+func (builder SearchBuilder) Results() (model.SearchResultSet, error) {
+	return builder.service.Search(builder.makeQuery())
+}
+
 // Slice returns the results of the query as a slice of objects
 func (builder SearchBuilder) Slice() (sliceof.Object[model.SearchResult], error) {
-	return builder.service.Query(builder.Criteria, builder.makeOptions()...)
+
+	// Text queries and offsets are handled by the search service
+	if (builder.Text != "") || (builder.Offset > 0) {
+		builder.Facets = nil
+		result, err := builder.Results()
+		return result.Results, err
+	}
+
+	return builder.service.Query(builder.makeCriteria(), builder.makeOptions()...)
 }
 
 // Range returns the results of the query as a Go 1.23 RangeFunc
 func (builder SearchBuilder) Range() (iter.Seq[model.SearchResult], error) {
-	return builder.service.Range(builder.Criteria, builder.makeOptions()...)
+
+	if (builder.Text != "") || (builder.Offset > 0) {
+		result, err := builder.Slice()
+		return slices.Values(result), err
+	}
+
+	return builder.service.Range(builder.makeCriteria(), builder.makeOptions()...)
 }
 
 // Count returns the number of records that match the query criteria
 func (builder SearchBuilder) Count() (int64, error) {
-	return builder.service.Count(builder.Criteria)
 
+	if builder.Text != "" {
+		builder.Facets = nil
+		builder.MaxRows = 1
+		result, err := builder.Results()
+		return result.Total, err
+	}
+
+	return builder.service.Count(builder.makeCriteria())
 }
 
 /********************************
  * MISC HELPERS
  ********************************/
 
+func (builder SearchBuilder) makeQuery() model.SearchQuery {
+	return model.SearchQuery{
+		Text:          builder.Text,
+		Criteria:      builder.makeCriteria(),
+		Facets:        builder.Facets,
+		SortField:     builder.SortField,
+		SortDirection: builder.SortDirection,
+		Offset:        builder.Offset,
+		MaxRows:       builder.MaxRows,
+	}
+}
+
+// makeCriteria returns the query criteria, limited to local documents if requested
+func (builder SearchBuilder) makeCriteria() exp.Expression {
+
+	if builder.LocalOnly {
+		return builder.Criteria.AndNotEqual("remote", true)
+	}
+
+	return builder.Criteria
+}
+
 func (builder SearchBuilder) makeOptions() []option.Option {
 
 	var object model.SearchResult
@@ -38,6 +38,9 @@ func ExecutableStep(stepInfo step.Step) Step {
 	case step.CacheURL:
 		return StepCacheURL(s)
 
+	case step.CallURL:
+		return StepCallURL(s)
+
 	case step.Delete:
 		return StepDelete(s)
 
@@ -113,6 +116,9 @@ func ExecutableStep(stepInfo step.Step) Step {
 	case step.RemoveEvent:
 		return StepRemoveEvent(s)
 
+	case step.RestoreRevision:
+		return StepRestoreRevision(s)
+
 	case step.Save:
 		return StepSave(s)
 
@@ -212,6 +218,9 @@ func ExecutableStep(stepInfo step.Step) Step {
 	case step.WithFollowing:
 		return StepWithFollowing(s)
 
+	case step.WithMention:
+		return StepWithMention(s)
+
 	case step.WithMessage:
 		return StepWithMessage(s)
 
@@ -0,0 +1,129 @@
+package build
+
+import (
+	"io"
+	"text/template"
+	"time"
+
+	"github.com/EmissarySocial/emissary/tools/callurl"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/convert"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/turbine/queue"
+)
+
+// StepCallURL is a Step that sends an HTTP request to an external API
+type StepCallURL struct {
+	Method     string
+	URL        *template.Template
+	Body       *template.Template
+	Headers    map[string]*template.Template
+	Secrets    map[string]string
+	Timeout    time.Duration
+	Queue      bool
+	StreamData map[string]string
+	RenderData map[string]string
+}
+
+// Get does not send the request.  Outbound calls are only made when an action is
+// submitted, so that simply viewing a page cannot trigger them.
+func (step StepCallURL) Get(_ Builder, _ io.Writer) PipelineBehavior {
+	return Continue()
+}
+
+// Post sends the request when the action is submitted
+func (step StepCallURL) Post(builder Builder, _ io.Writer) PipelineBehavior {
+	return step.Do(builder)
+}
+
+// Do sends the request immediately, or pushes it onto the background queue
+func (step StepCallURL) Do(builder Builder) PipelineBehavior {
+
+	const location = "build.StepCallURL.Do"
+
+	// Execute all templates using the current builder
+	url := executeTemplate(step.URL, builder)
+	body := executeTemplate(step.Body, builder)
+	headers := make(map[string]string, len(step.Headers))
+
+	for name, headerTemplate := range step.Headers {
+		headers[name] = executeTemplate(headerTemplate, builder)
+	}
+
+	// Background requests are sent by the queue consumer, which reads secrets and
+	// updates the Stream on its own.  Secret values are never stored in the queue.
+	if step.Queue {
+
+		streamID := ""
+
+		if streamBuilder, isStreamBuilder := builder.(Stream); isStreamBuilder {
+			streamID = streamBuilder.StreamID()
+		} else if len(step.StreamData) > 0 {
+			return Halt().WithError(derp.NewInternalError(location, "stream-data can only be used with a Stream builder"))
+		}
+
+		task := queue.NewTask("CallURL", mapof.Any{
+			"host":       builder.Hostname(),
+			"streamId":   streamID,
+			"method":     step.Method,
+			"url":        url,
+			"body":       body,
+			"headers":    mapof.Any(convert.MapOfAny(headers)),
+			"secrets":    mapof.Any(convert.MapOfAny(step.Secrets)),
+			"timeout":    int(step.Timeout / time.Second),
+			"streamData": mapof.Any(convert.MapOfAny(step.StreamData)),
+		})
+
+		if err := builder.factory().Queue().Publish(task); err != nil {
+			return Halt().WithError(derp.Wrap(err, location, "Error publishing task", url))
+		}
+
+		return nil
+	}
+
+	// Add secret headers from the Domain
+	domain := builder.factory().Domain().Get()
+
+	secretHeaders, err := callurl.SecretHeaders(step.Secrets, domain.Secrets)
+
+	if err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Error reading secrets"))
+	}
+
+	for name, value := range secretHeaders {
+		headers[name] = value
+	}
+
+	request := callurl.Request{
+		Method:       step.Method,
+		URL:          url,
+		Body:         body,
+		Headers:      headers,
+		Timeout:      step.Timeout,
+		AllowedHosts: domain.OutboundHostnames(),
+	}
+
+	response, err := request.Send()
+
+	if err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Error calling URL", url))
+	}
+
+	// Copy response values into the object being built.  A subsequent "save"
+	// step is required to persist these values.
+	object := builder.object()
+	schema := builder.schema()
+
+	for path, responsePath := range step.StreamData {
+		if err := schema.Set(object, path, response.Get(responsePath)); err != nil {
+			return Halt().WithError(derp.Wrap(err, location, "Error setting value from response", path, responsePath))
+		}
+	}
+
+	// Copy response values into the render data
+	for key, responsePath := range step.RenderData {
+		builder.setString(key, convert.String(response.Get(responsePath)))
+	}
+
+	return nil
+}
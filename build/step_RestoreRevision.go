@@ -0,0 +1,60 @@
+package build
+
+import (
+	"io"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StepRestoreRevision is a Step that copies a previous StreamRevision into the Stream's working Draft.
+// The published Stream is not changed until the Draft is promoted.
+type StepRestoreRevision struct{}
+
+func (step StepRestoreRevision) Get(builder Builder, _ io.Writer) PipelineBehavior {
+	return nil
+}
+
+// Post loads the revision identified by the "revisionId" query parameter and saves it as the new Draft
+func (step StepRestoreRevision) Post(builder Builder, _ io.Writer) PipelineBehavior {
+
+	const location = "build.StepRestoreRevision.Post"
+
+	factory := builder.factory()
+	streamID := builder.objectID()
+
+	revisionID, err := primitive.ObjectIDFromHex(builder.QueryParam("revisionId"))
+
+	if err != nil {
+		return Halt().WithError(derp.NewBadRequestError(location, "Invalid revisionId", builder.QueryParam("revisionId")))
+	}
+
+	// Load the requested revision
+	revision := model.NewStreamRevision()
+	if err := factory.StreamRevision().LoadByID(streamID, revisionID, &revision); err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Error loading revision", revisionID))
+	}
+
+	// Load (or create) the working draft
+	draftService := factory.StreamDraft()
+	draft := model.NewStream()
+	if err := draftService.LoadByID(streamID, &draft); err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Error loading draft", streamID))
+	}
+
+	// Overwrite the draft with the contents of the revision
+	note := "Restored revision " + revision.ID()
+	revision.ApplyTo(&draft)
+
+	if err := draftService.Save(&draft, note); err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Error saving draft", streamID))
+	}
+
+	// Record the restore in the revision history
+	if err := factory.StreamRevision().Create(&draft, builder.AuthenticatedID(), model.StreamRevisionKindRestore, note); err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Error recording revision", streamID))
+	}
+
+	return nil
+}
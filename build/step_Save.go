@@ -4,6 +4,7 @@ import (
 	"io"
 	"text/template"
 
+	"github.com/EmissarySocial/emissary/model"
 	"github.com/EmissarySocial/emissary/service"
 	"github.com/benpate/derp"
 )
@@ -53,5 +54,18 @@ func (step StepSave) do(builder Builder) PipelineBehavior {
 		return Halt().WithError(derp.Wrap(err, location, "Error saving model object"))
 	}
 
+	// Record a revision for Streams (and their Drafts)
+	if stream, ok := object.(*model.Stream); ok {
+
+		kind := model.StreamRevisionKindSave
+		if _, isDraft := modelService.(*service.StreamDraft); isDraft {
+			kind = model.StreamRevisionKindDraft
+		}
+
+		if err := builder.factory().StreamRevision().Create(stream, builder.AuthenticatedID(), kind, comment); err != nil {
+			return Halt().WithError(derp.Wrap(err, location, "Error recording revision"))
+		}
+	}
+
 	return Continue()
 }
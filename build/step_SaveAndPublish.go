@@ -41,5 +41,11 @@ func (step StepSaveAndPublish) Post(builder Builder, _ io.Writer) PipelineBehavi
 		return Halt().WithError(derp.Wrap(err, location, "Error publishing stream", streamBuilder._stream))
 	}
 
+	// Record the published version in the Stream's revision history, after all
+	// previous steps (like promote-draft and process-tags) have been saved.
+	if err := factory.StreamRevision().Create(streamBuilder._stream, builder.AuthenticatedID(), model.StreamRevisionKindPublish, "Published"); err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Error recording revision"))
+	}
+
 	return nil
 }
@@ -44,13 +44,24 @@ func (step StepSetPassword) Post(builder Builder, _ io.Writer) PipelineBehavior
 		return Halt().WithError(derp.Wrap(err, location, "Error loading user"))
 	}
 
-	// Set the password (with Steranko password hasher)
-	steranko := factory.Steranko()
+	// Set the password (with Steranko password hasher) and save it
+	hasher := factory.Steranko().PrimaryPasswordHasher()
 	newPassword := transaction.Get("new_password")
-	if err := steranko.SetPassword(&user, newPassword); err != nil {
+
+	if err := userService.SetPassword(&user, hasher, newPassword); err != nil {
 		return Halt().WithError(derp.Wrap(err, location, "Error setting password"))
 	}
 
+	// Record the change in the audit log
+	auditLog := model.NewAuditLog(model.AuditActionPasswordChange).
+		WithActor(user.UserID, user.Username).
+		WithObject("User", user.UserID).
+		WithSummary("Changed password")
+
+	if err := factory.AuditLog().Add(builder.request(), auditLog); err != nil {
+		derp.Report(derp.Wrap(err, location, "Error writing audit log", auditLog))
+	}
+
 	// Silence is AU-some
 	return nil
 }
@@ -2,8 +2,11 @@ package build
 
 import (
 	"io"
+	"strings"
 
+	"github.com/EmissarySocial/emissary/model"
 	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/convert"
 )
 
 // StepSetState is a Step that can change a Stream's state
@@ -15,22 +18,115 @@ func (step StepSetState) Get(builder Builder, _ io.Writer) PipelineBehavior {
 	return nil
 }
 
-// Post updates the stream with configured data, and moves the stream to a new state
-func (step StepSetState) Post(builder Builder, _ io.Writer) PipelineBehavior {
+// Post updates the stream with configured data, and moves the stream to a new state.
+// If the Template declares transitions, then the move must match one of them, and the
+// onExit/onEnter steps of the old and new states are executed around the change.
+func (step StepSetState) Post(builder Builder, buffer io.Writer) PipelineBehavior {
+
+	const location = "build.stepSetState.Post"
 
 	// If the builder is a StateSetter, then try to update the state
-	if setter, ok := builder.(StateSetter); ok {
+	setter, ok := builder.(StateSetter)
+
+	if !ok {
+		// Failure (obv)
+		return Halt().WithError(derp.NewInternalError(location, "Builder does not implement StateSetter interface"))
+	}
 
-		// This action may still fail (for instance) if the builder wraps
-		// a model object that is not a `model.StateSetter`
+	// Only builders that wrap a Template and a stateful object can enforce transitions
+	getter, hasTemplate := builder.(templateGetter)
+	enumerator, hasState := builder.object().(model.RoleStateEnumerator)
+
+	if !hasTemplate || !hasState {
 		if err := setter.setState(step.State); err != nil {
-			return Halt().WithError(derp.Wrap(err, "build.stepSetState.Post", "Error setting state"))
+			return Halt().WithError(derp.Wrap(err, location, "Error setting state"))
+		}
+		return nil
+	}
+
+	template := getter.template()
+	fromStateID := enumerator.State()
+
+	// Staying in the same state is not a transition
+	if fromStateID == step.State {
+		return nil
+	}
+
+	// Verify that this move is permitted
+	if err := step.allowTransition(builder, &template, enumerator, fromStateID); err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Transition not allowed", fromStateID, step.State))
+	}
+
+	factory := builder.factory()
+	result := NewPipelineResult()
+
+	// Execute "onExit" steps for the previous state
+	if fromState, ok := template.State(fromStateID); ok && len(fromState.OnExit) > 0 {
+		result.Merge(Pipeline(fromState.OnExit).Post(factory, builder, buffer))
+		result.Error = derp.Wrap(result.Error, location, "Error executing onExit steps", fromStateID)
+
+		if result.Halt || result.Error != nil {
+			return UseResult(result)
 		}
+	}
+
+	// This action may still fail (for instance) if the builder wraps
+	// a model object that is not a `model.StateSetter`
+	if err := setter.setState(step.State); err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Error setting state"))
+	}
+
+	// Execute "onEnter" steps for the new state
+	if toState, ok := template.State(step.State); ok && len(toState.OnEnter) > 0 {
+		result.Merge(Pipeline(toState.OnEnter).Post(factory, builder, buffer))
+		result.Error = derp.Wrap(result.Error, location, "Error executing onEnter steps", step.State)
+	}
+
+	// Success
+	return UseResult(result)
+}
+
+// allowTransition returns an error if the Template declares transitions, and none of them
+// permits the current user to move from the current state into the requested state.
+func (step StepSetState) allowTransition(builder Builder, template *model.Template, enumerator model.RoleStateEnumerator, fromStateID string) error {
+
+	const location = "build.StepSetState.allowTransition"
 
-		// Success
+	transitions, restricted := template.FindTransitions(fromStateID, step.State)
+
+	// Templates without transitions allow all state changes.
+	// New objects that have never had a state can move into any state.
+	if !restricted || fromStateID == "" || fromStateID == model.StreamStateNew {
 		return nil
 	}
 
-	// Failure (obv)
-	return Halt().WithError(derp.NewInternalError("build.stepSetState.Post", "Builder does not implement StateSetter interface"))
+	if len(transitions) == 0 {
+		return derp.NewBadRequestError(location, "Template does not declare this transition", fromStateID, step.State)
+	}
+
+	authorization := builder.authorization()
+	hasRole := false
+
+	for _, transition := range transitions {
+
+		if !transition.UserCan(enumerator, &authorization) {
+			continue
+		}
+
+		hasRole = true
+
+		if transition.Guard == nil {
+			return nil
+		}
+
+		if convert.Bool(strings.TrimSpace(executeTemplate(transition.Guard, builder))) {
+			return nil
+		}
+	}
+
+	if !hasRole {
+		return derp.NewForbiddenError(location, "User does not have a role that permits this transition", fromStateID, step.State)
+	}
+
+	return derp.NewBadRequestError(location, "Transition guard not satisfied", fromStateID, step.State)
 }
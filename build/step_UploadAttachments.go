@@ -3,11 +3,16 @@ package build
 import (
 	"encoding/json"
 	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
 
 	"github.com/EmissarySocial/emissary/model"
 	"github.com/benpate/derp"
 	"github.com/benpate/rosetta/mapof"
 	"github.com/benpate/rosetta/slice"
+	"github.com/benpate/turbine/queue"
+	"github.com/dustin/go-humanize"
 	"github.com/rs/zerolog/log"
 )
 
@@ -31,6 +36,10 @@ type StepUploadAttachments struct {
 	RuleHeight int      // Fixed height for all downloads
 	RuleWidth  int      // Fixed width for all downloads
 	RuleTypes  []string // Allowed extensions.  The first value is used as the default.
+
+	RuleKeepMetadata bool  // If TRUE, then EXIF/GPS metadata is kept in the original file
+	RuleMaxSize      int64 // Maximum size (in bytes) of each uploaded file.  Zero means no limit.
+	RuleMaxCount     int   // Maximum number of Attachments (in this category) that an object can have.  Zero means no limit.
 }
 
 func (step StepUploadAttachments) Get(builder Builder, _ io.Writer) PipelineBehavior {
@@ -73,6 +82,34 @@ func (step StepUploadAttachments) Post(builder Builder, buffer io.Writer) Pipeli
 		objectType = "Stream"
 	}
 
+	// RULE: Each file must be smaller than the maximum size
+	uploadSize, err := step.checkFileSize(files)
+
+	if err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Invalid file size"))
+	}
+
+	// RULE: Objects cannot have more than the maximum number of attachments
+	if (step.RuleMaxCount > 0) && (step.Action != "replace") {
+
+		existing, err := attachmentService.QueryByCategory(objectType, objectID, step.Category)
+
+		if err != nil {
+			return Halt().WithError(derp.Wrap(err, location, "Error counting existing Attachments"))
+		}
+
+		if err := step.checkFileCount(len(existing), len(files)); err != nil {
+			return Halt().WithError(derp.Wrap(err, location, "Invalid number of files"))
+		}
+	}
+
+	// RULE: Uploads cannot exceed the storage quota
+	userID := builder.authorization().UserID
+
+	if err := attachmentService.CheckQuota(userID, uploadSize); err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Storage quota exceeded"))
+	}
+
 	// Make room for new attachments
 	if err := attachmentService.MakeRoom(objectType, objectID, step.Category, step.Action, step.Maximum, len(files)); err != nil {
 		return Halt().WithError(derp.Wrap(err, location, "Error making room for new Attachments"))
@@ -96,6 +133,8 @@ func (step StepUploadAttachments) Post(builder Builder, buffer io.Writer) Pipeli
 		attachment := model.NewAttachment(objectType, objectID)
 		attachment.Original = fileHeader.Filename
 		attachment.Category = step.Category
+		attachment.UserID = userID
+		attachment.Size = fileHeader.Size
 
 		// Try to set labels from the stepInfo and form
 		if step.Label != "" {
@@ -111,20 +150,35 @@ func (step StepUploadAttachments) Post(builder Builder, buffer io.Writer) Pipeli
 			attachment.Description = slice.At(form.Value[step.DescriptionFieldname], index)
 		}
 
-		// Add the document into the media server.
-		// If it's an image or video, then save the dimensions as well.
-		if err := factory.MediaServer().Put(attachment.AttachmentID.Hex(), source); err != nil {
+		// Apply rules to Attachment
+		attachment.SetRules(step.RuleWidth, step.RuleHeight, step.RuleTypes)
+		attachment.Rules.KeepMetadata = step.RuleKeepMetadata
+
+		// Add the document (without its metadata) into the media server.
+		if err := attachmentService.PutOriginal(&attachment, source); err != nil {
 			return Halt().WithError(derp.Wrap(err, location, "Error saving attachment to mediaserver", attachment))
 		}
 
-		// Apply rules to Attachment
-		attachment.SetRules(step.RuleWidth, step.RuleHeight, step.RuleTypes)
+		// Media is processed in the background, so it is not READY yet
+		attachment.Status = model.AttachmentStatusWorking
 
 		// Try to save the Attachment
 		if err := attachmentService.Save(&attachment, "Uploaded file: "+fileHeader.Filename); err != nil {
 			return Halt().WithError(derp.Wrap(err, location, "Error saving attachment", attachment))
 		}
 
+		// Process the file (metadata, dimensions, renditions) in the background
+		task := queue.NewTask("ProcessMedia", mapof.Any{
+			"host":         builder.Hostname(),
+			"objectType":   attachment.ObjectType,
+			"objectId":     attachment.ObjectID.Hex(),
+			"attachmentId": attachment.AttachmentID.Hex(),
+		})
+
+		if err := factory.Queue().Publish(task); err != nil {
+			return Halt().WithError(derp.Wrap(err, location, "Error publishing task", task))
+		}
+
 		// Try to put the the attachmentId into the object
 		if step.AttachmentPath != "" {
 			log.Trace().Str("AttachmentPath", step.AttachmentPath).Str("Value", attachment.AttachmentID.Hex()).Msg("Setting attachment path")
@@ -183,3 +237,31 @@ func (step StepUploadAttachments) Post(builder Builder, buffer io.Writer) Pipeli
 	// After all files are uploaded, tell the client that we're done.
 	return Continue().WithEvent("attachments-updated", "true")
 }
+
+// checkFileSize returns an error if any file is larger than the maximum size.
+// Otherwise, it returns the total size of all files.
+func (step StepUploadAttachments) checkFileSize(files []*multipart.FileHeader) (int64, error) {
+
+	result := int64(0)
+
+	for _, fileHeader := range files {
+
+		if (step.RuleMaxSize > 0) && (fileHeader.Size > step.RuleMaxSize) {
+			return 0, derp.New(http.StatusRequestEntityTooLarge, "build.StepUploadAttachments.checkFileSize", "File is too large. Maximum size is "+humanize.Bytes(uint64(step.RuleMaxSize)), fileHeader.Filename)
+		}
+
+		result += fileHeader.Size
+	}
+
+	return result, nil
+}
+
+// checkFileCount returns an error if uploading more files would exceed the maximum number of attachments
+func (step StepUploadAttachments) checkFileCount(existing int, uploading int) error {
+
+	if (step.RuleMaxCount > 0) && (existing+uploading > step.RuleMaxCount) {
+		return derp.New(http.StatusBadRequest, "build.StepUploadAttachments.checkFileCount", "Too many files. Maximum is "+strconv.Itoa(step.RuleMaxCount), existing, uploading)
+	}
+
+	return nil
+}
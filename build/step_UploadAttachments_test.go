@@ -0,0 +1,51 @@
+package build
+
+import (
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/benpate/derp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadAttachments_FileSize(t *testing.T) {
+
+	files := []*multipart.FileHeader{
+		{Filename: "small.jpg", Size: 1000},
+		{Filename: "medium.jpg", Size: 2000},
+	}
+
+	// No limit
+	step := StepUploadAttachments{}
+	size, err := step.checkFileSize(files)
+	require.Nil(t, err)
+	require.Equal(t, int64(3000), size)
+
+	// Every file is within the limit
+	step.RuleMaxSize = 2000
+	size, err = step.checkFileSize(files)
+	require.Nil(t, err)
+	require.Equal(t, int64(3000), size)
+
+	// One file is too large
+	step.RuleMaxSize = 1999
+	_, err = step.checkFileSize(files)
+	require.Equal(t, http.StatusRequestEntityTooLarge, derp.ErrorCode(err))
+}
+
+func TestUploadAttachments_FileCount(t *testing.T) {
+
+	// No limit
+	step := StepUploadAttachments{}
+	require.Nil(t, step.checkFileCount(100, 100))
+
+	// Within the limit
+	step.RuleMaxCount = 3
+	require.Nil(t, step.checkFileCount(0, 3))
+	require.Nil(t, step.checkFileCount(2, 1))
+
+	// Too many files
+	require.Equal(t, http.StatusBadRequest, derp.ErrorCode(step.checkFileCount(3, 1)))
+	require.Equal(t, http.StatusBadRequest, derp.ErrorCode(step.checkFileCount(0, 4)))
+}
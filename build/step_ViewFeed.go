@@ -35,6 +35,14 @@ func (step StepViewFeed) Get(builder Builder, buffer io.Writer) PipelineBehavior
 
 	mimeType := step.detectMimeType(builder)
 
+	// Advertise the local WebSub hub, along with any external hubs configured for this domain
+	header := builder.response().Header()
+	header.Add("Link", `<`+builder.Permalink()+`/websub>; rel="hub"`)
+	for _, hub := range factory.Domain().Get().WebSubHubURLs() {
+		header.Add("Link", `<`+hub+`>; rel="hub"`)
+	}
+	header.Add("Link", `<`+builder.Permalink()+`/feed>; rel="self"`)
+
 	// Special case for JSONFeed
 	if mimeType == model.MimeTypeJSONFeed {
 		return step.asJSONFeed(builder, buffer, children)
@@ -121,6 +129,10 @@ func (step StepViewFeed) asJSONFeed(builder Builder, buffer io.Writer, children
 		},
 	}
 
+	for _, hub := range builder.factory().Domain().Get().WebSubHubURLs() {
+		feed.Hubs = append(feed.Hubs, jsonfeed.Hub{Type: "WebSub", URL: hub})
+	}
+
 	feed.Items = slice.Map(iterator.Slice(children, model.NewStream), convert.StreamToJsonFeed)
 
 	builder.response().Header().Add("Content-Type", model.MimeTypeJSONFeed)
@@ -0,0 +1,64 @@
+package build
+
+import (
+	"io"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/model/step"
+	"github.com/benpate/derp"
+)
+
+// StepWithMention is a Step that executes a list of sub-steps on a Mention that the current User moderates
+type StepWithMention struct {
+	SubSteps []step.Step
+}
+
+func (step StepWithMention) Get(builder Builder, buffer io.Writer) PipelineBehavior {
+	return step.execute(builder, buffer, ActionMethodGet)
+}
+
+// Post updates the Mention with approved data from the request body.
+func (step StepWithMention) Post(builder Builder, buffer io.Writer) PipelineBehavior {
+	return step.execute(builder, buffer, ActionMethodPost)
+}
+
+func (step StepWithMention) execute(builder Builder, buffer io.Writer, actionMethod ActionMethod) PipelineBehavior {
+
+	const location = "build.StepWithMention.execute"
+
+	if !builder.IsAuthenticated() {
+		return Halt().WithError(derp.NewUnauthorizedError(location, "Anonymous user is not authorized to perform this action"))
+	}
+
+	// Try to find the Template for this builder.
+	// This *should* work for all builders that use CommonWithTemplate
+	template, exists := getTemplate(builder)
+
+	if !exists {
+		return Halt().WithError(derp.NewInternalError(location, "This step cannot be used in this Renderer."))
+	}
+
+	// Collect required services and values
+	factory := builder.factory()
+	mentionService := factory.Mention()
+	token := builder.QueryParam("mentionId")
+	mention := model.NewMention()
+
+	// Mentions are only created by WebMentions, so they must already exist
+	if err := mentionService.LoadByToken(builder.AuthenticatedID(), token, &mention); err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Unable to load Mention", token))
+	}
+
+	// Create a new builder tied to the Mention record
+	subBuilder, err := NewModel(factory, builder.request(), builder.response(), template, &mention, builder.actionID())
+
+	if err != nil {
+		return Halt().WithError(derp.Wrap(err, location, "Unable to create sub-builder"))
+	}
+
+	// Execute the build pipeline on the Mention record
+	result := Pipeline(step.SubSteps).Execute(factory, subBuilder, buffer, actionMethod)
+	result.Error = derp.Wrap(result.Error, location, "Error executing steps for child")
+
+	return UseResult(result)
+}
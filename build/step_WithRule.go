@@ -61,9 +61,30 @@ func (step StepWithRule) execute(builder Builder, buffer io.Writer, actionMethod
 		return Halt().WithError(derp.Wrap(err, location, "Unable to create sub-builder"))
 	}
 
+	// Snapshot the Rule so that changes can be recorded in the audit log
+	isNew := rule.IsNew()
+	before := model.AuditSnapshot(&rule)
+
 	// Execute the POST build pipeline on the child
 	reesult := Pipeline(step.SubSteps).Execute(factory, subBuilder, buffer, actionMethod)
-	reesult.Error = derp.Wrap(reesult.Error, location, "Error executing steps for child")
+
+	if reesult.Error != nil {
+		reesult.Error = derp.Wrap(reesult.Error, location, "Error executing steps for child")
+		return UseResult(reesult)
+	}
+
+	if actionMethod == ActionMethodPost {
+
+		action := model.AuditActionRuleUpdate
+
+		if isNew {
+			action = model.AuditActionRuleCreate
+		} else if rule.IsDeleted() {
+			action = model.AuditActionRuleDelete
+		}
+
+		writeAuditLog(subBuilder, action, before, rule.Type+": "+rule.Trigger)
+	}
 
 	return UseResult(reesult)
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/EmissarySocial/emissary/model"
 	"github.com/EmissarySocial/emissary/service"
+	"github.com/benpate/data"
 	"github.com/benpate/derp"
 	"github.com/benpate/html"
 	"github.com/benpate/rosetta/convert"
@@ -268,6 +269,80 @@ func AsHTML(factory Factory, ctx echo.Context, b Builder, actionMethod ActionMet
 	return ctx.HTML(http.StatusOK, fullPage.String())
 }
 
+// AsAuditedHTML works like AsHTML, and also records any changes that a POST makes to the
+// Builder's object in the domain's audit log.  This is used for all admin pages.
+func AsAuditedHTML(factory Factory, ctx echo.Context, b Builder, actionMethod ActionMethod) error {
+
+	// GET requests do not change anything, so there is nothing to audit
+	if actionMethod != ActionMethodPost {
+		return AsHTML(factory, ctx, b, actionMethod)
+	}
+
+	// Snapshot the object BEFORE the pipeline runs, because some
+	// builders (like the Domain) modify a shared, cached value.
+	isNew := b.object().IsNew()
+	before := model.AuditSnapshot(b.object())
+
+	if err := AsHTML(factory, ctx, b, actionMethod); err != nil {
+		return err
+	}
+
+	action := model.AuditActionAdminUpdate
+
+	if isNew {
+		action = model.AuditActionAdminCreate
+	} else if isDeleted(b.object()) {
+		action = model.AuditActionAdminDelete
+	}
+
+	summary := b.objectType() + ": " + b.actionID()
+
+	if getter, ok := b.(templateGetter); ok {
+		summary = getter.template().TemplateID + ": " + b.actionID()
+	}
+
+	writeAuditLog(b, action, before, summary)
+	return nil
+}
+
+// writeAuditLog compares the Builder's object with a snapshot of its previous values,
+// and records any changes in the domain's audit log.  Failures are reported, but
+// never change the result of the request.
+func writeAuditLog(b Builder, action string, before map[string]any, summary string) {
+
+	const location = "build.writeAuditLog"
+
+	changes := model.AuditChanges(before, model.AuditSnapshot(b.object()))
+
+	if len(changes) == 0 {
+		return
+	}
+
+	auditLog := model.NewAuditLog(action).
+		WithObject(b.objectType(), b.objectID()).
+		WithSummary(summary)
+
+	auditLog.Changes = changes
+
+	if user, err := b.getUser(); err == nil {
+		auditLog = auditLog.WithActor(user.UserID, user.Username)
+	}
+
+	if err := b.factory().AuditLog().Add(b.request(), auditLog); err != nil {
+		derp.Report(derp.Wrap(err, location, "Error writing audit log", auditLog))
+	}
+}
+
+// isDeleted returns TRUE if the object has been (virtually) deleted
+func isDeleted(object data.Object) bool {
+
+	if deletable, ok := object.(interface{ IsDeleted() bool }); ok {
+		return deletable.IsDeleted()
+	}
+
+	return false
+}
+
 // isUserVisible returns TRUE if the currently signed in user is allowed to
 // view the provided model.User record.
 func isUserVisible(authorization *model.Authorization, user *model.User) bool {
@@ -4,17 +4,20 @@ import (
 	"os"
 	"strings"
 
+	"github.com/benpate/rosetta/convert"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/pflag"
 )
 
 // CommandLineArgs represents the command line arguments passed to the server
 type CommandLineArgs struct {
-	Source   string // Type of configuration file (Command Line | Enviornment Variable | Default)
-	Protocol string // Protocol to use when loading the configuration (MONGODB | FILE)
-	Location string // URI of the configuration file
-	Setup    bool   // If TRUE, then the server will run in SETUP mode
-	HTTPPort int    // Port to use in setup mode (only)
+	Source            string // Type of configuration file (Command Line | Enviornment Variable | Default)
+	Protocol          string // Protocol to use when loading the configuration (MONGODB | FILE | ENV)
+	Location          string // URI of the configuration file
+	Setup             bool   // If TRUE, then the server will run in SETUP mode
+	ReadOnly          bool   // If TRUE, then the configuration file cannot be changed by the server
+	HTTPPort          int    // Port to use in setup mode (only)
+	ValidateTemplates string // If present, validate the templates in this folder (or "embed" for the built-in templates) and exit
 }
 
 // GetCommandLineArgs returns the location of the configuration file
@@ -23,12 +26,17 @@ func GetCommandLineArgs() CommandLineArgs {
 	var source string
 	var location string
 	var setup bool
+	var readOnly bool
 	var httpPort int
+	var validateTemplates string
 
 	// Look for the configuration location in the command line arguments
 	pflag.StringVar(&location, "config", "", "Path to configuration file")
 	pflag.BoolVar(&setup, "setup", false, "Run setup server")
+	pflag.BoolVar(&readOnly, "readonly", false, "Prevent changes to the configuration file")
 	pflag.IntVar(&httpPort, "port", 0, "HTTP Port to use for setup mode.")
+	pflag.StringVar(&validateTemplates, "validate-templates", "", "Validate the templates in a folder, print all problems, and exit.")
+	pflag.Lookup("validate-templates").NoOptDefVal = "embed"
 	pflag.Parse()
 
 	if location != "" {
@@ -52,12 +60,19 @@ func GetCommandLineArgs() CommandLineArgs {
 		source = ConfigSourceDefault
 	}
 
+	// Allow read-only mode to be set from the environment as well
+	if convert.Bool(os.Getenv("EMISSARY_CONFIG_READONLY")) {
+		readOnly = true
+	}
+
 	return CommandLineArgs{
-		Source:   source,
-		Location: location,
-		Protocol: getConfigProtocol(location),
-		Setup:    setup,
-		HTTPPort: httpPort,
+		Source:            source,
+		Location:          location,
+		Protocol:          getConfigProtocol(location),
+		Setup:             setup,
+		ReadOnly:          readOnly,
+		HTTPPort:          httpPort,
+		ValidateTemplates: validateTemplates,
 	}
 }
 
@@ -74,10 +89,13 @@ func getConfigProtocol(location string) string {
 
 	case strings.HasPrefix(location, "file://"):
 		return StorageTypeFile
+
+	case strings.HasPrefix(location, "env://"):
+		return StorageTypeEnvironment
 	}
 
 	// Fatal error
-	log.Error().Msg("Invalid configuration location.  Must be file:// or mongodb:// or mongodb+srv://")
+	log.Error().Msg("Invalid configuration location.  Must be file:// or env:// or mongodb:// or mongodb+srv://")
 	os.Exit(1)
 
 	return ""
@@ -22,6 +22,8 @@ type Config struct {
 	Domains             set.Slice[Domain]            `json:"domains"`             // Slice of one or more domain configurations
 	Providers           set.Slice[Provider]          `json:"providers"`           // Slice of one or more OAuth client configurations
 	Templates           sliceof.Object[mapof.String] `json:"templates"`           // Folders containing all stream templates
+	TemplatePackages    set.Slice[TemplatePackage]   `json:"templatePackages"`    // Template packages installed on this server from Git repositories or .zip files
+	Packages            mapof.String                 `json:"packages"`            // Folder where installed template packages are unpacked
 	AttachmentOriginals mapof.String                 `json:"attachmentOriginals"` // Folder where original attachments will be stored
 	AttachmentCache     mapof.String                 `json:"attachmentCache"`     // Folder (possibly memory cache) where cached versions of attachmented files will be stored.
 	ExportCache         mapof.String                 `json:"exportCache"`         // Folder where exported files will be stored
@@ -31,15 +33,20 @@ type Config struct {
 	HTTPPort            int                          `json:"httpPort"`            // Port to listen on for HTTP requests
 	HTTPSPort           int                          `json:"httpsPort"`           // Port to listen on for HTTPS requests
 	DebugLevel          string                       `json:"debugLevel"`          // Amount of debugging information to log for the server, using zerolog levels (Trace, Debug, Info, Error, None)
+	ProvisioningToken   string                       `json:"provisioningToken"`   // Bearer token required to use the provisioning API.  If empty, then the provisioning API is disabled.
+	RateLimitStore      mapof.String                 `json:"rateLimitStore"`      // Where rate limits are counted (MEMORY, or REDIS with a connection URL)
+	RateLimits          mapof.String                 `json:"rateLimits"`          // Overrides the default rate limit for each class of routes (like "signin": "10/5m")
 	Source              string                       `json:"-"`                   // READONLY: Where did the initial config location come from?  (Command Line, Environment Variable, Default)
 	Location            string                       `json:"-"`                   // READONLY: Location where this config file is read from/to.  Not a part of the configuration itself.
+	ReadOnly            bool                         `json:"-" bson:"-"`          // READONLY: If TRUE, then this configuration cannot be changed by the setup console.
 	MongoID             primitive.ObjectID           `json:"-" bson:"_id"`        // Used as unique key for MongoDB
 }
 
 // NewConfig returns a fully initialized (but empty) Config data structure.
 func NewConfig() Config {
 	return Config{
-		Domains: make(set.Slice[Domain], 0),
+		Domains:          make(set.Slice[Domain], 0),
+		TemplatePackages: make(set.Slice[TemplatePackage], 0),
 	}
 }
 
@@ -51,11 +58,15 @@ func DefaultConfig() Config {
 
 		// File Locations
 		Templates:           sliceof.Object[mapof.String]{mapof.String{"adapter": "EMBED", "location": "templates"}},
+		TemplatePackages:    set.Slice[TemplatePackage]{},
+		Packages:            mapof.String{"adapter": "FILE", "location": "./.emissary/packages"},
 		AttachmentOriginals: mapof.String{"adapter": "FILE", "location": "./.emissary/attachments"},
 		AttachmentCache:     mapof.String{"adapter": "FILE", "location": "./.emissary/cache"},
 		ExportCache:         mapof.String{"adapter": "FILE", "location": "./.emissary/exports"},
 		Certificates:        mapof.String{"adapter": "FILE", "location": "./.emissary/certificates"},
 		ActivityPubCache:    mapof.String{},
+		RateLimitStore:      mapof.String{"adapter": "MEMORY"},
+		RateLimits:          mapof.String{},
 		DebugLevel:          "None",
 		HTTPPort:            8080,
 		HTTPSPort:           443,
@@ -82,6 +93,46 @@ func (config Config) DomainNames() []string {
 	return result
 }
 
+// DomainIDs returns an array of domain IDs in this configuration.
+func (config Config) DomainIDs() []string {
+
+	result := make([]string, len(config.Domains))
+
+	for index := range config.Domains {
+		result[index] = config.Domains[index].DomainID
+	}
+
+	return result
+}
+
+// TemplateLocations returns all folders that templates are loaded from, including
+// the active version of every installed TemplatePackage
+func (config Config) TemplateLocations() sliceof.Object[mapof.String] {
+
+	result := make(sliceof.Object[mapof.String], 0, len(config.Templates)+len(config.TemplatePackages))
+	result = append(result, config.Templates...)
+
+	for _, templatePackage := range config.TemplatePackages {
+		if folder, ok := templatePackage.Folder(); ok {
+			result = append(result, folder)
+		}
+	}
+
+	return result
+}
+
+// ProviderIDs returns an array of provider IDs in this configuration.
+func (config Config) ProviderIDs() []string {
+
+	result := make([]string, len(config.Providers))
+
+	for index := range config.Providers {
+		result[index] = config.Providers[index].ProviderID
+	}
+
+	return result
+}
+
 func (config Config) AllProviders() []form.LookupCode {
 
 	// Just locate the providers that require configuration
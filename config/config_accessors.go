@@ -20,11 +20,15 @@ func Schema() schema.Schema {
 				"attachmentCache":     WritableFolderSchema(),
 				"exportCache":         WritableFolderSchema(),
 				"certificates":        WritableFolderSchema(),
+				"packages":            WritableFolderSchema(),
 				"debugLevel":          schema.String{Enum: []string{"None", "Trace", "Debug", "Info", "Error"}, Default: "None"},
 				"adminEmail":          schema.String{Format: "email"},
 				"httpPort":            schema.Integer{Maximum: null.NewInt64(65535), Default: null.NewInt64(80)},
 				"httpsPort":           schema.Integer{Maximum: null.NewInt64(65535), Default: null.NewInt64(443)},
 				"activityPubCache":    DatabaseConnectInfo(),
+				"provisioningToken":   schema.String{MaxLength: 256},
+				"rateLimitStore":      RateLimitStoreSchema(),
+				"rateLimits":          RateLimitSchema(),
 			},
 		},
 	}
@@ -59,6 +63,9 @@ func (config *Config) GetPointer(name string) (any, bool) {
 	case "certificates":
 		return &config.Certificates, true
 
+	case "packages":
+		return &config.Packages, true
+
 	case "debugLevel":
 		return &config.DebugLevel, true
 
@@ -74,6 +81,15 @@ func (config *Config) GetPointer(name string) (any, bool) {
 	case "activityPubCache":
 		return &config.ActivityPubCache, true
 
+	case "provisioningToken":
+		return &config.ProvisioningToken, true
+
+	case "rateLimitStore":
+		return &config.RateLimitStore, true
+
+	case "rateLimits":
+		return &config.RateLimits, true
+
 	}
 
 	return nil, false
@@ -26,6 +26,8 @@ func TestConfig(t *testing.T) {
 		{"domains.0.owner.phoneNumber", "PHONE_NUMBER", nil},
 		{"domains.0.owner.mailingAddress", "MAILING_ADDRESS", nil},
 		{"domains.0.keyEncryptingKey", "12345678901234567890123456789012", nil},
+		{"domains.0.suspended", "true", true},
+		{"provisioningToken", "PROVISIONING_TOKEN", nil},
 
 		{"templates.0.adapter", "S3", nil},
 		{"templates.0.location", "LOCATION", nil},
@@ -45,6 +47,9 @@ func TestConfig(t *testing.T) {
 		{"certificates.bucket", "BUCKET", nil},
 		{"certificates.path", "PATH...", nil},
 
+		{"packages.adapter", "FILE", nil},
+		{"packages.location", "LOCATION", nil},
+
 		{"attachmentOriginals.adapter", "S3", nil},
 		{"attachmentOriginals.location", "LOCATION", nil},
 		{"attachmentOriginals.accessKey", "ACCESS_KEY", nil},
@@ -6,6 +6,15 @@ const StorageTypeMongo = "MONGODB"
 // StorageTypeFile represents a configuration database stored in a JSON file
 const StorageTypeFile = "FILE"
 
+// StorageTypeEnvironment represents a read-only configuration built from environment variables and mounted secrets
+const StorageTypeEnvironment = "ENV"
+
+// SearchEngineMongo represents a full-text search index that uses MongoDB text indexes
+const SearchEngineMongo = "MONGODB"
+
+// SearchEngineEmbedded represents a full-text search index that is stored in memory by Emissary itself
+const SearchEngineEmbedded = "EMBEDDED"
+
 // ConfigSourceCommandLine represents that the config file location was specified via the "--config" command line argument
 const ConfigSourceCommandLine = "COMMAND"
 
@@ -14,3 +23,9 @@ const ConfigSourceEnvironment = "ENVIRONMENT"
 
 // ConfigSourceDefault represents that the config file location was not specified, so the default value of "file://./config.json" was used
 const ConfigSourceDefault = "DEFAULT"
+
+// TemplatePackageSourceGit represents a TemplatePackage that was installed from a Git repository
+const TemplatePackageSourceGit = "GIT"
+
+// TemplatePackageSourceZip represents a TemplatePackage that was installed from an uploaded .zip file
+const TemplatePackageSourceZip = "ZIP"
@@ -17,6 +17,9 @@ type Domain struct {
 	Owner            Owner          `json:"owner"            bson:"owner"`            // Information about the owner of this domain
 	KeyEncryptingKey string         `json:"keyEncryptingKey" bson:"keyEncryptingKey"` // Key used to encrypt/decrypt JWT keys stored in the database
 	CreateOwner      bool           `json:"createOwner"      bson:"createOwner"`      // TRUE if the owner should be created when the domain is created
+	Suspended        bool           `json:"suspended"        bson:"suspended"`        // TRUE if this domain has been suspended, and should not be served
+	SearchEngine     string         `json:"searchEngine"     bson:"searchEngine"`     // Full-text search engine used by this domain (MONGODB or EMBEDDED)
+	StorageQuota     int            `json:"storageQuota"     bson:"storageQuota"`     // Maximum storage (in megabytes) for all attachments on this domain.  Zero means unlimited.
 }
 
 // NewDomain returns a fully initialized Domain object.
@@ -29,6 +32,7 @@ func NewDomain() Domain {
 		DomainID:         primitive.NewObjectID().Hex(),
 		SMTPConnection:   SMTPConnection{},
 		KeyEncryptingKey: keyEncryptingKey,
+		SearchEngine:     SearchEngineMongo,
 	}
 }
 
@@ -2,6 +2,7 @@ package config
 
 import (
 	"github.com/EmissarySocial/emissary/tools/random"
+	"github.com/benpate/rosetta/null"
 	"github.com/benpate/rosetta/schema"
 )
 
@@ -19,6 +20,9 @@ func DomainSchema() schema.Element {
 			"smtp":             SMTPConnectionSchema(),
 			"owner":            OwnerSchema(),
 			"keyEncryptingKey": schema.String{MinLength: 32, MaxLength: 32, Default: keyEncryptingKey},
+			"suspended":        schema.Boolean{},
+			"searchEngine":     schema.String{Enum: []string{SearchEngineMongo, SearchEngineEmbedded}, Default: SearchEngineMongo},
+			"storageQuota":     schema.Integer{Minimum: null.NewInt64(0)},
 		},
 	}
 }
@@ -51,6 +55,15 @@ func (domain *Domain) GetPointer(name string) (any, bool) {
 
 	case "keyEncryptingKey":
 		return &domain.KeyEncryptingKey, true
+
+	case "suspended":
+		return &domain.Suspended, true
+
+	case "searchEngine":
+		return &domain.SearchEngine, true
+
+	case "storageQuota":
+		return &domain.StorageQuota, true
 	}
 
 	return nil, false
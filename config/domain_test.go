@@ -27,6 +27,9 @@ func TestDomainSchema(t *testing.T) {
 		{"owner.phoneNumber", "123-456-7890", nil},
 		{"owner.mailingAddress", "1234 Owner Street, Ownerville, OW 00000", nil},
 		{"keyEncryptingKey", "12345678901234567890123456789012", nil},
+		{"suspended", "true", true},
+		{"searchEngine", "EMBEDDED", nil},
+		{"storageQuota", "1024", 1024},
 	}
 
 	tableTest_Schema(t, &s, &d, table)
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/convert"
+)
+
+// environmentPrefix is prepended to every environment variable that is read into the configuration
+const environmentPrefix = "EMISSARY_"
+
+// environment reads configuration values from environment variables and mounted secrets.
+// Every value NAME is read from the first of these locations that exists:
+// 1) the EMISSARY_NAME environment variable
+// 2) a file named by the EMISSARY_NAME_FILE environment variable
+// 3) a file named EMISSARY_NAME in the directory named by EMISSARY_SECRETS_DIR
+type environment struct {
+	lookup     func(string) (string, bool)
+	secretsDir string
+	err        error
+}
+
+// newEnvironment returns a fully initialized environment that reads values from the provided lookup function
+func newEnvironment(lookup func(string) (string, bool)) *environment {
+
+	result := &environment{
+		lookup: lookup,
+	}
+
+	result.secretsDir, _ = lookup(environmentPrefix + "SECRETS_DIR")
+	return result
+}
+
+// applyEnvironment overwrites the values in a Config with any values found in the environment.
+// Domains and Providers are matched by their IDs, and new records are added for IDs listed
+// in the EMISSARY_DOMAINS and EMISSARY_PROVIDERS variables.
+func applyEnvironment(config *Config, env *environment) error {
+
+	// Server Settings
+	env.setString("ADMIN_EMAIL", &config.AdminEmail)
+	env.setString("DEBUG_LEVEL", &config.DebugLevel)
+	env.setInt("HTTP_PORT", &config.HTTPPort)
+	env.setInt("HTTPS_PORT", &config.HTTPSPort)
+	env.setString("PROVISIONING_TOKEN", &config.ProvisioningToken)
+
+	// Folders are complex values, so they are read as JSON
+	env.setJSON("TEMPLATES", &config.Templates)
+	env.setJSON("ATTACHMENT_ORIGINALS", &config.AttachmentOriginals)
+	env.setJSON("ATTACHMENT_CACHE", &config.AttachmentCache)
+	env.setJSON("EXPORT_CACHE", &config.ExportCache)
+	env.setJSON("CERTIFICATES", &config.Certificates)
+	env.setJSON("PACKAGES", &config.Packages)
+	env.setJSON("ACTIVITYPUB_CACHE", &config.ActivityPubCache)
+	env.setJSON("RATE_LIMIT_STORE", &config.RateLimitStore)
+	env.setJSON("RATE_LIMITS", &config.RateLimits)
+
+	// Domains
+	for _, domainID := range env.ids("DOMAINS", config.DomainIDs()) {
+
+		domain, ok := config.Domains.Get(domainID)
+
+		if !ok {
+			domain = Domain{DomainID: domainID}
+		}
+
+		prefix := "DOMAIN_" + environmentKey(domainID) + "_"
+
+		env.setString(prefix+"LABEL", &domain.Label)
+		env.setString(prefix+"HOSTNAME", &domain.Hostname)
+		env.setString(prefix+"CONNECT_STRING", &domain.ConnectString)
+		env.setString(prefix+"DATABASE_NAME", &domain.DatabaseName)
+		env.setString(prefix+"KEY_ENCRYPTING_KEY", &domain.KeyEncryptingKey)
+		env.setBool(prefix+"CREATE_OWNER", &domain.CreateOwner)
+		env.setBool(prefix+"SUSPENDED", &domain.Suspended)
+		env.setString(prefix+"SEARCH_ENGINE", &domain.SearchEngine)
+		env.setInt(prefix+"STORAGE_QUOTA", &domain.StorageQuota)
+
+		env.setString(prefix+"SMTP_HOSTNAME", &domain.SMTPConnection.Hostname)
+		env.setString(prefix+"SMTP_USERNAME", &domain.SMTPConnection.Username)
+		env.setString(prefix+"SMTP_PASSWORD", &domain.SMTPConnection.Password)
+		env.setInt(prefix+"SMTP_PORT", &domain.SMTPConnection.Port)
+		env.setBool(prefix+"SMTP_TLS", &domain.SMTPConnection.TLS)
+
+		env.setString(prefix+"OWNER_DISPLAY_NAME", &domain.Owner.DisplayName)
+		env.setString(prefix+"OWNER_USERNAME", &domain.Owner.Username)
+		env.setString(prefix+"OWNER_EMAIL_ADDRESS", &domain.Owner.EmailAddress)
+		env.setString(prefix+"OWNER_PHONE_NUMBER", &domain.Owner.PhoneNumber)
+		env.setString(prefix+"OWNER_MAILING_ADDRESS", &domain.Owner.MailingAddress)
+
+		config.Domains.Put(domain)
+	}
+
+	// Providers
+	for _, providerID := range env.ids("PROVIDERS", config.ProviderIDs()) {
+
+		provider, ok := config.Providers.Get(providerID)
+
+		if !ok {
+			provider = NewProvider(providerID)
+		}
+
+		prefix := "PROVIDER_" + environmentKey(providerID) + "_"
+
+		env.setString(prefix+"CLIENT_ID", &provider.ClientID)
+		env.setString(prefix+"CLIENT_SECRET", &provider.ClientSecret)
+
+		config.Providers.Put(provider)
+	}
+
+	if env.err != nil {
+		return derp.Wrap(env.err, "config.applyEnvironment", "Error reading configuration from environment")
+	}
+
+	return nil
+}
+
+// validateEnvironment confirms that a Config built from the environment includes
+// all of the values that cannot be generated automatically.
+func validateEnvironment(config *Config) error {
+
+	const location = "config.validateEnvironment"
+
+	for _, domain := range config.Domains {
+
+		prefix := environmentPrefix + "DOMAIN_" + environmentKey(domain.DomainID) + "_"
+
+		if domain.Hostname == "" {
+			return derp.NewInternalError(location, "Missing required value: "+prefix+"HOSTNAME")
+		}
+
+		if domain.ConnectString == "" {
+			return derp.NewInternalError(location, "Missing required value: "+prefix+"CONNECT_STRING")
+		}
+
+		// The KEK cannot be generated randomly, because it would change every time the server restarts
+		if domain.KeyEncryptingKey == "" {
+			return derp.NewInternalError(location, "Missing required value: "+prefix+"KEY_ENCRYPTING_KEY")
+		}
+	}
+
+	return nil
+}
+
+/******************************************
+ * Value Readers
+ ******************************************/
+
+// get returns the named value from the environment or from a mounted secret
+func (env *environment) get(name string) (string, bool) {
+
+	name = environmentPrefix + name
+
+	if value, ok := env.lookup(name); ok {
+		return value, true
+	}
+
+	if filename, ok := env.lookup(name + "_FILE"); ok {
+		return env.readFile(filename, true)
+	}
+
+	if env.secretsDir != "" {
+		return env.readFile(filepath.Join(env.secretsDir, name), false)
+	}
+
+	return "", false
+}
+
+// readFile returns the contents of a mounted secret.  Missing files are only
+// reported as errors if they have been explicitly requested.
+func (env *environment) readFile(filename string, required bool) (string, bool) {
+
+	data, err := os.ReadFile(filename)
+
+	if err != nil {
+
+		if required || !os.IsNotExist(err) {
+			env.fail(derp.Wrap(err, "config.environment.readFile", "Error reading secret file", filename))
+		}
+
+		return "", false
+	}
+
+	return strings.TrimSpace(string(data)), true
+}
+
+// ids returns a unique list of IDs from the named (comma separated) value, appended to the provided list.
+func (env *environment) ids(name string, existing []string) []string {
+
+	result := existing
+
+	value, _ := env.get(name)
+
+	for _, id := range strings.Split(value, ",") {
+
+		id = strings.TrimSpace(id)
+
+		if id == "" {
+			continue
+		}
+
+		if !slices.Contains(result, id) {
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
+
+func (env *environment) setString(name string, target *string) {
+	if value, ok := env.get(name); ok {
+		*target = value
+	}
+}
+
+func (env *environment) setInt(name string, target *int) {
+
+	value, ok := env.get(name)
+
+	if !ok {
+		return
+	}
+
+	result, err := strconv.Atoi(strings.TrimSpace(value))
+
+	if err != nil {
+		env.fail(derp.Wrap(err, "config.environment.setInt", "Value must be an integer", environmentPrefix+name))
+		return
+	}
+
+	*target = result
+}
+
+func (env *environment) setBool(name string, target *bool) {
+	if value, ok := env.get(name); ok {
+		*target = convert.Bool(strings.TrimSpace(value))
+	}
+}
+
+func (env *environment) setJSON(name string, target any) {
+
+	value, ok := env.get(name)
+
+	if !ok {
+		return
+	}
+
+	if err := json.Unmarshal([]byte(value), target); err != nil {
+		env.fail(derp.Wrap(err, "config.environment.setJSON", "Value must be valid JSON", environmentPrefix+name))
+	}
+}
+
+// fail records the first error encountered while reading the environment
+func (env *environment) fail(err error) {
+	if env.err == nil {
+		env.err = err
+	}
+}
+
+/******************************************
+ * Helper Functions
+ ******************************************/
+
+// environmentKey converts an ID into a value that can be used in an environment variable name
+func environmentKey(id string) string {
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, id)
+}
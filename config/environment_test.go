@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/benpate/derp"
+	"github.com/stretchr/testify/require"
+)
+
+func testEnvironment(values map[string]string) *environment {
+	return newEnvironment(func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	})
+}
+
+func TestEnvironment_Build(t *testing.T) {
+
+	env := testEnvironment(map[string]string{
+		"EMISSARY_HTTP_PORT":                          "8000",
+		"EMISSARY_ADMIN_EMAIL":                        "admin@example.com",
+		"EMISSARY_TEMPLATES":                          `[{"adapter":"FILE","location":"/templates"}]`,
+		"EMISSARY_DOMAINS":                            "main, main,blog.example",
+		"EMISSARY_DOMAIN_MAIN_HOSTNAME":               "example.com",
+		"EMISSARY_DOMAIN_MAIN_CONNECT_STRING":         "mongodb://localhost",
+		"EMISSARY_DOMAIN_MAIN_KEY_ENCRYPTING_KEY":     "KEK",
+		"EMISSARY_DOMAIN_MAIN_SMTP_PORT":              "587",
+		"EMISSARY_DOMAIN_MAIN_SMTP_TLS":               "true",
+		"EMISSARY_DOMAIN_BLOG_EXAMPLE_HOSTNAME":       "blog.example",
+		"EMISSARY_PROVIDERS":                          "GIPHY",
+		"EMISSARY_PROVIDER_GIPHY_CLIENT_ID":           "CLIENT_ID",
+		"EMISSARY_PROVIDER_GIPHY_CLIENT_SECRET":       "CLIENT_SECRET",
+		"EMISSARY_DOMAIN_UNLISTED_CONNECT_STRING":     "ignored",
+		"EMISSARY_DOMAIN_MAIN_OWNER_EMAIL_ADDRESS":    "owner@example.com",
+		"EMISSARY_DOMAIN_BLOG_EXAMPLE_CONNECT_STRING": "mongodb://blog",
+	})
+
+	config := DefaultConfig()
+	require.Nil(t, applyEnvironment(&config, env))
+
+	require.Equal(t, 8000, config.HTTPPort)
+	require.Equal(t, 443, config.HTTPSPort)
+	require.Equal(t, "admin@example.com", config.AdminEmail)
+	require.Equal(t, "/templates", config.Templates[0]["location"])
+	require.Equal(t, 2, len(config.Domains))
+
+	main, ok := config.Domains.Get("main")
+	require.True(t, ok)
+	require.Equal(t, "example.com", main.Hostname)
+	require.Equal(t, "KEK", main.KeyEncryptingKey)
+	require.Equal(t, 587, main.SMTPConnection.Port)
+	require.True(t, main.SMTPConnection.TLS)
+	require.Equal(t, "owner@example.com", main.Owner.EmailAddress)
+
+	blog, ok := config.Domains.Get("blog.example")
+	require.True(t, ok)
+	require.Equal(t, "mongodb://blog", blog.ConnectString)
+
+	provider, ok := config.Providers.Get("GIPHY")
+	require.True(t, ok)
+	require.Equal(t, "CLIENT_SECRET", provider.ClientSecret)
+
+	// The "blog" domain does not have a KEK
+	require.NotNil(t, validateEnvironment(&config))
+}
+
+func TestEnvironment_Overlay(t *testing.T) {
+
+	config := NewConfig()
+	config.Domains.Put(Domain{DomainID: "abc123", Hostname: "example.com", ConnectString: "mongodb://localhost"})
+
+	env := testEnvironment(map[string]string{
+		"EMISSARY_DOMAIN_ABC123_SMTP_PASSWORD":      "SECRET",
+		"EMISSARY_DOMAIN_ABC123_KEY_ENCRYPTING_KEY": "KEK",
+	})
+
+	require.Nil(t, applyEnvironment(&config, env))
+	require.Equal(t, 1, len(config.Domains))
+
+	domain, _ := config.Domains.Get("abc123")
+	require.Equal(t, "example.com", domain.Hostname)
+	require.Equal(t, "SECRET", domain.SMTPConnection.Password)
+	require.Nil(t, validateEnvironment(&config))
+}
+
+func TestEnvironment_Secrets(t *testing.T) {
+
+	directory := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(directory, "password"), []byte("FROM_FILE\n"), 0600))
+	require.Nil(t, os.WriteFile(filepath.Join(directory, "EMISSARY_DOMAIN_MAIN_KEY_ENCRYPTING_KEY"), []byte("FROM_DIRECTORY"), 0600))
+
+	env := testEnvironment(map[string]string{
+		"EMISSARY_SECRETS_DIR":                    directory,
+		"EMISSARY_DOMAINS":                        "main",
+		"EMISSARY_DOMAIN_MAIN_SMTP_PASSWORD_FILE": filepath.Join(directory, "password"),
+	})
+
+	config := NewConfig()
+	require.Nil(t, applyEnvironment(&config, env))
+
+	domain, _ := config.Domains.Get("main")
+	require.Equal(t, "FROM_FILE", domain.SMTPConnection.Password)
+	require.Equal(t, "FROM_DIRECTORY", domain.KeyEncryptingKey)
+}
+
+func TestEnvironment_Errors(t *testing.T) {
+
+	{
+		env := testEnvironment(map[string]string{"EMISSARY_HTTP_PORT": "eighty"})
+		config := NewConfig()
+		require.NotNil(t, applyEnvironment(&config, env))
+	}
+
+	{
+		env := testEnvironment(map[string]string{"EMISSARY_ADMIN_EMAIL_FILE": "/does/not/exist"})
+		config := NewConfig()
+		require.NotNil(t, applyEnvironment(&config, env))
+	}
+}
+
+func TestReadOnlyStorage_Write(t *testing.T) {
+
+	storage := EnvironmentStorage{location: "env://"}
+	err := storage.Write(NewConfig())
+	require.NotNil(t, err)
+	require.Equal(t, 403, derp.ErrorCode(err))
+
+	file := FileStorage{location: filepath.Join(t.TempDir(), "config.json"), readOnly: true}
+	require.NotNil(t, file.Write(NewConfig()))
+
+	_, statError := os.Stat(file.location)
+	require.True(t, os.IsNotExist(statError))
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"github.com/EmissarySocial/emissary/tools/ratelimit"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/schema"
+)
+
+// Classes of routes that share a rate limit
+const (
+	RateLimitSignIn        = "signin"        // Sign-in forms, two-factor codes, and passkeys
+	RateLimitPasswordReset = "passwordReset" // Password reset emails and codes
+	RateLimitRegistration  = "registration"  // New account registrations
+	RateLimitWebMention    = "webmention"    // Incoming WebMentions
+	RateLimitWebSub        = "websub"        // WebSub subscription requests and content notifications
+	RateLimitSavedSearch   = "savedSearch"   // New SavedSearches created by visitors
+	RateLimitActivityPub   = "activitypub"   // ActivityPub inbox POSTs, counted by the (verified) domain of the sender
+	RateLimitActivityPubIP = "activitypubIP" // ActivityPub inbox POSTs, counted by IP address before signatures are verified
+)
+
+// DefaultRateLimits returns the rate limit for each class of routes, using the format
+// read by ratelimit.ParseRule.  These can be overridden by the "rateLimits" setting.
+func DefaultRateLimits() mapof.String {
+	return mapof.String{
+		RateLimitSignIn:        "10/5m",
+		RateLimitPasswordReset: "5/1h",
+		RateLimitRegistration:  "5/1h",
+		RateLimitWebMention:    "30/1m",
+		RateLimitWebSub:        "30/1m",
+		RateLimitSavedSearch:   "20/24h",
+		RateLimitActivityPub:   "300/1m",
+		RateLimitActivityPubIP: "600/1m",
+	}
+}
+
+// RateLimitSchema returns the schema for the "rateLimits" setting
+func RateLimitSchema() schema.Element {
+	return schema.Object{
+		Wildcard: schema.String{MaxLength: 20},
+	}
+}
+
+// RateLimitStoreSchema returns the schema for the "rateLimitStore" setting
+func RateLimitStoreSchema() schema.Element {
+	return schema.Object{
+		Properties: schema.ElementMap{
+			"adapter":  schema.String{Enum: []string{"MEMORY", "REDIS"}, Default: "MEMORY"},
+			"location": schema.String{},
+		},
+	}
+}
+
+// RateLimitRules returns the Rule for each class of routes, combining the
+// default limits with any that are overridden in this configuration.
+func (config Config) RateLimitRules() (map[string]ratelimit.Rule, error) {
+
+	values := DefaultRateLimits()
+
+	for class, value := range config.RateLimits {
+		values[class] = value
+	}
+
+	result := make(map[string]ratelimit.Rule, len(values))
+
+	for class, value := range values {
+
+		rule, err := ratelimit.ParseRule(value)
+
+		if err != nil {
+			return nil, derp.Wrap(err, "config.Config.RateLimitRules", "Invalid rate limit", class)
+		}
+
+		result[class] = rule
+	}
+
+	return result, nil
+}
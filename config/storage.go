@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 
+	"github.com/benpate/derp"
 	"github.com/rs/zerolog/log"
 )
 
@@ -23,11 +24,20 @@ func Load(args *CommandLineArgs) Storage {
 	case StorageTypeFile:
 		log.Info().Msg("Loading server config from file: " + args.Location)
 		return NewFileStorage(args)
+
+	case StorageTypeEnvironment:
+		log.Info().Msg("Loading server config from environment variables")
+		return NewEnvironmentStorage(args)
 	}
 
 	// Failure
-	log.Error().Msg("Invalid configuration location.  Must be file:// or mongodb:// or mongodb+srv://")
+	log.Error().Msg("Invalid configuration location.  Must be file:// or env:// or mongodb:// or mongodb+srv://")
 	os.Exit(1)
 
 	return nil
 }
+
+// NewReadOnlyError returns the error reported when a read-only configuration is changed
+func NewReadOnlyError(location string, details ...any) error {
+	return derp.NewForbiddenError(location, "Configuration is read-only.  Update the configuration source (environment, secrets, or file) and restart Emissary.", details...)
+}
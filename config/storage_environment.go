@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+
+	"github.com/benpate/derp"
+	"github.com/rs/zerolog/log"
+)
+
+// EnvironmentStorage is a read-only storage engine that builds the server configuration
+// from environment variables and mounted secrets.
+type EnvironmentStorage struct {
+	source        string
+	location      string
+	updateChannel chan Config
+}
+
+// NewEnvironmentStorage creates a fully initialized EnvironmentStorage instance
+func NewEnvironmentStorage(args *CommandLineArgs) EnvironmentStorage {
+
+	storage := EnvironmentStorage{
+		source:        args.Source,
+		location:      args.Location,
+		updateChannel: make(chan Config, 1),
+	}
+
+	config, err := storage.load()
+
+	// The environment cannot be fixed by the setup console, so any error is a catastrophic failure.
+	if err != nil {
+		derp.Report(err)
+		log.Error().Msg("FATAL: Emissary could not start because the configuration could not be read from the environment.")
+		log.Error().Msg(derp.Message(err))
+		os.Exit(1)
+	}
+
+	// Post the config to the update channel.  The environment does not change
+	// while the server is running, so this is the only update.
+	storage.updateChannel <- config
+
+	return storage
+}
+
+// Subscribe returns a channel that will receive the configuration every time it is updated
+func (storage EnvironmentStorage) Subscribe() <-chan Config {
+	return storage.updateChannel
+}
+
+// load builds the configuration from the default values, overwritten by the environment
+func (storage EnvironmentStorage) load() (Config, error) {
+
+	const location = "config.EnvironmentStorage.load"
+
+	result := DefaultConfig()
+
+	if err := applyEnvironment(&result, newEnvironment(os.LookupEnv)); err != nil {
+		return Config{}, derp.Wrap(err, location, "Error reading configuration")
+	}
+
+	if err := validateEnvironment(&result); err != nil {
+		return Config{}, derp.Wrap(err, location, "Invalid configuration")
+	}
+
+	result.Source = storage.source
+	result.Location = storage.location
+	result.ReadOnly = true
+
+	return result, nil
+}
+
+// Write always returns an error because the environment cannot be changed by Emissary
+func (storage EnvironmentStorage) Write(config Config) error {
+	return NewReadOnlyError("config.EnvironmentStorage.Write", storage.location)
+}
@@ -15,6 +15,7 @@ import (
 type FileStorage struct {
 	source        string
 	location      string
+	readOnly      bool
 	updateChannel chan Config
 }
 
@@ -27,6 +28,7 @@ func NewFileStorage(args *CommandLineArgs) FileStorage {
 	storage := FileStorage{
 		source:        args.Source,
 		location:      fileLocation,
+		readOnly:      args.ReadOnly,
 		updateChannel: make(chan Config, 1),
 	}
 
@@ -41,6 +43,12 @@ func NewFileStorage(args *CommandLineArgs) FileStorage {
 	// If the config was not found, then run in setup mode and create a new default configuration
 	case derp.NotFound(err):
 
+		if storage.readOnly {
+			log.Error().Msg("Emissary could not start because the read-only configuration file could not be found.")
+			log.Error().Msg("Check the file in location: " + fileLocation)
+			os.Exit(1)
+		}
+
 		if !args.Setup {
 			log.Error().Msg("Emissary could not start because the configuration file could not be found.")
 			log.Error().Msg("Please re-run Emissary with the --setup flag to create a new configuration file.")
@@ -117,8 +125,8 @@ func (storage FileStorage) Subscribe() <-chan Config {
 	return storage.updateChannel
 }
 
-// load reads the configuration from the filesystem and
-// creates a default configuration if the file is missing
+// load reads the configuration from the filesystem.  Read-only configurations
+// also include any secrets provided by environment variables or mounted files.
 func (storage FileStorage) load() (Config, error) {
 
 	result := NewConfig()
@@ -134,8 +142,17 @@ func (storage FileStorage) load() (Config, error) {
 		return Config{}, derp.NewInternalError("config.FileStorage.load", "Error unmarshaling configuration", derp.WithWrappedValue(err))
 	}
 
+	// Read-only configurations are never written back to disk,
+	// so it is safe to merge secrets from the environment.
+	if storage.readOnly {
+		if err := applyEnvironment(&result, newEnvironment(os.LookupEnv)); err != nil {
+			return Config{}, derp.Wrap(err, "config.FileStorage.load", "Error reading configuration from environment")
+		}
+	}
+
 	result.Source = storage.source
 	result.Location = storage.location
+	result.ReadOnly = storage.readOnly
 
 	return result, nil
 }
@@ -143,6 +160,11 @@ func (storage FileStorage) load() (Config, error) {
 // Write writes the configuration to the filesystem
 func (storage FileStorage) Write(config Config) error {
 
+	// Read-only files cannot be changed by the server
+	if storage.readOnly {
+		return NewReadOnlyError("config.FileStorage.Write", storage.location)
+	}
+
 	// Marshal the configuration to JSON
 	data, err := json.MarshalIndent(config, "", "    ")
 
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+)
+
+// TemplatePackage is a bundle of templates that has been installed on this server from a Git repository or a .zip file
+type TemplatePackage struct {
+	PackageID     string                                 `json:"packageId"     bson:"packageId"`     // Unique identifier for this package (also the folder name where it is installed)
+	Source        string                                 `json:"source"        bson:"source"`        // Where this package comes from (GIT, ZIP)
+	URL           string                                 `json:"url"           bson:"url"`           // Git repository URL (GIT packages only)
+	ActiveVersion string                                 `json:"activeVersion" bson:"activeVersion"` // Version that is currently being served
+	Versions      sliceof.Object[TemplatePackageVersion] `json:"versions"      bson:"versions"`      // All versions that are installed on this server, oldest first
+}
+
+// TemplatePackageVersion is a single installed version of a TemplatePackage
+type TemplatePackageVersion struct {
+	Version     string `json:"version"     bson:"version"`     // Resolved version identifier (Git commit hash or .zip checksum)
+	Ref         string `json:"ref"         bson:"ref"`         // Git tag, branch, or commit that was requested when installing (GIT packages only)
+	Location    string `json:"location"    bson:"location"`    // Local folder where this version is unpacked
+	InstallDate int64  `json:"installDate" bson:"installDate"` // Unix epoch (seconds) when this version was installed
+}
+
+// NewTemplatePackage returns a fully initialized TemplatePackage
+func NewTemplatePackage(packageID string) TemplatePackage {
+	return TemplatePackage{
+		PackageID: packageID,
+		Versions:  sliceof.NewObject[TemplatePackageVersion](),
+	}
+}
+
+// ID implements the set.Value interface
+func (templatePackage TemplatePackage) ID() string {
+	return templatePackage.PackageID
+}
+
+// Version returns the installed version with the provided identifier
+func (templatePackage TemplatePackage) Version(version string) (TemplatePackageVersion, bool) {
+
+	for _, item := range templatePackage.Versions {
+		if item.Version == version {
+			return item, true
+		}
+	}
+
+	return TemplatePackageVersion{}, false
+}
+
+// Active returns the version that is currently being served
+func (templatePackage TemplatePackage) Active() (TemplatePackageVersion, bool) {
+	return templatePackage.Version(templatePackage.ActiveVersion)
+}
+
+// Previous returns the installed version immediately before the active version,
+// which is the version that a rollback will restore.
+func (templatePackage TemplatePackage) Previous() (TemplatePackageVersion, bool) {
+
+	for index, item := range templatePackage.Versions {
+		if item.Version == templatePackage.ActiveVersion {
+			if index > 0 {
+				return templatePackage.Versions[index-1], true
+			}
+			break
+		}
+	}
+
+	return TemplatePackageVersion{}, false
+}
+
+// AddVersion adds (or replaces) an installed version, keeping the list in install order
+func (templatePackage *TemplatePackage) AddVersion(version TemplatePackageVersion) {
+
+	result := sliceof.NewObject[TemplatePackageVersion]()
+
+	for _, item := range templatePackage.Versions {
+		if item.Version != version.Version {
+			result = append(result, item)
+		}
+	}
+
+	templatePackage.Versions = append(result, version)
+}
+
+// RemoveVersion removes an installed version.  The active version cannot be removed.
+func (templatePackage *TemplatePackage) RemoveVersion(version string) bool {
+
+	if version == templatePackage.ActiveVersion {
+		return false
+	}
+
+	result := sliceof.NewObject[TemplatePackageVersion]()
+	found := false
+
+	for _, item := range templatePackage.Versions {
+		if item.Version == version {
+			found = true
+			continue
+		}
+		result = append(result, item)
+	}
+
+	templatePackage.Versions = result
+	return found
+}
+
+// Folder returns the readable folder definition for the active version of this package
+func (templatePackage TemplatePackage) Folder() (mapof.String, bool) {
+
+	if active, ok := templatePackage.Active(); ok {
+		return mapof.String{"adapter": FolderAdapterFile, "location": active.Location}, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplatePackage_Versions(t *testing.T) {
+
+	templatePackage := NewTemplatePackage("blog")
+
+	// Nothing is active until a version is installed
+	_, ok := templatePackage.Folder()
+	require.False(t, ok)
+
+	templatePackage.AddVersion(TemplatePackageVersion{Version: "v1", Location: "/packages/blog/v1"})
+	templatePackage.AddVersion(TemplatePackageVersion{Version: "v2", Location: "/packages/blog/v2"})
+	templatePackage.ActiveVersion = "v2"
+
+	folder, ok := templatePackage.Folder()
+	require.True(t, ok)
+	require.Equal(t, mapof.String{"adapter": FolderAdapterFile, "location": "/packages/blog/v2"}, folder)
+
+	// Rollback target is the version installed before the active one
+	previous, ok := templatePackage.Previous()
+	require.True(t, ok)
+	require.Equal(t, "v1", previous.Version)
+
+	// The active version cannot be removed
+	require.False(t, templatePackage.RemoveVersion("v2"))
+	require.True(t, templatePackage.RemoveVersion("v1"))
+	require.False(t, templatePackage.RemoveVersion("v1"))
+
+	_, ok = templatePackage.Previous()
+	require.False(t, ok)
+}
+
+func TestTemplatePackage_AddVersion_Reinstall(t *testing.T) {
+
+	templatePackage := NewTemplatePackage("blog")
+	templatePackage.AddVersion(TemplatePackageVersion{Version: "v1"})
+	templatePackage.AddVersion(TemplatePackageVersion{Version: "v2"})
+	templatePackage.AddVersion(TemplatePackageVersion{Version: "v1", Ref: "main"})
+
+	// Reinstalling a version moves it to the end of the list
+	require.Len(t, templatePackage.Versions, 2)
+	require.Equal(t, "v2", templatePackage.Versions[0].Version)
+	require.Equal(t, "main", templatePackage.Versions[1].Ref)
+}
+
+func TestConfig_TemplateLocations(t *testing.T) {
+
+	templatePackage := NewTemplatePackage("blog")
+	templatePackage.AddVersion(TemplatePackageVersion{Version: "v1", Location: "/packages/blog/v1"})
+	templatePackage.ActiveVersion = "v1"
+
+	inactive := NewTemplatePackage("inactive")
+
+	config := NewConfig()
+	config.Templates = sliceof.Object[mapof.String]{{"adapter": "EMBED", "location": "templates"}}
+	config.TemplatePackages = append(config.TemplatePackages, templatePackage, inactive)
+
+	require.Equal(t, sliceof.Object[mapof.String]{
+		{"adapter": "EMBED", "location": "templates"},
+		{"adapter": FolderAdapterFile, "location": "/packages/blog/v1"},
+	}, config.TemplateLocations())
+}
@@ -1,6 +1,8 @@
 package consumer
 
 import (
+	"time"
+
 	"github.com/EmissarySocial/emissary/domain"
 	"github.com/EmissarySocial/emissary/model"
 	"github.com/benpate/derp"
@@ -11,16 +13,35 @@ import (
 
 // AddSearchResult is a queue consumer that adds a new SearchResult to the database,
 // and sends an ActivityPub message to all followers who are listening for the same set of tags.
+// New SearchResults are also announced to the followers of every SavedSearch that they match.
 func AddSearchResult(factory *domain.Factory, args mapof.Any) queue.Result {
 
 	const location = "consumer.AddSearchResult"
 
+	searchResult := factory.Search().UnmarshalMap(args)
+
+	if err := addSearchResult(factory, searchResult); err != nil {
+		return queue.Error(derp.Wrap(err, location, "Error adding search result"))
+	}
+
+	return queue.Success()
+}
+
+// addSearchResult inserts/updates a SearchResult in the database, then announces it to
+// tag followers and (if it is new) to the followers of every matching SavedSearch
+func addSearchResult(factory *domain.Factory, searchResult model.SearchResult) error {
+
+	const location = "consumer.addSearchResult"
+
 	// Insert/Update the SearchResult in the database
 	searchService := factory.Search()
-	searchResult := searchService.UnmarshalMap(args)
+
+	// Determine if this is a new SearchResult before saving it
+	existing := model.NewSearchResult()
+	isNew := derp.NotFound(searchService.LoadByURL(searchResult.URL, &existing))
 
 	if err := searchService.Upsert(searchResult); err != nil {
-		return queue.Error(derp.Wrap(err, location, "Error saving search result"))
+		return derp.Wrap(err, location, "Error saving search result")
 	}
 
 	// Get All Followers who match this SearchResult
@@ -28,7 +49,7 @@ func AddSearchResult(factory *domain.Factory, args mapof.Any) queue.Result {
 	followers, err := followerService.RangeByTags(searchResult.TagValues...)
 
 	if err != nil {
-		return queue.Error(derp.Wrap(err, location, "Error loading followers"))
+		return derp.Wrap(err, location, "Error loading followers")
 	}
 
 	// Send Task to the Queue for each Follower
@@ -49,9 +70,74 @@ func AddSearchResult(factory *domain.Factory, args mapof.Any) queue.Result {
 		})
 
 		if err := q.Publish(task); err != nil {
-			return queue.Error(derp.Wrap(err, location, "Error sending message to queue"))
+			return derp.Wrap(err, location, "Error sending message to queue")
 		}
 	}
 
-	return queue.Success()
+	// RULE: Only announce new SearchResults to SavedSearch followers
+	if !isNew {
+		return nil
+	}
+
+	// Announce the SearchResult from every SavedSearch that it matches
+	savedSearchService := factory.SavedSearch()
+	savedSearches, err := savedSearchService.RangeMatches(&searchResult)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading saved searches")
+	}
+
+	outboxService := factory.Outbox()
+
+	for savedSearch := range savedSearches {
+
+		actor, err := savedSearchService.ActivityPubActor(&savedSearch, true)
+
+		if err != nil {
+			derp.Report(derp.Wrap(err, location, "Error loading saved search actor", savedSearch.SavedSearchID))
+			continue
+		}
+
+		// Publish sends the activity to ActivityPub, WebSub, and Email followers
+		activity := addSearchResult_Announce(&savedSearch, &searchResult)
+
+		if err := outboxService.Publish(&actor, model.FollowerTypeSearch, savedSearch.SavedSearchID, activity); err != nil {
+			derp.Report(derp.Wrap(err, location, "Error publishing saved search result", savedSearch.SavedSearchID))
+		}
+	}
+
+	return nil
+}
+
+// addSearchResult_Announce returns an "Announce" activity that shares a SearchResult
+// with the followers of a SavedSearch
+func addSearchResult_Announce(savedSearch *model.SavedSearch, searchResult *model.SearchResult) mapof.Any {
+
+	object := mapof.Any{
+		vocab.PropertyID:   searchResult.URL,
+		vocab.PropertyType: searchResult.Type,
+		vocab.PropertyURL:  searchResult.URL,
+	}
+
+	if searchResult.Name != "" {
+		object[vocab.PropertyName] = searchResult.Name
+	}
+
+	if searchResult.Summary != "" {
+		object[vocab.PropertyContent] = searchResult.Summary
+	}
+
+	if searchResult.IconURL != "" {
+		object[vocab.PropertyIcon] = searchResult.IconURL
+	}
+
+	return mapof.Any{
+		vocab.AtContext:         vocab.ContextTypeActivityStreams,
+		vocab.PropertyID:        savedSearch.URL + "/announce/" + searchResult.SearchResultID.Hex(),
+		vocab.PropertyType:      vocab.ActivityTypeAnnounce,
+		vocab.PropertyActor:     savedSearch.ActivityPubURL(),
+		vocab.PropertyObject:    object,
+		vocab.PropertyTo:        []string{savedSearch.ActivityPubFollowersURL()},
+		vocab.PropertyPublished: time.Now().UTC().Format(time.RFC3339),
+	}
 }
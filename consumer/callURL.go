@@ -0,0 +1,102 @@
+package consumer
+
+import (
+	"time"
+
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/callurl"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/turbine/queue"
+)
+
+// CallURL sends an HTTP request that was queued by the "call-url" step.  Secret
+// headers are read from the Domain when the task runs, and values from the
+// response are saved into the Stream (if requested).
+func CallURL(factory *domain.Factory, args mapof.Any) queue.Result {
+
+	const location = "consumer.CallURL"
+
+	domainModel := factory.Domain().Get()
+
+	// Assemble the request, adding secret headers from the Domain
+	headersArg := args.GetMap("headers")
+	headers := make(map[string]string, len(headersArg))
+
+	for name := range headersArg {
+		headers[name] = headersArg.GetString(name)
+	}
+
+	secretsArg := args.GetMap("secrets")
+	secrets := make(map[string]string, len(secretsArg))
+
+	for name := range secretsArg {
+		secrets[name] = secretsArg.GetString(name)
+	}
+
+	secretHeaders, err := callurl.SecretHeaders(secrets, domainModel.Secrets)
+
+	if err != nil {
+		return queue.Failure(derp.Wrap(err, location, "Error reading secrets"))
+	}
+
+	for name, value := range secretHeaders {
+		headers[name] = value
+	}
+
+	request := callurl.Request{
+		Method:       args.GetString("method"),
+		URL:          args.GetString("url"),
+		Body:         args.GetString("body"),
+		Headers:      headers,
+		Timeout:      time.Duration(args.GetInt("timeout")) * time.Second,
+		AllowedHosts: domainModel.OutboundHostnames(),
+	}
+
+	response, err := request.Send()
+
+	if err != nil {
+
+		// Requests that were refused (or rejected by the remote server) will never succeed
+		if derp.IsClientError(err) || (response.StatusCode >= 400 && response.StatusCode < 500) {
+			return queue.Failure(derp.Wrap(err, location, "Error calling URL", request.URL))
+		}
+
+		return queue.Error(derp.Wrap(err, location, "Error calling URL", request.URL))
+	}
+
+	// Save response values into the Stream
+	streamData := args.GetMap("streamData")
+	streamID := args.GetString("streamId")
+
+	if (len(streamData) == 0) || (streamID == "") {
+		return queue.Success()
+	}
+
+	streamService := factory.Stream()
+	stream := model.NewStream()
+
+	if err := streamService.LoadByToken(streamID, &stream); err != nil {
+		return queue.Error(derp.Wrap(err, location, "Cannot load stream", streamID))
+	}
+
+	template, err := factory.Template().Load(stream.TemplateID)
+
+	if err != nil {
+		return queue.Failure(derp.Wrap(err, location, "Cannot load template", stream.TemplateID))
+	}
+
+	for path := range streamData {
+		responsePath := streamData.GetString(path)
+		if err := template.Schema.Set(&stream, path, response.Get(responsePath)); err != nil {
+			return queue.Failure(derp.Wrap(err, location, "Error setting value from response", path, responsePath))
+		}
+	}
+
+	if err := streamService.Save(&stream, "Updated by call-url"); err != nil {
+		return queue.Error(derp.Wrap(err, location, "Error saving stream", streamID))
+	}
+
+	return queue.Success()
+}
@@ -25,6 +25,9 @@ func (consumer Consumer) Run(name string, args map[string]any) queue.Result {
 	case "AddSearchResult":
 		return WithFactory(consumer.serverFactory, args, AddSearchResult)
 
+	case "CallURL":
+		return WithFactory(consumer.serverFactory, args, CallURL)
+
 	case "CreateWebSubFollower":
 		return WithFactory(consumer.serverFactory, args, CreateWebSubFollower)
 
@@ -34,9 +37,15 @@ func (consumer Consumer) Run(name string, args map[string]any) queue.Result {
 	case "IndexAllUsers":
 		return WithFactory(consumer.serverFactory, args, IndexAllUsers)
 
+	case "IndexRemoteDocument":
+		return WithFactory(consumer.serverFactory, args, IndexRemoteDocument)
+
 	case "MakeStreamArchive":
 		return WithStream(consumer.serverFactory, args, MakeStreamArchive)
 
+	case "PingWebSubHubs":
+		return WithFactory(consumer.serverFactory, args, PingWebSubHubs)
+
 	case "ProcessMedia":
 		return WithFactory(consumer.serverFactory, args, ProcessMedia)
 
@@ -46,11 +55,14 @@ func (consumer Consumer) Run(name string, args map[string]any) queue.Result {
 	case "SendActivityPubMessage":
 		return WithFactory(consumer.serverFactory, args, SendActivityPubMessage)
 
+	case "SendSalmention":
+		return WithStream(consumer.serverFactory, args, SendSalmention)
+
 	case "SendWebMention":
 		return SendWebMention(args)
 
 	case "SendWebSubMessage":
-		return SendWebSubMessage(args)
+		return WithFactory(consumer.serverFactory, args, SendWebSubMessage)
 
 	case "stream.syndicate", "stream.syndicate.undo":
 		return StreamSyndicate(name, args)
@@ -0,0 +1,65 @@
+package consumer
+
+import (
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/turbine/queue"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IndexRemoteDocument is a queue consumer that adds a public document from another server
+// (received via an inbox or relay) to the search index.  Documents are only indexed if
+// their authors allow it, and if they are not blocked by this domain.
+func IndexRemoteDocument(factory *domain.Factory, args mapof.Any) queue.Result {
+
+	const location = "consumer.IndexRemoteDocument"
+
+	documentURL := args.GetString("url")
+	searchService := factory.Search()
+
+	// Load the document (usually from the cache) to find its author
+	document, err := factory.ActivityStream().Load(documentURL)
+
+	if err != nil {
+		return queue.Error(derp.Wrap(err, location, "Error loading document", documentURL))
+	}
+
+	authorID := document.AttributedTo().ID()
+
+	if document.IsActor() {
+		authorID = document.ID()
+	}
+
+	// RULE: Do not index documents from people or servers that this domain has blocked
+	ruleFilter := factory.Rule().Filter(primitive.NilObjectID, service.WithBlocksOnly())
+
+	if !ruleFilter.AllowSend(authorID) {
+		return queue.Success()
+	}
+
+	// Convert the document into a SearchResult
+	searchResult, indexable, err := searchService.RemoteSearchResult(documentURL)
+
+	if err != nil {
+		return queue.Error(derp.Wrap(err, location, "Error reading remote document", documentURL))
+	}
+
+	// If the document can no longer be indexed, then remove it from the index
+	if !indexable {
+
+		if err := searchService.DeleteRemoteByURL(documentURL); err != nil {
+			return queue.Error(derp.Wrap(err, location, "Error removing remote document", documentURL))
+		}
+
+		return queue.Success()
+	}
+
+	// Add the document to the index and notify followers
+	if err := addSearchResult(factory, searchResult); err != nil {
+		return queue.Error(derp.Wrap(err, location, "Error adding search result", documentURL))
+	}
+
+	return queue.Success()
+}
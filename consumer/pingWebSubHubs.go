@@ -0,0 +1,46 @@
+package consumer
+
+import (
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/benpate/derp"
+	"github.com/benpate/remote"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/turbine/queue"
+)
+
+// PingWebSubHubs notifies every external WebSub hub configured for this domain
+// that a feed has changed, so that the hub can distribute it to its own subscribers.
+// https://www.w3.org/TR/websub/#publishing
+func PingWebSubHubs(factory *domain.Factory, args mapof.Any) queue.Result {
+
+	const location = "consumer.PingWebSubHubs"
+
+	parentType := args.GetString("parentType")
+	parentID := args.GetString("parentId")
+
+	// Load the feed that has changed
+	topic, err := loadWebSubTopic(factory, parentType, parentID)
+
+	if err != nil {
+
+		if derp.NotFound(err) {
+			return queue.Success()
+		}
+
+		return queue.Error(derp.Wrap(err, location, "Error loading WebSub topic", parentType, parentID))
+	}
+
+	// Notify each external hub.  Hubs ignore duplicate pings, so it is safe to retry them all.
+	for _, hub := range factory.Domain().Get().WebSubHubURLs() {
+
+		transaction := remote.Post(hub).
+			Form("hub.mode", "publish").
+			Form("hub.url", topic.FeedURL)
+
+		if err := transaction.Send(); err != nil {
+			return queue.Error(derp.Wrap(err, location, "Error pinging WebSub hub", hub, topic.FeedURL))
+		}
+	}
+
+	return queue.Success()
+}
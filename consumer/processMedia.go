@@ -2,11 +2,52 @@ package consumer
 
 import (
 	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
 	"github.com/benpate/rosetta/mapof"
 	"github.com/benpate/turbine/queue"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// ProcessMedia is a queue consumer that prepares a newly uploaded Attachment.  It removes
+// private metadata, records real dimensions/durations, calculates a blurhash, and
+// pre-renders the formats allowed by the Attachment's rules.
 func ProcessMedia(factory *domain.Factory, args mapof.Any) queue.Result {
 
+	const location = "consumer.ProcessMedia"
+
+	objectType := args.GetString("objectType")
+
+	objectID, err := primitive.ObjectIDFromHex(args.GetString("objectId"))
+
+	if err != nil {
+		return queue.Failure(derp.Wrap(err, location, "Invalid objectId", args))
+	}
+
+	attachmentID, err := primitive.ObjectIDFromHex(args.GetString("attachmentId"))
+
+	if err != nil {
+		return queue.Failure(derp.Wrap(err, location, "Invalid attachmentId", args))
+	}
+
+	// Load the Attachment
+	attachmentService := factory.Attachment()
+	attachment := model.NewAttachment(objectType, objectID)
+
+	if err := attachmentService.LoadByID(objectType, objectID, attachmentID, &attachment); err != nil {
+
+		// If the Attachment has been deleted already, then there's nothing to do.
+		if derp.NotFound(err) {
+			return queue.Success()
+		}
+
+		return queue.Error(derp.Wrap(err, location, "Error loading Attachment", args))
+	}
+
+	// Process the media file
+	if err := attachmentService.ProcessMedia(&attachment); err != nil {
+		return queue.Error(derp.Wrap(err, location, "Error processing media", args))
+	}
+
 	return queue.Success()
 }
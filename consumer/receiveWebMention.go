@@ -2,6 +2,7 @@ package consumer
 
 import (
 	"bytes"
+	"net/http"
 
 	"github.com/EmissarySocial/emissary/domain"
 	"github.com/EmissarySocial/emissary/model"
@@ -19,21 +20,17 @@ func ReceiveWebMention(factory *domain.Factory, args mapof.Any) queue.Result {
 	mentionService := factory.Mention()
 	source := args.GetString("source")
 	target := args.GetString("target")
-
-	// Validate that the WebMention source actually links to the targetURL
-	var content bytes.Buffer
-	if err := mentionService.Verify(source, target, &content); err != nil {
-		return queue.Error(derp.Wrap(err, location, "Source does not link to target", source, target))
-	}
+	vouch := args.GetString("vouch")
 
 	// Parse the target URL into an object type and token
 	objectType, token, err := mentionService.ParseURL(target)
 
 	if err != nil {
-		return queue.Error(derp.Wrap(err, location, "Error parsing URL", target))
+		return queue.Failure(derp.Wrap(err, location, "Error parsing URL", target))
 	}
 
 	var objectID primitive.ObjectID
+	var userID primitive.ObjectID
 
 	// Validate the internal record that the mention is pointing to
 	switch objectType {
@@ -45,6 +42,7 @@ func ReceiveWebMention(factory *domain.Factory, args mapof.Any) queue.Result {
 			return queue.Error(derp.Wrap(err, location, "Cannot load stream", target))
 		}
 		objectID = stream.StreamID
+		userID = stream.AttributedTo.UserID
 
 	case model.MentionTypeUser:
 		userService := factory.User()
@@ -53,6 +51,7 @@ func ReceiveWebMention(factory *domain.Factory, args mapof.Any) queue.Result {
 			return queue.Error(derp.Wrap(err, location, "Cannot load user", token))
 		}
 		objectID = user.UserID
+		userID = user.UserID
 
 	default:
 		return queue.Error(derp.NewInternalError(location, "Unknown Mention Type.  This should never happen", objectType))
@@ -65,13 +64,37 @@ func ReceiveWebMention(factory *domain.Factory, args mapof.Any) queue.Result {
 		return queue.Error(derp.Wrap(err, location, "Error loading mention", objectType, token))
 	}
 
+	isNew := mention.IsNew()
+
+	// Validate that the WebMention source (still) links to the targetURL
+	var content bytes.Buffer
+	if err := mentionService.Verify(source, target, &content); err != nil {
+
+		// Sources that have been deleted, or that no longer link to the target remove the Mention
+		if (derp.ErrorCode(err) == http.StatusGone) || derp.NotFound(err) {
+
+			if !isNew {
+				if err := mentionService.Delete(&mention, "Source removed"); err != nil {
+					return queue.Error(derp.Wrap(err, location, "Error deleting stale mention", source))
+				}
+			}
+
+			return queue.Success()
+		}
+
+		return queue.Error(derp.Wrap(err, location, "Source does not link to target", source, target))
+	}
+
 	// Parse the WebMention source into the Mention object
-	if err := mentionService.GetPageInfo(&content, source, &mention); err != nil {
+	if err := mentionService.GetPageInfo(&content, source, target, &mention); err != nil {
 		return queue.Error(derp.Wrap(err, location, "Error parsing source", source))
 	}
 
+	// Hold the Mention for moderation unless it comes from a trusted (or vouched-for) source
+	mentionService.Moderate(&mention, userID, source, vouch)
+
 	// Try to save the mention to the database
-	if err := mentionService.Save(&mention, "Created"); err != nil {
+	if err := mentionService.Save(&mention, "Received WebMention"); err != nil {
 		return queue.Error(derp.Wrap(err, location, "Error saving mention"))
 	}
 
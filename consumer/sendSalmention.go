@@ -0,0 +1,44 @@
+package consumer
+
+import (
+	"strings"
+
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/turbine/queue"
+	"willnorris.com/go/webmention"
+)
+
+// SendSalmention re-sends WebMentions for every external link in a Stream, so that
+// upstream sites receive the updated list of responses to this Stream.
+// https://indieweb.org/Salmention
+func SendSalmention(factory *domain.Factory, _ *service.Stream, stream *model.Stream, _ mapof.Any) queue.Result {
+
+	const location = "consumer.SendSalmention"
+
+	// Discover all webmention links in the Stream content
+	reader := strings.NewReader(stream.Content.HTML)
+	links, err := webmention.DiscoverLinksFromReader(reader, stream.URL, "")
+
+	if err != nil {
+		return queue.Failure(derp.Wrap(err, location, "Error discovering webmention links", stream.StreamID))
+	}
+
+	// Add background tasks to TRY sending webmentions to every link we found
+	for _, link := range links {
+
+		task := queue.NewTask("SendWebMention", mapof.Any{
+			"source": stream.URL,
+			"target": link,
+		})
+
+		if err := factory.Queue().Publish(task); err != nil {
+			return queue.Error(derp.Wrap(err, location, "Error publishing task", task))
+		}
+	}
+
+	return queue.Success()
+}
@@ -5,14 +5,19 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 
-	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/tools/convert"
 	"github.com/benpate/derp"
 	"github.com/benpate/remote"
 	"github.com/benpate/rosetta/mapof"
 	"github.com/benpate/turbine/queue"
+	"github.com/kr/jsonfeed"
 )
 
-func SendWebSubMessage(args mapof.Any) queue.Result {
+// SendWebSubMessage sends a "fat ping" to a WebSub subscriber.  The message body contains
+// a feed with only the newly published item, rendered in the format that the subscriber negotiated.
+// https://indieweb.org/How_to_publish_and_consume_WebSub
+func SendWebSubMessage(factory *domain.Factory, args mapof.Any) queue.Result {
 
 	const location = "consumer.SendWebSubMessage"
 
@@ -20,25 +25,49 @@ func SendWebSubMessage(args mapof.Any) queue.Result {
 	inboxURL := args.GetString("inboxUrl")
 	format := args.GetString("format")
 	secret := args.GetString("secret")
+	parentType := args.GetString("parentType")
+	parentID := args.GetString("parentId")
+	objectID := args.GetString("objectId")
 
-	var body []byte
+	// Load the feed that the subscriber is following
+	topic, err := loadWebSubTopic(factory, parentType, parentID)
 
-	// TODO: LOW: SendWebSubMessage will require a refactor if we want to send "fat pings":
-	// https://indieweb.org/How_to_publish_and_consume_WebSub
-	switch format {
+	if err != nil {
 
-	case model.MimeTypeJSONFeed:
-		// Convert & Marshall
+		// If the feed has been removed, then there is nothing left to send
+		if derp.NotFound(err) {
+			return queue.Success()
+		}
 
-	case model.MimeTypeAtom:
-		// Convert & Marshall
+		return queue.Error(derp.Wrap(err, location, "Error loading WebSub topic", parentType, parentID))
+	}
+
+	// Load the newly published item
+	item, err := topic.LoadItem(factory, objectID)
+
+	if err != nil {
+
+		// Activities that are not feed items (or have since been deleted) are not distributed
+		if derp.NotFound(err) {
+			return queue.Success()
+		}
+
+		return queue.Error(derp.Wrap(err, location, "Error loading published item", objectID))
+	}
+
+	// Render the feed delta in the subscriber's format
+	feed := topic.JSONFeed(factory.Domain().Get().WebSubHubURLs())
+	feed.Items = []jsonfeed.Item{item}
+
+	body, contentType, err := convert.MarshalFeed(feed, format)
 
-	case model.MimeTypeRSS:
-		// Convert & Marshall
+	if err != nil {
+		return queue.Failure(derp.Wrap(err, location, "Error rendering feed", format))
 	}
 
 	transaction := remote.Post(inboxURL).
-		Header("Content-Type", format).
+		Header("Content-Type", contentType).
+		Header("Link", `<`+topic.HubURL+`>; rel="hub", <`+topic.FeedURL+`>; rel="self"`).
 		Body(string(body))
 
 	// Add HMAC signature, if necessary
@@ -0,0 +1,124 @@
+package consumer
+
+import (
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/convert"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/kr/jsonfeed"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// webSubTopic describes a feed (User, Stream, or SavedSearch) that WebSub subscribers can follow
+type webSubTopic struct {
+	ParentType  string
+	Title       string
+	Description string
+	URL         string
+	FeedURL     string
+	HubURL      string
+}
+
+// loadWebSubTopic loads the feed information for the designated parent object
+func loadWebSubTopic(factory *domain.Factory, parentType string, parentID string) (webSubTopic, error) {
+
+	const location = "consumer.loadWebSubTopic"
+
+	objectID, err := primitive.ObjectIDFromHex(parentID)
+
+	if err != nil {
+		return webSubTopic{}, derp.Wrap(err, location, "Invalid parentId", parentID)
+	}
+
+	switch parentType {
+
+	case model.FollowerTypeUser:
+		user := model.NewUser()
+		if err := factory.User().LoadByID(objectID, &user); err != nil {
+			return webSubTopic{}, derp.Wrap(err, location, "Error loading User", parentID)
+		}
+
+		return newWebSubTopic(parentType, user.DisplayName, user.StatusMessage, user.ProfileURL), nil
+
+	case model.FollowerTypeStream:
+		stream := model.NewStream()
+		if err := factory.Stream().LoadByID(objectID, &stream); err != nil {
+			return webSubTopic{}, derp.Wrap(err, location, "Error loading Stream", parentID)
+		}
+
+		return newWebSubTopic(parentType, stream.Label, stream.Summary, stream.URL), nil
+
+	case model.FollowerTypeSearch:
+		savedSearch := model.NewSavedSearch()
+		if err := factory.SavedSearch().LoadByID(objectID, &savedSearch); err != nil {
+			return webSubTopic{}, derp.Wrap(err, location, "Error loading SavedSearch", parentID)
+		}
+
+		return newWebSubTopic(parentType, savedSearch.Label(), savedSearch.Summary, savedSearch.URL), nil
+	}
+
+	return webSubTopic{}, derp.NewInternalError(location, "Invalid parentType", parentType)
+}
+
+// newWebSubTopic returns a webSubTopic using the standard "/feed" and "/websub" URLs for a parent object
+func newWebSubTopic(parentType string, title string, description string, url string) webSubTopic {
+	return webSubTopic{
+		ParentType:  parentType,
+		Title:       title,
+		Description: description,
+		URL:         url,
+		FeedURL:     url + "/feed",
+		HubURL:      url + "/websub",
+	}
+}
+
+// JSONFeed returns an empty JSONFeed for this topic, advertising the local hub along with any external hubs
+func (topic webSubTopic) JSONFeed(externalHubs sliceof.String) jsonfeed.Feed {
+
+	result := jsonfeed.Feed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       topic.Title,
+		Description: topic.Description,
+		HomePageURL: topic.URL,
+		FeedURL:     topic.FeedURL + "?format=json",
+		Hubs:        []jsonfeed.Hub{{Type: "WebSub", URL: topic.HubURL}},
+		Items:       []jsonfeed.Item{},
+	}
+
+	for _, hub := range externalHubs {
+		result.Hubs = append(result.Hubs, jsonfeed.Hub{Type: "WebSub", URL: hub})
+	}
+
+	return result
+}
+
+// LoadItem loads a single published object (by its URL) and converts it into a JSONFeed item
+func (topic webSubTopic) LoadItem(factory *domain.Factory, url string) (jsonfeed.Item, error) {
+
+	const location = "consumer.webSubTopic.LoadItem"
+
+	// SavedSearches publish SearchResults
+	if topic.ParentType == model.FollowerTypeSearch {
+
+		searchResult := model.NewSearchResult()
+		if err := factory.Search().LoadByURL(url, &searchResult); err != nil {
+			return jsonfeed.Item{}, derp.Wrap(err, location, "Error loading SearchResult", url)
+		}
+
+		return convert.SearchResultToJsonFeed(searchResult), nil
+	}
+
+	// Users and Streams publish Streams
+	stream := model.NewStream()
+	if err := factory.Stream().LoadByURL(url, &stream); err != nil {
+		return jsonfeed.Item{}, derp.Wrap(err, location, "Error loading Stream", url)
+	}
+
+	// RULE: WebSub subscribers are anonymous, so they only receive Streams that anyone can view
+	if !stream.DefaultAllowAnonymous() {
+		return jsonfeed.Item{}, derp.NewNotFoundError(location, "Stream is not public", url)
+	}
+
+	return convert.StreamToJsonFeed(stream), nil
+}
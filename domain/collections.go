@@ -3,6 +3,12 @@ package domain
 // CollectionAttachment is the name of the database collection where Attachments are stored
 const CollectionAttachment = "Attachment"
 
+// CollectionAuditLog is the name of the database collection where AuditLog records are stored
+const CollectionAuditLog = "AuditLog"
+
+// CollectionBlocklist is the name of the database collection where Blocklist subscriptions are stored
+const CollectionBlocklist = "Blocklist"
+
 // CollectionConnection is the name of the database collection where Connection records are stored
 const CollectionConnection = "Connection"
 
@@ -45,6 +51,9 @@ const CollectionOAuthUserToken = "OAuthUserToken"
 // CollectionOutbox is the name of the database collection where users' Outbox records are stored
 const CollectionOutbox = "Outbox"
 
+// CollectionSavedSearch is the name of the database collection where SavedSearches are stored
+const CollectionSavedSearch = "SavedSearch"
+
 // CollectionSearchResult is the name of the database collection where SearchResults are stored
 const CollectionSearchResult = "SearchResult"
 
@@ -57,6 +66,9 @@ const CollectionStream = "Stream"
 // CollectionStreamDraft is the name of the database collection where draft changes to streams are stored
 const CollectionStreamDraft = "StreamDraft"
 
+// CollectionStreamRevision is the name of the database collection where the revision history of streams is stored
+const CollectionStreamRevision = "StreamRevision"
+
 // CollectionStreamOutbox is the name of the database collection where users' StreamMessage records are stored
 const CollectionStreamOutbox = "StreamOutbox"
 
@@ -44,6 +44,7 @@ type Factory struct {
 	templateService     *service.Template
 	themeService        *service.Theme
 	widgetService       *service.Widget
+	translationService  *service.Translation
 	workingDirectory    *mediaserver.WorkingDirectory
 
 	// Upload Directories (from server)
@@ -52,32 +53,37 @@ type Factory struct {
 	exportCache         afero.Fs
 
 	// services (within this domain/factory)
-	activityService      service.ActivityStream
-	attachmentService    service.Attachment
-	connectionService    service.Connection
-	domainService        service.Domain
-	emailService         service.DomainEmail
-	encryptionKeyService service.EncryptionKey
-	folderService        service.Folder
-	followerService      service.Follower
-	followingService     service.Following
-	groupService         service.Group
-	inboxService         service.Inbox
-	jwtService           service.JWT
-	mentionService       service.Mention
-	oauthClient          service.OAuthClient
-	oauthUserToken       service.OAuthUserToken
-	outboxService        service.Outbox
-	responseService      service.Response
-	ruleService          service.Rule
-	searchTagService     service.SearchTag
-	searchService        service.Search
-	streamService        service.Stream
-	streamArchiveService service.StreamArchive
-	streamDraftService   service.StreamDraft
-	realtimeBroker       RealtimeBroker
-	userService          service.User
-	webhookService       service.Webhook
+	activityService       service.ActivityStream
+	attachmentService     service.Attachment
+	auditLogService       service.AuditLog
+	blocklistService      service.Blocklist
+	connectionService     service.Connection
+	domainService         service.Domain
+	emailService          service.DomainEmail
+	encryptionKeyService  service.EncryptionKey
+	folderService         service.Folder
+	followerService       service.Follower
+	followingService      service.Following
+	groupService          service.Group
+	inboxService          service.Inbox
+	jwtService            service.JWT
+	mentionService        service.Mention
+	oauthClient           service.OAuthClient
+	oauthUserToken        service.OAuthUserToken
+	oidcProviders         service.OIDCProviders
+	outboxService         service.Outbox
+	responseService       service.Response
+	ruleService           service.Rule
+	savedSearchService    service.SavedSearch
+	searchTagService      service.SearchTag
+	searchService         service.Search
+	streamService         service.Stream
+	streamArchiveService  service.StreamArchive
+	streamDraftService    service.StreamDraft
+	streamRevisionService service.StreamRevision
+	realtimeBroker        RealtimeBroker
+	userService           service.User
+	webhookService        service.Webhook
 
 	// real-time watchers
 	streamUpdateChannel chan primitive.ObjectID
@@ -86,7 +92,7 @@ type Factory struct {
 }
 
 // NewFactory creates a new factory tied to a MongoDB database
-func NewFactory(domain config.Domain, port string, providers []config.Provider, activityCache *mongo.Collection, registrationService *service.Registration, serverEmail *service.ServerEmail, themeService *service.Theme, templateService *service.Template, widgetService *service.Widget, contentService *service.Content, providerService *service.Provider, queue *queue.Queue, attachmentOriginals afero.Fs, attachmentCache afero.Fs, exportCache afero.Fs, httpCache *httpcache.HTTPCache, workingDirectory *mediaserver.WorkingDirectory) (*Factory, error) {
+func NewFactory(domain config.Domain, port string, providers []config.Provider, activityCache *mongo.Collection, registrationService *service.Registration, serverEmail *service.ServerEmail, themeService *service.Theme, templateService *service.Template, widgetService *service.Widget, translationService *service.Translation, contentService *service.Content, providerService *service.Provider, queue *queue.Queue, attachmentOriginals afero.Fs, attachmentCache afero.Fs, exportCache afero.Fs, httpCache *httpcache.HTTPCache, workingDirectory *mediaserver.WorkingDirectory) (*Factory, error) {
 
 	log.Info().Msg("Starting domain: " + domain.Hostname)
 
@@ -97,6 +103,7 @@ func NewFactory(domain config.Domain, port string, providers []config.Provider,
 		themeService:        themeService,
 		templateService:     templateService,
 		widgetService:       widgetService,
+		translationService:  translationService,
 		contentService:      contentService,
 		providerService:     providerService,
 		queue:               queue,
@@ -124,6 +131,8 @@ func NewFactory(domain config.Domain, port string, providers []config.Provider,
 	// Create empty service pointers.  These will be populated in the Refresh() step.
 	factory.activityService = service.NewActivityStream()
 	factory.attachmentService = service.NewAttachment()
+	factory.auditLogService = service.NewAuditLog()
+	factory.blocklistService = service.NewBlocklist()
 	factory.connectionService = service.NewConnection()
 	factory.domainService = service.NewDomain()
 	factory.emailService = service.NewDomainEmail(serverEmail)
@@ -137,14 +146,17 @@ func NewFactory(domain config.Domain, port string, providers []config.Provider,
 	factory.mentionService = service.NewMention()
 	factory.oauthClient = service.NewOAuthClient()
 	factory.oauthUserToken = service.NewOAuthUserToken()
+	factory.oidcProviders = service.NewOIDCProviders()
 	factory.outboxService = service.NewOutbox()
 	factory.responseService = service.NewResponse()
 	factory.ruleService = service.NewRule()
+	factory.savedSearchService = service.NewSavedSearch()
 	factory.searchService = service.NewSearch()
 	factory.searchTagService = service.NewSearchTag()
 	factory.streamService = service.NewStream()
 	factory.streamArchiveService = service.NewStreamArchive()
 	factory.streamDraftService = service.NewStreamDraft()
+	factory.streamRevisionService = service.NewStreamRevision()
 	factory.userService = service.NewUser()
 	factory.webhookService = service.NewWebhook()
 
@@ -155,6 +167,8 @@ func NewFactory(domain config.Domain, port string, providers []config.Provider,
 
 	// Start() is okay here because it will check for nil configuration before polling.
 	go factory.followingService.Start()
+	go factory.blocklistService.Start()
+	go factory.searchService.Start()
 
 	// Success!
 	return &factory, nil
@@ -167,7 +181,9 @@ func (factory *Factory) Refresh(domain config.Domain, providers []config.Provide
 	factory.attachmentCache = attachmentCache
 
 	// If the database connect string has changed, then update the database connection
-	if (factory.config.ConnectString != domain.ConnectString) || (factory.config.DatabaseName != domain.DatabaseName) {
+	databaseChanged := (factory.config.ConnectString != domain.ConnectString) || (factory.config.DatabaseName != domain.DatabaseName)
+
+	if databaseChanged {
 
 		// If the connect string is empty, then we don't need to (re-)connect to a database
 		if domain.ConnectString == "" {
@@ -210,9 +226,21 @@ func (factory *Factory) Refresh(domain config.Domain, providers []config.Provide
 		factory.attachmentService.Refresh(
 			factory.collection(CollectionAttachment),
 			factory.MediaServer(),
+			factory.AttachmentOriginals(),
+			factory.AttachmentCache(),
+			factory.Domain(),
+			factory.Group(),
+			factory.User(),
 			factory.Host(),
 		)
 
+		// Populate Blocklist Service
+		factory.blocklistService.Refresh(
+			factory.collection(CollectionBlocklist),
+			factory.Rule(),
+			factory.ActivityStream(),
+		)
+
 		factory.connectionService.Refresh(
 			factory.collection(CollectionConnection),
 		)
@@ -227,7 +255,7 @@ func (factory *Factory) Refresh(domain config.Domain, providers []config.Provide
 			factory.Registration(),
 			factory.Theme(),
 			factory.User(),
-			build.FuncMap(factory.Icons()),
+			build.FuncMap(factory.Icons(), factory.Translation()),
 			factory.Hostname(),
 		)
 
@@ -250,6 +278,7 @@ func (factory *Factory) Refresh(domain config.Domain, providers []config.Provide
 			factory.collection(CollectionFollower),
 			factory.User(),
 			factory.Stream(),
+			factory.SavedSearch(),
 			factory.Rule(),
 			factory.Email(),
 			factory.ActivityStream(),
@@ -288,11 +317,21 @@ func (factory *Factory) Refresh(domain config.Domain, providers []config.Provide
 			[]byte(domain.KeyEncryptingKey),
 		)
 
+		// Populate AuditLog Service
+		factory.auditLogService.Refresh(
+			factory.collection(CollectionAuditLog),
+			factory.JWT(),
+			factory.User(),
+		)
+
 		// Populate Mention Service
 		factory.mentionService.Refresh(
 			factory.collection(CollectionMention),
+			factory.Following(),
+			factory.Response(),
 			factory.Rule(),
 			factory.ActivityStream(),
+			factory.Queue(),
 			factory.Host(),
 		)
 
@@ -320,7 +359,9 @@ func (factory *Factory) Refresh(domain config.Domain, providers []config.Provide
 			factory.Template(),
 			factory.User(),
 			factory.Email(),
+			factory.Domain(),
 			factory.Queue(),
+			factory.Host(),
 		)
 
 		// Populate RealtimeBroker Service
@@ -346,11 +387,26 @@ func (factory *Factory) Refresh(domain config.Domain, providers []config.Provide
 			factory.Host(),
 		)
 
+		// Populate the SavedSearch Service
+		factory.savedSearchService.Refresh(
+			factory.collection(CollectionSavedSearch),
+			factory.Search(),
+			factory.Follower(),
+			factory.EncryptionKey(),
+			factory.Rule(),
+			factory.ActivityStream(),
+			factory.Host(),
+		)
+
 		// Populate the Search Service
 		factory.searchService.Refresh(
 			factory.collection(CollectionSearchResult),
+			factory.Domain(),
 			factory.SearchTag(),
+			factory.ActivityStream(),
+			factory.Queue(),
 			factory.Host(),
+			domain.SearchEngine,
 		)
 
 		// Populate the SearchTag Service
@@ -398,6 +454,13 @@ func (factory *Factory) Refresh(domain config.Domain, providers []config.Provide
 			factory.Stream(),
 		)
 
+		// Populate StreamRevision Service
+		factory.streamRevisionService.Refresh(
+			factory.collection(CollectionStreamRevision),
+			factory.Template(),
+			factory.User(),
+		)
+
 		// Populate User Service
 		factory.userService.Refresh(
 			factory.collection(CollectionUser),
@@ -434,6 +497,20 @@ func (factory *Factory) Refresh(domain config.Domain, providers []config.Provide
 		}
 	}
 
+	// Re-Populate Search Service
+	// This is separate because the search engine may change without changing the database
+	if !databaseChanged && (factory.config.SearchEngine != domain.SearchEngine) {
+		factory.searchService.Refresh(
+			factory.collection(CollectionSearchResult),
+			factory.Domain(),
+			factory.SearchTag(),
+			factory.ActivityStream(),
+			factory.Queue(),
+			factory.Host(),
+			domain.SearchEngine,
+		)
+	}
+
 	// Re-Populate Email Service
 	// This is separate because it may change separately from the DNS
 	factory.emailService.Refresh(
@@ -464,8 +541,11 @@ func (factory *Factory) Close() {
 	factory.realtimeBroker.Close()
 	factory.streamService.Close()
 	factory.followingService.Close()
+	factory.blocklistService.Close()
 	factory.followerService.Close()
 	factory.jwtService.Close()
+	factory.savedSearchService.Close()
+	factory.searchService.Close()
 	factory.userService.Close()
 }
 
@@ -541,6 +621,16 @@ func (factory *Factory) Attachment() *service.Attachment {
 	return &factory.attachmentService
 }
 
+// AuditLog returns a fully populated AuditLog service
+func (factory *Factory) AuditLog() *service.AuditLog {
+	return &factory.auditLogService
+}
+
+// Blocklist returns a fully populated Blocklist service
+func (factory *Factory) Blocklist() *service.Blocklist {
+	return &factory.blocklistService
+}
+
 // Rule returns a fully populated Rule service
 func (factory *Factory) Rule() *service.Rule {
 	return &factory.ruleService
@@ -606,6 +696,11 @@ func (factory *Factory) Outbox() *service.Outbox {
 	return &factory.outboxService
 }
 
+// SavedSearch returns a fully populated SavedSearch service
+func (factory *Factory) SavedSearch() *service.SavedSearch {
+	return &factory.savedSearchService
+}
+
 // Search returns a fully populated Search service
 func (factory *Factory) Search() *service.Search {
 	return &factory.searchService
@@ -631,6 +726,11 @@ func (factory *Factory) StreamDraft() *service.StreamDraft {
 	return &factory.streamDraftService
 }
 
+// StreamRevision returns a fully populated StreamRevision service
+func (factory *Factory) StreamRevision() *service.StreamRevision {
+	return &factory.streamRevisionService
+}
+
 // Response returns a fully populated Response service
 func (factory *Factory) Response() *service.Response {
 	return &factory.responseService
@@ -646,6 +746,11 @@ func (factory *Factory) Widget() *service.Widget {
 	return factory.widgetService
 }
 
+// Translation returns the global Translation service
+func (factory *Factory) Translation() *service.Translation {
+	return factory.translationService
+}
+
 // Webhook returns a fully populated Webhook service
 func (factory *Factory) Webhook() *service.Webhook {
 	return &factory.webhookService
@@ -754,6 +859,7 @@ func (factory *Factory) Locator() service.Locator {
 	return service.NewLocator(
 		factory.User(),
 		factory.Stream(),
+		factory.SavedSearch(),
 		factory.Hostname(),
 	)
 }
@@ -772,6 +878,31 @@ func (factory *Factory) Registration() *service.Registration {
 	return factory.registrationService
 }
 
+// OIDC returns a fully populated OIDC service, which manages sign-in through an external OpenID Connect provider
+func (factory *Factory) OIDC() service.OIDC {
+	return service.NewOIDC(
+		factory.Group(),
+		factory.Registration(),
+		factory.User(),
+		factory.JWT(),
+		&factory.oidcProviders,
+		factory.Domain().Get(),
+		factory.Host(),
+	)
+}
+
+// TwoFactor returns a fully populated TwoFactor service, which manages TOTP codes and passkeys
+func (factory *Factory) TwoFactor() service.TwoFactor {
+	return service.NewTwoFactor(
+		factory.User(),
+		factory.JWT(),
+		factory.config.KeyEncryptingKey,
+		factory.Host(),
+		factory.Hostname(),
+		factory.Domain().Get().Label,
+	)
+}
+
 // Steranko returns a fully populated Steranko adapter for the User service.
 func (factory *Factory) Steranko() *steranko.Steranko {
 
@@ -840,6 +971,9 @@ func (factory *Factory) ModelService(object data.Object) service.ModelService {
 	case *model.Following:
 		return factory.Following()
 
+	case *model.Mention:
+		return factory.Mention()
+
 	case *model.Message:
 		return factory.Inbox()
 
@@ -0,0 +1,17 @@
+package activitypub_search
+
+import (
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/hannibal/outbox"
+)
+
+// Context includes all of the necessary objects to handle an ActivityPub request
+type Context struct {
+	factory     *domain.Factory
+	savedSearch *model.SavedSearch
+}
+
+func (context Context) ActivityPubActor(withFollowers bool) (outbox.Actor, error) {
+	return context.factory.SavedSearch().ActivityPubActor(context.savedSearch, withFollowers)
+}
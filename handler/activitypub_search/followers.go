@@ -0,0 +1,54 @@
+package activitypub_search
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/EmissarySocial/emissary/handler/activitypub"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/server"
+	"github.com/benpate/derp"
+	"github.com/benpate/hannibal/vocab"
+	"github.com/benpate/rosetta/convert"
+	"github.com/labstack/echo/v4"
+)
+
+// GetFollowersCollection returns the ActivityPub followers of a SavedSearch
+func GetFollowersCollection(serverFactory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.activitypub_search.GetFollowersCollection"
+
+	return func(ctx echo.Context) error {
+
+		factory, savedSearch, err := getSavedSearch(serverFactory, ctx)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Request Not Accepted")
+		}
+
+		// If the request is for the collection itself, then return a summary and the URL of the first page
+		publishDateString := ctx.QueryParam("publishDate")
+
+		if publishDateString == "" {
+			ctx.Response().Header().Set("Content-Type", vocab.ContentTypeActivityPub)
+			result := activitypub.Collection(savedSearch.ActivityPubFollowersURL())
+			return ctx.JSON(http.StatusOK, result)
+		}
+
+		// Fall through means that we're looking for a specific page of the collection
+		publishedDate := convert.Int64Default(publishDateString, math.MaxInt64)
+		pageSize := 60
+
+		// Retrieve a page of followers from the database
+		followerService := factory.Follower()
+		followers, err := followerService.QueryByParentAndDate(model.FollowerTypeSearch, savedSearch.SavedSearchID, model.FollowerMethodActivityPub, publishedDate, pageSize)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error querying followers")
+		}
+
+		ctx.Response().Header().Set("Content-Type", vocab.ContentTypeActivityPub)
+		result := activitypub.CollectionPage(savedSearch.ActivityPubFollowersURL(), pageSize, followers)
+		return ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,54 @@
+package activitypub_search
+
+import (
+	"net/http"
+
+	"github.com/EmissarySocial/emissary/server"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/benpate/derp"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostInbox receives ActivityPub activities (Follow and Undo/Follow) sent to a SavedSearch
+func PostInbox(serverFactory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.activitypub_search.PostInbox"
+
+	return func(ctx echo.Context) error {
+
+		// Retrieve the Factory and SavedSearch from the Request
+		factory, savedSearch, err := getSavedSearch(serverFactory, ctx)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Request Not Accepted")
+		}
+
+		// Retrieve the activity from the request body
+		activity, err := factory.ActivityStream().ReceiveRequest(ctx.Request())
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error parsing ActivityPub request")
+		}
+
+		// RULE: Reject activities from Actors that are blocked by this domain
+		ruleFilter := factory.Rule().Filter(primitive.NilObjectID, service.WithBlocksOnly())
+		if ruleFilter.Disallow(&activity) {
+			return derp.NewForbiddenError(location, "Blocked by domain rule", activity.Actor().ID())
+		}
+
+		// Create a new request context for the ActivityPub router
+		context := Context{
+			factory:     factory,
+			savedSearch: &savedSearch,
+		}
+
+		// Handle the ActivityPub request
+		if err := searchRouter.Handle(context, activity); err != nil {
+			return derp.Wrap(err, location, "Error handling ActivityPub request")
+		}
+
+		// Send the response to the client
+		return ctx.String(http.StatusOK, "")
+	}
+}
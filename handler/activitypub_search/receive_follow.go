@@ -0,0 +1,47 @@
+package activitypub_search
+
+import (
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/benpate/hannibal/streams"
+	"github.com/benpate/hannibal/vocab"
+)
+
+func init() {
+	searchRouter.Add(vocab.ActivityTypeFollow, vocab.Any, func(context Context, activity streams.Document) error {
+
+		const location = "handler.activitypub_search.receiveFollow"
+
+		// Validate that the receiving SavedSearch matches the Actor ID in the Activity
+		if context.savedSearch.ActivityPubURL() != activity.Object().ID() {
+			return derp.NewBadRequestError(location, "Invalid SavedSearch ID", context.savedSearch.ActivityPubURL(), activity.Object().ID())
+		}
+
+		// Try to look up the complete actor record from the activity
+		document, err := activity.Actor().Load()
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error parsing actor", activity)
+		}
+
+		// Try to create a new follower record
+		followerService := context.factory.Follower()
+		follower := model.NewFollower()
+		if err := followerService.NewActivityPubFollower(model.FollowerTypeSearch, context.savedSearch.SavedSearchID, document, &follower); err != nil {
+			return derp.Wrap(err, location, "Error creating new follower", context.savedSearch)
+		}
+
+		// Send an "Accept" message to the Requester
+		actor, err := context.ActivityPubActor(false)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error loading actor", context.savedSearch)
+		}
+
+		acceptID := followerService.ActivityPubID(&follower)
+		actor.SendAccept(acceptID, activity)
+
+		// Voila!
+		return nil
+	})
+}
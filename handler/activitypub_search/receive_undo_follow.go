@@ -0,0 +1,59 @@
+package activitypub_search
+
+import (
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/benpate/hannibal/streams"
+	"github.com/benpate/hannibal/vocab"
+)
+
+func init() {
+	searchRouter.Add(vocab.ActivityTypeUndo, vocab.ActivityTypeFollow, undoFollow)
+	searchRouter.Add(vocab.ActivityTypeDelete, vocab.ActivityTypeFollow, undoFollow)
+}
+
+// undoFollow handles "Undo/Follow" and "Delete/Follow" activitites, which means
+// that this code is called when a remote actor unfollows a SavedSearch on this server.
+func undoFollow(context Context, activity streams.Document) error {
+
+	const location = "handler.activitypub_search.undoFollow"
+
+	// Load the original follow
+	originalFollow, err := activity.Object().Load()
+
+	if err != nil {
+		if derp.NotFound(err) {
+			return nil // If there is no follower record, then there's nothing to delete.
+		}
+
+		// All other errors are bad, tho.
+		return derp.Wrap(err, location, "Error retrieving original follow request", activity.Value())
+	}
+
+	// The "object" of the original follow must be this SavedSearch
+	if originalFollow.Object().ID() != context.savedSearch.ActivityPubURL() {
+		return derp.NewBadRequestError(location, "Invalid SavedSearch ID", context.savedSearch.ActivityPubURL(), originalFollow.Object().ID())
+	}
+
+	// The "actor" of the original follow is our follower.actor.ProfileURL
+	actorURL := originalFollow.Actor().ID()
+	followerService := context.factory.Follower()
+	follower := model.NewFollower()
+
+	if err := followerService.LoadByActivityPubFollower(model.FollowerTypeSearch, context.savedSearch.SavedSearchID, actorURL, &follower); err != nil {
+
+		if derp.NotFound(err) {
+			return nil
+		}
+
+		return derp.Wrap(err, location, "Error loading Follower", activity.Value(), context.savedSearch.SavedSearchID, actorURL)
+	}
+
+	// Try to delete the existing follower record
+	if err := followerService.Delete(&follower, "Removed by remote client"); err != nil {
+		return derp.Wrap(err, location, "Error deleting follower", follower)
+	}
+
+	// Voila!
+	return nil
+}
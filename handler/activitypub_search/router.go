@@ -0,0 +1,8 @@
+package activitypub_search
+
+import (
+	"github.com/benpate/hannibal/inbox"
+)
+
+// searchRouter defines the package-level router for savedSearch/ActivityPub requests
+var searchRouter inbox.Router[Context] = inbox.NewRouter[Context]()
@@ -0,0 +1,31 @@
+package activitypub_search
+
+import (
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/server"
+	"github.com/benpate/derp"
+	"github.com/labstack/echo/v4"
+)
+
+// getSavedSearch wraps all of the monotonous code of loading a factory and SavedSearch from the Request context.
+func getSavedSearch(serverFactory *server.Factory, ctx echo.Context) (*domain.Factory, model.SavedSearch, error) {
+
+	const location = "activitypub_search.getSavedSearch"
+
+	factory, err := serverFactory.ByContext(ctx)
+
+	if err != nil {
+		return nil, model.SavedSearch{}, derp.Wrap(err, location, "Unrecognized Domain")
+	}
+
+	// Try to load the SavedSearch
+	savedSearch := model.NewSavedSearch()
+	token := ctx.Param("savedSearchId")
+
+	if err := factory.SavedSearch().LoadByToken(token, &savedSearch); err != nil {
+		return nil, model.SavedSearch{}, derp.Wrap(err, location, "Error loading SavedSearch", token)
+	}
+
+	return factory, savedSearch, nil
+}
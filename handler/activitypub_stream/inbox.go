@@ -3,10 +3,14 @@ package activitypub_stream
 import (
 	"net/http"
 
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
 	"github.com/EmissarySocial/emissary/server"
+	"github.com/EmissarySocial/emissary/service"
 	"github.com/benpate/derp"
-	"github.com/benpate/hannibal/inbox"
+	"github.com/benpate/hannibal/streams"
 	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func PostInbox(serverFactory *server.Factory) echo.HandlerFunc {
@@ -27,12 +31,18 @@ func PostInbox(serverFactory *server.Factory) echo.HandlerFunc {
 		}
 
 		// Retrieve the activity from the request body
-		activity, err := inbox.ReceiveRequest(ctx.Request(), factory.ActivityStream())
+		activity, err := factory.ActivityStream().ReceiveRequest(ctx.Request())
 
 		if err != nil {
 			return derp.Wrap(err, location, "Error parsing ActivityPub request")
 		}
 
+		// RULE: Reject activities from Actors that are blocked by this domain
+		ruleFilter := factory.Rule().Filter(primitive.NilObjectID, service.WithBlocksOnly())
+		if ruleFilter.Disallow(&activity) {
+			return derp.NewForbiddenError(location, "Blocked by domain rule", activity.Actor().ID())
+		}
+
 		// Create a new request context for the ActivityPub router
 		context := Context{
 			factory: factory,
@@ -49,3 +59,36 @@ func PostInbox(serverFactory *server.Factory) echo.HandlerFunc {
 		return ctx.String(http.StatusOK, "")
 	}
 }
+
+// ReceiveSharedActivity routes an activity that was received by the domain's shared inbox
+// into the inbox of a single Stream.  Shared inbox requests are validated by the caller.
+func ReceiveSharedActivity(factory *domain.Factory, stream *model.Stream, activity streams.Document) error {
+
+	const location = "handler.activitypub_stream.ReceiveSharedActivity"
+
+	// Try to load the Stream's Template
+	template, err := factory.Template().Load(stream.TemplateID)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid Template", stream.TemplateID)
+	}
+
+	// RULE: Only Streams that are configured as Actors can receive activities
+	if template.Actor.IsNil() {
+		return derp.NewNotFoundError(location, "Actor not found", stream.StreamID)
+	}
+
+	// Create a new request context for the ActivityPub router
+	context := Context{
+		factory: factory,
+		stream:  stream,
+		actor:   &template.Actor,
+	}
+
+	// Handle the ActivityPub request
+	if err := streamRouter.Handle(context, activity); err != nil {
+		return derp.Wrap(err, location, "Error handling ActivityPub request", stream.StreamID)
+	}
+
+	return nil
+}
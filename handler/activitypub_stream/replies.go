@@ -1,12 +1,83 @@
 package activitypub_stream
 
 import (
+	"math"
+	"net/http"
+
+	"github.com/EmissarySocial/emissary/handler/activitypub"
+	"github.com/EmissarySocial/emissary/model"
 	"github.com/EmissarySocial/emissary/server"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/benpate/derp"
+	"github.com/benpate/hannibal/streams"
+	"github.com/benpate/rosetta/channel"
+	"github.com/benpate/rosetta/convert"
 	"github.com/labstack/echo/v4"
 )
 
+// GetRepliesCollection returns the ActivityPub "replies" collection for a Stream, which includes
+// replies received via ActivityPub and approved replies received via WebMention.
 func GetRepliesCollection(serverFactory *server.Factory) echo.HandlerFunc {
+
 	return func(ctx echo.Context) error {
-		return nil
+
+		const location = "handler.activitypub_stream.GetRepliesCollection"
+
+		// Verify the domain name
+		factory, err := serverFactory.ByContext(ctx)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error creating factory")
+		}
+
+		// Load the stream to verify the URL
+		streamService := factory.Stream()
+		stream := model.NewStream()
+		token := ctx.Param("stream")
+
+		if err := streamService.LoadByToken(token, &stream); err != nil {
+			return derp.Wrap(err, location, "Error loading stream")
+		}
+
+		// RULE: Only PUBLIC streams have /replies
+		if !stream.DefaultAllowAnonymous() {
+			return derp.NewUnauthorizedError(location, "Anonymous access not allowed")
+		}
+
+		baseRequestURL := stream.ActivityPubRepliesURL()
+		publishDateString := ctx.QueryParam("publishDate")
+
+		// If no "publishDate" then return the collection header.
+		if publishDateString == "" {
+			ctx.Response().Header().Set("Content-Type", model.MimeTypeActivityPub)
+			result := activitypub.Collection(baseRequestURL)
+			return ctx.JSON(http.StatusOK, result)
+		}
+
+		// Fall through means that we're looking for a specific page of the collection
+		publishDate := convert.Int64Default(publishDateString, math.MaxInt64)
+		pageSize := 60
+		done := make(channel.Done)
+
+		// Collect replies from ActivityPub and WebMentions
+		activityService := factory.ActivityStream()
+		replies := channel.Slice(channel.Limit(pageSize, activityService.QueryRepliesBeforeDate(stream.URL, publishDate, done), done))
+		mentions := factory.Mention().QueryRepliesBeforeDate(stream.StreamID, publishDate, pageSize)
+		replies = service.MergeReplies(replies, mentions, pageSize)
+
+		// Return a JSON-LD document
+		result := streams.NewOrderedCollectionPage()
+		result.PartOf = baseRequestURL
+
+		for _, reply := range replies {
+			result.OrderedItems = append(result.OrderedItems, reply.Map())
+		}
+
+		if len(replies) == pageSize {
+			result.Next = baseRequestURL + "?publishDate=" + convert.String(replies[pageSize-1].Published().Unix())
+		}
+
+		ctx.Response().Header().Set("Content-Type", model.MimeTypeActivityPub)
+		return ctx.JSON(http.StatusOK, result)
 	}
 }
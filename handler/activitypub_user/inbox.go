@@ -3,10 +3,12 @@ package activitypub_user
 import (
 	"net/http"
 
+	"github.com/EmissarySocial/emissary/domain"
 	"github.com/EmissarySocial/emissary/model"
 	"github.com/EmissarySocial/emissary/server"
+	"github.com/EmissarySocial/emissary/service"
 	"github.com/benpate/derp"
-	"github.com/benpate/hannibal/inbox"
+	"github.com/benpate/hannibal/streams"
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -43,12 +45,18 @@ func PostInbox(serverFactory *server.Factory) echo.HandlerFunc {
 		}
 
 		// Retrieve the activity from the request body
-		activity, err := inbox.ReceiveRequest(ctx.Request(), factory.ActivityStream())
+		activity, err := factory.ActivityStream().ReceiveRequest(ctx.Request())
 
 		if err != nil {
 			return derp.Wrap(err, location, "Error parsing ActivityPub request")
 		}
 
+		// RULE: Reject activities from Actors that are blocked by this domain
+		ruleFilter := factory.Rule().Filter(primitive.NilObjectID, service.WithBlocksOnly())
+		if ruleFilter.Disallow(&activity) {
+			return derp.NewForbiddenError(location, "Blocked by domain rule", activity.Actor().ID())
+		}
+
 		// Create a new Context
 		context := Context{
 			factory: factory,
@@ -60,7 +68,35 @@ func PostInbox(serverFactory *server.Factory) echo.HandlerFunc {
 			return derp.Wrap(err, location, "Error handling ActivityPub request")
 		}
 
+		// Add public documents to the search index (if enabled)
+		factory.Search().ReceiveActivity(activity)
+
 		// Send the response to the client
 		return ctx.String(http.StatusOK, "")
 	}
 }
+
+// ReceiveSharedActivity routes an activity that was received by the domain's shared inbox
+// into the inbox of a single User.  Shared inbox requests are validated by the caller.
+func ReceiveSharedActivity(factory *domain.Factory, user *model.User, activity streams.Document) error {
+
+	const location = "handler.activitypub_user.ReceiveSharedActivity"
+
+	// RULE: Only public users can receive activities
+	if !user.IsPublic {
+		return derp.NewNotFoundError(location, "User not found", user.UserID)
+	}
+
+	// Create a new Context
+	context := Context{
+		factory: factory,
+		user:    user,
+	}
+
+	// Handle the ActivityPub request
+	if err := inboxRouter.Handle(context, activity); err != nil {
+		return derp.Wrap(err, location, "Error handling ActivityPub request", user.UserID)
+	}
+
+	return nil
+}
@@ -61,7 +61,7 @@ func buildAdmin(factoryManager *server.Factory, actionMethod build.ActionMethod)
 		}
 
 		// Success!!
-		return build.AsHTML(factory, sterankoContext, builder, actionMethod)
+		return build.AsAuditedHTML(factory, sterankoContext, builder, actionMethod)
 	}
 }
 
@@ -89,9 +89,33 @@ func buildAdmin_GetBuilder(factory *domain.Factory, ctx *steranko.Context, templ
 	// Create the correct builder for this controller
 	switch template.Model {
 
+	case "blocklist":
+		blocklist := model.NewBlocklist()
+
+		if !objectID.IsZero() {
+			service := factory.Blocklist()
+			if err := service.LoadByID(objectID, &blocklist); err != nil {
+				return nil, derp.Wrap(err, location, "Error loading Blocklist", objectID)
+			}
+		}
+
+		return build.NewBlocklist(factory, ctx.Request(), ctx.Response(), template, &blocklist, actionID)
+
 	case "domain":
 		return build.NewDomain(factory, ctx.Request(), ctx.Response(), template, actionID)
 
+	case "savedSearch":
+		savedSearch := model.NewSavedSearch()
+
+		if !objectID.IsZero() {
+			service := factory.SavedSearch()
+			if err := service.LoadByID(objectID, &savedSearch); err != nil {
+				return nil, derp.Wrap(err, location, "Error loading SavedSearch", objectID)
+			}
+		}
+
+		return build.NewSavedSearch(factory, ctx.Request(), ctx.Response(), template, &savedSearch, actionID)
+
 	case "search":
 		return build.NewDomain(factory, ctx.Request(), ctx.Response(), template, actionID)
 
@@ -176,6 +200,6 @@ func buildAdmin_GetBuilder(factory *domain.Factory, ctx *steranko.Context, templ
 		return build.NewWebhook(factory, ctx.Request(), ctx.Response(), template, &webhook, actionID)
 
 	default:
-		return nil, derp.NewNotFoundError(location, "Template MODEL must be one of: 'rule', 'domain', 'syndication', 'group', 'stream', or 'user'", template.Model)
+		return nil, derp.NewNotFoundError(location, "Template MODEL must be one of: 'blocklist', 'savedSearch', 'rule', 'domain', 'syndication', 'group', 'stream', or 'user'", template.Model)
 	}
 }
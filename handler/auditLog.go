@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/benpate/steranko"
+	"github.com/labstack/echo/v4"
+)
+
+// writeAuditLog records a security-relevant action in the domain's audit log.
+// Errors are reported, but never prevent the action itself from completing.
+func writeAuditLog(ctx echo.Context, factory *domain.Factory, auditLog model.AuditLog) {
+
+	if err := factory.AuditLog().Add(ctx.Request(), auditLog); err != nil {
+		derp.Report(derp.Wrap(err, "handler.writeAuditLog", "Error writing audit log", auditLog))
+	}
+}
+
+// auditUser returns a new audit log entry for an action performed by (or on) the provided User
+func auditUser(action string, user *model.User, summary string) model.AuditLog {
+	return model.NewAuditLog(action).
+		WithActor(user.UserID, user.Username).
+		WithObject("User", user.UserID).
+		WithSummary(summary)
+}
+
+// auditAuthenticated returns a new audit log entry for an action performed by the signed-in User
+func auditAuthenticated(ctx echo.Context, factory *domain.Factory, action string) model.AuditLog {
+
+	authorization := getAuthorization(ctx)
+	result := model.NewAuditLog(action)
+
+	if !authorization.IsAuthenticated() {
+		return result
+	}
+
+	user := model.NewUser()
+
+	if err := factory.User().LoadByID(authorization.UserID, &user); err != nil {
+		return result.WithActor(authorization.UserID, "")
+	}
+
+	return result.WithActor(user.UserID, user.Username)
+}
+
+// GetAuditLogExport downloads all audit log records that match the request's
+// query parameters, as either CSV (the default) or JSON.
+// It can only be called by an authenticated administrator.
+func GetAuditLogExport(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.GetAuditLogExport"
+
+	auditLogService := factory.AuditLog()
+	filter := auditLogService.ParseFilter(ctx.QueryParams())
+	iterator, err := auditLogService.IteratorByFilter(filter)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading audit logs", filter)
+	}
+
+	defer iterator.Close()
+
+	format := ctx.QueryParam("format")
+	filename := "audit-log-" + factory.Hostname() + "-" + time.Now().UTC().Format(time.DateOnly)
+	response := ctx.Response()
+	auditLog := model.AuditLog{}
+
+	switch format {
+
+	case "json":
+		response.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		response.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`.json"`)
+		response.WriteHeader(http.StatusOK)
+
+		encoder := json.NewEncoder(response)
+		separator := "["
+
+		for iterator.Next(&auditLog) {
+
+			if _, err := response.Write([]byte(separator)); err != nil {
+				return derp.Wrap(err, location, "Error writing JSON")
+			}
+
+			if err := encoder.Encode(auditLog.ExportMap()); err != nil {
+				return derp.Wrap(err, location, "Error writing JSON")
+			}
+
+			separator = ","
+			auditLog = model.AuditLog{}
+		}
+
+		// Empty exports still need to be valid JSON
+		if separator == "[" {
+			separator = "[]"
+		} else {
+			separator = "]"
+		}
+
+		if _, err := response.Write([]byte(separator)); err != nil {
+			return derp.Wrap(err, location, "Error writing JSON")
+		}
+
+		return nil
+
+	default:
+		response.Header().Set(echo.HeaderContentType, "text/csv; charset=UTF-8")
+		response.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`.csv"`)
+		response.WriteHeader(http.StatusOK)
+
+		writer := csv.NewWriter(response)
+
+		if err := writer.Write(model.AuditLogCSVHeader()); err != nil {
+			return derp.Wrap(err, location, "Error writing CSV")
+		}
+
+		for iterator.Next(&auditLog) {
+
+			if err := writer.Write(auditLog.CSVRow()); err != nil {
+				return derp.Wrap(err, location, "Error writing CSV")
+			}
+
+			auditLog = model.AuditLog{}
+		}
+
+		writer.Flush()
+		return derp.Wrap(writer.Error(), location, "Error writing CSV")
+	}
+}
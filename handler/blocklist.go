@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/benpate/steranko"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostBlocklistSync downloads a Blocklist immediately, so that an administrator can preview
+// its changes.  It can only be called by an authenticated administrator.
+func PostBlocklistSync(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostBlocklistSync"
+
+	blocklist := model.NewBlocklist()
+
+	if err := blocklist_Load(ctx, factory, &blocklist); err != nil {
+		return derp.Wrap(err, location, "Error loading Blocklist")
+	}
+
+	// Errors are saved into the Blocklist status, so they are displayed on the page instead
+	if err := factory.Blocklist().Sync(&blocklist); err != nil {
+		derp.Report(derp.Wrap(err, location, "Error synchronizing Blocklist", blocklist.URL))
+	}
+
+	ctx.Response().Header().Set("HX-Trigger", "refreshPage")
+	return ctx.NoContent(http.StatusOK)
+}
+
+// PostBlocklistApply applies all of the pending changes in a Blocklist.
+// It can only be called by an authenticated administrator.
+func PostBlocklistApply(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostBlocklistApply"
+
+	blocklist := model.NewBlocklist()
+
+	if err := blocklist_Load(ctx, factory, &blocklist); err != nil {
+		return derp.Wrap(err, location, "Error loading Blocklist")
+	}
+
+	if err := factory.Blocklist().Apply(&blocklist); err != nil {
+		return derp.Wrap(err, location, "Error applying Blocklist", blocklist.BlocklistID)
+	}
+
+	ctx.Response().Header().Set("HX-Trigger", "refreshPage")
+	return ctx.NoContent(http.StatusOK)
+}
+
+// blocklist_Load verifies that the request was made by an administrator, then
+// loads the Blocklist identified in the URL.
+func blocklist_Load(ctx *steranko.Context, factory *domain.Factory, blocklist *model.Blocklist) error {
+
+	const location = "handler.blocklist_Load"
+
+	// Verify that this is an Administrator
+	if !getAuthorization(ctx).DomainOwner {
+		return derp.NewForbiddenError(location, "Only administrators can call this method")
+	}
+
+	blocklistID, err := primitive.ObjectIDFromHex(ctx.Param("blocklistId"))
+
+	if err != nil {
+		return derp.Wrap(err, location, "BlocklistID must be a valid ObjectID", ctx.Param("blocklistId"), derp.WithCode(http.StatusBadRequest))
+	}
+
+	if err := factory.Blocklist().LoadByID(blocklistID, blocklist); err != nil {
+		return derp.Wrap(err, location, "Error loading Blocklist", blocklistID)
+	}
+
+	return nil
+}
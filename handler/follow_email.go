@@ -77,6 +77,11 @@ func PostEmailFollower(serverFactory *server.Factory) echo.HandlerFunc {
 			return derp.Wrap(err, location, "Error sending confirmation email")
 		}
 
+		// SavedSearches do not have a profile page, so confirm inline
+		if follower.ParentType == model.FollowerTypeSearch {
+			return ctx.HTML(http.StatusOK, "Please check your email to confirm your subscription.")
+		}
+
 		// Forward the user to the confirmation page
 		return ctx.Redirect(http.StatusFound, "/@"+follower.ParentID.Hex()+"/follow-email-sent")
 	}
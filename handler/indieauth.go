@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/indieauth"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/steranko"
+)
+
+// GetIndieAuthMetadata returns the OAuth/IndieAuth server metadata for this domain
+// https://indieauth.spec.indieweb.org/#indieauth-server-metadata
+func GetIndieAuthMetadata(ctx *steranko.Context, factory *domain.Factory) error {
+
+	host := factory.Host()
+
+	return ctx.JSON(http.StatusOK, mapof.Any{
+		"issuer":                 host + "/",
+		"authorization_endpoint": host + "/oauth/authorize",
+		"token_endpoint":         host + "/oauth/token",
+		"introspection_endpoint": host + "/oauth/introspect",
+		"revocation_endpoint":    host + "/oauth/revoke",
+		"revocation_endpoint_auth_methods_supported":    []string{"none"},
+		"introspection_endpoint_auth_methods_supported": []string{"Bearer"},
+		"scopes_supported":                               model.IndieAuthScopes,
+		"response_types_supported":                       []string{"code"},
+		"grant_types_supported":                          []string{"authorization_code"},
+		"code_challenge_methods_supported":               []string{indieauth.CodeChallengeMethodS256},
+		"authorization_response_iss_parameter_supported": true,
+		"service_documentation":                          "https://indieauth.spec.indieweb.org/",
+	})
+}
+
+// PostOAuthIntrospect returns information about an access token.  Callers must
+// authenticate with their own (valid) access token.
+// https://indieauth.spec.indieweb.org/#access-token-verification
+func PostOAuthIntrospect(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostOAuthIntrospect"
+
+	// RULE: Callers must present a valid access token
+	callerToken := strings.TrimPrefix(ctx.Request().Header.Get("Authorization"), "Bearer ")
+
+	if _, _, err := parseOAuthAccessToken(factory, callerToken); err != nil {
+		return derp.Wrap(err, location, "Invalid access token")
+	}
+
+	// Inspect the requested token.  Invalid tokens are reported as inactive.
+	authorization, userToken, err := parseOAuthAccessToken(factory, ctx.FormValue("token"))
+
+	if err != nil {
+		return ctx.JSON(http.StatusOK, mapof.Any{"active": false})
+	}
+
+	user := model.NewUser()
+
+	if err := factory.User().LoadByID(authorization.UserID, &user); err != nil {
+		return ctx.JSON(http.StatusOK, mapof.Any{"active": false})
+	}
+
+	client := model.NewOAuthClient()
+
+	if err := factory.OAuthClient().LoadByClientID(userToken.ClientID, &client); err != nil {
+		return ctx.JSON(http.StatusOK, mapof.Any{"active": false})
+	}
+
+	return ctx.JSON(http.StatusOK, mapof.Any{
+		"active":    true,
+		"me":        user.ProfileURL,
+		"client_id": client.PublicID(),
+		"scope":     strings.Join(userToken.Scopes, " "),
+		"iat":       userToken.CreateDate / 1000,
+	})
+}
+
+// parseOAuthAccessToken validates an access token that was issued by the OAuth/IndieAuth
+// server.  The token must be a valid JWT, and must not have been revoked.
+func parseOAuthAccessToken(factory *domain.Factory, tokenString string) (model.Authorization, model.OAuthUserToken, error) {
+
+	const location = "handler.parseOAuthAccessToken"
+
+	if tokenString == "" {
+		return model.Authorization{}, model.OAuthUserToken{}, derp.NewUnauthorizedError(location, "Access token is required")
+	}
+
+	token, err := factory.JWT().ParseString(tokenString)
+
+	if err != nil {
+		return model.Authorization{}, model.OAuthUserToken{}, derp.NewUnauthorizedError(location, "Invalid access token", err.Error())
+	}
+
+	authorization, ok := token.Claims.(*model.Authorization)
+
+	if !token.Valid || !ok || authorization.UserID.IsZero() {
+		return model.Authorization{}, model.OAuthUserToken{}, derp.NewUnauthorizedError(location, "Invalid access token")
+	}
+
+	// RULE: Revoked tokens are not valid
+	userToken := model.NewOAuthUserToken()
+
+	if err := factory.OAuthUserToken().LoadByToken(tokenString, &userToken); err != nil {
+		return model.Authorization{}, model.OAuthUserToken{}, derp.NewUnauthorizedError(location, "Access token has been revoked")
+	}
+
+	return *authorization, userToken, nil
+}
+
+// indieAuthProfile returns the profile information that is shared with IndieAuth clients
+// https://indieauth.spec.indieweb.org/#profile-information
+func indieAuthProfile(user *model.User, includeEmail bool) mapof.Any {
+
+	result := mapof.Any{
+		"name":  user.DisplayName,
+		"url":   user.ProfileURL,
+		"photo": user.ActivityPubIconURL(),
+	}
+
+	if includeEmail {
+		result["email"] = user.EmailAddress
+	}
+
+	return result
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/benpate/derp"
+	"github.com/benpate/steranko"
+)
+
+// PostIntegrationSecret adds (or replaces) a secret value that the "call-url" step
+// can send to external APIs.  Secret values are write-only, and are never displayed.
+// It can only be called by an authenticated administrator.
+func PostIntegrationSecret(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostIntegrationSecret"
+
+	// Verify that this is an Administrator
+	authorization := getAuthorization(ctx)
+
+	if !authorization.DomainOwner {
+		return derp.NewForbiddenError(location, "Only administrators can call this method")
+	}
+
+	name := strings.TrimSpace(ctx.FormValue("name"))
+
+	if err := factory.Domain().SetSecret(name, ctx.FormValue("value")); err != nil {
+		return derp.Wrap(err, location, "Error saving secret", name)
+	}
+
+	// Success.
+	ctx.Response().Header().Set("HX-Trigger", "refreshPage")
+	return ctx.NoContent(http.StatusOK)
+}
+
+// PostIntegrationSecretRemove removes a secret value from the domain.
+// It can only be called by an authenticated administrator.
+func PostIntegrationSecretRemove(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostIntegrationSecretRemove"
+
+	// Verify that this is an Administrator
+	authorization := getAuthorization(ctx)
+
+	if !authorization.DomainOwner {
+		return derp.NewForbiddenError(location, "Only administrators can call this method")
+	}
+
+	name := ctx.FormValue("name")
+
+	if err := factory.Domain().RemoveSecret(name); err != nil {
+		return derp.Wrap(err, location, "Error removing secret", name)
+	}
+
+	// Success.
+	ctx.Response().Header().Set("HX-Trigger", "refreshPage")
+	return ctx.NoContent(http.StatusOK)
+}
@@ -297,6 +297,8 @@ func PostAccount_Block(serverFactory *server.Factory) func(model.Authorization,
 			return object.Relationship{}, derp.Wrap(err, location, "Error saving rule")
 		}
 
+		writeRuleAuditLog(factory, auth, model.AuditActionRuleCreate, &rule)
+
 		// Return the Rule record as a Toot
 		return rule.Toot(), nil
 	}
@@ -328,6 +330,8 @@ func PostAccount_Unblock(serverFactory *server.Factory) func(model.Authorization
 			return object.Relationship{}, derp.Wrap(err, location, "Error deleting rule")
 		}
 
+		writeRuleAuditLog(factory, auth, model.AuditActionRuleDelete, &rule)
+
 		// Return success
 		return rule.Toot(), nil
 	}
@@ -357,6 +361,8 @@ func PostAccount_Mute(serverFactory *server.Factory) func(model.Authorization, t
 			return object.Relationship{}, derp.Wrap(err, location, "Error saving rule")
 		}
 
+		writeRuleAuditLog(factory, auth, model.AuditActionRuleCreate, &rule)
+
 		// Return the Rule record as a Toot
 		return rule.Toot(), nil
 	}
@@ -388,6 +394,8 @@ func PostAccount_Unmute(serverFactory *server.Factory) func(model.Authorization,
 			return object.Relationship{}, derp.Wrap(err, location, "Error deleting rule")
 		}
 
+		writeRuleAuditLog(factory, auth, model.AuditActionRuleDelete, &rule)
+
 		// Return success
 		return rule.Toot(), nil
 	}
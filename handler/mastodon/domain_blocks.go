@@ -67,6 +67,8 @@ func PostDomainBlock(serverFactory *server.Factory) func(model.Authorization, tx
 			return struct{}{}, derp.Wrap(err, location, "Error saving rule")
 		}
 
+		writeRuleAuditLog(factory, auth, model.AuditActionRuleCreate, &rule)
+
 		return struct{}{}, nil
 	}
 }
@@ -97,6 +99,8 @@ func DeleteDomainBlock(serverFactory *server.Factory) func(model.Authorization,
 			return struct{}{}, derp.Wrap(err, location, "Error deleting rule")
 		}
 
+		writeRuleAuditLog(factory, auth, model.AuditActionRuleDelete, &rule)
+
 		return struct{}{}, nil
 	}
 }
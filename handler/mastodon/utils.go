@@ -4,6 +4,7 @@ import (
 	"net/url"
 	"strconv"
 
+	"github.com/EmissarySocial/emissary/domain"
 	"github.com/EmissarySocial/emissary/model"
 	"github.com/EmissarySocial/emissary/server"
 	"github.com/EmissarySocial/emissary/service"
@@ -114,3 +115,17 @@ func getStreamFromURL(serverFactory *server.Factory, streamURL string) (model.St
 	return stream, streamService, nil
 
 }
+
+// writeRuleAuditLog records a Rule change made through the Mastodon API in the domain's audit log.
+// The API does not expose the original request, so the IP address and User-Agent are not recorded.
+func writeRuleAuditLog(factory *domain.Factory, auth model.Authorization, action string, rule *model.Rule) {
+
+	auditLog := model.NewAuditLog(action).
+		WithActor(auth.UserID, "").
+		WithObject("Rule", rule.RuleID).
+		WithSummary(rule.Type + ": " + rule.Trigger + " (via Mastodon API)")
+
+	if err := factory.AuditLog().Add(nil, auditLog); err != nil {
+		derp.Report(derp.Wrap(err, "handler.mastodon.writeRuleAuditLog", "Error writing audit log", auditLog))
+	}
+}
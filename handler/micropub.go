@@ -0,0 +1,511 @@
+package handler
+
+import (
+	"html"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/micropub"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/slice"
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/benpate/steranko"
+	"github.com/benpate/turbine/queue"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// micropubMediaFields are the multipart form fields that can contain uploaded files
+var micropubMediaFields = []string{"photo", "video", "audio"}
+
+// GetMicropub handles Micropub queries (q=config, q=source, and q=syndicate-to)
+// https://www.w3.org/TR/micropub/#querying
+func GetMicropub(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.GetMicropub"
+
+	// Verify the access token.  Queries do not require a specific scope.
+	authorization, err := micropubAuthorization(ctx, factory, "")
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid access token")
+	}
+
+	switch ctx.QueryParam("q") {
+
+	case "config":
+		return ctx.JSON(http.StatusOK, mapof.Any{
+			"media-endpoint": factory.Host() + "/.micropub/media",
+			"syndicate-to":   micropubSyndicateTo(factory),
+			"q":              []string{"config", "source", "syndicate-to"},
+		})
+
+	case "syndicate-to":
+		return ctx.JSON(http.StatusOK, mapof.Any{
+			"syndicate-to": micropubSyndicateTo(factory),
+		})
+
+	case "source":
+
+		// Load the requested Stream
+		streamService := factory.Stream()
+		stream := model.NewStream()
+
+		if err := streamService.LoadByURL(ctx.QueryParam("url"), &stream); err != nil {
+			return derp.Wrap(err, location, "Error loading Stream", ctx.QueryParam("url"))
+		}
+
+		// RULE: User must be allowed to view the Stream (so that drafts and private Streams are not exposed)
+		if err := streamService.UserCan(&authorization, &stream, "view"); err != nil {
+			return derp.NewForbiddenError(location, "User is not authorized to view this stream", ctx.QueryParam("url"))
+		}
+
+		// Return the requested properties
+		queryParams := ctx.QueryParams()
+		names := append(queryParams["properties"], queryParams["properties[]"]...)
+
+		return ctx.JSON(http.StatusOK, mapof.Any{
+			"type":       []string{"h-entry"},
+			"properties": micropubProperties(&stream).Filter(names...),
+		})
+	}
+
+	return derp.NewBadRequestError(location, "Unsupported query", ctx.QueryParam("q"))
+}
+
+// PostMicropub handles Micropub create, update, delete, and undelete requests
+// https://www.w3.org/TR/micropub/#create
+func PostMicropub(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostMicropub"
+
+	// Parse the Micropub request (JSON or form-encoded)
+	request, err := micropub.Parse(ctx.Request())
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid Micropub request")
+	}
+
+	// Verify the access token.  Each action requires the matching scope.
+	authorization, err := micropubAuthorization(ctx, factory, request.Action)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid access token")
+	}
+
+	// Load the User who is posting
+	userService := factory.User()
+	user := model.NewUser()
+
+	if err := userService.LoadByID(authorization.UserID, &user); err != nil {
+		return derp.Wrap(err, location, "Error loading User", authorization.UserID)
+	}
+
+	switch request.Action {
+
+	case micropub.ActionCreate:
+		return postMicropub_Create(ctx, factory, &authorization, &user, request)
+
+	case micropub.ActionUpdate:
+		return postMicropub_Update(ctx, factory, &authorization, &user, request)
+
+	case micropub.ActionDelete:
+		return postMicropub_Delete(ctx, factory, &authorization, &user, request)
+
+	case micropub.ActionUndelete:
+		return postMicropub_Undelete(ctx, factory, &authorization, &user, request)
+	}
+
+	return derp.NewBadRequestError(location, "Unsupported action", request.Action)
+}
+
+// PostMicropubMedia handles uploads to the Micropub media endpoint
+// https://www.w3.org/TR/micropub/#media-endpoint
+func PostMicropubMedia(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostMicropubMedia"
+
+	authorization, err := micropubAuthorization(ctx, factory, "media")
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid access token")
+	}
+
+	form, err := ctx.MultipartForm()
+
+	if err != nil {
+		return derp.NewBadRequestError(location, "Invalid multipart form", err.Error())
+	}
+
+	files := form.File["file"]
+
+	if len(files) == 0 {
+		return derp.NewBadRequestError(location, "File is required")
+	}
+
+	attachment, err := micropubUpload(factory, authorization.UserID, files[0])
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error uploading file")
+	}
+
+	ctx.Response().Header().Set("Location", attachment.URL)
+	return ctx.NoContent(http.StatusCreated)
+}
+
+/******************************************
+ * Micropub Actions
+ ******************************************/
+
+// postMicropub_Create creates a new Stream in the User's outbox
+func postMicropub_Create(ctx *steranko.Context, factory *domain.Factory, authorization *model.Authorization, user *model.User, request micropub.Request) error {
+
+	const location = "handler.postMicropub_Create"
+
+	// RULE: Only h-entry posts are supported
+	if request.Type != "entry" {
+		return derp.NewBadRequestError(location, "Only h-entry posts are supported", request.Type)
+	}
+
+	// Upload any files that were included in the request
+	if form := ctx.Request().MultipartForm; form != nil {
+		for _, field := range micropubMediaFields {
+			for _, fileHeader := range form.File[field] {
+
+				attachment, err := micropubUpload(factory, user.UserID, fileHeader)
+
+				if err != nil {
+					return derp.Wrap(err, location, "Error uploading file", field)
+				}
+
+				request.Properties[field] = append(request.Properties[field], attachment.URL)
+			}
+		}
+	}
+
+	// Create the new Stream in the User's outbox
+	streamService := factory.Stream()
+	stream := model.NewStream()
+	stream.TemplateID = micropubTemplateID(factory, user, request.Properties)
+	stream.ParentID = user.UserID
+	stream.ParentIDs = []primitive.ObjectID{user.UserID}
+	stream.AttributedTo = user.PersonLink()
+
+	if err := micropubApply(factory, &stream, request.Properties); err != nil {
+		return derp.Wrap(err, location, "Error applying properties")
+	}
+
+	// Embed media into the content, and use the first photo as the icon
+	stream.Content = factory.Content().New(model.ContentFormatHTML, stream.Content.Raw+micropubMediaHTML(request.Properties))
+	stream.IconURL = request.Properties.First("photo")
+
+	// Use the published date, if provided
+	if published, err := time.Parse(time.RFC3339, request.Properties.First("published")); err == nil {
+		stream.PublishDate = published.Unix()
+	}
+
+	// Verify user permissions
+	if err := streamService.UserCan(authorization, &stream, "create"); err != nil {
+		return derp.NewForbiddenError(location, "User is not authorized to create this stream", stream.TemplateID)
+	}
+
+	// Save the Stream
+	if err := streamService.Save(&stream, "Created via Micropub"); err != nil {
+		return derp.Wrap(err, location, "Error saving stream")
+	}
+
+	// Publish the Stream to the User's outbox (unless it is a draft)
+	if request.Properties.First("post-status") != "draft" {
+		if err := streamService.Publish(user, &stream, true); err != nil {
+			return derp.Wrap(err, location, "Error publishing stream")
+		}
+	}
+
+	ctx.Response().Header().Set("Location", stream.URL)
+	return ctx.NoContent(http.StatusCreated)
+}
+
+// postMicropub_Update updates the properties of an existing Stream
+func postMicropub_Update(ctx *steranko.Context, factory *domain.Factory, authorization *model.Authorization, user *model.User, request micropub.Request) error {
+
+	const location = "handler.postMicropub_Update"
+
+	streamService := factory.Stream()
+	stream := model.NewStream()
+
+	if err := streamService.LoadByURL(request.URL, &stream); err != nil {
+		return derp.Wrap(err, location, "Error loading stream", request.URL)
+	}
+
+	if err := streamService.UserCan(authorization, &stream, "edit"); err != nil {
+		return derp.NewForbiddenError(location, "User is not authorized to edit this stream", request.URL)
+	}
+
+	// Apply changes to the Stream's current properties
+	properties := micropubProperties(&stream)
+	properties.Apply(request)
+
+	if err := micropubApply(factory, &stream, properties); err != nil {
+		return derp.Wrap(err, location, "Error applying properties")
+	}
+
+	if err := streamService.Save(&stream, "Updated via Micropub"); err != nil {
+		return derp.Wrap(err, location, "Error saving stream")
+	}
+
+	// Send updates to followers
+	if stream.IsPublished() {
+		if err := streamService.Publish(user, &stream, true); err != nil {
+			return derp.Wrap(err, location, "Error publishing stream")
+		}
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// postMicropub_Delete removes an existing Stream
+func postMicropub_Delete(ctx *steranko.Context, factory *domain.Factory, authorization *model.Authorization, user *model.User, request micropub.Request) error {
+
+	const location = "handler.postMicropub_Delete"
+
+	streamService := factory.Stream()
+	stream := model.NewStream()
+
+	if err := streamService.LoadByURL(request.URL, &stream); err != nil {
+		return derp.Wrap(err, location, "Error loading stream", request.URL)
+	}
+
+	if err := streamService.UserCan(authorization, &stream, "delete"); err != nil {
+		return derp.NewForbiddenError(location, "User is not authorized to delete this stream", request.URL)
+	}
+
+	// Notify followers that the Stream is gone
+	if stream.IsPublished() {
+		if err := streamService.UnPublish(user, &stream, true); err != nil {
+			return derp.Wrap(err, location, "Error unpublishing stream")
+		}
+	}
+
+	if err := streamService.Delete(&stream, "Deleted via Micropub"); err != nil {
+		return derp.Wrap(err, location, "Error deleting stream")
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// postMicropub_Undelete restores a previously deleted Stream
+func postMicropub_Undelete(ctx *steranko.Context, factory *domain.Factory, authorization *model.Authorization, user *model.User, request micropub.Request) error {
+
+	const location = "handler.postMicropub_Undelete"
+
+	streamService := factory.Stream()
+	stream := model.NewStream()
+
+	if err := streamService.LoadDeletedByURL(request.URL, &stream); err != nil {
+		return derp.Wrap(err, location, "Error loading deleted stream", request.URL)
+	}
+
+	if err := streamService.UserCan(authorization, &stream, "delete"); err != nil {
+		return derp.NewForbiddenError(location, "User is not authorized to restore this stream", request.URL)
+	}
+
+	// Restore the Stream and re-publish it to followers
+	stream.Journal.DeleteDate = 0
+
+	if err := streamService.Publish(user, &stream, true); err != nil {
+		return derp.Wrap(err, location, "Error publishing stream")
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+/******************************************
+ * Helper Functions
+ ******************************************/
+
+// micropubAuthorization validates the access token in a Micropub request.  The token can
+// be sent in the "Authorization" header, or in the "access_token" form field.  If a scope is
+// provided, then the token must include that scope (or the general "write" scope).
+func micropubAuthorization(ctx *steranko.Context, factory *domain.Factory, scope string) (model.Authorization, error) {
+
+	const location = "handler.micropubAuthorization"
+
+	tokenString := strings.TrimPrefix(ctx.Request().Header.Get("Authorization"), "Bearer ")
+
+	if tokenString == "" {
+		tokenString = ctx.FormValue("access_token")
+	}
+
+	authorization, _, err := parseOAuthAccessToken(factory, tokenString)
+
+	if err != nil {
+		return model.Authorization{}, derp.Wrap(err, location, "Invalid access token")
+	}
+
+	if scope != "" {
+		scopes := authorization.Scopes()
+		if !slice.Contains(scopes, scope) && !slice.Contains(scopes, "write") {
+			return model.Authorization{}, derp.NewForbiddenError(location, "Insufficient scope", scope)
+		}
+	}
+
+	return authorization, nil
+}
+
+// micropubSyndicateTo returns the syndication targets configured for this Domain
+func micropubSyndicateTo(factory *domain.Factory) []mapof.String {
+
+	targets := factory.Domain().Get().Syndication
+	result := make([]mapof.String, 0, len(targets))
+
+	for _, target := range targets {
+		result = append(result, mapof.String{
+			"uid":  target.Value,
+			"name": target.Label,
+		})
+	}
+
+	return result
+}
+
+// micropubTemplateID chooses the Template for a new Stream.  Posts with a name
+// are articles, and all other posts use the User's note template.
+func micropubTemplateID(factory *domain.Factory, user *model.User, properties micropub.Properties) string {
+
+	if properties.First("name") != "" {
+		if articles := factory.Template().ListByContainerLimited("outbox", sliceof.String{"article"}); len(articles) > 0 {
+			return articles[0].Value
+		}
+	}
+
+	return firstOf(user.NoteTemplate, "outbox-message")
+}
+
+// micropubProperties returns the microformats2 properties of a Stream
+func micropubProperties(stream *model.Stream) micropub.Properties {
+
+	result := micropub.Properties{}
+	result.SetString("name", stream.Label)
+	result.SetString("summary", stream.Summary)
+	result.SetHTML("content", stream.Content.HTML)
+	result.SetStrings("category", stream.Hashtags)
+	result.SetString("in-reply-to", stream.InReplyTo)
+	result.SetString("photo", stream.IconURL)
+	result.SetStrings("mp-syndicate-to", stream.Syndication.Values)
+
+	if stream.IsPublished() {
+		result.SetString("published", time.Unix(stream.PublishDate, 0).UTC().Format(time.RFC3339))
+	}
+
+	return result
+}
+
+// micropubApply copies microformats2 properties into a Stream
+func micropubApply(factory *domain.Factory, stream *model.Stream, properties micropub.Properties) error {
+
+	stream.Label = properties.First("name")
+	stream.Summary = properties.First("summary")
+	stream.Content = factory.Content().New(model.ContentFormatHTML, properties.HTML("content"))
+	stream.Hashtags = properties.Strings("category")
+	stream.InReplyTo = properties.First("in-reply-to")
+
+	// Only syndicate to targets that are configured for this Domain
+	targets := factory.Domain().Get().Syndication
+	syndication := make([]string, 0)
+
+	for _, value := range properties.Strings("mp-syndicate-to") {
+		for _, target := range targets {
+			if target.Value == value {
+				syndication = append(syndication, value)
+			}
+		}
+	}
+
+	if err := stream.Syndication.SetValue(syndication); err != nil {
+		return derp.Wrap(err, "handler.micropubApply", "Error setting syndication targets", syndication)
+	}
+
+	return nil
+}
+
+// micropubMediaHTML returns HTML tags for the photos, videos, and audio in a new post
+func micropubMediaHTML(properties micropub.Properties) string {
+
+	var result strings.Builder
+
+	for _, value := range properties["photo"] {
+		photo := micropub.Properties{"photo": {value}}
+		alt := ""
+
+		if object, ok := value.(map[string]any); ok {
+			alt, _ = object["alt"].(string)
+		}
+
+		result.WriteString(`<p><img src="` + html.EscapeString(photo.First("photo")) + `" alt="` + html.EscapeString(alt) + `"></p>`)
+	}
+
+	for _, src := range properties.Strings("video") {
+		result.WriteString(`<p><video src="` + html.EscapeString(src) + `" controls></video></p>`)
+	}
+
+	for _, src := range properties.Strings("audio") {
+		result.WriteString(`<p><audio src="` + html.EscapeString(src) + `" controls></audio></p>`)
+	}
+
+	return result.String()
+}
+
+// micropubUpload saves an uploaded file as an Attachment on the User,
+// then queues it for background processing.
+func micropubUpload(factory *domain.Factory, userID primitive.ObjectID, fileHeader *multipart.FileHeader) (model.Attachment, error) {
+
+	const location = "handler.micropubUpload"
+
+	attachmentService := factory.Attachment()
+
+	// RULE: Uploads cannot exceed the storage quota
+	if err := attachmentService.CheckQuota(userID, fileHeader.Size); err != nil {
+		return model.Attachment{}, derp.Wrap(err, location, "Storage quota exceeded")
+	}
+
+	source, err := fileHeader.Open()
+
+	if err != nil {
+		return model.Attachment{}, derp.Wrap(err, location, "Error reading uploaded file", fileHeader.Filename)
+	}
+
+	defer source.Close()
+
+	attachment := model.NewAttachment(model.AttachmentObjectTypeUser, userID)
+	attachment.Original = fileHeader.Filename
+	attachment.Category = "micropub"
+	attachment.UserID = userID
+	attachment.Size = fileHeader.Size
+	attachment.Status = model.AttachmentStatusWorking
+
+	if err := attachmentService.PutOriginal(&attachment, source); err != nil {
+		return model.Attachment{}, derp.Wrap(err, location, "Error saving file to mediaserver", fileHeader.Filename)
+	}
+
+	if err := attachmentService.Save(&attachment, "Uploaded via Micropub"); err != nil {
+		return model.Attachment{}, derp.Wrap(err, location, "Error saving attachment", fileHeader.Filename)
+	}
+
+	// Process the file (metadata, dimensions, renditions) in the background
+	task := queue.NewTask("ProcessMedia", mapof.Any{
+		"host":         factory.Hostname(),
+		"objectType":   attachment.ObjectType,
+		"objectId":     attachment.ObjectID.Hex(),
+		"attachmentId": attachment.AttachmentID.Hex(),
+	})
+
+	if err := factory.Queue().Publish(task); err != nil {
+		return model.Attachment{}, derp.Wrap(err, location, "Error publishing task", task)
+	}
+
+	return attachment, nil
+}
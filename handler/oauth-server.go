@@ -3,12 +3,16 @@ package handler
 import (
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/EmissarySocial/emissary/build"
+	"github.com/EmissarySocial/emissary/domain"
 	"github.com/EmissarySocial/emissary/model"
 	"github.com/EmissarySocial/emissary/server"
 	"github.com/benpate/derp"
 	"github.com/benpate/html"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/slice"
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -57,35 +61,38 @@ func PostOAuthAuthorization(serverFactory *server.Factory) echo.HandlerFunc {
 
 	return func(ctx echo.Context) error {
 
-		// Collect Form parameters
-		transaction := model.NewOAuthAuthorizationRequest()
+		// Locate the current domain
+		factory, err := serverFactory.ByContext(ctx)
 
-		if err := ctx.Bind(&transaction); err != nil {
-			return derp.Wrap(err, location, "Invalid form parameters")
+		if err != nil {
+			return derp.NewInternalError(location, "Invalid Domain.")
 		}
 
-		// Convert the ClientID
-		clientID, err := primitive.ObjectIDFromHex(transaction.ClientID)
-
-		if err != nil {
-			return derp.Wrap(err, location, "Invalid client_id")
+		// IndieAuth clients can redeem a code at the authorization endpoint to verify a profile URL
+		// https://indieauth.spec.indieweb.org/#profile-url-response
+		if ctx.FormValue("grant_type") == "authorization_code" {
+			return postOAuthAuthorization_profile(ctx, factory)
 		}
 
-		// Locate the current domain
-		factory, err := serverFactory.ByContext(ctx)
+		// Collect Form parameters
+		transaction := model.NewOAuthAuthorizationRequest()
 
-		if err != nil {
-			return derp.NewInternalError(location, "Invalid Domain.")
+		if err := ctx.Bind(&transaction); err != nil {
+			return derp.Wrap(err, location, "Invalid form parameters")
 		}
 
-		// Get Authorization
+		// Get Authorization.  Only signed-in Users can authorize (and discover) new clients.
 		authorization := getAuthorization(ctx)
 
+		if !authorization.IsAuthenticated() {
+			return derp.NewUnauthorizedError(location, "You must be signed in to authorize an application")
+		}
+
 		// Get Application
 		clientService := factory.OAuthClient()
 		application := model.NewOAuthClient()
 
-		if err := clientService.LoadByClientID(clientID, &application); err != nil {
+		if err := clientService.LoadByPublicID(transaction.ClientID, &application); err != nil {
 			return derp.Wrap(err, location, "Error loading OAuth Application")
 		}
 
@@ -102,11 +109,15 @@ func PostOAuthAuthorization(serverFactory *server.Factory) echo.HandlerFunc {
 			return derp.Wrap(err, location, "Error creating OAuthUserToken")
 		}
 
+		writeAuditLog(ctx, factory, auditAuthenticated(ctx, factory, model.AuditActionOAuthAuthorize).
+			WithObject("OAuthClient", application.ClientID).
+			WithSummary("Authorized "+application.Name+" ("+strings.Join(userToken.Scopes, " ")+")"))
+
 		// Complete the transaction based on the grant type
 		switch transaction.ResponseType {
 
 		case "code":
-			return postOAuthAuthorization_code(ctx, userToken, transaction)
+			return postOAuthAuthorization_code(ctx, factory.Host(), userToken, transaction)
 
 		case "token":
 			return postOAuthAuthorization_token(ctx, userToken, transaction)
@@ -117,7 +128,7 @@ func PostOAuthAuthorization(serverFactory *server.Factory) echo.HandlerFunc {
 }
 
 // postOAuthAuthorization_code handles `code` grant types used by server authentication flow
-func postOAuthAuthorization_code(ctx echo.Context, userToken model.OAuthUserToken, transaction model.OAuthAuthorizationRequest) error {
+func postOAuthAuthorization_code(ctx echo.Context, issuer string, userToken model.OAuthUserToken, transaction model.OAuthAuthorizationRequest) error {
 
 	// If this magic value is passed as the redirect URI, then we just return the token in the <title> tag of the HTML
 	// https://docs.joinmastodon.org/methods/apps/#form-data-parameters
@@ -137,9 +148,15 @@ func postOAuthAuthorization_code(ctx echo.Context, userToken model.OAuthUserToke
 		return derp.Wrap(err, "handler.postOAuthAuthorization_code", "Invalid redirect_uri", transaction.RedirectURI)
 	}
 
-	// Add the code to the URI
+	// Add the code (along with IndieAuth state and issuer values) to the URI
 	queryString := redirectURI.Query()
 	queryString.Set("code", userToken.Code())
+	queryString.Set("iss", issuer)
+
+	if transaction.State != "" {
+		queryString.Set("state", transaction.State)
+	}
+
 	redirectURI.RawQuery = queryString.Encode()
 
 	return ctx.Redirect(http.StatusFound, redirectURI.String())
@@ -187,38 +204,99 @@ func PostOAuthToken(serverFactory *server.Factory) echo.HandlerFunc {
 			return derp.Wrap(err, location, "Invalid form parameters")
 		}
 
-		// Convert client ID
-		clientID, err := primitive.ObjectIDFromHex(transaction.ClientID)
+		// Locate the domain factory
+		factory, err := serverFactory.ByContext(ctx)
 
 		if err != nil {
-			return derp.Wrap(err, location, "Invalid client_id")
+			return derp.Wrap(err, location, "Invalid Domain.")
 		}
 
-		// Convert transaction.Code => userToken
-		userTokenID, err := primitive.ObjectIDFromHex(transaction.Code)
+		// Redeem the authorization code
+		userToken, err := redeemOAuthCode(factory, transaction)
 
 		if err != nil {
-			return derp.Wrap(err, location, "Invalid code")
+			return derp.Wrap(err, location, "Error redeeming authorization code")
 		}
 
-		// Locate the domain factory
-		factory, err := serverFactory.ByContext(ctx)
+		// Load the User who granted the token
+		user := model.NewUser()
 
-		if err != nil {
-			return derp.Wrap(err, location, "Invalid Domain.")
+		if err := factory.User().LoadByID(userToken.UserID, &user); err != nil {
+			return derp.Wrap(err, location, "Error loading User")
 		}
 
-		// Load the UserToken
-		userTokenService := factory.OAuthUserToken()
-		userToken := model.NewOAuthUserToken()
+		writeAuditLog(ctx, factory, model.NewAuditLog(model.AuditActionOAuthToken).
+			WithActor(user.UserID, user.Username).
+			WithObject("OAuthClient", userToken.ClientID).
+			WithSummary("Issued access token ("+strings.Join(userToken.Scopes, " ")+")"))
 
-		if err := userTokenService.LoadByClientAndCode(userTokenID, clientID, transaction.ClientSecret, &userToken); err != nil {
-			return derp.Wrap(err, location, "Error loading OAuthUserToken")
+		// Return the Token (with IndieAuth profile information) as JSON
+		result := userToken.JSONResponse()
+		result["me"] = user.ProfileURL
+
+		if slice.Contains(userToken.Scopes, "profile") {
+			result["profile"] = indieAuthProfile(&user, slice.Contains(userToken.Scopes, "email"))
 		}
 
-		// Return the Token as JSON
-		return ctx.JSON(http.StatusOK, userToken.JSONResponse())
+		return ctx.JSON(http.StatusOK, result)
+	}
+}
+
+// postOAuthAuthorization_profile redeems an authorization code for the User's profile URL, without
+// issuing an access token.
+// https://indieauth.spec.indieweb.org/#profile-url-response
+func postOAuthAuthorization_profile(ctx echo.Context, factory *domain.Factory) error {
+
+	const location = "handler.postOAuthAuthorization_profile"
+
+	transaction := model.NewOAuthUserTokenRequest()
+
+	if err := ctx.Bind(&transaction); err != nil {
+		return derp.Wrap(err, location, "Invalid form parameters")
+	}
+
+	userToken, err := redeemOAuthCode(factory, transaction)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error redeeming authorization code")
+	}
+
+	user := model.NewUser()
+
+	if err := factory.User().LoadByID(userToken.UserID, &user); err != nil {
+		return derp.Wrap(err, location, "Error loading User")
+	}
+
+	result := mapof.Any{"me": user.ProfileURL}
+
+	if slice.Contains(userToken.Scopes, "profile") {
+		result["profile"] = indieAuthProfile(&user, slice.Contains(userToken.Scopes, "email"))
 	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// redeemOAuthCode loads the client named in a token request, then exchanges the authorization code for an OAuthUserToken
+func redeemOAuthCode(factory *domain.Factory, transaction model.OAuthUserTokenRequest) (model.OAuthUserToken, error) {
+
+	const location = "handler.redeemOAuthCode"
+
+	// Load the client (IndieAuth clients are identified by URL).  Clients were already
+	// discovered when the code was issued, so anonymous requests never download anything.
+	client := model.NewOAuthClient()
+
+	if err := factory.OAuthClient().LoadKnownByPublicID(transaction.ClientID, &client); err != nil {
+		return model.OAuthUserToken{}, derp.Wrap(err, location, "Invalid client_id", transaction.ClientID)
+	}
+
+	// Exchange the code for a UserToken
+	userToken := model.NewOAuthUserToken()
+
+	if err := factory.OAuthUserToken().Redeem(client, transaction, &userToken); err != nil {
+		return model.OAuthUserToken{}, derp.Wrap(err, location, "Invalid authorization code")
+	}
+
+	return userToken, nil
 }
 
 func PostOAuthRevoke(serverFactory *server.Factory) echo.HandlerFunc {
@@ -234,13 +312,6 @@ func PostOAuthRevoke(serverFactory *server.Factory) echo.HandlerFunc {
 			return derp.Wrap(err, location, "Invalid form parameters")
 		}
 
-		// Convert clientID
-		clientID, err := primitive.ObjectIDFromHex(transaction.ClientID)
-
-		if err != nil {
-			return derp.Wrap(err, location, "Invalid client_id")
-		}
-
 		// Locate the domain factory
 		factory, err := serverFactory.ByContext(ctx)
 
@@ -252,7 +323,14 @@ func PostOAuthRevoke(serverFactory *server.Factory) echo.HandlerFunc {
 		userTokenService := factory.OAuthUserToken()
 		userToken := model.NewOAuthUserToken()
 
-		err = userTokenService.LoadByClientAndToken(clientID, transaction.ClientSecret, transaction.Token, &userToken)
+		// Registered clients must include their client secret.  IndieAuth clients have no
+		// secret, so they can revoke a token just by presenting it.
+		// https://indieauth.spec.indieweb.org/#token-revocation
+		if clientID, parseError := primitive.ObjectIDFromHex(transaction.ClientID); parseError == nil {
+			err = userTokenService.LoadByClientAndToken(clientID, transaction.ClientSecret, transaction.Token, &userToken)
+		} else {
+			err = userTokenService.LoadByToken(transaction.Token, &userToken)
+		}
 
 		if derp.NotFound(err) {
 			return nil
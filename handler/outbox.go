@@ -171,6 +171,15 @@ func buildOutbox(serverFactory *server.Factory, actionMethod build.ActionMethod)
 			return derp.Wrap(err, location, "Error creating builder")
 		}
 
+		// Profile pages advertise the IndieAuth server so that users can sign in elsewhere with their profile URL
+		// https://indieauth.spec.indieweb.org/#discovery-by-clients
+		if actionMethod == build.ActionMethodGet {
+			context.Response().Header().Add("Link", "</.well-known/oauth-authorization-server>; rel=\"indieauth-metadata\"")
+			context.Response().Header().Add("Link", "</oauth/authorize>; rel=\"authorization_endpoint\"")
+			context.Response().Header().Add("Link", "</oauth/token>; rel=\"token_endpoint\"")
+			context.Response().Header().Add("Link", "</.micropub>; rel=\"micropub\"")
+		}
+
 		// Forward to the standard page builder to complete the job
 		return build.AsHTML(factory, sterankoContext, builder, actionMethod)
 	}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/EmissarySocial/emissary/config"
+	"github.com/EmissarySocial/emissary/server"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/schema"
+	"github.com/labstack/echo/v4"
+)
+
+// GetProvisioningDomains returns a summary of every domain on this server
+func GetProvisioningDomains(factory *server.Factory) echo.HandlerFunc {
+
+	return func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, factory.DomainReports())
+	}
+}
+
+// GetProvisioningDomain returns a single domain, including user counts and storage statistics
+func GetProvisioningDomain(factory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.GetProvisioningDomain"
+
+	return func(ctx echo.Context) error {
+
+		report, err := factory.DomainReport(ctx.Param("domainId"))
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error loading domain report")
+		}
+
+		return ctx.JSON(http.StatusOK, report)
+	}
+}
+
+// PostProvisioningDomain creates a new domain and seeds its owner account
+func PostProvisioningDomain(factory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.PostProvisioningDomain"
+
+	return func(ctx echo.Context) error {
+
+		// New domains start with a generated DomainID and key encrypting key,
+		// which can be overridden by the request body.
+		domain := config.NewDomain()
+
+		if err := ctx.Bind(&domain); err != nil {
+			return derp.Wrap(err, location, "Error parsing request body", derp.WithCode(http.StatusBadRequest))
+		}
+
+		// New domains are always active, so that their owner can be created
+		domain.Suspended = false
+
+		if err := schema.New(config.DomainSchema()).Validate(&domain); err != nil {
+			return derp.Wrap(err, location, "Invalid domain", derp.WithCode(http.StatusBadRequest))
+		}
+
+		// RULE: DomainIDs and Hostnames must be unique
+		for _, existing := range factory.ListDomains() {
+
+			if existing.DomainID == domain.DomainID {
+				return derp.New(http.StatusConflict, location, "DomainID already exists", domain.DomainID)
+			}
+
+			if existing.Hostname == domain.Hostname {
+				return derp.New(http.StatusConflict, location, "Hostname already exists", domain.Hostname)
+			}
+		}
+
+		// Save the domain (this also starts the domain and creates the owner)
+		if err := factory.PutDomain(domain); err != nil {
+			return derp.Wrap(err, location, "Error creating domain", domain.Hostname)
+		}
+
+		report, err := factory.DomainReport(domain.DomainID)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error loading domain report", domain.DomainID)
+		}
+
+		return ctx.JSON(http.StatusCreated, report)
+	}
+}
+
+// PatchProvisioningDomain changes the label of an existing domain
+func PatchProvisioningDomain(factory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.PatchProvisioningDomain"
+
+	return func(ctx echo.Context) error {
+
+		domainID := ctx.Param("domainId")
+
+		transaction := struct {
+			Label string `json:"label"`
+		}{}
+
+		if err := ctx.Bind(&transaction); err != nil {
+			return derp.Wrap(err, location, "Error parsing request body", derp.WithCode(http.StatusBadRequest))
+		}
+
+		if transaction.Label == "" {
+			return derp.NewBadRequestError(location, "Label is required", domainID)
+		}
+
+		if err := factory.RelabelDomain(domainID, transaction.Label); err != nil {
+			return derp.Wrap(err, location, "Error updating domain", domainID)
+		}
+
+		return provisioningDomain_Report(ctx, factory, domainID)
+	}
+}
+
+// PostProvisioningDomainSuspend stops serving a domain without removing its data
+func PostProvisioningDomainSuspend(factory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.PostProvisioningDomainSuspend"
+
+	return func(ctx echo.Context) error {
+
+		domainID := ctx.Param("domainId")
+
+		if err := factory.SuspendDomain(domainID); err != nil {
+			return derp.Wrap(err, location, "Error suspending domain", domainID)
+		}
+
+		return provisioningDomain_Report(ctx, factory, domainID)
+	}
+}
+
+// PostProvisioningDomainResume restarts a suspended domain
+func PostProvisioningDomainResume(factory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.PostProvisioningDomainResume"
+
+	return func(ctx echo.Context) error {
+
+		domainID := ctx.Param("domainId")
+
+		if err := factory.ResumeDomain(domainID); err != nil {
+			return derp.Wrap(err, location, "Error resuming domain", domainID)
+		}
+
+		return provisioningDomain_Report(ctx, factory, domainID)
+	}
+}
+
+// DeleteProvisioningDomain removes a domain from the server configuration.
+// The domain's database is NOT deleted.
+func DeleteProvisioningDomain(factory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.DeleteProvisioningDomain"
+
+	return func(ctx echo.Context) error {
+
+		domainID := ctx.Param("domainId")
+
+		// Verify that the domain exists before deleting it
+		if !slices.ContainsFunc(factory.ListDomains(), func(domain config.Domain) bool { return domain.DomainID == domainID }) {
+			return derp.NewNotFoundError(location, "Domain not found", domainID)
+		}
+
+		if err := factory.DeleteDomain(domainID); err != nil {
+			return derp.Wrap(err, location, "Error deleting domain", domainID)
+		}
+
+		return ctx.NoContent(http.StatusNoContent)
+	}
+}
+
+// provisioningDomain_Report writes the current report for a domain to the response
+func provisioningDomain_Report(ctx echo.Context, factory *server.Factory, domainID string) error {
+
+	report, err := factory.DomainReport(domainID)
+
+	if err != nil {
+		return derp.Wrap(err, "handler.provisioningDomain_Report", "Error loading domain report", domainID)
+	}
+
+	return ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/EmissarySocial/emissary/config"
+	"github.com/EmissarySocial/emissary/server"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/benpate/derp"
+	"github.com/labstack/echo/v4"
+)
+
+// GetProvisioningPackages returns every template package installed on this server
+func GetProvisioningPackages(factory *server.Factory) echo.HandlerFunc {
+
+	return func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, factory.TemplatePackages())
+	}
+}
+
+// GetProvisioningPackage returns a single template package, including all installed versions
+func GetProvisioningPackage(factory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.GetProvisioningPackage"
+
+	return func(ctx echo.Context) error {
+
+		templatePackage, err := factory.LoadTemplatePackage(ctx.Param("packageId"))
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error loading template package")
+		}
+
+		return ctx.JSON(http.StatusOK, templatePackage)
+	}
+}
+
+// PostProvisioningPackage installs a new version of a template package from a
+// Git repository (JSON body) or an uploaded .zip file (multipart form, field "file")
+func PostProvisioningPackage(factory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.PostProvisioningPackage"
+
+	return func(ctx echo.Context) error {
+
+		templatePackage, err := installTemplatePackage(ctx, factory)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error installing template package")
+		}
+
+		return ctx.JSON(http.StatusCreated, templatePackage)
+	}
+}
+
+// PostProvisioningPackageActivate pins a template package to one of its installed versions
+func PostProvisioningPackageActivate(factory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.PostProvisioningPackageActivate"
+
+	return func(ctx echo.Context) error {
+
+		packageID := ctx.Param("packageId")
+
+		transaction := struct {
+			Version string `json:"version" form:"version"`
+		}{}
+
+		if err := ctx.Bind(&transaction); err != nil {
+			return derp.Wrap(err, location, "Error parsing request body", derp.WithCode(http.StatusBadRequest))
+		}
+
+		if transaction.Version == "" {
+			return derp.NewBadRequestError(location, "Version is required", packageID)
+		}
+
+		templatePackage, err := factory.ActivateTemplatePackage(packageID, transaction.Version)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error activating template package", packageID, transaction.Version)
+		}
+
+		return ctx.JSON(http.StatusOK, templatePackage)
+	}
+}
+
+// PostProvisioningPackageRollback switches a template package back to its previous version
+func PostProvisioningPackageRollback(factory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.PostProvisioningPackageRollback"
+
+	return func(ctx echo.Context) error {
+
+		packageID := ctx.Param("packageId")
+		templatePackage, err := factory.RollbackTemplatePackage(packageID)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error rolling back template package", packageID)
+		}
+
+		return ctx.JSON(http.StatusOK, templatePackage)
+	}
+}
+
+// DeleteProvisioningPackage uninstalls a template package and all of its versions
+func DeleteProvisioningPackage(factory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.DeleteProvisioningPackage"
+
+	return func(ctx echo.Context) error {
+
+		packageID := ctx.Param("packageId")
+
+		if err := factory.RemoveTemplatePackage(packageID); err != nil {
+			return derp.Wrap(err, location, "Error removing template package", packageID)
+		}
+
+		return ctx.NoContent(http.StatusNoContent)
+	}
+}
+
+// DeleteProvisioningPackageVersion removes an inactive version of a template package
+func DeleteProvisioningPackageVersion(factory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.DeleteProvisioningPackageVersion"
+
+	return func(ctx echo.Context) error {
+
+		packageID := ctx.Param("packageId")
+		version := ctx.Param("version")
+
+		templatePackage, err := factory.RemoveTemplatePackageVersion(packageID, version)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error removing template package version", packageID, version)
+		}
+
+		return ctx.JSON(http.StatusOK, templatePackage)
+	}
+}
+
+// installTemplatePackage reads an install request from either the provisioning API
+// or the setup console, then installs the package from Git or from a .zip file.
+func installTemplatePackage(ctx echo.Context, factory *server.Factory) (config.TemplatePackage, error) {
+
+	const location = "handler.installTemplatePackage"
+
+	request := ctx.Request()
+
+	// Multipart forms upload a .zip file
+	if strings.HasPrefix(request.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+
+		fileHeader, err := ctx.FormFile("file")
+
+		if err != nil {
+			return config.TemplatePackage{}, derp.Wrap(err, location, "A .zip file is required", derp.WithCode(http.StatusBadRequest))
+		}
+
+		if fileHeader.Size > service.TemplatePackageMaxSize {
+			return config.TemplatePackage{}, derp.New(http.StatusRequestEntityTooLarge, location, "Template package is too large", fileHeader.Filename)
+		}
+
+		file, err := fileHeader.Open()
+
+		if err != nil {
+			return config.TemplatePackage{}, derp.Wrap(err, location, "Error opening uploaded file", fileHeader.Filename)
+		}
+
+		defer file.Close()
+
+		data, err := io.ReadAll(io.LimitReader(file, service.TemplatePackageMaxSize))
+
+		if err != nil {
+			return config.TemplatePackage{}, derp.Wrap(err, location, "Error reading uploaded file", fileHeader.Filename)
+		}
+
+		packageID := ctx.FormValue("packageId")
+
+		if packageID == "" {
+			packageID = fileHeader.Filename
+		}
+
+		return factory.InstallTemplatePackageFromZip(packageID, data)
+	}
+
+	// Everything else describes a Git repository
+	transaction := struct {
+		PackageID string `json:"packageId" form:"packageId"`
+		URL       string `json:"url"       form:"url"`
+		Ref       string `json:"ref"       form:"ref"`
+	}{}
+
+	if err := ctx.Bind(&transaction); err != nil {
+		return config.TemplatePackage{}, derp.Wrap(err, location, "Error parsing request body", derp.WithCode(http.StatusBadRequest))
+	}
+
+	if transaction.URL == "" {
+		return config.TemplatePackage{}, derp.NewBadRequestError(location, "Git repository URL is required")
+	}
+
+	return factory.InstallTemplatePackageFromGit(transaction.PackageID, transaction.URL, transaction.Ref)
+}
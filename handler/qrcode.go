@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/base64"
+	"html/template"
 	"strings"
 
 	"github.com/EmissarySocial/emissary/server"
@@ -41,3 +44,26 @@ func GetQRCode(fm *server.Factory) echo.HandlerFunc {
 		return nil
 	}
 }
+
+// qrCodeDataURI returns a PNG image of a QR code as a "data:" URL that
+// can be embedded directly into an HTML page.
+func qrCodeDataURI(value string) (template.URL, error) {
+
+	const location = "handler.qrCodeDataURI"
+
+	qrc, err := qrcode.New(value)
+
+	if err != nil {
+		return "", derp.Wrap(err, location, "Error generating QR Code")
+	}
+
+	var buffer bytes.Buffer
+	w := standard.NewWithWriter(AsWriteCloser{&buffer}, standard.WithBuiltinImageEncoder(standard.PNG_FORMAT))
+
+	if err := qrc.Save(w); err != nil {
+		return "", derp.Wrap(err, location, "Error writing image")
+	}
+
+	// nolint:gosec // the image is generated by this server, not by user input
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(buffer.Bytes())), nil
+}
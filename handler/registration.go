@@ -123,16 +123,14 @@ func GetCompleteRegistration(ctx *steranko.Context, factory *domain.Factory, dom
 		return ctx.NoContent(http.StatusOK)
 	}
 
-	// Try to sign-in with the new user's account
-	s := factory.Steranko()
-	cookie, err := s.CreateCertificate(ctx.Request(), &user)
+	// Try to sign-in with the new user's account (enrolling in 2FA if the domain requires it)
+	next, err := signInUser(ctx, factory, &user, "registration", "/@me")
 
 	if err != nil {
 		return derp.Wrap(err, location, "Error signing in user")
 	}
 
-	ctx.SetCookie(&cookie)
-	return ctx.Redirect(http.StatusFound, "/@me")
+	return ctx.Redirect(http.StatusFound, next)
 }
 
 // PostUpdateRegistration generates an echo.HandlerFunc that handles POST /register requests
@@ -0,0 +1,341 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/convert"
+	"github.com/benpate/derp"
+	"github.com/benpate/html"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/slice"
+	"github.com/benpate/steranko"
+	"github.com/benpate/turbine/queue"
+	"github.com/gorilla/feeds"
+	"github.com/kr/jsonfeed"
+	accept "github.com/timewasted/go-accept-headers"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// savedSearchFeedSize is the maximum number of items included in a SavedSearch feed
+const savedSearchFeedSize = 50
+
+// PostSavedSearch finds (or creates) a SavedSearch for the provided query, then
+// redirects to it so that it can be followed.
+func PostSavedSearch(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostSavedSearch"
+
+	transaction := struct {
+		Query string   `form:"q"`
+		Types []string `form:"types"`
+	}{}
+
+	if err := ctx.Bind(&transaction); err != nil {
+		return derp.Wrap(err, location, "Unable to bind input")
+	}
+
+	savedSearch, err := factory.SavedSearch().LoadOrCreateByQuery(transaction.Query, transaction.Types)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error saving search", transaction)
+	}
+
+	return ctx.Redirect(http.StatusSeeOther, savedSearch.URL)
+}
+
+// GetSavedSearch returns the ActivityPub Actor for a SavedSearch.  All other
+// requests are redirected to the SavedSearch's feed.
+func GetSavedSearch(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.GetSavedSearch"
+
+	savedSearch, err := getSavedSearch(ctx, factory)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading SavedSearch")
+	}
+
+	if !isJSONLDRequest(ctx) {
+		return ctx.Redirect(http.StatusSeeOther, savedSearch.FeedURL())
+	}
+
+	result, err := factory.SavedSearch().JSONLD(&savedSearch)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error generating JSON-LD", savedSearch.SavedSearchID)
+	}
+
+	ctx.Response().Header().Set("Content-Type", model.MimeTypeActivityPub)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetSavedSearchFeed returns the most recent SearchResults that match a SavedSearch
+// as an RSS, Atom, or JSON feed.
+func GetSavedSearchFeed(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.GetSavedSearchFeed"
+
+	savedSearch, err := getSavedSearch(ctx, factory)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading SavedSearch")
+	}
+
+	results, err := factory.SavedSearch().QueryResults(&savedSearch, savedSearchFeedSize)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading search results", savedSearch.SavedSearchID)
+	}
+
+	// Advertise the WebSub hub for this feed
+	externalHubs := factory.Domain().Get().WebSubHubURLs()
+	ctx.Response().Header().Add("Link", `<`+savedSearch.WebSubURL()+`>; rel="hub"`)
+	for _, hub := range externalHubs {
+		ctx.Response().Header().Add("Link", `<`+hub+`>; rel="hub"`)
+	}
+	ctx.Response().Header().Add("Link", `<`+savedSearch.FeedURL()+`>; rel="self"`)
+
+	mimeType := savedSearchFeedMimeType(ctx)
+
+	// Special case for JSONFeed
+	if mimeType == model.MimeTypeJSONFeed {
+
+		feed := jsonfeed.Feed{
+			Version:     "https://jsonfeed.org/version/1.1",
+			Title:       savedSearch.Label(),
+			HomePageURL: savedSearch.URL,
+			FeedURL:     savedSearch.FeedURL() + "?format=json",
+			Description: savedSearch.Summary,
+			Hubs: []jsonfeed.Hub{{
+				Type: "WebSub",
+				URL:  savedSearch.WebSubURL(),
+			}},
+			Items: slice.Map(results, convert.SearchResultToJsonFeed),
+		}
+
+		for _, hub := range externalHubs {
+			feed.Hubs = append(feed.Hubs, jsonfeed.Hub{Type: "WebSub", URL: hub})
+		}
+
+		bytes, err := json.Marshal(feed)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error generating JSONFeed")
+		}
+
+		return ctx.Blob(http.StatusOK, model.MimeTypeJSONFeed, bytes)
+	}
+
+	// Otherwise, build an RSS or Atom feed
+	feed := feeds.Feed{
+		Title:       savedSearch.Label(),
+		Description: savedSearch.Summary,
+		Link:        &feeds.Link{Href: savedSearch.URL},
+		Created:     time.Now(),
+		Items:       slice.Map(results, convert.SearchResultToGorillaFeed),
+	}
+
+	var xml string
+
+	if mimeType == model.MimeTypeAtom {
+		mimeType = "application/atom+xml; charset=UTF=8"
+		xml, err = feed.ToAtom()
+	} else {
+		mimeType = "application/rss+xml; charset=UTF=8"
+		xml, err = feed.ToRss()
+	}
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error generating feed")
+	}
+
+	return ctx.Blob(http.StatusOK, mimeType, []byte(xml))
+}
+
+// PostSavedSearchWebSub accepts WebSub subscription requests for a SavedSearch feed
+func PostSavedSearchWebSub(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostSavedSearchWebSub"
+
+	savedSearch, err := getSavedSearch(ctx, factory)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading SavedSearch")
+	}
+
+	// Negotiate the content type (format) requested by the WebSub follower
+	format, err := accept.Negotiate(ctx.Request().Header.Get("Accept"), model.MimeTypeJSONFeed, model.MimeTypeAtom, model.MimeTypeRSS, model.MimeTypeXML, model.MimeTypeXMLText)
+
+	if err != nil {
+		format = model.MimeTypeJSONFeed
+	}
+
+	leaseSeconds, _ := strconv.Atoi(ctx.FormValue("hub.lease_seconds"))
+
+	// Create a new background task to handle the WebSub follower
+	task := queue.NewTask("CreateWebSubFollower", mapof.Any{
+		"objectType":   model.FollowerTypeSearch,
+		"objectId":     savedSearch.SavedSearchID.Hex(),
+		"format":       format,
+		"mode":         ctx.FormValue("hub.mode"),
+		"topic":        ctx.FormValue("hub.topic"),
+		"callback":     ctx.FormValue("hub.callback"),
+		"secret":       ctx.FormValue("hub.secret"),
+		"leaseSeconds": leaseSeconds,
+	})
+
+	if err := factory.Queue().Publish(task); err != nil {
+		return derp.Wrap(err, location, "Error pushing task to queue")
+	}
+
+	// Status Code 202 (Accepted) conforms to the WebSub spec
+	// https://www.w3.org/TR/websub/#subscription-response-details
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+// GetSavedSearchFollowerConfirm activates a pending email subscription to a SavedSearch
+func GetSavedSearchFollowerConfirm(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.GetSavedSearchFollowerConfirm"
+
+	savedSearch, err := getSavedSearch(ctx, factory)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading SavedSearch")
+	}
+
+	followerID, err := primitive.ObjectIDFromHex(ctx.QueryParam("followerId"))
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid followerId", ctx.QueryParam("followerId"))
+	}
+
+	// Load the pending Follower and verify the secret
+	followerService := factory.Follower()
+	follower := model.NewFollower()
+
+	if err := followerService.LoadPendingEmailFollower(followerID, ctx.QueryParam("secret"), &follower); err != nil {
+		return derp.Wrap(err, location, "Error loading follower", followerID)
+	}
+
+	if follower.ParentID != savedSearch.SavedSearchID {
+		return derp.NewForbiddenError(location, "Follower does not belong to this SavedSearch", followerID)
+	}
+
+	// Activate the subscription
+	follower.StateID = model.FollowerStateActive
+
+	if err := followerService.Save(&follower, "Email confirmed by user"); err != nil {
+		return derp.Wrap(err, location, "Error saving follower", followerID)
+	}
+
+	return ctx.HTML(http.StatusOK, savedSearchMessage(savedSearch, "Thank you. You will receive email updates for this search."))
+}
+
+// GetSavedSearchFollowerUnsubscribe displays a confirmation form for email followers who
+// want to unsubscribe from a SavedSearch
+func GetSavedSearchFollowerUnsubscribe(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.GetSavedSearchFollowerUnsubscribe"
+
+	savedSearch, err := getSavedSearch(ctx, factory)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading SavedSearch")
+	}
+
+	b := html.New()
+	b.H1().InnerText(savedSearch.Label()).Close()
+	b.Form("post", "")
+	b.Input("hidden", "followerId").Value(ctx.QueryParam("followerId"))
+	b.Input("hidden", "secret").Value(ctx.QueryParam("secret"))
+	b.Button().Type("submit").InnerText("Unsubscribe from email updates")
+	b.CloseAll()
+
+	return ctx.HTML(http.StatusOK, b.String())
+}
+
+// PostSavedSearchFollowerUnsubscribe removes an email subscription to a SavedSearch
+func PostSavedSearchFollowerUnsubscribe(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostSavedSearchFollowerUnsubscribe"
+
+	savedSearch, err := getSavedSearch(ctx, factory)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading SavedSearch")
+	}
+
+	followerID, err := primitive.ObjectIDFromHex(ctx.FormValue("followerId"))
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid followerId", ctx.FormValue("followerId"))
+	}
+
+	// Load the Follower and verify the secret
+	followerService := factory.Follower()
+	follower := model.NewFollower()
+
+	if err := followerService.LoadBySecret(followerID, ctx.FormValue("secret"), &follower); err != nil {
+		return derp.Wrap(err, location, "Error loading follower", followerID)
+	}
+
+	if follower.ParentID != savedSearch.SavedSearchID {
+		return derp.NewForbiddenError(location, "Follower does not belong to this SavedSearch", followerID)
+	}
+
+	if err := followerService.Delete(&follower, "Unsubscribed from email notifications"); err != nil {
+		return derp.Wrap(err, location, "Error deleting follower", followerID)
+	}
+
+	return ctx.HTML(http.StatusOK, savedSearchMessage(savedSearch, "Done.  You have been unsubscribed from email notifications."))
+}
+
+// getSavedSearch loads the SavedSearch identified in the request path
+func getSavedSearch(ctx *steranko.Context, factory *domain.Factory) (model.SavedSearch, error) {
+
+	result := model.NewSavedSearch()
+	token := ctx.Param("savedSearchId")
+
+	if err := factory.SavedSearch().LoadByToken(token, &result); err != nil {
+		return result, derp.Wrap(err, "handler.getSavedSearch", "Error loading SavedSearch", token)
+	}
+
+	return result, nil
+}
+
+// savedSearchFeedMimeType returns the feed format requested by the client
+func savedSearchFeedMimeType(ctx *steranko.Context) string {
+
+	// First, try to get the format from the query string
+	switch ctx.QueryParam("format") {
+	case "json":
+		return model.MimeTypeJSONFeed
+	case "atom":
+		return model.MimeTypeAtom
+	case "rss":
+		return model.MimeTypeRSS
+	}
+
+	// Otherwise, get the format from the "Accept" header
+	if result, err := accept.Negotiate(ctx.Request().Header.Get("Accept"), model.MimeTypeJSONFeed, model.MimeTypeAtom, model.MimeTypeRSS, model.MimeTypeXML, model.MimeTypeXMLText); err == nil {
+		return result
+	}
+
+	// Finally, use JSONFeed as the default
+	return model.MimeTypeJSONFeed
+}
+
+// savedSearchMessage returns a simple HTML page with a message about a SavedSearch
+func savedSearchMessage(savedSearch model.SavedSearch, message string) string {
+	b := html.New()
+	b.H1().InnerText(savedSearch.Label()).Close()
+	b.Container("p").InnerText(message).Close()
+	b.CloseAll()
+	return b.String()
+}
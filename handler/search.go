@@ -2,9 +2,11 @@ package handler
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/EmissarySocial/emissary/domain"
 	"github.com/benpate/derp"
+	domaintools "github.com/benpate/domain"
 	"github.com/benpate/rosetta/mapof"
 	"github.com/benpate/steranko"
 	"github.com/benpate/turbine/queue"
@@ -59,3 +61,56 @@ func IndexAllUsers(ctx *steranko.Context, factory *domain.Factory) error {
 	// Success.
 	return ctx.NoContent(http.StatusOK)
 }
+
+// PostSearchRelay subscribes the domain's service actor to an ActivityPub relay, so that
+// public posts from other servers can be added to the search index.
+// It can only be called by an authenticated administrator.
+func PostSearchRelay(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostSearchRelay"
+
+	// Verify that this is an Administrator
+	authorization := getAuthorization(ctx)
+
+	if !authorization.DomainOwner {
+		return derp.NewForbiddenError(location, "Only administrators can call this method")
+	}
+
+	inboxURL := strings.TrimSpace(ctx.FormValue("url"))
+
+	if !domaintools.HasProtocol(inboxURL) {
+		return derp.NewBadRequestError(location, "Relay must be a valid URL", inboxURL)
+	}
+
+	if err := factory.Search().SubscribeRelay(inboxURL); err != nil {
+		return derp.Wrap(err, location, "Error subscribing to relay", inboxURL)
+	}
+
+	// Success.
+	ctx.Response().Header().Set("HX-Trigger", "refreshPage")
+	return ctx.NoContent(http.StatusOK)
+}
+
+// PostSearchRelayRemove unsubscribes the domain's service actor from an ActivityPub relay.
+// It can only be called by an authenticated administrator.
+func PostSearchRelayRemove(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostSearchRelayRemove"
+
+	// Verify that this is an Administrator
+	authorization := getAuthorization(ctx)
+
+	if !authorization.DomainOwner {
+		return derp.NewForbiddenError(location, "Only administrators can call this method")
+	}
+
+	inboxURL := ctx.FormValue("url")
+
+	if err := factory.Search().UnsubscribeRelay(inboxURL); err != nil {
+		return derp.Wrap(err, location, "Error unsubscribing from relay", inboxURL)
+	}
+
+	// Success.
+	ctx.Response().Header().Set("HX-Trigger", "refreshPage")
+	return ctx.NoContent(http.StatusOK)
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/steranko"
+)
+
+// GetSecurity displays the signed-in User's two-factor authentication settings
+func GetSecurity(ctx *steranko.Context, factory *domain.Factory, user *model.User) error {
+
+	data := twoFactorPageData(ctx, factory)
+	domain := factory.Domain().Get()
+
+	data["username"] = user.Username
+	data["hasTOTP"] = user.TwoFactor.TOTPEnabled
+	data["isEnabled"] = user.TwoFactor.IsEnabled()
+	data["isRequired"] = domain.RequiresTwoFactor(user)
+	data["isMasquerading"] = isMasquerading(ctx)
+	data["recoveryCodeCount"] = user.TwoFactor.RecoveryCodeCount()
+	data["passkeys"] = user.TwoFactor.Passkeys
+	data["message"] = ctx.QueryParam("message")
+
+	if oidc := domain.OIDCConfig(); oidc.IsReady() {
+		data["oidcLabel"] = oidc.Label
+		data["oidcLinked"] = user.MapIDs[model.UserMapIDOIDC] != ""
+	}
+
+	return renderTwoFactorPage(ctx, factory, "security", data)
+}
+
+// PostSecurityTOTP begins a new TOTP enrollment for the signed-in User
+func PostSecurityTOTP(ctx *steranko.Context, factory *domain.Factory, user *model.User) error {
+
+	const location = "handler.PostSecurityTOTP"
+
+	if err := guardSecurityChange(ctx); err != nil {
+		return derp.Wrap(err, location, "Cannot change two-factor settings")
+	}
+
+	if user.TwoFactor.TOTPEnabled {
+		return ctx.Redirect(http.StatusSeeOther, "/@me/security")
+	}
+
+	data := twoFactorPageData(ctx, factory)
+	data["cancel"] = "/@me/security"
+
+	return renderTOTPEnrollment(ctx, factory, user, data, "/@me/security/totp/confirm")
+}
+
+// PostSecurityTOTPConfirm confirms a pending TOTP enrollment for the signed-in User
+func PostSecurityTOTPConfirm(ctx *steranko.Context, factory *domain.Factory, user *model.User) error {
+
+	const location = "handler.PostSecurityTOTPConfirm"
+
+	if err := guardSecurityChange(ctx); err != nil {
+		return derp.Wrap(err, location, "Cannot change two-factor settings")
+	}
+
+	data := twoFactorPageData(ctx, factory)
+	ok, err := factory.TwoFactor().EnableTOTP(user, ctx.FormValue("code"))
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error confirming TOTP secret")
+	}
+
+	if !ok {
+		data["cancel"] = "/@me/security"
+		data["error"] = factory.Translation().Translate(data.GetString("locale"), "twoFactor.invalid")
+		return renderTOTPEnrollment(ctx, factory, user, data, "/@me/security/totp/confirm")
+	}
+
+	// Save the confirmed secret along with a new set of recovery codes
+	codes, err := saveRecoveryCodes(factory, user)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error saving recovery codes")
+	}
+
+	writeAuditLog(ctx, factory, auditUser(model.AuditActionSecurityChange, user, "Enabled authenticator app"))
+
+	data["codes"] = codes
+	data["next"] = "/@me/security"
+
+	return renderTwoFactorPage(ctx, factory, "two-factor-recovery", data)
+}
+
+// PostSecurityTOTPDisable removes TOTP from the signed-in User.  A current code
+// (or recovery code) is required so that a hijacked session cannot remove it.
+func PostSecurityTOTPDisable(ctx *steranko.Context, factory *domain.Factory, user *model.User) error {
+
+	const location = "handler.PostSecurityTOTPDisable"
+
+	if err := guardSecurityChange(ctx); err != nil {
+		return derp.Wrap(err, location, "Cannot change two-factor settings")
+	}
+
+	// RULE: Users who are required to use 2FA must keep at least one second factor
+	domain := factory.Domain().Get()
+
+	if domain.RequiresTwoFactor(user) && !user.TwoFactor.HasPasskeys() {
+		return ctx.Redirect(http.StatusSeeOther, "/@me/security?message=required")
+	}
+
+	ok, err := factory.TwoFactor().ValidateCode(user, ctx.FormValue("code"))
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error validating code")
+	}
+
+	if !ok {
+		return ctx.Redirect(http.StatusSeeOther, "/@me/security?message=invalid")
+	}
+
+	user.TwoFactor.DisableTOTP()
+
+	// Recovery codes are useless without a second factor
+	if !user.TwoFactor.IsEnabled() {
+		user.TwoFactor.RecoveryCodes = nil
+	}
+
+	if err := factory.User().Save(user, "TOTP disabled"); err != nil {
+		return derp.Wrap(err, location, "Error saving user")
+	}
+
+	writeAuditLog(ctx, factory, auditUser(model.AuditActionSecurityChange, user, "Disabled authenticator app"))
+
+	return ctx.Redirect(http.StatusSeeOther, "/@me/security")
+}
+
+// PostSecurityRecoveryCodes replaces the signed-in User's recovery codes
+func PostSecurityRecoveryCodes(ctx *steranko.Context, factory *domain.Factory, user *model.User) error {
+
+	const location = "handler.PostSecurityRecoveryCodes"
+
+	if err := guardSecurityChange(ctx); err != nil {
+		return derp.Wrap(err, location, "Cannot change two-factor settings")
+	}
+
+	if !user.TwoFactor.IsEnabled() {
+		return derp.NewBadRequestError(location, "Two-factor authentication is not enabled")
+	}
+
+	codes, err := saveRecoveryCodes(factory, user)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error saving recovery codes")
+	}
+
+	writeAuditLog(ctx, factory, auditUser(model.AuditActionSecurityChange, user, "Replaced recovery codes"))
+
+	data := twoFactorPageData(ctx, factory)
+	data["codes"] = codes
+	data["next"] = "/@me/security"
+
+	return renderTwoFactorPage(ctx, factory, "two-factor-recovery", data)
+}
+
+// PostSecurityPasskey returns WebAuthn options for registering a new passkey
+func PostSecurityPasskey(ctx *steranko.Context, factory *domain.Factory, user *model.User) error {
+
+	const location = "handler.PostSecurityPasskey"
+
+	if err := guardSecurityChange(ctx); err != nil {
+		return derp.Wrap(err, location, "Cannot change two-factor settings")
+	}
+
+	creation, session, err := factory.TwoFactor().BeginRegistration(user)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error beginning passkey registration")
+	}
+
+	challenge := model.NewTwoFactorChallenge(model.TwoFactorChallengePurposeRegister, user.UserID, "")
+	challenge.Session = session
+
+	if err := setTwoFactorChallenge(ctx, factory, challenge); err != nil {
+		return derp.Wrap(err, location, "Error saving challenge")
+	}
+
+	return ctx.JSON(http.StatusOK, creation)
+}
+
+// PostSecurityPasskeyFinish validates and saves a new passkey for the signed-in User
+func PostSecurityPasskeyFinish(ctx *steranko.Context, factory *domain.Factory, user *model.User) error {
+
+	const location = "handler.PostSecurityPasskeyFinish"
+
+	if err := guardSecurityChange(ctx); err != nil {
+		return derp.Wrap(err, location, "Cannot change two-factor settings")
+	}
+
+	challenge, err := getTwoFactorChallenge(ctx, factory, model.TwoFactorChallengePurposeRegister)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid registration challenge")
+	}
+
+	// RULE: The challenge must have been issued to the signed-in User
+	if (challenge.UserID != user.UserID) || (challenge.Session == nil) {
+		return derp.NewForbiddenError(location, "Registration challenge does not match the signed-in user")
+	}
+
+	if err := factory.TwoFactor().FinishRegistration(user, *challenge.Session, ctx.Request(), ctx.QueryParam("label")); err != nil {
+		return derp.Wrap(err, location, "Error registering passkey")
+	}
+
+	writeAuditLog(ctx, factory, auditUser(model.AuditActionSecurityChange, user, "Added passkey"))
+
+	clearTwoFactorChallenge(ctx)
+	return ctx.JSON(http.StatusOK, mapof.Any{"next": "/@me/security"})
+}
+
+// PostSecurityPasskeyDelete removes a passkey from the signed-in User
+func PostSecurityPasskeyDelete(ctx *steranko.Context, factory *domain.Factory, user *model.User) error {
+
+	const location = "handler.PostSecurityPasskeyDelete"
+
+	if err := guardSecurityChange(ctx); err != nil {
+		return derp.Wrap(err, location, "Cannot change two-factor settings")
+	}
+
+	// RULE: Users who are required to use 2FA must keep at least one second factor
+	domain := factory.Domain().Get()
+
+	if domain.RequiresTwoFactor(user) && !user.TwoFactor.TOTPEnabled && (len(user.TwoFactor.Passkeys) <= 1) {
+		return ctx.Redirect(http.StatusSeeOther, "/@me/security?message=required")
+	}
+
+	if err := factory.TwoFactor().RemovePasskey(user, ctx.Param("passkeyId")); err != nil {
+		return derp.Wrap(err, location, "Error removing passkey")
+	}
+
+	writeAuditLog(ctx, factory, auditUser(model.AuditActionSecurityChange, user, "Removed passkey"))
+
+	return ctx.Redirect(http.StatusSeeOther, "/@me/security")
+}
+
+// guardSecurityChange prevents domain owners from changing another User's
+// two-factor settings while masquerading as them.
+func guardSecurityChange(ctx *steranko.Context) error {
+
+	if isMasquerading(ctx) {
+		return derp.NewForbiddenError("handler.guardSecurityChange", "Two-factor settings cannot be changed while signed in as another user")
+	}
+
+	return nil
+}
@@ -4,11 +4,12 @@ import (
 	"net/http"
 
 	"github.com/EmissarySocial/emissary/server"
+	"github.com/EmissarySocial/emissary/service"
 	"github.com/benpate/derp"
 	"github.com/benpate/hannibal/vocab"
 	"github.com/benpate/rosetta/mapof"
 	"github.com/labstack/echo/v4"
-	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func GetServiceActor(serverFactory *server.Factory) echo.HandlerFunc {
@@ -77,19 +78,37 @@ func GetEmptyCollection(serverFactory *server.Factory) echo.HandlerFunc {
 	}
 }
 
-// PostServiceActor_Inbox does not take any actions, but only logs the request
-// IF logger is in Debug or Trace mode.
+// PostServiceActor_Inbox receives ActivityPub messages for the domain's service actor.
+// This includes public posts delivered by relays, which are added to the search index
+// if this domain has opted in to federated search.
 func PostServiceActor_Inbox(serverFactory *server.Factory) echo.HandlerFunc {
 
+	const location = "handler.PostServiceActor_Inbox"
+
 	return func(ctx echo.Context) error {
 
-		if zerolog.GlobalLevel() > zerolog.DebugLevel {
-			return ctx.NoContent(http.StatusOK)
+		// Find the factory for this hostname
+		factory, err := serverFactory.ByContext(ctx)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Invalid Domain")
+		}
+
+		// Retrieve (and validate) the activity from the request body
+		activity, err := factory.ActivityStream().ReceiveRequest(ctx.Request())
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error parsing ActivityPub request")
+		}
+
+		// RULE: Reject activities from Actors that are blocked by this domain
+		ruleFilter := factory.Rule().Filter(primitive.NilObjectID, service.WithBlocksOnly())
+		if ruleFilter.Disallow(&activity) {
+			return derp.NewForbiddenError(location, "Blocked by domain rule", activity.Actor().ID())
 		}
 
-		// Try to read/dump the Request body
-		// body, err := io.ReadAll(ctx.Request().Body)
-		// log.Trace().Msg(string(body))
+		// Add public documents to the search index (if enabled)
+		factory.Search().ReceiveActivity(activity)
 
 		// Return no content
 		return ctx.NoContent(http.StatusOK)
@@ -176,6 +176,16 @@ func setupDomainForm(header string) form.Element {
 				Path:        "keyEncryptingKey",
 				Label:       "Master Key",
 				Description: "32 Random Characters",
+			}, {
+				Type:        "select",
+				Path:        "searchEngine",
+				Label:       "Search Engine",
+				Description: "MONGODB uses a database text index. EMBEDDED keeps a full-text index in memory, with phrase and prefix matching.",
+			}, {
+				Type:        "text",
+				Path:        "storageQuota",
+				Label:       "Storage Quota (MB)",
+				Description: "Maximum space for all uploaded files on this domain.  Leave empty for no limit.",
 			}},
 		}, {
 			Label: "Account Owner",
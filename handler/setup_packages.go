@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/EmissarySocial/emissary/build"
+	"github.com/EmissarySocial/emissary/server"
+	"github.com/benpate/derp"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupPackagePost installs a template package from a Git repository or an uploaded .zip file
+func SetupPackagePost(factory *server.Factory) echo.HandlerFunc {
+
+	return func(ctx echo.Context) error {
+
+		if _, err := installTemplatePackage(ctx, factory); err != nil {
+			return derp.Wrap(err, "handler.SetupPackagePost", "Error installing template package")
+		}
+
+		build.RefreshPage(ctx)
+		return ctx.NoContent(http.StatusOK)
+	}
+}
+
+// SetupPackageActivate switches a template package to one of its installed versions
+func SetupPackageActivate(factory *server.Factory) echo.HandlerFunc {
+
+	return func(ctx echo.Context) error {
+
+		packageID := ctx.Param("package")
+		version := ctx.Param("version")
+
+		if _, err := factory.ActivateTemplatePackage(packageID, version); err != nil {
+			return derp.Wrap(err, "handler.SetupPackageActivate", "Error activating template package", packageID, version)
+		}
+
+		build.RefreshPage(ctx)
+		return ctx.NoContent(http.StatusOK)
+	}
+}
+
+// SetupPackageRollback switches a template package back to its previous version
+func SetupPackageRollback(factory *server.Factory) echo.HandlerFunc {
+
+	return func(ctx echo.Context) error {
+
+		packageID := ctx.Param("package")
+
+		if _, err := factory.RollbackTemplatePackage(packageID); err != nil {
+			return derp.Wrap(err, "handler.SetupPackageRollback", "Error rolling back template package", packageID)
+		}
+
+		build.RefreshPage(ctx)
+		return ctx.NoContent(http.StatusOK)
+	}
+}
+
+// SetupPackageDelete uninstalls a template package and all of its versions
+func SetupPackageDelete(factory *server.Factory) echo.HandlerFunc {
+
+	return func(ctx echo.Context) error {
+
+		packageID := ctx.Param("package")
+
+		if err := factory.RemoveTemplatePackage(packageID); err != nil {
+			return derp.Wrap(err, "handler.SetupPackageDelete", "Error removing template package", packageID)
+		}
+
+		build.RefreshPage(ctx)
+		return ctx.NoContent(http.StatusOK)
+	}
+}
+
+// SetupPackageVersionDelete removes an inactive version of a template package
+func SetupPackageVersionDelete(factory *server.Factory) echo.HandlerFunc {
+
+	return func(ctx echo.Context) error {
+
+		packageID := ctx.Param("package")
+		version := ctx.Param("version")
+
+		if _, err := factory.RemoveTemplatePackageVersion(packageID, version); err != nil {
+			return derp.Wrap(err, "handler.SetupPackageVersionDelete", "Error removing template package version", packageID, version)
+		}
+
+		build.RefreshPage(ctx)
+		return ctx.NoContent(http.StatusOK)
+	}
+}
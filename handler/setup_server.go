@@ -151,6 +151,9 @@ func getSetupForm(name string) (form.Element, bool, error) {
 					{Type: "text", Label: "HTTP", Description: "Port to use for HTTP connections (standard: 80, disabled: 0)", Path: "httpPort", Options: mapof.Any{"format": "number", "min": 0, "max:": 65535}},
 					{Type: "text", Label: "HTTPS", Description: "Port to use for HTTPS connections (standard: 443, disabled: 0)", Path: "httpsPort", Options: mapof.Any{"format": "number", "min": 0, "max:": 65535}},
 				}},
+				{Type: "layout-vertical", Label: "Provisioning API", Description: "Hosting services can create and manage domains through the JSON API at /.provisioning.  Leave blank to disable the API.", Children: []form.Element{
+					{Type: "text", Label: "Bearer Token", Path: "provisioningToken"},
+				}},
 				{Type: "layout-vertical", Label: "Testing and Development", Children: []form.Element{
 					{Type: "select", Label: "Debug Output", Path: "debugLevel"},
 				}},
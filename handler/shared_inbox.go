@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"iter"
+	"net/http"
+
+	"github.com/EmissarySocial/emissary/domain"
+	ap_stream "github.com/EmissarySocial/emissary/handler/activitypub_stream"
+	ap_user "github.com/EmissarySocial/emissary/handler/activitypub_user"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/server"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/benpate/derp"
+	"github.com/benpate/hannibal/streams"
+	"github.com/benpate/hannibal/vocab"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostSharedInbox receives ActivityPub messages for every actor on this domain, and routes
+// each message into the inbox of every local User and Stream that it is addressed to.
+// https://www.w3.org/TR/activitypub/#shared-inbox-delivery
+func PostSharedInbox(serverFactory *server.Factory) echo.HandlerFunc {
+
+	const location = "handler.PostSharedInbox"
+
+	return func(ctx echo.Context) error {
+
+		// Find the factory for this hostname
+		factory, err := serverFactory.ByContext(ctx)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Invalid Domain")
+		}
+
+		// Retrieve (and validate) the activity from the request body
+		activity, err := factory.ActivityStream().ReceiveRequest(ctx.Request())
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error parsing ActivityPub request")
+		}
+
+		// RULE: Reject activities from Actors that are blocked by this domain
+		ruleFilter := factory.Rule().Filter(primitive.NilObjectID, service.WithBlocksOnly())
+		if ruleFilter.Disallow(&activity) {
+			return derp.NewForbiddenError(location, "Blocked by domain rule", activity.Actor().ID())
+		}
+
+		// Deliver the activity to every addressed User
+		users, streams := sharedInbox_Recipients(factory, activity)
+
+		for _, user := range users {
+			if err := ap_user.ReceiveSharedActivity(factory, &user, activity); err != nil {
+				derp.Report(derp.Wrap(err, location, "Error delivering activity to User", user.UserID))
+			}
+		}
+
+		// Deliver the activity to every addressed Stream
+		for _, stream := range streams {
+			if err := ap_stream.ReceiveSharedActivity(factory, &stream, activity); err != nil {
+				derp.Report(derp.Wrap(err, location, "Error delivering activity to Stream", stream.StreamID))
+			}
+		}
+
+		// Add public documents to the search index (if enabled)
+		factory.Search().ReceiveActivity(activity)
+
+		// Send the response to the client
+		return ctx.String(http.StatusOK, "")
+	}
+}
+
+// sharedInbox_Recipients returns all of the local Users and Streams that should receive
+// an activity.  This includes everyone who is addressed directly (via to, cc, bto, bcc, or audience)
+// and, for public/followers-only activities, every local User who follows the activity's Actor.
+func sharedInbox_Recipients(factory *domain.Factory, activity streams.Document) ([]model.User, []model.Stream) {
+
+	const location = "handler.sharedInbox_Recipients"
+
+	userIDs, streamIDs := sharedInbox_RecipientIDs(activity, factory.Locator(), factory.Following().RangeActivityPubByURL)
+
+	users := make([]model.User, 0, len(userIDs))
+	streams := make([]model.Stream, 0, len(streamIDs))
+
+	userService := factory.User()
+	streamService := factory.Stream()
+
+	for _, userID := range userIDs {
+
+		user := model.NewUser()
+
+		if err := userService.LoadByID(userID, &user); err != nil {
+			derp.Report(derp.Wrap(err, location, "Error loading User", userID))
+			continue
+		}
+
+		users = append(users, user)
+	}
+
+	for _, streamID := range streamIDs {
+
+		stream := model.NewStream()
+
+		if err := streamService.LoadByID(streamID, &stream); err != nil {
+			derp.Report(derp.Wrap(err, location, "Error loading Stream", streamID))
+			continue
+		}
+
+		streams = append(streams, stream)
+	}
+
+	return users, streams
+}
+
+// sharedInbox_Locator finds local objects (Users and Streams) from their URLs
+type sharedInbox_Locator interface {
+	GetObjectFromURL(value string) (string, primitive.ObjectID, error)
+}
+
+// sharedInbox_RecipientIDs returns the unique IDs of the local Users and Streams that should
+// receive an activity.  `rangeFollowing` returns the local Following records for a remote Actor.
+func sharedInbox_RecipientIDs(activity streams.Document, locator sharedInbox_Locator, rangeFollowing func(profileURL string) (iter.Seq[model.Following], error)) ([]primitive.ObjectID, []primitive.ObjectID) {
+
+	const location = "handler.sharedInbox_RecipientIDs"
+
+	userIDs := make([]primitive.ObjectID, 0)
+	streamIDs := make([]primitive.ObjectID, 0)
+	seen := make(map[primitive.ObjectID]struct{})
+
+	// add appends an ID to a list of recipients (once)
+	add := func(list []primitive.ObjectID, objectID primitive.ObjectID) []primitive.ObjectID {
+
+		if _, ok := seen[objectID]; ok {
+			return list
+		}
+
+		seen[objectID] = struct{}{}
+		return append(list, objectID)
+	}
+
+	actorID := activity.Actor().ID()
+	followersID := ""
+
+	if actor, err := activity.Actor().Load(); err == nil {
+		followersID = actor.Followers().ID()
+	}
+
+	includeFollowers := false
+
+	for _, recipientID := range sharedInbox_Addresses(activity) {
+
+		// Public and Followers-only activities are delivered to everyone who follows the Actor
+		if (recipientID == vocab.NamespaceActivityStreamsPublic) || ((followersID != "") && (recipientID == followersID)) {
+			includeFollowers = true
+			continue
+		}
+
+		// Otherwise, look for Users and Streams on this domain
+		objectType, objectID, err := locator.GetObjectFromURL(recipientID)
+
+		if err != nil {
+			continue
+		}
+
+		switch objectType {
+
+		case "User":
+			userIDs = add(userIDs, objectID)
+
+		case "Stream":
+			streamIDs = add(streamIDs, objectID)
+		}
+	}
+
+	// Add every local User who follows the Actor
+	if includeFollowers && (actorID != "") {
+
+		followings, err := rangeFollowing(actorID)
+
+		if err != nil {
+			derp.Report(derp.Wrap(err, location, "Error loading Following records", actorID))
+			return userIDs, streamIDs
+		}
+
+		for following := range followings {
+			userIDs = add(userIDs, following.UserID)
+		}
+	}
+
+	return userIDs, streamIDs
+}
+
+// sharedInbox_Addresses returns a slice of every address that an activity
+// (or the object that it wraps) is delivered to.
+func sharedInbox_Addresses(activity streams.Document) []string {
+
+	result := make([]string, 0)
+
+	for _, document := range []streams.Document{activity, activity.Object()} {
+		for _, addresses := range []streams.Document{document.To(), document.CC(), document.BTo(), document.BCC(), document.Audience()} {
+			for address := range addresses.Channel() {
+				if id := address.ID(); id != "" {
+					result = append(result, id)
+				}
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/benpate/hannibal/streams"
+	"github.com/benpate/hannibal/vocab"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testLocator finds local objects from a fixed list of URLs
+type testLocator map[string]testLocatorObject
+
+type testLocatorObject struct {
+	objectType string
+	objectID   primitive.ObjectID
+}
+
+func (locator testLocator) GetObjectFromURL(value string) (string, primitive.ObjectID, error) {
+
+	if object, ok := locator[value]; ok {
+		return object.objectType, object.objectID, nil
+	}
+
+	return "", primitive.NilObjectID, derp.NewNotFoundError("testLocator", "Not found", value)
+}
+
+// testActorClient returns the same Actor for every request
+type testActorClient mapof.Any
+
+func (client testActorClient) Load(_ string, _ ...any) (streams.Document, error) {
+	return streams.NewDocument(mapof.Any(client), streams.WithClient(client)), nil
+}
+
+func TestSharedInboxRecipients(t *testing.T) {
+
+	alice := primitive.NewObjectID()
+	bob := primitive.NewObjectID()
+	carol := primitive.NewObjectID()
+	blog := primitive.NewObjectID()
+
+	locator := testLocator{
+		"https://local.example/@alice": {"User", alice},
+		"https://local.example/@bob":   {"User", bob},
+		"https://local.example/blog":   {"Stream", blog},
+	}
+
+	// Alice and Carol follow the remote Actor
+	rangeFollowing := func(profileURL string) (iter.Seq[model.Following], error) {
+		require.Equal(t, "https://remote.example/users/zed", profileURL)
+		return func(yield func(model.Following) bool) {
+			for _, userID := range []primitive.ObjectID{alice, carol} {
+				following := model.NewFollowing()
+				following.UserID = userID
+				if !yield(following) {
+					return
+				}
+			}
+		}, nil
+	}
+
+	client := testActorClient{
+		vocab.PropertyID:        "https://remote.example/users/zed",
+		vocab.PropertyFollowers: "https://remote.example/users/zed/followers",
+	}
+
+	// Direct addresses only
+	activity := streams.NewDocument(mapof.Any{
+		vocab.PropertyType:  vocab.ActivityTypeCreate,
+		vocab.PropertyActor: "https://remote.example/users/zed",
+		vocab.PropertyTo:    []any{"https://local.example/@bob", "https://elsewhere.example/users/yan"},
+		vocab.PropertyObject: mapof.Any{
+			vocab.PropertyType: vocab.ObjectTypeNote,
+			vocab.PropertyCC:   []any{"https://local.example/blog", "https://local.example/@bob"},
+		},
+	}, streams.WithClient(client))
+
+	userIDs, streamIDs := sharedInbox_RecipientIDs(activity, locator, rangeFollowing)
+	require.Equal(t, []primitive.ObjectID{bob}, userIDs)
+	require.Equal(t, []primitive.ObjectID{blog}, streamIDs)
+
+	// Followers-only activities fan out to every local follower (once each)
+	activity = streams.NewDocument(mapof.Any{
+		vocab.PropertyType:  vocab.ActivityTypeCreate,
+		vocab.PropertyActor: "https://remote.example/users/zed",
+		vocab.PropertyTo:    []any{"https://remote.example/users/zed/followers", "https://local.example/@alice"},
+	}, streams.WithClient(client))
+
+	userIDs, streamIDs = sharedInbox_RecipientIDs(activity, locator, rangeFollowing)
+	require.Equal(t, []primitive.ObjectID{alice, carol}, userIDs)
+	require.Empty(t, streamIDs)
+
+	// Public activities do the same
+	activity = streams.NewDocument(mapof.Any{
+		vocab.PropertyType:  vocab.ActivityTypeAnnounce,
+		vocab.PropertyActor: "https://remote.example/users/zed",
+		vocab.PropertyCC:    []any{vocab.NamespaceActivityStreamsPublic},
+	}, streams.WithClient(client))
+
+	userIDs, _ = sharedInbox_RecipientIDs(activity, locator, rangeFollowing)
+	require.Equal(t, []primitive.ObjectID{alice, carol}, userIDs)
+}
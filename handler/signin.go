@@ -3,11 +3,14 @@ package handler
 import (
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/EmissarySocial/emissary/model"
 	"github.com/EmissarySocial/emissary/server"
+	"github.com/EmissarySocial/emissary/tools/random"
 	"github.com/benpate/derp"
 	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/steranko"
 	"github.com/labstack/echo/v4"
 )
 
@@ -34,6 +37,13 @@ func GetSignIn(serverFactory *server.Factory) echo.HandlerFunc {
 		data["domainIcon"] = domain.IconURL()
 		data["hasRegistrationForm"] = factory.Domain().HasRegistrationForm()
 		data["next"] = url.QueryEscape(data.GetString("next"))
+		data["locale"] = factory.Translation().Negotiate(ctx.Request().Header.Get("Accept-Language"))
+
+		// Display a link to the OpenID Connect provider (if configured)
+		if oidc := domain.OIDCConfig(); oidc.IsReady() {
+			data["oidcLabel"] = oidc.Label
+			data["oidcURL"] = "/oidc/signin?next=" + data.GetString("next")
+		}
 
 		// Render the template
 		if err := template.ExecuteTemplate(ctx.Response(), "signin", data); err != nil {
@@ -56,21 +66,39 @@ func PostSignIn(serverFactory *server.Factory) echo.HandlerFunc {
 			return derp.NewInternalError("handler.PostSignIn", "Invalid Domain.")
 		}
 
-		// Try to sign in using Steranko
-		s := factory.Steranko()
-		if err := s.SignIn(ctx); err != nil {
+		// Collect values from the request body
+		var txn steranko.SigninTransaction
+
+		if err := ctx.Bind(&txn); err != nil {
+			return derp.Wrap(err, "handler.PostSignIn", "Unable to bind request body", derp.WithCode(http.StatusBadRequest))
+		}
+
+		// (short) random sleep to thwart timing attacks
+		time.Sleep(time.Duration(random.GenerateInt(500, 1500)) * time.Millisecond)
+
+		// Try to authenticate the username/password using Steranko
+		user := model.NewUser()
+
+		if err := factory.Steranko().Authenticate(txn.Username, txn.Password, &user); err != nil {
+			writeAuditLog(ctx, factory, model.NewAuditLog(model.AuditActionSignInFailed).
+				WithActor(user.UserID, txn.Username).
+				WithSummary("Invalid username or password"))
+			time.Sleep(time.Duration(random.GenerateInt(1000, 3000)) * time.Millisecond)
+			translation := factory.Translation()
+			locale := translation.Negotiate(ctx.Request().Header.Get("Accept-Language"))
 			ctx.Response().Header().Add("HX-Trigger", "SigninError")
-			return ctx.HTML(derp.ErrorCode(err), derp.Message(err))
+			return ctx.HTML(http.StatusForbidden, translation.Translate(locale, "signin.invalid"))
 		}
 
-		// If there is a "next" parameter, then redirect to that URL.
-		if next := ctx.QueryParam("next"); next != "" {
-			ctx.Response().Header().Add("Hx-Redirect", next)
-		} else {
-			// Otherwise, redirect to the user's profile page
-			ctx.Response().Header().Add("Hx-Redirect", "/@me")
+		// Sign in, or begin a two-factor challenge.  Then redirect to the next page.
+		next, err := signInUser(ctx, factory, &user, "password", ctx.QueryParam("next"))
+
+		if err != nil {
+			return derp.Wrap(err, "handler.PostSignIn", "Error signing in")
 		}
 
+		ctx.Response().Header().Add("Hx-Redirect", next)
+
 		/// 3..2..1.. Go!
 		return ctx.NoContent(http.StatusNoContent)
 	}
@@ -91,6 +119,11 @@ func PostSignOut(serverFactory *server.Factory) echo.HandlerFunc {
 
 		s := factory.Steranko()
 
+		// Record the sign-out while the User's certificate is still available
+		if getAuthorization(ctx).IsAuthenticated() {
+			writeAuditLog(ctx, factory, auditAuthenticated(ctx, factory, model.AuditActionSignOut).WithSummary("Signed out"))
+		}
+
 		// If there is a "next" parameter, then redirect to that URL.
 		hasBackupProfile := s.SignOut(ctx)
 
@@ -142,6 +175,7 @@ func PostResetPassword(serverFactory *server.Factory) echo.HandlerFunc {
 
 		if err := userService.LoadByUsernameOrEmail(transaction.EmailAddress, &user); err == nil {
 			userService.SendPasswordResetEmail(&user)
+			writeAuditLog(ctx, factory, auditUser(model.AuditActionPasswordResetRequest, &user, "Requested a password reset email"))
 		}
 
 		// Return a success message regardless of whether or not the user was found.
@@ -272,6 +306,8 @@ func PostResetCode(serverFactory *server.Factory) echo.HandlerFunc {
 			return derp.Wrap(err, "handler.GetResetCode", "Error saving user")
 		}
 
+		writeAuditLog(ctx, factory, auditUser(model.AuditActionPasswordReset, &user, "Reset password with emailed code"))
+
 		// Forward to the sign-in page with a success message
 		return ctx.Redirect(http.StatusSeeOther, "/signin?message=password-reset&username="+user.Username)
 	}
@@ -46,13 +46,21 @@ func PostMasquerade(serverFactory *server.Factory) echo.HandlerFunc {
 			return derp.Wrap(err, location, "Error loading User", derp.WithCode(http.StatusBadRequest))
 		}
 
-		// Create a masquerade certificate for the requested User
+		// Create a masquerade certificate for the requested User.  This does not
+		// require the User's second factor, because the domain owner has already
+		// signed in with their own.  Two-factor settings cannot be changed while
+		// masquerading (see isMasquerading).
 		certificate, err := s.CreateCertificate(ctx.Request(), &user)
 
 		if err != nil {
 			return derp.Wrap(err, location, "Error creating JWT certificate")
 		}
 
+		// Record the masquerade (as the domain owner) before switching certificates
+		writeAuditLog(ctx, factory, auditAuthenticated(ctx, factory, model.AuditActionMasquerade).
+			WithObject("User", user.UserID).
+			WithSummary("Signed in as "+user.Username))
+
 		// Push the certificate and make a -backup cookie
 		s.PushCookie(ctx, certificate)
 
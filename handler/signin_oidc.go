@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/benpate/steranko"
+	"github.com/labstack/echo/v4"
+)
+
+// GetSignInOIDC begins a sign-in through the domain's OpenID Connect provider
+func GetSignInOIDC(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.GetSignInOIDC"
+
+	oidcService := factory.OIDC()
+	challenge, err := oidcService.NewChallenge(localURL(ctx.QueryParam("next")))
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error creating challenge")
+	}
+
+	return redirectToOIDCProvider(ctx, factory, challenge)
+}
+
+// GetSignInOIDCCallback completes a sign-in (or account link) after the User returns
+// from the OpenID Connect provider.  Signed-in Users are still subject to the domain's
+// two-factor authentication policy.
+func GetSignInOIDCCallback(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.GetSignInOIDCCallback"
+
+	oidcService := factory.OIDC()
+
+	// Retrieve (and remove) the challenge that started this sign-in
+	challenge, err := getOIDCChallenge(ctx, factory)
+	clearOIDCChallenge(ctx)
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, location, "Invalid challenge"))
+		return ctx.Redirect(http.StatusSeeOther, "/signin?message=oidc-failed")
+	}
+
+	// The provider reports errors (such as the User cancelling) as query parameters
+	if providerError := ctx.QueryParam("error"); providerError != "" {
+		derp.Report(derp.NewBadRequestError(location, "Provider returned an error", providerError, ctx.QueryParam("error_description")))
+		return ctx.Redirect(http.StatusSeeOther, oidcFailureURL(challenge))
+	}
+
+	// Exchange the authorization code for a verified identity
+	identity, err := oidcService.Exchange(ctx.Request().Context(), challenge, ctx.QueryParam("state"), ctx.QueryParam("code"))
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, location, "Error validating provider response"))
+		return ctx.Redirect(http.StatusSeeOther, oidcFailureURL(challenge))
+	}
+
+	// Link the identity to a signed-in User
+	if !challenge.LinkUser.IsZero() {
+
+		authorization := getAuthorization(ctx)
+
+		if authorization.UserID != challenge.LinkUser {
+			return derp.NewForbiddenError(location, "Account links must be completed by the same user who started them")
+		}
+
+		user := model.NewUser()
+
+		if err := factory.User().LoadByID(authorization.UserID, &user); err != nil {
+			return derp.Wrap(err, location, "Error loading user")
+		}
+
+		if err := oidcService.LinkUser(identity, &user); err != nil {
+			derp.Report(derp.Wrap(err, location, "Error linking user"))
+			return ctx.Redirect(http.StatusSeeOther, oidcFailureURL(challenge))
+		}
+
+		writeAuditLog(ctx, factory, auditUser(model.AuditActionSecurityChange, &user, "Linked OpenID Connect account"))
+
+		return ctx.Redirect(http.StatusSeeOther, firstOf(challenge.Next, "/@me/security"))
+	}
+
+	// Otherwise, find (or create) the User for this identity, and sign them in
+	user := model.NewUser()
+
+	if err := oidcService.LoadUser(identity, &user); err != nil {
+
+		if derp.NotFound(err) {
+			return ctx.Redirect(http.StatusSeeOther, "/signin?message=oidc-no-account")
+		}
+
+		derp.Report(derp.Wrap(err, location, "Error loading user"))
+		return ctx.Redirect(http.StatusSeeOther, oidcFailureURL(challenge))
+	}
+
+	next, err := signInUser(ctx, factory, &user, "OpenID Connect", challenge.Next)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error signing in")
+	}
+
+	return ctx.Redirect(http.StatusSeeOther, next)
+}
+
+// PostSecurityOIDCLink begins linking the signed-in User to an identity at the domain's OpenID Connect provider
+func PostSecurityOIDCLink(ctx *steranko.Context, factory *domain.Factory, user *model.User) error {
+
+	const location = "handler.PostSecurityOIDCLink"
+
+	if err := guardSecurityChange(ctx); err != nil {
+		return derp.Wrap(err, location, "Cannot change sign-in settings")
+	}
+
+	challenge, err := factory.OIDC().NewChallenge("/@me/security")
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error creating challenge")
+	}
+
+	challenge.LinkUser = user.UserID
+
+	return redirectToOIDCProvider(ctx, factory, challenge)
+}
+
+// PostSecurityOIDCUnlink removes the link between the signed-in User and their OpenID Connect identity
+func PostSecurityOIDCUnlink(ctx *steranko.Context, factory *domain.Factory, user *model.User) error {
+
+	const location = "handler.PostSecurityOIDCUnlink"
+
+	if err := guardSecurityChange(ctx); err != nil {
+		return derp.Wrap(err, location, "Cannot change sign-in settings")
+	}
+
+	// RULE: Users without a password must keep their provider link, or they could not sign in again
+	if user.Password == "" {
+		return ctx.Redirect(http.StatusSeeOther, "/@me/security?message=oidc-password")
+	}
+
+	if err := factory.OIDC().UnlinkUser(user); err != nil {
+		return derp.Wrap(err, location, "Error unlinking user")
+	}
+
+	writeAuditLog(ctx, factory, auditUser(model.AuditActionSecurityChange, user, "Unlinked OpenID Connect account"))
+
+	return ctx.Redirect(http.StatusSeeOther, "/@me/security")
+}
+
+// PostOIDCClientSecret updates (or removes) the client secret for the domain's OpenID Connect
+// provider.  The secret is write-only, and is never displayed in the admin form.
+// It can only be called by an authenticated administrator.
+func PostOIDCClientSecret(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostOIDCClientSecret"
+
+	// Verify that this is an Administrator
+	authorization := getAuthorization(ctx)
+
+	if !authorization.DomainOwner {
+		return derp.NewForbiddenError(location, "Only administrators can call this method")
+	}
+
+	if err := factory.Domain().SetOIDCClientSecret(ctx.FormValue("value")); err != nil {
+		return derp.Wrap(err, location, "Error saving client secret")
+	}
+
+	// Success.
+	ctx.Response().Header().Set("HX-Trigger", "refreshPage")
+	return ctx.NoContent(http.StatusOK)
+}
+
+/******************************************
+ * OIDC Helpers
+ ******************************************/
+
+// redirectToOIDCProvider stores the challenge in the User's browser, and redirects them to the provider
+func redirectToOIDCProvider(ctx *steranko.Context, factory *domain.Factory, challenge model.OIDCChallenge) error {
+
+	const location = "handler.redirectToOIDCProvider"
+
+	oidcService := factory.OIDC()
+	authCodeURL, err := oidcService.AuthCodeURL(ctx.Request().Context(), challenge)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error connecting to provider")
+	}
+
+	token, err := oidcService.NewChallengeToken(challenge)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error creating challenge token")
+	}
+
+	// SameSite=Lax allows the cookie to be sent when the provider redirects back to us
+	ctx.SetCookie(&http.Cookie{
+		Name:     oidcCookieName(ctx.Request()),
+		Value:    token,
+		MaxAge:   int(model.OIDCChallengeDuration / time.Second),
+		Path:     "/",
+		Secure:   ctx.IsTLS(),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return ctx.Redirect(http.StatusSeeOther, authCodeURL)
+}
+
+// oidcCookieName returns the name of the cookie that stores an in-progress OpenID Connect sign-in
+func oidcCookieName(request *http.Request) string {
+
+	if request.TLS != nil {
+		return "__Host-OIDC"
+	}
+
+	return "OIDC"
+}
+
+// getOIDCChallenge retrieves and validates the challenge stored in the User's browser
+func getOIDCChallenge(ctx echo.Context, factory *domain.Factory) (model.OIDCChallenge, error) {
+
+	const location = "handler.getOIDCChallenge"
+
+	cookie, err := ctx.Cookie(oidcCookieName(ctx.Request()))
+
+	if err != nil {
+		return model.OIDCChallenge{}, derp.NewUnauthorizedError(location, "Sign-in has expired.  Please try again.")
+	}
+
+	challenge, err := factory.OIDC().ParseChallengeToken(cookie.Value)
+
+	if err != nil {
+		return model.OIDCChallenge{}, derp.Wrap(err, location, "Sign-in has expired.  Please try again.", derp.WithCode(http.StatusUnauthorized))
+	}
+
+	return challenge, nil
+}
+
+// clearOIDCChallenge removes the challenge from the User's browser
+func clearOIDCChallenge(ctx echo.Context) {
+
+	ctx.SetCookie(&http.Cookie{
+		Name:     oidcCookieName(ctx.Request()),
+		Value:    "",
+		MaxAge:   -1,
+		Path:     "/",
+		Secure:   ctx.IsTLS(),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// oidcFailureURL returns the page to display when a sign-in (or account link) fails
+func oidcFailureURL(challenge model.OIDCChallenge) string {
+
+	if !challenge.LinkUser.IsZero() {
+		return "/@me/security?message=oidc-failed"
+	}
+
+	return "/signin?message=oidc-failed"
+}
@@ -0,0 +1,459 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/random"
+	"github.com/EmissarySocial/emissary/tools/totp"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/benpate/steranko"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetSignInTwoFactor displays the second-factor challenge for a User who has entered a
+// valid password.  If the domain requires two-factor authentication and the User has
+// not enrolled yet, then the TOTP enrollment form is displayed instead.
+func GetSignInTwoFactor(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.GetSignInTwoFactor"
+
+	user := model.NewUser()
+
+	if _, err := loadTwoFactorChallenge(ctx, factory, model.TwoFactorChallengePurposeSignIn, &user); err != nil {
+		return derp.Wrap(err, location, "Invalid sign-in challenge")
+	}
+
+	data := twoFactorPageData(ctx, factory)
+
+	if !user.TwoFactor.IsEnabled() {
+		return renderTOTPEnrollment(ctx, factory, &user, data, "/signin/2fa")
+	}
+
+	data["hasTOTP"] = user.TwoFactor.TOTPEnabled
+	data["hasPasskeys"] = user.TwoFactor.HasPasskeys()
+
+	return renderTwoFactorPage(ctx, factory, "signin-2fa", data)
+}
+
+// PostSignInTwoFactor validates a TOTP or recovery code (or confirms a new TOTP enrollment)
+// and completes the sign-in.
+func PostSignInTwoFactor(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostSignInTwoFactor"
+
+	user := model.NewUser()
+	challenge, err := loadTwoFactorChallenge(ctx, factory, model.TwoFactorChallengePurposeSignIn, &user)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid sign-in challenge")
+	}
+
+	code := ctx.FormValue("code")
+	data := twoFactorPageData(ctx, factory)
+
+	// Users who are required to enroll must confirm their new TOTP secret
+	if !user.TwoFactor.IsEnabled() {
+
+		ok, err := factory.TwoFactor().EnableTOTP(&user, code)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error confirming TOTP secret")
+		}
+
+		if !ok {
+			data["error"] = factory.Translation().Translate(data.GetString("locale"), "twoFactor.invalid")
+			return renderTOTPEnrollment(ctx, factory, &user, data, "/signin/2fa")
+		}
+
+		// Save the new TOTP secret (and recovery codes) before signing in
+		codes, err := saveRecoveryCodes(factory, &user)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error saving recovery codes")
+		}
+
+		next, err := completeSignIn(ctx, factory, &user, "authenticator app", challenge.Next)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error signing in")
+		}
+
+		data["codes"] = codes
+		data["next"] = next
+		return renderTwoFactorPage(ctx, factory, "two-factor-recovery", data)
+	}
+
+	// (short) random sleep to slow down guessing
+	time.Sleep(time.Duration(random.GenerateInt(500, 1500)) * time.Millisecond)
+
+	ok, err := factory.TwoFactor().ValidateCode(&user, code)
+
+	if err != nil {
+
+		// Too many invalid codes means that the User must wait, then start over with their password
+		if derp.ErrorCode(err) == http.StatusTooManyRequests {
+			clearTwoFactorChallenge(ctx)
+			return ctx.Redirect(http.StatusSeeOther, "/signin?message=two-factor-locked")
+		}
+
+		return derp.Wrap(err, location, "Error validating code")
+	}
+
+	if !ok {
+		writeAuditLog(ctx, factory, auditUser(model.AuditActionTwoFactorFailed, &user, "Invalid two-factor code"))
+		data["hasTOTP"] = user.TwoFactor.TOTPEnabled
+		data["hasPasskeys"] = user.TwoFactor.HasPasskeys()
+		data["error"] = factory.Translation().Translate(data.GetString("locale"), "twoFactor.invalid")
+		return renderTwoFactorPage(ctx, factory, "signin-2fa", data)
+	}
+
+	next, err := completeSignIn(ctx, factory, &user, "two-factor code", challenge.Next)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error signing in")
+	}
+
+	return ctx.Redirect(http.StatusSeeOther, next)
+}
+
+// PostSignInTwoFactorPasskey returns WebAuthn options for a passkey challenge, after the
+// User has entered a valid password.
+func PostSignInTwoFactorPasskey(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostSignInTwoFactorPasskey"
+
+	user := model.NewUser()
+	challenge, err := loadTwoFactorChallenge(ctx, factory, model.TwoFactorChallengePurposeSignIn, &user)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid sign-in challenge")
+	}
+
+	assertion, session, err := factory.TwoFactor().BeginLogin(&user)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error beginning passkey challenge")
+	}
+
+	// Remember the WebAuthn session in the (still unexpired) challenge
+	challenge.Session = session
+
+	if err := setTwoFactorChallenge(ctx, factory, challenge); err != nil {
+		return derp.Wrap(err, location, "Error saving challenge")
+	}
+
+	return ctx.JSON(http.StatusOK, assertion)
+}
+
+// PostSignInTwoFactorPasskeyFinish validates a passkey challenge and completes the sign-in.
+func PostSignInTwoFactorPasskeyFinish(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostSignInTwoFactorPasskeyFinish"
+
+	user := model.NewUser()
+	challenge, err := loadTwoFactorChallenge(ctx, factory, model.TwoFactorChallengePurposeSignIn, &user)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid sign-in challenge")
+	}
+
+	if challenge.Session == nil {
+		return derp.NewBadRequestError(location, "Passkey challenge has not been started")
+	}
+
+	if err := factory.TwoFactor().FinishLogin(&user, *challenge.Session, ctx.Request()); err != nil {
+		writeAuditLog(ctx, factory, auditUser(model.AuditActionTwoFactorFailed, &user, "Invalid passkey"))
+		return derp.Wrap(err, location, "Invalid passkey")
+	}
+
+	next, err := completeSignIn(ctx, factory, &user, "passkey", challenge.Next)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error signing in")
+	}
+
+	return ctx.JSON(http.StatusOK, mapof.Any{"next": next})
+}
+
+// PostSignInPasskey returns WebAuthn options for a passwordless sign-in.
+func PostSignInPasskey(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostSignInPasskey"
+
+	assertion, session, err := factory.TwoFactor().BeginPasskeyLogin()
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error beginning passkey sign-in")
+	}
+
+	challenge := model.NewTwoFactorChallenge(model.TwoFactorChallengePurposePasskey, primitive.NilObjectID, ctx.QueryParam("next"))
+	challenge.Session = session
+
+	if err := setTwoFactorChallenge(ctx, factory, challenge); err != nil {
+		return derp.Wrap(err, location, "Error saving challenge")
+	}
+
+	return ctx.JSON(http.StatusOK, assertion)
+}
+
+// PostSignInPasskeyFinish validates a passwordless sign-in.  Passkeys require user
+// verification (a PIN or biometric) so they satisfy the domain's two-factor policy.
+func PostSignInPasskeyFinish(ctx *steranko.Context, factory *domain.Factory) error {
+
+	const location = "handler.PostSignInPasskeyFinish"
+
+	challenge, err := getTwoFactorChallenge(ctx, factory, model.TwoFactorChallengePurposePasskey)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid passkey challenge")
+	}
+
+	if challenge.Session == nil {
+		return derp.NewBadRequestError(location, "Passkey challenge has not been started")
+	}
+
+	user := model.NewUser()
+
+	if err := factory.TwoFactor().FinishPasskeyLogin(*challenge.Session, ctx.Request(), &user); err != nil {
+		return derp.Wrap(err, location, "Invalid passkey")
+	}
+
+	next, err := completeSignIn(ctx, factory, &user, "passkey", challenge.Next)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error signing in")
+	}
+
+	return ctx.JSON(http.StatusOK, mapof.Any{"next": next})
+}
+
+/******************************************
+ * Sign-In Helpers
+ ******************************************/
+
+// signInUser signs in a User whose password (or other primary credential) has been
+// verified.  If the User has enabled two-factor authentication, or the domain requires
+// it, then a challenge is started instead.  It returns the URL to redirect to next.
+func signInUser(ctx echo.Context, factory *domain.Factory, user *model.User, method string, next string) (string, error) {
+
+	const location = "handler.signInUser"
+
+	domain := factory.Domain().Get()
+
+	if !user.TwoFactor.IsEnabled() && !domain.RequiresTwoFactor(user) {
+		return completeSignIn(ctx, factory, user, method, next)
+	}
+
+	// A valid password does NOT reset the count of invalid codes.  Only a valid
+	// second factor (or the end of the lockout period) allows more guesses.
+	challenge := model.NewTwoFactorChallenge(model.TwoFactorChallengePurposeSignIn, user.UserID, next)
+
+	if err := setTwoFactorChallenge(ctx, factory, challenge); err != nil {
+		return "", derp.Wrap(err, location, "Error starting two-factor challenge")
+	}
+
+	return "/signin/2fa", nil
+}
+
+// completeSignIn creates a session for a User who has passed all required factors,
+// and returns the URL to redirect to next.  The method (e.g. "password") is recorded
+// in the domain's audit log.
+func completeSignIn(ctx echo.Context, factory *domain.Factory, user *model.User, method string, next string) (string, error) {
+
+	certificate, err := factory.Steranko().CreateCertificate(ctx.Request(), user)
+
+	if err != nil {
+		return "", derp.Wrap(err, "handler.completeSignIn", "Error creating JWT certificate")
+	}
+
+	ctx.SetCookie(&certificate)
+	clearTwoFactorChallenge(ctx)
+
+	writeAuditLog(ctx, factory, auditUser(model.AuditActionSignIn, user, "Signed in with "+method))
+
+	return firstOf(localURL(next), "/@me"), nil
+}
+
+// localURL returns the provided URL only if it is a path on this domain
+func localURL(value string) string {
+
+	if !strings.HasPrefix(value, "/") || strings.HasPrefix(value, "//") || strings.HasPrefix(value, "/\\") {
+		return ""
+	}
+
+	return value
+}
+
+// isMasquerading returns TRUE if a domain owner is signed in as another User
+func isMasquerading(ctx echo.Context) bool {
+	_, err := ctx.Cookie(steranko.CookieName(ctx.Request()) + "-backup")
+	return err == nil
+}
+
+/******************************************
+ * Challenge Cookies
+ ******************************************/
+
+// twoFactorCookieName returns the name of the cookie that stores an in-progress challenge
+func twoFactorCookieName(request *http.Request) string {
+
+	if request.TLS != nil {
+		return "__Host-TwoFactor"
+	}
+
+	return "TwoFactor"
+}
+
+// setTwoFactorChallenge signs a challenge and stores it in the User's browser
+func setTwoFactorChallenge(ctx echo.Context, factory *domain.Factory, challenge model.TwoFactorChallenge) error {
+
+	token, err := factory.TwoFactor().NewChallengeToken(challenge)
+
+	if err != nil {
+		return derp.Wrap(err, "handler.setTwoFactorChallenge", "Error creating challenge token")
+	}
+
+	ctx.SetCookie(&http.Cookie{
+		Name:     twoFactorCookieName(ctx.Request()),
+		Value:    token,
+		MaxAge:   int(model.TwoFactorChallengeDuration / time.Second),
+		Path:     "/",
+		Secure:   ctx.IsTLS(),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
+
+// getTwoFactorChallenge retrieves and validates the challenge stored in the User's browser
+func getTwoFactorChallenge(ctx echo.Context, factory *domain.Factory, purpose string) (model.TwoFactorChallenge, error) {
+
+	const location = "handler.getTwoFactorChallenge"
+
+	cookie, err := ctx.Cookie(twoFactorCookieName(ctx.Request()))
+
+	if err != nil {
+		return model.TwoFactorChallenge{}, derp.NewUnauthorizedError(location, "Sign-in has expired.  Please try again.")
+	}
+
+	challenge, err := factory.TwoFactor().ParseChallengeToken(cookie.Value, purpose)
+
+	if err != nil {
+		return model.TwoFactorChallenge{}, derp.Wrap(err, location, "Sign-in has expired.  Please try again.", derp.WithCode(http.StatusUnauthorized))
+	}
+
+	return challenge, nil
+}
+
+// loadTwoFactorChallenge retrieves the challenge stored in the User's browser, and loads the User that it references
+func loadTwoFactorChallenge(ctx echo.Context, factory *domain.Factory, purpose string, user *model.User) (model.TwoFactorChallenge, error) {
+
+	const location = "handler.loadTwoFactorChallenge"
+
+	challenge, err := getTwoFactorChallenge(ctx, factory, purpose)
+
+	if err != nil {
+		return model.TwoFactorChallenge{}, derp.Wrap(err, location, "Invalid challenge")
+	}
+
+	if err := factory.TwoFactor().LoadUser(challenge.UserID, user); err != nil {
+		return model.TwoFactorChallenge{}, derp.Wrap(err, location, "Error loading user", derp.WithCode(http.StatusUnauthorized))
+	}
+
+	return challenge, nil
+}
+
+// clearTwoFactorChallenge removes the challenge from the User's browser
+func clearTwoFactorChallenge(ctx echo.Context) {
+
+	ctx.SetCookie(&http.Cookie{
+		Name:     twoFactorCookieName(ctx.Request()),
+		Value:    "",
+		MaxAge:   -1,
+		Path:     "/",
+		Secure:   ctx.IsTLS(),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+/******************************************
+ * Rendering Helpers
+ ******************************************/
+
+// twoFactorPageData returns the common values used by all two-factor pages
+func twoFactorPageData(ctx echo.Context, factory *domain.Factory) mapof.Any {
+
+	domain := factory.Domain().Get()
+
+	return mapof.Any{
+		"domainName": domain.Label,
+		"domainIcon": domain.IconURL(),
+		"locale":     factory.Translation().Negotiate(ctx.Request().Header.Get("Accept-Language")),
+	}
+}
+
+// renderTwoFactorPage executes one of the global two-factor templates
+func renderTwoFactorPage(ctx echo.Context, factory *domain.Factory, templateName string, data mapof.Any) error {
+
+	template := factory.Domain().Theme().HTMLTemplate
+
+	if err := template.ExecuteTemplate(ctx.Response(), templateName, data); err != nil {
+		return derp.Wrap(err, "handler.renderTwoFactorPage", "Error executing template", templateName)
+	}
+
+	return nil
+}
+
+// renderTOTPEnrollment displays the QR code (and secret) for a new TOTP enrollment.
+// A pending secret is created if the User does not already have one.
+func renderTOTPEnrollment(ctx echo.Context, factory *domain.Factory, user *model.User, data mapof.Any, action string) error {
+
+	const location = "handler.renderTOTPEnrollment"
+
+	secret, err := factory.TwoFactor().BeginTOTP(user)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error beginning TOTP enrollment")
+	}
+
+	issuer := firstOf(data.GetString("domainName"), factory.Hostname())
+	uri := totp.URI(issuer, user.Username, secret)
+	qrCode, err := qrCodeDataURI(uri)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error generating QR code")
+	}
+
+	data["action"] = action
+	data["secret"] = secret
+	data["qrCode"] = qrCode
+
+	return renderTwoFactorPage(ctx, factory, "two-factor-enroll", data)
+}
+
+// saveRecoveryCodes replaces the User's recovery codes, and returns the new plaintext codes
+func saveRecoveryCodes(factory *domain.Factory, user *model.User) (sliceof.String, error) {
+
+	const location = "handler.saveRecoveryCodes"
+
+	codes, err := user.TwoFactor.NewRecoveryCodes()
+
+	if err != nil {
+		return nil, derp.Wrap(err, location, "Error generating recovery codes")
+	}
+
+	if err := factory.User().Save(user, "Two-factor recovery codes generated"); err != nil {
+		return nil, derp.Wrap(err, location, "Error saving user")
+	}
+
+	return codes, nil
+}
@@ -51,18 +51,13 @@ func GetSingleSignOn(ctx *steranko.Context, factory *domain.Factory, domain *mod
 		return derp.Wrap(err, location, "Error loading user")
 	}
 
-	// Create a sign-in session for the user
-	sterankoService := factory.Steranko()
-
-	certificate, err := sterankoService.CreateCertificate(ctx.Request(), &user)
+	// Sign in the user.  Single sign-on verifies the user's identity, but does not
+	// replace the second factor, so users may still be asked for a 2FA code.
+	next, err := signInUser(ctx, factory, &user, "single sign-on", "/@"+user.Username)
 
 	if err != nil {
-		return derp.Wrap(err, location, "Error creating certificate")
+		return derp.Wrap(err, location, "Error signing in")
 	}
 
-	// Push the certificate and make a -backup cookie
-	sterankoService.PushCookie(ctx, certificate)
-
-	// Forward to the user's profile page
-	return ctx.Redirect(http.StatusSeeOther, "/@"+user.Username)
+	return ctx.Redirect(http.StatusSeeOther, next)
 }
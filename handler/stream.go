@@ -80,6 +80,7 @@ func buildStream(serverFactory *server.Factory, actionMethod build.ActionMethod)
 		// https://www.w3.org/TR/webmention/#sender-discovers-receiver-webmention-endpoint
 		if actionMethod == build.ActionMethodGet {
 			ctx.Response().Header().Set("Link", "/.webmention; rel=\"webmention\"")
+			ctx.Response().Header().Add("Link", "</.micropub>; rel=\"micropub\"")
 		}
 
 		if err := build.AsHTML(factory, ctx, streamBuilder, actionMethod); err != nil {
@@ -43,6 +43,11 @@ func GetWebfinger(fm *server.Factory) echo.HandlerFunc {
 			return writeResource(ctx, resource)
 		}
 
+		// Next, look for SavedSearches (which are always ActivityPub actors)
+		if resource, err := factory.SavedSearch().LoadWebFinger(resourceID); err == nil {
+			return writeResource(ctx, resource)
+		}
+
 		// Otherwise, break unceremoniously
 		return derp.NewBadRequestError(location, "Invalid Resource", resourceID)
 	}
@@ -20,6 +20,7 @@ func PostWebMention(serverFactory *server.Factory) echo.HandlerFunc {
 		var body struct {
 			Source string `form:"source"`
 			Target string `form:"target"`
+			Vouch  string `form:"vouch"`
 		}
 
 		// Try to collect form data into the body struct
@@ -29,8 +30,10 @@ func PostWebMention(serverFactory *server.Factory) echo.HandlerFunc {
 
 		// Prepare a task to process the webmention asynchronously
 		task := queue.NewTask("ReceiveWebMention", mapof.Any{
+			"host":   ctx.Request().Host,
 			"source": body.Source,
 			"target": body.Target,
+			"vouch":  body.Vouch,
 		})
 
 		// Push the new task onto the background queue.
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/EmissarySocial/emissary/server"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/benpate/derp"
+	"github.com/benpate/hannibal"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuthorizedFetch middleware requires that ActivityPub GET requests include a valid
+// HTTP signature whenever the domain has enabled "authorized fetch" (aka "secure mode").
+// Signed requests from Actors or domains that are blocked by a domain-wide Rule are also rejected.
+func AuthorizedFetch(factory *server.Factory) echo.MiddlewareFunc {
+
+	const location = "middleware.AuthorizedFetch"
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+
+		return func(ctx echo.Context) error {
+
+			// Only ActivityPub fetches are affected
+			if !isActivityPubFetch(ctx.Request()) {
+				return next(ctx)
+			}
+
+			domainFactory, err := factory.ByContext(ctx)
+
+			if err != nil {
+				return derp.NewForbiddenError(location, "Unrecognized domain", ctx.Request().URL.Hostname(), err)
+			}
+
+			// Domain-wide block rules are only loaded once the signature has been verified
+			allow := func(actorID string) bool {
+				ruleFilter := domainFactory.Rule().Filter(primitive.NilObjectID, service.WithBlocksOnly())
+				return ruleFilter.AllowSend(actorID)
+			}
+
+			enabled := domainFactory.Domain().Get().AuthorizedFetch
+
+			if err := authorizeFetch(ctx.Request(), enabled, domainFactory.ActivityStream().VerifyRequest, allow); err != nil {
+				return derp.Wrap(err, location, "Request is not authorized")
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// authorizeFetch returns an error if a domain that has enabled authorized fetch receives
+// a request that is unsigned, has an invalid signature, or was signed by a blocked Actor.
+func authorizeFetch(request *http.Request, enabled bool, verify func(*http.Request) (string, error), allow func(string) bool) error {
+
+	const location = "middleware.authorizeFetch"
+
+	// Skip domains that have not enabled authorized fetch
+	if !enabled {
+		return nil
+	}
+
+	// Verify the HTTP signature, and find the Actor who signed the request
+	actorID, err := verify(request)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Authorized fetch requires a valid HTTP signature")
+	}
+
+	// Reject Actors (and domains) that are blocked by this domain
+	if !allow(actorID) {
+		return derp.NewForbiddenError(location, "Actor is blocked by this domain", actorID)
+	}
+
+	return nil
+}
+
+// isActivityPubFetch returns TRUE if the request is an ActivityPub GET that
+// should be protected by authorized fetch.  The domain's service actor is always
+// available, so that remote servers can verify the signatures we send them.
+func isActivityPubFetch(request *http.Request) bool {
+
+	if (request.Method != http.MethodGet) && (request.Method != http.MethodHead) {
+		return false
+	}
+
+	path := request.URL.Path
+
+	if (path == "/@service") || strings.HasPrefix(path, "/@service/") || strings.HasPrefix(path, "/.well-known/") {
+		return false
+	}
+
+	if strings.HasSuffix(path, "/pub") || strings.Contains(path, "/pub/") {
+		return true
+	}
+
+	return hannibal.IsActivityPubContentType(request.Header.Get("Accept"))
+}
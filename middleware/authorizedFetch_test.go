@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benpate/derp"
+	"github.com/benpate/hannibal/sigs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeFetch(t *testing.T) {
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	const keyID = "https://remote.example/users/alice#main-key"
+	const actorID = "https://remote.example/users/alice"
+
+	// verify checks signatures against the public key published by alice
+	verify := func(request *http.Request) (string, error) {
+
+		if !sigs.HasSignature(request) {
+			return "", derp.NewUnauthorizedError("test", "Unsigned")
+		}
+
+		keyFinder := func(id string) (string, error) {
+			if id != keyID {
+				return "", derp.NewForbiddenError("test", "Unknown key", id)
+			}
+			return sigs.EncodePublicPEM(privateKey), nil
+		}
+
+		if err := sigs.Verify(request, keyFinder, sigs.VerifierFields(sigs.FieldRequestTarget, sigs.FieldHost), sigs.VerifierIgnoreBodyDigest()); err != nil {
+			return "", derp.NewUnauthorizedError("test", "Invalid signature", err.Error())
+		}
+
+		return actorID, nil
+	}
+
+	allowAll := func(string) bool { return true }
+	blockAll := func(string) bool { return false }
+
+	newRequest := func(key *rsa.PrivateKey) *http.Request {
+		request := httptest.NewRequest(http.MethodGet, "https://local.example/@bob/pub/outbox", nil)
+		request.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+
+		if key != nil {
+			require.Nil(t, sigs.Sign(request, keyID, key, sigs.SignerFields(sigs.FieldRequestTarget, sigs.FieldHost, sigs.FieldDate)))
+		}
+
+		return request
+	}
+
+	unsigned := newRequest(nil)
+	badlySigned := newRequest(otherKey)
+	validlySigned := newRequest(privateKey)
+
+	// When authorized fetch is off, every request is allowed
+	require.Nil(t, authorizeFetch(unsigned, false, verify, allowAll))
+	require.Nil(t, authorizeFetch(badlySigned, false, verify, allowAll))
+	require.Nil(t, authorizeFetch(validlySigned, false, verify, allowAll))
+
+	// When authorized fetch is on, only valid signatures are allowed
+	err = authorizeFetch(unsigned, true, verify, allowAll)
+	require.Equal(t, http.StatusUnauthorized, derp.ErrorCode(err))
+
+	err = authorizeFetch(badlySigned, true, verify, allowAll)
+	require.Equal(t, http.StatusUnauthorized, derp.ErrorCode(err))
+
+	require.Nil(t, authorizeFetch(validlySigned, true, verify, allowAll))
+
+	// Blocked Actors are rejected, even with a valid signature
+	err = authorizeFetch(validlySigned, true, verify, blockAll)
+	require.Equal(t, http.StatusForbidden, derp.ErrorCode(err))
+}
+
+func TestIsActivityPubFetch(t *testing.T) {
+
+	request := httptest.NewRequest(http.MethodGet, "/@bob/pub/outbox", nil)
+	require.True(t, isActivityPubFetch(request))
+
+	request = httptest.NewRequest(http.MethodGet, "/@bob", nil)
+	require.False(t, isActivityPubFetch(request))
+
+	request.Header.Set("Accept", "application/activity+json")
+	require.True(t, isActivityPubFetch(request))
+
+	// The service actor is always available, so that remote servers can verify our signatures
+	request = httptest.NewRequest(http.MethodGet, "/@service", nil)
+	request.Header.Set("Accept", "application/activity+json")
+	require.False(t, isActivityPubFetch(request))
+
+	request = httptest.NewRequest(http.MethodPost, "/@bob/pub/inbox", nil)
+	require.False(t, isActivityPubFetch(request))
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/EmissarySocial/emissary/server"
+	"github.com/benpate/derp"
+	"github.com/labstack/echo/v4"
+)
+
+// ProvisioningPath is the URL prefix for all provisioning API requests
+const ProvisioningPath = "/.provisioning"
+
+// Provisioning is a "Pre" middleware that routes provisioning API requests to a separate
+// router.  The provisioning API is available on every hostname (even ones that have not
+// been configured yet) so these requests must skip the domain-specific middleware.
+func Provisioning(api *echo.Echo) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+
+		return func(ctx echo.Context) error {
+
+			path := ctx.Request().URL.Path
+
+			if (path == ProvisioningPath) || strings.HasPrefix(path, ProvisioningPath+"/") {
+				api.ServeHTTP(ctx.Response(), ctx.Request())
+				return nil
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// ProvisioningToken is a middleware that requires a valid bearer token for the provisioning API.
+// If no token is configured, then the provisioning API is disabled entirely.
+func ProvisioningToken(factory *server.Factory) echo.MiddlewareFunc {
+
+	const location = "middleware.ProvisioningToken"
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+
+		return func(ctx echo.Context) error {
+
+			token := factory.Config().ProvisioningToken
+
+			// RULE: Provisioning API is disabled unless a token is configured
+			if token == "" {
+				return derp.NewNotFoundError(location, "Provisioning API is not enabled")
+			}
+
+			authorization := ctx.Request().Header.Get("Authorization")
+			bearer, found := strings.CutPrefix(authorization, "Bearer ")
+
+			if !found || (subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1) {
+				ctx.Response().Header().Set("WWW-Authenticate", `Bearer realm="provisioning"`)
+				return derp.New(http.StatusUnauthorized, location, "Invalid provisioning token")
+			}
+
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/server"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/EmissarySocial/emissary/tools/ratelimit"
+	"github.com/benpate/derp"
+	"github.com/benpate/hannibal/sigs"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RateLimit middleware counts each request against the configured limit for a class of
+// routes (like "signin"), using one bucket per key (like the client's IP address).
+// Throttled requests are rejected with "429 Too Many Requests", and the first request
+// to exceed each limit is written to the domain's audit log.
+func RateLimit(factory *server.Factory, class string, keys ...ratelimit.KeyFunc) echo.MiddlewareFunc {
+
+	const location = "middleware.RateLimit"
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+
+		return func(ctx echo.Context) error {
+
+			limiter := factory.RateLimiter()
+
+			if limiter == nil {
+				return next(ctx)
+			}
+
+			request := ctx.Request()
+			event, found, err := limiter.Allow(class, request.Host, request, keys...)
+
+			// Rate limits should never take the site down, so allow the request if the store fails
+			if err != nil {
+				derp.Report(derp.Wrap(err, location, "Error checking rate limit.  Allowing request.", class))
+				return next(ctx)
+			}
+
+			if !found {
+				return next(ctx)
+			}
+
+			event.SetHeaders(ctx.Response().Header())
+
+			if event.Allowed {
+				return next(ctx)
+			}
+
+			// Record only the first throttled request, so that attackers cannot flood the audit log
+			if event.Throttled {
+				writeRateLimitAuditLog(factory, ctx, event)
+			}
+
+			return derp.New(http.StatusTooManyRequests, location, "Too many requests", class, event.KeyType)
+		}
+	}
+}
+
+// VerifyActor middleware verifies the HTTP signature on ActivityPub requests, and remembers
+// the Actor who signed them (see service.VerifiedActor) so that rate limits can count requests
+// by the Actor's domain, and inbox handlers do not need to verify the signature a second time.
+// Requests with missing or invalid signatures are passed through to the handler unchanged.
+func VerifyActor(factory *server.Factory) echo.MiddlewareFunc {
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+
+		return func(ctx echo.Context) error {
+
+			request := ctx.Request()
+
+			if !sigs.HasSignature(request) {
+				return next(ctx)
+			}
+
+			domainFactory, err := factory.ByContext(ctx)
+
+			if err != nil {
+				return derp.Wrap(err, "middleware.VerifyActor", "Unrecognized domain", request.Host)
+			}
+
+			if actorID, err := domainFactory.ActivityStream().VerifyRequest(request); err == nil {
+				ctx.SetRequest(service.WithVerifiedActor(request, actorID))
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// writeRateLimitAuditLog records a throttled client in the domain's audit log
+func writeRateLimitAuditLog(factory *server.Factory, ctx echo.Context, event ratelimit.Event) {
+
+	const location = "middleware.writeRateLimitAuditLog"
+
+	domainFactory, err := factory.ByContext(ctx)
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, location, "Unrecognized domain"))
+		return
+	}
+
+	auditLog := model.NewAuditLog(model.AuditActionRateLimit).
+		WithSummary(event.Class + " limit of " + event.Rule.String() + " exceeded by " + event.KeyType + " " + event.Key)
+
+	// Account keys are usernames or email addresses that were submitted by the client
+	if event.KeyType == ratelimit.KeyTypeAccount {
+		auditLog = auditLog.WithActor(primitive.NilObjectID, event.Key)
+	}
+
+	if err := domainFactory.AuditLog().Add(ctx.Request(), auditLog); err != nil {
+		derp.Report(derp.Wrap(err, location, "Error writing audit log", event.Class))
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/EmissarySocial/emissary/build"
+	"github.com/EmissarySocial/emissary/config"
+	"github.com/EmissarySocial/emissary/server"
+	"github.com/labstack/echo/v4"
+)
+
+// ReadOnlyConfig is a middleware that blocks setup console requests that would
+// change a read-only server configuration.
+func ReadOnlyConfig(factory *server.Factory) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+
+		return func(ctx echo.Context) error {
+
+			if factory.Config().ReadOnly {
+				return build.WrapInlineError(ctx.Response(), config.NewReadOnlyError("middleware.ReadOnlyConfig", ctx.Request().URL.Path))
+			}
+
+			return next(ctx)
+		}
+	}
+}
@@ -20,3 +20,8 @@ func (actor ActorSummary) UsernameOrID() string {
 
 	return actor.ID
 }
+
+// PropertySharedInbox is the name of the ActivityPub "endpoints" property
+// that identifies a server's shared inbox.
+// https://www.w3.org/TR/activitypub/#shared-inbox-delivery
+const PropertySharedInbox = "sharedInbox"
@@ -19,6 +19,7 @@ type Attachment struct {
 	AttachmentID primitive.ObjectID `bson:"_id"`         // ID of this Attachment
 	ObjectID     primitive.ObjectID `bson:"objectId"`    // ID of the Stream that owns this Attachment
 	ObjectType   string             `bson:"objectType"`  // Type of object that owns this Attachment
+	UserID       primitive.ObjectID `bson:"userId"`      // ID of the User who uploaded this Attachment (if known)
 	Original     string             `bson:"original"`    // Original filename uploaded by user
 	Category     string             `bson:"category"`    // Category of the file (defined by the Template)
 	Label        string             `bson:"label"`       // User-defined label for the attachment
@@ -28,8 +29,11 @@ type Attachment struct {
 	Rules        AttachmentRules    `bson:"rules"`       // Rules for downloading this attachment
 	Height       int                `bson:"height"`      // Height of the media file (if applicable)
 	Width        int                `bson:"width"`       // Width of the media file (if applicable)
-	Duration     int                `bbson:"duration"`   // Duration of the media file (if applicable)
+	Duration     int                `bson:"duration"`    // Duration of the media file, in seconds (if applicable)
+	Blurhash     string             `bson:"blurhash"`    // Blurhash placeholder for images and videos (if applicable)
+	Color        string             `bson:"color"`       // Dominant color of the media file, as a hex string (if applicable)
 	Rank         int                `bson:"rank"`        // The sort order to display the attachments in.
+	Size         int64              `bson:"size"`        // Size of the original file, in bytes
 
 	journal.Journal `json:"-" bson:",inline"` // Journal entry for fetch compatability
 }
@@ -144,6 +148,12 @@ func (attachment Attachment) FileSpec(address *url.URL) mediaserver.FileSpec {
 	return attachment.Rules.FileSpec(address, attachment.OriginalExtension())
 }
 
+// Renditions returns the FileSpecs for every version of this Attachment that
+// is allowed by its Rules, so that they can be pre-rendered into the cache.
+func (attachment Attachment) Renditions() []mediaserver.FileSpec {
+	return attachment.Rules.Renditions(attachment.AttachmentID.Hex(), attachment.OriginalExtension())
+}
+
 func (attachment Attachment) JSONLD() map[string]any {
 
 	result := map[string]any{
@@ -158,7 +168,10 @@ func (attachment Attachment) JSONLD() map[string]any {
 		result["height"] = attachment.Height
 	}
 
-	// TODO: Blurhash
+	if attachment.Blurhash != "" {
+		result["blurhash"] = attachment.Blurhash
+	}
+
 	// TODO: FocalPoint?? -> toot:focalPoint (http://joinmastodon.org/ns#focalPoint) https://docs.joinmastodon.org/spec/activitypub/
 	// TODO: Icon (if available) -> icon: {type:"", mediaType:"", url:""}
 
@@ -14,10 +14,11 @@ import (
 
 // AttachmentRules defines the rules for downloading an attachment
 type AttachmentRules struct {
-	Extensions sliceof.String // Allowed extensions.  The first value is used as the default.
-	Width      int            // Fixed width for all downloads
-	Height     int            // Fixed height for all downloads
-	Bitrate    int
+	Extensions   sliceof.String // Allowed extensions.  The first value is used as the default.
+	Width        int            // Fixed width for all downloads
+	Height       int            // Fixed height for all downloads
+	Bitrate      int
+	KeepMetadata bool // If TRUE, then EXIF/GPS metadata is NOT removed from the original file
 }
 
 // NewAttachmentRules returns a fully initialized AttachmentRules object
@@ -30,6 +31,27 @@ func NewAttachmentRules() AttachmentRules {
 	}
 }
 
+// Renditions returns the FileSpecs for every file format allowed by these rules, using the
+// default size.  These are the same FileSpecs that are used to serve requests that have no
+// query parameters, so they can be rendered ahead of time.
+func (rules AttachmentRules) Renditions(filename string, originalExtension string) []mediaserver.FileSpec {
+
+	// If no extensions are defined, then only the default format is used
+	if len(rules.Extensions) == 0 {
+		return []mediaserver.FileSpec{
+			rules.FileSpec(&url.URL{Path: "/" + filename}, originalExtension),
+		}
+	}
+
+	result := make([]mediaserver.FileSpec, 0, len(rules.Extensions))
+
+	for _, extension := range rules.Extensions {
+		result = append(result, rules.FileSpec(&url.URL{Path: "/" + filename + "." + extension}, originalExtension))
+	}
+
+	return result
+}
+
 // FileSpec applies the attachment rules to a request, and returns the best-matching FileSpec definition for mediaserver
 func (rules AttachmentRules) FileSpec(address *url.URL, originalExtension string) mediaserver.FileSpec {
 
@@ -5,9 +5,10 @@ import "github.com/benpate/rosetta/schema"
 func AttachmentRulesSchema() schema.Element {
 	return schema.Object{
 		Properties: schema.ElementMap{
-			"extensions": schema.Array{Items: schema.String{}},
-			"height":     schema.Integer{},
-			"width":      schema.Integer{},
+			"extensions":   schema.Array{Items: schema.String{}},
+			"height":       schema.Integer{},
+			"width":        schema.Integer{},
+			"keepMetadata": schema.Boolean{},
 		},
 	}
 }
@@ -24,6 +25,9 @@ func (rules *AttachmentRules) GetPointer(name string) (any, bool) {
 
 	case "width":
 		return &rules.Width, true
+
+	case "keepMetadata":
+		return &rules.KeepMetadata, true
 	}
 
 	return nil, false
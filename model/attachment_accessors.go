@@ -11,6 +11,7 @@ func AttachmentSchema() schema.Element {
 		Properties: schema.ElementMap{
 			"attachmentId": schema.String{Format: "objectId"},
 			"objectId":     schema.String{Format: "objectId"},
+			"userId":       schema.String{Format: "objectId"},
 			"objectType":   schema.String{Enum: []string{AttachmentObjectTypeDomain, AttachmentObjectTypeSearchTag, AttachmentObjectTypeStream, AttachmentObjectTypeUser}},
 			"category":     schema.String{},
 			"label":        schema.String{},
@@ -21,7 +22,10 @@ func AttachmentSchema() schema.Element {
 			"height":       schema.Integer{},
 			"width":        schema.Integer{},
 			"duration":     schema.Integer{},
+			"blurhash":     schema.String{},
+			"color":        schema.String{Format: "color"},
 			"rank":         schema.Integer{},
+			"size":         schema.Integer{BitSize: 64},
 
 			"rules": AttachmentRulesSchema(),
 		},
@@ -71,6 +75,15 @@ func (attachment *Attachment) GetPointer(name string) (any, bool) {
 	case "duration":
 		return &attachment.Duration, true
 
+	case "blurhash":
+		return &attachment.Blurhash, true
+
+	case "color":
+		return &attachment.Color, true
+
+	case "size":
+		return &attachment.Size, true
+
 	case "rules":
 		return &attachment.Rules, true
 	}
@@ -89,6 +102,9 @@ func (attachment *Attachment) GetStringOK(name string) (string, bool) {
 
 	case "objectId":
 		return attachment.ObjectID.Hex(), true
+
+	case "userId":
+		return attachment.UserID.Hex(), true
 	}
 
 	return "", false
@@ -115,6 +131,12 @@ func (attachment *Attachment) SetString(name string, value string) bool {
 			attachment.ObjectID = objectID
 			return true
 		}
+
+	case "userId":
+		if objectID, err := primitive.ObjectIDFromHex(value); err == nil {
+			attachment.UserID = objectID
+			return true
+		}
 	}
 
 	return false
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/benpate/rosetta/schema"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -25,8 +26,33 @@ func TestAttachmentSchema(t *testing.T) {
 		{"height", "100", 100},
 		{"width", "200", 200},
 		{"duration", "100", 100},
+		{"blurhash", "LEHV6nWB2yk8pyo0adR*.7kCMdnj", nil},
+		{"color", "#336699", nil},
+		{"rules.keepMetadata", "true", true},
 		{"rank", "1", 1},
+		{"userId", "123412341234123412341234", nil},
+		{"size", "1048576", int64(1048576)},
 	}
 
 	tableTest_Schema(t, &s, &attachment, table)
 }
+
+func TestAttachmentRenditions(t *testing.T) {
+
+	attachment := NewAttachment("Stream", primitive.NewObjectID())
+	attachment.Original = "photo.jpg"
+	attachment.SetRules(640, 0, []string{"webp", "jpeg"})
+
+	renditions := attachment.Renditions()
+	require.Equal(t, 2, len(renditions))
+	require.Equal(t, ".webp", renditions[0].Extension)
+	require.Equal(t, ".jpeg", renditions[1].Extension)
+	require.Equal(t, 640, renditions[0].Width)
+	require.Equal(t, attachment.AttachmentID.Hex(), renditions[0].Filename)
+
+	// With no rules, only the default format is rendered
+	attachment.SetRules(0, 0, nil)
+	renditions = attachment.Renditions()
+	require.Equal(t, 1, len(renditions))
+	require.Equal(t, ".webp", renditions[0].Extension)
+}
@@ -0,0 +1,211 @@
+package model
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/benpate/rosetta/sliceof"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditRedacted replaces the values of sensitive fields in the audit log
+const AuditRedacted = "[redacted]"
+
+// auditMaxLength is the longest string value that is stored in the audit log
+const auditMaxLength = 500
+
+// auditIgnoredFields are bookkeeping fields that are never reported as changes
+var auditIgnoredFields = []string{"_id", "createDate", "updateDate", "note", "signature"}
+
+// auditSensitiveFields are (lowercase) fragments of field names whose values are never
+// written to the audit log.  Changes to these fields are still recorded, but redacted.
+var auditSensitiveFields = []string{"password", "secret", "privatekey", "twofactor", "access_token", "refresh_token", "apikey", "credential"}
+
+// AuditChange describes a single field that was changed by an audited action
+type AuditChange struct {
+	Field string `json:"field"          bson:"field"`
+	From  any    `json:"from,omitempty" bson:"from,omitempty"`
+	To    any    `json:"to,omitempty"   bson:"to,omitempty"`
+}
+
+// AuditSnapshot captures the current values of an object as a flat map of
+// (dot-separated) field paths, using the object's BSON field names.
+func AuditSnapshot(object any) map[string]any {
+
+	result := make(map[string]any)
+
+	if object == nil {
+		return result
+	}
+
+	encoded, err := bson.Marshal(object)
+
+	if err != nil {
+		return result
+	}
+
+	document := bson.M{}
+
+	if err := bson.Unmarshal(encoded, &document); err != nil {
+		return result
+	}
+
+	auditFlatten(result, "", document)
+	return result
+}
+
+// AuditChanges compares two snapshots and returns the fields that were changed.
+// Values of sensitive fields are redacted, and long values are truncated.
+func AuditChanges(before map[string]any, after map[string]any) sliceof.Object[AuditChange] {
+
+	result := sliceof.NewObject[AuditChange]()
+
+	// Collect all field names from both snapshots
+	fields := make([]string, 0, len(after))
+
+	for field := range before {
+		fields = append(fields, field)
+	}
+
+	for field := range after {
+		if _, exists := before[field]; !exists {
+			fields = append(fields, field)
+		}
+	}
+
+	sort.Strings(fields)
+
+	for _, field := range fields {
+
+		if auditIsIgnored(field) {
+			continue
+		}
+
+		from, to := before[field], after[field]
+
+		if auditIsEmpty(from) && auditIsEmpty(to) {
+			continue
+		}
+
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+
+		if auditIsSensitive(field) {
+			result = append(result, AuditChange{Field: field, From: AuditRedacted, To: AuditRedacted})
+			continue
+		}
+
+		result = append(result, AuditChange{Field: field, From: auditValue(from), To: auditValue(to)})
+	}
+
+	return result
+}
+
+// auditFlatten copies all values from a (nested) document into a flat map of field paths
+func auditFlatten(result map[string]any, prefix string, value any) {
+
+	switch typed := value.(type) {
+
+	case bson.M:
+		auditFlattenMap(result, prefix, typed)
+
+	case map[string]any:
+		auditFlattenMap(result, prefix, typed)
+
+	case bson.D:
+		for _, element := range typed {
+			auditFlatten(result, auditPath(prefix, element.Key), element.Value)
+		}
+
+	default:
+		result[prefix] = value
+	}
+}
+
+func auditFlattenMap(result map[string]any, prefix string, value map[string]any) {
+
+	// Empty documents are recorded so that removing all keys is still visible
+	if len(value) == 0 && prefix != "" {
+		result[prefix] = nil
+		return
+	}
+
+	for key, child := range value {
+		auditFlatten(result, auditPath(prefix, key), child)
+	}
+}
+
+func auditPath(prefix string, key string) string {
+
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+// auditIsIgnored returns TRUE if the field is a bookkeeping value that should not be reported
+func auditIsIgnored(field string) bool {
+
+	for _, ignored := range auditIgnoredFields {
+		if field == ignored {
+			return true
+		}
+	}
+
+	return false
+}
+
+// auditIsSensitive returns TRUE if any part of the field path may contain a secret
+func auditIsSensitive(field string) bool {
+
+	field = strings.ToLower(field)
+
+	for _, sensitive := range auditSensitiveFields {
+		if strings.Contains(field, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// auditIsEmpty returns TRUE for zero values, so that missing and empty fields are treated the same
+func auditIsEmpty(value any) bool {
+
+	if value == nil {
+		return true
+	}
+
+	switch typed := value.(type) {
+	case string:
+		return typed == ""
+	case bool:
+		return !typed
+	case int32:
+		return typed == 0
+	case int64:
+		return typed == 0
+	case float64:
+		return typed == 0
+	case primitive.ObjectID:
+		return typed.IsZero()
+	case bson.A:
+		return len(typed) == 0
+	}
+
+	return false
+}
+
+// auditValue returns a value that is safe to store in the audit log
+func auditValue(value any) any {
+
+	if text, ok := value.(string); ok && len(text) > auditMaxLength {
+		return text[:auditMaxLength] + "…"
+	}
+
+	return value
+}
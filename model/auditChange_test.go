@@ -0,0 +1,79 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditChanges(t *testing.T) {
+
+	user := NewUser()
+	user.DisplayName = "Alice"
+	user.Username = "alice"
+	user.Password = "old-hash"
+	user.Data["bio"] = "Hello"
+
+	before := AuditSnapshot(&user)
+
+	user.DisplayName = "Alice Adams"
+	user.Password = "new-hash"
+	user.Data["bio"] = strings.Repeat("x", auditMaxLength+10)
+	user.UpdateDate = 1234
+
+	changes := AuditChanges(before, AuditSnapshot(&user))
+
+	fields := make([]string, 0, len(changes))
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+
+	// Bookkeeping fields (like updateDate) are ignored, and results are sorted
+	require.Equal(t, []string{"data.bio", "displayName", "password"}, fields)
+
+	// Long values are truncated
+	require.Equal(t, strings.Repeat("x", auditMaxLength)+"…", changes[0].To)
+
+	// Normal values are recorded as-is
+	require.Equal(t, "Alice", changes[1].From)
+	require.Equal(t, "Alice Adams", changes[1].To)
+
+	// Sensitive values are redacted
+	require.Equal(t, AuditRedacted, changes[2].From)
+	require.Equal(t, AuditRedacted, changes[2].To)
+}
+
+func TestAuditChanges_Empty(t *testing.T) {
+
+	// Missing and empty values are treated the same
+	before := map[string]any{"label": "", "count": int32(0)}
+	after := map[string]any{"other": nil}
+	require.Empty(t, AuditChanges(before, after))
+
+	// Identical snapshots have no changes
+	domain := NewDomain()
+	domain.Data["sso_secret"] = "shh"
+	require.Empty(t, AuditChanges(AuditSnapshot(&domain), AuditSnapshot(&domain)))
+}
+
+func TestAuditChanges_Redacted(t *testing.T) {
+
+	domain := NewDomain()
+	before := AuditSnapshot(&domain)
+
+	domain.Data["oidc_clientSecret"] = "shh"
+	domain.Data["smtp_password"] = "shh"
+	domain.Data["label"] = "Public"
+
+	changes := AuditChanges(before, AuditSnapshot(&domain))
+	require.Len(t, changes, 3)
+
+	for _, change := range changes {
+		if strings.Contains(change.Field, "label") {
+			require.Equal(t, "Public", change.To)
+			continue
+		}
+		require.Equal(t, AuditRedacted, change.To)
+	}
+}
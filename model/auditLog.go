@@ -0,0 +1,166 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/benpate/data/journal"
+	"github.com/benpate/rosetta/sliceof"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLog is a single, append-only record of a security-relevant action on this domain,
+// such as signing in, changing account security, or editing the domain's settings.
+type AuditLog struct {
+	AuditLogID  primitive.ObjectID          `json:"auditLogId"            bson:"_id"`                   // Unique identifier for this record
+	ActorID     primitive.ObjectID          `json:"actorId"               bson:"actorId"`               // User who performed the action (zero if not signed in)
+	ActorName   string                      `json:"actorName"             bson:"actorName"`             // Username of the actor (or the name they attempted to sign in with)
+	RealActorID primitive.ObjectID          `json:"realActorId,omitempty" bson:"realActorId,omitempty"` // Domain owner who performed the action while masquerading as the actor
+	Action      string                      `json:"action"                bson:"action"`                // Type of action performed (see AuditAction* constants)
+	ObjectType  string                      `json:"objectType"            bson:"objectType"`            // Type of object that was affected (e.g. "User", "Domain")
+	ObjectID    primitive.ObjectID          `json:"objectId"              bson:"objectId"`              // ID of the object that was affected
+	Summary     string                      `json:"summary"               bson:"summary"`               // Human-friendly description of the action
+	IPAddress   string                      `json:"ipAddress"             bson:"ipAddress"`             // IP address of the request
+	UserAgent   string                      `json:"userAgent"             bson:"userAgent"`             // User-Agent of the request
+	Changes     sliceof.Object[AuditChange] `json:"changes,omitempty"     bson:"changes,omitempty"`     // Fields that were changed by this action
+
+	journal.Journal `json:"-" bson:",inline"`
+}
+
+// NewAuditLog returns a fully initialized AuditLog record
+func NewAuditLog(action string) AuditLog {
+	return AuditLog{
+		AuditLogID: primitive.NewObjectID(),
+		Action:     action,
+		Changes:    sliceof.NewObject[AuditChange](),
+	}
+}
+
+// AuditLogFields returns the fields that are displayed in the audit log viewer
+func AuditLogFields() []string {
+	return []string{"_id", "actorId", "actorName", "realActorId", "action", "objectType", "objectId", "summary", "ipAddress", "userAgent", "changes", "createDate"}
+}
+
+func (auditLog AuditLog) Fields() []string {
+	return AuditLogFields()
+}
+
+/******************************************
+ * data.Object Interface
+ ******************************************/
+
+// ID returns the primary key of this object
+func (auditLog *AuditLog) ID() string {
+	return auditLog.AuditLogID.Hex()
+}
+
+/******************************************
+ * Other Methods
+ ******************************************/
+
+// IsMasquerade returns TRUE if this action was performed by a domain owner
+// who was signed in as another user.
+func (auditLog AuditLog) IsMasquerade() bool {
+	return !auditLog.RealActorID.IsZero()
+}
+
+// WithObject sets the object that was affected by this action
+func (auditLog AuditLog) WithObject(objectType string, objectID primitive.ObjectID) AuditLog {
+	auditLog.ObjectType = objectType
+	auditLog.ObjectID = objectID
+	return auditLog
+}
+
+// WithSummary sets the human-friendly description of this action
+func (auditLog AuditLog) WithSummary(summary string) AuditLog {
+	auditLog.Summary = summary
+	return auditLog
+}
+
+// WithActor sets the User who performed this action
+func (auditLog AuditLog) WithActor(userID primitive.ObjectID, name string) AuditLog {
+	auditLog.ActorID = userID
+	auditLog.ActorName = name
+	return auditLog
+}
+
+// ExportMap returns a representation of this AuditLog for JSON exports,
+// including the time that it was recorded.
+func (auditLog AuditLog) ExportMap() map[string]any {
+
+	result := map[string]any{
+		"auditLogId": auditLog.AuditLogID.Hex(),
+		"date":       auditLog.Date().Format(time.RFC3339),
+		"action":     auditLog.Action,
+		"actorId":    auditLog.ActorID.Hex(),
+		"actorName":  auditLog.ActorName,
+		"objectType": auditLog.ObjectType,
+		"objectId":   auditLog.ObjectID.Hex(),
+		"summary":    auditLog.Summary,
+		"ipAddress":  auditLog.IPAddress,
+		"userAgent":  auditLog.UserAgent,
+		"changes":    auditLog.Changes,
+	}
+
+	if auditLog.IsMasquerade() {
+		result["realActorId"] = auditLog.RealActorID.Hex()
+	}
+
+	return result
+}
+
+// AuditLogCSVHeader returns the column names used by CSV exports
+func AuditLogCSVHeader() []string {
+	return []string{"date", "action", "actorId", "actorName", "realActorId", "objectType", "objectId", "summary", "ipAddress", "userAgent", "changes"}
+}
+
+// CSVRow returns the values of this AuditLog in the same order as AuditLogCSVHeader.
+// Changes are encoded as a JSON array.  Values that may come from outside visitors
+// are escaped so that spreadsheets do not run them as formulas.
+func (auditLog AuditLog) CSVRow() []string {
+
+	changes := ""
+
+	if len(auditLog.Changes) > 0 {
+		if encoded, err := json.Marshal(auditLog.Changes); err == nil {
+			changes = string(encoded)
+		}
+	}
+
+	realActorID := ""
+
+	if auditLog.IsMasquerade() {
+		realActorID = auditLog.RealActorID.Hex()
+	}
+
+	return []string{
+		auditLog.Date().Format(time.RFC3339),
+		auditLog.Action,
+		auditLog.ActorID.Hex(),
+		csvText(auditLog.ActorName),
+		realActorID,
+		auditLog.ObjectType,
+		auditLog.ObjectID.Hex(),
+		csvText(auditLog.Summary),
+		auditLog.IPAddress,
+		csvText(auditLog.UserAgent),
+		changes,
+	}
+}
+
+// Date returns the time that this action was recorded
+func (auditLog AuditLog) Date() time.Time {
+	return time.UnixMilli(auditLog.CreateDate).UTC()
+}
+
+// csvText prefixes values that spreadsheets would treat as formulas (like "=HYPERLINK(...)")
+// with a single quote, so that they are displayed as plain text instead.
+func csvText(value string) string {
+
+	if (value != "") && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
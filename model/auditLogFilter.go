@@ -0,0 +1,112 @@
+package model
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benpate/exp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLogFilter describes the records to display (or export) from the audit log
+type AuditLogFilter struct {
+	Action    string             // Action, or group of actions (e.g. "signin" includes "signin.failed")
+	ActorID   primitive.ObjectID // User who performed the action (either directly, or by masquerading)
+	ObjectID  primitive.ObjectID // Object that was affected by the action
+	IPAddress string             // IP address of the request
+	Since     int64              // Earliest createDate to include (Unix epoch milliseconds)
+	Until     int64              // Latest createDate to include (Unix epoch milliseconds)
+}
+
+// Criteria returns an expression that matches all AuditLog records for this filter
+func (filter AuditLogFilter) Criteria() exp.Expression {
+
+	var result exp.Expression = exp.All()
+
+	if actions := filter.Actions(); len(actions) > 0 {
+		result = result.AndIn("action", actions)
+	}
+
+	if !filter.ActorID.IsZero() {
+		result = result.And(exp.Equal("actorId", filter.ActorID).OrEqual("realActorId", filter.ActorID))
+	}
+
+	if !filter.ObjectID.IsZero() {
+		result = result.AndEqual("objectId", filter.ObjectID)
+	}
+
+	if filter.IPAddress != "" {
+		result = result.AndEqual("ipAddress", filter.IPAddress)
+	}
+
+	if filter.Since > 0 {
+		result = result.AndGreaterOrEqual("createDate", filter.Since)
+	}
+
+	if filter.Until > 0 {
+		result = result.AndLessOrEqual("createDate", filter.Until)
+	}
+
+	return result
+}
+
+// Actions returns the individual actions that match this filter's Action
+func (filter AuditLogFilter) Actions() []string {
+
+	if filter.Action == "" {
+		return nil
+	}
+
+	result := make([]string, 0)
+
+	for _, action := range AuditActions() {
+		if action == filter.Action || strings.HasPrefix(action, filter.Action+".") {
+			result = append(result, action)
+		}
+	}
+
+	// Unknown actions match nothing, rather than everything
+	if len(result) == 0 {
+		result = append(result, filter.Action)
+	}
+
+	return result
+}
+
+// ParseAuditLogFilter reads a filter from URL query parameters.  Dates use the
+// YYYY-MM-DD format and include the entire day (in UTC).  Actors and objects
+// are identified by their IDs.
+func ParseAuditLogFilter(values url.Values) AuditLogFilter {
+
+	result := AuditLogFilter{
+		Action:    strings.TrimSpace(values.Get("action")),
+		IPAddress: strings.TrimSpace(values.Get("ip")),
+	}
+
+	if actorID, err := primitive.ObjectIDFromHex(values.Get("actorId")); err == nil {
+		result.ActorID = actorID
+	}
+
+	if objectID, err := primitive.ObjectIDFromHex(values.Get("objectId")); err == nil {
+		result.ObjectID = objectID
+	}
+
+	if since, err := time.Parse(time.DateOnly, values.Get("since")); err == nil {
+		result.Since = since.UnixMilli()
+	}
+
+	if until, err := time.Parse(time.DateOnly, values.Get("until")); err == nil {
+		result.Until = until.AddDate(0, 0, 1).UnixMilli() - 1
+	}
+
+	// "before" is a cursor (in Unix milliseconds) used to page through long results
+	if before, err := strconv.ParseInt(values.Get("before"), 10, 64); err == nil && before > 0 {
+		if (result.Until == 0) || (before <= result.Until) {
+			result.Until = before - 1
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogFilter_Actions(t *testing.T) {
+
+	// Groups of actions are matched by prefix
+	require.Equal(t, []string{AuditActionSignIn, AuditActionSignInFailed, AuditActionTwoFactorFailed}, AuditLogFilter{Action: "signin"}.Actions())
+
+	// Individual actions match exactly
+	require.Equal(t, []string{AuditActionPasswordReset}, AuditLogFilter{Action: "password.reset"}.Actions())
+
+	// Unknown actions match nothing
+	require.Equal(t, []string{"unknown"}, AuditLogFilter{Action: "unknown"}.Actions())
+
+	// Empty actions match everything
+	require.Nil(t, AuditLogFilter{}.Actions())
+}
+
+func TestParseAuditLogFilter(t *testing.T) {
+
+	values := url.Values{}
+	values.Set("action", "admin")
+	values.Set("ip", " 10.0.0.1 ")
+	values.Set("since", "2024-03-01")
+	values.Set("until", "2024-03-31")
+	values.Set("actorId", "not-an-id")
+
+	filter := ParseAuditLogFilter(values)
+	require.Equal(t, "admin", filter.Action)
+	require.Equal(t, "10.0.0.1", filter.IPAddress)
+	require.True(t, filter.ActorID.IsZero())
+	require.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).UnixMilli(), filter.Since)
+	require.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC).UnixMilli()-1, filter.Until)
+
+	// Paging cursors narrow the "until" date
+	values.Set("before", "1711000000000")
+	filter = ParseAuditLogFilter(values)
+	require.Equal(t, int64(1711000000000-1), filter.Until)
+}
@@ -0,0 +1,79 @@
+package model
+
+// AuditActionSignIn records a successful sign-in.  The summary describes the credentials used.
+const AuditActionSignIn = "signin"
+
+// AuditActionSignInFailed records a sign-in attempt with an invalid username or password.
+const AuditActionSignInFailed = "signin.failed"
+
+// AuditActionTwoFactorFailed records an invalid second-factor code.
+const AuditActionTwoFactorFailed = "signin.two-factor-failed"
+
+// AuditActionSignOut records a User signing out.
+const AuditActionSignOut = "signout"
+
+// AuditActionMasquerade records a domain owner signing in as another User.
+const AuditActionMasquerade = "masquerade"
+
+// AuditActionPasswordResetRequest records a request for a password reset email.
+const AuditActionPasswordResetRequest = "password.reset-request"
+
+// AuditActionPasswordReset records a password that was changed using a reset code.
+const AuditActionPasswordReset = "password.reset"
+
+// AuditActionPasswordChange records a signed-in User changing their password.
+const AuditActionPasswordChange = "password.change"
+
+// AuditActionSecurityChange records a change to a User's two-factor or external sign-in settings.
+const AuditActionSecurityChange = "security.change"
+
+// AuditActionAdminCreate records a domain owner creating a record through the admin pages.
+const AuditActionAdminCreate = "admin.create"
+
+// AuditActionAdminUpdate records a domain owner changing a record through the admin pages.
+const AuditActionAdminUpdate = "admin.update"
+
+// AuditActionAdminDelete records a domain owner deleting a record through the admin pages.
+const AuditActionAdminDelete = "admin.delete"
+
+// AuditActionOAuthAuthorize records a User granting access to an OAuth application.
+const AuditActionOAuthAuthorize = "oauth.authorize"
+
+// AuditActionOAuthToken records an OAuth access token being issued to an application.
+const AuditActionOAuthToken = "oauth.token"
+
+// AuditActionRuleCreate records a User creating a block or mute rule.
+const AuditActionRuleCreate = "rule.create"
+
+// AuditActionRuleUpdate records a User changing a block or mute rule.
+const AuditActionRuleUpdate = "rule.update"
+
+// AuditActionRuleDelete records a User removing a block or mute rule.
+const AuditActionRuleDelete = "rule.delete"
+
+// AuditActionRateLimit records a client that was throttled by a rate limit.
+const AuditActionRateLimit = "ratelimit.throttled"
+
+// AuditActions returns all of the actions that are recorded in the audit log
+func AuditActions() []string {
+	return []string{
+		AuditActionSignIn,
+		AuditActionSignInFailed,
+		AuditActionTwoFactorFailed,
+		AuditActionSignOut,
+		AuditActionMasquerade,
+		AuditActionPasswordResetRequest,
+		AuditActionPasswordReset,
+		AuditActionPasswordChange,
+		AuditActionSecurityChange,
+		AuditActionAdminCreate,
+		AuditActionAdminUpdate,
+		AuditActionAdminDelete,
+		AuditActionOAuthAuthorize,
+		AuditActionOAuthToken,
+		AuditActionRuleCreate,
+		AuditActionRuleUpdate,
+		AuditActionRuleDelete,
+		AuditActionRateLimit,
+	}
+}
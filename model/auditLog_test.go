@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAuditLog_CSVRow(t *testing.T) {
+
+	auditLog := NewAuditLog(AuditActionSignInFailed).
+		WithActor(primitive.NilObjectID, "=HYPERLINK(\"https://evil.example\")").
+		WithSummary("@SUM(A1:A9)")
+
+	auditLog.UserAgent = "-2+3"
+	auditLog.IPAddress = "192.0.2.1"
+
+	row := auditLog.CSVRow()
+	header := AuditLogCSVHeader()
+	require.Len(t, row, len(header))
+
+	// Values that spreadsheets would run as formulas are escaped
+	require.Equal(t, `'=HYPERLINK("https://evil.example")`, row[3])
+	require.Equal(t, "'@SUM(A1:A9)", row[7])
+	require.Equal(t, "192.0.2.1", row[8])
+	require.Equal(t, "'-2+3", row[9])
+
+	// Other values are unchanged
+	require.Equal(t, "alice", csvText("alice"))
+	require.Equal(t, "", csvText(""))
+	require.Equal(t, "'\tTAB", csvText("\tTAB"))
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"time"
+
+	"github.com/benpate/data/journal"
+	"github.com/benpate/rosetta/sliceof"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Blocklist is a remote list of blocked actors and domains that a Domain administrator
+// subscribes to.  Blocklists are synchronized on a schedule, and each entry becomes a
+// domain-wide Rule.
+type Blocklist struct {
+	BlocklistID   primitive.ObjectID             `json:"blocklistId"   bson:"_id"`           // Unique identifier of this Blocklist
+	Label         string                         `json:"label"         bson:"label"`         // Human-friendly name of this Blocklist
+	Format        string                         `json:"format"        bson:"format"`        // Format of the remote Blocklist (CSV, ACTIVITYPUB)
+	URL           string                         `json:"url"           bson:"url"`           // URL of the CSV file or ActivityPub Actor to import
+	Action        string                         `json:"action"        bson:"action"`        // Default action for imported Rules (BLOCK, MUTE, LABEL)
+	AutoApply     bool                           `json:"autoApply"     bson:"autoApply"`     // If TRUE, changes are applied on every sync.  Otherwise, they wait for an administrator to review them.
+	PollDuration  int                            `json:"pollDuration"  bson:"pollDuration"`  // Number of hours to wait between syncs
+	Status        string                         `json:"status"        bson:"status"`        // Status of the most recent sync (NEW, PENDING, SUCCESS, FAILURE)
+	StatusMessage string                         `json:"statusMessage" bson:"statusMessage"` // Human-friendly description of the most recent sync
+	LastSynced    int64                          `json:"lastSynced"    bson:"lastSynced"`    // Unix timestamp of the most recent sync
+	NextSync      int64                          `json:"nextSync"      bson:"nextSync"`      // Unix timestamp of the next scheduled sync
+	Pending       sliceof.Object[BlocklistEntry] `json:"pending"       bson:"pending"`       // Changes found in the most recent sync that have not been applied yet
+
+	journal.Journal `json:"-" bson:",inline"`
+}
+
+// NewBlocklist returns a fully initialized Blocklist object
+func NewBlocklist() Blocklist {
+	return Blocklist{
+		BlocklistID:  primitive.NewObjectID(),
+		Format:       BlocklistFormatCSV,
+		Action:       RuleActionBlock,
+		PollDuration: 24,
+		Status:       BlocklistStatusNew,
+		Pending:      sliceof.NewObject[BlocklistEntry](),
+	}
+}
+
+func BlocklistFields() []string {
+	return []string{"_id", "label", "format", "url", "action", "autoApply", "status", "statusMessage", "lastSynced", "nextSync"}
+}
+
+func (blocklist Blocklist) Fields() []string {
+	return BlocklistFields()
+}
+
+/******************************************
+ * data.Object Interface
+ ******************************************/
+
+// ID returns the unique identifier for this Blocklist, and is required to implement the data.Object interface
+func (blocklist Blocklist) ID() string {
+	return blocklist.BlocklistID.Hex()
+}
+
+/******************************************
+ * Other Methods
+ ******************************************/
+
+// HasPending returns TRUE if this Blocklist has changes that are waiting to be applied
+func (blocklist Blocklist) HasPending() bool {
+	return len(blocklist.Pending) > 0
+}
+
+// PendingAdditions returns all pending entries that will add new Rules
+func (blocklist Blocklist) PendingAdditions() sliceof.Object[BlocklistEntry] {
+	return blocklist.pendingByOperation(BlocklistEntryOperationAdd)
+}
+
+// PendingRemovals returns all pending entries that will remove existing Rules
+func (blocklist Blocklist) PendingRemovals() sliceof.Object[BlocklistEntry] {
+	return blocklist.pendingByOperation(BlocklistEntryOperationRemove)
+}
+
+// MarkSynced updates the sync timestamps and status of this Blocklist
+func (blocklist *Blocklist) MarkSynced(status string, message string) {
+	now := time.Now()
+	blocklist.Status = status
+	blocklist.StatusMessage = message
+	blocklist.LastSynced = now.Unix()
+	blocklist.NextSync = now.Add(time.Duration(max(blocklist.PollDuration, 1)) * time.Hour).Unix()
+}
+
+func (blocklist Blocklist) pendingByOperation(operation string) sliceof.Object[BlocklistEntry] {
+
+	result := sliceof.NewObject[BlocklistEntry]()
+
+	for _, entry := range blocklist.Pending {
+		if entry.Operation == operation {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,34 @@
+package model
+
+// BlocklistEntryOperationAdd identifies a BlocklistEntry that will add a new Rule
+const BlocklistEntryOperationAdd = "ADD"
+
+// BlocklistEntryOperationRemove identifies a BlocklistEntry that will remove an existing Rule
+const BlocklistEntryOperationRemove = "REMOVE"
+
+// BlocklistEntry is a single record in a remote Blocklist, which is converted into a domain-wide Rule.
+type BlocklistEntry struct {
+	Operation string `json:"operation" bson:"operation"` // Change to make when this entry is applied (ADD, REMOVE)
+	Type      string `json:"type"      bson:"type"`      // Type of Rule to create (ACTOR, DOMAIN, CONTENT)
+	Action    string `json:"action"    bson:"action"`    // Action to take when the Rule is triggered (BLOCK, MUTE, LABEL)
+	Trigger   string `json:"trigger"   bson:"trigger"`   // Actor, domain, or content that triggers the Rule
+	Summary   string `json:"summary"   bson:"summary"`   // Optional comment describing why this entry exists
+}
+
+// Key returns a value that uniquely identifies the Rule created by this entry
+func (entry BlocklistEntry) Key() string {
+	return entry.Type + ":" + entry.Trigger
+}
+
+// Rule returns a new domain-wide Rule that is populated from this entry
+func (entry BlocklistEntry) Rule(blocklist *Blocklist) Rule {
+	result := NewRule()
+	result.BlocklistID = blocklist.BlocklistID
+	result.BlocklistLabel = blocklist.Label
+	result.Type = entry.Type
+	result.Action = entry.Action
+	result.Trigger = entry.Trigger
+	result.Summary = entry.Summary
+	result.Label = "Blocked by " + blocklist.Label
+	return result
+}
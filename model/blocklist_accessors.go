@@ -0,0 +1,92 @@
+package model
+
+import (
+	"github.com/benpate/rosetta/null"
+	"github.com/benpate/rosetta/schema"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func BlocklistSchema() schema.Element {
+	return schema.Object{
+		Properties: schema.ElementMap{
+			"blocklistId":   schema.String{Format: "objectId"},
+			"label":         schema.String{MaxLength: 128},
+			"format":        schema.String{Enum: []string{BlocklistFormatCSV, BlocklistFormatActivityPub}, Required: true},
+			"url":           schema.String{Format: "url", MaxLength: 1024, Required: true},
+			"action":        schema.String{Enum: []string{RuleActionBlock, RuleActionMute, RuleActionLabel}, Required: true},
+			"autoApply":     schema.Boolean{},
+			"pollDuration":  schema.Integer{Minimum: null.NewInt64(1), Maximum: null.NewInt64(24 * 30)},
+			"status":        schema.String{Enum: []string{BlocklistStatusNew, BlocklistStatusPending, BlocklistStatusSuccess, BlocklistStatusFailure}},
+			"statusMessage": schema.String{},
+			"lastSynced":    schema.Integer{BitSize: 64},
+			"nextSync":      schema.Integer{BitSize: 64},
+		},
+	}
+}
+
+/******************************************
+ * Getter/Setter Interfaces
+ ******************************************/
+
+func (blocklist *Blocklist) GetPointer(name string) (any, bool) {
+
+	switch name {
+
+	case "label":
+		return &blocklist.Label, true
+
+	case "format":
+		return &blocklist.Format, true
+
+	case "url":
+		return &blocklist.URL, true
+
+	case "action":
+		return &blocklist.Action, true
+
+	case "autoApply":
+		return &blocklist.AutoApply, true
+
+	case "pollDuration":
+		return &blocklist.PollDuration, true
+
+	case "status":
+		return &blocklist.Status, true
+
+	case "statusMessage":
+		return &blocklist.StatusMessage, true
+
+	case "lastSynced":
+		return &blocklist.LastSynced, true
+
+	case "nextSync":
+		return &blocklist.NextSync, true
+	}
+
+	return nil, false
+}
+
+func (blocklist Blocklist) GetStringOK(name string) (string, bool) {
+
+	switch name {
+
+	case "blocklistId":
+		return blocklist.BlocklistID.Hex(), true
+	}
+
+	return "", false
+}
+
+func (blocklist *Blocklist) SetString(name string, value string) bool {
+
+	switch name {
+
+	case "blocklistId":
+		if objectID, err := primitive.ObjectIDFromHex(value); err == nil {
+			blocklist.BlocklistID = objectID
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,19 @@
+package model
+
+// BlocklistFormatCSV identifies a Blocklist published as a CSV file, in the format used by Mastodon and Oliphant
+const BlocklistFormatCSV = "CSV"
+
+// BlocklistFormatActivityPub identifies a Blocklist published as the "blocked" collection of an ActivityPub Actor
+const BlocklistFormatActivityPub = "ACTIVITYPUB"
+
+// BlocklistStatusNew identifies a Blocklist that has not been synchronized yet
+const BlocklistStatusNew = "NEW"
+
+// BlocklistStatusPending identifies a Blocklist whose most recent changes are waiting to be reviewed by an administrator
+const BlocklistStatusPending = "PENDING"
+
+// BlocklistStatusSuccess identifies a Blocklist that has been synchronized successfully
+const BlocklistStatusSuccess = "SUCCESS"
+
+// BlocklistStatusFailure identifies a Blocklist that could not be synchronized
+const BlocklistStatusFailure = "FAILURE"
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/benpate/rosetta/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocklistSchema(t *testing.T) {
+
+	s := schema.New(BlocklistSchema())
+	blocklist := NewBlocklist()
+
+	tests := []tableTestItem{
+		{"blocklistId", "000000000000000000000001", nil},
+		{"label", "Oliphant Tier 0", nil},
+		{"format", "CSV", nil},
+		{"url", "https://codeberg.org/oliphant/blocklists/raw/branch/main/blocklists/_unified_tier0_blocklist.csv", nil},
+		{"action", "BLOCK", nil},
+		{"autoApply", true, nil},
+		{"pollDuration", 24, nil},
+		{"status", "SUCCESS", nil},
+		{"statusMessage", "Synchronized", nil},
+		{"lastSynced", int64(1234567890), nil},
+		{"nextSync", int64(1234567891), nil},
+	}
+
+	tableTest_Schema(t, &s, &blocklist, tests)
+}
+
+func TestBlocklist_Pending(t *testing.T) {
+
+	blocklist := NewBlocklist()
+	require.False(t, blocklist.HasPending())
+
+	blocklist.Pending = append(blocklist.Pending,
+		BlocklistEntry{Operation: BlocklistEntryOperationAdd, Type: RuleTypeDomain, Trigger: "bad.example"},
+		BlocklistEntry{Operation: BlocklistEntryOperationRemove, Type: RuleTypeDomain, Trigger: "good.example"},
+		BlocklistEntry{Operation: BlocklistEntryOperationAdd, Type: RuleTypeActor, Trigger: "https://other.example/@troll"},
+	)
+
+	require.True(t, blocklist.HasPending())
+	require.Equal(t, 2, len(blocklist.PendingAdditions()))
+	require.Equal(t, 1, len(blocklist.PendingRemovals()))
+	require.Equal(t, "good.example", blocklist.PendingRemovals()[0].Trigger)
+}
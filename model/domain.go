@@ -1,6 +1,11 @@
 package model
 
 import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/EmissarySocial/emissary/tools/id"
 	"github.com/benpate/data/journal"
 	domainlib "github.com/benpate/domain"
 	"github.com/benpate/form"
@@ -28,15 +33,28 @@ type Domain struct {
 	DatabaseVersion  uint                            `bson:"databaseVersion"`  // Version of the database schema
 	Syndication      sliceof.Object[form.LookupCode] `bson:"syndication"`      // List of external services that this domain can syndicate to
 	PrivateKey       string                          `bson:"privateKey"`       // Private key for this domain
+	AuthorizedFetch  bool                            `bson:"authorizedFetch"`  // If TRUE, then ActivityPub GET requests must be signed by a remote Actor ("secure mode")
+	FederatedSearch  bool                            `bson:"federatedSearch"`  // If TRUE, then public posts and actors received via ActivityPub are added to the search index
+	SearchRetention  int                             `bson:"searchRetention"`  // Number of days to keep remote search results in the index (zero uses the default)
+	SearchRelays     sliceof.String                  `bson:"searchRelays"`     // Inbox URLs of ActivityPub relays that the service actor subscribes to
+	StorageUsed      int64                           `bson:"storageUsed"`      // Number of bytes used by all attachments on this domain (updated by the Attachment service)
+	WebSubHubs       string                          `bson:"webSubHubs"`       // URLs of external WebSub hubs (one per line) that are pinged whenever a feed on this domain changes
+	OutboundHosts    string                          `bson:"outboundHosts"`    // Hostnames (one per line) that the "call-url" step is allowed to connect to
+	Secrets          mapof.String                    `bson:"secrets"`          // Write-only values (like API keys) that the "call-url" step can send.  These are not in the schema, so templates can never read them.
+	OIDCClientSecret string                          `bson:"oidcClientSecret"` // Write-only OAuth client secret for the OpenID Connect provider.  This is not in the schema, so templates can never read it.
+	TwoFactorOwners  bool                            `bson:"twoFactorOwners"`  // If TRUE, then domain owners must use two-factor authentication to sign in
+	TwoFactorGroups  id.Slice                        `bson:"twoFactorGroups"`  // Members of these groups must use two-factor authentication to sign in
 	journal.Journal  `json:"-" bson:",inline"`
 }
 
 // NewDomain returns a fully initialized Domain object
 func NewDomain() Domain {
 	return Domain{
-		ThemeData: mapof.NewAny(),
-		ColorMode: DomainColorModeAuto,
-		Data:      mapof.NewString(),
+		ThemeData:    mapof.NewAny(),
+		ColorMode:    DomainColorModeAuto,
+		Data:         mapof.NewString(),
+		SearchRelays: sliceof.NewString(),
+		Secrets:      mapof.NewString(),
 	}
 }
 
@@ -79,3 +97,55 @@ func (domain *Domain) IconURL() string {
 
 	return domain.Host() + "/.domain/attachments/" + domain.IconID.Hex()
 }
+
+// RequiresTwoFactor returns TRUE if the domain's security policy requires
+// the provided User to sign in with a second factor.
+func (domain Domain) RequiresTwoFactor(user *User) bool {
+
+	if domain.TwoFactorOwners && user.IsOwner {
+		return true
+	}
+
+	for _, groupID := range domain.TwoFactorGroups {
+		if slices.Contains(user.GroupIDs, groupID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OutboundHostnames returns a parsed slice of hostnames from the "OutboundHosts" field.
+func (domain Domain) OutboundHostnames() sliceof.String {
+
+	result := sliceof.NewString()
+
+	for _, line := range strings.Split(domain.OutboundHosts, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
+
+// SecretNames returns the sorted names of all secrets in this domain, without their values
+func (domain Domain) SecretNames() sliceof.String {
+	result := sliceof.String(slices.Collect(maps.Keys(domain.Secrets)))
+	slices.Sort(result)
+	return result
+}
+
+// WebSubHubURLs returns a parsed slice of URLs from the "WebSubHubs" field.
+func (domain Domain) WebSubHubURLs() sliceof.String {
+
+	result := sliceof.NewString()
+
+	for _, line := range strings.Split(domain.WebSubHubs, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
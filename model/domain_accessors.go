@@ -1,7 +1,9 @@
 package model
 
 import (
+	"github.com/EmissarySocial/emissary/tools/id"
 	"github.com/benpate/form"
+	"github.com/benpate/rosetta/null"
 	"github.com/benpate/rosetta/schema"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -23,6 +25,14 @@ func DomainSchema() schema.Element {
 			"colorMode":        schema.String{Enum: []string{DomainColorModeAuto, DomainColorModeLight, DomainColorModeDark}},
 			"syndication":      schema.Array{Items: form.LookupCodeSchema()},
 			"registrationData": schema.Object{Wildcard: schema.String{}},
+			"authorizedFetch":  schema.Boolean{},
+			"federatedSearch":  schema.Boolean{},
+			"searchRetention":  schema.Integer{Minimum: null.NewInt64(0), Maximum: null.NewInt64(3650)},
+			"searchRelays":     schema.Array{Items: schema.String{Format: "url"}},
+			"webSubHubs":       schema.String{MaxLength: 4096},
+			"outboundHosts":    schema.String{MaxLength: 4096},
+			"twoFactorOwners":  schema.Boolean{},
+			"twoFactorGroups":  id.SliceSchema(),
 		},
 	}
 }
@@ -67,6 +77,30 @@ func (domain *Domain) GetPointer(name string) (any, bool) {
 
 	case "syndication":
 		return &domain.Syndication, true
+
+	case "authorizedFetch":
+		return &domain.AuthorizedFetch, true
+
+	case "federatedSearch":
+		return &domain.FederatedSearch, true
+
+	case "searchRetention":
+		return &domain.SearchRetention, true
+
+	case "searchRelays":
+		return &domain.SearchRelays, true
+
+	case "webSubHubs":
+		return &domain.WebSubHubs, true
+
+	case "outboundHosts":
+		return &domain.OutboundHosts, true
+
+	case "twoFactorOwners":
+		return &domain.TwoFactorOwners, true
+
+	case "twoFactorGroups":
+		return &domain.TwoFactorGroups, true
 	}
 
 	return nil, false
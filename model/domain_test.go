@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/benpate/rosetta/schema"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDomainSchema(t *testing.T) {
@@ -35,7 +36,47 @@ func TestDomainSchema(t *testing.T) {
 		{"syndication.0.label", "LABEL", nil},
 		{"syndication.1.description", "DESCRIPTION", nil},
 		{"syndication.1.href", "https://syndication.site", nil},
+		{"authorizedFetch", true, nil},
+		{"federatedSearch", true, nil},
+		{"searchRetention", 30, nil},
+		{"searchRelays.0", "https://relay.example/inbox", nil},
+		{"webSubHubs", "https://pubsubhubbub.appspot.com", nil},
+		{"outboundHosts", "api.example.com", nil},
+		{"twoFactorOwners", true, nil},
+		{"twoFactorGroups.0", "123456781234567812345678", nil},
 	}
 
 	tableTest_Schema(t, &s, &domain, table)
 }
+
+func TestDomain_WebSubHubURLs(t *testing.T) {
+
+	domain := NewDomain()
+	require.Empty(t, domain.WebSubHubURLs())
+
+	domain.WebSubHubs = "https://pubsubhubbub.appspot.com\r\n\n  https://websub.example/hub  \n"
+	require.Equal(t, []string{"https://pubsubhubbub.appspot.com", "https://websub.example/hub"}, []string(domain.WebSubHubURLs()))
+}
+
+func TestDomain_OutboundHostnames(t *testing.T) {
+
+	domain := NewDomain()
+	require.Empty(t, domain.OutboundHostnames())
+
+	domain.OutboundHosts = "api.example.com\r\n\n  *.hooks.example  \n"
+	require.Equal(t, []string{"api.example.com", "*.hooks.example"}, []string(domain.OutboundHostnames()))
+}
+
+func TestDomainSecrets(t *testing.T) {
+
+	domain := NewDomain()
+	domain.Secrets["zeta"] = "Z"
+	domain.Secrets["alpha"] = "A"
+
+	// Secret names are listed in order, but their values are not in the schema
+	require.Equal(t, []string{"alpha", "zeta"}, []string(domain.SecretNames()))
+
+	s := schema.New(DomainSchema())
+	_, err := s.Get(&domain, "secrets.alpha")
+	require.NotNil(t, err)
+}
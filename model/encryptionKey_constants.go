@@ -5,3 +5,6 @@ const EncryptionKeyTypeUser = "User"
 
 // EncryptionKeyTypeStream identifies an EncryptionKey that is owned by a Stream/Actor
 const EncryptionKeyTypeStream = "Stream"
+
+// EncryptionKeyTypeSearch identifies an EncryptionKey that is owned by a SavedSearch/Actor
+const EncryptionKeyTypeSearch = "Search"
@@ -84,8 +84,13 @@ func (follower Follower) GetJSONLD() mapof.Any {
 // ParentURL returns the URL of the parent object that this Follower is following.
 func (follower Follower) ParentURL(host string) string {
 
-	if follower.ParentType == FollowerTypeUser {
+	switch follower.ParentType {
+
+	case FollowerTypeUser:
 		return host + "/@" + follower.ParentID.Hex()
+
+	case FollowerTypeSearch:
+		return host + "/.searches/" + follower.ParentID.Hex()
 	}
 
 	return host + "/" + follower.ParentID.Hex()
@@ -11,6 +11,8 @@ type Group struct {
 	Token   string             `json:"token"   bson:"token"` // Uniqe token chosen by the administrator
 	Label   string             `json:"label"   bson:"label"` // Human-readable label for this group.
 
+	StorageQuota int64 `json:"storageQuota" bson:"storageQuota"` // Maximum storage (in megabytes) for each member of this group.  Zero means no group limit.
+
 	journal.Journal `json:"-" bson:",inline"`
 }
 
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"github.com/benpate/rosetta/null"
 	"github.com/benpate/rosetta/schema"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -11,6 +12,8 @@ func GroupSchema() schema.Element {
 			"groupId": schema.String{Format: "objectId"},
 			"token":   schema.String{MaxLength: 64},
 			"label":   schema.String{MaxLength: 64, Required: true},
+
+			"storageQuota": schema.Integer{BitSize: 64, Minimum: null.NewInt64(0)},
 		},
 	}
 }
@@ -36,6 +39,17 @@ func (group *Group) GetStringOK(name string) (string, bool) {
 	return "", false
 }
 
+func (group *Group) GetInt64OK(name string) (int64, bool) {
+
+	switch name {
+
+	case "storageQuota":
+		return group.StorageQuota, true
+	}
+
+	return 0, false
+}
+
 /******************************************
  * Setter Interfaces
  ******************************************/
@@ -61,3 +75,15 @@ func (group *Group) SetString(name string, value string) bool {
 
 	return false
 }
+
+func (group *Group) SetInt64(name string, value int64) bool {
+
+	switch name {
+
+	case "storageQuota":
+		group.StorageQuota = value
+		return true
+	}
+
+	return false
+}
@@ -15,6 +15,7 @@ func TestGroupSchema(t *testing.T) {
 		{"groupId", "5e5e5e5e5e5e5e5e5e5e5e5e", nil},
 		{"token", "professional", nil},
 		{"label", "LABEL", nil},
+		{"storageQuota", "500", int64(500)},
 	}
 
 	tableTest_Schema(t, &s, &group, table)
@@ -14,6 +14,7 @@ type PersonLink struct {
 	Username     string             `json:"username,omitempty"     bson:"username,omitempty"`     // Username of the person (e.g. @user@domain.social)
 	ProfileURL   string             `json:"profileUrl,omitempty"   bson:"profileUrl,omitempty"`   // URL of the person's profile
 	InboxURL     string             `json:"inboxUrl,omitempty"     bson:"inboxUrl,omitempty"`     // URL of the person's inbox
+	SharedInbox  string             `json:"sharedInbox,omitempty"  bson:"sharedInbox,omitempty"`  // URL of the shared inbox for the person's server (if available)
 	EmailAddress string             `json:"emailAddress,omitempty" bson:"emailAddress,omitempty"` // Email address of the person
 	IconURL      string             `json:"iconUrl,omitempty"      bson:"iconUrl,omitempty"`      // URL of the person's avatar/icon image
 }
@@ -88,6 +89,17 @@ func (person PersonLink) GetURL(name string) *url.URL {
 	return result
 }
 
+// DeliveryInbox returns the best inbox URL to use when delivering
+// messages to this person: the server's shared inbox, if one is known,
+// or the person's own inbox otherwise.
+func (person PersonLink) DeliveryInbox() string {
+	if person.SharedInbox != "" {
+		return person.SharedInbox
+	}
+
+	return person.InboxURL
+}
+
 // PersonLinkProfileURL is a convenience function that
 // returns the profile URL for a PersonLink
 func PersonLinkProfileURL(person PersonLink) string {
@@ -106,6 +118,7 @@ func (person PersonLink) MarshalMap() mapof.Any {
 		"username":     person.Username,
 		"profileUrl":   person.ProfileURL,
 		"inboxUrl":     person.InboxURL,
+		"sharedInbox":  person.SharedInbox,
 		"emailAddress": person.EmailAddress,
 		"iconUrl":      person.IconURL,
 	}
@@ -118,6 +131,7 @@ func (person *PersonLink) UnmarshalMap(data mapof.Any) {
 	person.Username = data.GetString("username")
 	person.ProfileURL = data.GetString("profileUrl")
 	person.InboxURL = data.GetString("inboxUrl")
+	person.SharedInbox = data.GetString("sharedInbox")
 	person.EmailAddress = data.GetString("emailAddress")
 	person.IconURL = data.GetString("iconUrl")
 }
@@ -14,6 +14,7 @@ func PersonLinkSchema() schema.Element {
 			"username":     schema.String{MaxLength: 128},
 			"profileUrl":   schema.String{Format: "url", MaxLength: 1024},
 			"inboxUrl":     schema.String{Format: "url", MaxLength: 1024},
+			"sharedInbox":  schema.String{Format: "url", MaxLength: 1024},
 			"iconUrl":      schema.String{Format: "url", MaxLength: 1024},
 			"emailAddress": schema.String{Format: "email", MaxLength: 128},
 		},
@@ -39,6 +40,9 @@ func (link *PersonLink) GetPointer(name string) (any, bool) {
 	case "inboxUrl":
 		return &link.InboxURL, true
 
+	case "sharedInbox":
+		return &link.SharedInbox, true
+
 	case "emailAddress":
 		return &link.EmailAddress, true
 
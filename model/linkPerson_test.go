@@ -17,6 +17,7 @@ func TestPersonLink(t *testing.T) {
 		{"username", "@john@connor.social", nil},
 		{"profileUrl", "https://john.connor.mil", nil},
 		{"inboxUrl", "https://john.connor.mil/inbox", nil},
+		{"sharedInbox", "https://connor.mil/inbox", nil},
 		{"emailAddress", "john.connor@mil", nil},
 		{"iconUrl", "https://john.connor.mil/image", nil},
 	}
@@ -1,19 +1,30 @@
 package model
 
 import (
+	"time"
+
 	"github.com/benpate/data/journal"
+	"github.com/benpate/hannibal"
+	"github.com/benpate/hannibal/vocab"
+	"github.com/benpate/rosetta/mapof"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Mention represents a single hyperlink from an external source to an internal object.
 // Mentions are created by WebMentions or by ActivityPub "Mention" records
 type Mention struct {
-	MentionID primitive.ObjectID `json:"mentionId" bson:"_id"`      // Unique ID for this record
-	ObjectID  primitive.ObjectID `json:"objectId"  bson:"objectId"` // Unique ID of the internal object that was mentioned
-	Type      string             `json:"type"      bson:"type"`     // Type of object that was mentioned (Stream, User)
-	StateID   string             `json:"stateId"   bson:"stateId"`  // State of this mention (Validated, Pending, Invalid)
-	Origin    OriginLink         `json:"origin"    bson:"origin"`   // Origin information of the site that mentions this object
-	Author    PersonLink         `json:"author"    bson:"author"`   // Author information of the person who mentioned this object
+	MentionID   primitive.ObjectID `json:"mentionId" bson:"_id"`                  // Unique ID for this record
+	ObjectID    primitive.ObjectID `json:"objectId"  bson:"objectId"`             // Unique ID of the internal object that was mentioned
+	UserID      primitive.ObjectID `json:"userId"    bson:"userId"`               // Unique ID of the User who owns the mentioned object (and moderates this Mention)
+	Type        string             `json:"type"      bson:"type"`                 // Type of object that was mentioned (Stream, User)
+	StateID     string             `json:"stateId"     bson:"stateId"`            // State of this mention (Validated, Pending, Invalid)
+	Kind        string             `json:"kind"        bson:"kind"`               // How the source relates to the mentioned object (Mention, Reply, Like, Repost, Bookmark, RSVP)
+	RSVP        string             `json:"rsvp"        bson:"rsvp,omitempty"`     // RSVP value (yes, no, maybe, interested) for RSVP mentions
+	Content     string             `json:"content"     bson:"content,omitempty"`  // Sanitized HTML content of the source
+	PublishDate int64              `json:"publishDate" bson:"publishDate"`        // Unix timestamp (in seconds) when the source was published
+	VouchURL    string             `json:"vouchUrl"    bson:"vouchUrl,omitempty"` // Optional Vouch URL sent with the WebMention
+	Origin      OriginLink         `json:"origin"      bson:"origin"`             // Origin information of the site that mentions this object
+	Author      PersonLink         `json:"author"      bson:"author"`             // Author information of the person who mentioned this object
 
 	journal.Journal `json:"-" bson:",inline"`
 }
@@ -22,6 +33,8 @@ type Mention struct {
 func NewMention() Mention {
 	return Mention{
 		MentionID: primitive.NewObjectID(),
+		StateID:   MentionStatusPending,
+		Kind:      MentionKindMention,
 		Origin:    NewOriginLink(),
 		Author:    NewPersonLink(),
 	}
@@ -36,3 +49,120 @@ func NewMention() Mention {
 func (mention Mention) ID() string {
 	return mention.MentionID.Hex()
 }
+
+/******************************************
+ * RoleStateEnumerator Interface
+ ******************************************/
+
+// State returns the current state of this Mention.
+// It is part of the RoleStateEnumerator interface
+func (mention Mention) State() string {
+	return mention.StateID
+}
+
+// Roles returns a list of all roles that match the provided authorization.
+// Mentions are moderated by the User who owns the mentioned object, so this
+// function only returns MagicRoleMyself if applicable.
+func (mention Mention) Roles(authorization *Authorization) []string {
+
+	if authorization.UserID == mention.UserID {
+		return []string{MagicRoleMyself}
+	}
+
+	// Intentionally NOT allowing MagicRoleAnonymous, MagicRoleAuthenticated, or MagicRoleOwner
+	return []string{}
+}
+
+/******************************************
+ * JSONLDGetter Interface
+ ******************************************/
+
+// GetJSONLD returns an ActivityStreams representation of this Mention,
+// so that WebMention replies can be displayed alongside ActivityPub replies.
+func (mention Mention) GetJSONLD() mapof.Any {
+
+	result := mapof.Any{
+		vocab.AtContext:         vocab.ContextTypeActivityStreams,
+		vocab.PropertyID:        mention.Origin.URL,
+		vocab.PropertyType:      vocab.ObjectTypeNote,
+		vocab.PropertyURL:       mention.Origin.URL,
+		vocab.PropertyPublished: hannibal.TimeFormat(time.Unix(mention.Published(), 0)),
+	}
+
+	if mention.Content != "" {
+		result[vocab.PropertyContent] = mention.Content
+	}
+
+	if mention.Author.NotEmpty() {
+		result[vocab.PropertyAttributedTo] = mapof.Any{
+			vocab.PropertyType: vocab.ActorTypePerson,
+			vocab.PropertyID:   mention.Author.ProfileURL,
+			vocab.PropertyURL:  mention.Author.ProfileURL,
+			vocab.PropertyName: mention.Author.Name,
+			vocab.PropertyIcon: mention.Author.IconURL,
+		}
+	}
+
+	return result
+}
+
+// Created returns the date that this Mention was received
+func (mention Mention) Created() int64 {
+	return mention.CreateDate
+}
+
+/******************************************
+ * Other Methods
+ ******************************************/
+
+// Published returns the Unix timestamp (in seconds) when the source was published,
+// or the date that the Mention was received if the source does not include one.
+func (mention Mention) Published() int64 {
+
+	if mention.PublishDate > 0 {
+		return mention.PublishDate
+	}
+
+	return mention.CreateDate / 1000
+}
+
+// IsReply returns TRUE if the source is a reply (or RSVP) to the mentioned object
+func (mention Mention) IsReply() bool {
+	return (mention.Kind == MentionKindReply) || (mention.Kind == MentionKindRSVP)
+}
+
+// ResponseType returns the ActivityStreams type of Response that this Mention
+// represents, or an empty string if it is not a Like or Repost.
+func (mention Mention) ResponseType() string {
+
+	switch mention.Kind {
+
+	case MentionKindLike:
+		return vocab.ActivityTypeLike
+
+	case MentionKindRepost:
+		return vocab.ActivityTypeAnnounce
+	}
+
+	return ""
+}
+
+// IsPending returns TRUE if this Mention is waiting for approval
+func (mention Mention) IsPending() bool {
+	return mention.StateID == MentionStatusPending
+}
+
+// IsValidated returns TRUE if this Mention has been approved for public display
+func (mention Mention) IsValidated() bool {
+	return mention.StateID == MentionStatusValidated
+}
+
+// DisplayEquals returns TRUE if both Mentions are displayed identically on the mentioned object
+func (mention Mention) DisplayEquals(other Mention) bool {
+	return (mention.Kind == other.Kind) &&
+		(mention.RSVP == other.RSVP) &&
+		(mention.Content == other.Content) &&
+		(mention.PublishDate == other.PublishDate) &&
+		(mention.Origin == other.Origin) &&
+		(mention.Author == other.Author)
+}
@@ -8,12 +8,18 @@ import (
 func MentionSchema() schema.Element {
 	return schema.Object{
 		Properties: schema.ElementMap{
-			"mentionId": schema.String{Format: "objectId"},
-			"objectId":  schema.String{Format: "objectId"},
-			"type":      schema.String{Enum: []string{MentionTypeStream, MentionTypeUser}},
-			"stateId":   schema.String{Enum: []string{MentionStatusValidated, MentionStatusPending, MentionStatusInvalid}},
-			"origin":    OriginLinkSchema(),
-			"author":    PersonLinkSchema(),
+			"mentionId":   schema.String{Format: "objectId"},
+			"objectId":    schema.String{Format: "objectId"},
+			"userId":      schema.String{Format: "objectId"},
+			"type":        schema.String{Enum: []string{MentionTypeStream, MentionTypeUser}},
+			"stateId":     schema.String{Enum: []string{MentionStatusValidated, MentionStatusPending, MentionStatusInvalid}},
+			"kind":        schema.String{Enum: []string{MentionKindMention, MentionKindReply, MentionKindLike, MentionKindRepost, MentionKindBookmark, MentionKindRSVP}},
+			"rsvp":        schema.String{Enum: []string{"", "yes", "no", "maybe", "interested"}},
+			"content":     schema.String{Format: "html"},
+			"publishDate": schema.Integer{BitSize: 64},
+			"vouchUrl":    schema.String{Format: "url"},
+			"origin":      OriginLinkSchema(),
+			"author":      PersonLinkSchema(),
 		},
 	}
 }
@@ -37,6 +43,21 @@ func (mention *Mention) GetPointer(name string) (any, bool) {
 
 	case "stateId":
 		return &mention.StateID, true
+
+	case "kind":
+		return &mention.Kind, true
+
+	case "rsvp":
+		return &mention.RSVP, true
+
+	case "content":
+		return &mention.Content, true
+
+	case "publishDate":
+		return &mention.PublishDate, true
+
+	case "vouchUrl":
+		return &mention.VouchURL, true
 	}
 
 	return nil, false
@@ -51,6 +72,8 @@ func (mention *Mention) GetStringOK(name string) (string, bool) {
 	case "objectId":
 		return mention.ObjectID.Hex(), true
 
+	case "userId":
+		return mention.UserID.Hex(), true
 	}
 
 	return "", false
@@ -70,6 +93,12 @@ func (mention *Mention) SetString(name string, value string) bool {
 			mention.ObjectID = objectID
 			return true
 		}
+
+	case "userId":
+		if userID, err := primitive.ObjectIDFromHex(value); err == nil {
+			mention.UserID = userID
+			return true
+		}
 	}
 
 	return false
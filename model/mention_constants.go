@@ -1,12 +1,12 @@
 package model
 
-// MentionStatusPending represents a Mention that has not yet been validated
+// MentionStatusPending represents a Mention that is waiting for the owner's approval
 const MentionStatusPending = "PENDING"
 
-// MentionStatusValidated represents a Mention that has been validated
+// MentionStatusValidated represents a Mention that has been approved for public display
 const MentionStatusValidated = "VALIDATED"
 
-// MentionStatusInvalid represents a Mention that is not valid
+// MentionStatusInvalid represents a Mention that has been rejected by the owner
 const MentionStatusInvalid = "INVALID"
 
 // MentionTypeStream represents a Mention that references a Stream record
@@ -14,3 +14,21 @@ const MentionTypeStream = "Stream"
 
 // MentionTypeUser represents a Mention that references a User record
 const MentionTypeUser = "User"
+
+// MentionKindMention represents a source that links to the mentioned object
+const MentionKindMention = "MENTION"
+
+// MentionKindReply represents a source that is a reply to the mentioned object (in-reply-to)
+const MentionKindReply = "REPLY"
+
+// MentionKindLike represents a source that likes the mentioned object (like-of)
+const MentionKindLike = "LIKE"
+
+// MentionKindRepost represents a source that reposts the mentioned object (repost-of)
+const MentionKindRepost = "REPOST"
+
+// MentionKindBookmark represents a source that bookmarks the mentioned object (bookmark-of)
+const MentionKindBookmark = "BOOKMARK"
+
+// MentionKindRSVP represents a source that is an RSVP to the mentioned event (in-reply-to + rsvp)
+const MentionKindRSVP = "RSVP"
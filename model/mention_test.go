@@ -3,7 +3,9 @@ package model
 import (
 	"testing"
 
+	"github.com/benpate/hannibal/vocab"
 	"github.com/benpate/rosetta/schema"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMention(t *testing.T) {
@@ -15,7 +17,14 @@ func TestMention(t *testing.T) {
 	table := []tableTestItem{
 		{"mentionId", "123412341234123412341234", nil},
 		{"objectId", "123456781234567812345678", nil},
+		{"userId", "876543218765432187654321", nil},
 		{"type", "Stream", nil},
+		{"stateId", "PENDING", nil},
+		{"kind", "REPLY", nil},
+		{"rsvp", "yes", nil},
+		{"content", "<p>Hello</p>", nil},
+		{"publishDate", int64(1700000000), nil},
+		{"vouchUrl", "https://vouch.url/", nil},
 		{"origin.type", "LIKE", nil},
 		{"origin.label", "LABEL", nil},
 		{"origin.url", "https://source.url", nil},
@@ -28,3 +37,28 @@ func TestMention(t *testing.T) {
 
 	tableTest_Schema(t, &s, &mention, table)
 }
+
+func TestMention_ResponseType(t *testing.T) {
+
+	mention := NewMention()
+	require.Equal(t, "", mention.ResponseType())
+
+	mention.Kind = MentionKindLike
+	require.Equal(t, vocab.ActivityTypeLike, mention.ResponseType())
+
+	mention.Kind = MentionKindRepost
+	require.Equal(t, vocab.ActivityTypeAnnounce, mention.ResponseType())
+
+	mention.Kind = MentionKindRSVP
+	require.True(t, mention.IsReply())
+}
+
+func TestMention_Published(t *testing.T) {
+
+	mention := NewMention()
+	mention.CreateDate = 1700000000000
+	require.Equal(t, int64(1700000000), mention.Published())
+
+	mention.PublishDate = 1600000000
+	require.Equal(t, int64(1600000000), mention.Published())
+}
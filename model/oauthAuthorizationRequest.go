@@ -3,6 +3,7 @@ package model
 import (
 	"strings"
 
+	"github.com/EmissarySocial/emissary/tools/indieauth"
 	"github.com/benpate/derp"
 	"github.com/benpate/rosetta/slice"
 )
@@ -23,6 +24,13 @@ type OAuthAuthorizationRequest struct {
 	Scope        string `query:"scope"         form:"scope"`
 	ForceLogin   bool   `query:"force_login"   form:"force_login"`
 	Language     string `query:"language"      form:"language"`
+
+	// IndieAuth extensions
+	// https://indieauth.spec.indieweb.org/#authorization-request
+	State               string `query:"state"                 form:"state"`
+	CodeChallenge       string `query:"code_challenge"        form:"code_challenge"`
+	CodeChallengeMethod string `query:"code_challenge_method" form:"code_challenge_method"`
+	Me                  string `query:"me"                    form:"me"`
 }
 
 func NewOAuthAuthorizationRequest() OAuthAuthorizationRequest {
@@ -30,6 +38,12 @@ func NewOAuthAuthorizationRequest() OAuthAuthorizationRequest {
 }
 
 func (req OAuthAuthorizationRequest) Scopes() []string {
+
+	// IndieAuth clients can request a profile-only authorization with no scopes
+	if req.Scope == "" {
+		return []string{}
+	}
+
 	return strings.Split(req.Scope, " ")
 }
 
@@ -39,6 +53,23 @@ func (req *OAuthAuthorizationRequest) Validate(app OAuthClient) error {
 
 	const location = "model.OAuthAuthorizationRequest.Validate"
 
+	// IndieAuth clients use a different set of rules
+	if app.IsIndieAuth() {
+		return req.validateIndieAuth(app)
+	}
+
+	// RULE: If present, PKCE values must be valid
+	if req.CodeChallenge != "" {
+
+		if req.CodeChallengeMethod == "" {
+			req.CodeChallengeMethod = indieauth.CodeChallengeMethodPlain
+		}
+
+		if !indieauth.IsChallengeMethod(req.CodeChallengeMethod) {
+			return derp.NewBadRequestError(location, "Invalid code_challenge_method", req.CodeChallengeMethod)
+		}
+	}
+
 	if len(app.RedirectURIs) == 0 {
 		return derp.NewInternalError(location, "Application must have at least one redirect_uri")
 	}
@@ -77,3 +108,53 @@ func (req *OAuthAuthorizationRequest) Validate(app OAuthClient) error {
 	// Success
 	return nil
 }
+
+// validateIndieAuth confirms that a request is valid for an IndieAuth client.
+// https://indieauth.spec.indieweb.org/#authorization-request
+func (req *OAuthAuthorizationRequest) validateIndieAuth(app OAuthClient) error {
+
+	const location = "model.OAuthAuthorizationRequest.validateIndieAuth"
+
+	// RULE: Redirect URI is required, and must match the client
+	if !indieauth.IsRedirectAllowed(app.ClientURL, req.RedirectURI, app.RedirectURIs) {
+		return derp.NewBadRequestError(location, "Invalid redirect_uri", req.RedirectURI)
+	}
+
+	// RULE: IndieAuth only uses the "code" response type.  ("id" is an older name for this)
+	switch req.ResponseType {
+	case "", "code", "id":
+		req.ResponseType = "code"
+	default:
+		return derp.NewBadRequestError(location, "Invalid response_type", req.ResponseType)
+	}
+
+	// RULE: The "me" value is only a hint, so invalid values are ignored
+	if me, err := indieauth.CanonicalURL(req.Me); err == nil {
+		req.Me = me
+	} else {
+		req.Me = ""
+	}
+
+	// RULE: PKCE is required
+	if req.CodeChallenge == "" {
+		return derp.NewBadRequestError(location, "code_challenge is required")
+	}
+
+	if req.CodeChallengeMethod == "" {
+		req.CodeChallengeMethod = indieauth.CodeChallengeMethodS256
+	}
+
+	if req.CodeChallengeMethod != indieauth.CodeChallengeMethodS256 {
+		return derp.NewBadRequestError(location, "Invalid code_challenge_method", req.CodeChallengeMethod)
+	}
+
+	// RULE: Verify that scope is valid.  Empty scopes are allowed (profile-only authorization)
+	for _, scope := range req.Scopes() {
+		if !slice.Contains(app.Scopes, scope) {
+			return derp.NewBadRequestError(location, "Invalid scope", scope)
+		}
+	}
+
+	// Success
+	return nil
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthAuthorizationRequest_IndieAuth(t *testing.T) {
+
+	client := NewOAuthClient()
+	client.ClientURL = "https://app.example.com/"
+	client.Scopes = IndieAuthScopes
+
+	request := OAuthAuthorizationRequest{
+		ClientID:      "https://app.example.com/",
+		RedirectURI:   "https://app.example.com/callback",
+		Scope:         "create media",
+		CodeChallenge: "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+		Me:            "Example.com/@alice",
+	}
+
+	require.Nil(t, request.Validate(client))
+	require.Equal(t, "code", request.ResponseType)
+	require.Equal(t, "S256", request.CodeChallengeMethod)
+	require.Equal(t, "https://example.com/@alice", request.Me)
+
+	// Profile-only requests have no scopes
+	request.Scope = ""
+	require.Nil(t, request.Validate(client))
+	require.Empty(t, request.Scopes())
+}
+
+func TestOAuthAuthorizationRequest_IndieAuth_Errors(t *testing.T) {
+
+	client := NewOAuthClient()
+	client.ClientURL = "https://app.example.com/"
+	client.Scopes = IndieAuthScopes
+
+	valid := func() OAuthAuthorizationRequest {
+		return OAuthAuthorizationRequest{
+			ClientID:      "https://app.example.com/",
+			RedirectURI:   "https://app.example.com/callback",
+			CodeChallenge: "challenge",
+		}
+	}
+
+	// Redirects must match the client
+	request := valid()
+	request.RedirectURI = "https://evil.example.com/callback"
+	require.NotNil(t, request.Validate(client))
+
+	// PKCE is required
+	request = valid()
+	request.CodeChallenge = ""
+	require.NotNil(t, request.Validate(client))
+
+	// Plain PKCE is not allowed
+	request = valid()
+	request.CodeChallengeMethod = "plain"
+	require.NotNil(t, request.Validate(client))
+
+	// Scopes must be supported
+	request = valid()
+	request.Scope = "admin"
+	require.NotNil(t, request.Validate(client))
+
+	// Only the "code" response type is allowed
+	request = valid()
+	request.ResponseType = "token"
+	require.NotNil(t, request.Validate(client))
+}
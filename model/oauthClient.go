@@ -14,10 +14,16 @@ type OAuthClient struct {
 	Website      string             `json:"website"       bson:"website"`
 	RedirectURIs []string           `json:"redirectUris"  bson:"redirectUris"`
 	Scopes       sliceof.String     `json:"scopes"        bson:"scopes"`
+	ClientURL    string             `json:"clientUrl"     bson:"clientUrl,omitempty"` // IndieAuth clients are identified by URL instead of by ClientID
+	LogoURL      string             `json:"logoUrl"       bson:"logoUrl,omitempty"`   // Logo published by IndieAuth clients
 
 	journal.Journal `json:"-" bson:",inline"`
 }
 
+// IndieAuthScopes are the scopes that IndieAuth clients can request.  These include
+// the IndieAuth/Micropub scopes, and the general scopes used by the Mastodon API.
+var IndieAuthScopes = []string{"profile", "email", "create", "update", "delete", "media", "read", "write", "follow"}
+
 func NewOAuthClient() OAuthClient {
 	return OAuthClient{
 		ClientID:     primitive.NewObjectID(),
@@ -30,6 +36,19 @@ func (app OAuthClient) ID() string {
 	return app.ClientID.Hex()
 }
 
+// IsIndieAuth returns TRUE if this client was discovered via IndieAuth
+func (app OAuthClient) IsIndieAuth() bool {
+	return app.ClientURL != ""
+}
+
+// PublicID returns the client_id that remote applications use to identify this client
+func (app OAuthClient) PublicID() string {
+	if app.IsIndieAuth() {
+		return app.ClientURL
+	}
+	return app.ClientID.Hex()
+}
+
 // ToToot converts this object into a Mastodon-compatible Application object
 func (app OAuthClient) Toot() object.Application {
 	return object.Application{
@@ -20,6 +20,14 @@ type OAuthUserToken struct {
 	APIUser          bool               `json:"A" bson:"apiUser"`
 	Scopes           sliceof.String     `json:"S" bson:"scopes"`
 
+	// The authorization code and PKCE values are stored between the authorization and token requests,
+	// then cleared once the code is redeemed
+	AuthorizationCode   string `json:"-" bson:"authorizationCode,omitempty"`
+	CodeExpires         int64  `json:"-" bson:"codeExpires,omitempty"` // Unix epoch (in seconds) when the authorization code expires
+	CodeChallenge       string `json:"-" bson:"codeChallenge,omitempty"`
+	CodeChallengeMethod string `json:"-" bson:"codeChallengeMethod,omitempty"`
+	RedirectURI         string `json:"-" bson:"redirectUri,omitempty"`
+
 	journal.Journal `json:"-" bson:",inline"`
 }
 
@@ -35,9 +43,22 @@ func (token OAuthUserToken) ID() string {
 }
 
 // Code returns the OAuth2 code that is used to request an access token.
-// This is just the string version of the ID.
 func (token OAuthUserToken) Code() string {
-	return token.OAuthUserTokenID.Hex()
+	return token.AuthorizationCode
+}
+
+// IsCodeExpired returns TRUE if the authorization code can no longer be redeemed
+func (token OAuthUserToken) IsCodeExpired() bool {
+	return time.Now().Unix() > token.CodeExpires
+}
+
+// ClearCode removes the authorization code and PKCE values, so that the code cannot be redeemed again
+func (token *OAuthUserToken) ClearCode() {
+	token.AuthorizationCode = ""
+	token.CodeExpires = 0
+	token.CodeChallenge = ""
+	token.CodeChallengeMethod = ""
+	token.RedirectURI = ""
 }
 
 func (token OAuthUserToken) JSONResponse() map[string]any {
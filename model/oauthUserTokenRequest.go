@@ -18,6 +18,7 @@ type OAuthUserTokenRequest struct {
 	ClientSecret string `form:"client_secret"`
 	RedirectURI  string `form:"redirect_uri"`
 	Scope        string `form:"scope"`
+	CodeVerifier string `form:"code_verifier"` // PKCE code verifier (required for IndieAuth clients)
 }
 
 func NewOAuthUserTokenRequest() OAuthUserTokenRequest {
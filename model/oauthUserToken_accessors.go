@@ -8,13 +8,18 @@ import (
 func OAuthUserTokenSchema() schema.Element {
 	return schema.Object{
 		Properties: schema.ElementMap{
-			"oauthUserTokenId": schema.String{Format: "objectId", Required: true},
-			"userId":           schema.String{Format: "objectId", Required: true},
-			"clientId":         schema.String{Format: "objectId", Required: true},
-			"token":            schema.String{},
-			"clientSecret":     schema.String{},
-			"scopes":           schema.Array{Items: schema.String{}},
-			"apiUser":          schema.Boolean{},
+			"oauthUserTokenId":    schema.String{Format: "objectId", Required: true},
+			"userId":              schema.String{Format: "objectId", Required: true},
+			"clientId":            schema.String{Format: "objectId", Required: true},
+			"token":               schema.String{},
+			"clientSecret":        schema.String{},
+			"scopes":              schema.Array{Items: schema.String{}},
+			"apiUser":             schema.Boolean{},
+			"authorizationCode":   schema.String{},
+			"codeExpires":         schema.Integer{BitSize: 64},
+			"codeChallenge":       schema.String{},
+			"codeChallengeMethod": schema.String{},
+			"redirectUri":         schema.String{},
 		},
 	}
 }
@@ -30,6 +35,21 @@ func (userToken *OAuthUserToken) GetPointer(name string) (any, bool) {
 
 	case "apiUser":
 		return &userToken.APIUser, true
+
+	case "authorizationCode":
+		return &userToken.AuthorizationCode, true
+
+	case "codeExpires":
+		return &userToken.CodeExpires, true
+
+	case "codeChallenge":
+		return &userToken.CodeChallenge, true
+
+	case "codeChallengeMethod":
+		return &userToken.CodeChallengeMethod, true
+
+	case "redirectUri":
+		return &userToken.RedirectURI, true
 	}
 
 	return nil, false
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/benpate/rosetta/convert"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+)
+
+// UserMapIDOIDC identifies the subject ("sub" claim) that a User is known by
+// at the domain's OpenID Connect provider.
+const UserMapIDOIDC = "OIDC"
+
+// OIDCConfig contains the settings for signing in through an external OpenID
+// Connect provider.  These values are stored in the Domain's Data map, which
+// is edited by the "admin-oidc" template.
+type OIDCConfig struct {
+	Active       bool           // If TRUE, then users can sign in through this provider
+	Label        string         // Human-friendly name of the provider, displayed on the sign-in page
+	Issuer       string         // Issuer URL used for OIDC discovery (e.g. "https://accounts.google.com")
+	ClientID     string         // OAuth client ID registered with the provider
+	ClientSecret string         // OAuth client secret registered with the provider
+	Scopes       sliceof.String // Additional scopes to request (beyond "openid")
+	GroupsClaim  string         // Name (or dot-separated path) of the claim that lists the user's groups
+	GroupMap     mapof.String   // Maps values in the groups claim to local Group tokens
+	Provision    bool           // If TRUE, then new users are created the first time they sign in
+	LinkEmail    bool           // If TRUE, then existing users are linked by their verified email address
+}
+
+// OIDCConfig returns the OpenID Connect settings for this domain
+func (domain Domain) OIDCConfig() OIDCConfig {
+
+	data := domain.Data
+
+	result := OIDCConfig{
+		Active:       data.GetString("oidc_active") == "true",
+		Label:        data.GetString("oidc_label"),
+		Issuer:       strings.TrimSpace(data.GetString("oidc_issuer")),
+		ClientID:     strings.TrimSpace(data.GetString("oidc_clientId")),
+		ClientSecret: domain.OIDCClientSecret,
+		Scopes:       strings.Fields(strings.ReplaceAll(data.GetString("oidc_scopes"), ",", " ")),
+		GroupsClaim:  strings.TrimSpace(data.GetString("oidc_groupsClaim")),
+		GroupMap:     mapof.NewString(),
+		Provision:    data.GetString("oidc_provision") == "true",
+		LinkEmail:    data.GetString("oidc_linkEmail") == "true",
+	}
+
+	if result.Label == "" {
+		result.Label = "Single Sign-On"
+	}
+
+	// Group mappings are written one per line, as "claimValue = groupToken"
+	for _, line := range strings.Split(data.GetString("oidc_groups"), "\n") {
+		if claimValue, groupToken, ok := strings.Cut(line, "="); ok {
+			claimValue = strings.TrimSpace(claimValue)
+			groupToken = strings.TrimSpace(groupToken)
+
+			if claimValue != "" && groupToken != "" {
+				result.GroupMap[claimValue] = groupToken
+			}
+		}
+	}
+
+	return result
+}
+
+// IsReady returns TRUE if the provider is active and has all required settings
+func (config OIDCConfig) IsReady() bool {
+	return config.Active && config.Issuer != "" && config.ClientID != ""
+}
+
+// OIDCIdentity contains the claims returned by an OpenID Connect provider
+// that Emissary uses to find, create, and update Users.
+type OIDCIdentity struct {
+	Subject       string         // Stable, unique identifier for this user at the provider
+	EmailAddress  string         // Email address reported by the provider
+	EmailVerified bool           // TRUE if the provider has verified the email address
+	Username      string         // Preferred username reported by the provider
+	DisplayName   string         // Full name reported by the provider
+	Groups        sliceof.String // Values from the configured groups claim
+}
+
+// NewOIDCIdentity extracts an OIDCIdentity from a set of ID Token claims.
+// groupsClaim may be a dot-separated path into nested claims (e.g. "realm_access.roles")
+func NewOIDCIdentity(claims mapof.Any, groupsClaim string) OIDCIdentity {
+
+	result := OIDCIdentity{
+		Subject:       convert.String(claims["sub"]),
+		EmailAddress:  convert.String(claims["email"]),
+		EmailVerified: convert.Bool(claims["email_verified"]),
+		Username:      convert.String(claims["preferred_username"]),
+		DisplayName:   convert.String(claims["name"]),
+		Groups:        sliceof.NewString(),
+	}
+
+	if groupsClaim == "" {
+		return result
+	}
+
+	// Walk the claim path to find the list of groups
+	var value any = map[string]any(claims)
+
+	for _, name := range strings.Split(groupsClaim, ".") {
+		object, ok := value.(map[string]any)
+
+		if !ok {
+			return result
+		}
+
+		value = object[name]
+	}
+
+	result.Groups = convert.SliceOfString(value)
+	return result
+}
+
+// MappedGroups returns the local Group tokens that the identity belongs to (add)
+// and the mapped Group tokens that it does not belong to (remove).
+func (identity OIDCIdentity) MappedGroups(groupMap mapof.String) (add sliceof.String, remove sliceof.String) {
+
+	add = sliceof.NewString()
+	remove = sliceof.NewString()
+
+	for claimValue, groupToken := range groupMap {
+		if identity.Groups.Contains(claimValue) && !add.Contains(groupToken) {
+			add = append(add, groupToken)
+		}
+	}
+
+	// A group listed under several claim values is kept if any of them match
+	for _, groupToken := range groupMap {
+		if !add.Contains(groupToken) && !remove.Contains(groupToken) {
+			remove = append(remove, groupToken)
+		}
+	}
+
+	slices.Sort(add)
+	slices.Sort(remove)
+
+	return add, remove
+}
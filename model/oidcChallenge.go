@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OIDCChallengeDuration is the amount of time that a User has to complete
+// sign-in at the OpenID Connect provider.
+const OIDCChallengeDuration = 10 * time.Minute
+
+// OIDCChallenge is a short-lived, signed token that tracks an OpenID Connect
+// sign-in while the User is away at the provider.  It carries the values needed
+// to validate the callback: the "state" and "nonce" values, and the PKCE verifier.
+// Its claim names do not overlap with Authorization, so it can never be mistaken
+// for a signed-in session.
+type OIDCChallenge struct {
+	State    string             `json:"oidcState"`
+	Nonce    string             `json:"oidcNonce"`
+	Verifier string             `json:"oidcVerifier"`
+	Next     string             `json:"oidcNext,omitempty"`
+	LinkUser primitive.ObjectID `json:"oidcLinkUser,omitempty"` // If present, then the provider identity is linked to this (signed-in) User
+
+	jwt.RegisteredClaims
+}
+
+// NewOIDCChallenge returns a fully initialized OIDCChallenge
+func NewOIDCChallenge(state string, nonce string, verifier string, next string) OIDCChallenge {
+	return OIDCChallenge{
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+		Next:     next,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(OIDCChallengeDuration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+}
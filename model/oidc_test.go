@@ -0,0 +1,80 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/benpate/rosetta/mapof"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomain_OIDCConfig(t *testing.T) {
+
+	domain := NewDomain()
+	require.False(t, domain.OIDCConfig().IsReady())
+	require.Equal(t, "Single Sign-On", domain.OIDCConfig().Label)
+
+	domain.Data["oidc_active"] = "true"
+	domain.Data["oidc_label"] = "Keycloak"
+	domain.Data["oidc_issuer"] = " https://sso.example.com/realms/members "
+	domain.Data["oidc_clientId"] = "emissary"
+	domain.Data["oidc_scopes"] = "groups, offline_access"
+	domain.Data["oidc_groupsClaim"] = "groups"
+	domain.Data["oidc_groups"] = "/staff = staff\n/editors=editors\n\ninvalid line\n = missing"
+	domain.Data["oidc_provision"] = "true"
+
+	config := domain.OIDCConfig()
+	require.True(t, config.IsReady())
+	require.Equal(t, "Keycloak", config.Label)
+	require.Equal(t, "https://sso.example.com/realms/members", config.Issuer)
+	require.Equal(t, []string{"groups", "offline_access"}, []string(config.Scopes))
+	require.Equal(t, mapof.String{"/staff": "staff", "/editors": "editors"}, config.GroupMap)
+	require.True(t, config.Provision)
+	require.False(t, config.LinkEmail)
+
+	// The client secret is only read from its write-only field
+	domain.Data["oidc_clientSecret"] = "from-data"
+	require.Equal(t, "", domain.OIDCConfig().ClientSecret)
+
+	domain.OIDCClientSecret = "write-only"
+	require.Equal(t, "write-only", domain.OIDCConfig().ClientSecret)
+}
+
+func TestOIDCIdentity(t *testing.T) {
+
+	claims := mapof.Any{
+		"sub":            "123",
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"groups":         []any{"staff", "editors"},
+		"realm_access":   map[string]any{"roles": []any{"admin"}},
+	}
+
+	identity := NewOIDCIdentity(claims, "groups")
+	require.Equal(t, "123", identity.Subject)
+	require.True(t, identity.EmailVerified)
+	require.Equal(t, []string{"staff", "editors"}, []string(identity.Groups))
+
+	// Nested claims use a dot-separated path
+	identity = NewOIDCIdentity(claims, "realm_access.roles")
+	require.Equal(t, []string{"admin"}, []string(identity.Groups))
+
+	// Missing claims result in no groups
+	identity = NewOIDCIdentity(claims, "missing.roles")
+	require.Empty(t, identity.Groups)
+}
+
+func TestOIDCIdentity_MappedGroups(t *testing.T) {
+
+	groupMap := mapof.String{
+		"staff":    "members",
+		"admins":   "members",
+		"editors":  "editors",
+		"external": "guests",
+	}
+
+	identity := OIDCIdentity{Groups: []string{"staff", "editors", "unmapped"}}
+	add, remove := identity.MappedGroups(groupMap)
+
+	require.Equal(t, []string{"editors", "members"}, []string(add))
+	require.Equal(t, []string{"guests"}, []string(remove))
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"encoding/base64"
+	"time"
+
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// Passkey is a WebAuthn credential that a User has registered for signing in
+type Passkey struct {
+	CredentialID    string         `bson:"credentialId"`    // Base64URL encoded credential ID
+	PublicKey       []byte         `bson:"publicKey"`       // COSE encoded public key
+	AttestationType string         `bson:"attestationType"` // Attestation format used when the credential was created
+	AAGUID          []byte         `bson:"aaguid"`          // Identifies the model of the authenticator
+	SignCount       uint32         `bson:"signCount"`       // Most recent signature counter (used to detect cloned authenticators)
+	BackupEligible  bool           `bson:"backupEligible"`  // If TRUE, then the credential can be synced between devices
+	BackupState     bool           `bson:"backupState"`     // If TRUE, then the credential is currently synced between devices
+	Transports      sliceof.String `bson:"transports"`      // Transports that the authenticator supports (usb, nfc, ble, internal, hybrid)
+	Label           string         `bson:"label"`           // Human-friendly name for this passkey
+	CreateDate      int64          `bson:"createDate"`      // Unix epoch seconds when this passkey was registered
+	LastUsedDate    int64          `bson:"lastUsedDate"`    // Unix epoch seconds when this passkey was last used
+}
+
+// NewPasskey returns a Passkey from a newly registered WebAuthn credential
+func NewPasskey(credential *webauthn.Credential, label string) Passkey {
+
+	transports := make(sliceof.String, len(credential.Transport))
+
+	for index, transport := range credential.Transport {
+		transports[index] = string(transport)
+	}
+
+	return Passkey{
+		CredentialID:    PasskeyCredentialID(credential.ID),
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		Transports:      transports,
+		Label:           label,
+		CreateDate:      time.Now().Unix(),
+	}
+}
+
+// PasskeyCredentialID encodes a raw WebAuthn credential ID for storage
+func PasskeyCredentialID(value []byte) string {
+	return base64.RawURLEncoding.EncodeToString(value)
+}
+
+// Credential returns this Passkey as a WebAuthn credential
+func (passkey Passkey) Credential() webauthn.Credential {
+
+	credentialID, _ := base64.RawURLEncoding.DecodeString(passkey.CredentialID)
+	transports := make([]protocol.AuthenticatorTransport, len(passkey.Transports))
+
+	for index, transport := range passkey.Transports {
+		transports[index] = protocol.AuthenticatorTransport(transport)
+	}
+
+	return webauthn.Credential{
+		ID:              credentialID,
+		PublicKey:       passkey.PublicKey,
+		AttestationType: passkey.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: passkey.BackupEligible,
+			BackupState:    passkey.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    passkey.AAGUID,
+			SignCount: passkey.SignCount,
+		},
+	}
+}
+
+// Update records a successful sign-in using this Passkey
+func (passkey *Passkey) Update(credential *webauthn.Credential) {
+	passkey.SignCount = credential.Authenticator.SignCount
+	passkey.BackupState = credential.Flags.BackupState
+	passkey.LastUsedDate = time.Now().Unix()
+}
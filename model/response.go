@@ -21,6 +21,7 @@ type Response struct {
 	Type       string             `json:"type"       bson:"type"`              // Type of Response (e.g. "Announce", "Bookmark", "Like", "Dislike", etc...)
 	Summary    string             `json:"summary"    bson:"summary,omitempty"` // Summary of the response (e.g. "I liked this post because...")
 	Content    string             `json:"content"    bson:"content,omitempty"` // Custom value assigned to the response (emoji, vote, etc.)
+	URL        string             `json:"url"        bson:"url,omitempty"`     // URL of the source document, for Responses that were received via WebMention
 
 	journal.Journal `json:"-" bson:",inline"`
 }
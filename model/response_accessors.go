@@ -16,6 +16,7 @@ func ResponseSchema() schema.Element {
 			"object":     schema.String{Format: "url"},
 			"type":       schema.String{MaxLength: 128, Enum: []string{vocab.ActivityTypeAnnounce, vocab.ActivityTypeLike, vocab.ActivityTypeDislike}},
 			"content":    schema.String{MaxLength: 256},
+			"url":        schema.String{Format: "url"},
 		},
 	}
 }
@@ -35,6 +36,9 @@ func (response *Response) GetPointer(name string) (any, bool) {
 
 	case "content":
 		return &response.Content, true
+
+	case "url":
+		return &response.URL, true
 	}
 
 	return nil, false
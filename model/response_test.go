@@ -19,6 +19,7 @@ func TestResponse(t *testing.T) {
 		{"actor", "http://actor.com", nil},
 		{"object", "https://example/object", nil},
 		{"content", "😀", nil},
+		{"url", "https://source.example/like", nil},
 	}
 
 	tableTest_Schema(t, &s, &response, tests)
@@ -12,6 +12,8 @@ type Rule struct {
 	UserID         primitive.ObjectID `json:"userId"         bson:"userId"`         // Unique identifier of the User who owns this Rule
 	FollowingID    primitive.ObjectID `json:"followingId"    bson:"followingId"`    // Unique identifier of the Following record that created this Rule.  If Zero, then this rule was created by the user.
 	FollowingLabel string             `json:"followingLabel" bson:"followingLabel"` // Label of the Following record that created this Rule.
+	BlocklistID    primitive.ObjectID `json:"blocklistId"    bson:"blocklistId"`    // Unique identifier of the Blocklist that created this Rule.  If Zero, then this rule was not imported from a Blocklist.
+	BlocklistLabel string             `json:"blocklistLabel" bson:"blocklistLabel"` // Label of the Blocklist that created this Rule.
 	Type           string             `json:"type"           bson:"type"`           // Type of Rule (e.g. "ACTOR", "DOMAIN", "CONTENT")
 	Action         string             `json:"action"         bson:"action"`         // Action to take when this rule is triggered (e.g. "BLOCK", "MUTE", "LABEL")
 	Label          string             `json:"label"          bson:"label"`          // Human-friendly label to add to messages
@@ -45,6 +47,7 @@ func (rule Rule) Fields() []string {
 		"_id",
 		"userId",
 		"followingId",
+		"blocklistId",
 		"type",
 		"action",
 		"label",
@@ -115,12 +118,18 @@ func (rule Rule) OriginAdmin() bool {
 	return rule.UserID.IsZero()
 }
 
-// OriginRemote returns TRUE if this Rule was created by a Following record.
+// OriginRemote returns TRUE if this Rule was imported from a Following record or a Blocklist.
 func (rule Rule) OriginRemote() bool {
 	return !rule.OriginUser()
 }
 
-// OriginUser returns TRUE if this Rule was created by the User.
+// OriginBlocklist returns TRUE if this Rule was imported from a domain Blocklist.
+func (rule Rule) OriginBlocklist() bool {
+	return !rule.BlocklistID.IsZero()
+}
+
+// OriginUser returns TRUE if this Rule was created by the User (or domain administrator),
+// and was not imported from a Following record or a Blocklist.
 func (rule Rule) OriginUser() bool {
-	return rule.FollowingID.IsZero()
+	return rule.FollowingID.IsZero() && rule.BlocklistID.IsZero()
 }
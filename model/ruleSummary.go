@@ -20,6 +20,7 @@ type RuleSummary struct {
 	Trigger        string             `bson:"trigger"`
 	Label          string             `bson:"label"`
 	FollowingLabel string             `bson:"followingLabel"`
+	BlocklistLabel string             `bson:"blocklistLabel"`
 }
 
 // RuleSummaryFields returns a list of fields that should be queried from the
@@ -33,6 +34,7 @@ func RuleSummaryFields() []string {
 		"trigger",
 		"label",
 		"followingLabel",
+		"blocklistLabel",
 	}
 }
 
@@ -82,7 +84,7 @@ func (rule RuleSummary) IsDisallowed(document *streams.Document) bool {
 	document.Append(vocab.PropertyTag, map[string]any{
 		vocab.PropertyHref:    "/@me/inbox/rule-edit?ruleId=" + rule.RuleID.Hex(),
 		vocab.PropertyRel:     TagRelationRule,
-		vocab.PropertyName:    rule.FollowingLabel,
+		vocab.PropertyName:    rule.sourceLabel(),
 		vocab.PropertyContent: rule.Label,
 	})
 
@@ -156,3 +158,13 @@ func (rule RuleSummary) matchesContent(document *streams.Document) bool {
 
 	return false
 }
+
+// sourceLabel returns the label of the Following record or Blocklist that created this rule.
+func (rule RuleSummary) sourceLabel() string {
+
+	if rule.FollowingLabel != "" {
+		return rule.FollowingLabel
+	}
+
+	return rule.BlocklistLabel
+}
@@ -12,6 +12,8 @@ func RuleSchema() schema.Element {
 			"userId":         schema.String{Required: true, Format: "objectId"},
 			"followingId":    schema.String{Format: "objectId"},
 			"followingLabel": schema.String{},
+			"blocklistId":    schema.String{Format: "objectId"},
+			"blocklistLabel": schema.String{},
 			"type":           schema.String{Required: true, Enum: []string{RuleTypeDomain, RuleTypeActor, RuleTypeContent}},
 			"action":         schema.String{Required: true, Enum: []string{RuleActionBlock, RuleActionMute, RuleActionLabel}},
 			"label":          schema.String{},
@@ -43,6 +45,9 @@ func (rule *Rule) GetPointer(name string) (any, bool) {
 	case "followingLabel":
 		return &rule.FollowingLabel, true
 
+	case "blocklistLabel":
+		return &rule.BlocklistLabel, true
+
 	case "action":
 		return &rule.Action, true
 
@@ -72,6 +77,8 @@ func (rule *Rule) GetStringOK(name string) (string, bool) {
 	case "followingId":
 		return rule.FollowingID.Hex(), true
 
+	case "blocklistId":
+		return rule.BlocklistID.Hex(), true
 	}
 
 	return "", false
@@ -98,6 +105,12 @@ func (rule *Rule) SetString(name string, value string) bool {
 			rule.FollowingID = objectID
 			return true
 		}
+
+	case "blocklistId":
+		if objectID, err := primitive.ObjectIDFromHex(value); err == nil {
+			rule.BlocklistID = objectID
+			return true
+		}
 	}
 
 	return false
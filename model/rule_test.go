@@ -17,6 +17,7 @@ func TestRuleSchema(t *testing.T) {
 		{"userId", "876543218765432187654321", nil},
 		{"followingId", "876543218765432187654321", nil},
 		{"followingLabel", "Hoo boy", nil},
+		{"blocklistId", "876543218765432187654322", nil},
 		{"type", "ACTOR", nil},
 		{"action", "LABEL", nil},
 		{"label", "LABEL", nil},
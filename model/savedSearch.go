@@ -0,0 +1,201 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/EmissarySocial/emissary/tools/parse"
+	"github.com/benpate/data/journal"
+	"github.com/benpate/exp"
+	"github.com/benpate/rosetta/sliceof"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SavedSearch is a search query that people can follow.  Each SavedSearch is published
+// as an ActivityPub actor and as an RSS/Atom/JSON feed.  New SearchResults that match
+// the query are announced to all of its Followers.
+type SavedSearch struct {
+	SavedSearchID primitive.ObjectID `json:"savedSearchId" bson:"_id"`     // Unique identifier of this SavedSearch
+	Name          string             `json:"name"          bson:"name"`    // Human-friendly name of this SavedSearch
+	Summary       string             `json:"summary"       bson:"summary"` // Human-friendly description of this SavedSearch
+	Text          string             `json:"text"          bson:"text"`    // Free-text query, which may include "phrases", prefix*, and -excluded words
+	Tags          sliceof.String     `json:"tags"          bson:"tags"`    // Normalized tag values.  Matching SearchResults must include ALL of these tags
+	Types         sliceof.String     `json:"types"         bson:"types"`   // ActivityPub object types.  Matching SearchResults must be ONE of these types
+	URL           string             `json:"url"           bson:"url"`     // Public URL of this SavedSearch
+
+	journal.Journal `json:"-" bson:",inline"`
+}
+
+// NewSavedSearch returns a fully initialized SavedSearch object
+func NewSavedSearch() SavedSearch {
+	return SavedSearch{
+		SavedSearchID: primitive.NewObjectID(),
+		Tags:          sliceof.NewString(),
+		Types:         sliceof.NewString(),
+	}
+}
+
+func SavedSearchFields() []string {
+	return []string{"_id", "name", "summary", "text", "tags", "types", "url"}
+}
+
+func (savedSearch SavedSearch) Fields() []string {
+	return SavedSearchFields()
+}
+
+/******************************************
+ * data.Object Interface
+ ******************************************/
+
+// ID returns the unique identifier for this SavedSearch, and is required to implement the data.Object interface
+func (savedSearch SavedSearch) ID() string {
+	return savedSearch.SavedSearchID.Hex()
+}
+
+/******************************************
+ * Query Methods
+ ******************************************/
+
+// IsEmpty returns TRUE if this SavedSearch does not filter SearchResults in any way
+func (savedSearch SavedSearch) IsEmpty() bool {
+	return (savedSearch.Text == "") && savedSearch.Tags.IsEmpty() && savedSearch.Types.IsEmpty()
+}
+
+// QueryString returns the free-text query and tags of this SavedSearch as a
+// single string, such as "sourdough #baking"
+func (savedSearch SavedSearch) QueryString() string {
+
+	result := savedSearch.Text
+
+	for _, tag := range savedSearch.Tags {
+		if result != "" {
+			result += " "
+		}
+		result += "#" + tag
+	}
+
+	return result
+}
+
+// SetQueryString parses a query string into tags (from #hashtags) and free text
+func (savedSearch *SavedSearch) SetQueryString(value string) {
+
+	tags, remainder := parse.HashtagsAndRemainder(value)
+
+	savedSearch.Text = strings.Join(strings.Fields(remainder), " ")
+	savedSearch.Tags = sliceof.NewString()
+
+	for _, tag := range tags {
+		if tag = ToToken(tag); (tag != "") && !savedSearch.Tags.Contains(tag) {
+			savedSearch.Tags = append(savedSearch.Tags, tag)
+		}
+	}
+}
+
+// Criteria returns the (non-text) filters that SearchResults must match
+func (savedSearch SavedSearch) Criteria() exp.Expression {
+
+	var result exp.Expression = exp.All()
+
+	if savedSearch.Types.NotEmpty() {
+		result = result.AndIn("type", savedSearch.Types)
+	}
+
+	for _, tag := range savedSearch.Tags {
+		result = result.AndEqual("tagValues", tag)
+	}
+
+	return result
+}
+
+// Query returns a SearchQuery that finds all SearchResults matching this SavedSearch,
+// newest first.
+func (savedSearch SavedSearch) Query(maxRows int64) SearchQuery {
+	return SearchQuery{
+		Text:          savedSearch.Text,
+		Criteria:      savedSearch.Criteria(),
+		SortField:     "createDate",
+		SortDirection: "desc",
+		MaxRows:       maxRows,
+	}
+}
+
+// MatchesFilters returns TRUE if the SearchResult matches the tags and types
+// of this SavedSearch.  Text queries are evaluated by the search service.
+func (savedSearch SavedSearch) MatchesFilters(searchResult *SearchResult) bool {
+
+	if savedSearch.Types.NotEmpty() && !savedSearch.Types.Contains(searchResult.Type) {
+		return false
+	}
+
+	for _, tag := range savedSearch.Tags {
+		if !searchResult.TagValues.Contains(tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+/******************************************
+ * ActivityPub Methods
+ ******************************************/
+
+// Label returns a human-friendly name for this SavedSearch, using the
+// query itself if no Name has been set.
+func (savedSearch SavedSearch) Label() string {
+
+	if savedSearch.Name != "" {
+		return savedSearch.Name
+	}
+
+	return savedSearch.QueryString()
+}
+
+// ActorLink returns a PersonLink that describes this SavedSearch as an Actor
+func (savedSearch SavedSearch) ActorLink() PersonLink {
+	return PersonLink{
+		Name:       savedSearch.Label(),
+		ProfileURL: savedSearch.URL,
+		Username:   savedSearch.Username(),
+	}
+}
+
+// Username returns the ActivityPub username of this SavedSearch
+func (savedSearch SavedSearch) Username() string {
+	return "search-" + savedSearch.SavedSearchID.Hex()
+}
+
+// ActivityPubURL returns the URL of this SavedSearch's ActivityPub actor
+func (savedSearch SavedSearch) ActivityPubURL() string {
+	return savedSearch.URL
+}
+
+// ActivityPubPublicKeyURL returns the URL of this SavedSearch's public key
+func (savedSearch SavedSearch) ActivityPubPublicKeyURL() string {
+	return savedSearch.URL + "#main-key"
+}
+
+// ActivityPubInboxURL returns the URL of this SavedSearch's ActivityPub inbox
+func (savedSearch SavedSearch) ActivityPubInboxURL() string {
+	return savedSearch.URL + "/pub/inbox"
+}
+
+// ActivityPubOutboxURL returns the URL of this SavedSearch's ActivityPub outbox
+func (savedSearch SavedSearch) ActivityPubOutboxURL() string {
+	return savedSearch.URL + "/pub/outbox"
+}
+
+// ActivityPubFollowersURL returns the URL of this SavedSearch's ActivityPub followers collection
+func (savedSearch SavedSearch) ActivityPubFollowersURL() string {
+	return savedSearch.URL + "/pub/followers"
+}
+
+// FeedURL returns the URL of this SavedSearch's RSS/Atom/JSON feed
+func (savedSearch SavedSearch) FeedURL() string {
+	return savedSearch.URL + "/feed"
+}
+
+// WebSubURL returns the URL of this SavedSearch's WebSub hub
+func (savedSearch SavedSearch) WebSubURL() string {
+	return savedSearch.URL + "/websub"
+}
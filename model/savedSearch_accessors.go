@@ -0,0 +1,83 @@
+package model
+
+import (
+	"github.com/benpate/rosetta/schema"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func SavedSearchSchema() schema.Element {
+	return schema.Object{
+		Properties: schema.ElementMap{
+			"savedSearchId": schema.String{Format: "objectId"},
+			"name":          schema.String{MaxLength: 128},
+			"summary":       schema.String{MaxLength: 1024},
+			"text":          schema.String{MaxLength: 256},
+			"query":         schema.String{MaxLength: 512},
+			"tags":          schema.Array{Items: schema.String{MaxLength: 128}},
+			"types":         schema.Array{Items: schema.String{MaxLength: 64}},
+			"url":           schema.String{Format: "url", MaxLength: 1024},
+		},
+	}
+}
+
+/******************************************
+ * Getter/Setter Interfaces
+ ******************************************/
+
+func (savedSearch *SavedSearch) GetPointer(name string) (any, bool) {
+
+	switch name {
+
+	case "name":
+		return &savedSearch.Name, true
+
+	case "summary":
+		return &savedSearch.Summary, true
+
+	case "text":
+		return &savedSearch.Text, true
+
+	case "tags":
+		return &savedSearch.Tags, true
+
+	case "types":
+		return &savedSearch.Types, true
+
+	case "url":
+		return &savedSearch.URL, true
+	}
+
+	return nil, false
+}
+
+func (savedSearch SavedSearch) GetStringOK(name string) (string, bool) {
+
+	switch name {
+
+	case "savedSearchId":
+		return savedSearch.SavedSearchID.Hex(), true
+
+	case "query":
+		return savedSearch.QueryString(), true
+	}
+
+	return "", false
+}
+
+func (savedSearch *SavedSearch) SetString(name string, value string) bool {
+
+	switch name {
+
+	case "savedSearchId":
+		if objectID, err := primitive.ObjectIDFromHex(value); err == nil {
+			savedSearch.SavedSearchID = objectID
+			return true
+		}
+
+	case "query":
+		savedSearch.SetQueryString(value)
+		return true
+	}
+
+	return false
+}
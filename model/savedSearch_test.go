@@ -0,0 +1,72 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/benpate/rosetta/schema"
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedSearchSchema(t *testing.T) {
+
+	s := schema.New(SavedSearchSchema())
+	savedSearch := NewSavedSearch()
+
+	tests := []tableTestItem{
+		{"savedSearchId", "000000000000000000000001", nil},
+		{"name", "Sourdough Recipes", nil},
+		{"summary", "Everything about bread", nil},
+		{"text", "sourdough -rye", nil},
+		{"tags", sliceof.String{"baking", "bread"}, &sliceof.String{"baking", "bread"}},
+		{"types", sliceof.String{"Article", "Note"}, &sliceof.String{"Article", "Note"}},
+		{"url", "https://example.com/.searches/000000000000000000000001", nil},
+		{"query", "sourdough   #Baking -rye #bread", "sourdough -rye #baking #bread"},
+	}
+
+	tableTest_Schema(t, &s, &savedSearch, tests)
+}
+
+func TestSavedSearch_MatchesFilters(t *testing.T) {
+
+	savedSearch := NewSavedSearch()
+	savedSearch.Tags = sliceof.String{"baking", "bread"}
+	savedSearch.Types = sliceof.String{"Article", "Note"}
+
+	searchResult := NewSearchResult()
+	searchResult.Type = "Note"
+	searchResult.TagValues = sliceof.String{"bread", "baking", "sourdough"}
+	require.True(t, savedSearch.MatchesFilters(&searchResult))
+
+	// All tags are required
+	searchResult.TagValues = sliceof.String{"bread"}
+	require.False(t, savedSearch.MatchesFilters(&searchResult))
+
+	// Types must match one of the allowed values
+	searchResult.TagValues = sliceof.String{"bread", "baking"}
+	searchResult.Type = "Person"
+	require.False(t, savedSearch.MatchesFilters(&searchResult))
+
+	// Empty filters match everything
+	require.True(t, NewSavedSearch().MatchesFilters(&searchResult))
+}
+
+func TestSavedSearch_Label(t *testing.T) {
+
+	savedSearch := NewSavedSearch()
+	savedSearch.Text = "sourdough"
+	savedSearch.Tags = sliceof.String{"baking"}
+	require.Equal(t, "sourdough #baking", savedSearch.Label())
+
+	savedSearch.Name = "Bread"
+	require.Equal(t, "Bread", savedSearch.Label())
+}
+
+func TestSavedSearch_SetQueryString(t *testing.T) {
+
+	savedSearch := NewSavedSearch()
+	savedSearch.SetQueryString(`#Bread "wild yeast" #bread starter`)
+
+	require.Equal(t, `"wild yeast" starter`, savedSearch.Text)
+	require.Equal(t, sliceof.String{"bread"}, savedSearch.Tags)
+}
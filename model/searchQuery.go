@@ -0,0 +1,19 @@
+package model
+
+import "github.com/benpate/exp"
+
+// SearchQuery describes a search of the SearchResult index
+type SearchQuery struct {
+	Text          string         // Text is the free-text query, which may include "phrases", prefix*, and -excluded words
+	Criteria      exp.Expression // Criteria are additional (non-text) filters, such as types and tags
+	Facets        []string       // Facets is the list of fields to count values for (such as "type" and "tagValues")
+	SortField     string         // SortField is the field to sort by.  Text queries sort by relevance when this is empty
+	SortDirection string         // SortDirection is either "asc" or "desc"
+	Offset        int64          // Offset is the number of results to skip before the first one returned
+	MaxRows       int64          // MaxRows is the maximum number of results to return (zero for all results)
+}
+
+// IsRelevanceSort returns TRUE if results should be sorted by their relevance to the text query
+func (query SearchQuery) IsRelevanceSort() bool {
+	return (query.Text != "") && (query.SortField == "" || query.SortField == "score")
+}
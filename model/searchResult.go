@@ -1,9 +1,12 @@
 package model
 
 import (
+	"html"
+	"html/template"
 	"math/rand/v2"
 
 	"github.com/benpate/data/journal"
+	"github.com/benpate/rosetta/mapof"
 	"github.com/benpate/rosetta/sliceof"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -23,6 +26,10 @@ type SearchResult struct {
 	Rank           int64              `bson:"rank"`         // Rank is the rank of this SearchResult in the search index.
 	Shuffle        int64              `bson:"shuffle"`      // Shuffle is a random number used to shuffle the search results.
 	ReIndexDate    int64              `bson:"reindexDate"`  // ReIndexDate is the date that this SearchResult should be reindexed.
+	Remote         bool               `bson:"remote"`       // Remote is TRUE if this SearchResult was received from another server via ActivityPub.
+	ExpireDate     int64              `bson:"expireDate"`   // ExpireDate is the date that this (remote) SearchResult should be removed from the index.  Zero means never.
+	Score          float64            `bson:"-"`            // Score is the relevance of this SearchResult to the current text query (not stored)
+	Highlights     mapof.String       `bson:"-"`            // Highlights contains HTML snippets of each field that matched the current text query (not stored)
 
 	journal.Journal `bson:",inline"`
 }
@@ -53,6 +60,26 @@ func (searchResult *SearchResult) Update(other SearchResult) {
 	searchResult.TagNames = other.TagNames
 	searchResult.TagValues = other.TagValues
 	searchResult.FullText = other.FullText
+	searchResult.ExpireDate = other.ExpireDate
+}
+
+// Highlight returns an HTML snippet of the requested field, with words that matched the
+// current text query wrapped in <mark> tags.  If the field did not match, then its
+// (escaped) original value is returned instead.
+func (searchResult SearchResult) Highlight(field string) template.HTML {
+
+	if highlight := searchResult.Highlights.GetString(field); highlight != "" {
+		return template.HTML(highlight) // Highlights are escaped by the search engine
+	}
+
+	switch field {
+	case "name":
+		return template.HTML(html.EscapeString(searchResult.Name))
+	case "summary":
+		return template.HTML(html.EscapeString(searchResult.Summary))
+	}
+
+	return ""
 }
 
 func (searchResult SearchResult) Fields() []string {
@@ -64,5 +91,6 @@ func (searchResult SearchResult) Fields() []string {
 		"summary",
 		"icon",
 		"tagNames",
+		"remote",
 	}
 }
@@ -0,0 +1,40 @@
+package model
+
+import "github.com/benpate/rosetta/sliceof"
+
+// SearchResultSet is a single page of SearchResults, along with the
+// total number of matches and the facet counts for the whole query.
+type SearchResultSet struct {
+	Results sliceof.Object[SearchResult]           // Results is the current page of SearchResults
+	Total   int64                                  // Total is the number of SearchResults that match the query
+	Facets  map[string]sliceof.Object[SearchFacet] // Facets contains value counts for each requested field
+}
+
+// SearchFacet is the number of SearchResults that share a single value
+type SearchFacet struct {
+	Value string `bson:"_id"`   // Value is the field value being counted
+	Count int64  `bson:"count"` // Count is the number of SearchResults that have this value
+}
+
+// NewSearchResultSet returns a fully initialized SearchResultSet
+func NewSearchResultSet() SearchResultSet {
+	return SearchResultSet{
+		Results: make(sliceof.Object[SearchResult], 0),
+		Facets:  make(map[string]sliceof.Object[SearchFacet]),
+	}
+}
+
+// Facet returns the value counts for a single field, sorted by count
+func (set SearchResultSet) Facet(field string) sliceof.Object[SearchFacet] {
+
+	if result, ok := set.Facets[field]; ok {
+		return result
+	}
+
+	return make(sliceof.Object[SearchFacet], 0)
+}
+
+// IsEmpty returns TRUE if there are no SearchResults in this set
+func (set SearchResultSet) IsEmpty() bool {
+	return len(set.Results) == 0
+}
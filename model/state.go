@@ -1,14 +1,64 @@
 package model
 
+import (
+	"github.com/EmissarySocial/emissary/model/step"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/convert"
+	"github.com/hjson/hjson-go/v4"
+)
+
 // State defines an individual state that a Template/Stream can be in.  States are the basis
 // for transitions, forms, and actions.
 type State struct {
-	StateID     string `json:"stateId"     bson:"stateId"`     // Unique ID for this state (within this Template)
-	Label       string `json:"label"       bson:"label"`       // Human-friendly label for this State
-	Description string `json:"description" bson:"description"` // Description of this State
+	StateID     string      `json:"stateId"     bson:"stateId"`     // Unique ID for this state (within this Template)
+	Label       string      `json:"label"       bson:"label"`       // Human-friendly label for this State
+	Description string      `json:"description" bson:"description"` // Description of this State
+	OnEnter     []step.Step `json:"onEnter"     bson:"onEnter"`     // Steps to execute when a Stream moves into this State
+	OnExit      []step.Step `json:"onExit"      bson:"onExit"`      // Steps to execute when a Stream moves out of this State
 }
 
 // NewState returns a fully initialized State object.
 func NewState() State {
-	return State{}
+	return State{
+		OnEnter: make([]step.Step, 0),
+		OnExit:  make([]step.Step, 0),
+	}
+}
+
+func (state *State) UnmarshalJSON(data []byte) error {
+	var asMap map[string]any
+
+	if err := hjson.Unmarshal(data, &asMap); err != nil {
+		return derp.Wrap(err, "model.State.UnmarshalJSON", "Invalid JSON")
+	}
+
+	return state.UnmarshalMap(asMap)
+}
+
+func (state *State) UnmarshalMap(data map[string]any) error {
+
+	const location = "model.State.UnmarshalMap"
+
+	// Import easy values
+	state.StateID = convert.String(data["stateId"])
+	state.Label = convert.String(data["label"])
+	state.Description = convert.String(data["description"])
+
+	// Import hooks
+	onEnter, err := step.NewPipeline(convert.SliceOfMap(data["onEnter"]))
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error reading onEnter steps", data["onEnter"])
+	}
+
+	onExit, err := step.NewPipeline(convert.SliceOfMap(data["onExit"]))
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error reading onExit steps", data["onExit"])
+	}
+
+	state.OnEnter = onEnter
+	state.OnExit = onExit
+
+	return nil
 }
@@ -0,0 +1,105 @@
+package step
+
+import (
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+)
+
+// CallURL is a Step that sends an HTTP request to an external API.  The URL, body,
+// and headers are Go templates.  Secret headers are read from the Domain's secrets
+// at runtime, so they are never available to templates.  Values from the JSON
+// response can be copied into the Stream's data, or into the render data.  Requests are
+// only sent when the action is submitted (POST), never when it is displayed (GET).
+type CallURL struct {
+	Method     string                        // HTTP method to use (GET, POST, PUT, PATCH, DELETE)
+	URL        *template.Template            // Template for the URL to call
+	Body       *template.Template            // Template for the request body
+	Headers    map[string]*template.Template // Templates for additional request headers
+	Secrets    map[string]string             // Map of header names => Domain secrets that contain the header value
+	Timeout    time.Duration                 // Maximum time to wait for a response
+	Queue      bool                          // If TRUE, then the request is sent in the background
+	StreamData map[string]string             // Map of Stream paths => response paths
+	RenderData map[string]string             // Map of render data keys => response paths
+}
+
+// NewCallURL returns a fully initialized CallURL object
+func NewCallURL(stepInfo mapof.Any) (CallURL, error) {
+
+	const location = "model.step.NewCallURL"
+
+	method := strings.ToUpper(first(stepInfo.GetString("method"), http.MethodGet))
+
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return CallURL{}, derp.NewInternalError(location, "Method must be GET, POST, PUT, PATCH, or DELETE", method)
+	}
+
+	if stepInfo.GetString("url") == "" {
+		return CallURL{}, derp.NewInternalError(location, "URL is required")
+	}
+
+	urlTemplate, err := template.New("url").Funcs(FuncMap()).Parse(stepInfo.GetString("url"))
+
+	if err != nil {
+		return CallURL{}, derp.Wrap(err, location, "Error parsing URL template")
+	}
+
+	bodyTemplate, err := template.New("body").Funcs(FuncMap()).Parse(stepInfo.GetString("body"))
+
+	if err != nil {
+		return CallURL{}, derp.Wrap(err, location, "Error parsing body template")
+	}
+
+	headersInfo := stepInfo.GetMap("headers")
+	headers := make(map[string]*template.Template, len(headersInfo))
+
+	for name := range headersInfo {
+		headerTemplate, err := template.New(name).Funcs(FuncMap()).Parse(headersInfo.GetString(name))
+
+		if err != nil {
+			return CallURL{}, derp.Wrap(err, location, "Error parsing header template", name)
+		}
+
+		headers[name] = headerTemplate
+	}
+
+	result := CallURL{
+		Method:     method,
+		URL:        urlTemplate,
+		Body:       bodyTemplate,
+		Headers:    headers,
+		Secrets:    callURLStringMap(stepInfo.GetMap("secrets")),
+		Timeout:    time.Duration(stepInfo.GetInt("timeout")) * time.Second,
+		Queue:      stepInfo.GetBool("queue"),
+		StreamData: callURLStringMap(stepInfo.GetMap("stream-data")),
+		RenderData: callURLStringMap(stepInfo.GetMap("render-data")),
+	}
+
+	// Background requests finish after the page has been rendered
+	if result.Queue && (len(result.RenderData) > 0) {
+		return CallURL{}, derp.NewInternalError(location, "render-data cannot be used with queued requests")
+	}
+
+	return result, nil
+}
+
+// AmStep is here only to verify that this struct is a build pipeline step
+func (step CallURL) AmStep() {}
+
+// callURLStringMap converts a map of arguments into a map of strings
+func callURLStringMap(value mapof.Any) map[string]string {
+
+	result := make(map[string]string, len(value))
+
+	for key := range value {
+		result[key] = value.GetString(key)
+	}
+
+	return result
+}
@@ -11,3 +11,8 @@ func NewEditConnection(stepInfo mapof.Any) (EditConnection, error) {
 
 // AmStep is here only to verify that this struct is a build pipeline step
 func (step EditConnection) AmStep() {}
+
+// RequireModel implements the ModelRequirer interface, because this step only works with the Domain
+func (step EditConnection) RequireModel() string {
+	return "domain"
+}
@@ -16,6 +16,7 @@ func NewEditRegistration(stepInfo mapof.Any) (EditRegistration, error) {
 // AmStep is here only to verify that this struct is a build pipeline step
 func (step EditRegistration) AmStep() {}
 
-func (step EditRegistration) RequireType() string {
-	return "registration"
+// RequireModel implements the ModelRequirer interface, because this step only works with the Domain
+func (step EditRegistration) RequireModel() string {
+	return "domain"
 }
@@ -17,3 +17,8 @@ func NewStreamPromoteDraft(stepInfo mapof.Any) (StreamPromoteDraft, error) {
 
 // AmStep is here only to verify that this struct is a build pipeline step
 func (step StreamPromoteDraft) AmStep() {}
+
+// RequireModel implements the ModelRequirer interface, because this step only works with Streams
+func (step StreamPromoteDraft) RequireModel() string {
+	return "stream"
+}
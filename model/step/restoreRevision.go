@@ -0,0 +1,20 @@
+package step
+
+import (
+	"github.com/benpate/rosetta/mapof"
+)
+
+// RestoreRevision represents a pipeline-step that copies a previous StreamRevision into the Stream's working Draft
+type RestoreRevision struct{}
+
+func NewRestoreRevision(stepInfo mapof.Any) (RestoreRevision, error) {
+	return RestoreRevision{}, nil
+}
+
+// AmStep is here only to verify that this struct is a build pipeline step
+func (step RestoreRevision) AmStep() {}
+
+// RequireModel implements the ModelRequirer interface, because this step only works with Streams
+func (step RestoreRevision) RequireModel() string {
+	return "stream"
+}
@@ -21,3 +21,8 @@ func NewSaveAndPublish(stepInfo mapof.Any) (SaveAndPublish, error) {
 
 // AmStep is here only to verify that this struct is a build pipeline step
 func (step SaveAndPublish) AmStep() {}
+
+// RequireModel implements the ModelRequirer interface, because this step only works with Streams
+func (step SaveAndPublish) RequireModel() string {
+	return "stream"
+}
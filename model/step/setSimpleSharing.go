@@ -23,3 +23,8 @@ func NewSetSimpleSharing(stepInfo mapof.Any) (SetSimpleSharing, error) {
 
 // AmStep is here only to verify that this struct is a build pipeline step
 func (step SetSimpleSharing) AmStep() {}
+
+// RequireModel implements the ModelRequirer interface, because this step only works with Streams
+func (step SetSimpleSharing) RequireModel() string {
+	return "stream"
+}
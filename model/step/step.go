@@ -53,6 +53,9 @@ func New(stepInfo mapof.Any) (Step, error) {
 	case "cache-url":
 		return NewCacheURL(stepInfo)
 
+	case "call-url":
+		return NewCallURL(stepInfo)
+
 	case "delete":
 		return NewDelete(stepInfo)
 
@@ -134,6 +137,9 @@ func New(stepInfo mapof.Any) (Step, error) {
 	case "remove-event":
 		return NewRemoveEvent(stepInfo)
 
+	case "restore-revision":
+		return NewRestoreRevision(stepInfo)
+
 	case "save":
 		return NewSave(stepInfo)
 
@@ -227,6 +233,9 @@ func New(stepInfo mapof.Any) (Step, error) {
 	case "with-follower":
 		return NewWithFollower(stepInfo)
 
+	case "with-mention":
+		return NewWithMention(stepInfo)
+
 	case "with-message":
 		return NewWithMessage(stepInfo)
 
@@ -16,3 +16,8 @@ func NewUnPublish(stepInfo mapof.Any) (UnPublish, error) {
 
 // AmStep is here only to verify that this struct is a build pipeline step
 func (step UnPublish) AmStep() {}
+
+// RequireModel implements the ModelRequirer interface, because this step only works with Streams
+func (step UnPublish) RequireModel() string {
+	return "stream"
+}
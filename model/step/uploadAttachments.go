@@ -24,6 +24,10 @@ type UploadAttachments struct {
 	RuleHeight int      // Fixed height for all downloads
 	RuleWidth  int      // Fixed width for all downloads
 	RuleTypes  []string // Allowed extensions.  The first value is used as the default.
+
+	RuleKeepMetadata bool  // If TRUE, then EXIF/GPS metadata is kept in the original file
+	RuleMaxSize      int64 // Maximum size (in bytes) of each uploaded file.  Zero means no limit.
+	RuleMaxCount     int   // Maximum number of Attachments (in this category) that an object can have.  Zero means no limit.
 }
 
 // NewUploadAttachments returns a fully parsed UploadAttachments object
@@ -57,6 +61,10 @@ func NewUploadAttachments(stepInfo mapof.Any) (UploadAttachments, error) {
 		RuleHeight: rules.GetInt("height"),
 		RuleWidth:  rules.GetInt("width"),
 		RuleTypes:  rules.GetSliceOfString("types"),
+
+		RuleKeepMetadata: rules.GetBool("keep-metadata"),
+		RuleMaxSize:      parseByteSize(rules.GetString("max-size")),
+		RuleMaxCount:     rules.GetInt("max-count"),
 	}, nil
 }
 
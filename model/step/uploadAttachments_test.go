@@ -0,0 +1,37 @@
+package step
+
+import (
+	"testing"
+
+	"github.com/benpate/rosetta/mapof"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+
+	require.Equal(t, int64(0), parseByteSize(""))
+	require.Equal(t, int64(500), parseByteSize("500"))
+	require.Equal(t, int64(500), parseByteSize("500B"))
+	require.Equal(t, int64(500*1024), parseByteSize("500KB"))
+	require.Equal(t, int64(10*1024*1024), parseByteSize(" 10 mb "))
+	require.Equal(t, int64(2*1024*1024*1024), parseByteSize("2GB"))
+
+	// Invalid values mean "no limit"
+	require.Equal(t, int64(0), parseByteSize("lots"))
+	require.Equal(t, int64(0), parseByteSize("-5MB"))
+	require.Equal(t, int64(0), parseByteSize("1.5MB"))
+}
+
+func TestUploadAttachments_Rules(t *testing.T) {
+
+	step, err := NewUploadAttachments(mapof.Any{
+		"rules": mapof.Any{
+			"max-size":  "5MB",
+			"max-count": 3,
+		},
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, int64(5*1024*1024), step.RuleMaxSize)
+	require.Equal(t, 3, step.RuleMaxCount)
+}
@@ -1,5 +1,10 @@
 package step
 
+import (
+	"strconv"
+	"strings"
+)
+
 // first is a cheapy little function to pick the first "non-zero" value from
 // a list of values.
 func first[T comparable](values ...T) T {
@@ -14,3 +19,36 @@ func first[T comparable](values ...T) T {
 
 	return zero
 }
+
+// parseByteSize converts a human-friendly file size (like "500KB", "10MB", or "1GB")
+// into a number of bytes.  Values with no units are treated as bytes.  Invalid values
+// return zero, which means "no limit".
+func parseByteSize(value string) int64 {
+
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"GB", 1024 * 1024 * 1024},
+		{"MB", 1024 * 1024},
+		{"KB", 1024},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	result, err := strconv.ParseInt(value, 10, 64)
+
+	if (err != nil) || (result < 0) {
+		return 0
+	}
+
+	return result * multiplier
+}
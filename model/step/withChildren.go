@@ -29,3 +29,8 @@ func NewWithChildren(stepInfo mapof.Any) (WithChildren, error) {
 
 // AmStep is here only to verify that this struct is a build pipeline step
 func (step WithChildren) AmStep() {}
+
+// RequireModel implements the ModelRequirer interface, because this step only works with Streams
+func (step WithChildren) RequireModel() string {
+	return "stream"
+}
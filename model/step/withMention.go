@@ -0,0 +1,31 @@
+package step
+
+import (
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/convert"
+	"github.com/benpate/rosetta/mapof"
+)
+
+// WithMention is a Step that returns a new Mention Builder
+type WithMention struct {
+	SubSteps []Step
+}
+
+// NewWithMention returns a fully initialized WithMention object
+func NewWithMention(stepInfo mapof.Any) (WithMention, error) {
+
+	const location = "NewWithMention"
+
+	subSteps, err := NewPipeline(convert.SliceOfMap(stepInfo["steps"]))
+
+	if err != nil {
+		return WithMention{}, derp.Wrap(err, location, "Invalid 'steps'", stepInfo)
+	}
+
+	return WithMention{
+		SubSteps: subSteps,
+	}, nil
+}
+
+// AmStep is here only to verify that this struct is a build pipeline step
+func (step WithMention) AmStep() {}
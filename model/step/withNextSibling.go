@@ -29,3 +29,8 @@ func NewWithNextSibling(stepInfo mapof.Any) (WithNextSibling, error) {
 
 // AmStep is here only to verify that this struct is a build pipeline step
 func (step WithNextSibling) AmStep() {}
+
+// RequireModel implements the ModelRequirer interface, because this step only works with Streams
+func (step WithNextSibling) RequireModel() string {
+	return "stream"
+}
@@ -29,3 +29,8 @@ func NewWithParent(stepInfo mapof.Any) (WithParent, error) {
 
 // AmStep is here only to verify that this struct is a build pipeline step
 func (step WithParent) AmStep() {}
+
+// RequireModel implements the ModelRequirer interface, because this step only works with Streams
+func (step WithParent) RequireModel() string {
+	return "stream"
+}
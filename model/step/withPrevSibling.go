@@ -29,3 +29,8 @@ func NewWithPrevSibling(stepInfo mapof.Any) (WithPrevSibling, error) {
 
 // AmStep is here only to verify that this struct is a build pipeline step
 func (step WithPrevSibling) AmStep() {}
+
+// RequireModel implements the ModelRequirer interface, because this step only works with Streams
+func (step WithPrevSibling) RequireModel() string {
+	return "stream"
+}
@@ -42,6 +42,9 @@ type Stream struct {
 	InReplyTo        string                       `json:"inReplyTo"              bson:"inReplyTo"`              // If this stream is a reply to another stream or web page, then this links to the original document.
 	AttributedTo     PersonLink                   `json:"attributedTo,omitempty" bson:"attributedTo,omitempty"` // List of people who are attributed to this document
 	Content          Content                      `json:"content,omitempty"      bson:"content,omitempty"`      // Body content object for this Stream.
+	Language         string                       `json:"language,omitempty"     bson:"language,omitempty"`     // Language code (BCP 47) of the Label and Content
+	NameMap          mapof.String                 `json:"nameMap,omitempty"      bson:"nameMap,omitempty"`      // Translations of the Label, keyed by language code
+	ContentMap       mapof.String                 `json:"contentMap,omitempty"   bson:"contentMap,omitempty"`   // Translations of the Content (in HTML), keyed by language code
 	Widgets          set.Slice[StreamWidget]      `json:"widgets,omitempty"      bson:"widgets,omitempty"`      // Additional widgets to include when building this Stream.
 	Hashtags         sliceof.String               `json:"hashtags,omitempty"     bson:"hashtags,omitempty"`     // List of hashtags that are associated with this document
 	Data             mapof.Any                    `json:"data,omitempty"         bson:"data,omitempty"`         // Set of data to populate into the Template.  This is validated by the JSON-Schema of the Template.
@@ -62,7 +65,7 @@ func NewStream() Stream {
 		Token:         streamID.Hex(),
 		ParentID:      primitive.NilObjectID,
 		ParentIDs:     id.NewSlice(),
-		StateID:       "new",
+		StateID:       StreamStateNew,
 		Permissions:   NewStreamPermissions(),
 		Widgets:       NewStreamWidgets(),
 		Data:          mapof.NewAny(),
@@ -299,6 +302,10 @@ func (stream *Stream) ActivityPubInboxURL() string {
 	return stream.URL + "/pub/inbox"
 }
 
+func (stream *Stream) ActivityPubSharedInboxURL() string {
+	return SharedInboxURL(stream.URL)
+}
+
 func (stream *Stream) ActivityPubOutboxURL() string {
 	return stream.URL + "/pub/outbox"
 }
@@ -368,6 +375,38 @@ func (stream *Stream) PublishActivity() string {
 	return vocab.ActivityTypeCreate
 }
 
+// ActivityPubNameMap returns all translations of this Stream's Label, keyed by language code,
+// including the Label itself (in the Stream's primary Language).  It returns an empty map
+// if the Stream does not declare a Language or any translations.
+func (stream *Stream) ActivityPubNameMap() mapof.String {
+	return stream.languageMap(stream.Label, stream.NameMap)
+}
+
+// ActivityPubContentMap returns all translations of this Stream's Content (in HTML), keyed by
+// language code, including the Content itself (in the Stream's primary Language).  It returns an
+// empty map if the Stream does not declare a Language or any translations.
+func (stream *Stream) ActivityPubContentMap() mapof.String {
+	return stream.languageMap(stream.Content.HTML, stream.ContentMap)
+}
+
+// languageMap combines a primary value with its translations
+func (stream *Stream) languageMap(value string, translations mapof.String) mapof.String {
+
+	result := mapof.NewString()
+
+	for language, translation := range translations {
+		if (language != "") && (translation != "") {
+			result[language] = translation
+		}
+	}
+
+	if (stream.Language != "") && (value != "") {
+		result[stream.Language] = value
+	}
+
+	return result
+}
+
 /******************************************
  * Mastodon API Methods
  ******************************************/
@@ -509,6 +548,9 @@ func (stream *Stream) CopyFrom(other Stream) {
 	stream.InReplyTo = other.InReplyTo
 	stream.AttributedTo = other.AttributedTo
 	stream.Content = other.Content
+	stream.Language = other.Language
+	stream.NameMap = other.NameMap
+	stream.ContentMap = other.ContentMap
 	stream.Widgets = other.Widgets
 	stream.Hashtags = other.Hashtags
 	stream.Data = other.Data
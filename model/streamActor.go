@@ -41,6 +41,10 @@ func (actor StreamActor) JSONLD(stream *Stream) mapof.Any {
 		vocab.PropertyPreferredUsername: stream.Token,
 	}
 
+	result[vocab.PropertyEndpoints] = mapof.Any{
+		PropertySharedInbox: stream.ActivityPubSharedInboxURL(),
+	}
+
 	if stream.Summary != "" {
 		result[vocab.PropertySummary] = stream.Summary
 	}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/benpate/data/journal"
+	"github.com/benpate/rosetta/convert"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StreamRevision is an immutable snapshot of a Stream (or its Draft) that is recorded every
+// time the Stream is saved or published.  Revisions can be compared, and restored as new drafts.
+type StreamRevision struct {
+	StreamRevisionID primitive.ObjectID `json:"streamRevisionId" bson:"_id"`        // Unique identifier for this Revision
+	StreamID         primitive.ObjectID `json:"streamId"         bson:"streamId"`   // ID of the Stream that this Revision belongs to
+	Kind             string             `json:"kind"             bson:"kind"`       // Kind of change that created this Revision (SAVE, DRAFT, PUBLISH, RESTORE)
+	AuthorID         primitive.ObjectID `json:"authorId"         bson:"authorId"`   // ID of the User who made this change
+	AuthorName       string             `json:"authorName"       bson:"authorName"` // Display name of the User who made this change
+	Note             string             `json:"note"             bson:"note"`       // Human-friendly note describing this change
+	StateID          string             `json:"stateId"          bson:"stateId"`    // State of the Stream when this Revision was recorded
+	Label            string             `json:"label"            bson:"label"`      // Label of the Stream when this Revision was recorded
+	Summary          string             `json:"summary"          bson:"summary"`    // Summary of the Stream when this Revision was recorded
+	IconURL          string             `json:"iconUrl"          bson:"iconUrl"`    // Icon of the Stream when this Revision was recorded
+	Content          Content            `json:"content"          bson:"content"`    // Content of the Stream when this Revision was recorded
+	Data             mapof.Any          `json:"data"             bson:"data"`       // Custom data of the Stream when this Revision was recorded
+
+	journal.Journal `json:"-" bson:",inline"`
+}
+
+// NewStreamRevision returns a fully initialized StreamRevision object
+func NewStreamRevision() StreamRevision {
+	return StreamRevision{
+		StreamRevisionID: primitive.NewObjectID(),
+		Data:             mapof.NewAny(),
+	}
+}
+
+// NewStreamRevisionFromStream returns a new StreamRevision that is a snapshot of the provided Stream
+func NewStreamRevisionFromStream(stream *Stream, kind string) StreamRevision {
+
+	result := NewStreamRevision()
+	result.StreamID = stream.StreamID
+	result.Kind = kind
+	result.StateID = stream.StateID
+	result.Label = stream.Label
+	result.Summary = stream.Summary
+	result.IconURL = stream.IconURL
+	result.Content = stream.Content
+
+	// Copy data values so that later changes to the Stream do not leak into this Revision
+	for key, value := range stream.Data {
+		result.Data[key] = value
+	}
+
+	return result
+}
+
+/******************************************
+ * data.Object Interface
+ ******************************************/
+
+// ID returns the unique identifier for this StreamRevision (in string format)
+func (revision StreamRevision) ID() string {
+	return revision.StreamRevisionID.Hex()
+}
+
+func (revision StreamRevision) Fields() []string {
+	return []string{"_id", "streamId", "kind", "authorId", "authorName", "note", "stateId", "label", "createDate"}
+}
+
+/******************************************
+ * Other Data Methods
+ ******************************************/
+
+// ApplyTo copies the contents of this Revision into a Stream (or Draft)
+func (revision StreamRevision) ApplyTo(stream *Stream) {
+	stream.Label = revision.Label
+	stream.Summary = revision.Summary
+	stream.IconURL = revision.IconURL
+	stream.Content = revision.Content
+	stream.Data = mapof.NewAny()
+
+	for key, value := range revision.Data {
+		stream.Data[key] = value
+	}
+}
+
+// Diff returns a field-level list of the changes from this Revision to the "other" Revision
+func (revision StreamRevision) Diff(other StreamRevision) sliceof.Object[StreamRevisionChange] {
+
+	result := sliceof.NewObject[StreamRevisionChange]()
+
+	add := func(field string, before string, after string) {
+		if before != after {
+			result = append(result, StreamRevisionChange{Field: field, Before: before, After: after})
+		}
+	}
+
+	add("label", revision.Label, other.Label)
+	add("summary", revision.Summary, other.Summary)
+	add("iconUrl", revision.IconURL, other.IconURL)
+	add("stateId", revision.StateID, other.StateID)
+	add("content", revision.Content.HTML, other.Content.HTML)
+
+	// Compare all data values (in a stable order)
+	keys := make([]string, 0, len(revision.Data)+len(other.Data))
+
+	for key := range revision.Data {
+		keys = append(keys, key)
+	}
+
+	for key := range other.Data {
+		if _, exists := revision.Data[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		add("data."+key, convert.String(revision.Data[key]), convert.String(other.Data[key]))
+	}
+
+	return result
+}
+
+// StreamRevisionChange describes a single field that is different between two StreamRevisions
+type StreamRevisionChange struct {
+	Field  string // Path of the field that changed
+	Before string // Value of the field in the older Revision
+	After  string // Value of the field in the newer Revision
+}
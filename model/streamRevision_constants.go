@@ -0,0 +1,13 @@
+package model
+
+// StreamRevisionKindSave represents a Revision recorded when a published Stream is saved directly
+const StreamRevisionKindSave = "SAVE"
+
+// StreamRevisionKindDraft represents a Revision recorded when a Stream's working Draft is saved
+const StreamRevisionKindDraft = "DRAFT"
+
+// StreamRevisionKindPublish represents a Revision recorded when a Stream (or its promoted Draft) is published
+const StreamRevisionKindPublish = "PUBLISH"
+
+// StreamRevisionKindRestore represents a Revision recorded when an older Revision is restored as a new Draft
+const StreamRevisionKindRestore = "RESTORE"
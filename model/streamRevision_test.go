@@ -0,0 +1,60 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/benpate/rosetta/mapof"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamRevision_Snapshot(t *testing.T) {
+
+	stream := NewStream()
+	stream.StateID = "published"
+	stream.Label = "Label"
+	stream.Content = NewHTMLContent("<p>Hello</p>")
+	stream.Data = mapof.Any{"tags": "#one"}
+
+	revision := NewStreamRevisionFromStream(&stream, StreamRevisionKindSave)
+	require.Equal(t, stream.StreamID, revision.StreamID)
+	require.Equal(t, StreamRevisionKindSave, revision.Kind)
+	require.Equal(t, "published", revision.StateID)
+	require.Equal(t, "<p>Hello</p>", revision.Content.HTML)
+
+	// Later changes to the Stream must not change the Revision
+	stream.Data["tags"] = "#two"
+	require.Equal(t, "#one", revision.Data["tags"])
+
+	// Revisions can be copied back into a Stream
+	draft := NewStream()
+	revision.ApplyTo(&draft)
+	require.Equal(t, "Label", draft.Label)
+	require.Equal(t, "<p>Hello</p>", draft.Content.HTML)
+	require.Equal(t, "#one", draft.Data["tags"])
+}
+
+func TestStreamRevision_Diff(t *testing.T) {
+
+	before := NewStreamRevision()
+	before.Label = "Old Label"
+	before.StateID = "unpublished"
+	before.Content = NewHTMLContent("<p>Old</p>")
+	before.Data = mapof.Any{"tags": "#one", "removed": "gone"}
+
+	after := NewStreamRevision()
+	after.Label = "New Label"
+	after.StateID = "unpublished"
+	after.Content = NewHTMLContent("<p>New</p>")
+	after.Data = mapof.Any{"tags": "#one", "added": 42}
+
+	changes := before.Diff(after)
+
+	require.Equal(t, []StreamRevisionChange{
+		{Field: "label", Before: "Old Label", After: "New Label"},
+		{Field: "content", Before: "<p>Old</p>", After: "<p>New</p>"},
+		{Field: "data.added", Before: "", After: "42"},
+		{Field: "data.removed", Before: "gone", After: ""},
+	}, []StreamRevisionChange(changes))
+
+	require.Empty(t, before.Diff(before))
+}
@@ -31,6 +31,9 @@ func StreamSchema() schema.Element {
 			"context":          schema.String{Format: "url"},
 			"inReplyTo":        schema.String{Format: "url"},
 			"content":          ContentSchema(),
+			"language":         schema.String{MaxLength: 35},
+			"nameMap":          schema.Object{Wildcard: schema.String{MaxLength: 128}},
+			"contentMap":       schema.Object{Wildcard: schema.String{Format: "html"}},
 			"widgets":          WidgetSchema(),
 			"hashtags":         schema.Array{Items: schema.String{Format: "token"}},
 			"data":             schema.Object{Wildcard: schema.Any{}},
@@ -94,6 +97,15 @@ func (stream *Stream) GetPointer(name string) (any, bool) {
 	case "content":
 		return &stream.Content, true
 
+	case "language":
+		return &stream.Language, true
+
+	case "nameMap":
+		return &stream.NameMap, true
+
+	case "contentMap":
+		return &stream.ContentMap, true
+
 	case "widgets":
 		return &stream.Widgets, true
 
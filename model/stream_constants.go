@@ -0,0 +1,4 @@
+package model
+
+// StreamStateNew is the initial state of a Stream that has not yet been saved into any Template-defined state
+const StreamStateNew = "new"
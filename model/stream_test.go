@@ -6,6 +6,7 @@ import (
 	"github.com/benpate/rosetta/mapof"
 	"github.com/benpate/rosetta/schema"
 	"github.com/benpate/rosetta/sliceof"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStreamSchema(t *testing.T) {
@@ -46,6 +47,9 @@ func TestStreamSchema(t *testing.T) {
 		{"content.format", "HTML", nil},
 		{"content.raw", "TEST_RAWCONTENT", nil},
 		{"content.html", "TEST_HTML", nil},
+		{"language", "en", nil},
+		{"nameMap.fr", "DOC-LABEL-FR", nil},
+		{"contentMap.fr", "TEST_HTML_FR", nil},
 
 		{"permissions.ABC.0", "00000000000000000000000B", nil},
 		{"permissions.ABC.1", "00000000000000000000000C", nil},
@@ -81,3 +85,21 @@ func TestPermissionSchema(t *testing.T) {
 
 	tableTest_Schema(t, &s, &m, table)
 }
+
+func TestStream_ActivityPubLanguageMaps(t *testing.T) {
+
+	stream := NewStream()
+	stream.Label = "Hello"
+	stream.Content.HTML = "<p>Hello World</p>"
+
+	// Streams without a language or translations do not include language maps
+	require.Empty(t, stream.ActivityPubNameMap())
+	require.Empty(t, stream.ActivityPubContentMap())
+
+	stream.Language = "en"
+	stream.NameMap = mapof.String{"fr": "Bonjour", "es": ""}
+	stream.ContentMap = mapof.String{"fr": "<p>Bonjour le monde</p>"}
+
+	require.Equal(t, mapof.String{"en": "Hello", "fr": "Bonjour"}, stream.ActivityPubNameMap())
+	require.Equal(t, mapof.String{"en": "<p>Hello World</p>", "fr": "<p>Bonjour le monde</p>"}, stream.ActivityPubContentMap())
+}
@@ -35,6 +35,7 @@ type Template struct {
 	WidgetLocations    sliceof.String       `json:"widget-locations"   bson:"widgetLocations"`    // List of locations where widgets can be placed.  Common values are: "TOP", "BOTTOM", "LEFT", "RIGHT"
 	Schema             schema.Schema        `json:"schema"             bson:"schema"`             // JSON Schema that describes the data required to populate this Template.
 	States             mapof.Object[State]  `json:"states"             bson:"states"`             // Map of States (by state.ID) that Streams of this Template can be in.
+	Transitions        []Transition         `json:"transitions"        bson:"transitions"`        // List of permitted moves between States.  If empty, then Streams can move between States freely.
 	AccessRoles        mapof.Object[Role]   `json:"accessRoles"        bson:"accessRoles"`        // Map of custom roles defined by this Template.
 	Actions            mapof.Object[Action] `json:"actions"            bson:"actions"`            // Map of actions that can be performed on streams of this Template
 	HTMLTemplate       *template.Template   `json:"-"                  bson:"-"`                  // Compiled HTML template
@@ -45,6 +46,7 @@ type Template struct {
 	Datasets           DatasetMap           `json:"datasets"           bson:"-"`                  // Lookup codes defined by this template
 	DefaultAction      string               `json:"defaultAction"      bson:"defaultAction"`      // Name of the action to be used when none is provided.  Also serves as the permissions for viewing a Stream.  If this is empty, it is assumed to be "view"
 	Actor              StreamActor          `json:"actor"              bson:"actor"`              // ActivityPub Actor operated on behalf of this Template/Stream
+	RevisionLimit      int                  `json:"revisionLimit"      bson:"revisionLimit"`      // Maximum number of StreamRevisions to retain for each Stream.  Zero disables revision history.
 }
 
 type DatasetMap map[string]form.ReadOnlyLookupGroup
@@ -61,6 +63,7 @@ func NewTemplate(templateID string, funcMap template.FuncMap) Template {
 		ChildSortDirection: option.SortDirectionAscending,
 		WidgetLocations:    make(sliceof.String, 0),
 		States:             make(map[string]State),
+		Transitions:        make([]Transition, 0),
 		AccessRoles:        make(map[string]Role),
 		Actions:            make(map[string]Action),
 		DefaultAction:      "view",
@@ -113,6 +116,25 @@ func (template *Template) State(stateID string) (State, bool) {
 	return state, ok
 }
 
+// FindTransitions returns all declared Transitions that move from one State to another.
+// The second return value is FALSE if this Template does not restrict state changes at all.
+func (template *Template) FindTransitions(fromStateID string, toStateID string) ([]Transition, bool) {
+
+	if len(template.Transitions) == 0 {
+		return nil, false
+	}
+
+	result := make([]Transition, 0)
+
+	for _, transition := range template.Transitions {
+		if transition.Matches(fromStateID, toStateID) {
+			result = append(result, transition)
+		}
+	}
+
+	return result, true
+}
+
 // Action returns the action object for a specified name
 func (template *Template) Action(actionID string) (Action, bool) {
 	action, ok := template.Actions[actionID]
@@ -154,6 +176,11 @@ func (template *Template) Inherit(parent *Template) {
 		template.SocialRules = append(template.SocialRules, parent.SocialRules...)
 	}
 
+	// Inherit RevisionLimit.
+	if template.RevisionLimit == 0 {
+		template.RevisionLimit = parent.RevisionLimit
+	}
+
 	// Inherit ContainedBy.
 	if len(template.ContainedBy) == 0 {
 		template.ContainedBy = parent.ContainedBy
@@ -178,6 +205,11 @@ func (template *Template) Inherit(parent *Template) {
 		}
 	}
 
+	// Inherit Transitions.
+	if len(template.Transitions) == 0 {
+		template.Transitions = parent.Transitions
+	}
+
 	// Inherit States from the parent.
 	for stateID, state := range parent.States {
 		if _, exists := template.States[stateID]; !exists {
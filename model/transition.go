@@ -0,0 +1,97 @@
+package model
+
+import (
+	"html/template"
+
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/convert"
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/hjson/hjson-go/v4"
+)
+
+// Transition declares a permitted move between two States in a Template.  Once a Template
+// declares any Transitions, Streams can only change states along one of them.
+type Transition struct {
+	From  sliceof.String     `json:"from"  bson:"from"`  // List of States that this Transition leaves from.  If empty, then any State is allowed.
+	To    string             `json:"to"    bson:"to"`    // State that this Transition moves into
+	Roles sliceof.String     `json:"roles" bson:"roles"` // List of roles required to use this Transition.  If empty, then none are required.
+	Guard *template.Template `json:"-"     bson:"-"`     // Optional condition (evaluated against the Stream) that must be "true" for this Transition to be used
+}
+
+// NewTransition returns a fully initialized Transition
+func NewTransition() Transition {
+	return Transition{
+		From:  sliceof.NewString(),
+		Roles: sliceof.NewString(),
+	}
+}
+
+// Matches returns TRUE if this Transition moves between the provided States
+func (transition *Transition) Matches(fromStateID string, toStateID string) bool {
+
+	if transition.To != toStateID {
+		return false
+	}
+
+	if len(transition.From) == 0 {
+		return true
+	}
+
+	return matchOne(transition.From, fromStateID)
+}
+
+// AllowedRoles returns all of the roles that are allowed to use this Transition.
+// Like Actions, owners can always use every Transition.
+func (transition *Transition) AllowedRoles() []string {
+
+	result := make([]string, 0, len(transition.Roles)+2)
+
+	if len(transition.Roles) > 0 {
+		result = append(result, transition.Roles...)
+	} else {
+		result = append(result, MagicRoleAnonymous, MagicRoleAuthenticated)
+	}
+
+	return append(result, MagicRoleMyself, MagicRoleOwner)
+}
+
+// UserCan returns TRUE if the provided authorization includes one of the roles required for this Transition
+func (transition *Transition) UserCan(enumerator RoleStateEnumerator, authorization *Authorization) bool {
+	return matchAny(enumerator.Roles(authorization), transition.AllowedRoles())
+}
+
+func (transition *Transition) UnmarshalJSON(data []byte) error {
+	var asMap map[string]any
+
+	if err := hjson.Unmarshal(data, &asMap); err != nil {
+		return derp.Wrap(err, "model.Transition.UnmarshalJSON", "Invalid JSON")
+	}
+
+	return transition.UnmarshalMap(asMap)
+}
+
+func (transition *Transition) UnmarshalMap(data map[string]any) error {
+
+	// Import easy values
+	transition.From = convert.SliceOfString(data["from"])
+	transition.To = convert.String(data["to"])
+	transition.Roles = convert.SliceOfString(data["roles"])
+	transition.Guard = nil
+
+	if transition.To == "" {
+		return derp.NewInternalError("model.Transition.UnmarshalMap", "Transition must include a 'to' state", data)
+	}
+
+	// Import guard condition
+	if guard := convert.String(data["guard"]); guard != "" {
+		parsed, err := template.New("").Parse(guard)
+
+		if err != nil {
+			return derp.Wrap(err, "model.Transition.UnmarshalMap", "Invalid 'guard'", guard)
+		}
+
+		transition.Guard = parsed
+	}
+
+	return nil
+}
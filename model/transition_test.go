@@ -0,0 +1,94 @@
+package model
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/hjson/hjson-go/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func testTransitionTemplate(t *testing.T) Template {
+
+	definition := []byte(`{
+		states: {
+			draft: {label:"Draft"}
+			review: {
+				label:"In Review"
+				onEnter: [{do:"send-email", email:"review-requested"}]
+				onExit: [{do:"set-data", values:{reviewed:"true"}}]
+			}
+			published: {label:"Published"}
+		}
+		transitions: [
+			{from:["draft"], to:"review", roles:["editor"]}
+			{from:["review"], to:"published", roles:["reviewer"], guard:"{{ .DataString \"approved\" }}"}
+			{to:"draft"}
+		]
+	}`)
+
+	result := NewTemplate("test", template.FuncMap{})
+	require.Nil(t, hjson.Unmarshal(definition, &result))
+	return result
+}
+
+func TestTransition_Unmarshal(t *testing.T) {
+
+	template := testTransitionTemplate(t)
+
+	require.Equal(t, 3, len(template.Transitions))
+	require.Nil(t, template.Transitions[0].Guard)
+	require.NotNil(t, template.Transitions[1].Guard)
+
+	review, ok := template.State("review")
+	require.True(t, ok)
+	require.Equal(t, "In Review", review.Label)
+	require.Equal(t, 1, len(review.OnEnter))
+	require.Equal(t, 1, len(review.OnExit))
+}
+
+func TestTransition_Unmarshal_MissingTo(t *testing.T) {
+	result := NewTemplate("test", template.FuncMap{})
+	require.NotNil(t, hjson.Unmarshal([]byte(`{transitions:[{from:["draft"]}]}`), &result))
+}
+
+func TestTransition_FindTransitions(t *testing.T) {
+
+	template := testTransitionTemplate(t)
+
+	// Declared transition
+	transitions, restricted := template.FindTransitions("draft", "review")
+	require.True(t, restricted)
+	require.Equal(t, 1, len(transitions))
+
+	// Undeclared transition
+	transitions, restricted = template.FindTransitions("draft", "published")
+	require.True(t, restricted)
+	require.Empty(t, transitions)
+
+	// Empty "from" matches every state
+	transitions, _ = template.FindTransitions("published", "draft")
+	require.Equal(t, 1, len(transitions))
+
+	// Templates without transitions are not restricted
+	empty := NewTemplate("empty", nil)
+	_, restricted = empty.FindTransitions("draft", "published")
+	require.False(t, restricted)
+}
+
+func TestTransition_AllowedRoles(t *testing.T) {
+
+	template := testTransitionTemplate(t)
+
+	require.Equal(t, []string{"editor", MagicRoleMyself, MagicRoleOwner}, template.Transitions[0].AllowedRoles())
+	require.Equal(t, []string{MagicRoleAnonymous, MagicRoleAuthenticated, MagicRoleMyself, MagicRoleOwner}, template.Transitions[2].AllowedRoles())
+}
+
+func TestTransition_Inherit(t *testing.T) {
+
+	parent := testTransitionTemplate(t)
+	child := NewTemplate("child", template.FuncMap{})
+	child.Inherit(&parent)
+
+	require.Equal(t, 3, len(child.Transitions))
+}
@@ -0,0 +1,230 @@
+package model
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/EmissarySocial/emissary/tools/random"
+	"github.com/EmissarySocial/emissary/tools/totp"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/sliceof"
+)
+
+// TwoFactorRecoveryCodeCount is the number of recovery codes generated for each User
+const TwoFactorRecoveryCodeCount = 10
+
+// TwoFactorMaxAttempts is the number of invalid codes allowed before codes are locked out
+const TwoFactorMaxAttempts = 5
+
+// TwoFactorLockout is how long all codes are rejected after too many invalid codes.
+// Signing in with a valid password does not shorten the lockout.
+const TwoFactorLockout = 15 * time.Minute
+
+// TwoFactor contains a User's second-factor authentication settings
+type TwoFactor struct {
+	TOTPSecret    string                  `bson:"totpSecret,omitempty"`    // TOTP secret, sealed with the domain's key-encrypting key (pending until TOTPEnabled is TRUE)
+	TOTPEnabled   bool                    `bson:"totpEnabled,omitempty"`   // If TRUE, then the TOTP secret has been confirmed by the User
+	TOTPLastStep  int64                   `bson:"totpLastStep,omitempty"`  // Time step of the last accepted TOTP code (prevents replays)
+	RecoveryCodes sliceof.String          `bson:"recoveryCodes,omitempty"` // SHA-256 hashes of unused recovery codes
+	Passkeys      sliceof.Object[Passkey] `bson:"passkeys,omitempty"`      // WebAuthn credentials registered by the User
+	FailedCount   int                     `bson:"failedCount,omitempty"`   // Number of invalid codes entered since the last valid code
+	FailedAt      int64                   `bson:"failedAt,omitempty"`      // Unix time of the most recent invalid code
+}
+
+// IsEnabled returns TRUE if the User has configured any second factor
+func (twoFactor TwoFactor) IsEnabled() bool {
+	return twoFactor.TOTPEnabled || twoFactor.HasPasskeys()
+}
+
+// HasPasskeys returns TRUE if the User has registered at least one passkey
+func (twoFactor TwoFactor) HasPasskeys() bool {
+	return len(twoFactor.Passkeys) > 0
+}
+
+// RecoveryCodeCount returns the number of unused recovery codes
+func (twoFactor TwoFactor) RecoveryCodeCount() int {
+	return len(twoFactor.RecoveryCodes)
+}
+
+/******************************************
+ * TOTP Methods
+ ******************************************/
+
+// BeginTOTP stores a new (pending) TOTP secret, which has already been sealed
+// by the caller.  It is not used to sign in until it is confirmed by EnableTOTP.
+func (twoFactor *TwoFactor) BeginTOTP(sealedSecret string) {
+	twoFactor.TOTPSecret = sealedSecret
+	twoFactor.TOTPEnabled = false
+	twoFactor.TOTPLastStep = 0
+}
+
+// EnableTOTP confirms a pending TOTP secret using a code from the User's
+// authenticator app.  The secret is the unsealed value of TOTPSecret.
+// It returns TRUE if the code was valid.
+func (twoFactor *TwoFactor) EnableTOTP(secret string, code string, now time.Time) bool {
+
+	if (twoFactor.TOTPSecret == "") || twoFactor.TOTPEnabled {
+		return false
+	}
+
+	step, ok := totp.Validate(secret, code, now, twoFactor.TOTPLastStep)
+
+	if !ok {
+		return false
+	}
+
+	twoFactor.TOTPEnabled = true
+	twoFactor.TOTPLastStep = step
+	return true
+}
+
+// DisableTOTP removes the User's TOTP secret
+func (twoFactor *TwoFactor) DisableTOTP() {
+	twoFactor.TOTPSecret = ""
+	twoFactor.TOTPEnabled = false
+	twoFactor.TOTPLastStep = 0
+}
+
+// ValidateTOTP returns TRUE if the code matches the User's confirmed TOTP secret.
+// The secret is the unsealed value of TOTPSecret.  Each code can only be used once,
+// so the User must be saved after a successful match.
+func (twoFactor *TwoFactor) ValidateTOTP(secret string, code string, now time.Time) bool {
+
+	if !twoFactor.TOTPEnabled {
+		return false
+	}
+
+	step, ok := totp.Validate(secret, code, now, twoFactor.TOTPLastStep)
+
+	if !ok {
+		return false
+	}
+
+	twoFactor.TOTPLastStep = step
+	return true
+}
+
+/******************************************
+ * Lockout Methods
+ ******************************************/
+
+// IsLocked returns TRUE if too many invalid codes have been entered recently
+func (twoFactor TwoFactor) IsLocked(now time.Time) bool {
+
+	if twoFactor.FailedCount < TwoFactorMaxAttempts {
+		return false
+	}
+
+	return now.Before(time.Unix(twoFactor.FailedAt, 0).Add(TwoFactorLockout))
+}
+
+// AddFailure records an invalid code.  Failures older than the lockout period
+// are forgotten, so the count only includes recent guesses.
+func (twoFactor *TwoFactor) AddFailure(now time.Time) {
+
+	if now.After(time.Unix(twoFactor.FailedAt, 0).Add(TwoFactorLockout)) {
+		twoFactor.FailedCount = 0
+	}
+
+	twoFactor.FailedCount++
+	twoFactor.FailedAt = now.Unix()
+}
+
+// ResetFailures clears the count of invalid codes after a second factor succeeds
+func (twoFactor *TwoFactor) ResetFailures() {
+	twoFactor.FailedCount = 0
+	twoFactor.FailedAt = 0
+}
+
+/******************************************
+ * Recovery Code Methods
+ ******************************************/
+
+// NewRecoveryCodes replaces all recovery codes with a new set.  The plaintext codes
+// are returned so that they can be displayed to the User (once) and only their
+// hashes are stored.
+func (twoFactor *TwoFactor) NewRecoveryCodes() (sliceof.String, error) {
+
+	plaintext := make(sliceof.String, TwoFactorRecoveryCodeCount)
+	hashes := make(sliceof.String, TwoFactorRecoveryCodeCount)
+
+	for index := range plaintext {
+
+		code, err := random.GenerateBytes(5)
+
+		if err != nil {
+			return nil, derp.Wrap(err, "model.TwoFactor.NewRecoveryCodes", "Error generating recovery code")
+		}
+
+		value := hex.EncodeToString(code)
+		plaintext[index] = value[:5] + "-" + value[5:]
+		hashes[index] = hashRecoveryCode(plaintext[index])
+	}
+
+	twoFactor.RecoveryCodes = hashes
+	return plaintext, nil
+}
+
+// UseRecoveryCode returns TRUE if the code matches one of the User's unused
+// recovery codes.  Matching codes are removed, so the User must be saved
+// after a successful match.
+func (twoFactor *TwoFactor) UseRecoveryCode(code string) bool {
+
+	hash := hashRecoveryCode(code)
+
+	for index, existing := range twoFactor.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(existing), []byte(hash)) == 1 {
+			twoFactor.RecoveryCodes = append(twoFactor.RecoveryCodes[:index], twoFactor.RecoveryCodes[index+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// hashRecoveryCode normalizes and hashes a recovery code for storage
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
+
+/******************************************
+ * Passkey Methods
+ ******************************************/
+
+// Passkey returns the passkey with the provided credential ID
+func (twoFactor *TwoFactor) Passkey(credentialID string) (*Passkey, bool) {
+
+	for index := range twoFactor.Passkeys {
+		if twoFactor.Passkeys[index].CredentialID == credentialID {
+			return &twoFactor.Passkeys[index], true
+		}
+	}
+
+	return nil, false
+}
+
+// AddPasskey adds a new passkey to the User's list of credentials
+func (twoFactor *TwoFactor) AddPasskey(passkey Passkey) {
+	twoFactor.Passkeys = append(twoFactor.Passkeys, passkey)
+}
+
+// RemovePasskey removes the passkey with the provided credential ID.
+// It returns TRUE if a passkey was removed.
+func (twoFactor *TwoFactor) RemovePasskey(credentialID string) bool {
+
+	for index := range twoFactor.Passkeys {
+		if twoFactor.Passkeys[index].CredentialID == credentialID {
+			twoFactor.Passkeys = append(twoFactor.Passkeys[:index], twoFactor.Passkeys[index+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TwoFactorChallengePurposeSignIn identifies a challenge issued after a valid password,
+// which must be completed with a second factor before the User is signed in.
+const TwoFactorChallengePurposeSignIn = "signin"
+
+// TwoFactorChallengePurposePasskey identifies a challenge for a passwordless passkey sign-in.
+const TwoFactorChallengePurposePasskey = "passkey"
+
+// TwoFactorChallengePurposeRegister identifies a challenge for registering a new passkey.
+const TwoFactorChallengePurposeRegister = "register"
+
+// TwoFactorChallengeDuration is the amount of time that a challenge is valid
+const TwoFactorChallengeDuration = 5 * time.Minute
+
+// TwoFactorChallenge is a short-lived, signed token that tracks a sign-in (or passkey
+// registration) that is in progress.  Its claim names do not overlap with Authorization,
+// so it can never be mistaken for a signed-in session.
+type TwoFactorChallenge struct {
+	Purpose string                `json:"twoFactorPurpose"`
+	UserID  primitive.ObjectID    `json:"twoFactorUserId,omitempty"`
+	Next    string                `json:"twoFactorNext,omitempty"`
+	Session *webauthn.SessionData `json:"twoFactorSession,omitempty"`
+
+	jwt.RegisteredClaims
+}
+
+// NewTwoFactorChallenge returns a fully initialized TwoFactorChallenge
+func NewTwoFactorChallenge(purpose string, userID primitive.ObjectID, next string) TwoFactorChallenge {
+	return TwoFactorChallenge{
+		Purpose: purpose,
+		UserID:  userID,
+		Next:    next,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TwoFactorChallengeDuration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+}
@@ -0,0 +1,168 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmissarySocial/emissary/tools/totp"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTwoFactor_TOTP(t *testing.T) {
+
+	twoFactor := TwoFactor{}
+	now := time.Now()
+
+	secret, err := totp.NewSecret()
+	require.Nil(t, err)
+
+	require.False(t, twoFactor.IsEnabled())
+	twoFactor.BeginTOTP("SEALED")
+	require.Equal(t, "SEALED", twoFactor.TOTPSecret)
+
+	// Pending secrets cannot be used to sign in
+	code, err := totp.Code(secret, totp.Step(now))
+	require.Nil(t, err)
+	require.False(t, twoFactor.ValidateTOTP(secret, code, now))
+	require.False(t, twoFactor.IsEnabled())
+
+	// Confirm the secret
+	require.False(t, twoFactor.EnableTOTP(secret, "000000"+code, now))
+	require.True(t, twoFactor.EnableTOTP(secret, code, now))
+	require.True(t, twoFactor.IsEnabled())
+
+	// The confirmation code cannot be replayed
+	require.False(t, twoFactor.ValidateTOTP(secret, code, now))
+
+	// The next code is accepted once
+	next, _ := totp.Code(secret, totp.Step(now)+1)
+	require.True(t, twoFactor.ValidateTOTP(secret, next, now))
+	require.False(t, twoFactor.ValidateTOTP(secret, next, now))
+
+	twoFactor.DisableTOTP()
+	require.False(t, twoFactor.IsEnabled())
+	require.Equal(t, "", twoFactor.TOTPSecret)
+}
+
+func TestTwoFactor_Lockout(t *testing.T) {
+
+	twoFactor := TwoFactor{}
+	now := time.Now()
+
+	for range TwoFactorMaxAttempts - 1 {
+		twoFactor.AddFailure(now)
+	}
+
+	require.False(t, twoFactor.IsLocked(now))
+
+	twoFactor.AddFailure(now)
+	require.True(t, twoFactor.IsLocked(now))
+	require.True(t, twoFactor.IsLocked(now.Add(TwoFactorLockout-time.Second)))
+
+	// The lockout expires by itself
+	later := now.Add(TwoFactorLockout + time.Second)
+	require.False(t, twoFactor.IsLocked(later))
+
+	// ...and old failures are forgotten
+	twoFactor.AddFailure(later)
+	require.Equal(t, 1, twoFactor.FailedCount)
+
+	// A valid second factor clears all failures
+	twoFactor.ResetFailures()
+	require.Equal(t, 0, twoFactor.FailedCount)
+	require.False(t, twoFactor.IsLocked(later))
+}
+
+func TestTwoFactor_RecoveryCodes(t *testing.T) {
+
+	twoFactor := TwoFactor{}
+	codes, err := twoFactor.NewRecoveryCodes()
+
+	require.Nil(t, err)
+	require.Equal(t, TwoFactorRecoveryCodeCount, len(codes))
+	require.Equal(t, TwoFactorRecoveryCodeCount, twoFactor.RecoveryCodeCount())
+
+	// Plaintext codes are never stored
+	for _, code := range codes {
+		require.NotContains(t, twoFactor.RecoveryCodes, code)
+	}
+
+	// Codes are accepted once, ignoring case and formatting
+	require.True(t, twoFactor.UseRecoveryCode(" "+strings.ToUpper(codes[0])+" "))
+	require.False(t, twoFactor.UseRecoveryCode(codes[0]))
+	require.True(t, twoFactor.UseRecoveryCode(strings.ReplaceAll(codes[1], "-", "")))
+	require.False(t, twoFactor.UseRecoveryCode("nope"))
+	require.Equal(t, TwoFactorRecoveryCodeCount-2, twoFactor.RecoveryCodeCount())
+
+	// Regenerating codes invalidates the old ones
+	_, err = twoFactor.NewRecoveryCodes()
+	require.Nil(t, err)
+	require.False(t, twoFactor.UseRecoveryCode(codes[2]))
+}
+
+func TestTwoFactor_Passkeys(t *testing.T) {
+
+	credential := webauthn.Credential{
+		ID:        []byte{1, 2, 3, 4},
+		PublicKey: []byte{5, 6, 7, 8},
+		Transport: nil,
+		Flags:     webauthn.CredentialFlags{BackupEligible: true},
+		Authenticator: webauthn.Authenticator{
+			SignCount: 7,
+		},
+	}
+
+	user := NewUser()
+	user.TwoFactor.AddPasskey(NewPasskey(&credential, "Laptop"))
+	require.True(t, user.TwoFactor.IsEnabled())
+
+	// Passkeys convert back into WebAuthn credentials
+	credentials := user.WebAuthnCredentials()
+	require.Equal(t, 1, len(credentials))
+	require.Equal(t, credential.ID, credentials[0].ID)
+	require.Equal(t, credential.PublicKey, credentials[0].PublicKey)
+	require.True(t, credentials[0].Flags.BackupEligible)
+	require.Equal(t, uint32(7), credentials[0].Authenticator.SignCount)
+
+	// Look up passkeys by credential ID
+	passkey, ok := user.TwoFactor.Passkey(PasskeyCredentialID(credential.ID))
+	require.True(t, ok)
+	require.Equal(t, "Laptop", passkey.Label)
+
+	require.False(t, user.TwoFactor.RemovePasskey("missing"))
+	require.True(t, user.TwoFactor.RemovePasskey(passkey.CredentialID))
+	require.False(t, user.TwoFactor.IsEnabled())
+}
+
+func TestUser_WebAuthnID(t *testing.T) {
+	user := NewUser()
+	require.Equal(t, user.UserID, primitive.ObjectID(user.WebAuthnID()))
+}
+
+func TestDomain_RequiresTwoFactor(t *testing.T) {
+
+	groupID := primitive.NewObjectID()
+	domain := NewDomain()
+
+	owner := NewUser()
+	owner.IsOwner = true
+
+	member := NewUser()
+	member.AddGroup(groupID)
+
+	other := NewUser()
+
+	require.False(t, domain.RequiresTwoFactor(&owner))
+	require.False(t, domain.RequiresTwoFactor(&member))
+
+	domain.TwoFactorOwners = true
+	require.True(t, domain.RequiresTwoFactor(&owner))
+	require.False(t, domain.RequiresTwoFactor(&member))
+
+	domain.TwoFactorGroups = []primitive.ObjectID{groupID}
+	require.True(t, domain.RequiresTwoFactor(&member))
+	require.False(t, domain.RequiresTwoFactor(&other))
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/benpate/rosetta/mapof"
 	"github.com/benpate/rosetta/sliceof"
 	"github.com/benpate/toot/object"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -36,15 +37,17 @@ type User struct {
 	Hashtags        sliceof.String             `json:"hashtags"        bson:"hashtags"`             // Slice of tags that can be used to categorize this user.
 	Links           sliceof.Object[PersonLink] `json:"links"           bson:"links"`                // Slice of links to profiles on other web services.
 	PasswordReset   PasswordReset              `json:"-"               bson:"passwordReset"`        // Most recent password reset information.
+	TwoFactor       TwoFactor                  `json:"-"               bson:"twoFactor"`            // Second-factor authentication settings (TOTP, recovery codes, and passkeys)
 	Data            mapof.String               `json:"data"            bson:"data"`                 // Custom profile data that can be stored with this User.
 	journal.Journal `json:"-" bson:",inline"`
 
-	FollowerCount  int  `json:"followerCount"   bson:"followerCount"`  // Number of followers for this user
-	FollowingCount int  `json:"followingCount"  bson:"followingCount"` // Number of actors that this user is following
-	RuleCount      int  `json:"ruleCount"       bson:"ruleCount"`      // Number of rules (blocks) that this user has implemented
-	IsOwner        bool `json:"isOwner"         bson:"isOwner"`        // If TRUE, then this user is a website owner with FULL privileges.
-	IsPublic       bool `json:"isPublic"        bson:"isPublic"`       // If TRUE, then this user's profile is publicly visible
-	IsIndexable    bool `json:"isIndexable"     bson:"isIndexable"`    // If TRUE, then this user's profile can be indexed by search engines.
+	FollowerCount  int   `json:"followerCount"   bson:"followerCount"`  // Number of followers for this user
+	FollowingCount int   `json:"followingCount"  bson:"followingCount"` // Number of actors that this user is following
+	RuleCount      int   `json:"ruleCount"       bson:"ruleCount"`      // Number of rules (blocks) that this user has implemented
+	StorageUsed    int64 `json:"storageUsed"     bson:"storageUsed"`    // Number of bytes used by this user's uploaded attachments
+	IsOwner        bool  `json:"isOwner"         bson:"isOwner"`        // If TRUE, then this user is a website owner with FULL privileges.
+	IsPublic       bool  `json:"isPublic"        bson:"isPublic"`       // If TRUE, then this user's profile is publicly visible
+	IsIndexable    bool  `json:"isIndexable"     bson:"isIndexable"`    // If TRUE, then this user's profile can be indexed by search engines.
 }
 
 // NewUser returns a fully initialized User object.
@@ -161,6 +164,37 @@ func (user *User) Claims() jwt.Claims {
 	return result
 }
 
+/******************************************
+ * WebAuthn Interface
+ ******************************************/
+
+// WebAuthnID returns the user handle that identifies this User to WebAuthn authenticators
+func (user *User) WebAuthnID() []byte {
+	return id.ToBytes(user.UserID)
+}
+
+// WebAuthnName returns the account name displayed by WebAuthn authenticators
+func (user *User) WebAuthnName() string {
+	return user.Username
+}
+
+// WebAuthnDisplayName returns the human-friendly name displayed by WebAuthn authenticators
+func (user *User) WebAuthnDisplayName() string {
+	return user.DisplayName
+}
+
+// WebAuthnCredentials returns all of the passkeys registered by this User
+func (user *User) WebAuthnCredentials() []webauthn.Credential {
+
+	result := make([]webauthn.Credential, len(user.TwoFactor.Passkeys))
+
+	for index, passkey := range user.TwoFactor.Passkeys {
+		result[index] = passkey.Credential()
+	}
+
+	return result
+}
+
 /******************************************
  * RoleStateEnumerator Interface
  ******************************************/
@@ -237,6 +271,9 @@ func (user User) GetJSONLD() mapof.Any {
 		vocab.PropertyFollowing:         user.ActivityPubFollowingURL(),
 		vocab.PropertyFollowers:         user.ActivityPubFollowersURL(),
 		vocab.PropertyLiked:             user.ActivityPubLikedURL(),
+		vocab.PropertyEndpoints: mapof.Any{
+			PropertySharedInbox: user.ActivityPubSharedInboxURL(),
+		},
 		// TODO: Revisit FEP-c648
 		// https://codeberg.org/fediverse/fep/src/branch/main/fep/c648/fep-c648.md
 		// vocab.PropertyBlocked:           user.ActivityPubBlockedURL(), // Temporarily removed because of problems with Mastodon parsing JSON-LD.  Maybe this is it?
@@ -301,6 +338,10 @@ func (user *User) ActivityPubInboxURL() string {
 	return user.ProfileURL + "/pub/inbox"
 }
 
+func (user *User) ActivityPubSharedInboxURL() string {
+	return SharedInboxURL(user.ProfileURL)
+}
+
 func (user *User) ActivityPubFollowersURL() string {
 	if user.ProfileURL == "" {
 		return ""
@@ -32,6 +32,7 @@ func UserSchema() schema.Element {
 			"followerCount":  schema.Integer{},
 			"followingCount": schema.Integer{},
 			"ruleCount":      schema.Integer{},
+			"storageUsed":    schema.Integer{BitSize: 64},
 			"isPublic":       schema.Boolean{},
 			"isOwner":        schema.Boolean{},
 			"isIndexable":    schema.Boolean{},
@@ -76,6 +77,9 @@ func (user *User) GetPointer(name string) (any, bool) {
 	case "ruleCount":
 		return &user.RuleCount, true
 
+	case "storageUsed":
+		return &user.StorageUsed, true
+
 	case "displayName":
 		return &user.DisplayName, true
 
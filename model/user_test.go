@@ -33,6 +33,7 @@ func TestUserSchema(t *testing.T) {
 		{"followerCount", "1", 1},
 		{"followingCount", "2", 2},
 		{"ruleCount", "3", 3},
+		{"storageUsed", "4096", int64(4096)},
 		{"isPublic", "true", true},
 		{"isOwner", "true", true},
 		{"isIndexable", "true", true},
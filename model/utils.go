@@ -1,6 +1,7 @@
 package model
 
 import (
+	"net/url"
 	"strings"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -28,3 +29,18 @@ func ToToken(value string) string {
 
 	return result.String()
 }
+
+// SharedInboxURL returns the URL of the domain-wide shared inbox
+// that receives ActivityPub messages on behalf of the provided
+// (local) actor URL.  It returns an empty string if the actor URL
+// cannot be parsed.
+func SharedInboxURL(actorURL string) string {
+
+	parsedURL, err := url.Parse(actorURL)
+
+	if err != nil || parsedURL.Host == "" {
+		return ""
+	}
+
+	return parsedURL.Scheme + "://" + parsedURL.Host + "/.inbox"
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EmissarySocial/emissary/config"
+	"github.com/EmissarySocial/emissary/server"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/stretchr/testify/require"
+)
+
+// testStorage is an in-memory config.Storage that publishes a single configuration
+type testStorage struct {
+	updates chan config.Config
+	mutex   sync.Mutex
+	written config.Config
+}
+
+func newTestStorage(value config.Config) *testStorage {
+	result := &testStorage{
+		updates: make(chan config.Config, 1),
+	}
+	result.updates <- value
+	return result
+}
+
+func (storage *testStorage) Subscribe() <-chan config.Config {
+	return storage.updates
+}
+
+func (storage *testStorage) Write(value config.Config) error {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+	storage.written = value
+	return nil
+}
+
+// newTestProvisioning returns a provisioning API backed by an in-memory server configuration
+func newTestProvisioning(t *testing.T, token string, domains ...config.Domain) (*server.Factory, *testStorage, http.Handler) {
+
+	value := config.NewConfig()
+	value.ProvisioningToken = token
+	value.AttachmentOriginals = testFolder(t)
+	value.AttachmentCache = testFolder(t)
+	value.ExportCache = testFolder(t)
+
+	for _, domain := range domains {
+		value.Domains.Put(domain)
+	}
+
+	storage := newTestStorage(value)
+	factory := server.NewFactory(storage, embed.FS{})
+
+	select {
+	case <-factory.Ready():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Factory did not load its configuration")
+	}
+
+	return factory, storage, makeProvisioningRoutes(factory)
+}
+
+// testDomain returns a domain configuration whose database is unreachable.
+// The domain is configured, but cannot be served, so these tests run without external services.
+func testDomain(domainID string, hostname string) config.Domain {
+	result := config.NewDomain()
+	result.DomainID = domainID
+	result.Label = "Test Domain"
+	result.Hostname = hostname
+	result.ConnectString = "mongodb://127.0.0.1:1/?serverSelectionTimeoutMS=50&connectTimeoutMS=50"
+	result.DatabaseName = domainID
+	return result
+}
+
+// testFolder returns a temporary FILE folder configuration
+func testFolder(t *testing.T) mapof.String {
+	return mapof.String{
+		"adapter":  config.FolderAdapterFile,
+		"location": t.TempDir(),
+	}
+}
+
+// testProvisioningRequest sends a single request to the provisioning API
+func testProvisioningRequest(api http.Handler, token string, method string, path string, body string) *httptest.ResponseRecorder {
+
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	api.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestProvisioning_Disabled(t *testing.T) {
+
+	_, _, api := newTestProvisioning(t, "")
+
+	response := testProvisioningRequest(api, "anything", http.MethodGet, "/.provisioning/domains", "")
+	require.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestProvisioning_Token(t *testing.T) {
+
+	_, _, api := newTestProvisioning(t, "correct-token")
+
+	// Missing token
+	response := testProvisioningRequest(api, "", http.MethodGet, "/.provisioning/domains", "")
+	require.Equal(t, http.StatusUnauthorized, response.Code)
+	require.Contains(t, response.Header().Get("WWW-Authenticate"), "Bearer")
+
+	// Wrong token
+	response = testProvisioningRequest(api, "wrong-token", http.MethodGet, "/.provisioning/domains", "")
+	require.Equal(t, http.StatusUnauthorized, response.Code)
+
+	// Correct token
+	response = testProvisioningRequest(api, "correct-token", http.MethodGet, "/.provisioning/domains", "")
+	require.Equal(t, http.StatusOK, response.Code)
+	require.JSONEq(t, "[]", response.Body.String())
+}
+
+func TestProvisioning_Create(t *testing.T) {
+
+	const token = "correct-token"
+	_, storage, api := newTestProvisioning(t, token, testDomain("existing-domain", "existing.example.com"))
+
+	create := func(domainID string, hostname string, connectString string) int {
+		body := `{"domainId":"` + domainID + `","label":"New Domain","hostname":"` + hostname + `","connectString":"` + connectString + `","databaseName":"new"}`
+		return testProvisioningRequest(api, token, http.MethodPost, "/.provisioning/domains", body).Code
+	}
+
+	// Required fields are validated
+	require.Equal(t, http.StatusBadRequest, create("new-domain", "new.example.com", ""))
+	require.Equal(t, http.StatusBadRequest, create("new-domain", "", "mongodb://localhost"))
+
+	// DomainIDs and hostnames must be unique
+	require.Equal(t, http.StatusConflict, create("existing-domain", "new.example.com", "mongodb://localhost"))
+	require.Equal(t, http.StatusConflict, create("new-domain", "existing.example.com", "mongodb://localhost"))
+
+	// Rejected domains are never written to the configuration
+	require.Len(t, storage.written.Domains, 0)
+}
+
+func TestProvisioning_Suspend(t *testing.T) {
+
+	const token = "correct-token"
+	factory, storage, api := newTestProvisioning(t, token, testDomain("test-domain", "test.example.com"))
+
+	// Suspend the domain
+	response := testProvisioningRequest(api, token, http.MethodPost, "/.provisioning/domains/test-domain/suspend", "")
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	report := server.DomainReport{}
+	require.Nil(t, json.Unmarshal(response.Body.Bytes(), &report))
+	require.Equal(t, "test-domain", report.DomainID)
+	require.True(t, report.Suspended)
+	require.False(t, report.Running)
+
+	// Suspended domains are saved, and are no longer served
+	suspended, _ := storage.written.Domains.Get("test-domain")
+	require.True(t, suspended.Suspended)
+
+	_, err := factory.ByDomainName("test.example.com")
+	require.Equal(t, http.StatusServiceUnavailable, derp.ErrorCode(err))
+
+	// Unknown domains are not found
+	response = testProvisioningRequest(api, token, http.MethodPost, "/.provisioning/domains/missing-domain/suspend", "")
+	require.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestProvisioning_Report(t *testing.T) {
+
+	const token = "correct-token"
+	_, _, api := newTestProvisioning(t, token,
+		testDomain("first-domain", "first.example.com"),
+		testDomain("second-domain", "second.example.com"),
+	)
+
+	// Summary of every domain
+	response := testProvisioningRequest(api, token, http.MethodGet, "/.provisioning/domains", "")
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NotContains(t, response.Body.String(), "keyEncryptingKey")
+
+	reports := []server.DomainReport{}
+	require.Nil(t, json.Unmarshal(response.Body.Bytes(), &reports))
+	require.Len(t, reports, 2)
+
+	// Report for a single domain
+	response = testProvisioningRequest(api, token, http.MethodGet, "/.provisioning/domains/second-domain", "")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	report := server.DomainReport{}
+	require.Nil(t, json.Unmarshal(response.Body.Bytes(), &report))
+	require.Equal(t, "second-domain", report.DomainID)
+	require.Equal(t, "second.example.com", report.Hostname)
+	require.False(t, report.Suspended)
+
+	// Unknown domains are not found
+	response = testProvisioningRequest(api, token, http.MethodGet, "/.provisioning/domains/missing-domain", "")
+	require.Equal(t, http.StatusNotFound, response.Code)
+}
@@ -0,0 +1,58 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/benpate/data"
+	mongodb "github.com/benpate/data-mongo"
+	"github.com/benpate/derp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DatabaseStats contains the storage statistics reported by MongoDB for a single database
+type DatabaseStats struct {
+	Collections int64 `bson:"collections"` // Number of collections in the database
+	Objects     int64 `bson:"objects"`     // Number of documents in the database
+	DataSize    int64 `bson:"dataSize"`    // Uncompressed size of all documents (in bytes)
+	StorageSize int64 `bson:"storageSize"` // Space allocated for documents on disk (in bytes)
+	IndexSize   int64 `bson:"indexSize"`   // Space allocated for indexes on disk (in bytes)
+}
+
+// GetDatabaseStats returns the storage statistics for the database behind a data.Session
+func GetDatabaseStats(ctx context.Context, session data.Session) (DatabaseStats, error) {
+
+	const location = "queries.GetDatabaseStats"
+
+	result := DatabaseStats{}
+
+	// Guarantee that we're using MongoDB
+	database := mongoDatabase(session)
+
+	if database == nil {
+		return result, derp.NewInternalError(location, "Database must be MongoDB")
+	}
+
+	// scale=1 reports all sizes in bytes
+	if err := database.RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}, {Key: "scale", Value: 1}}).Decode(&result); err != nil {
+		return result, derp.Wrap(err, location, "Error reading database statistics", database.Name())
+	}
+
+	return result, nil
+}
+
+// mongoDatabase unwraps a data.Session as the underlying mongo.Database.
+func mongoDatabase(original data.Session) *mongo.Database {
+
+	switch orig := original.(type) {
+
+	case mongodb.Session:
+		return orig.Mongo()
+
+	case *mongodb.Session:
+		return orig.Mongo()
+
+	default:
+		return nil
+	}
+}
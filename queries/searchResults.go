@@ -0,0 +1,175 @@
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/data"
+	mongodb "github.com/benpate/data-mongo"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+	"github.com/benpate/rosetta/sliceof"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SearchResultsTextIndex is the name of the weighted text index on the SearchResult collection.
+// Change this name whenever the index weights change, so that the index is rebuilt.
+const SearchResultsTextIndex = "searchResult_text_v1"
+
+// SearchResults_EnsureTextIndex creates the weighted text index on the SearchResult collection,
+// replacing any other text index (MongoDB only allows one text index per collection)
+func SearchResults_EnsureTextIndex(collection data.Collection, weights map[string]int) error {
+
+	const location = "queries.SearchResults_EnsureTextIndex"
+
+	m := mongoCollection(collection)
+
+	if m == nil {
+		return derp.NewInternalError(location, "Database must be MongoDB")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	indexes := m.Indexes()
+	specifications, err := indexes.ListSpecifications(ctx)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error listing indexes")
+	}
+
+	for _, specification := range specifications {
+
+		if specification.Name == SearchResultsTextIndex {
+			return nil
+		}
+
+		// Remove outdated text indexes
+		if _, isText := specification.KeysDocument.Lookup("_fts").StringValueOK(); isText {
+			if _, err := indexes.DropOne(ctx, specification.Name); err != nil {
+				return derp.Wrap(err, location, "Error removing outdated text index", specification.Name)
+			}
+		}
+	}
+
+	keys := bson.D{}
+	weightsBSON := bson.M{}
+
+	for field, weight := range weights {
+		keys = append(keys, bson.E{Key: field, Value: "text"})
+		weightsBSON[field] = weight
+	}
+
+	indexModel := mongo.IndexModel{
+		Keys: keys,
+		Options: options.Index().
+			SetName(SearchResultsTextIndex).
+			SetWeights(weightsBSON).
+			SetDefaultLanguage("english"),
+	}
+
+	if _, err := indexes.CreateOne(ctx, indexModel); err != nil {
+		return derp.Wrap(err, location, "Error creating text index")
+	}
+
+	return nil
+}
+
+// SearchResults_ByScore returns the SearchResults that match the criteria (which must include
+// a $fullText predicate) sorted by their MongoDB text score, then by rank.
+func SearchResults_ByScore(collection data.Collection, criteria exp.Expression, fields []string, offset int64, maxRows int64) ([]model.SearchResult, error) {
+
+	const location = "queries.SearchResults_ByScore"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	projection := bson.M{"score": 1}
+	for _, field := range fields {
+		projection[field] = 1
+	}
+
+	stages := bson.A{
+		bson.M{"$match": mongodb.ExpressionToBSON(criteria)},
+		bson.M{"$addFields": bson.M{"score": bson.M{"$meta": "textScore"}}},
+		bson.M{"$sort": bson.D{{Key: "score", Value: -1}, {Key: "rank", Value: 1}}},
+	}
+
+	if offset > 0 {
+		stages = append(stages, bson.M{"$skip": offset})
+	}
+
+	if maxRows > 0 {
+		stages = append(stages, bson.M{"$limit": maxRows})
+	}
+
+	stages = append(stages, bson.M{"$project": projection})
+
+	// Scores are not part of the SearchResult record, so read them separately
+	scored := make([]struct {
+		model.SearchResult `bson:",inline"`
+		Score              float64 `bson:"score"`
+	}, 0)
+
+	if err := pipeline(ctx, collection, &scored, stages); err != nil {
+		return nil, derp.Wrap(err, location, "Error querying SearchResults", criteria)
+	}
+
+	result := make([]model.SearchResult, len(scored))
+
+	for index, item := range scored {
+		result[index] = item.SearchResult
+		result[index].Score = item.Score
+	}
+
+	return result, nil
+}
+
+// SearchResults_Facets counts the number of SearchResults that match the criteria,
+// grouped by each value of the requested fields.  Array fields (like tagValues) are
+// counted once for each value in the array.
+func SearchResults_Facets(collection data.Collection, criteria exp.Expression, fields []string, maxValues int) (map[string]sliceof.Object[model.SearchFacet], error) {
+
+	const location = "queries.SearchResults_Facets"
+
+	result := make(map[string]sliceof.Object[model.SearchFacet], len(fields))
+
+	if len(fields) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	facets := bson.M{}
+
+	for _, field := range fields {
+		facets[field] = bson.A{
+			bson.M{"$unwind": "$" + field},
+			bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			bson.M{"$limit": maxValues},
+		}
+	}
+
+	stages := bson.A{
+		bson.M{"$match": mongodb.ExpressionToBSON(criteria)},
+		bson.M{"$facet": facets},
+	}
+
+	// $facet always returns a single document
+	documents := make([]map[string]sliceof.Object[model.SearchFacet], 0, 1)
+
+	if err := pipeline(ctx, collection, &documents, stages); err != nil {
+		return nil, derp.Wrap(err, location, "Error counting facets", criteria, fields)
+	}
+
+	if len(documents) > 0 {
+		result = documents[0]
+	}
+
+	return result, nil
+}
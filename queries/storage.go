@@ -0,0 +1,26 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/benpate/data"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// IncrementStorageUsed adds (or subtracts) a number of bytes from the "storageUsed" counter
+// of all records that match the criteria.  This is an atomic update, so it is safe to call
+// while other processes are uploading or deleting files.
+func IncrementStorageUsed(collection data.Collection, criteria exp.Expression, delta int64) error {
+
+	if delta == 0 {
+		return nil
+	}
+
+	if err := RawUpdate(context.Background(), collection, criteria, bson.M{"$inc": bson.M{"storageUsed": delta}}); err != nil {
+		return derp.Wrap(err, "queries.IncrementStorageUsed", "Error updating storage used", criteria, delta)
+	}
+
+	return nil
+}
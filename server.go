@@ -19,17 +19,21 @@ import (
 
 	"github.com/EmissarySocial/emissary/config"
 	"github.com/EmissarySocial/emissary/handler"
+	ap_search "github.com/EmissarySocial/emissary/handler/activitypub_search"
 	ap_stream "github.com/EmissarySocial/emissary/handler/activitypub_stream"
 	ap_user "github.com/EmissarySocial/emissary/handler/activitypub_user"
 	"github.com/EmissarySocial/emissary/handler/stripe"
 	"github.com/EmissarySocial/emissary/handler/unsplash"
 	mw "github.com/EmissarySocial/emissary/middleware"
 	"github.com/EmissarySocial/emissary/server"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/EmissarySocial/emissary/tools/ratelimit"
 	"github.com/benpate/derp"
 	"github.com/benpate/digital-dome/dome4echo"
 	"github.com/benpate/domain"
 	"github.com/benpate/form/widget"
 	"github.com/benpate/hannibal/vocab"
+	"github.com/benpate/rosetta/mapof"
 	"github.com/benpate/rosetta/slice"
 	"github.com/benpate/steranko"
 	"github.com/davecgh/go-spew/spew"
@@ -79,6 +83,12 @@ func main() {
 
 	// Locate the configuration file and populate the server factory
 	commandLineArgs := config.GetCommandLineArgs()
+
+	// Validate templates (offline) and exit
+	if commandLineArgs.ValidateTemplates != "" {
+		os.Exit(validateTemplates(commandLineArgs.ValidateTemplates))
+	}
+
 	configStorage := config.Load(&commandLineArgs)
 
 	factory := server.NewFactory(configStorage, embeddedFiles)
@@ -88,7 +98,11 @@ func main() {
 	e.Logger.SetLevel(gommonlog.OFF)
 	e.HideBanner = true
 	e.HidePort = true
-	e.HTTPErrorHandler = errorHandler
+	e.HTTPErrorHandler = makeErrorHandler(factory.Translation())
+
+	// Trust X-Forwarded-For headers only from proxies on private networks, so that
+	// ctx.RealIP() cannot be forged by clients
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
 
 	// Global middleware
 	// TODO: HIGH: Implement echo.Secure - https://echo.labstack.com/docs/middleware/secure
@@ -161,25 +175,61 @@ func makeSetupRoutes(factory *server.Factory, e *echo.Echo) {
 	// Setup Routes
 	e.GET("/", handler.SetupPageGet(factory, setupTemplates, "index.html"))
 	e.GET("/server", handler.SetupPageGet(factory, setupTemplates, "server.html"))
-	e.POST("/server", handler.SetupServerPost(factory))
+	e.POST("/server", handler.SetupServerPost(factory), mw.ReadOnlyConfig(factory))
 	e.GET("/server/:section", handler.SetupServerGet(factory))
-	e.POST("/server/:section", handler.SetupServerPost(factory))
+	e.POST("/server/:section", handler.SetupServerPost(factory), mw.ReadOnlyConfig(factory))
 	e.GET("/domains", handler.SetupPageGet(factory, setupTemplates, "domains.html"))
 	e.GET("/domains/:domain", handler.SetupDomainGet(factory))
-	e.POST("/domains/:domain", handler.SetupDomainPost(factory))
-	e.DELETE("/domains/:domain", handler.SetupDomainDelete(factory))
+	e.POST("/domains/:domain", handler.SetupDomainPost(factory), mw.ReadOnlyConfig(factory))
+	e.DELETE("/domains/:domain", handler.SetupDomainDelete(factory), mw.ReadOnlyConfig(factory))
 	e.POST("/domains/:domain/signin", handler.SetupDomainSigninPost(factory))
 	e.GET("/domains/:domain/users", handler.SetupDomainUsersGet(factory, setupTemplates))
 	e.POST("/domains/:domain/users", handler.SetupDomainUserPost(factory, setupTemplates))
 	e.POST("/domains/:domain/users/:user/invite", handler.SetupDomainUserInvite(factory, setupTemplates))
 	e.DELETE("/domains/:domain/users/:user", handler.SetupDomainUserDelete(factory, setupTemplates))
+	e.GET("/packages", handler.SetupPageGet(factory, setupTemplates, "packages.html"))
+	e.POST("/packages", handler.SetupPackagePost(factory), mw.ReadOnlyConfig(factory))
+	e.DELETE("/packages/:package", handler.SetupPackageDelete(factory), mw.ReadOnlyConfig(factory))
+	e.POST("/packages/:package/rollback", handler.SetupPackageRollback(factory), mw.ReadOnlyConfig(factory))
+	e.POST("/packages/:package/:version", handler.SetupPackageActivate(factory), mw.ReadOnlyConfig(factory))
+	e.DELETE("/packages/:package/:version", handler.SetupPackageVersionDelete(factory), mw.ReadOnlyConfig(factory))
 	e.GET("/oauth", handler.SetupOAuthList(factory, setupTemplates))
 	e.GET("/oauth/:provider", handler.SetupOAuthGet(factory, setupTemplates))
-	e.POST("/oauth/:provider", handler.SetupOAuthPost(factory, setupTemplates))
+	e.POST("/oauth/:provider", handler.SetupOAuthPost(factory, setupTemplates), mw.ReadOnlyConfig(factory))
 	e.GET("/.themes/:themeId/:bundleId", handler.GetThemeBundle(factory))
 	e.GET("/.themes/:themeId/resources/:filename", handler.GetThemeResource(factory))
 }
 
+// makeProvisioningRoutes generates a separate Echo instance for the JSON provisioning API
+func makeProvisioningRoutes(factory *server.Factory) *echo.Echo {
+
+	api := echo.New()
+	api.Logger.SetLevel(gommonlog.OFF)
+	api.HideBanner = true
+	api.HidePort = true
+	api.HTTPErrorHandler = provisioningErrorHandler
+
+	api.Use(middleware.Recover())
+	api.Use(mw.ProvisioningToken(factory))
+
+	api.GET("/.provisioning/domains", handler.GetProvisioningDomains(factory))
+	api.POST("/.provisioning/domains", handler.PostProvisioningDomain(factory))
+	api.GET("/.provisioning/domains/:domainId", handler.GetProvisioningDomain(factory))
+	api.PATCH("/.provisioning/domains/:domainId", handler.PatchProvisioningDomain(factory))
+	api.DELETE("/.provisioning/domains/:domainId", handler.DeleteProvisioningDomain(factory))
+	api.POST("/.provisioning/domains/:domainId/suspend", handler.PostProvisioningDomainSuspend(factory))
+	api.POST("/.provisioning/domains/:domainId/resume", handler.PostProvisioningDomainResume(factory))
+	api.GET("/.provisioning/packages", handler.GetProvisioningPackages(factory))
+	api.POST("/.provisioning/packages", handler.PostProvisioningPackage(factory))
+	api.GET("/.provisioning/packages/:packageId", handler.GetProvisioningPackage(factory))
+	api.DELETE("/.provisioning/packages/:packageId", handler.DeleteProvisioningPackage(factory))
+	api.POST("/.provisioning/packages/:packageId/activate", handler.PostProvisioningPackageActivate(factory))
+	api.POST("/.provisioning/packages/:packageId/rollback", handler.PostProvisioningPackageRollback(factory))
+	api.DELETE("/.provisioning/packages/:packageId/:version", handler.DeleteProvisioningPackageVersion(factory))
+
+	return api
+}
+
 // makeStandardRoutes generates a new Echo instance the primary server behavior
 func makeStandardRoutes(factory *server.Factory, e *echo.Echo) {
 
@@ -191,11 +241,31 @@ func makeStandardRoutes(factory *server.Factory, e *echo.Echo) {
 	e.Pre(mw.HttpsRedirect)
 	e.Pre(middleware.RemoveTrailingSlash())
 
+	// Provisioning API works on every hostname, so it is routed before the domain middleware
+	e.Pre(mw.Provisioning(makeProvisioningRoutes(factory)))
+
 	// Middleware for standard pages
 	// e.Use(mw.Debug()) <- this is super chatty, so only enable it on dev, or for short periods of time.
 	e.Use(mw.Domain(factory))
 	e.Use(steranko.Middleware(factory))
 	e.Use(middleware.CORS())
+	e.Use(mw.AuthorizedFetch(factory))
+
+	// Rate limits for routes that are commonly abused
+	signInLimit := mw.RateLimit(factory, config.RateLimitSignIn, ratelimit.ByIP(), ratelimit.ByFormValue("username"))
+	resetLimit := mw.RateLimit(factory, config.RateLimitPasswordReset, ratelimit.ByIP(), ratelimit.ByFormValue("emailAddress"), ratelimit.ByFormValue("userId"))
+	registrationLimit := mw.RateLimit(factory, config.RateLimitRegistration, ratelimit.ByIP())
+	webMentionLimit := mw.RateLimit(factory, config.RateLimitWebMention, ratelimit.ByIP())
+	webSubLimit := mw.RateLimit(factory, config.RateLimitWebSub, ratelimit.ByIP())
+	savedSearchLimit := mw.RateLimit(factory, config.RateLimitSavedSearch, ratelimit.ByIP())
+
+	// Inbox requests are counted by IP before their (expensive) signatures are verified,
+	// and then by the verified domain of the remote Actor.
+	inboxLimit := []echo.MiddlewareFunc{
+		mw.RateLimit(factory, config.RateLimitActivityPubIP, ratelimit.ByIP()),
+		mw.VerifyActor(factory),
+		mw.RateLimit(factory, config.RateLimitActivityPub, ratelimit.ByActorDomain(service.VerifiedActor)),
+	}
 
 	// TODO: Commonly accessed routest that we should serve
 	e.GET("/robots.txt", handler.TBD)                       // https://developers.google.com/search/docs/advanced/robots/create-robots-txt
@@ -223,6 +293,7 @@ func makeStandardRoutes(factory *server.Factory, e *echo.Echo) {
 	e.GET("/.well-known/nodeinfo", handler.GetNodeInfo(factory))
 	e.GET("/.well-known/nodeinfo/2.0", handler.GetNodeInfo20(factory))
 	e.GET("/.well-known/nodeinfo/2.1", handler.GetNodeInfo21(factory))
+	e.GET("/.well-known/oauth-authorization-server", handler.WithFactory(factory, handler.GetIndieAuthMetadata))
 	e.GET("/nodeinfo/2.0", handler.GetNodeInfo20(factory))
 	e.GET("/nodeinfo/2.0.json", handler.GetNodeInfo20(factory))
 	e.GET("/nodeinfo/2.1", handler.GetNodeInfo21(factory))
@@ -231,9 +302,24 @@ func makeStandardRoutes(factory *server.Factory, e *echo.Echo) {
 	// Built-In Service  Routes
 	e.POST("/.follower/new", handler.PostEmailFollower(factory))
 	e.GET("/.giphy", handler.GetGiphyWidget(factory))
+	e.POST("/.inbox", handler.PostSharedInbox(factory), inboxLimit...)
+	e.GET("/.micropub", handler.WithFactory(factory, handler.GetMicropub))
+	e.POST("/.micropub", handler.WithFactory(factory, handler.PostMicropub))
+	e.POST("/.micropub/media", handler.WithFactory(factory, handler.PostMicropubMedia))
 	e.GET("/.oembed", handler.WithFactory(factory, handler.GetOEmbed))
 	e.POST("/.stripe", stripe.PostWebhook(factory))
 	e.GET("/.searchTag/:searchTagId/attachments/:attachmentId", handler.WithFactory(factory, handler.GetSearchTagAttachment))
+	e.POST("/.searches", handler.WithFactory(factory, handler.PostSavedSearch), savedSearchLimit)
+	e.GET("/.searches/:savedSearchId", handler.WithFactory(factory, handler.GetSavedSearch))
+	e.GET("/.searches/:savedSearchId/feed", handler.WithFactory(factory, handler.GetSavedSearchFeed))
+	e.GET("/.searches/:savedSearchId/websub", handler.WithFactory(factory, handler.GetSavedSearchFeed))
+	e.POST("/.searches/:savedSearchId/websub", handler.WithFactory(factory, handler.PostSavedSearchWebSub), webSubLimit)
+	e.GET("/.searches/:savedSearchId/follower-confirm", handler.WithFactory(factory, handler.GetSavedSearchFollowerConfirm))
+	e.GET("/.searches/:savedSearchId/follower-unsubscribe", handler.WithFactory(factory, handler.GetSavedSearchFollowerUnsubscribe))
+	e.POST("/.searches/:savedSearchId/follower-unsubscribe", handler.WithFactory(factory, handler.PostSavedSearchFollowerUnsubscribe))
+	e.POST("/.searches/:savedSearchId/pub/inbox", ap_search.PostInbox(factory), inboxLimit...)
+	e.GET("/.searches/:savedSearchId/pub/outbox", handler.GetEmptyCollection(factory))
+	e.GET("/.searches/:savedSearchId/pub/followers", ap_search.GetFollowersCollection(factory))
 	e.GET("/.themes/:themeId/:bundleId", handler.GetThemeBundle(factory))
 	e.GET("/.themes/:themeId/resources/:filename", handler.GetThemeResource(factory))
 	e.GET("/.templates/:templateId/:bundleId", handler.GetTemplateBundle(factory))
@@ -241,9 +327,9 @@ func makeStandardRoutes(factory *server.Factory, e *echo.Echo) {
 	e.GET("/.unsplash/photos/:photo", unsplash.GetPhoto(factory))
 	e.GET("/.unsplash/collections/:collection/random", unsplash.GetCollectionRandom(factory))
 	e.GET("/.webmention", handler.TBD)
-	e.POST("/.webmention", handler.PostWebMention(factory))
+	e.POST("/.webmention", handler.PostWebMention(factory), webMentionLimit)
 	e.GET("/.websub/:userId/:followingId", handler.GetWebSubClient(factory))
-	e.POST("/.websub/:userId/:followingId", handler.PostWebSubClient(factory))
+	e.POST("/.websub/:userId/:followingId", handler.PostWebSubClient(factory), webSubLimit)
 	e.GET("/.widgets/:widgetId/:bundleId", handler.GetWidgetBundle(factory))
 	e.GET("/.widgets/:widgetId/resources/:filename", handler.GetWidgetResource(factory))
 
@@ -257,17 +343,25 @@ func makeStandardRoutes(factory *server.Factory, e *echo.Echo) {
 
 	// Authentication Pages
 	e.GET("/signin", handler.GetSignIn(factory))
-	e.POST("/signin", handler.PostSignIn(factory))
+	e.POST("/signin", handler.PostSignIn(factory), signInLimit)
+	e.GET("/signin/2fa", handler.WithFactory(factory, handler.GetSignInTwoFactor))
+	e.POST("/signin/2fa", handler.WithFactory(factory, handler.PostSignInTwoFactor), signInLimit)
+	e.POST("/signin/2fa/passkey", handler.WithFactory(factory, handler.PostSignInTwoFactorPasskey), signInLimit)
+	e.POST("/signin/2fa/passkey/finish", handler.WithFactory(factory, handler.PostSignInTwoFactorPasskeyFinish), signInLimit)
+	e.POST("/signin/passkey", handler.WithFactory(factory, handler.PostSignInPasskey), signInLimit)
+	e.POST("/signin/passkey/finish", handler.WithFactory(factory, handler.PostSignInPasskeyFinish), signInLimit)
+	e.GET("/oidc/signin", handler.WithFactory(factory, handler.GetSignInOIDC))
+	e.GET("/oidc/callback", handler.WithFactory(factory, handler.GetSignInOIDCCallback), signInLimit)
 	e.POST("/signout", handler.PostSignOut(factory))
 	e.GET("/register", handler.WithRegistration(factory, handler.GetRegister))
 	e.GET("/register/:action", handler.WithRegistration(factory, handler.GetRegister))
-	e.POST("/register", handler.WithRegistration(factory, handler.PostRegister))
+	e.POST("/register", handler.WithRegistration(factory, handler.PostRegister), registrationLimit)
 	e.GET("/register/complete", handler.WithRegistration(factory, handler.GetCompleteRegistration))
-	e.POST("/register/update", handler.WithRegistration(factory, handler.PostRegister))
+	e.POST("/register/update", handler.WithRegistration(factory, handler.PostRegister), registrationLimit)
 	e.GET("/signin/reset", handler.GetResetPassword(factory))
-	e.POST("/signin/reset", handler.PostResetPassword(factory))
+	e.POST("/signin/reset", handler.PostResetPassword(factory), resetLimit)
 	e.GET("/signin/reset-code", handler.GetResetCode(factory))
-	e.POST("/signin/reset-code", handler.PostResetCode(factory))
+	e.POST("/signin/reset-code", handler.PostResetCode(factory), resetLimit)
 	e.POST("/.masquerade", handler.PostMasquerade(factory), mw.Owner)
 	e.GET("/.sso", handler.WithDomain(factory, handler.GetSingleSignOn))
 
@@ -289,7 +383,7 @@ func makeStandardRoutes(factory *server.Factory, e *echo.Echo) {
 	// Profile Pages
 	// NOTE: these are rewritten from /@:userId by the rewrite middleware
 	e.GET("/@service", handler.GetServiceActor(factory))
-	e.POST("/@service/inbox", handler.PostServiceActor_Inbox(factory))
+	e.POST("/@service/inbox", handler.PostServiceActor_Inbox(factory), inboxLimit...)
 	e.GET("/@service/inbox", handler.GetEmptyCollection(factory))
 	e.GET("/@service/outbox", handler.GetEmptyCollection(factory))
 	e.GET("/@service/following", handler.GetEmptyCollection(factory))
@@ -309,6 +403,16 @@ func makeStandardRoutes(factory *server.Factory, e *echo.Echo) {
 	e.POST("/@me/intent/follow", handler.WithAuthenticatedUser(factory, handler.PostIntent_Follow))
 	e.GET("/@me/intent/like", handler.WithAuthenticatedUser(factory, handler.GetIntent_Like))
 	e.POST("/@me/intent/like", handler.WithAuthenticatedUser(factory, handler.PostIntent_Like))
+	e.GET("/@me/security", handler.WithAuthenticatedUser(factory, handler.GetSecurity))
+	e.POST("/@me/security/totp", handler.WithAuthenticatedUser(factory, handler.PostSecurityTOTP))
+	e.POST("/@me/security/totp/confirm", handler.WithAuthenticatedUser(factory, handler.PostSecurityTOTPConfirm))
+	e.POST("/@me/security/totp/disable", handler.WithAuthenticatedUser(factory, handler.PostSecurityTOTPDisable))
+	e.POST("/@me/security/recovery-codes", handler.WithAuthenticatedUser(factory, handler.PostSecurityRecoveryCodes))
+	e.POST("/@me/security/passkeys", handler.WithAuthenticatedUser(factory, handler.PostSecurityPasskey))
+	e.POST("/@me/security/passkeys/finish", handler.WithAuthenticatedUser(factory, handler.PostSecurityPasskeyFinish))
+	e.POST("/@me/security/passkeys/:passkeyId/delete", handler.WithAuthenticatedUser(factory, handler.PostSecurityPasskeyDelete))
+	e.POST("/@me/security/oidc", handler.WithAuthenticatedUser(factory, handler.PostSecurityOIDCLink))
+	e.POST("/@me/security/oidc/delete", handler.WithAuthenticatedUser(factory, handler.PostSecurityOIDCUnlink))
 
 	// ActivityPub Routes for Users
 	e.GET("/@:userId", handler.GetOutbox(factory))
@@ -317,7 +421,7 @@ func makeStandardRoutes(factory *server.Factory, e *echo.Echo) {
 	e.POST("/@:userId/:action", handler.PostOutbox(factory))
 	e.GET("/@:userId/attachments/:attachmentId", handler.GetUserAttachment(factory))
 	e.GET("/@:userId/pub", handler.GetOutbox(factory))
-	e.POST("/@:userId/pub/inbox", ap_user.PostInbox(factory))
+	e.POST("/@:userId/pub/inbox", ap_user.PostInbox(factory), inboxLimit...)
 	e.GET("/@:userId/pub/outbox", ap_user.GetOutboxCollection(factory))
 	e.GET("/@:userId/pub/followers", ap_user.GetFollowersCollection(factory))
 	e.GET("/@:userId/pub/following", ap_user.GetFollowingCollection(factory))
@@ -333,10 +437,13 @@ func makeStandardRoutes(factory *server.Factory, e *echo.Echo) {
 
 	// ActivityPub Routes for Streams
 	e.GET("/:stream/pub", ap_stream.GetJSONLD(factory))
-	e.POST("/:stream/pub/inbox", ap_stream.PostInbox(factory))
+	e.POST("/:stream/pub/inbox", ap_stream.PostInbox(factory), inboxLimit...)
 	e.GET("/:stream/pub/outbox", ap_stream.GetOutboxCollection(factory))
 	e.GET("/:stream/pub/followers", ap_stream.GetFollowersCollection(factory))
 	e.GET("/:stream/pub/children", handler.WithFactory(factory, ap_stream.GetChildrenCollection))
+	e.GET("/:stream/pub/replies", ap_stream.GetRepliesCollection(factory))
+	e.GET("/:stream/pub/likes", ap_stream.GetResponseCollection(factory, vocab.ActivityTypeLike))
+	e.GET("/:stream/pub/shares", ap_stream.GetResponseCollection(factory, vocab.ActivityTypeAnnounce))
 
 	// Domain Admin Pages
 	e.GET("/admin", handler.GetAdmin(factory), mw.Owner)
@@ -346,8 +453,16 @@ func makeStandardRoutes(factory *server.Factory, e *echo.Echo) {
 	e.POST("/admin/:param1/:param2", handler.PostAdmin(factory), mw.Owner)
 	e.GET("/admin/:param1/:param2/:param3", handler.GetAdmin(factory), mw.Owner)
 	e.POST("/admin/:param1/:param2/:param3", handler.PostAdmin(factory), mw.Owner)
+	e.GET("/admin/audit/export", handler.WithFactory(factory, handler.GetAuditLogExport), mw.Owner)
 	e.POST("/admin/index-all-streams", handler.WithFactory(factory, handler.IndexAllStreams), mw.Owner)
 	e.POST("/admin/index-all-users", handler.WithFactory(factory, handler.IndexAllUsers), mw.Owner)
+	e.POST("/admin/search-relays", handler.WithFactory(factory, handler.PostSearchRelay), mw.Owner)
+	e.POST("/admin/search-relays/remove", handler.WithFactory(factory, handler.PostSearchRelayRemove), mw.Owner)
+	e.POST("/admin/integration-secrets", handler.WithFactory(factory, handler.PostIntegrationSecret), mw.Owner)
+	e.POST("/admin/integration-secrets/remove", handler.WithFactory(factory, handler.PostIntegrationSecretRemove), mw.Owner)
+	e.POST("/admin/oidc-client-secret", handler.WithFactory(factory, handler.PostOIDCClientSecret), mw.Owner)
+	e.POST("/admin/blocklists/:blocklistId/sync", handler.WithFactory(factory, handler.PostBlocklistSync), mw.Owner)
+	e.POST("/admin/blocklists/:blocklistId/apply", handler.WithFactory(factory, handler.PostBlocklistApply), mw.Owner)
 
 	// OAuth Client Connections
 	e.GET("/oauth/clients/:provider", handler.GetOAuth(factory), mw.Owner)
@@ -361,7 +476,8 @@ func makeStandardRoutes(factory *server.Factory, e *echo.Echo) {
 
 	// OAuth Server
 	e.GET("/oauth/authorize", handler.GetOAuthAuthorization(factory), mw.Authenticated)
-	e.POST("/oauth/authorize", handler.PostOAuthAuthorization(factory), mw.Authenticated)
+	e.POST("/oauth/authorize", handler.PostOAuthAuthorization(factory)) // Not using mw.Authenticated because IndieAuth clients redeem profile codes here.  The handler checks authentication for new authorizations.
+	e.POST("/oauth/introspect", handler.WithFactory(factory, handler.PostOAuthIntrospect))
 	e.POST("/oauth/token", handler.PostOAuthToken(factory))
 	e.POST("/oauth/revoke", handler.PostOAuthRevoke(factory))
 
@@ -394,6 +510,21 @@ func openLocalhostBrowser(factory *server.Factory, options ...config.Option) {
 	}
 }
 
+// hostPolicy allows TLS certificates for every active, non-local domain in the current configuration
+func hostPolicy(factory *server.Factory) autocert.HostPolicy {
+
+	return func(_ context.Context, host string) error {
+
+		for _, domainConfig := range factory.Config().Domains {
+			if (domainConfig.Hostname == host) && !domainConfig.Suspended && domain.NotLocalhost(host) {
+				return nil
+			}
+		}
+
+		return derp.NewForbiddenError("main.hostPolicy", "Host not configured", host)
+	}
+}
+
 // startHTTP starts the HTTPS server using Let's Encrypt SSL certificates.
 // If the configured port is not available, it will wait one second and retry until it is
 func startHTTPS(factory *server.Factory, e *echo.Echo, options ...config.Option) {
@@ -413,9 +544,11 @@ func startHTTPS(factory *server.Factory, e *echo.Echo, options ...config.Option)
 			return
 		}
 
-		// Initialize Let's Encrypt autocert for TLS certificates
+		// Initialize Let's Encrypt autocert for TLS certificates.
+		// The host policy reads the current configuration so that
+		// domains added by the provisioning API receive certificates too.
 		e.AutoTLSManager = autocert.Manager{
-			HostPolicy: autocert.HostWhitelist(domains...),
+			HostPolicy: hostPolicy(factory),
 			Cache:      autocert.DirCache(config.Certificates["location"]),
 			Prompt:     autocert.AcceptTOS,
 			Email:      config.AdminEmail,
@@ -488,18 +621,52 @@ func gracefulShutdown(e *echo.Echo) {
 	}
 }
 
+// provisioningErrorHandler reports errors from the provisioning API as JSON
+func provisioningErrorHandler(err error, ctx echo.Context) {
+
+	// Route errors (like 404 and 405) come from echo itself
+	if httpError, ok := err.(*echo.HTTPError); ok {
+		_ = ctx.JSON(httpError.Code, mapof.Any{"error": httpError.Message})
+		return
+	}
+
+	errorCode := derp.ErrorCode(err)
+
+	// Client errors report the original cause (like a validation failure) so that it can be fixed
+	if derp.IsClientError(err) {
+		_ = ctx.JSON(errorCode, mapof.Any{"error": derp.Message(derp.RootCause(err))})
+		return
+	}
+
+	derp.Report(err)
+	_ = ctx.JSON(errorCode, mapof.Any{"error": derp.Message(err)})
+}
+
+// makeErrorHandler returns a custom error handler that reports errors to the client,
+// translating standard error messages into the language requested by the browser.
+func makeErrorHandler(translation *service.Translation) echo.HTTPErrorHandler {
+
+	return func(err error, ctx echo.Context) {
+		errorHandler(translation, err, ctx)
+	}
+}
+
 // errorHandler is a custom error handler that returns a JSON error message to the client
-func errorHandler(err error, ctx echo.Context) {
+func errorHandler(translation *service.Translation, err error, ctx echo.Context) {
 
 	// Special handling of permisssion errors
 	request := ctx.Request()
 
 	errorCode := derp.ErrorCode(err)
 
+	// Translate the message for this error, if it has a catalog key (or no message at all)
+	locale := translation.Negotiate(request.Header.Get("Accept-Language"))
+	message := translation.ErrorMessage(locale, err)
+
 	switch errorCode {
 
-	case http.StatusNotFound:
-		_ = ctx.String(derp.ErrorCode(err), derp.Message(err))
+	case http.StatusNotFound, http.StatusTooManyRequests:
+		_ = ctx.String(errorCode, message)
 		return
 
 	case http.StatusUnauthorized:
@@ -512,7 +679,7 @@ func errorHandler(err error, ctx echo.Context) {
 			return
 		}
 
-		_ = ctx.String(errorCode, derp.Message(err))
+		_ = ctx.String(errorCode, message)
 		return
 	}
 
@@ -526,5 +693,5 @@ func errorHandler(err error, ctx echo.Context) {
 	}
 
 	// Fall through to general error handler
-	_ = ctx.String(derp.ErrorCode(err), derp.Message(err))
+	_ = ctx.String(errorCode, message)
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/EmissarySocial/emissary/domain"
 	"github.com/EmissarySocial/emissary/service"
 	"github.com/EmissarySocial/emissary/tools/httpcache"
+	"github.com/EmissarySocial/emissary/tools/ratelimit"
 	mongodb "github.com/benpate/data-mongo"
 	"github.com/benpate/derp"
 	"github.com/benpate/digital-dome/dome"
@@ -49,11 +50,15 @@ type Factory struct {
 	mutex   sync.RWMutex
 	ready   chan struct{}
 
+	// packageMutex serializes changes to installed template packages
+	packageMutex sync.Mutex
+
 	// Server-level services
 	registrationService service.Registration
 	themeService        service.Theme
 	templateService     service.Template
 	widgetService       service.Widget
+	translationService  service.Translation
 	contentService      service.Content
 	providerService     service.Provider
 	emailService        service.ServerEmail
@@ -67,8 +72,9 @@ type Factory struct {
 	queue               queue.Queue
 	digitalDome         dome.Dome
 
-	domains   map[string]*domain.Factory
-	httpCache httpcache.HTTPCache
+	domains     map[string]*domain.Factory
+	httpCache   httpcache.HTTPCache
+	rateLimiter *ratelimit.Limiter
 }
 
 // NewFactory uses the provided configuration data to generate a new Factory
@@ -91,6 +97,9 @@ func NewFactory(storage config.Storage, embeddedFiles embed.FS) *Factory {
 
 	factory.httpCache = httpcache.NewOtterCache(otterCache, httpcache.WithTTL(1*time.Minute))
 
+	// Global Translation Service (must be created before the FuncMap is used)
+	factory.translationService = service.NewTranslation()
+
 	// Global Registration Service
 	factory.registrationService = service.NewRegistration(factory.FuncMap())
 
@@ -113,6 +122,7 @@ func NewFactory(storage config.Storage, embeddedFiles embed.FS) *Factory {
 		factory.Email(),
 		factory.Theme(),
 		factory.Widget(),
+		factory.Translation(),
 		factory.FuncMap(),
 		sliceof.NewObject[mapof.String](),
 	)
@@ -200,7 +210,7 @@ func (factory *Factory) start() {
 
 		// Refresh cached values in global services
 		factory.emailService.Refresh()
-		factory.templateService.Refresh(config.Templates)
+		factory.templateService.Refresh(config.TemplateLocations())
 		factory.providerService.Refresh(config.Providers)
 
 		if err := factory.refreshCommonDatabase(config.ActivityPubCache); err != nil {
@@ -208,6 +218,7 @@ func (factory *Factory) start() {
 		}
 
 		factory.refreshQueue()
+		factory.refreshRateLimiter(config)
 
 		// Add logging to the Silicon Dome WAF
 		if factory.commonDatabase != nil {
@@ -230,7 +241,7 @@ func (factory *Factory) start() {
 		for domainID := range factory.domains {
 			factory.mutex.Lock()
 			if factory.domains[domainID].MarkForDeletion {
-				delete(factory.domains, domainID)
+				factory.removeDomain(domainID)
 			}
 			factory.mutex.Unlock()
 		}
@@ -249,10 +260,26 @@ func (factory *Factory) Ready() <-chan struct{} {
 	return factory.ready
 }
 
+// removeDomain stops serving a domain, and closes all of its background processes and connections
+// CALLS TO THIS MUST BE LOCKED
+func (factory *Factory) removeDomain(hostname string) {
+
+	if existing, ok := factory.domains[hostname]; ok {
+		delete(factory.domains, hostname)
+		existing.Close()
+	}
+}
+
 // refreshDomain attempts to refresh an existing domain, or creates a new one if it doesn't exist
 // CALLS TO THIS MUST BE LOCKED
 func (factory *Factory) refreshDomain(config config.Config, domainConfig config.Domain) error {
 
+	// Suspended domains are removed from the cache so that they are no longer served
+	if domainConfig.Suspended {
+		factory.removeDomain(domainConfig.Hostname)
+		return nil
+	}
+
 	// Try to find the domain
 	if existing := factory.domains[domainConfig.Hostname]; existing != nil {
 
@@ -278,6 +305,7 @@ func (factory *Factory) refreshDomain(config config.Config, domainConfig config.
 		&factory.themeService,
 		&factory.templateService,
 		&factory.widgetService,
+		&factory.translationService,
 		&factory.contentService,
 		&factory.providerService,
 		&factory.queue,
@@ -365,6 +393,56 @@ func (factory *Factory) refreshQueue() {
 	}
 }
 
+// refreshRateLimiter replaces the current rate limiter with a new one that
+// uses the store and limits defined in the configuration
+func (factory *Factory) refreshRateLimiter(config config.Config) {
+
+	const location = "server.Factory.refreshRateLimiter"
+
+	rules, err := config.RateLimitRules()
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, location, "Invalid rate limits.  Using default values instead.", config.RateLimits))
+		config.RateLimits = nil
+		rules, _ = config.RateLimitRules()
+	}
+
+	var store ratelimit.Store
+
+	switch config.RateLimitStore.GetString("adapter") {
+
+	case "REDIS":
+		redisStore, err := ratelimit.NewRedisStoreFromURL(config.RateLimitStore.GetString("location"), "emissary:ratelimit:")
+
+		if err != nil {
+			derp.Report(derp.Wrap(err, location, "Error connecting to Redis.  Using in-memory rate limits instead."))
+			break
+		}
+
+		store = redisStore
+	}
+
+	if store == nil {
+		memoryStore, err := ratelimit.NewMemoryStore(10_000)
+
+		if err != nil {
+			derp.Report(derp.Wrap(err, location, "Error creating in-memory rate limit store.  Rate limits are disabled."))
+			return
+		}
+
+		store = memoryStore
+	}
+
+	factory.mutex.Lock()
+	previous := factory.rateLimiter
+	factory.rateLimiter = ratelimit.NewLimiter(store, rules)
+	factory.mutex.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+}
+
 /****************************
  * Server Config Methods
  ****************************/
@@ -391,6 +469,11 @@ func (factory *Factory) UpdateConfig(value config.Config) error {
 	factory.mutex.Lock()
 	defer factory.mutex.Unlock()
 
+	// RULE: Read-only configurations cannot be changed
+	if factory.config.ReadOnly {
+		return config.NewReadOnlyError("server.Factory.UpdateConfig")
+	}
+
 	factory.config = value
 
 	if err := factory.storage.Write(value); err != nil {
@@ -440,6 +523,11 @@ func (factory *Factory) putDomain(configuration config.Domain) error {
 	factory.mutex.Lock()
 	defer factory.mutex.Unlock()
 
+	// RULE: Read-only configurations cannot be changed
+	if factory.config.ReadOnly {
+		return config.NewReadOnlyError("server.Factory.putDomain")
+	}
+
 	// Add the domain to the collection
 	factory.config.Domains.Put(configuration)
 
@@ -482,6 +570,14 @@ func (factory *Factory) DeleteDomain(domainID string) error {
 	factory.mutex.Lock()
 	defer factory.mutex.Unlock()
 
+	// RULE: Read-only configurations cannot be changed
+	if factory.config.ReadOnly {
+		return config.NewReadOnlyError("server.Factory.DeleteDomain")
+	}
+
+	// Find the domain's hostname before it is removed from the configuration
+	domainConfig, _ := factory.config.Domains.Get(domainID)
+
 	// Delete the domain from the collection
 	factory.config.Domains.Delete(domainID)
 
@@ -490,6 +586,9 @@ func (factory *Factory) DeleteDomain(domainID string) error {
 		return derp.Wrap(err, "server.Factory.DeleteDomain", "Error saving configuration")
 	}
 
+	// Stop serving the domain immediately, without waiting for the storage engine to reload
+	factory.removeDomain(domainConfig.Hostname)
+
 	return nil
 }
 
@@ -503,6 +602,11 @@ func (factory *Factory) PutProvider(oauthClient config.Provider) error {
 	factory.mutex.Lock()
 	defer factory.mutex.Unlock()
 
+	// RULE: Read-only configurations cannot be changed
+	if factory.config.ReadOnly {
+		return config.NewReadOnlyError("server.Factory.PutProvider")
+	}
+
 	// Add the domain to the collection
 	factory.config.Providers.Put(oauthClient)
 
@@ -522,6 +626,11 @@ func (factory *Factory) DeleteProvider(providerID string) error {
 	factory.mutex.Lock()
 	defer factory.mutex.Unlock()
 
+	// RULE: Read-only configurations cannot be changed
+	if factory.config.ReadOnly {
+		return config.NewReadOnlyError("server.Factory.DeleteProvider")
+	}
+
 	// Delete the connection from the collection
 	factory.config.Providers.Delete(providerID)
 
@@ -580,6 +689,13 @@ func (factory *Factory) ByDomainName(name string) (*domain.Factory, error) {
 		return domain, nil
 	}
 
+	// Report suspended domains separately from unrecognized ones
+	for _, domainConfig := range factory.config.Domains {
+		if domainConfig.Suspended && (domainConfig.Hostname == name) {
+			return nil, derp.New(http.StatusServiceUnavailable, "server.Factory.ByDomainName", "Domain is suspended", name)
+		}
+	}
+
 	return nil, derp.NewNotFoundError("server.Factory.ByDomainName", "Unrecognized domain name", name, factory.config)
 }
 
@@ -633,9 +749,14 @@ func (factory *Factory) Widget() *service.Widget {
 	return &factory.widgetService
 }
 
+// Translation returns the global translation service
+func (factory *Factory) Translation() *service.Translation {
+	return &factory.translationService
+}
+
 // FuncMap returns the global funcMap (used by all templates)
 func (factory *Factory) FuncMap() template.FuncMap {
-	return build.FuncMap(factory.Icons())
+	return build.FuncMap(factory.Icons(), factory.Translation())
 }
 
 // Icons returns the global icon collection
@@ -698,6 +819,14 @@ func (factory *Factory) HTTPCache() *httpcache.HTTPCache {
 	return &factory.httpCache
 }
 
+// RateLimiter returns the limiter for sign-ins, registrations, and other
+// abusable routes.  It returns nil if rate limits are not available.
+func (factory *Factory) RateLimiter() *ratelimit.Limiter {
+	factory.mutex.RLock()
+	defer factory.mutex.RUnlock()
+	return factory.rateLimiter
+}
+
 func (factory *Factory) port(domainConfig config.Domain) string {
 
 	// If not localhost, then use standard ports and assume the
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/EmissarySocial/emissary/config"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+)
+
+/****************************
+ * Template Package Methods
+ ****************************/
+
+// TemplatePackage returns a TemplatePackage service that stages packages for the global template service
+func (factory *Factory) TemplatePackage() service.TemplatePackage {
+	return service.NewTemplatePackage(factory.Template())
+}
+
+// TemplatePackages returns all template packages that are installed on this server
+func (factory *Factory) TemplatePackages() []config.TemplatePackage {
+	return factory.Config().TemplatePackages
+}
+
+// LoadTemplatePackage returns a single template package that is installed on this server
+func (factory *Factory) LoadTemplatePackage(packageID string) (config.TemplatePackage, error) {
+
+	if templatePackage, ok := factory.Config().TemplatePackages.Get(packageID); ok {
+		return templatePackage, nil
+	}
+
+	return config.TemplatePackage{}, derp.NewNotFoundError("server.Factory.LoadTemplatePackage", "Template package not found", packageID)
+}
+
+// InstallTemplatePackageFromGit downloads a Git repository at a tag, branch, or commit,
+// validates it, and then makes it the active version of the package.
+func (factory *Factory) InstallTemplatePackageFromGit(packageID string, repositoryURL string, ref string) (config.TemplatePackage, error) {
+
+	const location = "server.Factory.InstallTemplatePackageFromGit"
+
+	if packageID == "" {
+		packageID = service.TemplatePackageID(repositoryURL)
+	}
+
+	result, err := factory.installTemplatePackage(packageID, config.TemplatePackageSourceGit, repositoryURL, func(packageID string, packages mapof.String, references sliceof.Object[mapof.String]) (config.TemplatePackageVersion, error) {
+		templatePackageService := factory.TemplatePackage()
+		return templatePackageService.StageGit(packages, packageID, repositoryURL, ref, references)
+	})
+
+	if err != nil {
+		return result, derp.Wrap(err, location, "Error installing template package", repositoryURL, ref)
+	}
+
+	return result, nil
+}
+
+// InstallTemplatePackageFromZip unpacks a .zip file, validates it, and then makes it the
+// active version of the package.
+func (factory *Factory) InstallTemplatePackageFromZip(packageID string, data []byte) (config.TemplatePackage, error) {
+
+	const location = "server.Factory.InstallTemplatePackageFromZip"
+
+	result, err := factory.installTemplatePackage(packageID, config.TemplatePackageSourceZip, "", func(packageID string, packages mapof.String, references sliceof.Object[mapof.String]) (config.TemplatePackageVersion, error) {
+		templatePackageService := factory.TemplatePackage()
+		return templatePackageService.StageZip(packages, packageID, data, references)
+	})
+
+	if err != nil {
+		return result, derp.Wrap(err, location, "Error installing template package", packageID)
+	}
+
+	return result, nil
+}
+
+// ActivateTemplatePackage switches a package to any of its installed versions
+func (factory *Factory) ActivateTemplatePackage(packageID string, version string) (config.TemplatePackage, error) {
+
+	const location = "server.Factory.ActivateTemplatePackage"
+
+	factory.packageMutex.Lock()
+	defer factory.packageMutex.Unlock()
+
+	templatePackage, err := factory.LoadTemplatePackage(packageID)
+
+	if err != nil {
+		return templatePackage, derp.Wrap(err, location, "Error loading template package", packageID)
+	}
+
+	if _, ok := templatePackage.Version(version); !ok {
+		return templatePackage, derp.NewNotFoundError(location, "Version is not installed", packageID, version)
+	}
+
+	templatePackage.ActiveVersion = version
+
+	if err := factory.putTemplatePackage(templatePackage); err != nil {
+		return templatePackage, derp.Wrap(err, location, "Error activating template package", packageID, version)
+	}
+
+	return templatePackage, nil
+}
+
+// RollbackTemplatePackage switches a package back to the version that was installed before the active version
+func (factory *Factory) RollbackTemplatePackage(packageID string) (config.TemplatePackage, error) {
+
+	const location = "server.Factory.RollbackTemplatePackage"
+
+	templatePackage, err := factory.LoadTemplatePackage(packageID)
+
+	if err != nil {
+		return templatePackage, derp.Wrap(err, location, "Error loading template package", packageID)
+	}
+
+	previous, ok := templatePackage.Previous()
+
+	if !ok {
+		return templatePackage, derp.New(http.StatusConflict, location, "No previous version to roll back to", packageID)
+	}
+
+	return factory.ActivateTemplatePackage(packageID, previous.Version)
+}
+
+// RemoveTemplatePackageVersion deletes an installed version that is not currently active
+func (factory *Factory) RemoveTemplatePackageVersion(packageID string, version string) (config.TemplatePackage, error) {
+
+	const location = "server.Factory.RemoveTemplatePackageVersion"
+
+	factory.packageMutex.Lock()
+	defer factory.packageMutex.Unlock()
+
+	templatePackage, err := factory.LoadTemplatePackage(packageID)
+
+	if err != nil {
+		return templatePackage, derp.Wrap(err, location, "Error loading template package", packageID)
+	}
+
+	if version == templatePackage.ActiveVersion {
+		return templatePackage, derp.New(http.StatusConflict, location, "The active version cannot be removed", packageID, version)
+	}
+
+	if !templatePackage.RemoveVersion(version) {
+		return templatePackage, derp.NewNotFoundError(location, "Version is not installed", packageID, version)
+	}
+
+	if err := factory.putTemplatePackage(templatePackage); err != nil {
+		return templatePackage, derp.Wrap(err, location, "Error saving template package", packageID)
+	}
+
+	templatePackageService := factory.TemplatePackage()
+	if err := templatePackageService.Remove(factory.Config().Packages, packageID, version); err != nil {
+		return templatePackage, derp.Wrap(err, location, "Error removing package files", packageID, version)
+	}
+
+	return templatePackage, nil
+}
+
+// RemoveTemplatePackage uninstalls a package and all of its versions
+func (factory *Factory) RemoveTemplatePackage(packageID string) error {
+
+	const location = "server.Factory.RemoveTemplatePackage"
+
+	factory.packageMutex.Lock()
+	defer factory.packageMutex.Unlock()
+
+	templatePackage, err := factory.LoadTemplatePackage(packageID)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading template package", packageID)
+	}
+
+	// Stop serving the package before removing its files
+	configuration := factory.Config()
+	configuration.TemplatePackages.Delete(packageID)
+
+	if err := factory.UpdateConfig(configuration); err != nil {
+		return derp.Wrap(err, location, "Error saving configuration", packageID)
+	}
+
+	factory.templateService.Refresh(configuration.TemplateLocations())
+
+	templatePackageService := factory.TemplatePackage()
+	for _, version := range templatePackage.Versions {
+		if err := templatePackageService.Remove(configuration.Packages, packageID, version.Version); err != nil {
+			return derp.Wrap(err, location, "Error removing package files", packageID, version.Version)
+		}
+	}
+
+	return nil
+}
+
+// installTemplatePackage stages a new version of a package (using the provided function)
+// and then activates it.  If staging fails, then the running templates are not changed.
+func (factory *Factory) installTemplatePackage(packageID string, source string, url string, stage func(string, mapof.String, sliceof.Object[mapof.String]) (config.TemplatePackageVersion, error)) (config.TemplatePackage, error) {
+
+	const location = "server.Factory.installTemplatePackage"
+
+	factory.packageMutex.Lock()
+	defer factory.packageMutex.Unlock()
+
+	configuration := factory.Config()
+
+	// RULE: Read-only configurations cannot be changed
+	if configuration.ReadOnly {
+		return config.TemplatePackage{}, config.NewReadOnlyError(location)
+	}
+
+	// RULE: PackageID is required
+	if packageID = service.TemplatePackageID(packageID); packageID == "" {
+		return config.TemplatePackage{}, derp.NewBadRequestError(location, "Package ID is required")
+	}
+
+	templatePackage, exists := configuration.TemplatePackages.Get(packageID)
+
+	if !exists {
+		templatePackage = config.NewTemplatePackage(packageID)
+	}
+
+	// Validate against every other template that is currently running
+	references := sliceof.NewObject[mapof.String]()
+	for _, folder := range configuration.Templates {
+		references = append(references, folder)
+	}
+	for _, other := range configuration.TemplatePackages {
+		if folder, ok := other.Folder(); ok && other.PackageID != packageID {
+			references = append(references, folder)
+		}
+	}
+
+	// Stage the new version on the local filesystem
+	version, err := stage(packageID, configuration.Packages, references)
+
+	if err != nil {
+		return templatePackage, derp.Wrap(err, location, "Error staging template package", packageID)
+	}
+
+	// Switch to the new version
+	templatePackage.Source = source
+	templatePackage.URL = url
+	templatePackage.AddVersion(version)
+	templatePackage.ActiveVersion = version.Version
+
+	if err := factory.putTemplatePackage(templatePackage); err != nil {
+		return templatePackage, derp.Wrap(err, location, "Error activating template package", packageID)
+	}
+
+	return templatePackage, nil
+}
+
+// putTemplatePackage saves a package into the configuration, then reloads the
+// template service so that the package's active version is served immediately.
+// CALLS TO THIS MUST HOLD THE PACKAGE MUTEX
+func (factory *Factory) putTemplatePackage(templatePackage config.TemplatePackage) error {
+
+	configuration := factory.Config()
+	configuration.TemplatePackages.Put(templatePackage)
+
+	if err := factory.UpdateConfig(configuration); err != nil {
+		return derp.Wrap(err, "server.Factory.putTemplatePackage", "Error saving configuration", templatePackage.PackageID)
+	}
+
+	factory.templateService.Refresh(configuration.TemplateLocations())
+	return nil
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/EmissarySocial/emissary/config"
+	"github.com/EmissarySocial/emissary/domain"
+	"github.com/EmissarySocial/emissary/queries"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+)
+
+// DomainReport summarizes a single domain for the provisioning API.
+// It never includes secrets like connection strings, passwords, or encryption keys.
+type DomainReport struct {
+	DomainID     string `json:"domainId"`
+	Label        string `json:"label"`
+	Hostname     string `json:"hostname"`
+	Suspended    bool   `json:"suspended"`
+	Running      bool   `json:"running"`                // TRUE if this domain is currently being served
+	Users        int64  `json:"users,omitempty"`        // Number of User accounts on this domain
+	Attachments  int64  `json:"attachments,omitempty"`  // Number of Attachments on this domain
+	StorageBytes int64  `json:"storageBytes,omitempty"` // Space used by the domain's database (documents and indexes)
+	StorageQuota int    `json:"storageQuota,omitempty"` // Maximum storage (in megabytes) for all attachments on this domain
+	StorageUsed  int64  `json:"storageUsed,omitempty"`  // Space used by the domain's attachments (in bytes)
+}
+
+/****************************
+ * Provisioning Methods
+ ****************************/
+
+// SuspendDomain stops serving a domain without removing its configuration or data
+func (factory *Factory) SuspendDomain(domainID string) error {
+
+	if err := factory.setDomainSuspended(domainID, true); err != nil {
+		return derp.Wrap(err, "server.Factory.SuspendDomain", "Error suspending domain", domainID)
+	}
+
+	return nil
+}
+
+// ResumeDomain restarts a domain that was previously suspended
+func (factory *Factory) ResumeDomain(domainID string) error {
+
+	if err := factory.setDomainSuspended(domainID, false); err != nil {
+		return derp.Wrap(err, "server.Factory.ResumeDomain", "Error resuming domain", domainID)
+	}
+
+	return nil
+}
+
+// RelabelDomain changes the human-friendly label of a domain
+func (factory *Factory) RelabelDomain(domainID string, label string) error {
+
+	const location = "server.Factory.RelabelDomain"
+
+	domainConfig, err := factory.existingDomain(domainID)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading domain", domainID)
+	}
+
+	domainConfig.Label = label
+
+	if err := factory.putDomain(domainConfig); err != nil {
+		return derp.Wrap(err, location, "Error saving domain", domainID)
+	}
+
+	return nil
+}
+
+// DomainReports returns a summary of every domain on this server, without usage statistics
+func (factory *Factory) DomainReports() []DomainReport {
+
+	factory.mutex.RLock()
+	defer factory.mutex.RUnlock()
+
+	result := make([]DomainReport, len(factory.config.Domains))
+
+	for index, domainConfig := range factory.config.Domains {
+		result[index] = factory.domainReport(domainConfig)
+	}
+
+	return result
+}
+
+// DomainReport returns a summary of a single domain, including user counts and storage statistics.
+// Statistics are only available for domains that are currently running, and are collected on a
+// best-effort basis: if the domain's database cannot be reached, the basic report is still returned.
+func (factory *Factory) DomainReport(domainID string) (DomainReport, error) {
+
+	const location = "server.Factory.DomainReport"
+
+	domainConfig, err := factory.existingDomain(domainID)
+
+	if err != nil {
+		return DomainReport{}, derp.Wrap(err, location, "Error loading domain", domainID)
+	}
+
+	factory.mutex.RLock()
+	result := factory.domainReport(domainConfig)
+	domainFactory := factory.domains[domainConfig.Hostname]
+	factory.mutex.RUnlock()
+
+	// Suspended domains have no running services to report on
+	if !result.Running || (domainFactory.Session == nil) {
+		return result, nil
+	}
+
+	if err := factory.domainStatistics(domainFactory, &result); err != nil {
+		derp.Report(derp.Wrap(err, location, "Error collecting domain statistics", domainID))
+	}
+
+	return result, nil
+}
+
+// domainStatistics adds user counts and storage statistics to a domain report
+func (factory *Factory) domainStatistics(domainFactory *domain.Factory, result *DomainReport) error {
+
+	const location = "server.Factory.domainStatistics"
+
+	var err error
+
+	if result.Users, err = domainFactory.User().Count(exp.All()); err != nil {
+		return derp.Wrap(err, location, "Error counting users")
+	}
+
+	if result.Attachments, err = domainFactory.Attachment().Count(exp.All()); err != nil {
+		return derp.Wrap(err, location, "Error counting attachments")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stats, err := queries.GetDatabaseStats(ctx, domainFactory.Session)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error reading storage statistics")
+	}
+
+	result.StorageBytes = stats.StorageSize + stats.IndexSize
+	result.StorageUsed = domainFactory.Domain().Get().StorageUsed
+
+	return nil
+}
+
+// setDomainSuspended updates the suspended flag on a domain, then saves and refreshes it
+func (factory *Factory) setDomainSuspended(domainID string, suspended bool) error {
+
+	const location = "server.Factory.setDomainSuspended"
+
+	domainConfig, err := factory.existingDomain(domainID)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading domain", domainID)
+	}
+
+	domainConfig.Suspended = suspended
+
+	if err := factory.putDomain(domainConfig); err != nil {
+		return derp.Wrap(err, location, "Error saving domain", domainID)
+	}
+
+	return nil
+}
+
+// existingDomain returns the configuration for a domain that already exists.
+// Unlike DomainByID, it never creates a new domain.
+func (factory *Factory) existingDomain(domainID string) (config.Domain, error) {
+
+	factory.mutex.RLock()
+	defer factory.mutex.RUnlock()
+
+	if domainConfig, ok := factory.config.Domains.Get(domainID); ok {
+		return domainConfig, nil
+	}
+
+	return config.Domain{}, derp.NewNotFoundError("server.Factory.existingDomain", "DomainID not found", domainID)
+}
+
+// domainReport creates the basic report for a domain.
+// CALLS TO THIS MUST BE LOCKED
+func (factory *Factory) domainReport(domainConfig config.Domain) DomainReport {
+
+	_, running := factory.domains[domainConfig.Hostname]
+
+	return DomainReport{
+		DomainID:     domainConfig.DomainID,
+		Label:        domainConfig.Label,
+		Hostname:     domainConfig.Hostname,
+		Suspended:    domainConfig.Suspended,
+		Running:      running,
+		StorageQuota: domainConfig.StorageQuota,
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/benpate/derp"
+	"github.com/benpate/domain"
+	"github.com/benpate/hannibal/inbox"
+	"github.com/benpate/hannibal/sigs"
+	"github.com/benpate/hannibal/streams"
+	"github.com/benpate/hannibal/validator"
+	"github.com/benpate/hannibal/vocab"
+)
+
+// signatureClockSkew is how far in the future a signed Date is allowed to be
+const signatureClockSkew = time.Hour
+
+// VerifyRequest validates the HTTP signature on an incoming request, such as an ActivityPub
+// GET or inbox POST, and returns the ID of the remote Actor who signed it.  Signatures must
+// include the request Date (and the body Digest, if there is a body) so that they expire.
+func (service *ActivityStream) VerifyRequest(request *http.Request) (string, error) {
+
+	const location = "service.ActivityStream.VerifyRequest"
+
+	// RULE: Request must be signed
+	if !sigs.HasSignature(request) {
+		return "", derp.NewUnauthorizedError(location, "Request must include an HTTP signature")
+	}
+
+	actorID := ""
+
+	// keyFinder loads the public key used to sign this request,
+	// and remembers the Actor who owns it.
+	keyFinder := func(keyID string) (string, error) {
+
+		document, err := service.Load(keyID)
+
+		if err != nil {
+			return "", derp.Wrap(err, location, "Error loading public key", keyID)
+		}
+
+		// Some servers publish their keys as standalone documents.
+		if publicKeyPEM := document.PublicKeyPEM(); publicKeyPEM != "" {
+
+			owner := document.Get(vocab.PropertyOwner).String()
+
+			// RULE: Key must belong to an Actor on the same server
+			if (owner == "") || (domain.NameOnly(owner) != domain.NameOnly(keyID)) {
+				return "", derp.NewForbiddenError(location, "Public key must be owned by an Actor on the same server", keyID, owner)
+			}
+
+			actorID = owner
+			return publicKeyPEM, nil
+		}
+
+		// Other servers (like Mastodon) embed their keys in the Actor document.
+		for key := document.PublicKey(); key.NotNil(); key = key.Tail() {
+			if key.ID() == keyID {
+				actorID = document.ID()
+				return key.PublicKeyPEM(), nil
+			}
+		}
+
+		return "", derp.NewForbiddenError(location, "Actor must publish the key used to sign this request", keyID)
+	}
+
+	// RULE: Request must include a valid Date that is not too far in the future.
+	// (The verifier rejects dates that are too far in the past)
+	date, err := http.ParseTime(request.Header.Get(sigs.FieldDate))
+
+	if err != nil {
+		return "", derp.NewUnauthorizedError(location, "Request must include a valid Date header", request.Header.Get(sigs.FieldDate))
+	}
+
+	if date.After(time.Now().Add(signatureClockSkew)) {
+		return "", derp.NewUnauthorizedError(location, "Request date is in the future", request.Header.Get(sigs.FieldDate))
+	}
+
+	// Signatures must include the Date, so that they cannot be replayed forever.
+	fields := []string{sigs.FieldRequestTarget, sigs.FieldHost, sigs.FieldDate}
+	verifierOptions := []sigs.VerifierOption{}
+
+	if hasRequestBody(request) {
+
+		// RULE: Requests with a body (like inbox POSTs) must also sign a Digest of that body
+		if request.Header.Get(sigs.FieldDigest) == "" {
+			return "", derp.NewUnauthorizedError(location, "Request must include a Digest header")
+		}
+
+		fields = append(fields, sigs.FieldDigest)
+
+	} else {
+
+		// GET requests have no body, so there is no Digest to verify
+		verifierOptions = append(verifierOptions, sigs.VerifierIgnoreBodyDigest())
+	}
+
+	verifierOptions = append(verifierOptions, sigs.VerifierFields(fields...))
+
+	if err := sigs.Verify(request, keyFinder, verifierOptions...); err != nil {
+		return "", derp.NewUnauthorizedError(location, "Invalid HTTP signature", err.Error())
+	}
+
+	return actorID, nil
+}
+
+// hasRequestBody returns TRUE if the request includes a body that must be covered by a Digest
+func hasRequestBody(request *http.Request) bool {
+	return (request.Method != http.MethodGet) && (request.Method != http.MethodHead)
+}
+
+// ReceiveRequest parses (and validates) an ActivityPub document that was POSTed to an inbox.
+// Requests whose signatures have already been verified (see WithVerifiedActor) are accepted
+// without verifying the signature a second time.
+func (service *ActivityStream) ReceiveRequest(request *http.Request) (streams.Document, error) {
+	return inbox.ReceiveRequest(request, service, inbox.WithValidators(
+		verifiedActorValidator{},
+		validator.NewHTTPSig(),
+		validator.NewDeletedObject(),
+	))
+}
+
+// verifiedActorKey is the context key for the Actor who signed a request
+type verifiedActorKey struct{}
+
+// WithVerifiedActor returns a copy of the request that remembers the Actor
+// whose HTTP signature has already been verified by VerifyRequest.
+func WithVerifiedActor(request *http.Request, actorID string) *http.Request {
+	return request.WithContext(context.WithValue(request.Context(), verifiedActorKey{}, actorID))
+}
+
+// VerifiedActor returns the ID of the Actor whose HTTP signature has already
+// been verified for this request, or an empty string if there is none.
+func VerifiedActor(request *http.Request) string {
+	actorID, _ := request.Context().Value(verifiedActorKey{}).(string)
+	return actorID
+}
+
+// verifiedActorValidator is an inbox Validator that accepts documents from the Actor
+// who signed the request, when that signature has already been verified.  All other
+// documents are left for the remaining validators.
+type verifiedActorValidator struct{}
+
+// Validate implements the inbox.Validator interface
+func (verifiedActorValidator) Validate(request *http.Request, document *streams.Document) validator.Result {
+
+	verifiedActorID := VerifiedActor(request)
+
+	if verifiedActorID == "" {
+		return validator.ResultUnknown
+	}
+
+	// Read the Actor's ID without loading the Actor from its server
+	actor := document.Get(vocab.PropertyActor)
+	actorID := actor.ID()
+
+	if actor.IsString() {
+		actorID = actor.String()
+	}
+
+	if actorID == verifiedActorID {
+		return validator.ResultValid
+	}
+
+	return validator.ResultUnknown
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benpate/derp"
+	"github.com/benpate/hannibal/sigs"
+	"github.com/benpate/hannibal/streams"
+	"github.com/benpate/hannibal/vocab"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/stretchr/testify/require"
+)
+
+// testDocumentClient returns documents from a fixed map of URLs
+type testDocumentClient map[string]mapof.Any
+
+func (client testDocumentClient) Load(uri string, _ ...any) (streams.Document, error) {
+
+	if value, ok := client[uri]; ok {
+		return streams.NewDocument(value, streams.WithClient(client)), nil
+	}
+
+	return streams.NilDocument(), derp.NewNotFoundError("testDocumentClient", "Not found", uri)
+}
+
+func TestVerifyRequest(t *testing.T) {
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	const actorID = "https://remote.example/users/alice"
+	const keyID = actorID + "#main-key"
+	const standaloneKeyID = "https://remote.example/keys/1"
+	const foreignKeyID = "https://evil.example/keys/1"
+
+	publicKeyPEM := sigs.EncodePublicPEM(privateKey)
+
+	activityService := NewActivityStream()
+	activityService.innerClient = testDocumentClient{
+
+		// Mastodon-style key, embedded in the Actor
+		keyID: {
+			vocab.PropertyID: actorID,
+			vocab.PropertyPublicKey: mapof.Any{
+				vocab.PropertyID:           keyID,
+				vocab.PropertyOwner:        actorID,
+				vocab.PropertyPublicKeyPEM: publicKeyPEM,
+			},
+		},
+
+		// Standalone key document
+		standaloneKeyID: {
+			vocab.PropertyID:           standaloneKeyID,
+			vocab.PropertyOwner:        actorID,
+			vocab.PropertyPublicKeyPEM: publicKeyPEM,
+		},
+
+		// Key that claims to belong to an Actor on another server
+		foreignKeyID: {
+			vocab.PropertyID:           foreignKeyID,
+			vocab.PropertyOwner:        actorID,
+			vocab.PropertyPublicKeyPEM: publicKeyPEM,
+		},
+	}
+
+	newRequest := func(keyID string, key *rsa.PrivateKey) *http.Request {
+		request := httptest.NewRequest(http.MethodGet, "https://local.example/@bob/pub/outbox", nil)
+		request.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+
+		if key != nil {
+			require.Nil(t, sigs.Sign(request, keyID, key, sigs.SignerFields(sigs.FieldRequestTarget, sigs.FieldHost, sigs.FieldDate)))
+		}
+
+		return request
+	}
+
+	// Unsigned requests
+	_, err = activityService.VerifyRequest(newRequest("", nil))
+	require.Equal(t, http.StatusUnauthorized, derp.ErrorCode(err))
+
+	// Badly signed requests
+	_, err = activityService.VerifyRequest(newRequest(keyID, otherKey))
+	require.Equal(t, http.StatusUnauthorized, derp.ErrorCode(err))
+
+	// Requests signed with unknown keys
+	_, err = activityService.VerifyRequest(newRequest("https://remote.example/users/nobody#main-key", privateKey))
+	require.Equal(t, http.StatusUnauthorized, derp.ErrorCode(err))
+
+	// Requests signed with keys owned by Actors on other servers
+	_, err = activityService.VerifyRequest(newRequest(foreignKeyID, privateKey))
+	require.Equal(t, http.StatusUnauthorized, derp.ErrorCode(err))
+
+	// Validly signed requests return the Actor who signed them
+	signer, err := activityService.VerifyRequest(newRequest(keyID, privateKey))
+	require.Nil(t, err)
+	require.Equal(t, actorID, signer)
+
+	signer, err = activityService.VerifyRequest(newRequest(standaloneKeyID, privateKey))
+	require.Nil(t, err)
+	require.Equal(t, actorID, signer)
+
+	// Signatures that do not include the Date can be replayed forever, so they are rejected
+	request := httptest.NewRequest(http.MethodGet, "https://local.example/@bob/pub/outbox", nil)
+	request.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	require.Nil(t, sigs.Sign(request, keyID, privateKey, sigs.SignerFields(sigs.FieldRequestTarget, sigs.FieldHost)))
+	_, err = activityService.VerifyRequest(request)
+	require.Equal(t, http.StatusUnauthorized, derp.ErrorCode(err))
+
+	// Stale and future-dated signatures are rejected
+	for _, date := range []time.Time{time.Now().Add(-24 * time.Hour), time.Now().Add(2 * time.Hour)} {
+		request = httptest.NewRequest(http.MethodGet, "https://local.example/@bob/pub/outbox", nil)
+		request.Header.Set("Date", date.UTC().Format(http.TimeFormat))
+		require.Nil(t, sigs.Sign(request, keyID, privateKey, sigs.SignerFields(sigs.FieldRequestTarget, sigs.FieldHost, sigs.FieldDate)))
+		_, err = activityService.VerifyRequest(request)
+		require.Equal(t, http.StatusUnauthorized, derp.ErrorCode(err))
+	}
+
+	// Requests with a body must sign a Digest that matches the body
+	newPost := func(body string, fields ...string) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "https://local.example/.inbox", strings.NewReader(body))
+		request.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+		require.Nil(t, sigs.Sign(request, keyID, privateKey, sigs.SignerFields(fields...)))
+		return request
+	}
+
+	signer, err = activityService.VerifyRequest(newPost(`{"type":"Like"}`, sigs.FieldRequestTarget, sigs.FieldHost, sigs.FieldDate, sigs.FieldDigest))
+	require.Nil(t, err)
+	require.Equal(t, actorID, signer)
+
+	_, err = activityService.VerifyRequest(newPost(`{"type":"Like"}`, sigs.FieldRequestTarget, sigs.FieldHost, sigs.FieldDate))
+	require.Equal(t, http.StatusUnauthorized, derp.ErrorCode(err))
+
+	request = newPost(`{"type":"Like"}`, sigs.FieldRequestTarget, sigs.FieldHost, sigs.FieldDate, sigs.FieldDigest)
+	request.Body = io.NopCloser(strings.NewReader(`{"type":"Delete"}`))
+	_, err = activityService.VerifyRequest(request)
+	require.Equal(t, http.StatusUnauthorized, derp.ErrorCode(err))
+}
+
+func TestReceiveRequest_VerifiedActor(t *testing.T) {
+
+	activityService := NewActivityStream()
+	activityService.innerClient = testDocumentClient{}
+
+	newRequest := func() *http.Request {
+		body := `{"type":"Create","actor":"https://remote.example/users/alice","object":{"type":"Note"}}`
+		return httptest.NewRequest(http.MethodPost, "https://local.example/.inbox", strings.NewReader(body))
+	}
+
+	// Requests from the Actor whose signature has already been verified are accepted
+	document, err := activityService.ReceiveRequest(WithVerifiedActor(newRequest(), "https://remote.example/users/alice"))
+	require.Nil(t, err)
+	require.Equal(t, "https://remote.example/users/alice", document.Get(vocab.PropertyActor).String())
+
+	// Documents from other Actors are not trusted
+	_, err = activityService.ReceiveRequest(WithVerifiedActor(newRequest(), "https://remote.example/users/mallory"))
+	require.Equal(t, http.StatusUnauthorized, derp.ErrorCode(err))
+
+	// Neither are requests that have not been verified
+	_, err = activityService.ReceiveRequest(newRequest())
+	require.Equal(t, http.StatusUnauthorized, derp.ErrorCode(err))
+}
@@ -9,14 +9,20 @@ import (
 	"github.com/benpate/mediaserver"
 	"github.com/benpate/rosetta/schema"
 	"github.com/rs/zerolog/log"
+	"github.com/spf13/afero"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Attachment manages all interactions with the Attachment collection
 type Attachment struct {
-	collection  data.Collection
-	mediaServer mediaserver.MediaServer
-	host        string
+	collection    data.Collection
+	mediaServer   mediaserver.MediaServer
+	originals     afero.Fs
+	cache         afero.Fs
+	domainService *Domain
+	groupService  *Group
+	userService   *User
+	host          string
 }
 
 // NewAttachment returns a fully populated Attachment service
@@ -29,9 +35,14 @@ func NewAttachment() Attachment {
  ******************************************/
 
 // Refresh updates any stateful data that is cached inside this service.
-func (service *Attachment) Refresh(collection data.Collection, mediaServer mediaserver.MediaServer, host string) {
+func (service *Attachment) Refresh(collection data.Collection, mediaServer mediaserver.MediaServer, originals afero.Fs, cache afero.Fs, domainService *Domain, groupService *Group, userService *User, host string) {
 	service.collection = collection
 	service.mediaServer = mediaServer
+	service.originals = originals
+	service.cache = cache
+	service.domainService = domainService
+	service.groupService = groupService
+	service.userService = userService
 	service.host = host
 }
 
@@ -91,11 +102,18 @@ func (service *Attachment) Save(attachment *model.Attachment, note string) error
 	// Calculate the URL
 	attachment.SetURL(service.host)
 
+	isNew := attachment.IsNew()
+
 	// Save the record to the database
 	if err := service.collection.Save(attachment, note); err != nil {
 		return derp.Wrap(err, "service.Attachment", "Error saving Attachment", attachment, note)
 	}
 
+	// New Attachments count against storage quotas
+	if isNew {
+		service.addStorageUsed(attachment, attachment.Size)
+	}
+
 	return nil
 }
 
@@ -113,6 +131,9 @@ func (service *Attachment) Delete(attachment *model.Attachment, note string) err
 		return derp.Wrap(err, "service.Attachment", "Error deleting Attachment", attachment, note)
 	}
 
+	// Release the storage used by this Attachment
+	service.addStorageUsed(attachment, -attachment.Size)
+
 	return nil
 }
 
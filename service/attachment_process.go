@@ -0,0 +1,242 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/mediaproc"
+	"github.com/benpate/derp"
+	"github.com/benpate/mediaserver"
+	"github.com/benpate/mediaserver/ffmpeg"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/afero"
+)
+
+// PutOriginal saves the original file for a newly uploaded Attachment into the media server.
+// Originals are available as soon as they are saved, so EXIF/GPS metadata is removed from
+// images here (unless the Attachment's rules say otherwise), before anyone can download them.
+func (service *Attachment) PutOriginal(attachment *model.Attachment, source io.Reader) error {
+
+	const location = "service.Attachment.PutOriginal"
+
+	filename := attachment.AttachmentID.Hex()
+
+	if (attachment.MimeCategory() == model.AttachmentMediaTypeImage) && !attachment.Rules.KeepMetadata {
+
+		original, err := io.ReadAll(source)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error reading uploaded file", filename)
+		}
+
+		stripped, err := mediaproc.StripMetadata(original)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error removing metadata", filename)
+		}
+
+		attachment.Size = int64(len(stripped))
+		source = bytes.NewReader(stripped)
+	}
+
+	if err := service.mediaServer.Put(filename, source); err != nil {
+		return derp.Wrap(err, location, "Error saving file to mediaserver", filename)
+	}
+
+	return nil
+}
+
+// ProcessMedia does the (potentially slow) work of preparing an uploaded file.  It removes
+// EXIF/GPS metadata from the original (unless the Attachment's rules say otherwise), reads the
+// real dimensions and duration of the file, calculates a blurhash and dominant color for images,
+// and pre-renders every format declared in the Attachment's rules.  When this is complete,
+// the Attachment is marked READY.
+func (service *Attachment) ProcessMedia(attachment *model.Attachment) error {
+
+	const location = "service.Attachment.ProcessMedia"
+
+	switch attachment.MimeCategory() {
+
+	case model.AttachmentMediaTypeImage:
+		if err := service.processImage(attachment); err != nil {
+			return derp.Wrap(err, location, "Error processing image", attachment.AttachmentID)
+		}
+
+	case model.AttachmentMediaTypeAudio, model.AttachmentMediaTypeVideo:
+		if err := service.processAudioVideo(attachment); err != nil {
+			return derp.Wrap(err, location, "Error processing audio/video", attachment.AttachmentID)
+		}
+	}
+
+	// Pre-render all renditions into the cache
+	service.renderAll(attachment)
+
+	// Mark the Attachment as ready to use
+	attachment.Status = model.AttachmentStatusReady
+
+	if err := service.Save(attachment, "Processed media"); err != nil {
+		return derp.Wrap(err, location, "Error saving Attachment", attachment.AttachmentID)
+	}
+
+	return nil
+}
+
+// processImage strips metadata from an original image, then records
+// its dimensions, blurhash, and dominant color.
+func (service *Attachment) processImage(attachment *model.Attachment) error {
+
+	const location = "service.Attachment.processImage"
+
+	filename := attachment.AttachmentID.Hex()
+	original, err := afero.ReadFile(service.originals, filename)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error reading original file", filename)
+	}
+
+	// Remove EXIF/GPS metadata unless the rules say to keep it
+	if !attachment.Rules.KeepMetadata {
+
+		stripped, err := mediaproc.StripMetadata(original)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error removing metadata", filename)
+		}
+
+		if !bytes.Equal(stripped, original) {
+
+			if err := service.mediaServer.Put(filename, bytes.NewReader(stripped)); err != nil {
+				return derp.Wrap(err, location, "Error saving stripped original", filename)
+			}
+
+			// Remove any versions that were cached before the metadata was removed
+			if err := service.cache.RemoveAll(filename); err != nil {
+				return derp.Wrap(err, location, "Error removing cached files", filename)
+			}
+
+			// Update storage usage to match the new file size
+			delta := int64(len(stripped) - len(original))
+			attachment.Size += delta
+			service.addStorageUsed(attachment, delta)
+
+			original = stripped
+		}
+	}
+
+	// Read dimensions, blurhash, and dominant color from the image
+	info, err := mediaproc.AnalyzeImage(original)
+
+	if err != nil {
+		// Some image formats (like SVG) cannot be decoded.  This is not an error.
+		log.Debug().Str("location", location).Str("filename", filename).Err(err).Msg("Unable to analyze image")
+		return nil
+	}
+
+	attachment.Width = info.Width
+	attachment.Height = info.Height
+	attachment.Blurhash = info.Blurhash
+	attachment.Color = info.Color
+	return nil
+}
+
+// processAudioVideo records the dimensions and duration of an audio or video file
+func (service *Attachment) processAudioVideo(attachment *model.Attachment) error {
+
+	const location = "service.Attachment.processAudioVideo"
+
+	// FFprobe is optional.  Without it, these values are left blank.
+	if !mediaproc.ProbeIsInstalled {
+		return nil
+	}
+
+	// FFprobe requires a real file, so copy the original into a temp file
+	filename := attachment.AttachmentID.Hex()
+	original, err := service.originals.Open(filename)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error opening original file", filename)
+	}
+
+	defer original.Close()
+
+	tempFile, err := os.CreateTemp("", "emissary-*"+attachment.OriginalExtension())
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error creating temp file")
+	}
+
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	if _, err := io.Copy(tempFile, original); err != nil {
+		return derp.Wrap(err, location, "Error copying original file", filename)
+	}
+
+	info, err := mediaproc.Probe(tempFile.Name())
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error probing media file", filename)
+	}
+
+	attachment.Width = info.Width
+	attachment.Height = info.Height
+	attachment.Duration = info.Duration
+	return nil
+}
+
+// renderAll pre-renders every rendition of an Attachment into the cache.
+// Errors are reported but do not stop processing, because any missing
+// renditions will still be created on demand.
+func (service *Attachment) renderAll(attachment *model.Attachment) {
+
+	const location = "service.Attachment.renderAll"
+
+	// Only media files are converted by the mediaserver
+	switch attachment.MimeCategory() {
+	case model.AttachmentMediaTypeImage, model.AttachmentMediaTypeAudio, model.AttachmentMediaTypeVideo:
+	default:
+		return
+	}
+
+	// Renditions require FFmpeg
+	if !ffmpeg.IsInstalled {
+		return
+	}
+
+	for _, filespec := range attachment.Renditions() {
+		if err := service.render(filespec); err != nil {
+			derp.Report(derp.Wrap(err, location, "Error rendering file", filespec))
+		}
+	}
+}
+
+// render writes a single rendition into the cache, unless it already exists
+func (service *Attachment) render(filespec mediaserver.FileSpec) error {
+
+	const location = "service.Attachment.render"
+
+	if exists, _ := afero.Exists(service.cache, filespec.ProcessedPath()); exists {
+		return nil
+	}
+
+	if err := service.cache.MkdirAll(filespec.ProcessedDir(), 0755); err != nil {
+		return derp.Wrap(err, location, "Error creating cache folder", filespec.ProcessedDir())
+	}
+
+	cachedFile, err := service.cache.Create(filespec.ProcessedPath())
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error creating cached file", filespec.ProcessedPath())
+	}
+
+	defer cachedFile.Close()
+
+	if err := service.mediaServer.Process(filespec, cachedFile); err != nil {
+		derp.Report(service.cache.Remove(filespec.ProcessedPath()))
+		return derp.Wrap(err, location, "Error processing file", filespec)
+	}
+
+	return nil
+}
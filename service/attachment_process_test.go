@@ -0,0 +1,55 @@
+package service
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/mediaserver"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPutOriginal_StripsMetadata(t *testing.T) {
+
+	// Build a JPEG with a comment segment (standing in for EXIF/GPS data)
+	var buffer bytes.Buffer
+	require.Nil(t, jpeg.Encode(&buffer, image.NewGray(image.Rect(0, 0, 8, 8)), nil))
+	clean := buffer.Bytes()
+
+	comment := []byte("GPS 45.0 -93.0")
+	withMetadata := append([]byte{}, clean[0:2]...)
+	withMetadata = append(withMetadata, 0xFF, 0xFE, 0x00, byte(len(comment)+2))
+	withMetadata = append(withMetadata, comment...)
+	withMetadata = append(withMetadata, clean[2:]...)
+
+	originals := afero.NewMemMapFs()
+	attachmentService := NewAttachment()
+	attachmentService.mediaServer = mediaserver.New(originals, afero.NewMemMapFs(), nil)
+
+	put := func(filename string, keepMetadata bool) []byte {
+		attachment := model.NewAttachment(model.AttachmentObjectTypeStream, primitive.NewObjectID())
+		attachment.Original = filename
+		attachment.Size = int64(len(withMetadata))
+		attachment.Rules.KeepMetadata = keepMetadata
+
+		require.Nil(t, attachmentService.PutOriginal(&attachment, bytes.NewReader(withMetadata)))
+
+		result, err := afero.ReadFile(originals, attachment.AttachmentID.Hex())
+		require.Nil(t, err)
+		require.Equal(t, int64(len(result)), attachment.Size)
+		return result
+	}
+
+	// Images are stored without their metadata
+	require.Equal(t, clean, put("photo.jpg", false))
+
+	// ...unless the Attachment's rules keep it
+	require.Equal(t, withMetadata, put("photo.jpg", true))
+
+	// Other files are stored unchanged
+	require.Equal(t, withMetadata, put("document.pdf", false))
+}
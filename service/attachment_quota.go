@@ -0,0 +1,110 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// bytesPerMegabyte converts storage quotas (configured in megabytes) into bytes
+const bytesPerMegabyte = 1024 * 1024
+
+// CheckQuota returns an error if uploading `size` more bytes would exceed the storage quota
+// for this domain, or for the User who is uploading the files.
+func (service *Attachment) CheckQuota(userID primitive.ObjectID, size int64) error {
+
+	const location = "service.Attachment.CheckQuota"
+
+	// RULE: Uploads cannot exceed the quota for the whole domain
+	if quota := service.domainService.StorageQuota(); quota > 0 {
+
+		used := service.domainService.Get().StorageUsed
+
+		if used+size > quota {
+			return derp.New(http.StatusRequestEntityTooLarge, location, "This upload would exceed the storage quota for this website", quotaDetails(quota, used, size))
+		}
+	}
+
+	// Anonymous uploads are only limited by the domain quota
+	if userID.IsZero() {
+		return nil
+	}
+
+	user := model.NewUser()
+
+	if err := service.userService.LoadByID(userID, &user); err != nil {
+		return derp.Wrap(err, location, "Error loading User", userID)
+	}
+
+	quota, err := service.UserStorageQuota(&user)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error calculating storage quota", userID)
+	}
+
+	// RULE: Uploads cannot exceed the quota for the User's groups
+	if (quota > 0) && (user.StorageUsed+size > quota) {
+		return derp.New(http.StatusRequestEntityTooLarge, location, "This upload would exceed your storage quota", quotaDetails(quota, user.StorageUsed, size))
+	}
+
+	return nil
+}
+
+// UserStorageQuota returns the maximum number of bytes that a User can upload.  This is the
+// largest quota of all the groups that the User belongs to.  Zero means that the User is only
+// limited by the domain quota.
+func (service *Attachment) UserStorageQuota(user *model.User) (int64, error) {
+
+	// Domain owners are only limited by the domain quota
+	if user.IsOwner {
+		return 0, nil
+	}
+
+	groups, err := service.groupService.ListByIDs(user.GroupIDs...)
+
+	if err != nil {
+		return 0, derp.Wrap(err, "service.Attachment.UserStorageQuota", "Error loading groups", user.GroupIDs)
+	}
+
+	result := int64(0)
+
+	for _, group := range groups {
+		result = max(result, group.StorageQuota)
+	}
+
+	return result * bytesPerMegabyte, nil
+}
+
+// addStorageUsed updates the storage counters for the domain and for the User who uploaded
+// an Attachment.  Errors are reported, but do not stop the calling process.
+func (service *Attachment) addStorageUsed(attachment *model.Attachment, delta int64) {
+
+	const location = "service.Attachment.addStorageUsed"
+
+	if delta == 0 {
+		return
+	}
+
+	if err := service.domainService.AddStorageUsed(delta); err != nil {
+		derp.Report(derp.Wrap(err, location, "Error updating domain storage", attachment.AttachmentID))
+	}
+
+	if !attachment.UserID.IsZero() {
+		if err := service.userService.AddStorageUsed(attachment.UserID, delta); err != nil {
+			derp.Report(derp.Wrap(err, location, "Error updating user storage", attachment.AttachmentID))
+		}
+	}
+}
+
+// quotaDetails returns a human-friendly summary of a quota check, in megabytes
+func quotaDetails(quota int64, used int64, size int64) mapof.Any {
+	return mapof.Any{
+		"quotaMB":     quota / bytesPerMegabyte,
+		"usedMB":      used / bytesPerMegabyte,
+		"uploadMB":    size / bytesPerMegabyte,
+		"availableMB": max(quota-used, 0) / bytesPerMegabyte,
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/EmissarySocial/emissary/config"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newTestAttachmentQuota returns an Attachment service for a domain with the provided
+// quota (in megabytes) that has already used (usedMB) of its storage.
+func newTestAttachmentQuota(quotaMB int, usedMB int64) (Attachment, *User, *Group) {
+
+	domainService := NewDomain()
+	domainService.configuration = config.Domain{StorageQuota: quotaMB}
+	domainService.domain.StorageUsed = usedMB * bytesPerMegabyte
+
+	userService := &User{collection: newTestCollection()}
+	groupService := &Group{collection: newTestCollection()}
+
+	result := NewAttachment()
+	result.domainService = &domainService
+	result.userService = userService
+	result.groupService = groupService
+
+	return result, userService, groupService
+}
+
+// newTestQuotaGroup saves a Group with the provided quota (in megabytes)
+func newTestQuotaGroup(t *testing.T, groupService *Group, quotaMB int64) primitive.ObjectID {
+	group := model.NewGroup()
+	group.StorageQuota = quotaMB
+	require.Nil(t, groupService.collection.Save(&group, "test"))
+	return group.GroupID
+}
+
+// newTestQuotaUser saves a User who has already used (usedMB) of their storage
+func newTestQuotaUser(t *testing.T, userService *User, usedMB int64, groupIDs ...primitive.ObjectID) model.User {
+	user := model.NewUser()
+	user.StorageUsed = usedMB * bytesPerMegabyte
+	user.GroupIDs = groupIDs
+	require.Nil(t, userService.collection.Save(&user, "test"))
+	return user
+}
+
+func TestCheckQuota_Domain(t *testing.T) {
+
+	attachmentService, _, _ := newTestAttachmentQuota(10, 8)
+
+	// Anonymous uploads are limited by the domain quota
+	require.Nil(t, attachmentService.CheckQuota(primitive.NilObjectID, 2*bytesPerMegabyte))
+
+	err := attachmentService.CheckQuota(primitive.NilObjectID, 2*bytesPerMegabyte+1)
+	require.Equal(t, http.StatusRequestEntityTooLarge, derp.ErrorCode(err))
+
+	// Domains without a quota are unlimited
+	attachmentService, _, _ = newTestAttachmentQuota(0, 8000)
+	require.Nil(t, attachmentService.CheckQuota(primitive.NilObjectID, 1000*bytesPerMegabyte))
+}
+
+func TestCheckQuota_Group(t *testing.T) {
+
+	attachmentService, userService, groupService := newTestAttachmentQuota(0, 0)
+
+	small := newTestQuotaGroup(t, groupService, 5)
+	large := newTestQuotaGroup(t, groupService, 20)
+
+	// Users are limited by the largest quota of all their groups
+	user := newTestQuotaUser(t, userService, 15, small, large)
+
+	quota, err := attachmentService.UserStorageQuota(&user)
+	require.Nil(t, err)
+	require.Equal(t, int64(20*bytesPerMegabyte), quota)
+
+	require.Nil(t, attachmentService.CheckQuota(user.UserID, 5*bytesPerMegabyte))
+
+	err = attachmentService.CheckQuota(user.UserID, 5*bytesPerMegabyte+1)
+	require.Equal(t, http.StatusRequestEntityTooLarge, derp.ErrorCode(err))
+
+	// Users in a single group are limited by that group
+	user = newTestQuotaUser(t, userService, 4, small)
+
+	err = attachmentService.CheckQuota(user.UserID, 2*bytesPerMegabyte)
+	require.Equal(t, http.StatusRequestEntityTooLarge, derp.ErrorCode(err))
+
+	// Users without any limited groups are only limited by the domain
+	user = newTestQuotaUser(t, userService, 1000)
+	require.Nil(t, attachmentService.CheckQuota(user.UserID, 1000*bytesPerMegabyte))
+}
+
+func TestCheckQuota_Owner(t *testing.T) {
+
+	attachmentService, userService, groupService := newTestAttachmentQuota(100, 50)
+
+	small := newTestQuotaGroup(t, groupService, 5)
+
+	// Owners ignore group quotas...
+	owner := newTestQuotaUser(t, userService, 40, small)
+	owner.IsOwner = true
+	require.Nil(t, userService.collection.Save(&owner, "test"))
+
+	require.Nil(t, attachmentService.CheckQuota(owner.UserID, 50*bytesPerMegabyte))
+
+	// ...but not the domain quota
+	err := attachmentService.CheckQuota(owner.UserID, 50*bytesPerMegabyte+1)
+	require.Equal(t, http.StatusRequestEntityTooLarge, derp.ErrorCode(err))
+}
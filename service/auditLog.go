@@ -0,0 +1,138 @@
+package service
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/data"
+	"github.com/benpate/data/option"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+	"github.com/benpate/steranko"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLog manages the append-only log of security-relevant actions on this domain.
+// Records can be added and queried, but are never updated or deleted.
+type AuditLog struct {
+	collection  data.Collection
+	jwtService  *JWT
+	userService *User
+}
+
+// NewAuditLog returns a fully populated AuditLog service
+func NewAuditLog() AuditLog {
+	return AuditLog{}
+}
+
+/******************************************
+ * Lifecycle Methods
+ ******************************************/
+
+// Refresh updates any stateful data that is cached inside this service.
+func (service *AuditLog) Refresh(collection data.Collection, jwtService *JWT, userService *User) {
+	service.collection = collection
+	service.jwtService = jwtService
+	service.userService = userService
+}
+
+// Close stops any background processes controlled by this service
+func (service *AuditLog) Close() {
+
+}
+
+/******************************************
+ * Common Data Methods
+ ******************************************/
+
+// Count returns the number of records that match the provided criteria
+func (service *AuditLog) Count(criteria exp.Expression) (int64, error) {
+	return service.collection.Count(criteria)
+}
+
+// Query returns a slice containing all of the AuditLogs that match the provided criteria
+func (service *AuditLog) Query(criteria exp.Expression, options ...option.Option) ([]model.AuditLog, error) {
+	result := make([]model.AuditLog, 0)
+	err := service.collection.Query(&result, criteria, options...)
+	return result, err
+}
+
+// Iterator returns an iterator containing all of the AuditLogs that match the provided criteria
+func (service *AuditLog) Iterator(criteria exp.Expression, options ...option.Option) (data.Iterator, error) {
+	return service.collection.Iterator(criteria, options...)
+}
+
+/******************************************
+ * Custom Methods
+ ******************************************/
+
+// Add appends a new record to the audit log.  The request's IP address and User-Agent
+// are added automatically, along with the real actor if a domain owner is masquerading.
+func (service *AuditLog) Add(request *http.Request, auditLog model.AuditLog) error {
+
+	const location = "service.AuditLog.Add"
+
+	// RULE: Audit logs are append-only, so existing records can never be overwritten
+	if auditLog.AuditLogID.IsZero() || auditLog.CreateDate != 0 {
+		return derp.NewInternalError(location, "Audit logs cannot be modified", auditLog)
+	}
+
+	if request != nil {
+		auditLog.IPAddress = auditRealIP(request)
+		auditLog.UserAgent = request.UserAgent()
+
+		// If a domain owner is masquerading, their own certificate is kept in the backup cookie
+		if cookie, err := request.Cookie(steranko.CookieName(request) + "-backup"); err == nil {
+			realActor := model.NewAuthorization()
+			if err := service.jwtService.ParseClaims(cookie.Value, &realActor); err == nil {
+				auditLog.RealActorID = realActor.UserID
+			}
+		}
+	}
+
+	if err := service.collection.Save(&auditLog, ""); err != nil {
+		return derp.Wrap(err, location, "Error saving audit log", auditLog)
+	}
+
+	return nil
+}
+
+// ParseFilter reads a filter from URL query parameters (see model.ParseAuditLogFilter).
+// The "actor" parameter may also identify a User by their username.
+func (service *AuditLog) ParseFilter(values url.Values) model.AuditLogFilter {
+
+	result := model.ParseAuditLogFilter(values)
+
+	if username := strings.TrimSpace(values.Get("actor")); username != "" {
+
+		user := model.NewUser()
+
+		// Unknown Users should match no records, rather than every record
+		if err := service.userService.LoadByUsername(strings.TrimPrefix(username, "@"), &user); err != nil {
+			result.ActorID = primitive.NewObjectID()
+		} else {
+			result.ActorID = user.UserID
+		}
+	}
+
+	return result
+}
+
+// QueryByFilter returns the most recent AuditLogs that match the provided filter
+func (service *AuditLog) QueryByFilter(filter model.AuditLogFilter, options ...option.Option) ([]model.AuditLog, error) {
+	options = append(options, option.SortDesc("createDate"))
+	return service.Query(filter.Criteria(), options...)
+}
+
+// IteratorByFilter returns an iterator of all AuditLogs that match the provided filter, most recent first
+func (service *AuditLog) IteratorByFilter(filter model.AuditLogFilter) (data.Iterator, error) {
+	return service.Iterator(filter.Criteria(), option.SortDesc("createDate"))
+}
+
+// auditRealIP returns the IP address of the client that made the request.  Like the
+// IPExtractor in server.go, it trusts X-Forwarded-For headers only when they are added
+// by proxies on private networks, so that clients cannot forge their own address.
+var auditRealIP = echo.ExtractIPFromXFFHeader()
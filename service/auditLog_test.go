@@ -0,0 +1,40 @@
+package service
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/exp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog_IPAddress(t *testing.T) {
+
+	auditLogService := NewAuditLog()
+	auditLogService.collection = newTestCollection()
+
+	add := func(remoteAddr string, forwardedFor string) string {
+
+		request := httptest.NewRequest("POST", "/signin", nil)
+		request.RemoteAddr = remoteAddr
+		request.Header.Set("X-Forwarded-For", forwardedFor)
+		request.Header.Set("X-Real-Ip", "203.0.113.99")
+
+		auditLog := model.NewAuditLog(model.AuditActionSignInFailed)
+		require.Nil(t, auditLogService.Add(request, auditLog))
+
+		saved := model.AuditLog{}
+		require.Nil(t, auditLogService.collection.Load(exp.Equal("_id", auditLog.AuditLogID), &saved))
+		return saved.IPAddress
+	}
+
+	// Clients cannot choose their own IP address
+	require.Equal(t, "198.51.100.7", add("198.51.100.7:4321", "192.0.2.1"))
+
+	// Headers added by a proxy on a private network are trusted
+	require.Equal(t, "192.0.2.1", add("10.0.0.2:4321", "192.0.2.1"))
+
+	// Requests without a proxy use the remote address
+	require.Equal(t, "198.51.100.7", add("198.51.100.7:4321", ""))
+}
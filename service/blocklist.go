@@ -0,0 +1,392 @@
+package service
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/data"
+	"github.com/benpate/data/option"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+	"github.com/benpate/rosetta/schema"
+	"github.com/benpate/rosetta/sliceof"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Blocklist manages the remote Blocklists that a Domain administrator subscribes to.
+type Blocklist struct {
+	collection     data.Collection
+	ruleService    *Rule
+	activityStream *ActivityStream
+	closed         chan bool
+}
+
+// NewBlocklist returns a fully initialized Blocklist service
+func NewBlocklist() Blocklist {
+	return Blocklist{
+		closed: make(chan bool),
+	}
+}
+
+/******************************************
+ * Lifecycle Methods
+ ******************************************/
+
+// Refresh updates any stateful data that is cached inside this service.
+func (service *Blocklist) Refresh(collection data.Collection, ruleService *Rule, activityStream *ActivityStream) {
+	service.collection = collection
+	service.ruleService = ruleService
+	service.activityStream = activityStream
+}
+
+// Close stops the Blocklist scheduler
+func (service *Blocklist) Close() {
+	close(service.closed)
+}
+
+// Start begins the background scheduler that synchronizes each
+// Blocklist according to its own polling frequency
+func (service *Blocklist) Start() {
+
+	const location = "service.Blocklist.Start"
+
+	// Wait until the service has booted up correctly.
+	for service.collection == nil {
+		time.Sleep(1 * time.Minute)
+	}
+
+	for {
+
+		// Get a list of all Blocklists that are ready to sync
+		it, err := service.ListSyncable()
+
+		if err != nil {
+			derp.Report(derp.Wrap(err, location, "Error listing syncable blocklists"))
+		} else {
+
+			blocklist := model.NewBlocklist()
+
+			for it.Next(&blocklist) {
+
+				if err := service.Sync(&blocklist); err != nil {
+					derp.Report(derp.Wrap(err, location, "Error synchronizing blocklist", blocklist.URL))
+				}
+
+				blocklist = model.NewBlocklist()
+			}
+		}
+
+		// Check for new work once per hour
+		select {
+		case <-service.closed:
+			return
+		case <-time.After(1 * time.Hour):
+		}
+	}
+}
+
+/******************************************
+ * Common Data Methods
+ ******************************************/
+
+// Count returns the number of records that match the provided criteria
+func (service *Blocklist) Count(criteria exp.Expression) (int64, error) {
+	return service.collection.Count(notDeleted(criteria))
+}
+
+// Query returns a slice containing all of the Blocklists that match the provided criteria
+func (service *Blocklist) Query(criteria exp.Expression, options ...option.Option) ([]model.Blocklist, error) {
+	result := make([]model.Blocklist, 0)
+	err := service.collection.Query(&result, notDeleted(criteria), options...)
+	return result, err
+}
+
+// List returns an iterator containing all of the Blocklists that match the provided criteria
+func (service *Blocklist) List(criteria exp.Expression, options ...option.Option) (data.Iterator, error) {
+	return service.collection.Iterator(notDeleted(criteria), options...)
+}
+
+// Load retrieves a Blocklist from the database
+func (service *Blocklist) Load(criteria exp.Expression, blocklist *model.Blocklist) error {
+
+	if err := service.collection.Load(notDeleted(criteria), blocklist); err != nil {
+		return derp.Wrap(err, "service.Blocklist.Load", "Error loading Blocklist", criteria)
+	}
+
+	return nil
+}
+
+// Save adds/updates a Blocklist in the database
+func (service *Blocklist) Save(blocklist *model.Blocklist, note string) error {
+
+	const location = "service.Blocklist.Save"
+
+	// Validate the value before saving
+	if err := service.Schema().Validate(blocklist); err != nil {
+		return derp.Wrap(err, location, "Error validating Blocklist", blocklist)
+	}
+
+	// RULE: New Blocklists are synchronized as soon as possible
+	if blocklist.Status == model.BlocklistStatusNew {
+		blocklist.NextSync = 0
+	}
+
+	if err := service.collection.Save(blocklist, note); err != nil {
+		return derp.Wrap(err, location, "Error saving Blocklist", blocklist, note)
+	}
+
+	return nil
+}
+
+// Delete removes a Blocklist (and all of the Rules that it created) from the database
+func (service *Blocklist) Delete(blocklist *model.Blocklist, note string) error {
+
+	const location = "service.Blocklist.Delete"
+
+	// Remove all Rules imported from this Blocklist
+	if err := service.ruleService.DeleteByBlocklist(blocklist.BlocklistID, note); err != nil {
+		return derp.Wrap(err, location, "Error deleting rules", blocklist.BlocklistID)
+	}
+
+	// Delete the Blocklist itself
+	if err := service.collection.Delete(blocklist, note); err != nil {
+		return derp.Wrap(err, location, "Error deleting Blocklist", blocklist, note)
+	}
+
+	return nil
+}
+
+/******************************************
+ * Model Service Methods
+ ******************************************/
+
+// ObjectType returns the type of object that this service manages
+func (service *Blocklist) ObjectType() string {
+	return "Blocklist"
+}
+
+// ObjectNew returns a fully initialized model.Blocklist as a data.Object.
+func (service *Blocklist) ObjectNew() data.Object {
+	result := model.NewBlocklist()
+	return &result
+}
+
+func (service *Blocklist) ObjectID(object data.Object) primitive.ObjectID {
+
+	if blocklist, ok := object.(*model.Blocklist); ok {
+		return blocklist.BlocklistID
+	}
+
+	return primitive.NilObjectID
+}
+
+func (service *Blocklist) ObjectQuery(result any, criteria exp.Expression, options ...option.Option) error {
+	return service.collection.Query(result, notDeleted(criteria), options...)
+}
+
+func (service *Blocklist) ObjectList(criteria exp.Expression, options ...option.Option) (data.Iterator, error) {
+	return service.List(criteria, options...)
+}
+
+func (service *Blocklist) ObjectLoad(criteria exp.Expression) (data.Object, error) {
+	result := model.NewBlocklist()
+	err := service.Load(criteria, &result)
+	return &result, err
+}
+
+func (service *Blocklist) ObjectSave(object data.Object, note string) error {
+	if blocklist, ok := object.(*model.Blocklist); ok {
+		return service.Save(blocklist, note)
+	}
+	return derp.NewInternalError("service.Blocklist.ObjectSave", "Invalid object type", object)
+}
+
+func (service *Blocklist) ObjectDelete(object data.Object, note string) error {
+	if blocklist, ok := object.(*model.Blocklist); ok {
+		return service.Delete(blocklist, note)
+	}
+	return derp.NewInternalError("service.Blocklist.ObjectDelete", "Invalid object type", object)
+}
+
+func (service *Blocklist) ObjectUserCan(object data.Object, authorization model.Authorization, action string) error {
+	return derp.NewUnauthorizedError("service.Blocklist.ObjectUserCan", "Not Authorized")
+}
+
+func (service *Blocklist) Schema() schema.Schema {
+	return schema.New(model.BlocklistSchema())
+}
+
+/******************************************
+ * Custom Queries
+ ******************************************/
+
+// LoadByID retrieves a single Blocklist by its unique ID
+func (service *Blocklist) LoadByID(blocklistID primitive.ObjectID, result *model.Blocklist) error {
+	return service.Load(exp.Equal("_id", blocklistID), result)
+}
+
+// ListSyncable returns an iterator of all Blocklists that are due to be synchronized
+func (service *Blocklist) ListSyncable() (data.Iterator, error) {
+	criteria := exp.LessOrEqual("nextSync", time.Now().Unix())
+	return service.List(criteria, option.SortAsc("nextSync"))
+}
+
+/******************************************
+ * Synchronization
+ ******************************************/
+
+// Sync downloads the remote Blocklist and calculates the changes that it would make
+// to the domain-wide Rules.  If the Blocklist is set to AutoApply, then changes are
+// applied immediately.  Otherwise, they are saved for an administrator to review.
+func (service *Blocklist) Sync(blocklist *model.Blocklist) error {
+
+	const location = "service.Blocklist.Sync"
+
+	// Retrieve the current version of the remote Blocklist
+	entries, err := service.fetch(blocklist)
+
+	if err != nil {
+		blocklist.MarkSynced(model.BlocklistStatusFailure, err.Error())
+
+		if saveErr := service.Save(blocklist, "Sync failed"); saveErr != nil {
+			derp.Report(derp.Wrap(saveErr, location, "Error saving blocklist", blocklist))
+		}
+
+		return derp.Wrap(err, location, "Error retrieving remote blocklist", blocklist.URL)
+	}
+
+	// Compare the remote Blocklist to the Rules that we've already imported
+	rules, err := service.ruleService.QueryByBlocklist(blocklist.BlocklistID)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading existing rules", blocklist.BlocklistID)
+	}
+
+	blocklist.Pending = blocklistDiff(entries, rules)
+
+	switch {
+
+	// Nothing has changed since the last sync
+	case !blocklist.HasPending():
+		blocklist.MarkSynced(model.BlocklistStatusSuccess, "No changes")
+
+	// Apply changes immediately
+	case blocklist.AutoApply:
+		blocklist.MarkSynced(model.BlocklistStatusSuccess, "")
+		return service.Apply(blocklist)
+
+	// Wait for an administrator to review changes
+	default:
+		message := strconv.Itoa(len(blocklist.PendingAdditions())) + " additions and " + strconv.Itoa(len(blocklist.PendingRemovals())) + " removals waiting for review"
+		blocklist.MarkSynced(model.BlocklistStatusPending, message)
+	}
+
+	if err := service.Save(blocklist, "Synchronized"); err != nil {
+		return derp.Wrap(err, location, "Error saving blocklist", blocklist)
+	}
+
+	return nil
+}
+
+// Apply converts all pending changes in a Blocklist into domain-wide Rules
+func (service *Blocklist) Apply(blocklist *model.Blocklist) error {
+
+	const location = "service.Blocklist.Apply"
+
+	// Remove Rules first, so that changed entries can be re-added
+	for _, entry := range blocklist.PendingRemovals() {
+
+		rule := model.NewRule()
+
+		if err := service.ruleService.LoadByBlocklist(blocklist.BlocklistID, entry.Type, entry.Trigger, &rule); err != nil {
+
+			if derp.NotFound(err) {
+				continue
+			}
+
+			return derp.Wrap(err, location, "Error loading rule", entry)
+		}
+
+		if err := service.ruleService.Delete(&rule, "Removed from Blocklist: "+blocklist.Label); err != nil {
+			return derp.Wrap(err, location, "Error deleting rule", rule)
+		}
+	}
+
+	// Add new Rules
+	for _, entry := range blocklist.PendingAdditions() {
+
+		rule := entry.Rule(blocklist)
+
+		if err := service.ruleService.Save(&rule, "Imported from Blocklist: "+blocklist.Label); err != nil {
+			return derp.Wrap(err, location, "Error saving rule", rule)
+		}
+	}
+
+	// Mark the Blocklist as up to date
+	blocklist.StatusMessage = "Applied " + strconv.Itoa(len(blocklist.Pending)) + " changes"
+	blocklist.Status = model.BlocklistStatusSuccess
+	blocklist.Pending = sliceof.NewObject[model.BlocklistEntry]()
+
+	if err := service.Save(blocklist, "Applied changes"); err != nil {
+		return derp.Wrap(err, location, "Error saving blocklist", blocklist)
+	}
+
+	return nil
+}
+
+// blocklistDiff compares the entries in a remote Blocklist with the Rules that have already
+// been imported from it, and returns the changes needed to bring the Rules up to date.
+func blocklistDiff(entries []model.BlocklistEntry, rules []model.Rule) sliceof.Object[model.BlocklistEntry] {
+
+	result := sliceof.NewObject[model.BlocklistEntry]()
+
+	// Index existing Rules by their type and trigger
+	existing := make(map[string]model.Rule, len(rules))
+
+	for _, rule := range rules {
+		existing[rule.Type+":"+rule.Trigger] = rule
+	}
+
+	// Find new and changed entries
+	found := make(map[string]struct{}, len(entries))
+
+	for _, entry := range entries {
+
+		key := entry.Key()
+		found[key] = struct{}{}
+
+		if rule, ok := existing[key]; ok {
+
+			if rule.Action == entry.Action {
+				continue
+			}
+
+			// Changed entries are removed, then re-added
+			result = append(result, blocklistRemoval(rule))
+		}
+
+		entry.Operation = model.BlocklistEntryOperationAdd
+		result = append(result, entry)
+	}
+
+	// Find removed entries
+	for _, rule := range rules {
+		if _, ok := found[rule.Type+":"+rule.Trigger]; !ok {
+			result = append(result, blocklistRemoval(rule))
+		}
+	}
+
+	return result
+}
+
+// blocklistRemoval returns a BlocklistEntry that removes the provided Rule
+func blocklistRemoval(rule model.Rule) model.BlocklistEntry {
+	return model.BlocklistEntry{
+		Operation: model.BlocklistEntryOperationRemove,
+		Type:      rule.Type,
+		Action:    rule.Action,
+		Trigger:   rule.Trigger,
+		Summary:   rule.Summary,
+	}
+}
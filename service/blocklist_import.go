@@ -0,0 +1,316 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/benpate/domain"
+	"github.com/benpate/hannibal/collections"
+	"github.com/benpate/hannibal/streams"
+	"github.com/benpate/hannibal/vocab"
+	"github.com/benpate/remote"
+	"github.com/benpate/rosetta/first"
+)
+
+// blocklistMaxEntries limits the number of entries that will be imported from a single Blocklist
+const blocklistMaxEntries = 50000
+
+// blocklistMaxSize limits the size (in bytes) of a CSV file that will be downloaded from a remote Blocklist.
+// This is generous for the largest public blocklists, which are a few hundred kilobytes.
+const blocklistMaxSize = 16 * 1024 * 1024
+
+// fetch retrieves the current entries from a remote Blocklist
+func (service *Blocklist) fetch(blocklist *model.Blocklist) ([]model.BlocklistEntry, error) {
+
+	switch blocklist.Format {
+
+	case model.BlocklistFormatActivityPub:
+		return service.fetchActivityPub(blocklist)
+
+	case model.BlocklistFormatCSV:
+		return service.fetchCSV(blocklist)
+	}
+
+	return nil, derp.NewBadRequestError("service.Blocklist.fetch", "Unrecognized Blocklist format", blocklist.Format)
+}
+
+// fetchCSV retrieves a Blocklist that is published as a CSV file
+func (service *Blocklist) fetchCSV(blocklist *model.Blocklist) ([]model.BlocklistEntry, error) {
+
+	const location = "service.Blocklist.fetchCSV"
+
+	var body string
+	txn := remote.Get(blocklist.URL).
+		With(limitResponseSize(blocklistMaxSize)).
+		Result(&body)
+
+	if err := txn.Send(); err != nil {
+		return nil, derp.Wrap(err, location, "Error retrieving CSV file", blocklist.URL)
+	}
+
+	result, err := parseBlocklistCSV(strings.NewReader(body), blocklist.Action)
+
+	if err != nil {
+		return nil, derp.Wrap(err, location, "Error parsing CSV file", blocklist.URL)
+	}
+
+	return result, nil
+}
+
+// fetchActivityPub retrieves a Blocklist that is published as the "blocked" collection of an
+// ActivityPub Actor.  The URL may point to the Actor itself, or directly to the collection.
+func (service *Blocklist) fetchActivityPub(blocklist *model.Blocklist) ([]model.BlocklistEntry, error) {
+
+	const location = "service.Blocklist.fetchActivityPub"
+
+	collection, err := service.activityStream.Load(blocklist.URL)
+
+	if err != nil {
+		return nil, derp.Wrap(err, location, "Error loading ActivityPub document", blocklist.URL)
+	}
+
+	// If this is an Actor, then use its published "blocked" collection
+	if blockedURL := collection.Get("blocked").String(); blockedURL != "" {
+
+		collection, err = service.activityStream.Load(blockedURL)
+
+		if err != nil {
+			return nil, derp.Wrap(err, location, "Error loading blocked collection", blockedURL)
+		}
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	result := make([]model.BlocklistEntry, 0)
+	seen := make(map[string]struct{})
+
+	for document := range collections.Documents(collection, done) {
+
+		if entry, ok := blocklistEntryFromActivity(document, blocklist.Action); ok {
+
+			if _, exists := seen[entry.Key()]; exists {
+				continue
+			}
+
+			seen[entry.Key()] = struct{}{}
+			result = append(result, entry)
+
+			if len(result) >= blocklistMaxEntries {
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// parseBlocklistCSV reads domain blocks from a CSV file in the format used by Mastodon
+// exports and Oliphant blocklists.  A header row (e.g. "#domain,#severity,#public_comment")
+// is optional.  Without one, the first column is the domain and the second is the severity.
+func parseBlocklistCSV(reader io.Reader, defaultAction string) ([]model.BlocklistEntry, error) {
+
+	const location = "service.parseBlocklistCSV"
+
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	csvReader.TrimLeadingSpace = true
+
+	// Default column positions (used when there is no header row)
+	columnDomain := 0
+	columnSeverity := 1
+	columnComment := -1
+
+	result := make([]model.BlocklistEntry, 0)
+	seen := make(map[string]struct{})
+	isFirstRow := true
+
+	for {
+
+		record, err := csvReader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, derp.Wrap(err, location, "Error reading CSV record")
+		}
+
+		if len(record) == 0 {
+			continue
+		}
+
+		// Parse the header row (if present)
+		if isFirstRow {
+			isFirstRow = false
+
+			if isBlocklistCSVHeader(record) {
+				columnDomain, columnSeverity, columnComment = -1, -1, -1
+
+				for index, name := range record {
+					switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "#") {
+					case "domain":
+						columnDomain = index
+					case "severity":
+						columnSeverity = index
+					case "public_comment", "comment":
+						columnComment = index
+					}
+				}
+
+				if columnDomain < 0 {
+					return nil, derp.NewBadRequestError(location, "CSV header must include a 'domain' column", record)
+				}
+
+				continue
+			}
+		}
+
+		hostname := strings.ToLower(domain.NameOnly(strings.TrimSpace(csvColumn(record, columnDomain))))
+
+		// Skip comments, blank lines, and obfuscated domains
+		if (hostname == "") || strings.HasPrefix(hostname, "#") || strings.Contains(hostname, "*") {
+			continue
+		}
+
+		action, ok := blocklistSeverityAction(csvColumn(record, columnSeverity), defaultAction)
+
+		if !ok {
+			continue
+		}
+
+		if _, exists := seen[hostname]; exists {
+			continue
+		}
+
+		seen[hostname] = struct{}{}
+
+		result = append(result, model.BlocklistEntry{
+			Type:    model.RuleTypeDomain,
+			Action:  action,
+			Trigger: hostname,
+			Summary: strings.TrimSpace(csvColumn(record, columnComment)),
+		})
+
+		if len(result) >= blocklistMaxEntries {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// isBlocklistCSVHeader returns TRUE if the provided record is a header row
+func isBlocklistCSVHeader(record []string) bool {
+
+	for _, value := range record {
+		switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), "#") {
+		case "domain", "severity":
+			return true
+		}
+	}
+
+	return false
+}
+
+// blocklistSeverityAction converts a Mastodon severity into a Rule action.
+// It returns FALSE if the entry should not be imported.
+func blocklistSeverityAction(severity string, defaultAction string) (string, bool) {
+
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+
+	case "suspend":
+		return model.RuleActionBlock, true
+
+	case "silence", "limit":
+		return model.RuleActionMute, true
+
+	case "noop":
+		return "", false
+	}
+
+	return defaultAction, true
+}
+
+// blocklistEntryFromActivity converts a published Block activity into a BlocklistEntry
+func blocklistEntryFromActivity(activity streams.Document, defaultAction string) (model.BlocklistEntry, bool) {
+
+	if activity.Type() != vocab.ActivityTypeBlock {
+		return model.BlocklistEntry{}, false
+	}
+
+	object := activity.Object()
+
+	result := model.BlocklistEntry{
+		Action:  defaultAction,
+		Summary: object.Summary(),
+	}
+
+	switch object.Type() {
+
+	// Domain Blocks are represented as Applications, Services, or Organizations
+	case vocab.ActorTypeApplication, vocab.ActorTypeService, vocab.ActorTypeOrganization:
+		result.Type = model.RuleTypeDomain
+		result.Trigger = strings.ToLower(domain.NameOnly(first.String(object.URL(), object.ID())))
+
+	// Content Blocks are represented as Notes
+	case vocab.ObjectTypeNote:
+		result.Type = model.RuleTypeContent
+		result.Trigger = object.Content()
+
+	// Anything else (incl. Null) is treated as an Actor Block
+	default:
+		result.Type = model.RuleTypeActor
+		result.Trigger = object.ID()
+	}
+
+	if result.Trigger == "" {
+		return model.BlocklistEntry{}, false
+	}
+
+	return result, true
+}
+
+// csvColumn safely returns a column from a CSV record
+func csvColumn(record []string, index int) string {
+
+	if (index < 0) || (index >= len(record)) {
+		return ""
+	}
+
+	return record[index]
+}
+
+// limitResponseSize is a remote.Option that rejects response bodies larger than maxSize bytes
+func limitResponseSize(maxSize int64) remote.Option {
+
+	return remote.Option{
+		AfterRequest: func(_ *remote.Transaction, response *http.Response) error {
+
+			const location = "service.limitResponseSize"
+
+			defer response.Body.Close()
+
+			// Read one byte more than the limit, so that oversized responses can be detected
+			body, err := io.ReadAll(io.LimitReader(response.Body, maxSize+1))
+
+			if err != nil {
+				return derp.Wrap(err, location, "Error reading response body")
+			}
+
+			if int64(len(body)) > maxSize {
+				return derp.NewBadRequestError(location, "Response body is too large", maxSize)
+			}
+
+			response.Body = io.NopCloser(bytes.NewReader(body))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/exp"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseBlocklistCSV_Mastodon(t *testing.T) {
+
+	csv := `#domain,#severity,#reject_media,#reject_reports,#public_comment,#obfuscate
+bad.example,suspend,true,true,Spam,false
+noisy.example,silence,false,false,Reply guys,false
+fine.example,noop,false,false,,false
+*.hidden.example,suspend,true,true,,true
+BAD.example,suspend,true,true,Duplicate,false
+`
+
+	entries, err := parseBlocklistCSV(strings.NewReader(csv), model.RuleActionLabel)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(entries))
+
+	require.Equal(t, model.RuleTypeDomain, entries[0].Type)
+	require.Equal(t, "bad.example", entries[0].Trigger)
+	require.Equal(t, model.RuleActionBlock, entries[0].Action)
+	require.Equal(t, "Spam", entries[0].Summary)
+
+	require.Equal(t, "noisy.example", entries[1].Trigger)
+	require.Equal(t, model.RuleActionMute, entries[1].Action)
+	require.Equal(t, "Reply guys", entries[1].Summary)
+}
+
+func TestParseBlocklistCSV_NoHeader(t *testing.T) {
+
+	csv := "one.example\ntwo.example,suspend\n\n# comment\nhttps://three.example/path\n"
+
+	entries, err := parseBlocklistCSV(strings.NewReader(csv), model.RuleActionMute)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(entries))
+
+	require.Equal(t, "one.example", entries[0].Trigger)
+	require.Equal(t, model.RuleActionMute, entries[0].Action)
+
+	require.Equal(t, "two.example", entries[1].Trigger)
+	require.Equal(t, model.RuleActionBlock, entries[1].Action)
+
+	require.Equal(t, "three.example", entries[2].Trigger)
+}
+
+func TestBlocklistDiff(t *testing.T) {
+
+	entries := []model.BlocklistEntry{
+		{Type: model.RuleTypeDomain, Action: model.RuleActionBlock, Trigger: "same.example"},
+		{Type: model.RuleTypeDomain, Action: model.RuleActionBlock, Trigger: "changed.example"},
+		{Type: model.RuleTypeDomain, Action: model.RuleActionBlock, Trigger: "new.example"},
+	}
+
+	rules := []model.Rule{
+		{Type: model.RuleTypeDomain, Action: model.RuleActionBlock, Trigger: "same.example"},
+		{Type: model.RuleTypeDomain, Action: model.RuleActionMute, Trigger: "changed.example"},
+		{Type: model.RuleTypeDomain, Action: model.RuleActionBlock, Trigger: "removed.example"},
+	}
+
+	diff := blocklistDiff(entries, rules)
+	require.Equal(t, 4, len(diff))
+
+	require.Equal(t, model.BlocklistEntryOperationRemove, diff[0].Operation)
+	require.Equal(t, "changed.example", diff[0].Trigger)
+
+	require.Equal(t, model.BlocklistEntryOperationAdd, diff[1].Operation)
+	require.Equal(t, "changed.example", diff[1].Trigger)
+
+	require.Equal(t, model.BlocklistEntryOperationAdd, diff[2].Operation)
+	require.Equal(t, "new.example", diff[2].Trigger)
+
+	require.Equal(t, model.BlocklistEntryOperationRemove, diff[3].Operation)
+	require.Equal(t, "removed.example", diff[3].Trigger)
+}
+
+// newTestBlocklistService returns a Blocklist service (and its Rule service) backed by in-memory collections
+func newTestBlocklistService() (*Blocklist, *Rule) {
+
+	ruleCollection := newTestCollection()
+	userService := &User{collection: newTestCollection(), rules: ruleCollection}
+
+	ruleService := NewRule()
+	ruleService.Refresh(ruleCollection, nil, userService, nil, "local.example")
+
+	blocklistService := NewBlocklist()
+	blocklistService.Refresh(newTestCollection(), &ruleService, nil)
+
+	return &blocklistService, &ruleService
+}
+
+// newTestBlocklist returns a Blocklist with pending additions for every trigger
+func newTestBlocklist(label string, triggers ...string) model.Blocklist {
+
+	blocklist := model.NewBlocklist()
+	blocklist.Label = label
+	blocklist.URL = "https://" + label + ".example/blocklist.csv"
+
+	for _, trigger := range triggers {
+		blocklist.Pending = append(blocklist.Pending, model.BlocklistEntry{
+			Operation: model.BlocklistEntryOperationAdd,
+			Type:      model.RuleTypeDomain,
+			Action:    model.RuleActionBlock,
+			Trigger:   trigger,
+		})
+	}
+
+	return blocklist
+}
+
+// domainRuleOwners returns the BlocklistID of every domain-wide Rule for a trigger
+func domainRuleOwners(t *testing.T, ruleService *Rule, trigger string) []primitive.ObjectID {
+
+	rules, err := ruleService.Query(exp.Equal("userId", primitive.NilObjectID).AndEqual("trigger", trigger))
+	require.Nil(t, err)
+
+	result := make([]primitive.ObjectID, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, rule.BlocklistID)
+	}
+
+	return result
+}
+
+func TestBlocklist_Overlapping(t *testing.T) {
+
+	blocklistService, ruleService := newTestBlocklistService()
+
+	first := newTestBlocklist("first", "bad.example", "only-first.example")
+	second := newTestBlocklist("second", "bad.example", "only-second.example")
+
+	require.Nil(t, blocklistService.Save(&first, "test"))
+	require.Nil(t, blocklistService.Save(&second, "test"))
+	require.Nil(t, blocklistService.Apply(&first))
+	require.Nil(t, blocklistService.Apply(&second))
+
+	// Each Blocklist owns its own copy of the overlapping Rule
+	require.ElementsMatch(t, []primitive.ObjectID{first.BlocklistID, second.BlocklistID}, domainRuleOwners(t, ruleService, "bad.example"))
+
+	// ...so neither Blocklist has changes left pending after the next sync
+	for _, blocklist := range []model.Blocklist{first, second} {
+		rules, err := ruleService.QueryByBlocklist(blocklist.BlocklistID)
+		require.Nil(t, err)
+		require.Equal(t, 2, len(rules))
+
+		entries := []model.BlocklistEntry{
+			{Type: model.RuleTypeDomain, Action: model.RuleActionBlock, Trigger: "bad.example"},
+			{Type: model.RuleTypeDomain, Action: model.RuleActionBlock, Trigger: "only-" + blocklist.Label + ".example"},
+		}
+		require.Empty(t, blocklistDiff(entries, rules))
+	}
+
+	// Removing one Blocklist keeps the blocks that the other still lists
+	require.Nil(t, blocklistService.Delete(&first, "test"))
+	require.Equal(t, []primitive.ObjectID{second.BlocklistID}, domainRuleOwners(t, ruleService, "bad.example"))
+	require.Empty(t, domainRuleOwners(t, ruleService, "only-first.example"))
+	require.Equal(t, []primitive.ObjectID{second.BlocklistID}, domainRuleOwners(t, ruleService, "only-second.example"))
+}
+
+func TestBlocklist_ManualRule(t *testing.T) {
+
+	blocklistService, ruleService := newTestBlocklistService()
+
+	blocklist := newTestBlocklist("shared", "bad.example", "worse.example")
+	require.Nil(t, blocklistService.Save(&blocklist, "test"))
+	require.Nil(t, blocklistService.Apply(&blocklist))
+
+	// Imported Rules are labeled with their Blocklist, not a Following
+	rules, err := ruleService.QueryByBlocklist(blocklist.BlocklistID)
+	require.Nil(t, err)
+	require.Equal(t, "shared", rules[0].BlocklistLabel)
+	require.Empty(t, rules[0].FollowingLabel)
+	require.False(t, rules[0].OriginUser())
+
+	// An administrator can add the same Rule by hand (before or after the import)
+	manual := model.NewRule()
+	manual.Type = model.RuleTypeDomain
+	manual.Action = model.RuleActionBlock
+	manual.Trigger = "bad.example"
+	require.True(t, manual.OriginUser())
+	require.Nil(t, ruleService.Save(&manual, "test"))
+
+	require.ElementsMatch(t, []primitive.ObjectID{primitive.NilObjectID, blocklist.BlocklistID}, domainRuleOwners(t, ruleService, "bad.example"))
+
+	// Saving the manual Rule again does not create another copy
+	duplicate := manual
+	duplicate.RuleID = primitive.NewObjectID()
+	duplicate.Journal = manual.Journal
+	duplicate.CreateDate = 0
+	require.Nil(t, ruleService.Save(&duplicate, "test"))
+	require.Equal(t, 2, len(domainRuleOwners(t, ruleService, "bad.example")))
+
+	// Removing the Blocklist keeps the manual Rule
+	require.Nil(t, blocklistService.Delete(&blocklist, "test"))
+	require.Equal(t, []primitive.ObjectID{primitive.NilObjectID}, domainRuleOwners(t, ruleService, "bad.example"))
+	require.Empty(t, domainRuleOwners(t, ruleService, "worse.example"))
+}
+
+func TestBlocklist_FetchCSV_MaxSize(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		size := blocklistMaxSize
+		if r.URL.Path == "/large.csv" {
+			size = blocklistMaxSize + 1
+		}
+		_, _ = w.Write([]byte("bad.example\n" + strings.Repeat("#", size-len("bad.example\n"))))
+	}))
+	defer server.Close()
+
+	blocklistService, _ := newTestBlocklistService()
+
+	blocklist := model.NewBlocklist()
+	blocklist.URL = server.URL + "/small.csv"
+	entries, err := blocklistService.fetchCSV(&blocklist)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(entries))
+
+	blocklist.URL = server.URL + "/large.csv"
+	_, err = blocklistService.fetchCSV(&blocklist)
+	require.NotNil(t, err)
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/benpate/data"
+	"github.com/benpate/data/option"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+	"github.com/benpate/rosetta/compare"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// testCollection is an in-memory data.Collection for service tests.  Records are stored as BSON,
+// so criteria are matched against the same field names (and inline structs) that MongoDB uses.
+type testCollection struct {
+	records *[][]byte
+}
+
+func newTestCollection() testCollection {
+	return testCollection{records: &[][]byte{}}
+}
+
+func (collection testCollection) Count(criteria exp.Expression, _ ...option.Option) (int64, error) {
+
+	var result int64
+
+	for _, record := range *collection.records {
+		if collection.match(record, criteria) {
+			result++
+		}
+	}
+
+	return result, nil
+}
+
+// Query populates a pointer to a slice with every matching record
+func (collection testCollection) Query(target any, criteria exp.Expression, _ ...option.Option) error {
+
+	slice := reflect.ValueOf(target).Elem()
+
+	for _, record := range *collection.records {
+
+		if !collection.match(record, criteria) {
+			continue
+		}
+
+		item := reflect.New(slice.Type().Elem())
+
+		if err := bson.Unmarshal(record, item.Interface()); err != nil {
+			return derp.Wrap(err, "testCollection.Query", "Error decoding record")
+		}
+
+		slice.Set(reflect.Append(slice, item.Elem()))
+	}
+
+	return nil
+}
+
+// Iterator returns an iterator over every matching record
+func (collection testCollection) Iterator(criteria exp.Expression, _ ...option.Option) (data.Iterator, error) {
+
+	result := testIterator{}
+
+	for _, record := range *collection.records {
+		if collection.match(record, criteria) {
+			result.records = append(result.records, record)
+		}
+	}
+
+	return &result, nil
+}
+
+func (collection testCollection) Load(criteria exp.Expression, target data.Object) error {
+
+	for _, record := range *collection.records {
+		if collection.match(record, criteria) {
+			return bson.Unmarshal(record, target)
+		}
+	}
+
+	return derp.NewNotFoundError("testCollection.Load", "Record not found", criteria)
+}
+
+func (collection testCollection) Save(object data.Object, note string) error {
+
+	if object.IsNew() {
+		object.SetCreated(note)
+	} else {
+		object.SetUpdated(note)
+	}
+
+	record, err := bson.Marshal(object)
+
+	if err != nil {
+		return derp.Wrap(err, "testCollection.Save", "Error encoding record")
+	}
+
+	criteria := exp.Equal("_id", reflect.ValueOf(object).Elem().FieldByName(idField(object)).Interface())
+
+	for index, existing := range *collection.records {
+		if collection.match(existing, criteria) {
+			(*collection.records)[index] = record
+			return nil
+		}
+	}
+
+	*collection.records = append(*collection.records, record)
+	return nil
+}
+
+// Delete virtually deletes a record, the same way that the MongoDB adapter does
+func (collection testCollection) Delete(object data.Object, note string) error {
+	object.SetDeleted(note)
+	return collection.Save(object, note)
+}
+
+func (collection testCollection) HardDelete(criteria exp.Expression) error {
+
+	*collection.records = slices.DeleteFunc(*collection.records, func(record []byte) bool {
+		return collection.match(record, criteria)
+	})
+
+	return nil
+}
+
+// match returns TRUE if a BSON record matches the criteria
+func (collection testCollection) match(record []byte, criteria exp.Expression) bool {
+
+	if criteria == nil {
+		return true
+	}
+
+	document := bson.M{}
+
+	if err := bson.Unmarshal(record, &document); err != nil {
+		return false
+	}
+
+	return criteria.Match(func(predicate exp.Predicate) bool {
+
+		value, exists := document[predicate.Field]
+
+		// Missing fields never match, except in "not equal" comparisons
+		if !exists {
+			return predicate.Operator == exp.OperatorNotEqual
+		}
+
+		switch predicate.Operator {
+		case exp.OperatorIn:
+			return matchAny(value, predicate.Value)
+		case exp.OperatorNotIn:
+			return !matchAny(value, predicate.Value)
+		}
+
+		result, _ := compare.WithOperator(value, predicate.Operator, predicate.Value)
+		return result
+	})
+}
+
+// matchAny returns TRUE if a value equals any item in a slice of values
+func matchAny(value any, values any) bool {
+
+	slice := reflect.ValueOf(values)
+
+	if slice.Kind() != reflect.Slice {
+		return false
+	}
+
+	// Values of the same type (such as ObjectIDs) are matched without conversion
+	valueType := reflect.TypeOf(value)
+	direct := valueType.Comparable() && (valueType == slice.Type().Elem())
+
+	for index := range slice.Len() {
+
+		item := slice.Index(index).Interface()
+
+		if direct {
+			if value == item {
+				return true
+			}
+			continue
+		}
+
+		if result, _ := compare.WithOperator(value, exp.OperatorEqual, item); result {
+			return true
+		}
+	}
+
+	return false
+}
+
+// idField returns the name of the struct field that is stored as the "_id" of a record
+func idField(object data.Object) string {
+
+	structure := reflect.TypeOf(object).Elem()
+
+	for index := range structure.NumField() {
+		if field := structure.Field(index); field.Tag.Get("bson") == "_id" {
+			return field.Name
+		}
+	}
+
+	return ""
+}
+
+// testIterator is a data.Iterator over a fixed list of BSON records
+type testIterator struct {
+	records [][]byte
+	err     error
+}
+
+func (iterator *testIterator) Next(target any) bool {
+
+	if (iterator.err != nil) || (len(iterator.records) == 0) {
+		return false
+	}
+
+	record := iterator.records[0]
+	iterator.records = iterator.records[1:]
+
+	if err := bson.Unmarshal(record, target); err != nil {
+		iterator.err = derp.Wrap(err, "testIterator.Next", "Error decoding record")
+		return false
+	}
+
+	return true
+}
+
+func (iterator *testIterator) Error() error {
+	return iterator.err
+}
+
+func (iterator *testIterator) Count() int {
+	return len(iterator.records)
+}
+
+func (iterator *testIterator) Close() error {
+	return nil
+}
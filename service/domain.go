@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 	"html/template"
+	"maps"
+	"regexp"
+	"strings"
 
 	"github.com/EmissarySocial/emissary/config"
 	"github.com/EmissarySocial/emissary/model"
@@ -22,6 +25,9 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// secretNamePattern limits the names of domain secrets, which are also used in template configuration
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
 // Domain service manages all access to the singleton model.Domain in the database
 type Domain struct {
 	collection          data.Collection
@@ -175,6 +181,9 @@ func (service *Domain) Save(domain model.Domain, note string) error {
 		return derp.Wrap(err, "service.Domain.Save", "Error validating Domain with custom schema from Theme", domain)
 	}
 
+	// RULE: Storage usage is only changed via AddStorageUsed
+	domain.StorageUsed = service.domain.StorageUsed
+
 	// Try to save the value to the database
 	if err := service.collection.Save(&domain, note); err != nil {
 		return derp.Wrap(err, "service.Domain.Save", "Error saving Domain")
@@ -186,6 +195,89 @@ func (service *Domain) Save(domain model.Domain, note string) error {
 	return nil
 }
 
+// StorageQuota returns the maximum number of bytes that all attachments on this
+// domain can use, as set by the server administrator.  Zero means unlimited.
+func (service *Domain) StorageQuota() int64 {
+	return int64(service.configuration.StorageQuota) * bytesPerMegabyte
+}
+
+// SetSecret adds or updates a write-only secret that the "call-url" step can send
+func (service *Domain) SetSecret(name string, value string) error {
+
+	const location = "service.Domain.SetSecret"
+
+	if !secretNamePattern.MatchString(name) {
+		return derp.NewBadRequestError(location, "Secret names can only include letters, numbers, dashes, underscores, and dots", name)
+	}
+
+	if value == "" {
+		return derp.NewBadRequestError(location, "Secret value is required", name)
+	}
+
+	// Copy the secrets so that the in-memory cache is only updated by a successful save
+	domain := service.Get()
+	domain.Secrets = maps.Clone(domain.Secrets)
+
+	if domain.Secrets == nil {
+		domain.Secrets = mapof.NewString()
+	}
+
+	domain.Secrets[name] = value
+
+	if err := service.Save(domain, "Updated secret"); err != nil {
+		return derp.Wrap(err, location, "Error saving Domain", name)
+	}
+
+	return nil
+}
+
+// RemoveSecret removes a write-only secret from this domain
+func (service *Domain) RemoveSecret(name string) error {
+
+	const location = "service.Domain.RemoveSecret"
+
+	domain := service.Get()
+
+	if _, exists := domain.Secrets[name]; !exists {
+		return nil
+	}
+
+	domain.Secrets = maps.Clone(domain.Secrets)
+	delete(domain.Secrets, name)
+
+	if err := service.Save(domain, "Removed secret"); err != nil {
+		return derp.Wrap(err, location, "Error saving Domain", name)
+	}
+
+	return nil
+}
+
+// SetOIDCClientSecret updates the write-only client secret for the OpenID Connect
+// provider.  An empty value removes the secret.
+func (service *Domain) SetOIDCClientSecret(value string) error {
+
+	domain := service.Get()
+	domain.OIDCClientSecret = strings.TrimSpace(value)
+
+	if err := service.Save(domain, "Updated OpenID Connect client secret"); err != nil {
+		return derp.Wrap(err, "service.Domain.SetOIDCClientSecret", "Error saving Domain")
+	}
+
+	return nil
+}
+
+// AddStorageUsed adds (or subtracts) a number of bytes from the storage used by this domain
+func (service *Domain) AddStorageUsed(delta int64) error {
+
+	if err := queries.IncrementStorageUsed(service.collection, exp.All(), delta); err != nil {
+		return derp.Wrap(err, "service.Domain.AddStorageUsed", "Error updating storage used", delta)
+	}
+
+	// Update the in-memory cache
+	service.domain.StorageUsed += delta
+	return nil
+}
+
 /******************************************
  * Generic Data Methods
  ******************************************/
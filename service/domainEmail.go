@@ -75,6 +75,7 @@ func (service *DomainEmail) SendWelcome(txn model.RegistrationTxn) error {
 			"Domain_URL":   domain.Host(),
 			"Domain_Name":  domain.Label,
 			"Domain_Icon":  domain.IconURL(),
+			"Locale":       "",
 		},
 	)
 
@@ -112,6 +113,7 @@ func (service *DomainEmail) SendPasswordReset(user *model.User) error {
 			"Domain_URL":   domain.Host(),
 			"Domain_Name":  domain.Label,
 			"Domain_Icon":  domain.IconURL(),
+			"Locale":       user.Locale,
 		},
 	)
 
@@ -147,6 +149,7 @@ func (service *DomainEmail) SendFollowerConfirmation(actor model.PersonLink, fol
 			"Domain_URL":   domain.Host(),
 			"Domain_Name":  domain.Label,
 			"Domain_Icon":  domain.IconURL(),
+			"Locale":       "",
 		},
 	)
 
@@ -186,6 +189,7 @@ func (service *DomainEmail) SendFollowerActivity(follower model.Follower, activi
 			"Domain_URL":   domain.Host(),
 			"Domain_Name":  domain.Label,
 			"Domain_Icon":  domain.IconURL(),
+			"Locale":       "",
 			"Unsubscribe":  follower.UnsubscribeLink(domain.Host()),
 		},
 	)
@@ -21,14 +21,15 @@ import (
 // Follower defines a service that tracks the (possibly external) accounts that are followers of an internal User
 
 type Follower struct {
-	collection      data.Collection
-	userService     *User
-	ruleService     *Rule
-	streamService   *Stream
-	domainEmail     *DomainEmail
-	activityService *ActivityStream
-	queue           *queue.Queue
-	host            string
+	collection         data.Collection
+	userService        *User
+	ruleService        *Rule
+	streamService      *Stream
+	savedSearchService *SavedSearch
+	domainEmail        *DomainEmail
+	activityService    *ActivityStream
+	queue              *queue.Queue
+	host               string
 }
 
 // NewFollower returns a fully initialized Follower service
@@ -41,10 +42,11 @@ func NewFollower() Follower {
  ******************************************/
 
 // Refresh updates any stateful data that is cached inside this service.
-func (service *Follower) Refresh(collection data.Collection, userService *User, streamService *Stream, ruleService *Rule, domainEmail *DomainEmail, activityService *ActivityStream, queue *queue.Queue, host string) {
+func (service *Follower) Refresh(collection data.Collection, userService *User, streamService *Stream, savedSearchService *SavedSearch, ruleService *Rule, domainEmail *DomainEmail, activityService *ActivityStream, queue *queue.Queue, host string) {
 	service.collection = collection
 	service.userService = userService
 	service.streamService = streamService
+	service.savedSearchService = savedSearchService
 	service.ruleService = ruleService
 	service.domainEmail = domainEmail
 	service.activityService = activityService
@@ -398,6 +400,15 @@ func (service *Follower) LoadParentActor(follower *model.Follower) (model.Person
 
 		return stream.ActorLink(), nil
 
+	case model.FollowerTypeSearch:
+
+		savedSearch := model.NewSavedSearch()
+		if err := service.savedSearchService.LoadByID(follower.ParentID, &savedSearch); err != nil {
+			return model.PersonLink{}, derp.Wrap(err, "service.Follower.LoadParentActor", "Error loading parent saved search", follower)
+		}
+
+		return savedSearch.ActorLink(), nil
+
 	}
 
 	return model.PersonLink{}, derp.NewInternalError("service.Follower.LoadParentActor", "Invalid parentType", follower)
@@ -445,6 +456,7 @@ func (service *Follower) NewActivityPubFollower(parentType string, parentID prim
 		Username:     actor.UsernameOrID(),
 		IconURL:      actor.IconOrImage().URL(),
 		InboxURL:     actor.Get("inbox").String(),
+		SharedInbox:  actor.Endpoints().Get(model.PropertySharedInbox).String(),
 		EmailAddress: actor.Get("email").String(),
 	}
 
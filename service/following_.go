@@ -1,6 +1,7 @@
 package service
 
 import (
+	"iter"
 	"math/rand"
 	"time"
 
@@ -321,6 +322,22 @@ func (service *Following) ListActivityPub(userID primitive.ObjectID, options ...
 	return service.List(criteria, options...)
 }
 
+// RangeActivityPubByURL returns a RangeFunc that iterates over every local User's Following record
+// for the provided ActivityPub profile URL.  This is used to route messages received by the shared inbox.
+func (service *Following) RangeActivityPubByURL(profileURL string) (iter.Seq[model.Following], error) {
+
+	criteria := exp.Equal("profileUrl", profileURL).
+		AndEqual("method", model.FollowingMethodActivityPub)
+
+	it, err := service.List(criteria)
+
+	if err != nil {
+		return nil, derp.Wrap(err, "service.Following.RangeActivityPubByURL", "Error creating iterator", profileURL)
+	}
+
+	return RangeFunc(it, model.NewFollowing), nil
+}
+
 /******************************************
  * Custom Queries
  ******************************************/
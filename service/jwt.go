@@ -131,6 +131,52 @@ func (service *JWT) ParseString(tokenString string) (*jwt.Token, error) {
 	return result, nil
 }
 
+// NewToken signs a custom set of claims using the current key
+func (service *JWT) NewToken(claims jwt.Claims) (string, error) {
+
+	const location = "service.JWT.NewToken"
+
+	keyName, key, err := service.GetCurrentKey()
+
+	if err != nil {
+		return "", derp.Wrap(err, location, "Error getting JWT key")
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = keyName
+
+	result, err := token.SignedString(key)
+
+	if err != nil {
+		return "", derp.Wrap(err, location, "Error signing JWT token")
+	}
+
+	return result, nil
+}
+
+// ParseClaims parses and validates a token that was created by NewToken
+func (service *JWT) ParseClaims(tokenString string, claims jwt.Claims) error {
+
+	const location = "service.JWT.ParseClaims"
+
+	// RULE: JWT token must not be empty
+	if tokenString == "" {
+		return derp.NewBadRequestError(location, "JWT token is empty")
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, service.FindKey, jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}))
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error parsing JWT token", derp.WithCode(http.StatusBadRequest))
+	}
+
+	if !token.Valid {
+		return derp.NewBadRequestError(location, "Invalid JWT token")
+	}
+
+	return nil
+}
+
 /******************************************
  * Database Methods
  ******************************************/
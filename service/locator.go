@@ -11,16 +11,18 @@ import (
 )
 
 type Locator struct {
-	userService   *User
-	streamService *Stream
-	host          string
+	userService        *User
+	streamService      *Stream
+	savedSearchService *SavedSearch
+	host               string
 }
 
-func NewLocator(userService *User, streamService *Stream, host string) Locator {
+func NewLocator(userService *User, streamService *Stream, savedSearchService *SavedSearch, host string) Locator {
 	return Locator{
-		userService:   userService,
-		streamService: streamService,
-		host:          host,
+		userService:        userService,
+		streamService:      streamService,
+		savedSearchService: savedSearchService,
+		host:               host,
 	}
 }
 
@@ -69,6 +71,16 @@ func (service Locator) GetObjectFromURL(value string) (string, primitive.ObjectI
 
 		return "Stream", stream.StreamID, nil
 
+	case "Search":
+
+		savedSearch := model.NewSavedSearch()
+
+		if err := service.savedSearchService.LoadByToken(token, &savedSearch); err != nil {
+			return "", primitive.NilObjectID, derp.Wrap(err, location, "Error loading saved search", token)
+		}
+
+		return "Search", savedSearch.SavedSearchID, nil
+
 	}
 
 	// Fall through is failure.  Feel bad.
@@ -86,6 +98,11 @@ func getObjectFromURL(value *url.URL) (string, string) {
 		return "Stream", "home"
 	}
 
+	// Paths beginning with "/.searches/" are saved searches
+	if token == ".searches" {
+		return "Search", list.Slash(path).Tail().First()
+	}
+
 	// Token starting with "@" is a user
 	if strings.HasPrefix(token, "@") {
 		return "User", strings.TrimPrefix(token, "@")
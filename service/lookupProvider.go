@@ -4,6 +4,7 @@ import (
 	"github.com/EmissarySocial/emissary/model"
 	"github.com/EmissarySocial/emissary/tools/dataset"
 	"github.com/benpate/form"
+	"github.com/benpate/hannibal/vocab"
 	"github.com/benpate/rosetta/list"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -42,6 +43,12 @@ func (service LookupProvider) Group(path string) form.LookupGroup {
 			form.LookupCode{Value: "POSTS", Label: "Posts Only (ignore replies)"},
 		)
 
+	case "blocklist-formats":
+		return form.NewReadOnlyLookupGroup(
+			form.LookupCode{Value: model.BlocklistFormatCSV, Label: "CSV File (Mastodon compatible)"},
+			form.LookupCode{Value: model.BlocklistFormatActivityPub, Label: "ActivityPub Collection"},
+		)
+
 	case "following-rule-actions":
 		return form.NewReadOnlyLookupGroup(
 			form.LookupCode{Value: "IGNORE", Label: "Do not import rules from this source (display messages normally)"},
@@ -102,6 +109,18 @@ func (service LookupProvider) Group(path string) form.LookupGroup {
 			form.LookupCode{Value: "-1", Label: "Blocked", Description: "Users cannot see this tag at all."},
 		)
 
+	case "search-types":
+		return form.NewReadOnlyLookupGroup(
+			form.LookupCode{Value: vocab.ObjectTypeArticle, Label: "Articles"},
+			form.LookupCode{Value: vocab.ObjectTypeNote, Label: "Notes"},
+			form.LookupCode{Value: vocab.ObjectTypePage, Label: "Pages"},
+			form.LookupCode{Value: vocab.ObjectTypeImage, Label: "Images"},
+			form.LookupCode{Value: vocab.ObjectTypeVideo, Label: "Videos"},
+			form.LookupCode{Value: vocab.ObjectTypeAudio, Label: "Audio"},
+			form.LookupCode{Value: vocab.ObjectTypeEvent, Label: "Events"},
+			form.LookupCode{Value: vocab.ActorTypePerson, Label: "People"},
+		)
+
 	case "searchTag-groups":
 		return form.ReadOnlyLookupGroup(service.searchTagService.ListGroups())
 
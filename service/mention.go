@@ -4,19 +4,27 @@ import (
 	"bytes"
 	"io"
 	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/hentry"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/benpate/data"
 	"github.com/benpate/data/option"
 	"github.com/benpate/derp"
+	"github.com/benpate/domain"
 	"github.com/benpate/exp"
+	"github.com/benpate/hannibal/streams"
 	"github.com/benpate/remote"
+	"github.com/benpate/rosetta/first"
 	"github.com/benpate/rosetta/list"
+	"github.com/benpate/rosetta/mapof"
 	"github.com/benpate/rosetta/schema"
 	"github.com/benpate/rosetta/slice"
+	"github.com/benpate/rosetta/sliceof"
 	"github.com/benpate/sherlock"
+	"github.com/benpate/turbine/queue"
 	"github.com/tomnomnom/linkheader"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -35,10 +43,13 @@ import (
 
 // Mention defines a service that can send and receive mention data
 type Mention struct {
-	collection      data.Collection
-	ruleService     *Rule
-	activityService *ActivityStream
-	host            string
+	collection       data.Collection
+	followingService *Following
+	responseService  *Response
+	ruleService      *Rule
+	activityService  *ActivityStream
+	queue            *queue.Queue
+	host             string
 }
 
 // NewMention returns a fully initialized Mention service
@@ -51,10 +62,13 @@ func NewMention() Mention {
  ******************************************/
 
 // Refresh updates any stateful data that is cached inside this service.
-func (service *Mention) Refresh(collection data.Collection, ruleService *Rule, activityService *ActivityStream, host string) {
+func (service *Mention) Refresh(collection data.Collection, followingService *Following, responseService *Response, ruleService *Rule, activityService *ActivityStream, queue *queue.Queue, host string) {
 	service.collection = collection
+	service.followingService = followingService
+	service.responseService = responseService
 	service.ruleService = ruleService
 	service.activityService = activityService
+	service.queue = queue
 	service.host = host
 }
 
@@ -102,11 +116,31 @@ func (service *Mention) Save(mention *model.Mention, note string) error {
 		return derp.Wrap(err, "service.Mention.Save", "Error validating Mention", mention)
 	}
 
+	// Load the previous version (if any) to see what has changed
+	previous := model.Mention{}
+
+	if err := service.collection.Load(exp.Equal("_id", mention.MentionID), &previous); err != nil && !derp.NotFound(err) {
+		return derp.Wrap(err, "service.Mention.Save", "Error loading previous Mention", mention.MentionID)
+	}
+
 	// Save the value to the database
 	if err := service.collection.Save(mention, note); err != nil {
 		return derp.Wrap(err, "service.Mention.Save", "Error saving Mention", mention, note)
 	}
 
+	// Likes and Reposts are also stored as Responses
+	if err := service.syncResponse(mention); err != nil {
+		return derp.Wrap(err, "service.Mention.Save", "Error saving Response", mention)
+	}
+
+	// Approving (or changing) a Mention changes the content of the mentioned Stream, so let upstream sites know.
+	// Unchanged Mentions are not sent again, so that sites that link to each other do not ping each other forever.
+	if mention.IsValidated() != previous.IsValidated() {
+		service.sendSalmention(mention)
+	} else if mention.IsValidated() && !mention.DisplayEquals(previous) {
+		service.sendSalmention(mention)
+	}
+
 	return nil
 }
 
@@ -120,6 +154,16 @@ func (service *Mention) Delete(mention *model.Mention, note string) error {
 		return derp.Wrap(err, "service.Mention.Delete", "Error deleting Mention", criteria)
 	}
 
+	// Remove the matching Response (if any)
+	if err := service.deleteResponse(mention); err != nil {
+		return derp.Wrap(err, "service.Mention.Delete", "Error deleting Response", mention)
+	}
+
+	// Removing an approved Mention also changes the content of the mentioned Stream
+	if mention.IsValidated() {
+		service.sendSalmention(mention)
+	}
+
 	return nil
 }
 
@@ -220,10 +264,213 @@ func (service *Mention) LoadOrCreate(objectType string, objectID primitive.Objec
 	return result, derp.Wrap(err, "service.Mention.LoadOrCreate", "Error loading Mention", objectType, objectID, originURL)
 }
 
+// LoadByToken loads a Mention that is moderated by the provided User
+func (service *Mention) LoadByToken(userID primitive.ObjectID, token string, result *model.Mention) error {
+
+	mentionID, err := primitive.ObjectIDFromHex(token)
+
+	if err != nil {
+		return derp.Wrap(err, "service.Mention.LoadByToken", "Invalid Mention ID", token)
+	}
+
+	criteria := exp.Equal("_id", mentionID).AndEqual("userId", userID)
+	return service.Load(criteria, result)
+}
+
 func (service *Mention) QueryByObjectID(objectID primitive.ObjectID, options ...option.Option) ([]model.Mention, error) {
 	return service.Query(exp.Equal("objectId", objectID), options...)
 }
 
+// QueryValidatedByObjectID returns all approved Mentions of the provided object.  These are safe to display publicly.
+func (service *Mention) QueryValidatedByObjectID(objectID primitive.ObjectID, options ...option.Option) ([]model.Mention, error) {
+	criteria := exp.Equal("objectId", objectID).AndEqual("stateId", model.MentionStatusValidated)
+	return service.Query(criteria, options...)
+}
+
+// QueryPendingByUser returns all Mentions that are waiting for the provided User to approve them.
+// Mentions that were received before moderation existed (with no stateId) are included, too.
+func (service *Mention) QueryPendingByUser(userID primitive.ObjectID, options ...option.Option) ([]model.Mention, error) {
+	criteria := exp.Equal("userId", userID).AndIn("stateId", []string{model.MentionStatusPending, ""})
+	return service.Query(criteria, options...)
+}
+
+// QueryRepliesBeforeDate returns approved WebMention replies to the provided object (newest first)
+// as ActivityStreams documents, so that they can be displayed alongside ActivityPub replies.
+func (service *Mention) QueryRepliesBeforeDate(objectID primitive.ObjectID, maxDate int64, maxRows int) sliceof.Object[streams.Document] {
+
+	const location = "service.Mention.QueryRepliesBeforeDate"
+
+	criteria := exp.Equal("objectId", objectID).
+		AndEqual("stateId", model.MentionStatusValidated).
+		AndIn("kind", []string{model.MentionKindReply, model.MentionKindRSVP}).
+		AndLessThan("publishDate", maxDate)
+
+	mentions, err := service.Query(criteria, option.SortDesc("publishDate"), option.MaxRows(int64(maxRows)))
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, location, "Error loading replies", objectID))
+		return sliceof.NewObject[streams.Document]()
+	}
+
+	return slice.Map(mentions, func(mention model.Mention) streams.Document {
+		return service.activityService.NewDocument(mention.GetJSONLD())
+	})
+}
+
+// MergeReplies combines two lists of replies (each sorted newest first) into a single
+// list, sorted newest first and limited to `maxRows` documents.
+func MergeReplies(replies sliceof.Object[streams.Document], others sliceof.Object[streams.Document], maxRows int) sliceof.Object[streams.Document] {
+
+	result := make(sliceof.Object[streams.Document], 0, len(replies)+len(others))
+	result = append(result, replies...)
+	result = append(result, others...)
+
+	sort.SliceStable(result, func(i int, j int) bool {
+		return result[i].Published().After(result[j].Published())
+	})
+
+	if len(result) > maxRows {
+		result = result[:maxRows]
+	}
+
+	return result
+}
+
+// CountPendingByUser returns the number of Mentions that are waiting for the provided User to approve them.
+func (service *Mention) CountPendingByUser(userID primitive.ObjectID) (int64, error) {
+	criteria := exp.Equal("userId", userID).AndIn("stateId", []string{model.MentionStatusPending, ""})
+	return service.Count(criteria)
+}
+
+/******************************************
+ * Moderation Methods
+ ******************************************/
+
+// Moderate sets the moderation state of a Mention that was received via WebMention.
+// New Mentions are held for moderation unless they come from a trusted (or vouched-for) source.
+// Existing Mentions keep their current state, so rejected Mentions stay rejected.
+func (service *Mention) Moderate(mention *model.Mention, userID primitive.ObjectID, sourceURL string, vouchURL string) {
+
+	const location = "service.Mention.Moderate"
+
+	mention.UserID = userID
+
+	if !mention.IsNew() && (mention.StateID != "") {
+		return
+	}
+
+	mention.VouchURL = vouchURL
+	mention.StateID = model.MentionStatusPending
+
+	if service.IsTrustedSource(userID, sourceURL) {
+		mention.StateID = model.MentionStatusValidated
+		return
+	}
+
+	if vouchURL == "" {
+		return
+	}
+
+	if err := service.VerifyVouch(userID, sourceURL, vouchURL); err != nil {
+		derp.Report(derp.Wrap(err, location, "Invalid vouch.  Mention will be held for moderation", sourceURL, vouchURL))
+		return
+	}
+
+	mention.StateID = model.MentionStatusValidated
+}
+
+// IsTrustedSource returns TRUE if the User follows the domain of the provided URL.
+// Mentions from trusted sources are approved automatically.
+func (service *Mention) IsTrustedSource(userID primitive.ObjectID, sourceURL string) bool {
+
+	const location = "service.Mention.IsTrustedSource"
+
+	hostname := urlHostname(sourceURL)
+
+	if hostname == "" {
+		return false
+	}
+
+	it, err := service.followingService.ListByUserID(userID)
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, location, "Error listing following", userID))
+		return false
+	}
+
+	following := model.NewFollowing()
+
+	for it.Next(&following) {
+
+		if (urlHostname(following.URL) == hostname) || (urlHostname(following.ProfileURL) == hostname) {
+			return true
+		}
+
+		following = model.NewFollowing()
+	}
+
+	return false
+}
+
+// VerifyVouch confirms that the vouch URL is on a domain that the User trusts,
+// and that the vouch document links to the domain of the source URL.
+// https://indieweb.org/Vouch
+func (service *Mention) VerifyVouch(userID primitive.ObjectID, sourceURL string, vouchURL string) error {
+
+	const location = "service.Mention.VerifyVouch"
+
+	// RULE: The vouch must come from a domain that the User trusts
+	if !service.IsTrustedSource(userID, vouchURL) {
+		return derp.NewForbiddenError(location, "Vouch URL is not on a trusted domain", vouchURL)
+	}
+
+	// Try to load the vouch document
+	var content string
+
+	if err := remote.Get(vouchURL).Result(&content).Send(); err != nil {
+		return derp.Wrap(err, location, "Error retrieving vouch", vouchURL)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error parsing vouch", vouchURL)
+	}
+
+	// The vouch must link to the source domain
+	sourceHostname := urlHostname(sourceURL)
+
+	for _, href := range doc.Find("a[href]").Map(getHrefFromNode) {
+		if urlHostname(href) == sourceHostname {
+			return nil
+		}
+	}
+
+	return derp.NewForbiddenError(location, "Vouch does not link to source domain", vouchURL, sourceURL)
+}
+
+// sendSalmention queues a task to re-send WebMentions from the mentioned Stream,
+// so that upstream sites can update their copies of this conversation.
+// https://indieweb.org/Salmention
+func (service *Mention) sendSalmention(mention *model.Mention) {
+
+	const location = "service.Mention.sendSalmention"
+
+	// Only Streams have outbound links
+	if mention.Type != model.MentionTypeStream {
+		return
+	}
+
+	task := queue.NewTask("SendSalmention", mapof.Any{
+		"host":     domain.NameOnly(service.host),
+		"streamId": mention.ObjectID.Hex(),
+	})
+
+	if err := service.queue.Publish(task); err != nil {
+		derp.Report(derp.Wrap(err, location, "Error publishing task", task))
+	}
+}
+
 /******************************************
  * Web-Mention Helpers
  ******************************************/
@@ -389,8 +636,10 @@ func (service *Mention) Verify(source string, target string, buffer io.Writer) e
 	return derp.NewNotFoundError(location, "Target link not found", source, target)
 }
 
-// TODO: HIGH: This should use a common service to get URL data from Microformats, OpenGraph, JSON-LD, etc.
-func (service *Mention) GetPageInfo(body *bytes.Buffer, originURL string, mention *model.Mention) error {
+// GetPageInfo copies metadata from the source document into the Mention.  Microformats (h-entry)
+// determine how the source relates to the target, and other metadata (OpenGraph, JSON-LD, etc.)
+// fills in any gaps.
+func (service *Mention) GetPageInfo(body *bytes.Buffer, originURL string, targetURL string, mention *model.Mention) error {
 
 	const location = "service.Mention.GetPageInfo"
 
@@ -401,9 +650,9 @@ func (service *Mention) GetPageInfo(body *bytes.Buffer, originURL string, mentio
 		return derp.Wrap(err, location, "Error retrieving page", originURL)
 	}
 
-	// Copy the page data into the mention
+	// Copy the page data into the mention.  Origin.URL is not changed, because
+	// it is used to find this Mention again when the source is updated or deleted.
 	mention.Origin.Label = document.Name()
-	mention.Origin.URL = document.ID()
 
 	if attributedTo := document.AttributedTo(); attributedTo.NotNil() {
 		mention.Author.Name = attributedTo.Name()
@@ -412,15 +661,136 @@ func (service *Mention) GetPageInfo(body *bytes.Buffer, originURL string, mentio
 		mention.Author.IconURL = attributedTo.Icon().URL()
 	}
 
+	// Read the h-entry to find the type of mention and its content
+	entry := hentry.Parse(bytes.NewReader(body.Bytes()), originURL, targetURL)
+
+	mention.Kind = mentionKind(entry.Type)
+	mention.RSVP = entry.RSVP
+	mention.Content = entry.Content
+
+	if !entry.Published.IsZero() {
+		mention.PublishDate = entry.Published.Unix()
+	}
+
+	if entry.Name != "" {
+		mention.Origin.Label = entry.Name
+	}
+
+	if entry.AuthorName != "" {
+		mention.Author.Name = entry.AuthorName
+	}
+
+	if entry.AuthorURL != "" {
+		mention.Author.ProfileURL = entry.AuthorURL
+	}
+
+	if entry.AuthorPhoto != "" {
+		mention.Author.IconURL = entry.AuthorPhoto
+	}
+
 	// No errors
 	return nil
 }
 
+// mentionKind maps h-entry relationships to Mention kinds
+func mentionKind(entryType string) string {
+
+	switch entryType {
+
+	case hentry.TypeReply:
+		return model.MentionKindReply
+
+	case hentry.TypeLike:
+		return model.MentionKindLike
+
+	case hentry.TypeRepost:
+		return model.MentionKindRepost
+
+	case hentry.TypeBookmark:
+		return model.MentionKindBookmark
+
+	case hentry.TypeRSVP:
+		return model.MentionKindRSVP
+	}
+
+	return model.MentionKindMention
+}
+
+/******************************************
+ * Response Helpers
+ ******************************************/
+
+// syncResponse creates (or updates) a Response record for approved Likes and Reposts,
+// and removes it for all other Mentions.
+func (service *Mention) syncResponse(mention *model.Mention) error {
+
+	const location = "service.Mention.syncResponse"
+
+	responseType := mention.ResponseType()
+
+	// Only approved Likes and Reposts of Streams become Responses
+	if (responseType == "") || !mention.IsValidated() || (mention.Type != model.MentionTypeStream) {
+		return service.deleteResponse(mention)
+	}
+
+	response := model.NewResponse()
+
+	if err := service.responseService.LoadByURL(mention.Origin.URL, &response); err != nil {
+		if !derp.NotFound(err) {
+			return derp.Wrap(err, location, "Error loading Response", mention.Origin.URL)
+		}
+	}
+
+	response.Actor = first.String(mention.Author.ProfileURL, mention.Origin.URL)
+	response.Object = service.host + "/" + mention.ObjectID.Hex()
+	response.Type = responseType
+	response.URL = mention.Origin.URL
+
+	if err := service.responseService.Save(&response, "Received via WebMention"); err != nil {
+		return derp.Wrap(err, location, "Error saving Response", response)
+	}
+
+	return nil
+}
+
+// deleteResponse removes the Response record (if any) that was created for a Mention
+func (service *Mention) deleteResponse(mention *model.Mention) error {
+
+	const location = "service.Mention.deleteResponse"
+
+	response := model.NewResponse()
+
+	if err := service.responseService.LoadByURL(mention.Origin.URL, &response); err != nil {
+		if derp.NotFound(err) {
+			return nil
+		}
+		return derp.Wrap(err, location, "Error loading Response", mention.Origin.URL)
+	}
+
+	if err := service.responseService.Delete(&response, "WebMention removed"); err != nil {
+		return derp.Wrap(err, location, "Error deleting Response", response)
+	}
+
+	return nil
+}
+
 // getHrefFromNode returns the [href] value for a given goquery selection
 func getHrefFromNode(index int, node *goquery.Selection) string {
 	return node.AttrOr("href", "")
 }
 
+// urlHostname returns the lowercase hostname of a URL, or an empty string if the URL is invalid
+func urlHostname(value string) string {
+
+	parsed, err := url.Parse(value)
+
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(parsed.Hostname())
+}
+
 // isExternalHref returns TRUE if this URL points to an external domain
 func isExternalHref(href string) bool {
 	return strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://")
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newTestMentionService returns a Mention service where the User follows the provided URLs
+func newTestMentionService(t *testing.T, userID primitive.ObjectID, followingURLs ...string) Mention {
+
+	followingService := &Following{collection: newTestCollection()}
+
+	for _, followingURL := range followingURLs {
+		following := model.NewFollowing()
+		following.UserID = userID
+		following.URL = followingURL
+		require.Nil(t, followingService.collection.Save(&following, "test"))
+	}
+
+	result := NewMention()
+	result.collection = newTestCollection()
+	result.followingService = followingService
+	result.responseService = &Response{collection: newTestCollection()}
+
+	return result
+}
+
+// newTestVouchServer returns a web server with a single vouch page that links to the provided URL
+func newTestVouchServer(linkURL string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><a href="` + linkURL + `">A friend of mine</a></body></html>`))
+	}))
+}
+
+func TestMentionModerate_Unknown(t *testing.T) {
+
+	userID := primitive.NewObjectID()
+	mentionService := newTestMentionService(t, userID, "https://friend.example/")
+
+	// Mentions from unknown domains are held for moderation
+	mention := model.NewMention()
+	mentionService.Moderate(&mention, userID, "https://spam.example/post", "")
+
+	require.Equal(t, userID, mention.UserID)
+	require.True(t, mention.IsPending())
+}
+
+func TestMentionModerate_Following(t *testing.T) {
+
+	userID := primitive.NewObjectID()
+	mentionService := newTestMentionService(t, userID, "https://Friend.example/profile")
+
+	// Mentions from domains that the User follows are approved automatically
+	mention := model.NewMention()
+	mentionService.Moderate(&mention, userID, "https://friend.example/posts/123", "")
+	require.True(t, mention.IsValidated())
+
+	// ...but other Users' followings are not trusted
+	mention = model.NewMention()
+	mentionService.Moderate(&mention, primitive.NewObjectID(), "https://friend.example/posts/123", "")
+	require.True(t, mention.IsPending())
+}
+
+func TestMentionModerate_Vouch(t *testing.T) {
+
+	// The vouch page is on a domain that the User follows, and links to the source domain
+	vouchServer := newTestVouchServer("https://newcomer.example/")
+	defer vouchServer.Close()
+
+	userID := primitive.NewObjectID()
+	mentionService := newTestMentionService(t, userID, vouchServer.URL)
+
+	mention := model.NewMention()
+	mentionService.Moderate(&mention, userID, "https://newcomer.example/post", vouchServer.URL+"/friends")
+	require.True(t, mention.IsValidated())
+	require.Equal(t, vouchServer.URL+"/friends", mention.VouchURL)
+
+	// Vouches that do not link to the source domain are ignored
+	mention = model.NewMention()
+	mentionService.Moderate(&mention, userID, "https://stranger.example/post", vouchServer.URL+"/friends")
+	require.True(t, mention.IsPending())
+
+	// Vouches from domains that the User does not follow are ignored
+	mentionService = newTestMentionService(t, userID, "https://friend.example/")
+
+	mention = model.NewMention()
+	mentionService.Moderate(&mention, userID, "https://newcomer.example/post", vouchServer.URL+"/friends")
+	require.True(t, mention.IsPending())
+}
+
+func TestMentionModerate_Existing(t *testing.T) {
+
+	userID := primitive.NewObjectID()
+	mentionService := newTestMentionService(t, userID, "https://friend.example/")
+
+	// Rejected Mentions stay rejected, even when they are received again from a trusted source
+	mention := model.NewMention()
+	mention.Type = model.MentionTypeUser
+	mention.StateID = model.MentionStatusInvalid
+	require.Nil(t, mentionService.collection.Save(&mention, "test"))
+
+	mentionService.Moderate(&mention, userID, "https://friend.example/post", "")
+	require.Equal(t, model.MentionStatusInvalid, mention.StateID)
+
+	// Mentions received before moderation existed are moderated like new Mentions
+	mention.StateID = ""
+	mentionService.Moderate(&mention, userID, "https://friend.example/post", "")
+	require.True(t, mention.IsValidated())
+}
+
+func TestMentionModerate_ApproveReject(t *testing.T) {
+
+	userID := primitive.NewObjectID()
+	objectID := primitive.NewObjectID()
+	mentionService := newTestMentionService(t, userID)
+
+	// Receive a Mention from an unknown domain
+	mention := model.NewMention()
+	mention.Type = model.MentionTypeUser
+	mention.ObjectID = objectID
+	mention.Origin.URL = "https://unknown.example/post"
+	mentionService.Moderate(&mention, userID, mention.Origin.URL, "")
+	require.Nil(t, mentionService.Save(&mention, "test"))
+
+	// Pending Mentions are waiting for the User, and are not displayed publicly
+	requirePending := func(expected int) {
+		pending, err := mentionService.QueryPendingByUser(userID)
+		require.Nil(t, err)
+		require.Len(t, pending, expected)
+
+		count, err := mentionService.CountPendingByUser(userID)
+		require.Nil(t, err)
+		require.Equal(t, int64(expected), count)
+	}
+
+	requireValidated := func(expected int) {
+		validated, err := mentionService.QueryValidatedByObjectID(objectID)
+		require.Nil(t, err)
+		require.Len(t, validated, expected)
+	}
+
+	requirePending(1)
+	requireValidated(0)
+
+	// Only the User who owns the mentioned object can moderate it
+	loaded := model.NewMention()
+	require.NotNil(t, mentionService.LoadByToken(primitive.NewObjectID(), mention.MentionID.Hex(), &loaded))
+	require.Nil(t, mentionService.LoadByToken(userID, mention.MentionID.Hex(), &loaded))
+	require.Equal(t, []string{model.MagicRoleMyself}, loaded.Roles(&model.Authorization{UserID: userID}))
+	require.Empty(t, loaded.Roles(&model.Authorization{UserID: primitive.NewObjectID(), DomainOwner: true}))
+
+	// Approve the Mention
+	loaded.StateID = model.MentionStatusValidated
+	require.Nil(t, mentionService.Save(&loaded, "Approved"))
+	requirePending(0)
+	requireValidated(1)
+
+	// Reject the Mention
+	loaded.StateID = model.MentionStatusInvalid
+	require.Nil(t, mentionService.Save(&loaded, "Rejected"))
+	requirePending(0)
+	requireValidated(0)
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMentionSave_Salmention(t *testing.T) {
+
+	userID := primitive.NewObjectID()
+	mentionService := newTestMentionService(t, userID)
+
+	taskQueue, tasks := newTestQueue()
+	mentionService.queue = taskQueue
+	mentionService.host = "https://local.example"
+
+	mention := model.NewMention()
+	mention.Type = model.MentionTypeStream
+	mention.ObjectID = primitive.NewObjectID()
+	mention.UserID = userID
+	mention.Origin.URL = "https://remote.example/post"
+	mention.Content = "Hello there"
+
+	// Pending Mentions are not displayed, so nothing is sent
+	require.Nil(t, mentionService.Save(&mention, "Received"))
+	require.Empty(t, tasks.Names())
+
+	// Approving the Mention sends a Salmention
+	mention.StateID = model.MentionStatusValidated
+	require.Nil(t, mentionService.Save(&mention, "Approved"))
+	require.Equal(t, []string{"SendSalmention"}, tasks.Names())
+
+	// Saving an already-validated Mention again (twice) sends nothing
+	require.Nil(t, mentionService.Save(&mention, "Re-verified"))
+	require.Nil(t, mentionService.Save(&mention, "Re-verified"))
+	require.Equal(t, []string{"SendSalmention"}, tasks.Names())
+
+	// Changing the displayed content sends another Salmention
+	mention.Content = "Hello there (edited)"
+	require.Nil(t, mentionService.Save(&mention, "Updated"))
+	require.Equal(t, []string{"SendSalmention", "SendSalmention"}, tasks.Names())
+
+	// Rejecting the Mention removes it from the Stream, so upstream sites are notified
+	mention.StateID = model.MentionStatusInvalid
+	require.Nil(t, mentionService.Save(&mention, "Rejected"))
+	require.Equal(t, []string{"SendSalmention", "SendSalmention", "SendSalmention"}, tasks.Names())
+}
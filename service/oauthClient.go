@@ -1,7 +1,10 @@
 package service
 
 import (
+	"time"
+
 	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/indieauth"
 	"github.com/EmissarySocial/emissary/tools/random"
 	"github.com/benpate/data"
 	"github.com/benpate/data/option"
@@ -18,6 +21,12 @@ type OAuthClient struct {
 	host                  string
 }
 
+// indieAuthClientRefresh is how often IndieAuth client information is re-downloaded
+const indieAuthClientRefresh = 24 * time.Hour
+
+// indieAuthClientMaxSize limits the size (in bytes) of a client_id page that will be downloaded
+const indieAuthClientMaxSize = 1024 * 1024
+
 // NewOAuthClient returns a fully populated OAuthClient service.
 func NewOAuthClient() OAuthClient {
 	return OAuthClient{}
@@ -135,10 +144,111 @@ func (service *OAuthClient) LoadByClientID(clientID primitive.ObjectID, client *
 	return service.Load(criteria, client)
 }
 
+// LoadByClientURL loads a single IndieAuth client using its client_id URL
+func (service *OAuthClient) LoadByClientURL(clientURL string, client *model.OAuthClient) error {
+	criteria := exp.Equal("clientUrl", clientURL)
+	return service.Load(criteria, client)
+}
+
+// LoadKnownByPublicID loads a client using the "client_id" value sent by a remote application.
+// Unlike LoadByPublicID, it never downloads IndieAuth client information, so it only finds
+// clients that have already been authorized by a signed-in User.
+func (service *OAuthClient) LoadKnownByPublicID(publicID string, client *model.OAuthClient) error {
+
+	const location = "service.OAuthClient.LoadKnownByPublicID"
+
+	if clientID, err := primitive.ObjectIDFromHex(publicID); err == nil {
+		return service.LoadByClientID(clientID, client)
+	}
+
+	clientURL, err := indieauth.CanonicalURL(publicID)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid client_id", publicID)
+	}
+
+	return service.LoadByClientURL(clientURL, client)
+}
+
+// LoadByPublicID loads a client using the "client_id" value sent by a remote application.
+// This is either a stringified ObjectID (for registered apps) or a URL (for IndieAuth clients).
+// IndieAuth clients are discovered and saved automatically the first time they are used.
+func (service *OAuthClient) LoadByPublicID(publicID string, client *model.OAuthClient) error {
+
+	const location = "service.OAuthClient.LoadByPublicID"
+
+	if clientID, err := primitive.ObjectIDFromHex(publicID); err == nil {
+		return service.LoadByClientID(clientID, client)
+	}
+
+	if !indieauth.IsClientID(publicID) {
+		return derp.NewBadRequestError(location, "Invalid client_id", publicID)
+	}
+
+	if err := service.discover(publicID, client); err != nil {
+		return derp.Wrap(err, location, "Error discovering IndieAuth client", publicID)
+	}
+
+	return nil
+}
+
 /******************************************
  * Custom Methods
  ******************************************/
 
+// discover loads an IndieAuth client from the database, refreshing its information
+// from the client_id URL if it is new or has not been updated recently.
+func (service *OAuthClient) discover(clientURL string, client *model.OAuthClient) error {
+
+	const location = "service.OAuthClient.discover"
+
+	clientURL, err := indieauth.CanonicalURL(clientURL)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid client_id", clientURL)
+	}
+
+	// Try to load an existing client
+	if err := service.LoadByClientURL(clientURL, client); err == nil {
+
+		// Recently updated clients do not need to be refreshed
+		if time.Since(time.UnixMilli(client.UpdateDate)) < indieAuthClientRefresh {
+			return nil
+		}
+
+	} else if !derp.NotFound(err) {
+		return derp.Wrap(err, location, "Error loading client", clientURL)
+	}
+
+	// Fetch the client information from its URL
+	info, err := indieauth.FetchClient(clientURL, limitResponseSize(indieAuthClientMaxSize))
+
+	if err != nil {
+
+		// Existing clients can still be used if the refresh fails
+		if !client.IsNew() {
+			derp.Report(derp.Wrap(err, location, "Error refreshing client information", clientURL))
+			return nil
+		}
+
+		return derp.Wrap(err, location, "Error fetching client information", clientURL)
+	}
+
+	// Copy the client information into the record
+	client.ClientURL = clientURL
+	client.Name = info.Name
+	client.Website = info.URL
+	client.LogoURL = info.LogoURL
+	client.RedirectURIs = info.RedirectURIs
+	client.Scopes = model.IndieAuthScopes
+
+	if err := service.Save(client, "Discovered via IndieAuth"); err != nil {
+		return derp.Wrap(err, location, "Error saving client", clientURL)
+	}
+
+	return nil
+}
+
 func (service *OAuthClient) ValidateClientSecret(clientID primitive.ObjectID, clientSecret string) error {
 
 	const location = "service.OAuthClient.ValidateClientSecret"
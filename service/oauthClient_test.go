@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthClient_LoadKnownByPublicID(t *testing.T) {
+
+	clientService := NewOAuthClient()
+	clientService.collection = newTestCollection()
+
+	saved := model.NewOAuthClient()
+	saved.Name = "Test App"
+	saved.ClientURL = "https://app.example.com/"
+	saved.RedirectURIs = []string{"https://app.example.com/callback"}
+	require.Nil(t, clientService.collection.Save(&saved, "test"))
+
+	// Known clients are found by ObjectID or by (canonical) URL
+	client := model.NewOAuthClient()
+	require.Nil(t, clientService.LoadKnownByPublicID(saved.ClientID.Hex(), &client))
+	require.Equal(t, saved.ClientID, client.ClientID)
+
+	client = model.NewOAuthClient()
+	require.Nil(t, clientService.LoadKnownByPublicID("https://APP.example.com", &client))
+	require.Equal(t, saved.ClientID, client.ClientID)
+
+	// Unknown clients are never discovered
+	client = model.NewOAuthClient()
+	err := clientService.LoadKnownByPublicID("https://other.example.com/", &client)
+	require.True(t, derp.NotFound(err))
+	require.True(t, client.IsNew())
+}
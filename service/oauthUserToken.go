@@ -1,7 +1,13 @@
 package service
 
 import (
+	"crypto/subtle"
+	"strings"
+	"time"
+
 	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/indieauth"
+	"github.com/EmissarySocial/emissary/tools/random"
 	"github.com/benpate/data"
 	"github.com/benpate/data/option"
 	"github.com/benpate/derp"
@@ -19,6 +25,9 @@ type OAuthUserToken struct {
 	host               string
 }
 
+// oauthCodeLifetime is how long an authorization code can be redeemed after it is issued
+const oauthCodeLifetime = 10 * time.Minute
+
 // NewOAuthUserToken returns a fully populated OAuthUserToken service.
 func NewOAuthUserToken() OAuthUserToken {
 	return OAuthUserToken{}
@@ -139,14 +148,24 @@ func (service *OAuthUserToken) LoadByUserAndClient(userID primitive.ObjectID, cl
 	return service.Load(criteria, result)
 }
 
-func (service *OAuthUserToken) LoadByClientAndCode(userTokenID primitive.ObjectID, clientID primitive.ObjectID, clientSecret string, result *model.OAuthUserToken) error {
+func (service *OAuthUserToken) LoadByClientAndCode(code string, clientID primitive.ObjectID, clientSecret string, result *model.OAuthUserToken) error {
 
 	// RULE: must have a valid clientSecret to load this record
 	if err := service.oauthClientService.ValidateClientSecret(clientID, clientSecret); err != nil {
 		return derp.Wrap(err, "service.OAuthUserToken.LoadByClientAndToken", "Invalid client secret")
 	}
 
-	criteria := exp.Equal("_id", userTokenID).
+	return service.LoadByCode(code, clientID, result)
+}
+
+// LoadByCode loads the OAuthUserToken that was issued to a client with the provided authorization code
+func (service *OAuthUserToken) LoadByCode(code string, clientID primitive.ObjectID, result *model.OAuthUserToken) error {
+
+	if code == "" {
+		return derp.NewNotFoundError("service.OAuthUserToken.LoadByCode", "Authorization code is required")
+	}
+
+	criteria := exp.Equal("authorizationCode", code).
 		AndEqual("clientId", clientID)
 
 	return service.Load(criteria, result)
@@ -165,10 +184,72 @@ func (service *OAuthUserToken) LoadByClientAndToken(clientID primitive.ObjectID,
 	return service.Load(criteria, result)
 }
 
+// LoadByToken loads the OAuthUserToken that matches an access token.  Revoked tokens are not found.
+func (service *OAuthUserToken) LoadByToken(token string, result *model.OAuthUserToken) error {
+	criteria := exp.Equal("token", token)
+	return service.Load(criteria, result)
+}
+
 /******************************************
  * Custom Methods
  ******************************************/
 
+// Redeem exchanges an authorization code for an OAuthUserToken.  Codes expire shortly after they
+// are issued, and can only be redeemed once.  Registered clients without PKCE must provide their
+// client secret.  If a PKCE code challenge was used, then the code verifier must match it.  IndieAuth clients have
+// no secret, so they always require PKCE.
+func (service *OAuthUserToken) Redeem(client model.OAuthClient, transaction model.OAuthUserTokenRequest, result *model.OAuthUserToken) error {
+
+	const location = "service.OAuthUserToken.Redeem"
+
+	if err := service.LoadByCode(transaction.Code, client.ClientID, result); err != nil {
+		if derp.NotFound(err) {
+			return derp.NewBadRequestError(location, "Invalid authorization code")
+		}
+		return derp.Wrap(err, location, "Error loading OAuthUserToken")
+	}
+
+	// Copy the values to check, then invalidate the code before anything else, so that
+	// it cannot be redeemed again (even if this attempt fails)
+	redeemed := *result
+	result.ClearCode()
+
+	if err := service.Save(result, "Redeemed authorization code"); err != nil {
+		return derp.Wrap(err, location, "Error saving OAuthUserToken")
+	}
+
+	// RULE: Codes must be redeemed before they expire
+	if redeemed.IsCodeExpired() {
+		return derp.NewBadRequestError(location, "Authorization code has expired")
+	}
+
+	// RULE: Registered clients without PKCE must provide their client secret
+	if !client.IsIndieAuth() && (redeemed.CodeChallenge == "") {
+		if subtle.ConstantTimeCompare([]byte(client.ClientSecret), []byte(transaction.ClientSecret)) != 1 {
+			return derp.NewBadRequestError(location, "Invalid client_secret")
+		}
+	}
+
+	// RULE: IndieAuth clients must use PKCE
+	if client.IsIndieAuth() && (redeemed.CodeChallenge == "") {
+		return derp.NewBadRequestError(location, "code_challenge is required for IndieAuth clients")
+	}
+
+	// RULE: The redirect_uri must match the authorization request
+	if (redeemed.RedirectURI != "") && (transaction.RedirectURI != redeemed.RedirectURI) {
+		return derp.NewBadRequestError(location, "Invalid redirect_uri", transaction.RedirectURI)
+	}
+
+	// RULE: The code verifier must match the code challenge
+	if redeemed.CodeChallenge != "" {
+		if !indieauth.VerifyCodeChallenge(redeemed.CodeChallengeMethod, redeemed.CodeChallenge, transaction.CodeVerifier) {
+			return derp.NewBadRequestError(location, "Invalid code_verifier")
+		}
+	}
+
+	return nil
+}
+
 func (service *OAuthUserToken) CreateFromUser(user *model.User, clientID primitive.ObjectID, scope string) (model.OAuthUserToken, error) {
 
 	// Load the client from the database
@@ -210,25 +291,41 @@ func (service *OAuthUserToken) Create(client model.OAuthClient, authorization mo
 		return model.OAuthUserToken{}, derp.Wrap(err, location, "Invalid OAuthUserTokenRequest")
 	}
 
-	// If we already have a token for this user/client, then just return that.
+	// If we already have a token for this user/client, then reuse it.
 	result := model.NewOAuthUserToken()
-	if err := service.LoadByUserAndClient(authorization.UserID, client.ClientID, &result); err == nil {
-		return result, nil
+	err := service.LoadByUserAndClient(authorization.UserID, client.ClientID, &result)
+
+	// Create a new token if none exists, or if the requested scopes have changed
+	if (err != nil) || (strings.Join(result.Scopes, " ") != strings.Join(transaction.Scopes(), " ")) {
+
+		token, err := service.JWT(authorization.UserID, transaction.Scope)
+
+		if err != nil {
+			return model.OAuthUserToken{}, derp.Wrap(err, location, "Error generating random token")
+		}
+
+		// Copy data from the authorization
+		result.ClientID = client.ClientID
+		result.UserID = authorization.UserID
+		result.Scopes = transaction.Scopes()
+		result.Token = token
+		result.APIUser = true
 	}
 
-	// Fall through means we're going to create a new token
-	token, err := service.JWT(authorization.UserID, transaction.Scope)
+	// Issue a new, short-lived authorization code
+	code, err := random.GenerateString(32)
 
 	if err != nil {
-		return model.OAuthUserToken{}, derp.Wrap(err, location, "Error generating random token")
+		return model.OAuthUserToken{}, derp.Wrap(err, location, "Error generating authorization code")
 	}
 
-	// Copy data from the authorization
-	result.ClientID = client.ClientID
-	result.UserID = authorization.UserID
-	result.Scopes = transaction.Scopes()
-	result.Token = token
-	result.APIUser = true
+	result.AuthorizationCode = code
+	result.CodeExpires = time.Now().Add(oauthCodeLifetime).Unix()
+
+	// Remember PKCE values until the authorization code is redeemed
+	result.CodeChallenge = transaction.CodeChallenge
+	result.CodeChallengeMethod = transaction.CodeChallengeMethod
+	result.RedirectURI = transaction.RedirectURI
 
 	// Save the result to the database
 	if err := service.Save(&result, "Create"); err != nil {
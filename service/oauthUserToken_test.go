@@ -0,0 +1,93 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/indieauth"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newTestUserToken saves an OAuthUserToken with an authorization code (and optional PKCE challenge)
+// that expires at the provided time
+func newTestUserToken(t *testing.T, service *OAuthUserToken, client model.OAuthClient, code string, expires time.Time, challenge string) {
+	userToken := model.NewOAuthUserToken()
+	userToken.ClientID = client.ClientID
+	userToken.UserID = primitive.NewObjectID()
+	userToken.AuthorizationCode = code
+	userToken.CodeExpires = expires.Unix()
+
+	if challenge != "" {
+		userToken.CodeChallenge = challenge
+		userToken.CodeChallengeMethod = indieauth.CodeChallengeMethodS256
+	}
+
+	require.Nil(t, service.collection.Save(&userToken, "test"))
+}
+
+func TestOAuthUserToken_Redeem(t *testing.T) {
+
+	userTokenService := NewOAuthUserToken()
+	userTokenService.collection = newTestCollection()
+
+	client := model.NewOAuthClient()
+	client.ClientSecret = "SECRET"
+
+	redeem := func(code string, secret string) error {
+		transaction := model.OAuthUserTokenRequest{Code: code, ClientSecret: secret}
+		result := model.NewOAuthUserToken()
+		return userTokenService.Redeem(client, transaction, &result)
+	}
+
+	// Codes can only be redeemed once
+	newTestUserToken(t, &userTokenService, client, "VALID", time.Now().Add(time.Minute), "")
+	require.Nil(t, redeem("VALID", "SECRET"))
+	require.NotNil(t, redeem("VALID", "SECRET"))
+
+	// Failed attempts also use up the code
+	newTestUserToken(t, &userTokenService, client, "GUESSED", time.Now().Add(time.Minute), "")
+	require.NotNil(t, redeem("GUESSED", "WRONG"))
+	require.NotNil(t, redeem("GUESSED", "SECRET"))
+
+	// Expired codes cannot be redeemed
+	newTestUserToken(t, &userTokenService, client, "EXPIRED", time.Now().Add(-time.Second), "")
+	require.NotNil(t, redeem("EXPIRED", "SECRET"))
+
+	// Codes are not valid for other clients
+	newTestUserToken(t, &userTokenService, model.NewOAuthClient(), "OTHER", time.Now().Add(time.Minute), "")
+	require.NotNil(t, redeem("OTHER", "SECRET"))
+
+	// Empty codes never match
+	require.NotNil(t, redeem("", "SECRET"))
+}
+
+func TestOAuthUserToken_Redeem_IndieAuth(t *testing.T) {
+
+	userTokenService := NewOAuthUserToken()
+	userTokenService.collection = newTestCollection()
+
+	client := model.NewOAuthClient()
+	client.ClientURL = "https://app.example.com/"
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	redeem := func(code string, verifier string) error {
+		transaction := model.OAuthUserTokenRequest{Code: code, CodeVerifier: verifier}
+		result := model.NewOAuthUserToken()
+		return userTokenService.Redeem(client, transaction, &result)
+	}
+
+	// The code verifier must match the challenge, and only works once
+	newTestUserToken(t, &userTokenService, client, "PKCE", time.Now().Add(time.Minute), indieauth.CodeChallenge(indieauth.CodeChallengeMethodS256, verifier))
+	require.Nil(t, redeem("PKCE", verifier))
+	require.NotNil(t, redeem("PKCE", verifier))
+
+	newTestUserToken(t, &userTokenService, client, "WRONG", time.Now().Add(time.Minute), indieauth.CodeChallenge(indieauth.CodeChallengeMethodS256, verifier))
+	require.NotNil(t, redeem("WRONG", "wrong-verifier"))
+
+	// IndieAuth clients must use PKCE
+	newTestUserToken(t, &userTokenService, client, "NOPKCE", time.Now().Add(time.Minute), "")
+	require.NotNil(t, redeem("NOPKCE", ""))
+}
@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/random"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// OIDC service manages sign-in through an external OpenID Connect provider
+// (such as Keycloak, Authentik, or Google Workspace).  It uses provider discovery,
+// PKCE, and nonce validation, and maps the provider's identities onto local Users.
+type OIDC struct {
+	groupService        *Group
+	registrationService *Registration
+	userService         *User
+	jwtService          *JWT
+	providers           *OIDCProviders
+	domain              model.Domain
+	host                string
+}
+
+// NewOIDC returns a fully initialized OIDC service
+func NewOIDC(groupService *Group, registrationService *Registration, userService *User, jwtService *JWT, providers *OIDCProviders, domain model.Domain, host string) OIDC {
+	return OIDC{
+		groupService:        groupService,
+		registrationService: registrationService,
+		userService:         userService,
+		jwtService:          jwtService,
+		providers:           providers,
+		domain:              domain,
+		host:                host,
+	}
+}
+
+// Config returns the OpenID Connect settings for this domain
+func (service OIDC) Config() model.OIDCConfig {
+	return service.domain.OIDCConfig()
+}
+
+// RedirectURL returns the callback URL that must be registered with the provider
+func (service OIDC) RedirectURL() string {
+	return service.host + "/oidc/callback"
+}
+
+/******************************************
+ * Challenge Methods
+ ******************************************/
+
+// NewChallenge returns a new challenge with random state, nonce, and PKCE verifier values
+func (service OIDC) NewChallenge(next string) (model.OIDCChallenge, error) {
+
+	const location = "service.OIDC.NewChallenge"
+
+	state, err := random.GenerateString(32)
+
+	if err != nil {
+		return model.OIDCChallenge{}, derp.Wrap(err, location, "Error generating state")
+	}
+
+	nonce, err := random.GenerateString(32)
+
+	if err != nil {
+		return model.OIDCChallenge{}, derp.Wrap(err, location, "Error generating nonce")
+	}
+
+	return model.NewOIDCChallenge(state, nonce, oauth2.GenerateVerifier(), next), nil
+}
+
+// NewChallengeToken returns a signed token for the provided challenge
+func (service OIDC) NewChallengeToken(challenge model.OIDCChallenge) (string, error) {
+
+	result, err := service.jwtService.NewToken(challenge)
+
+	if err != nil {
+		return "", derp.Wrap(err, "service.OIDC.NewChallengeToken", "Error signing challenge")
+	}
+
+	return result, nil
+}
+
+// ParseChallengeToken validates a signed challenge token
+func (service OIDC) ParseChallengeToken(token string) (model.OIDCChallenge, error) {
+
+	challenge := model.OIDCChallenge{}
+
+	if err := service.jwtService.ParseClaims(token, &challenge); err != nil {
+		return model.OIDCChallenge{}, derp.Wrap(err, "service.OIDC.ParseChallengeToken", "Invalid challenge token")
+	}
+
+	return challenge, nil
+}
+
+/******************************************
+ * Protocol Methods
+ ******************************************/
+
+// AuthCodeURL returns the provider's authorization URL for the provided challenge
+func (service OIDC) AuthCodeURL(ctx context.Context, challenge model.OIDCChallenge) (string, error) {
+
+	const location = "service.OIDC.AuthCodeURL"
+
+	_, oauthConfig, err := service.provider(ctx)
+
+	if err != nil {
+		return "", derp.Wrap(err, location, "Error connecting to provider")
+	}
+
+	result := oauthConfig.AuthCodeURL(
+		challenge.State,
+		oidc.Nonce(challenge.Nonce),
+		oauth2.S256ChallengeOption(challenge.Verifier),
+	)
+
+	return result, nil
+}
+
+// Exchange validates the provider's callback, exchanges the authorization code for
+// tokens, and verifies the ID Token.  It returns the identity described by the token.
+func (service OIDC) Exchange(ctx context.Context, challenge model.OIDCChallenge, state string, code string) (model.OIDCIdentity, error) {
+
+	const location = "service.OIDC.Exchange"
+
+	// RULE: The callback must match the challenge that started this sign-in
+	if (state == "") || subtle.ConstantTimeCompare([]byte(state), []byte(challenge.State)) != 1 {
+		return model.OIDCIdentity{}, derp.NewBadRequestError(location, "Invalid state")
+	}
+
+	// RULE: Authorization code is required
+	if code == "" {
+		return model.OIDCIdentity{}, derp.NewBadRequestError(location, "Authorization code is required")
+	}
+
+	provider, oauthConfig, err := service.provider(ctx)
+
+	if err != nil {
+		return model.OIDCIdentity{}, derp.Wrap(err, location, "Error connecting to provider")
+	}
+
+	// Exchange the authorization code (and PKCE verifier) for tokens
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(challenge.Verifier))
+
+	if err != nil {
+		return model.OIDCIdentity{}, derp.Wrap(err, location, "Error exchanging authorization code", derp.WithCode(http.StatusBadRequest))
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+
+	if !ok {
+		return model.OIDCIdentity{}, derp.NewBadRequestError(location, "Provider did not return an ID Token")
+	}
+
+	// Verify the ID Token's signature, issuer, audience, and expiration
+	config := service.Config()
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: config.ClientID}).Verify(ctx, rawIDToken)
+
+	if err != nil {
+		return model.OIDCIdentity{}, derp.Wrap(err, location, "Invalid ID Token", derp.WithCode(http.StatusBadRequest))
+	}
+
+	// RULE: The ID Token must have been issued for this sign-in
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(challenge.Nonce)) != 1 {
+		return model.OIDCIdentity{}, derp.NewBadRequestError(location, "Invalid nonce")
+	}
+
+	claims := mapof.NewAny()
+
+	if err := idToken.Claims(&claims); err != nil {
+		return model.OIDCIdentity{}, derp.Wrap(err, location, "Error reading ID Token claims")
+	}
+
+	// Some providers only publish groups through the UserInfo endpoint
+	if groupsClaim, _, _ := strings.Cut(config.GroupsClaim, "."); (groupsClaim != "") && (claims[groupsClaim] == nil) {
+		if err := service.mergeUserInfo(ctx, provider, token, claims); err != nil {
+			return model.OIDCIdentity{}, derp.Wrap(err, location, "Error loading user info")
+		}
+	}
+
+	identity := model.NewOIDCIdentity(claims, config.GroupsClaim)
+
+	if identity.Subject == "" {
+		return model.OIDCIdentity{}, derp.NewBadRequestError(location, "ID Token does not include a subject")
+	}
+
+	return identity, nil
+}
+
+/******************************************
+ * User Methods
+ ******************************************/
+
+// LoadUser finds the local User for a verified identity, and updates their group
+// memberships from the provider.  Users are located by their linked subject ID, then
+// (if allowed) by their verified email address.  If no User is found, then a new one
+// is created through the Registration service (if allowed).
+func (service OIDC) LoadUser(identity model.OIDCIdentity, user *model.User) error {
+
+	const location = "service.OIDC.LoadUser"
+
+	config := service.Config()
+	note := "Signed in with " + config.Label
+
+	err := service.userService.LoadByMapID(model.UserMapIDOIDC, identity.Subject, user)
+
+	// Try to link an existing User by their verified email address
+	if derp.NotFound(err) && config.LinkEmail && identity.EmailVerified && (identity.EmailAddress != "") {
+		err = service.userService.LoadByEmail(identity.EmailAddress, user)
+
+		if err == nil {
+			if existing := user.MapIDs[model.UserMapIDOIDC]; (existing != "") && (existing != identity.Subject) {
+				return derp.NewForbiddenError(location, "This account is already linked to a different identity")
+			}
+
+			if user.MapIDs == nil {
+				user.MapIDs = mapof.NewString()
+			}
+
+			user.MapIDs[model.UserMapIDOIDC] = identity.Subject
+			note = "Linked to " + config.Label
+		}
+	}
+
+	// Create a new User if allowed
+	if derp.NotFound(err) {
+
+		if !config.Provision {
+			return derp.NewNotFoundError(location, "No account is linked to this identity", identity.Subject)
+		}
+
+		txn := model.RegistrationTxn{
+			DisplayName:  identity.DisplayName,
+			EmailAddress: identity.EmailAddress,
+			Username:     oidcUsername(identity),
+		}
+
+		newUser, err := service.registrationService.RegisterExternal(service.groupService, service.userService, &service.domain, model.UserMapIDOIDC, identity.Subject, txn)
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error creating user")
+		}
+
+		*user = newUser
+	} else if err != nil {
+		return derp.Wrap(err, location, "Error loading user", identity.Subject)
+	}
+
+	// Update group memberships from the identity's claims
+	if err := service.setGroups(identity, user); err != nil {
+		return derp.Wrap(err, location, "Error updating groups")
+	}
+
+	if err := service.userService.Save(user, note); err != nil {
+		return derp.Wrap(err, location, "Error saving user")
+	}
+
+	return nil
+}
+
+// LinkUser links a verified identity to an existing (signed-in) User
+func (service OIDC) LinkUser(identity model.OIDCIdentity, user *model.User) error {
+
+	const location = "service.OIDC.LinkUser"
+
+	// RULE: Each identity can only be linked to a single User
+	other := model.NewUser()
+
+	if err := service.userService.LoadByMapID(model.UserMapIDOIDC, identity.Subject, &other); err == nil {
+		if other.UserID != user.UserID {
+			return derp.NewForbiddenError(location, "This identity is already linked to a different account")
+		}
+	} else if !derp.NotFound(err) {
+		return derp.Wrap(err, location, "Error checking for linked users", identity.Subject)
+	}
+
+	if user.MapIDs == nil {
+		user.MapIDs = mapof.NewString()
+	}
+
+	user.MapIDs[model.UserMapIDOIDC] = identity.Subject
+
+	if err := service.setGroups(identity, user); err != nil {
+		return derp.Wrap(err, location, "Error updating groups")
+	}
+
+	if err := service.userService.Save(user, "Linked to "+service.Config().Label); err != nil {
+		return derp.Wrap(err, location, "Error saving user")
+	}
+
+	return nil
+}
+
+// UnlinkUser removes the link between a User and their provider identity
+func (service OIDC) UnlinkUser(user *model.User) error {
+
+	delete(user.MapIDs, model.UserMapIDOIDC)
+
+	if err := service.userService.Save(user, "Unlinked from "+service.Config().Label); err != nil {
+		return derp.Wrap(err, "service.OIDC.UnlinkUser", "Error saving user")
+	}
+
+	return nil
+}
+
+/******************************************
+ * Helper Methods
+ ******************************************/
+
+// provider returns the (cached) provider and OAuth configuration for this domain
+func (service OIDC) provider(ctx context.Context) (*oidc.Provider, oauth2.Config, error) {
+
+	const location = "service.OIDC.provider"
+
+	config := service.Config()
+
+	if !config.IsReady() {
+		return nil, oauth2.Config{}, derp.NewNotFoundError(location, "OpenID Connect is not configured")
+	}
+
+	provider, err := service.providers.Get(ctx, config.Issuer)
+
+	if err != nil {
+		return nil, oauth2.Config{}, derp.Wrap(err, location, "Error loading provider", config.Issuer)
+	}
+
+	scopes := []string{oidc.ScopeOpenID, "profile", "email"}
+
+	for _, scope := range config.Scopes {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	oauthConfig := oauth2.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  service.RedirectURL(),
+		Scopes:       scopes,
+	}
+
+	return provider, oauthConfig, nil
+}
+
+// mergeUserInfo adds claims from the provider's UserInfo endpoint that are not already in the ID Token
+func (service OIDC) mergeUserInfo(ctx context.Context, provider *oidc.Provider, token *oauth2.Token, claims mapof.Any) error {
+
+	const location = "service.OIDC.mergeUserInfo"
+
+	// Not all providers publish a UserInfo endpoint
+	if provider.UserInfoEndpoint() == "" {
+		return nil
+	}
+
+	userInfo, err := provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading user info")
+	}
+
+	// RULE: UserInfo must describe the same subject as the ID Token
+	if userInfo.Subject != claims["sub"] {
+		return derp.NewBadRequestError(location, "UserInfo subject does not match ID Token", userInfo.Subject)
+	}
+
+	extra := mapof.NewAny()
+
+	if err := userInfo.Claims(&extra); err != nil {
+		return derp.Wrap(err, location, "Error reading user info claims")
+	}
+
+	for key, value := range extra {
+		if _, exists := claims[key]; !exists {
+			claims[key] = value
+		}
+	}
+
+	return nil
+}
+
+// setGroups adds and removes the User from mapped Groups, based on the identity's groups claim
+func (service OIDC) setGroups(identity model.OIDCIdentity, user *model.User) error {
+
+	const location = "service.OIDC.setGroups"
+
+	add, remove := identity.MappedGroups(service.Config().GroupMap)
+
+	for _, token := range add {
+		group := model.NewGroup()
+
+		if err := service.groupService.LoadByToken(token, &group); err != nil {
+			return derp.Wrap(err, location, "Error loading group", token)
+		}
+
+		user.AddGroup(group.GroupID)
+	}
+
+	for _, token := range remove {
+		group := model.NewGroup()
+
+		if err := service.groupService.LoadByToken(token, &group); err != nil {
+			return derp.Wrap(err, location, "Error loading group", token)
+		}
+
+		user.RemoveGroup(group.GroupID)
+	}
+
+	return nil
+}
+
+// oidcUsername returns a username suggested by the identity, using only characters that are safe in URLs
+func oidcUsername(identity model.OIDCIdentity) string {
+
+	username := identity.Username
+
+	if username == "" {
+		username, _, _ = strings.Cut(identity.EmailAddress, "@")
+	}
+
+	result := strings.Builder{}
+
+	for _, r := range strings.ToLower(username) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			result.WriteRune(r)
+		}
+	}
+
+	if result.Len() > 32 {
+		return result.String()[:32]
+	}
+
+	return result.String()
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/benpate/derp"
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// oidcProviderCacheDuration is how long discovered provider settings are reused
+// before they are fetched again.  Signing keys are refreshed separately by the provider.
+const oidcProviderCacheDuration = time.Hour
+
+// OIDCProviders caches the settings discovered from OpenID Connect providers, so that
+// discovery documents are not downloaded on every sign-in.  Each domain has its own
+// cache, and providers are keyed by their issuer URL.
+type OIDCProviders struct {
+	providers map[string]oidcProvider
+	mutex     *sync.Mutex
+}
+
+// oidcProvider is a single cached provider
+type oidcProvider struct {
+	provider *oidc.Provider
+	expires  time.Time
+}
+
+// NewOIDCProviders returns a fully initialized OIDCProviders cache
+func NewOIDCProviders() OIDCProviders {
+	return OIDCProviders{
+		providers: make(map[string]oidcProvider),
+		mutex:     &sync.Mutex{},
+	}
+}
+
+// Get returns the provider for the issuer URL, performing discovery if it
+// is not already in the cache (or has expired).
+func (cache *OIDCProviders) Get(ctx context.Context, issuer string) (*oidc.Provider, error) {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cached, exists := cache.providers[issuer]; exists && time.Now().Before(cached.expires) {
+		return cached.provider, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, issuer)
+
+	if err != nil {
+		return nil, derp.Wrap(err, "service.OIDCProviders.Get", "Error discovering provider", issuer)
+	}
+
+	cache.providers[issuer] = oidcProvider{
+		provider: provider,
+		expires:  time.Now().Add(oidcProviderCacheDuration),
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// mockOIDCProvider is a minimal OpenID Connect provider that supports
+// discovery, PKCE, signed ID Tokens, and the UserInfo endpoint.
+type mockOIDCProvider struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	codeChallenge string
+	nonce         string
+	claims        jwt.MapClaims
+	userInfo      mapof.Any
+	discoveries   atomic.Int32
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	provider := &mockOIDCProvider{key: key}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		provider.discoveries.Add(1)
+		host := provider.server.URL
+		writeJSON(w, mapof.Any{
+			"issuer":                                host,
+			"authorization_endpoint":                host + "/authorize",
+			"token_endpoint":                        host + "/token",
+			"userinfo_endpoint":                     host + "/userinfo",
+			"jwks_uri":                              host + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, mapof.Any{"keys": []mapof.Any{{
+			"kty": "RSA",
+			"kid": "test",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+
+		// Validate the authorization code and PKCE verifier
+		verifier := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+
+		if r.FormValue("code") != "good-code" || base64.RawURLEncoding.EncodeToString(verifier[:]) != provider.codeChallenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":   provider.server.URL,
+			"aud":   "emissary",
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": provider.nonce,
+		}
+
+		for key, value := range provider.claims {
+			claims[key] = value
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test"
+		idToken, err := token.SignedString(key)
+		require.Nil(t, err)
+
+		writeJSON(w, mapof.Any{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   60,
+			"id_token":     idToken,
+		})
+	})
+
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, provider.userInfo)
+	})
+
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+
+	return provider
+}
+
+// authorize simulates a user signing in at the provider, recording the
+// PKCE challenge and nonce from the authorization URL
+func (provider *mockOIDCProvider) authorize(t *testing.T, authCodeURL string) url.Values {
+	parsed, err := url.Parse(authCodeURL)
+	require.Nil(t, err)
+
+	query := parsed.Query()
+	provider.codeChallenge = query.Get("code_challenge")
+	provider.nonce = query.Get("nonce")
+	return query
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func newTestOIDC(provider *mockOIDCProvider, groupsClaim string) OIDC {
+	domain := model.NewDomain()
+	domain.Data["oidc_active"] = "true"
+	domain.Data["oidc_issuer"] = provider.server.URL
+	domain.Data["oidc_clientId"] = "emissary"
+	domain.OIDCClientSecret = "secret"
+	domain.Data["oidc_groupsClaim"] = groupsClaim
+
+	providers := NewOIDCProviders()
+	return NewOIDC(nil, nil, nil, nil, &providers, domain, "https://example.com")
+}
+
+func TestOIDC_Exchange(t *testing.T) {
+
+	provider := newMockOIDCProvider(t)
+	provider.claims = jwt.MapClaims{
+		"sub":                "user-123",
+		"email":              "alice@example.com",
+		"email_verified":     true,
+		"preferred_username": "alice",
+		"name":               "Alice",
+		"groups":             []string{"staff", "editors"},
+	}
+
+	service := newTestOIDC(provider, "groups")
+	ctx := context.Background()
+
+	challenge, err := service.NewChallenge("/home")
+	require.Nil(t, err)
+
+	authCodeURL, err := service.AuthCodeURL(ctx, challenge)
+	require.Nil(t, err)
+
+	query := provider.authorize(t, authCodeURL)
+	require.Equal(t, "emissary", query.Get("client_id"))
+	require.Equal(t, "https://example.com/oidc/callback", query.Get("redirect_uri"))
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+	require.Equal(t, challenge.State, query.Get("state"))
+	require.Equal(t, challenge.Nonce, query.Get("nonce"))
+	require.Contains(t, query.Get("scope"), "openid")
+
+	identity, err := service.Exchange(ctx, challenge, query.Get("state"), "good-code")
+	require.Nil(t, err)
+	require.Equal(t, "user-123", identity.Subject)
+	require.Equal(t, "alice@example.com", identity.EmailAddress)
+	require.True(t, identity.EmailVerified)
+	require.Equal(t, "alice", identity.Username)
+	require.Equal(t, "Alice", identity.DisplayName)
+	require.Equal(t, []string{"staff", "editors"}, []string(identity.Groups))
+}
+
+func TestOIDC_Exchange_UserInfoGroups(t *testing.T) {
+
+	provider := newMockOIDCProvider(t)
+	provider.claims = jwt.MapClaims{"sub": "user-123"}
+	provider.userInfo = mapof.Any{
+		"sub":          "user-123",
+		"realm_access": mapof.Any{"roles": []string{"admin"}},
+	}
+
+	service := newTestOIDC(provider, "realm_access.roles")
+	ctx := context.Background()
+
+	challenge, err := service.NewChallenge("")
+	require.Nil(t, err)
+
+	authCodeURL, err := service.AuthCodeURL(ctx, challenge)
+	require.Nil(t, err)
+	provider.authorize(t, authCodeURL)
+
+	identity, err := service.Exchange(ctx, challenge, challenge.State, "good-code")
+	require.Nil(t, err)
+	require.Equal(t, []string{"admin"}, []string(identity.Groups))
+}
+
+func TestOIDC_Exchange_Invalid(t *testing.T) {
+
+	provider := newMockOIDCProvider(t)
+	provider.claims = jwt.MapClaims{"sub": "user-123"}
+
+	service := newTestOIDC(provider, "")
+	ctx := context.Background()
+
+	begin := func() model.OIDCChallenge {
+		challenge, err := service.NewChallenge("")
+		require.Nil(t, err)
+
+		authCodeURL, err := service.AuthCodeURL(ctx, challenge)
+		require.Nil(t, err)
+		provider.authorize(t, authCodeURL)
+		return challenge
+	}
+
+	// State must match the challenge
+	challenge := begin()
+	_, err := service.Exchange(ctx, challenge, "wrong-state", "good-code")
+	require.NotNil(t, err)
+
+	// Authorization code must be valid
+	challenge = begin()
+	_, err = service.Exchange(ctx, challenge, challenge.State, "bad-code")
+	require.NotNil(t, err)
+
+	// PKCE verifier must match the code challenge
+	challenge = begin()
+	challenge.Verifier = "wrong-verifier-wrong-verifier-wrong-verifier"
+	_, err = service.Exchange(ctx, challenge, challenge.State, "good-code")
+	require.NotNil(t, err)
+
+	// Nonce must match the challenge
+	challenge = begin()
+	provider.nonce = "replayed-nonce"
+	_, err = service.Exchange(ctx, challenge, challenge.State, "good-code")
+	require.NotNil(t, err)
+}
+
+func TestOIDC_ProviderCache(t *testing.T) {
+
+	provider := newMockOIDCProvider(t)
+	provider.claims = jwt.MapClaims{"sub": "user-123"}
+
+	service := newTestOIDC(provider, "")
+	ctx := context.Background()
+
+	// Discovery happens once, and is reused by every sign-in and callback
+	for range 3 {
+		challenge, err := service.NewChallenge("")
+		require.Nil(t, err)
+
+		authCodeURL, err := service.AuthCodeURL(ctx, challenge)
+		require.Nil(t, err)
+		provider.authorize(t, authCodeURL)
+
+		_, err = service.Exchange(ctx, challenge, challenge.State, "good-code")
+		require.Nil(t, err)
+	}
+
+	require.Equal(t, int32(1), provider.discoveries.Load())
+
+	// Changing the issuer discovers the new provider
+	other := newMockOIDCProvider(t)
+	service.domain.Data["oidc_issuer"] = other.server.URL
+
+	challenge, err := service.NewChallenge("")
+	require.Nil(t, err)
+
+	_, err = service.AuthCodeURL(ctx, challenge)
+	require.Nil(t, err)
+	require.Equal(t, int32(1), other.discoveries.Load())
+}
+
+func TestOIDC_NotConfigured(t *testing.T) {
+
+	service := NewOIDC(nil, nil, nil, nil, nil, model.NewDomain(), "https://example.com")
+
+	challenge, err := service.NewChallenge("")
+	require.Nil(t, err)
+
+	_, err = service.AuthCodeURL(context.Background(), challenge)
+	require.NotNil(t, err)
+}
+
+func TestOIDCUsername(t *testing.T) {
+	require.Equal(t, "johndoe", oidcUsername(model.OIDCIdentity{Username: "John.Doe"}))
+	require.Equal(t, "alice_b", oidcUsername(model.OIDCIdentity{EmailAddress: "Alice_B@example.com"}))
+	require.Equal(t, "", oidcUsername(model.OIDCIdentity{}))
+}
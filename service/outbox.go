@@ -22,8 +22,10 @@ type Outbox struct {
 	templateService *Template
 	userService     *User
 	domainEmail     *DomainEmail
+	domainService   *Domain
 	lock            *sync.Mutex
 	queue           *queue.Queue
+	host            string
 }
 
 // NewOutbox returns a fully populated Outbox service
@@ -38,7 +40,7 @@ func NewOutbox() Outbox {
  ******************************************/
 
 // Refresh updates any stateful data that is cached inside this service.
-func (service *Outbox) Refresh(collection data.Collection, streamService *Stream, activityService *ActivityStream, followerService *Follower, templateService *Template, userService *User, domainEmail *DomainEmail, queue *queue.Queue) {
+func (service *Outbox) Refresh(collection data.Collection, streamService *Stream, activityService *ActivityStream, followerService *Follower, templateService *Template, userService *User, domainEmail *DomainEmail, domainService *Domain, queue *queue.Queue, host string) {
 	service.collection = collection
 	service.streamService = streamService
 	service.activityService = activityService
@@ -46,7 +48,9 @@ func (service *Outbox) Refresh(collection data.Collection, streamService *Stream
 	service.templateService = templateService
 	service.userService = userService
 	service.domainEmail = domainEmail
+	service.domainService = domainService
 	service.queue = queue
+	service.host = host
 }
 
 // Close stops any background processes controlled by this service
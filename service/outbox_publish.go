@@ -1,12 +1,15 @@
 package service
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/EmissarySocial/emissary/model"
 	"github.com/benpate/derp"
+	"github.com/benpate/domain"
 	"github.com/benpate/hannibal/outbox"
 	"github.com/benpate/hannibal/vocab"
+	"github.com/benpate/rosetta/convert"
 	"github.com/benpate/rosetta/mapof"
 	"github.com/benpate/turbine/queue"
 	"github.com/rs/zerolog/log"
@@ -44,7 +47,7 @@ func (service *Outbox) Publish(actor *outbox.Actor, parentType string, parentID
 
 	// Send notifications to all Followers
 	go service.sendNotifications_ActivityPub(actor, activity)
-	go service.sendNotifications_WebSub(parentType, parentID)
+	go service.sendNotifications_WebSub(parentType, parentID, activity)
 	go service.sendNotifications_WebMention(activity)
 	go service.sendNotifications_Email(parentType, parentID, activity)
 
@@ -97,25 +100,59 @@ func (service Outbox) sendNotifications_ActivityPub(actor *outbox.Actor, activit
 	actor.Send(activity)
 }
 
-// TODO: HIGH: Thoroughly re-test WebSub notifications.  They've been rebuilt from scratch.
-func (service Outbox) sendNotifications_WebSub(parentType string, parentID primitive.ObjectID) {
+// sendNotifications_WebSub sends "fat pings" containing the newly published item to all WebSub Followers,
+// and notifies any external WebSub hubs that are configured for this domain.
+func (service Outbox) sendNotifications_WebSub(parentType string, parentID primitive.ObjectID, activity mapof.Any) {
 
 	const location = "service.Outbox.sendNotifications_WebSub"
 
+	host := domain.NameOnly(service.host)
+	objectID := activity.GetMap(vocab.PropertyObject).GetString(vocab.PropertyID)
+
+	// Notify external hubs that this feed has changed
+	if hubs := service.domainService.Get().WebSubHubURLs(); hubs.NotEmpty() {
+
+		task := queue.NewTask("PingWebSubHubs", mapof.Any{
+			"host":       host,
+			"parentType": parentType,
+			"parentId":   parentID.Hex(),
+		})
+
+		if err := service.queue.Publish(task); err != nil {
+			derp.Report(derp.Wrap(err, location, "Error publishing task", task))
+		}
+	}
+
+	// RULE: Only activities with an object (e.g. Create, Update, Announce) can be distributed to subscribers
+	if objectID == "" {
+		return
+	}
+
+	// RULE: WebSub subscribers are anonymous, so they only receive public activities.
+	// (SavedSearches only announce public SearchResults)
+	if (parentType != model.FollowerTypeSearch) && !isPublicActivity(activity) {
+		return
+	}
+
 	// Get this User's Followers from the database
 	followers, err := service.followerService.WebSubFollowersChannel(parentType, parentID)
 
 	if err != nil {
 		derp.Report(derp.Wrap(err, location, "Error loading Followers", parentType, parentID))
+		return
 	}
 
-	// Queue up all ActivityPub messages to be sent
+	// Queue up all WebSub messages to be sent
 	for follower := range followers {
 
 		task := queue.NewTask("SendWebSubMessage", mapof.Any{
-			"inboxUrl": follower.Actor.InboxURL,
-			"format":   follower.Format,
-			"secret":   follower.Data.GetString("secret"),
+			"host":       host,
+			"parentType": parentType,
+			"parentId":   parentID.Hex(),
+			"objectId":   objectID,
+			"inboxUrl":   follower.Actor.InboxURL,
+			"format":     follower.Format,
+			"secret":     follower.Data.GetString("secret"),
 		})
 
 		if err := service.queue.Publish(task); err != nil {
@@ -182,3 +219,17 @@ func (service *Outbox) sendNotifications_Email(parentType string, parentID primi
 		}
 	}
 }
+
+// isPublicActivity returns TRUE if an activity (or its object) is addressed to the public collection
+func isPublicActivity(activity mapof.Any) bool {
+
+	object := activity.GetMap(vocab.PropertyObject)
+
+	for _, value := range []any{activity[vocab.PropertyTo], activity[vocab.PropertyCC], object[vocab.PropertyTo], object[vocab.PropertyCC]} {
+		if slices.Contains(convert.SliceOfString(value), vocab.NamespaceActivityStreamsPublic) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/benpate/hannibal/vocab"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicActivity(t *testing.T) {
+
+	// Activities addressed to the public collection
+	require.True(t, isPublicActivity(mapof.Any{
+		vocab.PropertyTo: []string{vocab.NamespaceActivityStreamsPublic},
+	}))
+
+	require.True(t, isPublicActivity(mapof.Any{
+		vocab.PropertyObject: mapof.Any{
+			vocab.PropertyCC: []any{"https://example.com/@alice/followers", vocab.NamespaceActivityStreamsPublic},
+		},
+	}))
+
+	// Private and members-only activities
+	require.False(t, isPublicActivity(mapof.Any{}))
+
+	require.False(t, isPublicActivity(mapof.Any{
+		vocab.PropertyTo:     []string{"https://example.com/@alice/followers"},
+		vocab.PropertyObject: mapof.Any{vocab.PropertyID: "https://example.com/private-post"},
+	}))
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/benpate/turbine/queue"
+)
+
+// testTaskStorage records every Task that is published to a test queue
+type testTaskStorage struct {
+	tasks []queue.Task
+	mutex sync.Mutex
+}
+
+// newTestQueue returns a queue that saves every published Task into storage
+// (without running it) so that tests can inspect which Tasks were published.
+func newTestQueue() (*queue.Queue, *testTaskStorage) {
+
+	storage := &testTaskStorage{}
+
+	result := queue.New(
+		queue.WithStorage(storage),
+		queue.WithPollStorage(false),
+		queue.WithRunImmediatePriority(-1),
+		queue.WithWorkerCount(0),
+	)
+
+	return &result, storage
+}
+
+// Names returns the names of all Tasks that have been published
+func (storage *testTaskStorage) Names() []string {
+
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	result := make([]string, 0, len(storage.tasks))
+
+	for _, task := range storage.tasks {
+		result = append(result, task.Name)
+	}
+
+	return result
+}
+
+func (storage *testTaskStorage) GetTasks() ([]queue.Task, error) {
+	return nil, nil
+}
+
+func (storage *testTaskStorage) SaveTask(task queue.Task) error {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	storage.tasks = append(storage.tasks, task)
+	return nil
+}
+
+func (storage *testTaskStorage) DeleteTask(taskID string) error {
+	return nil
+}
+
+func (storage *testTaskStorage) LogFailure(task queue.Task) error {
+	return nil
+}
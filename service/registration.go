@@ -241,6 +241,54 @@ func (service *Registration) UpdateRegistration(groupService *Group, userService
 	return nil
 }
 
+// RegisterExternal creates a new User for an identity that has already been verified by an
+// external service (such as an OpenID Connect provider).  The User is linked to the service
+// via its MapIDs, and the Domain's registration settings (state, templates, and groups) are
+// applied just as they would be for an online signup.
+func (service *Registration) RegisterExternal(groupService *Group, userService *User, domain *model.Domain, source string, sourceID string, txn model.RegistrationTxn) (model.User, error) {
+
+	const location = "service.Registration.RegisterExternal"
+
+	// RULE: External identities must include a source ID
+	if sourceID == "" {
+		return model.User{}, derp.NewBadRequestError(location, "Source ID is required", source)
+	}
+
+	// RULE: External identities must include an email address
+	if txn.EmailAddress == "" {
+		return model.User{}, derp.NewBadRequestError(location, "Email address is required", source, sourceID)
+	}
+
+	user := model.NewUser()
+
+	// RULE: Email addresses must be unique.  Existing accounts are linked, not duplicated.
+	if err := userService.LoadByEmail(txn.EmailAddress, &user); !derp.NotFound(err) {
+		return model.User{}, derp.NewBadRequestError(location, "An account with this email address already exists", txn.EmailAddress)
+	}
+
+	// RULE: If the requested username is already taken, then calculate a new one when saving
+	if txn.Username != "" {
+		if err := userService.LoadByUsername(txn.Username, &user); !derp.NotFound(err) {
+			txn.Username = ""
+		}
+	}
+
+	// Copy Transaction data into a new User object
+	user = model.NewUser()
+	user.MapIDs[source] = sourceID
+
+	if err := service.setUserData(groupService, domain, &user, txn, []string{"displayName", "emailAddress", "username"}); err != nil {
+		return model.User{}, derp.Wrap(err, location, "Error setting user data")
+	}
+
+	// Try to save the User to the database
+	if err := userService.Save(&user, "Created by "+source+" sign-in"); err != nil {
+		return model.User{}, derp.Wrap(err, location, "Error creating new User")
+	}
+
+	return user, nil
+}
+
 // setUserData copies all allowed fields from the Transaction into the User, and silently warns if any field names are not recognized
 func (service *Registration) setUserData(groupService *Group, domain *model.Domain, user *model.User, txn model.RegistrationTxn, allowedFields []string) error {
 
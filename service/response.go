@@ -179,7 +179,7 @@ func (service *Response) QueryByUserAndDate(userID primitive.ObjectID, responseT
 
 func (service *Response) QueryByObjectAndDate(objectID string, responseType string, maxDate int64, pageSize int) ([]model.Response, error) {
 
-	criteria := exp.Equal("objectId", objectID).AndEqual("type", responseType).And(exp.LessThan("createDate", maxDate))
+	criteria := exp.Equal("object", objectID).AndEqual("type", responseType).And(exp.LessThan("createDate", maxDate))
 	options := []option.Option{option.SortDesc("createDate"), option.MaxRows(int64(pageSize))}
 
 	return service.Query(criteria, options...)
@@ -215,6 +215,11 @@ func (service *Response) LoadByActorAndObject(actor string, object string, respo
 	return service.Load(criteria, response)
 }
 
+// LoadByURL loads a Response that was received via WebMention, using the URL of its source document
+func (service *Response) LoadByURL(url string, response *model.Response) error {
+	return service.Load(exp.Equal("url", url), response)
+}
+
 func (service *Response) CountByContent(objectID string) (mapof.Int, error) {
 	return queries.CountResponsesByContent(service.collection, objectID)
 }
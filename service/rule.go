@@ -350,7 +350,7 @@ func (service *Rule) QueryDomainBlocks() ([]model.Rule, error) {
 
 	criteria := exp.Equal("userId", primitive.NilObjectID).
 		AndEqual("type", model.RuleTypeDomain).
-		AndEqual("behavior", model.RuleActionBlock)
+		AndEqual("action", model.RuleActionBlock)
 
 	return service.Query(criteria, option.SortAsc("trigger"))
 }
@@ -360,11 +360,49 @@ func (service *Rule) QueryBlockedActors(userID primitive.ObjectID) ([]model.Rule
 
 	criteria := service.byUserID(userID).
 		AndEqual("type", model.RuleTypeActor).
-		AndEqual("behavior", model.RuleActionBlock)
+		AndEqual("action", model.RuleActionBlock)
 
 	return service.Query(criteria, option.SortAsc("trigger"))
 }
 
+// QueryByBlocklist returns all domain-wide Rules that were imported from the provided Blocklist
+func (service *Rule) QueryByBlocklist(blocklistID primitive.ObjectID) ([]model.Rule, error) {
+
+	criteria := exp.Equal("userId", primitive.NilObjectID).
+		AndEqual("blocklistId", blocklistID)
+
+	return service.Query(criteria, option.SortAsc("trigger"))
+}
+
+// LoadByBlocklist retrieves a single domain-wide Rule that was imported from the provided Blocklist
+func (service *Rule) LoadByBlocklist(blocklistID primitive.ObjectID, ruleType string, trigger string, rule *model.Rule) error {
+
+	criteria := exp.Equal("userId", primitive.NilObjectID).
+		AndEqual("blocklistId", blocklistID).
+		AndEqual("type", ruleType).
+		AndEqual("trigger", trigger)
+
+	return service.Load(criteria, rule)
+}
+
+// DeleteByBlocklist removes all domain-wide Rules that were imported from the provided Blocklist
+func (service *Rule) DeleteByBlocklist(blocklistID primitive.ObjectID, note string) error {
+
+	rules, err := service.QueryByBlocklist(blocklistID)
+
+	if err != nil {
+		return derp.Wrap(err, "service.Rule.DeleteByBlocklist", "Error querying rules", blocklistID)
+	}
+
+	for index := range rules {
+		if err := service.Delete(&rules[index], note); err != nil {
+			return derp.Wrap(err, "service.Rule.DeleteByBlocklist", "Error deleting rule", rules[index])
+		}
+	}
+
+	return nil
+}
+
 /******************************************
  * Rule Filters
  ******************************************/
@@ -378,6 +416,8 @@ func (service *Rule) Filter(userID primitive.ObjectID, options ...RuleFilterOpti
  ******************************************/
 
 // hasDuplicate returns TRUE if the provided Rule is a duplicate of an existing Rule.
+// Each Blocklist owns its own copy of a Rule, so Rules imported from different Blocklists
+// (or from a Blocklist and by hand) are never duplicates of one another.
 // IMPORTANT: This method MAY update the provided Rule
 func (service *Rule) hasDuplicate(rule *model.Rule) bool {
 
@@ -387,10 +427,18 @@ func (service *Rule) hasDuplicate(rule *model.Rule) bool {
 		AndEqual("type", rule.Type).
 		AndEqual("trigger", rule.Trigger)
 
-	duplicate := model.NewRule()
+	candidates, err := service.Query(criteria)
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, "service.Rule.hasDuplicate", "Error searching for duplicate rules", criteria))
+		return false
+	}
+
+	// Older Rules may not have a blocklistId at all, so the owner is compared here instead of in the query
+	duplicate, found := findRuleByBlocklist(candidates, rule.BlocklistID)
 
 	// If a duplicate is not found, then return FALSE
-	if err := service.Load(criteria, &duplicate); derp.NotFound(err) {
+	if !found {
 		return false
 	}
 
@@ -407,6 +455,19 @@ func (service *Rule) hasDuplicate(rule *model.Rule) bool {
 	return true
 }
 
+// findRuleByBlocklist returns the first Rule that was imported from the provided
+// Blocklist.  A zero blocklistID matches Rules that were not imported from any Blocklist.
+func findRuleByBlocklist(rules []model.Rule, blocklistID primitive.ObjectID) (model.Rule, bool) {
+
+	for _, rule := range rules {
+		if rule.BlocklistID == blocklistID {
+			return rule, true
+		}
+	}
+
+	return model.Rule{}, false
+}
+
 // byUserID generates a criteria expression that searches for:
 // 1) Rules that belong to the provided User
 // 2) Rules that belong to no User (i.e. public rules)
@@ -50,17 +50,18 @@ func (filter *RuleFilter) AllowSend(actorID string) bool {
 	return true
 }
 
-// ChannelSend inspects the channel of recipients to see if they should receive messages or not.
-func (filter *RuleFilter) ChannelSend(ch <-chan model.Follower) <-chan string {
+// ChannelSend inspects the channel of Followers to see if they should receive messages or not,
+// and returns a channel containing only the Followers who are allowed.
+func (filter *RuleFilter) ChannelSend(ch <-chan model.Follower) <-chan model.Follower {
 
-	result := make(chan string)
+	result := make(chan model.Follower)
 	go func() {
 		defer close(result)
 
 		for follower := range ch {
 			if filter.AllowSend(follower.Actor.ProfileURL) {
 				log.Trace().Str("loc", "service.RuleFilter.ChannelSend").Str("actorID", follower.Actor.ProfileURL).Msg("Allowed")
-				result <- follower.Actor.ProfileURL
+				result <- follower
 			} else {
 				log.Trace().Str("loc", "service.RuleFilter.ChannelSend").Str("actorID", follower.Actor.ProfileURL).Msg("Blocked")
 			}
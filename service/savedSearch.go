@@ -0,0 +1,294 @@
+package service
+
+import (
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/data"
+	"github.com/benpate/data/option"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+	"github.com/benpate/rosetta/schema"
+	"github.com/benpate/rosetta/sliceof"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SavedSearch manages the search queries that people can follow via ActivityPub, WebSub, feeds, and email.
+type SavedSearch struct {
+	collection      data.Collection
+	searchService   *Search
+	followerService *Follower
+	keyService      *EncryptionKey
+	ruleService     *Rule
+	activityStream  *ActivityStream
+	host            string
+}
+
+// NewSavedSearch returns a fully initialized SavedSearch service
+func NewSavedSearch() SavedSearch {
+	return SavedSearch{}
+}
+
+/******************************************
+ * Lifecycle Methods
+ ******************************************/
+
+// Refresh updates any stateful data that is cached inside this service.
+func (service *SavedSearch) Refresh(collection data.Collection, searchService *Search, followerService *Follower, keyService *EncryptionKey, ruleService *Rule, activityStream *ActivityStream, host string) {
+	service.collection = collection
+	service.searchService = searchService
+	service.followerService = followerService
+	service.keyService = keyService
+	service.ruleService = ruleService
+	service.activityStream = activityStream
+	service.host = host
+}
+
+// Close stops any background processes controlled by this service
+func (service *SavedSearch) Close() {
+	// Nothin to do here.
+}
+
+/******************************************
+ * Common Data Methods
+ ******************************************/
+
+// Count returns the number of records that match the provided criteria
+func (service *SavedSearch) Count(criteria exp.Expression) (int64, error) {
+	return service.collection.Count(notDeleted(criteria))
+}
+
+// Query returns a slice containing all of the SavedSearches that match the provided criteria
+func (service *SavedSearch) Query(criteria exp.Expression, options ...option.Option) ([]model.SavedSearch, error) {
+	result := make([]model.SavedSearch, 0)
+	err := service.collection.Query(&result, notDeleted(criteria), options...)
+	return result, err
+}
+
+// List returns an iterator containing all of the SavedSearches that match the provided criteria
+func (service *SavedSearch) List(criteria exp.Expression, options ...option.Option) (data.Iterator, error) {
+	return service.collection.Iterator(notDeleted(criteria), options...)
+}
+
+// Range returns a Go 1.23 RangeFunc that iterates over the SavedSearches that match the provided criteria
+func (service *SavedSearch) Range(criteria exp.Expression, options ...option.Option) (iter.Seq[model.SavedSearch], error) {
+
+	it, err := service.List(criteria, options...)
+
+	if err != nil {
+		return nil, derp.Wrap(err, "service.SavedSearch.Range", "Error creating iterator", criteria)
+	}
+
+	return RangeFunc(it, model.NewSavedSearch), nil
+}
+
+// Load retrieves a SavedSearch from the database
+func (service *SavedSearch) Load(criteria exp.Expression, savedSearch *model.SavedSearch) error {
+
+	if err := service.collection.Load(notDeleted(criteria), savedSearch); err != nil {
+		return derp.Wrap(err, "service.SavedSearch.Load", "Error loading SavedSearch", criteria)
+	}
+
+	return nil
+}
+
+// Save adds/updates a SavedSearch in the database
+func (service *SavedSearch) Save(savedSearch *model.SavedSearch, note string) error {
+
+	const location = "service.SavedSearch.Save"
+
+	// RULE: SavedSearches must filter results somehow
+	if savedSearch.IsEmpty() {
+		return derp.NewBadRequestError(location, "SavedSearch must include text, tags, or types", savedSearch)
+	}
+
+	// RULE: Calculate the public URL of this SavedSearch
+	savedSearch.URL = service.host + "/.searches/" + savedSearch.SavedSearchID.Hex()
+
+	// Validate the value before saving
+	if err := service.Schema().Validate(savedSearch); err != nil {
+		return derp.Wrap(err, location, "Error validating SavedSearch", savedSearch)
+	}
+
+	if err := service.collection.Save(savedSearch, note); err != nil {
+		return derp.Wrap(err, location, "Error saving SavedSearch", savedSearch, note)
+	}
+
+	return nil
+}
+
+// Delete removes a SavedSearch from the database
+func (service *SavedSearch) Delete(savedSearch *model.SavedSearch, note string) error {
+
+	if err := service.collection.Delete(savedSearch, note); err != nil {
+		return derp.Wrap(err, "service.SavedSearch.Delete", "Error deleting SavedSearch", savedSearch, note)
+	}
+
+	return nil
+}
+
+/******************************************
+ * Model Service Methods
+ ******************************************/
+
+// ObjectType returns the type of object that this service manages
+func (service *SavedSearch) ObjectType() string {
+	return "SavedSearch"
+}
+
+// ObjectNew returns a fully initialized model.SavedSearch as a data.Object.
+func (service *SavedSearch) ObjectNew() data.Object {
+	result := model.NewSavedSearch()
+	return &result
+}
+
+func (service *SavedSearch) ObjectID(object data.Object) primitive.ObjectID {
+
+	if savedSearch, ok := object.(*model.SavedSearch); ok {
+		return savedSearch.SavedSearchID
+	}
+
+	return primitive.NilObjectID
+}
+
+func (service *SavedSearch) ObjectQuery(result any, criteria exp.Expression, options ...option.Option) error {
+	return service.collection.Query(result, notDeleted(criteria), options...)
+}
+
+func (service *SavedSearch) ObjectList(criteria exp.Expression, options ...option.Option) (data.Iterator, error) {
+	return service.List(criteria, options...)
+}
+
+func (service *SavedSearch) ObjectLoad(criteria exp.Expression) (data.Object, error) {
+	result := model.NewSavedSearch()
+	err := service.Load(criteria, &result)
+	return &result, err
+}
+
+func (service *SavedSearch) ObjectSave(object data.Object, note string) error {
+	if savedSearch, ok := object.(*model.SavedSearch); ok {
+		return service.Save(savedSearch, note)
+	}
+	return derp.NewInternalError("service.SavedSearch.ObjectSave", "Invalid object type", object)
+}
+
+func (service *SavedSearch) ObjectDelete(object data.Object, note string) error {
+	if savedSearch, ok := object.(*model.SavedSearch); ok {
+		return service.Delete(savedSearch, note)
+	}
+	return derp.NewInternalError("service.SavedSearch.ObjectDelete", "Invalid object type", object)
+}
+
+func (service *SavedSearch) ObjectUserCan(object data.Object, authorization model.Authorization, action string) error {
+	return derp.NewUnauthorizedError("service.SavedSearch.ObjectUserCan", "Not Authorized")
+}
+
+func (service *SavedSearch) Schema() schema.Schema {
+	return schema.New(model.SavedSearchSchema())
+}
+
+/******************************************
+ * Custom Queries
+ ******************************************/
+
+// LoadByID retrieves a single SavedSearch by its unique ID
+func (service *SavedSearch) LoadByID(savedSearchID primitive.ObjectID, result *model.SavedSearch) error {
+	return service.Load(exp.Equal("_id", savedSearchID), result)
+}
+
+// LoadByToken retrieves a single SavedSearch using a string representation of its unique ID
+func (service *SavedSearch) LoadByToken(token string, result *model.SavedSearch) error {
+
+	savedSearchID, err := primitive.ObjectIDFromHex(token)
+
+	if err != nil {
+		return derp.Wrap(err, "service.SavedSearch.LoadByToken", "Invalid SavedSearch ID", token)
+	}
+
+	return service.LoadByID(savedSearchID, result)
+}
+
+// LoadOrCreateByQuery finds the SavedSearch that matches a search query, creating a new one
+// if necessary.  Hashtags in the query are saved as tag filters, and the remaining words
+// are used as the free-text query.
+func (service *SavedSearch) LoadOrCreateByQuery(queryString string, types []string) (model.SavedSearch, error) {
+
+	const location = "service.SavedSearch.LoadOrCreateByQuery"
+
+	result := model.NewSavedSearch()
+	result.SetQueryString(queryString)
+
+	// Normalize tags and types so that equivalent queries find the same record
+	for _, value := range types {
+		if value = strings.TrimSpace(value); (value != "") && !result.Types.Contains(value) {
+			result.Types = append(result.Types, value)
+		}
+	}
+
+	slices.Sort(result.Tags)
+	slices.Sort(result.Types)
+
+	// Look for an existing SavedSearch with the same query
+	criteria := exp.Equal("text", result.Text).
+		AndEqual("tags", result.Tags).
+		AndEqual("types", result.Types)
+
+	if err := service.Load(criteria, &result); err == nil {
+		return result, nil
+	} else if !derp.NotFound(err) {
+		return result, derp.Wrap(err, location, "Error loading SavedSearch", criteria)
+	}
+
+	// Otherwise, create a new SavedSearch
+	if err := service.Save(&result, "Created by query"); err != nil {
+		return result, derp.Wrap(err, location, "Error saving SavedSearch", result)
+	}
+
+	return result, nil
+}
+
+// RangeMatches returns all SavedSearches that match the provided SearchResult
+func (service *SavedSearch) RangeMatches(searchResult *model.SearchResult) (iter.Seq[model.SavedSearch], error) {
+
+	const location = "service.SavedSearch.RangeMatches"
+
+	savedSearches, err := service.Range(exp.All())
+
+	if err != nil {
+		return nil, derp.Wrap(err, location, "Error loading SavedSearches")
+	}
+
+	// Tokenize the SearchResult once, then compare it to every SavedSearch
+	matchesText := service.searchService.TextMatcher(searchResult)
+
+	return func(yield func(model.SavedSearch) bool) {
+		for savedSearch := range savedSearches {
+
+			if !savedSearch.MatchesFilters(searchResult) {
+				continue
+			}
+
+			if !matchesText(savedSearch.Text) {
+				continue
+			}
+
+			if !yield(savedSearch) {
+				return
+			}
+		}
+	}, nil
+}
+
+// QueryResults returns the most recent SearchResults that match a SavedSearch
+func (service *SavedSearch) QueryResults(savedSearch *model.SavedSearch, maxRows int64) (sliceof.Object[model.SearchResult], error) {
+
+	resultSet, err := service.searchService.Search(savedSearch.Query(maxRows))
+
+	if err != nil {
+		return nil, derp.Wrap(err, "service.SavedSearch.QueryResults", "Error searching", savedSearch)
+	}
+
+	return resultSet.Results, nil
+}
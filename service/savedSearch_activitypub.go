@@ -0,0 +1,151 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/benpate/digit"
+	"github.com/benpate/domain"
+	"github.com/benpate/hannibal/outbox"
+	"github.com/benpate/hannibal/vocab"
+	"github.com/benpate/rosetta/list"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/******************************************
+ * ActivityPub API
+ ******************************************/
+
+// JSONLD returns the ActivityPub actor for a SavedSearch, including its public key.
+// This map will still need to be marshalled into JSON
+func (service *SavedSearch) JSONLD(savedSearch *model.SavedSearch) (mapof.Any, error) {
+
+	const location = "service.SavedSearch.JSONLD"
+
+	// Try to load the Encryption Key for this Actor
+	key := model.NewEncryptionKey()
+	if err := service.keyService.LoadByParentID(model.EncryptionKeyTypeSearch, savedSearch.SavedSearchID, &key); err != nil {
+		return mapof.NewAny(), derp.Wrap(err, location, "Error loading Public Key", savedSearch.SavedSearchID)
+	}
+
+	result := mapof.Any{
+		vocab.AtContext:                 sliceof.Any{vocab.ContextTypeActivityStreams, vocab.ContextTypeSecurity, vocab.ContextTypeToot},
+		vocab.PropertyID:                savedSearch.ActivityPubURL(),
+		vocab.PropertyType:              vocab.ActorTypeService,
+		vocab.PropertyURL:               savedSearch.FeedURL(),
+		vocab.PropertyPreferredUsername: savedSearch.Username(),
+		vocab.PropertyName:              savedSearch.Label(),
+		vocab.PropertyInbox:             savedSearch.ActivityPubInboxURL(),
+		vocab.PropertyOutbox:            savedSearch.ActivityPubOutboxURL(),
+		vocab.PropertyFollowers:         savedSearch.ActivityPubFollowersURL(),
+		vocab.PropertyTootDiscoverable:  false,
+		vocab.PropertyTootIndexable:     false,
+
+		vocab.PropertyPublicKey: mapof.Any{
+			vocab.PropertyID:           savedSearch.ActivityPubPublicKeyURL(),
+			vocab.PropertyOwner:        savedSearch.ActivityPubURL(),
+			vocab.PropertyPublicKeyPEM: key.PublicPEM,
+		},
+	}
+
+	if savedSearch.Summary != "" {
+		result[vocab.PropertySummary] = savedSearch.Summary
+	}
+
+	return result, nil
+}
+
+// ActivityPubActor returns an ActivityPub Actor object for the provided SavedSearch,
+// which can be used to send ActivityPub messages.
+func (service *SavedSearch) ActivityPubActor(savedSearch *model.SavedSearch, withFollowers bool) (outbox.Actor, error) {
+
+	const location = "service.SavedSearch.ActivityPubActor"
+
+	// Try to load the SavedSearch's keys from the database
+	encryptionKey := model.NewEncryptionKey()
+	if err := service.keyService.LoadByParentID(model.EncryptionKeyTypeSearch, savedSearch.SavedSearchID, &encryptionKey); err != nil {
+		return outbox.Actor{}, derp.Wrap(err, location, "Error loading encryption key", savedSearch.SavedSearchID)
+	}
+
+	// Extract the Private Key from the Encryption Key
+	privateKey, err := service.keyService.GetPrivateKey(&encryptionKey)
+
+	if err != nil {
+		return outbox.Actor{}, derp.Wrap(err, location, "Error extracting private key", encryptionKey)
+	}
+
+	// Deliveries are collapsed into each remote server's shared inbox, when available
+	client := NewSharedInboxClient(service.activityStream)
+
+	// Return the ActivityPub Actor
+	actor := outbox.NewActor(savedSearch.ActivityPubURL(), privateKey, outbox.WithClient(client))
+
+	// Populate the Actor's ActivityPub Followers, if requested
+	if withFollowers {
+
+		// Get a channel of all Followers
+		followers, err := service.followerService.ActivityPubFollowersChannel(model.FollowerTypeSearch, savedSearch.SavedSearchID)
+
+		if err != nil {
+			return outbox.Actor{}, derp.Wrap(err, location, "Error retrieving followers")
+		}
+
+		// Get a filter to prevent sending to "Blocked" followers
+		ruleFilter := service.ruleService.Filter(primitive.NilObjectID, WithBlocksOnly())
+		allowedFollowers := ruleFilter.ChannelSend(followers)
+		followerIDs := client.Followers(allowedFollowers)
+
+		// Add the channel of follower IDs to the Actor
+		actor.With(outbox.WithFollowers(followerIDs))
+	}
+
+	return actor, nil
+}
+
+/******************************************
+ * WebFinger Behavior
+ ******************************************/
+
+// LoadWebFinger returns a WebFinger resource for the SavedSearch identified by the token
+// (which looks like "acct:search-<id>@hostname")
+func (service *SavedSearch) LoadWebFinger(token string) (digit.Resource, error) {
+
+	const location = "service.SavedSearch.LoadWebFinger"
+
+	switch {
+
+	case domain.HasProtocol(token):
+		token = list.Last(token, '/')
+
+	case strings.HasPrefix(token, "acct:"):
+		token = strings.TrimPrefix(token, "acct:")
+		token = strings.TrimPrefix(token, "@")
+		token = strings.TrimSuffix(token, "@"+domain.NameOnly(service.host))
+
+		if !strings.HasPrefix(token, "search-") {
+			return digit.Resource{}, derp.NewBadRequestError(location, "Invalid token", token)
+		}
+
+		token = strings.TrimPrefix(token, "search-")
+
+	default:
+		return digit.Resource{}, derp.NewBadRequestError(location, "Invalid token", token)
+	}
+
+	// Try to load the SavedSearch from the database
+	savedSearch := model.NewSavedSearch()
+	if err := service.LoadByToken(token, &savedSearch); err != nil {
+		return digit.Resource{}, derp.Wrap(err, location, "Error loading SavedSearch", token)
+	}
+
+	// Make a WebFinger resource for this SavedSearch.
+	result := digit.NewResource("acct:"+savedSearch.Username()+"@"+domain.NameOnly(service.host)).
+		Alias(savedSearch.ActivityPubURL()).
+		Link(digit.RelationTypeSelf, model.MimeTypeActivityPub, savedSearch.ActivityPubURL()).
+		Link(digit.RelationTypeProfile, model.MimeTypeHTML, savedSearch.FeedURL())
+
+	return result, nil
+}
@@ -6,23 +6,33 @@ import (
 	"time"
 
 	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/queries"
+	"github.com/EmissarySocial/emissary/tools/fulltext"
 	"github.com/benpate/data"
 	"github.com/benpate/data/option"
 	"github.com/benpate/derp"
 	"github.com/benpate/exp"
 	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/turbine/queue"
 )
 
 // Search defines a service that manages all searchable pages in a domain.
 type Search struct {
 	collection       data.Collection
+	domainService    *Domain
 	searchTagService *SearchTag
+	activityStream   *ActivityStream
+	queue            *queue.Queue
+	engine           SearchEngine
 	host             string
+	closed           chan bool
 }
 
 // NewSearch returns a fully initialized Search service
 func NewSearch() Search {
-	return Search{}
+	return Search{
+		closed: make(chan bool),
+	}
 }
 
 /******************************************
@@ -30,15 +40,56 @@ func NewSearch() Search {
  ******************************************/
 
 // Refresh updates any stateful data that is cached inside this service.
-func (service *Search) Refresh(collection data.Collection, searchTagService *SearchTag, host string) {
+func (service *Search) Refresh(collection data.Collection, domainService *Domain, searchTagService *SearchTag, activityStream *ActivityStream, queue *queue.Queue, host string, engineName string) {
 	service.collection = collection
+	service.domainService = domainService
 	service.searchTagService = searchTagService
+	service.activityStream = activityStream
+	service.queue = queue
 	service.host = host
+
+	// (Re-)start the full-text search engine
+	service.closeEngine()
+	service.engine = NewSearchEngine(engineName, collection)
 }
 
 // Close stops any background processes controlled by this service
 func (service *Search) Close() {
-	// Nothin to do here.
+	service.closeEngine()
+	close(service.closed)
+}
+
+// closeEngine stops the current full-text search engine (if any)
+func (service *Search) closeEngine() {
+	if service.engine != nil {
+		service.engine.Close()
+	}
+}
+
+// Start begins the background scheduler that removes expired
+// remote SearchResults from the index
+func (service *Search) Start() {
+
+	const location = "service.Search.Start"
+
+	// Wait until the service has booted up correctly.
+	for service.collection == nil {
+		time.Sleep(1 * time.Minute)
+	}
+
+	for {
+
+		if err := service.PurgeExpired(); err != nil {
+			derp.Report(derp.Wrap(err, location, "Error purging expired search results"))
+		}
+
+		// Check for new work once per hour
+		select {
+		case <-service.closed:
+			return
+		case <-time.After(1 * time.Hour):
+		}
+	}
 }
 
 /******************************************
@@ -98,6 +149,8 @@ func (service *Search) Save(searchResult *model.SearchResult, note string) error
 		return derp.Wrap(err, "service.Search.Save", "Error saving Search", searchResult, note)
 	}
 
+	service.engine.Index(searchResult)
+
 	for _, tagName := range searchResult.TagNames {
 		if err := service.searchTagService.Upsert(tagName); err != nil {
 			return derp.Wrap(err, "service.Search.Save", "Error saving SearchTag", searchResult, tagName)
@@ -116,6 +169,8 @@ func (service *Search) Delete(searchResult *model.SearchResult, note string) err
 		return derp.Wrap(err, "service.Search.Delete", "Error deleting Search", searchResult, note)
 	}
 
+	service.engine.Remove(searchResult.SearchResultID)
+
 	return nil
 }
 
@@ -123,6 +178,64 @@ func (service *Search) Delete(searchResult *model.SearchResult, note string) err
  * Custom Queries
  ******************************************/
 
+// Search returns a page of SearchResults that match the query.  Text queries are ranked by
+// the configured SearchEngine, and include relevance scores and highlighted snippets.
+func (service *Search) Search(query model.SearchQuery) (model.SearchResultSet, error) {
+
+	const location = "service.Search.Search"
+
+	text := fulltext.ParseQuery(query.Text)
+
+	// Queries without any text to match are handled by the database directly
+	if text.IsEmpty() {
+
+		var err error
+		result := model.NewSearchResultSet()
+
+		if err = service.collection.Query(&result.Results, query.Criteria, searchOptions(query)...); err != nil {
+			return result, derp.Wrap(err, location, "Error querying SearchResults", query)
+		}
+
+		result.Results = searchSkip(result.Results, query.Offset)
+
+		if result.Total, err = service.collection.Count(query.Criteria); err != nil {
+			return result, derp.Wrap(err, location, "Error counting SearchResults", query)
+		}
+
+		if result.Facets, err = queries.SearchResults_Facets(service.collection, query.Criteria, query.Facets, searchFacetLimit); err != nil {
+			return result, derp.Wrap(err, location, "Error counting facets", query)
+		}
+
+		return result, nil
+	}
+
+	// Text queries are handled by the search engine
+	result, err := service.engine.Search(query, text)
+
+	if err != nil {
+		return result, derp.Wrap(err, location, "Error searching SearchResults", query)
+	}
+
+	// Add highlighted snippets for each field that matched
+	for index := range result.Results {
+
+		searchResult := &result.Results[index]
+		searchResult.Highlights = mapof.NewString()
+
+		for field, value := range map[string]string{
+			"name":     searchResult.Name,
+			"summary":  searchResult.Summary,
+			"fullText": searchResult.FullText,
+		} {
+			if highlight := fulltext.Highlight(value, text, 240); highlight != "" {
+				searchResult.Highlights[field] = highlight
+			}
+		}
+	}
+
+	return result, nil
+}
+
 func (service *Search) RangeByTags(tags ...string) (iter.Seq[model.SearchResult], error) {
 	return service.Range(exp.In("tags", tags))
 }
@@ -220,3 +333,24 @@ func (service *Search) UnmarshalMap(original map[string]any) model.SearchResult
 
 	return searchResult
 }
+
+// TextMatcher returns a function that reports whether a single SearchResult matches a text query.
+// The SearchResult is only tokenized once, so that new SearchResults can be evaluated against
+// many SavedSearches without querying (or rebuilding) the whole index.
+func (service *Search) TextMatcher(searchResult *model.SearchResult) func(text string) bool {
+
+	index := fulltext.NewIndex(searchIndexWeights())
+	index.Put(searchResult.SearchResultID.Hex(), searchIndexFields(searchResult))
+
+	return func(text string) bool {
+
+		query := fulltext.ParseQuery(text)
+
+		// Empty queries match everything
+		if query.IsEmpty() {
+			return true
+		}
+
+		return len(index.Search(query, 1)) > 0
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"github.com/EmissarySocial/emissary/config"
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/fulltext"
+	"github.com/benpate/data"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchEngine is a full-text index of SearchResults.  The SearchResult collection is
+// always the source of truth.  Each engine uses it to answer text queries with
+// relevance scores and facet counts.
+type SearchEngine interface {
+
+	// Index adds or updates a SearchResult in the full-text index
+	Index(searchResult *model.SearchResult)
+
+	// Remove removes a SearchResult from the full-text index
+	Remove(searchResultID primitive.ObjectID)
+
+	// Search returns the SearchResults that match a text query, with relevance scores and facet counts
+	Search(query model.SearchQuery, text fulltext.Query) (model.SearchResultSet, error)
+
+	// Close stops any background processes controlled by this engine
+	Close()
+}
+
+// searchFieldWeights defines the relative importance of each field in a text search
+var searchFieldWeights = map[string]int{
+	"name":     10,
+	"tagNames": 6,
+	"summary":  4,
+	"fullText": 1,
+}
+
+// searchIndexWeights returns the field weights used by in-memory full-text indexes
+func searchIndexWeights() map[string]float64 {
+
+	result := make(map[string]float64, len(searchFieldWeights))
+	for field, weight := range searchFieldWeights {
+		result[field] = float64(weight)
+	}
+
+	return result
+}
+
+// searchIndexFields returns the text of each indexed field in a SearchResult
+func searchIndexFields(searchResult *model.SearchResult) map[string]string {
+	return map[string]string{
+		"name":     searchResult.Name,
+		"tagNames": searchResult.TagNames.Join(" "),
+		"summary":  searchResult.Summary,
+		"fullText": searchResult.FullText,
+	}
+}
+
+// searchFacetLimit is the maximum number of values returned for each facet
+const searchFacetLimit = 50
+
+// NewSearchEngine returns the SearchEngine that matches the provided name.
+// Unrecognized names use the MongoDB text index.
+func NewSearchEngine(engineName string, collection data.Collection) SearchEngine {
+
+	switch engineName {
+
+	case config.SearchEngineEmbedded:
+		return NewSearchEngineEmbedded(collection)
+	}
+
+	return NewSearchEngineMongo(collection)
+}
+
+// searchFields returns the fields to load for each SearchResult in a text search
+func searchFields() []string {
+	return append(model.SearchResult{}.Fields(), "tagValues", "fullText", "rank")
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/fulltext"
+	"github.com/benpate/data"
+	"github.com/benpate/data/option"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// embeddedSearchBatch is the maximum number of text matches that are checked against
+// the database in a single query.  Every match is checked, so that totals and facets
+// are counted across all results, not just the current page.
+const embeddedSearchBatch = 1000
+
+// SearchEngineEmbedded keeps an in-memory, full-text index of all SearchResults.
+// It supports stemming, phrases, prefix matches, and per-field boosts.  The index
+// is rebuilt from the database in the background whenever the engine starts.
+type SearchEngineEmbedded struct {
+	collection data.Collection
+	index      *fulltext.Index
+	done       chan struct{}
+}
+
+// NewSearchEngineEmbedded returns a fully initialized SearchEngineEmbedded,
+// and loads all existing SearchResults into the index in the background.
+func NewSearchEngineEmbedded(collection data.Collection) SearchEngineEmbedded {
+
+	result := SearchEngineEmbedded{
+		collection: collection,
+		index:      fulltext.NewIndex(searchIndexWeights()),
+		done:       make(chan struct{}),
+	}
+
+	go result.load()
+
+	return result
+}
+
+// load adds every SearchResult in the database to the index
+func (engine SearchEngineEmbedded) load() {
+
+	const location = "service.SearchEngineEmbedded.load"
+
+	iterator, err := engine.collection.Iterator(exp.All())
+
+	if err != nil {
+		derp.Report(derp.Wrap(err, location, "Error listing SearchResults"))
+		return
+	}
+
+	defer iterator.Close()
+
+	searchResult := model.NewSearchResult()
+
+	for iterator.Next(&searchResult) {
+
+		select {
+		case <-engine.done:
+			return
+		default:
+		}
+
+		engine.Index(&searchResult)
+		searchResult = model.NewSearchResult()
+	}
+
+	log.Debug().Int("count", engine.index.Len()).Msg("Embedded search index loaded")
+}
+
+// Index adds or updates a SearchResult in the full-text index
+func (engine SearchEngineEmbedded) Index(searchResult *model.SearchResult) {
+	engine.index.Put(searchResult.SearchResultID.Hex(), searchIndexFields(searchResult))
+}
+
+// Remove removes a SearchResult from the full-text index
+func (engine SearchEngineEmbedded) Remove(searchResultID primitive.ObjectID) {
+	engine.index.Delete(searchResultID.Hex())
+}
+
+// Close stops loading the index (if it is still in progress)
+func (engine SearchEngineEmbedded) Close() {
+	close(engine.done)
+}
+
+// Search returns the SearchResults that match a text query, with relevance scores and facet counts
+func (engine SearchEngineEmbedded) Search(query model.SearchQuery, text fulltext.Query) (model.SearchResultSet, error) {
+
+	const location = "service.SearchEngineEmbedded.Search"
+
+	result := model.NewSearchResultSet()
+	matches := engine.index.Search(text, 0)
+
+	if len(matches) == 0 {
+		return result, nil
+	}
+
+	// Find all matches that also satisfy the (non-text) criteria
+	filtered, err := engine.filter(query.Criteria, matches)
+
+	if err != nil {
+		return result, derp.Wrap(err, location, "Error filtering SearchResults", query)
+	}
+
+	// Count totals and facets across all matches
+	result.Total = int64(len(filtered))
+
+	for _, field := range query.Facets {
+		result.Facets[field] = embeddedFacet(filtered, field)
+	}
+
+	// Sort by relevance, then load the full records for the current page
+	if query.IsRelevanceSort() {
+
+		sort.SliceStable(filtered, func(i int, j int) bool {
+			if filtered[i].Score == filtered[j].Score {
+				return filtered[i].Rank < filtered[j].Rank
+			}
+			return filtered[i].Score > filtered[j].Score
+		})
+
+		filtered = searchSkip(filtered, query.Offset)
+
+		if (query.MaxRows > 0) && (int64(len(filtered)) > query.MaxRows) {
+			filtered = filtered[:query.MaxRows]
+		}
+
+		if result.Results, err = engine.page(filtered); err != nil {
+			return result, derp.Wrap(err, location, "Error loading SearchResults", query)
+		}
+
+		return result, nil
+	}
+
+	// Otherwise, let the database sort by another field
+	scores := make(map[primitive.ObjectID]float64, len(filtered))
+	ids := make([]primitive.ObjectID, len(filtered))
+
+	for index, searchResult := range filtered {
+		scores[searchResult.SearchResultID] = searchResult.Score
+		ids[index] = searchResult.SearchResultID
+	}
+
+	if err := engine.collection.Query(&result.Results, exp.In("_id", ids), searchOptions(query)...); err != nil {
+		return result, derp.Wrap(err, location, "Error loading SearchResults", query)
+	}
+
+	result.Results = searchSkip(result.Results, query.Offset)
+
+	for index := range result.Results {
+		result.Results[index].Score = scores[result.Results[index].SearchResultID]
+	}
+
+	return result, nil
+}
+
+// filter returns the text matches that also satisfy the (non-text) criteria.  Only the
+// fields needed for sorting and facet counts are loaded.  Matches are checked in batches
+// so that each database query remains small.
+func (engine SearchEngineEmbedded) filter(criteria exp.Expression, matches []fulltext.Match) (sliceof.Object[model.SearchResult], error) {
+
+	const location = "service.SearchEngineEmbedded.filter"
+
+	result := make(sliceof.Object[model.SearchResult], 0, len(matches))
+	scores := make(map[primitive.ObjectID]float64, len(matches))
+	ids := make([]primitive.ObjectID, 0, len(matches))
+
+	for _, match := range matches {
+		if searchResultID, err := primitive.ObjectIDFromHex(match.ID); err == nil {
+			scores[searchResultID] = match.Score
+			ids = append(ids, searchResultID)
+		}
+	}
+
+	fields := option.Fields("_id", "type", "attributedTo", "tagNames", "tagValues", "rank")
+
+	for batch := range slices.Chunk(ids, embeddedSearchBatch) {
+
+		found := make(sliceof.Object[model.SearchResult], 0, len(batch))
+
+		if err := engine.collection.Query(&found, criteria.AndIn("_id", batch), fields); err != nil {
+			return nil, derp.Wrap(err, location, "Error loading SearchResults")
+		}
+
+		for index := range found {
+			found[index].Score = scores[found[index].SearchResultID]
+		}
+
+		result = append(result, found...)
+	}
+
+	return result, nil
+}
+
+// page loads the full records for a page of filtered SearchResults, keeping their order and scores
+func (engine SearchEngineEmbedded) page(filtered sliceof.Object[model.SearchResult]) (sliceof.Object[model.SearchResult], error) {
+
+	const location = "service.SearchEngineEmbedded.page"
+
+	if len(filtered) == 0 {
+		return make(sliceof.Object[model.SearchResult], 0), nil
+	}
+
+	ids := make([]primitive.ObjectID, len(filtered))
+
+	for index, searchResult := range filtered {
+		ids[index] = searchResult.SearchResultID
+	}
+
+	loaded := make(sliceof.Object[model.SearchResult], 0, len(filtered))
+
+	if err := engine.collection.Query(&loaded, exp.In("_id", ids), option.Fields(searchFields()...)); err != nil {
+		return nil, derp.Wrap(err, location, "Error loading SearchResults")
+	}
+
+	byID := make(map[primitive.ObjectID]model.SearchResult, len(loaded))
+
+	for _, searchResult := range loaded {
+		byID[searchResult.SearchResultID] = searchResult
+	}
+
+	result := make(sliceof.Object[model.SearchResult], 0, len(filtered))
+
+	for _, searchResult := range filtered {
+		if full, ok := byID[searchResult.SearchResultID]; ok {
+			full.Score = searchResult.Score
+			result = append(result, full)
+		}
+	}
+
+	return result, nil
+}
+
+// embeddedFacet counts the values of a single field across a set of SearchResults
+func embeddedFacet(searchResults []model.SearchResult, field string) sliceof.Object[model.SearchFacet] {
+
+	counts := make(map[string]int64)
+
+	for _, searchResult := range searchResults {
+
+		switch field {
+
+		case "type":
+			counts[searchResult.Type]++
+
+		case "attributedTo":
+			counts[searchResult.AttributedTo]++
+
+		case "tagNames":
+			for _, value := range searchResult.TagNames {
+				counts[value]++
+			}
+
+		case "tagValues":
+			for _, value := range searchResult.TagValues {
+				counts[value]++
+			}
+		}
+	}
+
+	result := make(sliceof.Object[model.SearchFacet], 0, len(counts))
+
+	for value, count := range counts {
+		result = append(result, model.SearchFacet{Value: value, Count: count})
+	}
+
+	sort.Slice(result, func(i int, j int) bool {
+		if result[i].Count == result[j].Count {
+			return result[i].Value < result[j].Value
+		}
+		return result[i].Count > result[j].Count
+	})
+
+	if len(result) > searchFacetLimit {
+		result = result[:searchFacetLimit]
+	}
+
+	return result
+}
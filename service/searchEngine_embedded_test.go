@@ -0,0 +1,108 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/fulltext"
+	"github.com/benpate/exp"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// newTestSearchEngineEmbedded returns an embedded search engine containing (count) SearchResults
+// that all match the word "apple".  Ranks are sequential, and types alternate between Article and Note.
+func newTestSearchEngineEmbedded(t *testing.T, count int) SearchEngineEmbedded {
+
+	collection := newTestCollection()
+
+	engine := SearchEngineEmbedded{
+		collection: collection,
+		index:      fulltext.NewIndex(searchIndexWeights()),
+		done:       make(chan struct{}),
+	}
+
+	for index := range count {
+
+		searchResult := model.NewSearchResult()
+		searchResult.Name = "apple " + strconv.Itoa(index)
+		searchResult.Rank = int64(index)
+		searchResult.Type = "Article"
+
+		if index%2 == 1 {
+			searchResult.Type = "Note"
+		}
+
+		record, err := bson.Marshal(searchResult)
+		require.Nil(t, err)
+
+		*collection.records = append(*collection.records, record)
+		engine.Index(&searchResult)
+	}
+
+	return engine
+}
+
+func TestSearchEngineEmbedded_NoLimit(t *testing.T) {
+
+	engine := newTestSearchEngineEmbedded(t, 2500)
+
+	query := model.SearchQuery{
+		Text:     "apple",
+		Criteria: exp.All(),
+		Facets:   []string{"type"},
+		MaxRows:  10,
+	}
+
+	result, err := engine.Search(query, fulltext.ParseQuery(query.Text))
+	require.Nil(t, err)
+
+	// Totals and facets include every match, not just the first batch
+	require.Equal(t, int64(2500), result.Total)
+	require.Len(t, result.Results, 10)
+	require.Equal(t, int64(1250), result.Facet("type")[0].Count)
+	require.Equal(t, int64(1250), result.Facet("type")[1].Count)
+}
+
+func TestSearchEngineEmbedded_Offset(t *testing.T) {
+
+	engine := newTestSearchEngineEmbedded(t, 1200)
+
+	search := func(offset int64, maxRows int64) model.SearchResultSet {
+		query := model.SearchQuery{
+			Text:     "apple",
+			Criteria: exp.Equal("type", "Note"),
+			Offset:   offset,
+			MaxRows:  maxRows,
+		}
+
+		result, err := engine.Search(query, fulltext.ParseQuery(query.Text))
+		require.Nil(t, err)
+		return result
+	}
+
+	// First page
+	result := search(0, 10)
+	require.Equal(t, int64(600), result.Total)
+	require.Len(t, result.Results, 10)
+	require.Equal(t, int64(1), result.Results[0].Rank)
+	require.NotZero(t, result.Results[0].Score)
+	require.Equal(t, "apple 1", result.Results[0].Name)
+
+	// Second page continues where the first page ended
+	result = search(10, 10)
+	require.Len(t, result.Results, 10)
+	require.Equal(t, int64(21), result.Results[0].Rank)
+
+	// Last page (beyond the old 1000 match limit) is partially filled
+	result = search(595, 10)
+	require.Equal(t, int64(600), result.Total)
+	require.Len(t, result.Results, 5)
+	require.Equal(t, int64(1191), result.Results[0].Rank)
+
+	// Pages after the last result are empty
+	result = search(600, 10)
+	require.Equal(t, int64(600), result.Total)
+	require.Empty(t, result.Results)
+}
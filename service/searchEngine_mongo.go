@@ -0,0 +1,172 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/queries"
+	"github.com/EmissarySocial/emissary/tools/fulltext"
+	"github.com/benpate/data"
+	"github.com/benpate/data/option"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+	"github.com/benpate/rosetta/sliceof"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchEngineMongo uses a weighted MongoDB text index to search SearchResults.
+// MongoDB handles stemming, phrases, and exclusions natively.  Prefix terms are
+// matched with regular expressions, because text indexes only match whole words.
+type SearchEngineMongo struct {
+	collection data.Collection
+}
+
+// NewSearchEngineMongo returns a fully initialized SearchEngineMongo,
+// and creates the text index in the background.
+func NewSearchEngineMongo(collection data.Collection) SearchEngineMongo {
+
+	go func() {
+		if err := queries.SearchResults_EnsureTextIndex(collection, searchFieldWeights); err != nil {
+			derp.Report(derp.Wrap(err, "service.NewSearchEngineMongo", "Error creating text index"))
+		}
+	}()
+
+	return SearchEngineMongo{
+		collection: collection,
+	}
+}
+
+// Index is a no-op, because MongoDB updates its text index automatically
+func (engine SearchEngineMongo) Index(_ *model.SearchResult) {}
+
+// Remove is a no-op, because MongoDB updates its text index automatically
+func (engine SearchEngineMongo) Remove(_ primitive.ObjectID) {}
+
+// Close is a no-op, because there are no background processes to stop
+func (engine SearchEngineMongo) Close() {}
+
+// Search returns the SearchResults that match a text query, with relevance scores and facet counts
+func (engine SearchEngineMongo) Search(query model.SearchQuery, text fulltext.Query) (model.SearchResultSet, error) {
+
+	const location = "service.SearchEngineMongo.Search"
+
+	result := model.NewSearchResultSet()
+	criteria := query.Criteria
+	search := engine.textSearch(text)
+
+	if search != "" {
+		criteria = criteria.AndEqual("$fullText", search)
+	}
+
+	// Every prefix must begin a word in at least one field
+	for _, term := range text.Prefixes() {
+		pattern := `\b` + regexp.QuoteMeta(term.Text)
+		criteria = criteria.And(exp.Or(
+			exp.Contains("name", pattern),
+			exp.Contains("tagNames", pattern),
+			exp.Contains("summary", pattern),
+		))
+	}
+
+	// Read results, using the text score if available
+	var err error
+
+	if (search != "") && query.IsRelevanceSort() {
+		result.Results, err = queries.SearchResults_ByScore(engine.collection, criteria, searchFields(), query.Offset, query.MaxRows)
+	} else {
+		err = engine.collection.Query(&result.Results, criteria, searchOptions(query)...)
+		result.Results = searchSkip(result.Results, query.Offset)
+	}
+
+	if err != nil {
+		return result, derp.Wrap(err, location, "Error querying SearchResults", query)
+	}
+
+	// Count all matches
+	if result.Total, err = engine.collection.Count(criteria); err != nil {
+		return result, derp.Wrap(err, location, "Error counting SearchResults", query)
+	}
+
+	// Count facets
+	if result.Facets, err = queries.SearchResults_Facets(engine.collection, criteria, query.Facets, searchFacetLimit); err != nil {
+		return result, derp.Wrap(err, location, "Error counting facets", query)
+	}
+
+	return result, nil
+}
+
+// textSearch converts a parsed query into MongoDB $text syntax.  MongoDB matches any of the
+// plain words, requires all "phrases", and removes -excluded words.
+func (engine SearchEngineMongo) textSearch(text fulltext.Query) string {
+
+	words := make([]string, 0, len(text.Terms))
+
+	for _, term := range text.Terms {
+
+		if term.Prefix {
+			continue
+		}
+
+		value := strings.ReplaceAll(term.Text, `"`, "")
+
+		if term.Phrase {
+			value = `"` + value + `"`
+		}
+
+		if term.Exclude {
+			value = "-" + value
+		}
+
+		words = append(words, value)
+	}
+
+	// Exclusions alone do not match anything in MongoDB
+	if len(text.Words())+len(text.Phrases()) == 0 {
+		return ""
+	}
+
+	return strings.Join(words, " ")
+}
+
+// searchOptions returns the query options for a SearchQuery that is not sorted by relevance.
+// The data layer cannot skip rows, so skipped rows are also read, then removed by searchSkip.
+func searchOptions(query model.SearchQuery) []option.Option {
+
+	sortField := query.SortField
+
+	if (sortField == "") || (sortField == "score") {
+		sortField = "rank"
+	}
+
+	result := []option.Option{
+		option.Fields(searchFields()...),
+		option.CaseSensitive(false),
+	}
+
+	if query.SortDirection == option.SortDirectionDescending {
+		result = append(result, option.SortDesc(sortField))
+	} else {
+		result = append(result, option.SortAsc(sortField))
+	}
+
+	if query.MaxRows > 0 {
+		result = append(result, option.MaxRows(query.Offset+query.MaxRows))
+	}
+
+	return result
+}
+
+// searchSkip removes the first (offset) results that were read by searchOptions
+func searchSkip(searchResults sliceof.Object[model.SearchResult], offset int64) sliceof.Object[model.SearchResult] {
+
+	if offset <= 0 {
+		return searchResults
+	}
+
+	if offset >= int64(len(searchResults)) {
+		return make(sliceof.Object[model.SearchResult], 0)
+	}
+
+	return searchResults[offset:]
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+	"github.com/benpate/hannibal/streams"
+	"github.com/benpate/hannibal/vocab"
+	"github.com/benpate/rosetta/first"
+	"github.com/benpate/rosetta/html"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/benpate/turbine/queue"
+)
+
+// searchRetentionDefault is the number of days that remote SearchResults are kept
+// when the Domain does not set its own retention period
+const searchRetentionDefault = 30
+
+// searchNoIndexHints are the hashtags that people use to opt out of being indexed
+var searchNoIndexHints = []string{"#nobot", "#noindex"}
+
+/******************************************
+ * Federated Search
+ ******************************************/
+
+// ReceiveActivity inspects an ActivityPub activity that was received by this server.  If this
+// domain has opted in to federated search, then the activity's object is queued to be added to
+// (or removed from) the search index.
+func (service *Search) ReceiveActivity(activity streams.Document) {
+
+	const location = "service.Search.ReceiveActivity"
+
+	// RULE: Federated search must be enabled for this domain
+	if domain := service.domainService.Get(); !domain.FederatedSearch {
+		return
+	}
+
+	objectID := activity.Object().ID()
+
+	if objectID == "" {
+		return
+	}
+
+	switch activity.Type() {
+
+	// New and updated documents are indexed in the background
+	case vocab.ActivityTypeCreate,
+		vocab.ActivityTypeUpdate,
+		vocab.ActivityTypeAnnounce:
+
+		task := queue.NewTask("IndexRemoteDocument", mapof.Any{
+			"url": objectID,
+		})
+
+		if err := service.queue.Publish(task); err != nil {
+			derp.Report(derp.Wrap(err, location, "Error publishing task", objectID))
+		}
+
+	// Deleted documents are removed immediately
+	case vocab.ActivityTypeDelete:
+
+		// RULE: Actors can only delete documents from their own server
+		if !isSameHost(activity.Actor().ID(), objectID) {
+			return
+		}
+
+		if err := service.DeleteRemoteByURL(objectID); err != nil {
+			derp.Report(derp.Wrap(err, location, "Error removing remote document", objectID))
+		}
+	}
+}
+
+// RemoteSearchResult loads a document from another server and converts it into a SearchResult.
+// It returns FALSE if the document is not public, or if its author has not opted in to being indexed.
+func (service *Search) RemoteSearchResult(documentURL string) (model.SearchResult, bool, error) {
+
+	const location = "service.Search.RemoteSearchResult"
+
+	// RULE: Local documents are indexed separately
+	if strings.HasPrefix(documentURL, service.host+"/") {
+		return model.SearchResult{}, false, nil
+	}
+
+	// Load the original document from its server
+	document, err := service.activityStream.Load(documentURL)
+
+	if err != nil {
+		return model.SearchResult{}, false, derp.Wrap(err, location, "Error loading document", documentURL)
+	}
+
+	// Actors are indexed if they are "discoverable"
+	if document.IsActor() {
+
+		if !isDiscoverable(document) {
+			return model.SearchResult{}, false, nil
+		}
+
+		return service.remoteActorSearchResult(document), true, nil
+	}
+
+	// RULE: Only index the kinds of documents that people search for
+	switch document.Type() {
+
+	case vocab.ObjectTypeArticle,
+		vocab.ObjectTypeAudio,
+		vocab.ObjectTypeEvent,
+		vocab.ObjectTypeImage,
+		vocab.ObjectTypeNote,
+		vocab.ObjectTypePage,
+		vocab.ObjectTypeVideo:
+
+	default:
+		return model.SearchResult{}, false, nil
+	}
+
+	// RULE: Only index public documents
+	if !isPublic(document) {
+		return model.SearchResult{}, false, nil
+	}
+
+	// RULE: Documents are indexed only if their author allows it
+	author, err := document.AttributedTo().Load()
+
+	if err != nil {
+		return model.SearchResult{}, false, derp.Wrap(err, location, "Error loading author", documentURL)
+	}
+
+	if !isIndexable(author) {
+		return model.SearchResult{}, false, nil
+	}
+
+	return service.remoteObjectSearchResult(document, author), true, nil
+}
+
+// DeleteRemoteByURL removes a remote SearchResult from the index.  Local SearchResults are never
+// removed by this method.
+func (service *Search) DeleteRemoteByURL(documentURL string) error {
+
+	searchResult := model.NewSearchResult()
+
+	if err := service.Load(exp.Equal("url", documentURL).AndEqual("remote", true), &searchResult); err != nil {
+
+		if derp.NotFound(err) {
+			return nil
+		}
+
+		return derp.Wrap(err, "service.Search.DeleteRemoteByURL", "Error loading SearchResult", documentURL)
+	}
+
+	return service.Delete(&searchResult, "deleted by remote server")
+}
+
+// PurgeExpired removes all remote SearchResults whose retention period has passed
+func (service *Search) PurgeExpired() error {
+
+	const location = "service.Search.PurgeExpired"
+
+	criteria := exp.Equal("remote", true).
+		AndGreaterThan("expireDate", 0).
+		AndLessThan("expireDate", time.Now().Unix())
+
+	searchResults, err := service.Range(criteria)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error listing expired SearchResults")
+	}
+
+	for searchResult := range searchResults {
+		if err := service.Delete(&searchResult, "expired"); err != nil {
+			return derp.Wrap(err, location, "Error deleting SearchResult", searchResult.URL)
+		}
+	}
+
+	return nil
+}
+
+// SubscribeRelay adds an ActivityPub relay to this domain, and sends a "Follow" request from
+// the domain's service actor.  Relays deliver public posts to the service actor's inbox.
+func (service *Search) SubscribeRelay(inboxURL string) error {
+
+	const location = "service.Search.SubscribeRelay"
+
+	domain := service.domainService.Get()
+
+	if domain.SearchRelays.Contains(inboxURL) {
+		return nil
+	}
+
+	domain.SearchRelays = append(domain.SearchRelays, inboxURL)
+
+	if err := service.domainService.Save(domain, "Subscribed to relay"); err != nil {
+		return derp.Wrap(err, location, "Error saving Domain", inboxURL)
+	}
+
+	if err := service.sendRelayMessage(inboxURL, service.relayFollow(inboxURL)); err != nil {
+		return derp.Wrap(err, location, "Error sending Follow request", inboxURL)
+	}
+
+	return nil
+}
+
+// UnsubscribeRelay removes an ActivityPub relay from this domain, and sends an "Undo"
+// request from the domain's service actor.
+func (service *Search) UnsubscribeRelay(inboxURL string) error {
+
+	const location = "service.Search.UnsubscribeRelay"
+
+	domain := service.domainService.Get()
+
+	if !domain.SearchRelays.Contains(inboxURL) {
+		return nil
+	}
+
+	domain.SearchRelays = slices.DeleteFunc(domain.SearchRelays, func(value string) bool {
+		return value == inboxURL
+	})
+
+	if err := service.domainService.Save(domain, "Unsubscribed from relay"); err != nil {
+		return derp.Wrap(err, location, "Error saving Domain", inboxURL)
+	}
+
+	follow := service.relayFollow(inboxURL)
+
+	undo := mapof.Any{
+		vocab.AtContext:      vocab.ContextTypeActivityStreams,
+		vocab.PropertyID:     follow.GetString(vocab.PropertyID) + "/undo",
+		vocab.PropertyType:   vocab.ActivityTypeUndo,
+		vocab.PropertyActor:  service.domainService.ActorID(),
+		vocab.PropertyObject: follow,
+	}
+
+	if err := service.sendRelayMessage(inboxURL, undo); err != nil {
+		return derp.Wrap(err, location, "Error sending Undo request", inboxURL)
+	}
+
+	return nil
+}
+
+// relayFollow returns the "Follow" activity that subscribes the service actor to a relay
+func (service *Search) relayFollow(inboxURL string) mapof.Any {
+
+	actorID := service.domainService.ActorID()
+
+	return mapof.Any{
+		vocab.AtContext:      vocab.ContextTypeActivityStreams,
+		vocab.PropertyID:     actorID + "/following/" + url.PathEscape(inboxURL),
+		vocab.PropertyType:   vocab.ActivityTypeFollow,
+		vocab.PropertyActor:  actorID,
+		vocab.PropertyObject: vocab.NamespaceActivityStreamsPublic,
+	}
+}
+
+// sendRelayMessage queues an ActivityPub message from the service actor to a relay
+func (service *Search) sendRelayMessage(inboxURL string, message mapof.Any) error {
+
+	task := queue.NewTask("SendActivityPubMessage", mapof.Any{
+		"actorType": model.FollowerTypeSearch,
+		"inboxURL":  inboxURL,
+		"message":   message,
+	})
+
+	if err := service.queue.Publish(task); err != nil {
+		return derp.Wrap(err, "service.Search.sendRelayMessage", "Error publishing task", inboxURL)
+	}
+
+	return nil
+}
+
+// remoteActorSearchResult converts a remote Actor into a SearchResult
+func (service *Search) remoteActorSearchResult(actor streams.Document) model.SearchResult {
+
+	summary := html.ToSearchText(actor.Summary())
+
+	result := service.remoteSearchResult(actor)
+	result.Name = first.String(actor.Name(), actor.PreferredUsername())
+	result.AttributedTo = actor.UsernameOrID()
+	result.Summary = html.Summary(actor.Summary())
+	result.IconURL = actor.Icon().URL()
+	result.FullText = strings.Join([]string{actor.Name(), actor.UsernameOrID(), summary}, ", ")
+
+	return result
+}
+
+// remoteObjectSearchResult converts a remote Object (Note, Article, etc) into a SearchResult
+func (service *Search) remoteObjectSearchResult(object streams.Document, author streams.Document) model.SearchResult {
+
+	result := service.remoteSearchResult(object)
+	result.Name = object.Name()
+	result.AttributedTo = first.String(author.Name(), author.UsernameOrID())
+	result.Summary = html.Summary(first.String(object.Summary(), object.Content()))
+	result.IconURL = first.String(object.IconOrImage().URL(), author.Icon().URL())
+	result.FullText = html.ToSearchText(object.Name() + " " + object.Summary() + " " + object.Content())
+
+	return result
+}
+
+// remoteSearchResult returns a SearchResult with the values that are common to all remote documents
+func (service *Search) remoteSearchResult(document streams.Document) model.SearchResult {
+
+	const location = "service.Search.remoteSearchResult"
+
+	result := model.NewSearchResult()
+	result.Type = document.Type()
+	result.URL = document.ID()
+	result.Remote = true
+	result.ExpireDate = time.Now().AddDate(0, 0, service.retentionDays()).Unix()
+
+	// Collect #hashtags from the document
+	hashtags := sliceof.NewString()
+
+	for _, tag := range document.Tag().SliceOfDocuments() {
+		if tag.Type() == vocab.LinkTypeHashtag {
+			hashtags = append(hashtags, strings.TrimPrefix(tag.Name(), "#"))
+		}
+	}
+
+	tagNames, tagValues, err := service.searchTagService.NormalizeTags(hashtags...)
+	derp.Report(derp.Wrap(err, location, "Error normalizing tags", hashtags))
+
+	result.TagNames = tagNames
+	result.TagValues = tagValues
+
+	return result
+}
+
+// retentionDays returns the number of days that remote SearchResults are kept in the index
+func (service *Search) retentionDays() int {
+
+	if domain := service.domainService.Get(); domain.SearchRetention > 0 {
+		return domain.SearchRetention
+	}
+
+	return searchRetentionDefault
+}
+
+/******************************************
+ * Helper Functions
+ ******************************************/
+
+// isPublic returns TRUE if a document is addressed to the public collection
+func isPublic(document streams.Document) bool {
+
+	recipients := append(document.To().SliceOfDocuments(), document.CC().SliceOfDocuments()...)
+
+	for _, recipient := range recipients {
+		switch recipient.ID() {
+		case vocab.NamespaceActivityStreamsPublic, "as:Public", "Public":
+			return true
+		}
+	}
+
+	return false
+}
+
+// isIndexable returns TRUE if an Actor allows its posts to be indexed by search engines.
+// Actors that do not publish an "indexable" flag fall back to their "discoverable" flag.
+func isIndexable(actor streams.Document) bool {
+
+	if hasNoIndexHint(actor) {
+		return false
+	}
+
+	if indexable, exists := actor.Map()[vocab.PropertyTootIndexable].(bool); exists {
+		return indexable
+	}
+
+	return actor.Get(vocab.PropertyTootDiscoverable).Bool()
+}
+
+// isDiscoverable returns TRUE if an Actor allows itself to be listed in directories and search results
+func isDiscoverable(actor streams.Document) bool {
+
+	if hasNoIndexHint(actor) {
+		return false
+	}
+
+	return actor.Get(vocab.PropertyTootDiscoverable).Bool()
+}
+
+// hasNoIndexHint returns TRUE if an Actor has asked not to be indexed, either with
+// a "noindex" property, or with a #nobot or #noindex hashtag in their profile.
+func hasNoIndexHint(actor streams.Document) bool {
+
+	if actor.Get("noindex").Bool() {
+		return true
+	}
+
+	summary := strings.ToLower(html.ToSearchText(actor.Summary()))
+
+	for _, hint := range searchNoIndexHints {
+		if strings.Contains(summary, hint) {
+			return true
+		}
+	}
+
+	for _, tag := range actor.Tag().SliceOfDocuments() {
+		if slices.Contains(searchNoIndexHints, strings.ToLower(tag.Name())) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSameHost returns TRUE if two URLs are on the same host
+func isSameHost(left string, right string) bool {
+
+	leftURL, err := url.Parse(left)
+
+	if err != nil {
+		return false
+	}
+
+	rightURL, err := url.Parse(right)
+
+	if err != nil {
+		return false
+	}
+
+	return (leftURL.Host != "") && (leftURL.Host == rightURL.Host)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/benpate/hannibal/streams"
+	"github.com/benpate/hannibal/vocab"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchFederated_IsPublic(t *testing.T) {
+
+	public := streams.NewDocument(map[string]any{
+		vocab.PropertyTo: []any{"https://example.com/@alice/followers"},
+		vocab.PropertyCC: []any{vocab.NamespaceActivityStreamsPublic},
+	})
+
+	require.True(t, isPublic(public))
+
+	private := streams.NewDocument(map[string]any{
+		vocab.PropertyTo: []any{"https://example.com/@alice/followers"},
+	})
+
+	require.False(t, isPublic(private))
+}
+
+func TestSearchFederated_IsIndexable(t *testing.T) {
+
+	// Explicit "indexable" flags are used first
+	require.True(t, isIndexable(streams.NewDocument(map[string]any{
+		vocab.PropertyTootIndexable:    true,
+		vocab.PropertyTootDiscoverable: false,
+	})))
+
+	require.False(t, isIndexable(streams.NewDocument(map[string]any{
+		vocab.PropertyTootIndexable:    false,
+		vocab.PropertyTootDiscoverable: true,
+	})))
+
+	// Fall back to "discoverable" when "indexable" is missing
+	require.True(t, isIndexable(streams.NewDocument(map[string]any{
+		vocab.PropertyTootDiscoverable: true,
+	})))
+
+	// Missing flags mean "not indexable"
+	require.False(t, isIndexable(streams.NewDocument(map[string]any{})))
+}
+
+func TestSearchFederated_NoIndexHints(t *testing.T) {
+
+	require.False(t, isIndexable(streams.NewDocument(map[string]any{
+		vocab.PropertyTootIndexable: true,
+		"noindex":                   true,
+	})))
+
+	require.False(t, isDiscoverable(streams.NewDocument(map[string]any{
+		vocab.PropertyTootDiscoverable: true,
+		vocab.PropertySummary:          "<p>Just here to chat. #NoBot</p>",
+	})))
+
+	require.False(t, isDiscoverable(streams.NewDocument(map[string]any{
+		vocab.PropertyTootDiscoverable: true,
+		vocab.PropertyTag: []any{
+			map[string]any{vocab.PropertyType: vocab.LinkTypeHashtag, vocab.PropertyName: "#noindex"},
+		},
+	})))
+
+	require.True(t, isDiscoverable(streams.NewDocument(map[string]any{
+		vocab.PropertyTootDiscoverable: true,
+		vocab.PropertySummary:          "<p>Baking bread every day</p>",
+	})))
+}
+
+func TestSearchFederated_IsSameHost(t *testing.T) {
+	require.True(t, isSameHost("https://example.com/@alice", "https://example.com/notes/1"))
+	require.False(t, isSameHost("https://example.com/@alice", "https://other.com/notes/1"))
+	require.False(t, isSameHost("", ""))
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchTextMatcher(t *testing.T) {
+
+	searchResult := model.NewSearchResult()
+	searchResult.Name = "Growing tomatoes on a balcony"
+	searchResult.Summary = "Container gardening for small spaces"
+
+	matches := (&Search{}).TextMatcher(&searchResult)
+
+	// The same SearchResult can be compared to many queries
+	require.True(t, matches(""))
+	require.True(t, matches("tomatoes"))
+	require.True(t, matches("balcony gardening"))
+	require.False(t, matches("potatoes"))
+	require.False(t, matches("tomatoes -balcony"))
+}
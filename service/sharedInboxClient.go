@@ -0,0 +1,101 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/hannibal/streams"
+	"github.com/benpate/hannibal/vocab"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/rs/zerolog/log"
+)
+
+// SharedInboxClient is a hannibal Client that collapses ActivityPub deliveries
+// so that each remote server's shared inbox receives a single copy of a message,
+// instead of one copy for every Follower on that server.  It works by filtering
+// the channel of Followers sent to an outbox.Actor, then substituting the shared
+// inbox whenever the Actor looks up a Follower's inbox URL.
+// SharedInboxClients are created for a single delivery, and should not be reused.
+type SharedInboxClient struct {
+	innerClient streams.Client
+	inboxes     map[string]string   // map of Follower ProfileURLs => shared inbox URLs
+	scheduled   map[string]struct{} // set of shared inbox URLs that are already scheduled for delivery
+	mutex       sync.RWMutex
+}
+
+// NewSharedInboxClient returns a fully initialized SharedInboxClient that wraps the provided Client.
+func NewSharedInboxClient(innerClient streams.Client) *SharedInboxClient {
+	return &SharedInboxClient{
+		innerClient: innerClient,
+		inboxes:     make(map[string]string),
+		scheduled:   make(map[string]struct{}),
+	}
+}
+
+// Load implements the hannibal Client interface.  Followers that will receive
+// messages via a shared inbox are returned as stub documents (which saves a
+// round-trip to the remote server).  All other requests are passed to the inner client.
+func (client *SharedInboxClient) Load(uri string, options ...any) (streams.Document, error) {
+
+	client.mutex.RLock()
+	sharedInbox, ok := client.inboxes[uri]
+	client.mutex.RUnlock()
+
+	if ok {
+		return streams.NewDocument(mapof.Any{
+			vocab.PropertyID:    uri,
+			vocab.PropertyInbox: sharedInbox,
+		}, streams.WithClient(client)), nil
+	}
+
+	return client.innerClient.Load(uri, options...)
+}
+
+// Followers filters a channel of Followers, returning the ProfileURL of every Follower who does not
+// publish a shared inbox, plus a single representative Follower for each distinct shared inbox.
+func (client *SharedInboxClient) Followers(followers <-chan model.Follower) <-chan string {
+
+	result := make(chan string)
+
+	go func() {
+
+		defer close(result)
+
+		for follower := range followers {
+
+			sharedInbox := follower.Actor.SharedInbox
+
+			// Followers without a shared inbox receive messages directly
+			if sharedInbox == "" {
+				result <- follower.Actor.ProfileURL
+				continue
+			}
+
+			// Skip Followers whose shared inbox has already been scheduled for delivery
+			if !client.schedule(follower.Actor.ProfileURL, sharedInbox) {
+				log.Trace().Str("loc", "service.SharedInboxClient.Followers").Str("sharedInbox", sharedInbox).Msg("Skipping duplicate shared inbox")
+				continue
+			}
+
+			result <- follower.Actor.ProfileURL
+		}
+	}()
+
+	return result
+}
+
+// schedule assigns a shared inbox to the provided Follower, returning FALSE
+// if the shared inbox has already been assigned to another Follower.
+func (client *SharedInboxClient) schedule(profileURL string, sharedInbox string) bool {
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if _, exists := client.scheduled[sharedInbox]; exists {
+		return false
+	}
+
+	client.scheduled[sharedInbox] = struct{}{}
+	client.inboxes[profileURL] = sharedInbox
+	return true
+}
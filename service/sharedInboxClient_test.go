@@ -0,0 +1,77 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/hannibal/streams"
+	"github.com/benpate/hannibal/vocab"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/stretchr/testify/require"
+)
+
+// testInnerClient records every URI that is passed through to the remote server
+type testInnerClient struct {
+	loaded []string
+}
+
+func (client *testInnerClient) Load(uri string, _ ...any) (streams.Document, error) {
+	client.loaded = append(client.loaded, uri)
+	return streams.NewDocument(mapof.Any{
+		vocab.PropertyID:    uri,
+		vocab.PropertyInbox: uri + "/inbox",
+	}), nil
+}
+
+func TestSharedInboxClient_Followers(t *testing.T) {
+
+	innerClient := &testInnerClient{}
+	client := NewSharedInboxClient(innerClient)
+
+	followers := make(chan model.Follower)
+
+	go func() {
+		defer close(followers)
+		for _, actor := range []model.PersonLink{
+			{ProfileURL: "https://one.example/users/alice", SharedInbox: "https://one.example/inbox"},
+			{ProfileURL: "https://one.example/users/bob", SharedInbox: "https://one.example/inbox"},
+			{ProfileURL: "https://two.example/users/carol", SharedInbox: "https://two.example/inbox"},
+			{ProfileURL: "https://three.example/users/dave"},
+			{ProfileURL: "https://three.example/users/erin"},
+			{ProfileURL: "https://one.example/users/frank", SharedInbox: "https://one.example/inbox"},
+		} {
+			follower := model.NewFollower()
+			follower.Actor = actor
+			followers <- follower
+		}
+	}()
+
+	result := make([]string, 0)
+	for profileURL := range client.Followers(followers) {
+		result = append(result, profileURL)
+	}
+
+	// One Follower per shared inbox, plus every Follower without a shared inbox
+	require.Equal(t, []string{
+		"https://one.example/users/alice",
+		"https://two.example/users/carol",
+		"https://three.example/users/dave",
+		"https://three.example/users/erin",
+	}, result)
+
+	// Representative Followers are delivered to their shared inbox, without a round-trip
+	alice, err := client.Load("https://one.example/users/alice")
+	require.Nil(t, err)
+	require.Equal(t, "https://one.example/inbox", alice.Inbox().ID())
+
+	carol, err := client.Load("https://two.example/users/carol")
+	require.Nil(t, err)
+	require.Equal(t, "https://two.example/inbox", carol.Inbox().ID())
+	require.Empty(t, innerClient.loaded)
+
+	// Followers without a shared inbox are loaded from their own server
+	dave, err := client.Load("https://three.example/users/dave")
+	require.Nil(t, err)
+	require.Equal(t, "https://three.example/users/dave/inbox", dave.Inbox().ID())
+	require.Equal(t, []string{"https://three.example/users/dave"}, innerClient.loaded)
+}
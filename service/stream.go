@@ -534,6 +534,38 @@ func (service *Stream) LoadByURL(streamURL string, result *model.Stream) error {
 	return service.LoadByToken(token, result)
 }
 
+// LoadDeletedByURL returns a single soft-deleted `Stream` that matches the provided URL
+func (service *Stream) LoadDeletedByURL(streamURL string, result *model.Stream) error {
+
+	const location = "service.Stream.LoadDeletedByURL"
+
+	// Verify we have a valid URL
+	uri, err := url.Parse(streamURL)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid URL", streamURL)
+	}
+
+	// Retrieve the Token from the request path
+	token, _, err := service.ParsePath(uri)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid URL", streamURL)
+	}
+
+	criteria := exp.Equal("token", token).AndGreaterThan("deleteDate", 0)
+
+	if streamID, err := primitive.ObjectIDFromHex(token); err == nil {
+		criteria = exp.Equal("_id", streamID).AndGreaterThan("deleteDate", 0)
+	}
+
+	if err := service.collection.Load(criteria, result); err != nil {
+		return derp.Wrap(err, location, "Error loading deleted Stream", streamURL)
+	}
+
+	return nil
+}
+
 // LoadParent returns the Stream that is the parent of the provided Stream
 func (service *Stream) LoadParent(stream *model.Stream, parent *model.Stream) error {
 
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/data"
+	"github.com/benpate/data/option"
+	"github.com/benpate/derp"
+	"github.com/benpate/exp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StreamRevision manages the immutable history of changes made to each Stream
+type StreamRevision struct {
+	collection      data.Collection
+	templateService *Template
+	userService     *User
+}
+
+// NewStreamRevision returns a fully initialized StreamRevision service
+func NewStreamRevision() StreamRevision {
+	return StreamRevision{}
+}
+
+/******************************************
+ * Lifecycle Methods
+ ******************************************/
+
+// Refresh updates any stateful data that is cached inside this service.
+func (service *StreamRevision) Refresh(collection data.Collection, templateService *Template, userService *User) {
+	service.collection = collection
+	service.templateService = templateService
+	service.userService = userService
+}
+
+// Close stops any background processes controlled by this service
+func (service *StreamRevision) Close() {
+	// Nothin to do here.
+}
+
+/******************************************
+ * Common Data Methods
+ ******************************************/
+
+// Count returns the number of StreamRevisions that match the provided criteria
+func (service *StreamRevision) Count(criteria exp.Expression) (int64, error) {
+	return service.collection.Count(notDeleted(criteria))
+}
+
+// Query returns a slice containing all of the StreamRevisions that match the provided criteria
+func (service *StreamRevision) Query(criteria exp.Expression, options ...option.Option) ([]model.StreamRevision, error) {
+	result := make([]model.StreamRevision, 0)
+	err := service.collection.Query(&result, notDeleted(criteria), options...)
+	return result, err
+}
+
+// Load retrieves a StreamRevision from the database
+func (service *StreamRevision) Load(criteria exp.Expression, revision *model.StreamRevision) error {
+
+	if err := service.collection.Load(notDeleted(criteria), revision); err != nil {
+		return derp.Wrap(err, "service.StreamRevision.Load", "Error loading StreamRevision", criteria)
+	}
+
+	return nil
+}
+
+/******************************************
+ * Custom Queries
+ ******************************************/
+
+// QueryByStream returns all StreamRevisions for a single Stream, newest first
+func (service *StreamRevision) QueryByStream(streamID primitive.ObjectID) ([]model.StreamRevision, error) {
+	criteria := exp.Equal("streamId", streamID)
+	return service.Query(criteria, option.SortDesc("createDate"))
+}
+
+// LoadByID retrieves a single StreamRevision, which must belong to the provided Stream
+func (service *StreamRevision) LoadByID(streamID primitive.ObjectID, revisionID primitive.ObjectID, revision *model.StreamRevision) error {
+	criteria := exp.Equal("_id", revisionID).AndEqual("streamId", streamID)
+	return service.Load(criteria, revision)
+}
+
+/******************************************
+ * Custom Actions
+ ******************************************/
+
+// Create records a new, immutable snapshot of the provided Stream.  Revisions are only
+// recorded when the Stream's Template sets a positive "revisionLimit", and older
+// Revisions beyond that limit are removed.
+func (service *StreamRevision) Create(stream *model.Stream, authorID primitive.ObjectID, kind string, note string) error {
+
+	const location = "service.StreamRevision.Create"
+
+	// Find the retention limit for this Stream
+	template, err := service.templateService.Load(stream.TemplateID)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error loading Template", stream.TemplateID)
+	}
+
+	if template.RevisionLimit <= 0 {
+		return nil
+	}
+
+	// Build the snapshot
+	revision := model.NewStreamRevisionFromStream(stream, kind)
+	revision.AuthorID = authorID
+	revision.Note = note
+
+	if !authorID.IsZero() {
+		user := model.NewUser()
+		if err := service.userService.LoadByID(authorID, &user); err == nil {
+			revision.AuthorName = user.DisplayName
+		} else if !derp.NotFound(err) {
+			return derp.Wrap(err, location, "Error loading author", authorID)
+		}
+	}
+
+	// Revisions are never updated, so this is always an insert
+	if err := service.collection.Save(&revision, note); err != nil {
+		return derp.Wrap(err, location, "Error saving StreamRevision", stream.StreamID)
+	}
+
+	// Remove revisions that exceed the retention limit
+	if err := service.prune(stream.StreamID, template.RevisionLimit); err != nil {
+		return derp.Wrap(err, location, "Error removing expired StreamRevisions", stream.StreamID)
+	}
+
+	return nil
+}
+
+// prune permanently removes all but the newest "limit" StreamRevisions for a Stream
+func (service *StreamRevision) prune(streamID primitive.ObjectID, limit int) error {
+
+	const location = "service.StreamRevision.prune"
+
+	revisions, err := service.Query(
+		exp.Equal("streamId", streamID),
+		option.Fields("_id"),
+		option.SortDesc("createDate"),
+	)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error querying StreamRevisions", streamID)
+	}
+
+	if len(revisions) <= limit {
+		return nil
+	}
+
+	expired := make([]primitive.ObjectID, 0, len(revisions)-limit)
+	for _, revision := range revisions[limit:] {
+		expired = append(expired, revision.StreamRevisionID)
+	}
+
+	if err := service.collection.HardDelete(exp.In("_id", expired)); err != nil {
+		return derp.Wrap(err, location, "Error deleting StreamRevisions", streamID)
+	}
+
+	return nil
+}
@@ -47,6 +47,15 @@ func (service *Stream) JSONLD(stream *model.Stream) mapof.Any {
 		result[vocab.PropertyContent] = stream.Content.HTML
 	}
 
+	// Multilingual Streams include every available translation
+	if nameMap := stream.ActivityPubNameMap(); len(nameMap) > 0 {
+		result["nameMap"] = nameMap
+	}
+
+	if contentMap := stream.ActivityPubContentMap(); len(contentMap) > 0 {
+		result["contentMap"] = contentMap
+	}
+
 	if stream.IconURL != "" {
 		result[vocab.PropertyIcon] = stream.IconURL
 	}
@@ -67,6 +76,11 @@ func (service *Stream) JSONLD(stream *model.Stream) mapof.Any {
 		result[vocab.PropertyTag] = slice.Map(stream.Hashtags, service.HashtagAsJSONLD)
 	}
 
+	// Collections of responses to this Stream
+	result[vocab.PropertyReplies] = stream.ActivityPubRepliesURL()
+	result[vocab.PropertyLikes] = stream.ActivityPubLikesURL()
+	result[vocab.PropertyShares] = stream.ActivityPubSharesURL()
+
 	// NOTE: According to Mastodon ActivityPub guide (https://docs.joinmastodon.org/spec/activitypub/)
 	// putting as:public in the To field means that this mesage is public, and "listed"
 	// putting as:public in the Cc field means that this message is public, but "unlisted"
@@ -164,8 +178,11 @@ func (service *Stream) ActivityPubActor(streamID primitive.ObjectID, withFollowe
 		return outbox.Actor{}, derp.Wrap(err, location, "Error extracting private key", encryptionKey)
 	}
 
+	// Deliveries are collapsed into each remote server's shared inbox, when available
+	client := NewSharedInboxClient(service.activityStream)
+
 	// Return the ActivityPub Actor
-	actor := outbox.NewActor(service.ActivityPubURL(streamID), privateKey, outbox.WithClient(service.activityStream)) // TODO: Restore Queue:: , outbox.WithQueue(service.queue))
+	actor := outbox.NewActor(service.ActivityPubURL(streamID), privateKey, outbox.WithClient(client)) // TODO: Restore Queue:: , outbox.WithQueue(service.queue))
 
 	// Populate the Actor's ActivityPub Followers, if requested
 	if withFollowers {
@@ -179,7 +196,8 @@ func (service *Stream) ActivityPubActor(streamID primitive.ObjectID, withFollowe
 
 		// Get a filter to prevent sending to "Blocked" followers
 		ruleFilter := service.ruleService.Filter(primitive.NilObjectID, WithBlocksOnly())
-		followerIDs := ruleFilter.ChannelSend(followers)
+		allowedFollowers := ruleFilter.ChannelSend(followers)
+		followerIDs := client.Followers(allowedFollowers)
 
 		// Add the channel of follower IDs to the Actor
 		actor.With(outbox.WithFollowers(followerIDs))
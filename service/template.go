@@ -30,13 +30,14 @@ type Template struct {
 	emailService        *ServerEmail                 // Email Service
 	themeService        *Theme                       // Theme Service
 	widgetService       *Widget                      // Widget Service
+	translationService  *Translation                 // Translation Service
 	funcMap             template.FuncMap             // Map of functions to use in golang templates
 	mutex               sync.RWMutex                 // Mutext that locks access to the templates structure
 	refresh             chan channel.Done            // Channel that is used to signal that the template service should refresh
 }
 
 // NewTemplate returns a fully initialized Template service.
-func NewTemplate(filesystemService Filesystem, registrationService *Registration, emailService *ServerEmail, themeService *Theme, widgetService *Widget, funcMap template.FuncMap, locations []mapof.String) *Template {
+func NewTemplate(filesystemService Filesystem, registrationService *Registration, emailService *ServerEmail, themeService *Theme, widgetService *Widget, translationService *Translation, funcMap template.FuncMap, locations []mapof.String) *Template {
 
 	service := &Template{
 		templates:           make(set.Map[model.Template]),
@@ -47,6 +48,7 @@ func NewTemplate(filesystemService Filesystem, registrationService *Registration
 		emailService:        emailService,
 		themeService:        themeService,
 		widgetService:       widgetService,
+		translationService:  translationService,
 		funcMap:             funcMap,
 		refresh:             make(chan channel.Done),
 	}
@@ -126,6 +128,7 @@ func (service *Template) watch() {
 func (service *Template) loadTemplates() error {
 
 	service.templatePrep = make(set.Map[model.Template])
+	service.translationService.prepare()
 
 	// For each configured location...
 	for _, location := range service.locations {
@@ -199,6 +202,11 @@ func (service *Template) loadTemplates() error {
 					derp.Report(derp.Wrap(err, "service.Template.loadTemplates", "Error adding widget"))
 				}
 
+			case DefinitionCatalog:
+				if err := service.translationService.Add(subdirectory, file); err != nil {
+					derp.Report(derp.Wrap(err, "service.Template.loadTemplates", "Error adding catalog"))
+				}
+
 			default:
 				derp.Report(derp.NewInternalError("service.Template.loadTemplates", "Unrecognized definition type", location, definitionType))
 			}
@@ -213,6 +221,7 @@ func (service *Template) loadTemplates() error {
 	}
 
 	service.themeService.calculateAllInheritance()
+	service.translationService.commit()
 
 	// Assign the prep area to live
 	service.mutex.Lock()
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/EmissarySocial/emissary/config"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/hairyhenderson/go-git/v5"
+	"github.com/hairyhenderson/go-git/v5/plumbing"
+	"github.com/hairyhenderson/go-git/v5/plumbing/object"
+	"github.com/hairyhenderson/go-git/v5/storage/memory"
+)
+
+// TemplatePackageMaxSize is the largest (uncompressed) template package that can be installed
+const TemplatePackageMaxSize = 100 * 1024 * 1024
+
+// TemplatePackage service downloads template packages from Git repositories or .zip files,
+// validates them, and stages them on the local filesystem so that they can be activated.
+type TemplatePackage struct {
+	templateService *Template
+}
+
+// NewTemplatePackage returns a fully initialized TemplatePackage service
+func NewTemplatePackage(templateService *Template) TemplatePackage {
+	return TemplatePackage{
+		templateService: templateService,
+	}
+}
+
+// TemplatePackageError is returned when a staged package fails validation.
+// It includes every diagnostic reported by the template linter.
+type TemplatePackageError struct {
+	Diagnostics sliceof.Object[TemplateDiagnostic]
+}
+
+func (err TemplatePackageError) Error() string {
+
+	messages := make([]string, 0, len(err.Diagnostics))
+
+	for _, diagnostic := range err.Diagnostics {
+		if diagnostic.IsError() {
+			messages = append(messages, diagnostic.String())
+		}
+	}
+
+	return "Template package is invalid:\n" + strings.Join(messages, "\n")
+}
+
+/******************************************
+ * Staging Methods
+ ******************************************/
+
+// StageGit downloads a Git repository at the requested tag, branch, or commit, and stages it
+// as a new version of the package.  If ref is empty, then the repository's HEAD is used.
+func (service *TemplatePackage) StageGit(packages mapof.String, packageID string, repositoryURL string, ref string, references sliceof.Object[mapof.String]) (config.TemplatePackageVersion, error) {
+
+	const location = "service.TemplatePackage.StageGit"
+
+	// Clone the repository into memory
+	repository, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
+		URL:  repositoryURL,
+		Tags: git.AllTags,
+	})
+
+	if err != nil {
+		return config.TemplatePackageVersion{}, derp.Wrap(err, location, "Error cloning Git repository", repositoryURL, derp.WithCode(http.StatusBadRequest))
+	}
+
+	// Resolve the requested revision into a specific commit
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	hash, err := repository.ResolveRevision(plumbing.Revision(ref))
+
+	if err != nil {
+		return config.TemplatePackageVersion{}, derp.Wrap(err, location, "Unknown Git revision", repositoryURL, ref, derp.WithCode(http.StatusBadRequest))
+	}
+
+	commit, err := repository.CommitObject(*hash)
+
+	if err != nil {
+		return config.TemplatePackageVersion{}, derp.Wrap(err, location, "Error loading Git commit", repositoryURL, hash.String())
+	}
+
+	// Write every file in the commit into the staging folder
+	files, err := commit.Files()
+
+	if err != nil {
+		return config.TemplatePackageVersion{}, derp.Wrap(err, location, "Error reading Git commit", repositoryURL, hash.String())
+	}
+
+	write := func(target string) error {
+		size := int64(0)
+		return files.ForEach(func(file *object.File) error {
+
+			size += file.Size
+			if size > TemplatePackageMaxSize {
+				return derp.New(http.StatusRequestEntityTooLarge, location, "Template package is too large", repositoryURL)
+			}
+
+			reader, err := file.Reader()
+
+			if err != nil {
+				return derp.Wrap(err, location, "Error reading file from Git", file.Name)
+			}
+
+			defer reader.Close()
+			return writePackageFile(target, file.Name, reader)
+		})
+	}
+
+	result, err := service.stage(packages, packageID, hash.String(), references, write)
+
+	if err != nil {
+		return result, derp.Wrap(err, location, "Error staging Git repository", repositoryURL, ref)
+	}
+
+	result.Ref = ref
+	return result, nil
+}
+
+// StageZip unpacks a .zip file and stages it as a new version of the package.
+// The version identifier is a checksum of the .zip file's contents.
+func (service *TemplatePackage) StageZip(packages mapof.String, packageID string, data []byte, references sliceof.Object[mapof.String]) (config.TemplatePackageVersion, error) {
+
+	const location = "service.TemplatePackage.StageZip"
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		return config.TemplatePackageVersion{}, derp.Wrap(err, location, "Invalid .zip file", derp.WithCode(http.StatusBadRequest))
+	}
+
+	checksum := sha256.Sum256(data)
+	version := hex.EncodeToString(checksum[:])[:16]
+
+	// Write every file in the archive into the staging folder.
+	// Walking the fs.FS (instead of the raw file headers) rejects unsafe paths like "../"
+	write := func(target string) error {
+		size := int64(0)
+		return fs.WalkDir(archive, ".", func(name string, entry fs.DirEntry, err error) error {
+
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() {
+				return nil
+			}
+
+			info, err := entry.Info()
+
+			if err != nil {
+				return derp.Wrap(err, location, "Error reading file from .zip", name)
+			}
+
+			size += info.Size()
+			if size > TemplatePackageMaxSize {
+				return derp.New(http.StatusRequestEntityTooLarge, location, "Template package is too large")
+			}
+
+			reader, err := archive.Open(name)
+
+			if err != nil {
+				return derp.Wrap(err, location, "Error reading file from .zip", name)
+			}
+
+			defer reader.Close()
+			return writePackageFile(target, name, reader)
+		})
+	}
+
+	result, err := service.stage(packages, packageID, version, references, write)
+
+	if err != nil {
+		return result, derp.Wrap(err, location, "Error staging .zip file")
+	}
+
+	return result, nil
+}
+
+// Remove deletes an installed version from the local filesystem
+func (service *TemplatePackage) Remove(packages mapof.String, packageID string, version string) error {
+
+	folder, err := packageFolder(packages, packageID, version)
+
+	if err != nil {
+		return derp.Wrap(err, "service.TemplatePackage.Remove", "Invalid package location", packageID, version)
+	}
+
+	if err := os.RemoveAll(folder); err != nil {
+		return derp.Wrap(err, "service.TemplatePackage.Remove", "Error removing package files", folder)
+	}
+
+	return nil
+}
+
+// stage writes a package into a hidden staging folder, validates it against the templates that
+// are already running, and then moves it into its permanent location.  Nothing is visible to the
+// template service until the caller activates the returned version.
+func (service *TemplatePackage) stage(packages mapof.String, packageID string, version string, references sliceof.Object[mapof.String], write func(string) error) (config.TemplatePackageVersion, error) {
+
+	const location = "service.TemplatePackage.stage"
+
+	target, err := packageFolder(packages, packageID, version)
+
+	if err != nil {
+		return config.TemplatePackageVersion{}, derp.Wrap(err, location, "Invalid package location", packageID, version)
+	}
+
+	// Versions are identified by their contents, so an existing version does not need to be staged again
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		return config.TemplatePackageVersion{
+			Version:     version,
+			Location:    filepath.Join(target, packageRoot(target)),
+			InstallDate: time.Now().Unix(),
+		}, nil
+	}
+
+	// Hidden folders are skipped by the template loader, so staging is never served
+	staging := filepath.Join(filepath.Dir(target), ".staging-"+version)
+
+	if err := os.RemoveAll(staging); err != nil {
+		return config.TemplatePackageVersion{}, derp.Wrap(err, location, "Error clearing staging folder", staging)
+	}
+
+	defer os.RemoveAll(staging) // nolint:errcheck
+
+	if err := write(staging); err != nil {
+		return config.TemplatePackageVersion{}, derp.Wrap(err, location, "Error writing package files", packageID)
+	}
+
+	// Packages are often wrapped in a single top-level folder (like GitHub archives)
+	root := packageRoot(staging)
+
+	// Validate the package against the templates that are already installed
+	diagnostics := service.templateService.Validate(
+		sliceof.Object[mapof.String]{{"adapter": config.FolderAdapterFile, "location": filepath.Join(staging, root)}},
+		references,
+	)
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.IsError() {
+			return config.TemplatePackageVersion{}, derp.Wrap(TemplatePackageError{Diagnostics: diagnostics}, location, "Template package failed validation", packageID, derp.WithCode(http.StatusUnprocessableEntity))
+		}
+	}
+
+	// Move the staged files into their permanent location
+	if err := os.Rename(staging, target); err != nil {
+		return config.TemplatePackageVersion{}, derp.Wrap(err, location, "Error moving package into place", target)
+	}
+
+	return config.TemplatePackageVersion{
+		Version:     version,
+		Location:    filepath.Join(target, root),
+		InstallDate: time.Now().Unix(),
+	}, nil
+}
+
+/******************************************
+ * Helper Functions
+ ******************************************/
+
+// packageIDPattern matches every character that is not allowed in a package identifier
+var packageIDPattern = regexp.MustCompile(`[^a-z0-9\-_]+`)
+
+// TemplatePackageID generates a safe package identifier from a name, URL, or filename
+func TemplatePackageID(value string) string {
+
+	value = strings.ToLower(strings.TrimSpace(value))
+	value = strings.TrimSuffix(value, "/")
+	value = path.Base(value)
+	value = strings.TrimSuffix(value, ".git")
+	value = strings.TrimSuffix(value, ".zip")
+	value = packageIDPattern.ReplaceAllString(value, "-")
+	value = strings.Trim(value, "-")
+
+	return value
+}
+
+// packageFolder returns the local folder where a version of a package is installed
+func packageFolder(packages mapof.String, packageID string, version string) (string, error) {
+
+	const location = "service.packageFolder"
+
+	if packages["adapter"] != config.FolderAdapterFile {
+		return "", derp.NewInternalError(location, "Template packages must be stored in a FILE folder", packages)
+	}
+
+	if packages["location"] == "" {
+		return "", derp.NewInternalError(location, "Template package folder is not configured", packages)
+	}
+
+	if (packageID != TemplatePackageID(packageID)) || (packageID == "") {
+		return "", derp.NewBadRequestError(location, "Invalid package ID", packageID)
+	}
+
+	if (version == "") || strings.ContainsAny(version, `/\.`) {
+		return "", derp.NewBadRequestError(location, "Invalid package version", version)
+	}
+
+	return filepath.Join(packages["location"], packageID, version), nil
+}
+
+// packageRoot returns the folder (relative to the staging folder) that contains
+// the package's templates.  If the package has been wrapped in a single folder,
+// then that folder is used.
+func packageRoot(staging string) string {
+
+	entries, err := os.ReadDir(staging)
+
+	if err != nil {
+		return ""
+	}
+
+	visible := make([]os.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), ".") {
+			visible = append(visible, entry)
+		}
+	}
+
+	if len(visible) != 1 || !visible[0].IsDir() {
+		return ""
+	}
+
+	// A single folder that is itself a template is not a wrapper
+	if _, _, err := findDefinition(os.DirFS(filepath.Join(staging, visible[0].Name()))); err == nil {
+		return ""
+	}
+
+	return visible[0].Name()
+}
+
+// writePackageFile copies a single file into the staging folder
+func writePackageFile(staging string, name string, reader io.Reader) error {
+
+	const location = "service.writePackageFile"
+
+	if !fs.ValidPath(name) {
+		return derp.NewBadRequestError(location, "Invalid file name in package", name)
+	}
+
+	filename := filepath.Join(staging, filepath.FromSlash(name))
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return derp.Wrap(err, location, "Error creating folder", filename)
+	}
+
+	file, err := os.Create(filename)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error creating file", filename)
+	}
+
+	defer file.Close()
+
+	if _, err := io.Copy(file, reader); err != nil {
+		return derp.Wrap(err, location, "Error writing file", filename)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EmissarySocial/emissary/config"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/stretchr/testify/require"
+)
+
+func testTemplatePackageZip(t *testing.T, files map[string]string) []byte {
+
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+
+	for name, content := range files {
+		file, err := writer.Create(name)
+		require.Nil(t, err)
+		_, err = file.Write([]byte(content))
+		require.Nil(t, err)
+	}
+
+	require.Nil(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func TestTemplatePackage_StageZip(t *testing.T) {
+
+	packages := mapof.String{"adapter": config.FolderAdapterFile, "location": t.TempDir()}
+	references := sliceof.Object[mapof.String]{{"adapter": "EMBED", "location": "reference"}}
+	service := NewTemplatePackage(testTemplateValidator())
+
+	// Archives wrapped in a single folder are unwrapped
+	data := testTemplatePackageZip(t, map[string]string{
+		"my-package-1.0/article/template.hjson": `{templateId:"article", extends:["base"], actions:{view:{roles:["viewer"], do:"view-html"}}}`,
+		"my-package-1.0/article/view.html":      `<div>{{.Label}}</div>`,
+	})
+
+	version, err := service.StageZip(packages, "my-package", data, references)
+	require.Nil(t, err)
+	require.Len(t, version.Version, 16)
+	require.Equal(t, filepath.Join(packages["location"], "my-package", version.Version, "my-package-1.0"), version.Location)
+	require.FileExists(t, filepath.Join(version.Location, "article", "template.hjson"))
+
+	// Installing the same archive again returns the same version
+	again, err := service.StageZip(packages, "my-package", data, references)
+	require.Nil(t, err)
+	require.Equal(t, version.Version, again.Version)
+	require.Equal(t, version.Location, again.Location)
+
+	// Removing the version deletes its files
+	require.Nil(t, service.Remove(packages, "my-package", version.Version))
+	require.NoDirExists(t, filepath.Join(packages["location"], "my-package", version.Version))
+}
+
+func TestTemplatePackage_StageZip_Invalid(t *testing.T) {
+
+	packages := mapof.String{"adapter": config.FolderAdapterFile, "location": t.TempDir()}
+	references := sliceof.Object[mapof.String]{{"adapter": "EMBED", "location": "reference"}}
+	service := NewTemplatePackage(testTemplateValidator())
+
+	data := testTemplatePackageZip(t, map[string]string{
+		"article/template.hjson": `{templateId:"article", extends:["missing"]}`,
+	})
+
+	_, err := service.StageZip(packages, "broken", data, references)
+	require.NotNil(t, err)
+	require.Equal(t, 422, derp.ErrorCode(err))
+
+	// Failed packages are never moved into place
+	entries, err := os.ReadDir(filepath.Join(packages["location"], "broken"))
+	require.Nil(t, err)
+	require.Empty(t, entries)
+}
+
+func TestTemplatePackage_StageZip_NotZip(t *testing.T) {
+
+	packages := mapof.String{"adapter": config.FolderAdapterFile, "location": t.TempDir()}
+	service := NewTemplatePackage(testTemplateValidator())
+
+	_, err := service.StageZip(packages, "nope", []byte("not a zip file"), nil)
+	require.NotNil(t, err)
+	require.Equal(t, 400, derp.ErrorCode(err))
+}
+
+func TestTemplatePackageID(t *testing.T) {
+	require.Equal(t, "templates", TemplatePackageID("https://github.com/example/templates.git"))
+	require.Equal(t, "my-templates", TemplatePackageID("My Templates.zip"))
+	require.Equal(t, "blog", TemplatePackageID("blog/"))
+	require.Equal(t, "", TemplatePackageID("../.."))
+}
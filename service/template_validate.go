@@ -0,0 +1,759 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/model/step"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/convert"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/hjson/hjson-go/v4"
+)
+
+// TemplateDiagnosticError marks a problem that will break a template at runtime
+const TemplateDiagnosticError = "error"
+
+// TemplateDiagnosticWarning marks a problem that may not work as intended
+const TemplateDiagnosticWarning = "warning"
+
+// TemplateDiagnostic describes a single problem found while validating a template folder
+type TemplateDiagnostic struct {
+	File     string // Path of the file that contains the problem
+	Line     int    // Line number of the problem (zero if unknown)
+	Severity string // TemplateDiagnosticError or TemplateDiagnosticWarning
+	Message  string // Human-friendly description of the problem
+}
+
+// String returns the diagnostic in the common "file:line: severity: message" format
+func (diagnostic TemplateDiagnostic) String() string {
+
+	if diagnostic.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", diagnostic.File, diagnostic.Line, diagnostic.Severity, diagnostic.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", diagnostic.File, diagnostic.Severity, diagnostic.Message)
+}
+
+// IsError returns TRUE if this diagnostic describes an error (not a warning)
+func (diagnostic TemplateDiagnostic) IsError() bool {
+	return diagnostic.Severity == TemplateDiagnosticError
+}
+
+// Validate loads every definition in the "targets" folders and returns a list of all problems found.
+// The "references" folders (like the built-in templates) are loaded so that targets can extend them,
+// but they are not validated themselves.  Validate does not change the templates that are currently in use.
+func (service *Template) Validate(targets sliceof.Object[mapof.String], references sliceof.Object[mapof.String]) sliceof.Object[TemplateDiagnostic] {
+
+	validator := templateValidator{
+		funcMap:   service.funcMap,
+		templates: make(map[string]*validatedTemplate),
+		resolved:  make(map[string]model.Template),
+	}
+
+	for _, location := range references {
+		validator.loadFolder(&service.filesystemService, location, false)
+	}
+
+	for _, location := range targets {
+		validator.loadFolder(&service.filesystemService, location, true)
+	}
+
+	validator.validateTemplates()
+
+	// Sort diagnostics by file and line so that output is stable
+	sort.SliceStable(validator.diagnostics, func(a int, b int) bool {
+		if validator.diagnostics[a].File != validator.diagnostics[b].File {
+			return validator.diagnostics[a].File < validator.diagnostics[b].File
+		}
+		return validator.diagnostics[a].Line < validator.diagnostics[b].Line
+	})
+
+	return validator.diagnostics
+}
+
+/******************************************
+ * Validator Internals
+ ******************************************/
+
+// validatedTemplate is a Template that has been loaded by the templateValidator
+type validatedTemplate struct {
+	template model.Template // Template parsed from the definition (without actions)
+	raw      mapof.Any      // Raw definition data
+	file     string         // Path of the definition file
+	lines    []string       // Lines of the definition file, used to locate diagnostics
+	isTarget bool           // TRUE if this template should be validated
+}
+
+// templateValidator collects the templates and diagnostics for a single Validate call
+type templateValidator struct {
+	funcMap     template.FuncMap
+	templates   map[string]*validatedTemplate
+	resolved    map[string]model.Template
+	diagnostics sliceof.Object[TemplateDiagnostic]
+}
+
+var templateValidatorLinePattern = regexp.MustCompile(`line (\d+)`)
+
+var templateValidatorHTMLLinePattern = regexp.MustCompile(`^template: [^:]+:(\d+):`)
+
+// report adds a new diagnostic to the results
+func (validator *templateValidator) report(severity string, file string, line int, message string, args ...any) {
+	validator.diagnostics = append(validator.diagnostics, TemplateDiagnostic{
+		File:     file,
+		Line:     line,
+		Severity: severity,
+		Message:  fmt.Sprintf(message, args...),
+	})
+}
+
+// loadFolder loads every definition in a single template location
+func (validator *templateValidator) loadFolder(filesystemService *Filesystem, location mapof.String, isTarget bool) {
+
+	filesystem, err := filesystemService.GetFS(location)
+
+	if err != nil {
+		if isTarget {
+			validator.report(TemplateDiagnosticError, location["location"], 0, "cannot open folder: %s", derp.Message(derp.RootCause(err)))
+		}
+		return
+	}
+
+	directories, err := fs.ReadDir(filesystem, ".")
+
+	if err != nil {
+		if isTarget {
+			validator.report(TemplateDiagnosticError, location["location"], 0, "cannot read folder: %s", err.Error())
+		}
+		return
+	}
+
+	for _, directory := range directories {
+
+		directoryName := directory.Name()
+
+		// Skip files and "hidden" directories
+		if !directory.IsDir() || strings.HasPrefix(directoryName, ".") {
+			continue
+		}
+
+		subdirectory, err := fs.Sub(filesystem, directoryName)
+
+		if err != nil {
+			continue
+		}
+
+		dirPath := path.Join(location["location"], directoryName)
+		definitionType, definition, err := findDefinition(subdirectory)
+
+		if err != nil {
+			if isTarget {
+				validator.report(TemplateDiagnosticError, dirPath, 0, "no definition file found")
+			}
+			continue
+		}
+
+		file := path.Join(dirPath, definitionFilename(subdirectory, definitionType))
+
+		if definitionType == DefinitionTemplate {
+			validator.loadTemplate(directoryName, subdirectory, file, definition, isTarget)
+			continue
+		}
+
+		// Other definitions are only checked for syntax and HTML errors
+		if isTarget {
+			var raw mapof.Any
+			if err := hjson.Unmarshal(definition, &raw); err != nil {
+				validator.reportSyntax(file, err)
+			}
+			validator.compileHTML(subdirectory, dirPath)
+
+			if definitionType == DefinitionCatalog {
+				validator.validateCatalog(subdirectory, dirPath)
+			}
+		}
+	}
+}
+
+// validateCatalog checks the syntax of every locale file in a message catalog
+func (validator *templateValidator) validateCatalog(filesystem fs.FS, dirPath string) {
+
+	files, err := fs.ReadDir(filesystem, ".")
+
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+
+		if _, ok := catalogLocale(file); !ok {
+			continue
+		}
+
+		content, err := fs.ReadFile(filesystem, file.Name())
+
+		if err != nil {
+			validator.report(TemplateDiagnosticError, path.Join(dirPath, file.Name()), 0, "cannot read file: %s", err.Error())
+			continue
+		}
+
+		var messages map[string]any
+		if err := hjson.Unmarshal(content, &messages); err != nil {
+			validator.reportSyntax(path.Join(dirPath, file.Name()), err)
+		}
+	}
+}
+
+// loadTemplate parses a single template definition into the validator's library
+func (validator *templateValidator) loadTemplate(templateID string, filesystem fs.FS, file string, definition []byte, isTarget bool) {
+
+	var raw mapof.Any
+
+	if err := hjson.Unmarshal(definition, &raw); err != nil {
+		if isTarget {
+			validator.reportSyntax(file, err)
+		}
+		return
+	}
+
+	// Parse everything except the actions, state hooks, and transitions, which are validated step-by-step later
+	withoutActions := mapof.NewAny()
+	for key, value := range raw {
+		switch key {
+		case "actions", "transitions":
+		case "states":
+			withoutActions[key] = withoutStateHooks(convert.MapOfAny(value))
+		default:
+			withoutActions[key] = value
+		}
+	}
+
+	result := model.NewTemplate(templateID, validator.funcMap)
+
+	if data, err := json.Marshal(withoutActions); err != nil {
+		validator.report(TemplateDiagnosticError, file, 0, "cannot read definition: %s", err.Error())
+		return
+	} else if err := hjson.Unmarshal(data, &result); err != nil {
+		if isTarget {
+			validator.report(TemplateDiagnosticError, file, 0, "invalid definition: %s", derp.Message(derp.RootCause(err)))
+		}
+		return
+	}
+
+	if isTarget {
+
+		// Compile all HTML files
+		validator.compileHTML(filesystem, path.Dir(file))
+
+		// Load all bundles
+		if err := populateBundles(result.Bundles, filesystem); err != nil {
+			validator.report(TemplateDiagnosticError, file, validator.findKey(strings.Split(string(definition), "\n"), 0, "bundles"), "invalid bundle: %s", derp.Message(derp.RootCause(err)))
+		}
+	}
+
+	validator.templates[result.TemplateID] = &validatedTemplate{
+		template: result,
+		raw:      raw,
+		file:     file,
+		lines:    strings.Split(string(definition), "\n"),
+		isTarget: isTarget,
+	}
+}
+
+// reportSyntax reports an HJSON syntax error, including the line number when available
+func (validator *templateValidator) reportSyntax(file string, err error) {
+
+	line := 0
+	if match := templateValidatorLinePattern.FindStringSubmatch(err.Error()); len(match) == 2 {
+		line, _ = strconv.Atoi(match[1])
+	}
+
+	// Remove the (multi-line) source excerpt from the error message
+	message, _, _ := strings.Cut(err.Error(), " >>> ")
+
+	validator.report(TemplateDiagnosticError, file, line, "syntax error: %s", message)
+}
+
+// compileHTML compiles every HTML file in a directory, reporting any errors with their line numbers
+func (validator *templateValidator) compileHTML(filesystem fs.FS, dirPath string) {
+
+	files, err := fs.ReadDir(filesystem, ".")
+
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+
+		filename := file.Name()
+
+		if file.IsDir() || !strings.HasSuffix(filename, ".html") {
+			continue
+		}
+
+		content, err := fs.ReadFile(filesystem, filename)
+
+		if err != nil {
+			validator.report(TemplateDiagnosticError, path.Join(dirPath, filename), 0, "cannot read file: %s", err.Error())
+			continue
+		}
+
+		name := strings.TrimSuffix(filename, ".html")
+
+		if _, err := template.New(name).Funcs(validator.funcMap).Parse(string(content)); err != nil {
+
+			line := 0
+			if match := templateValidatorHTMLLinePattern.FindStringSubmatch(err.Error()); len(match) == 2 {
+				line, _ = strconv.Atoi(match[1])
+			}
+
+			validator.report(TemplateDiagnosticError, path.Join(dirPath, filename), line, "%s", err.Error())
+		}
+	}
+}
+
+// resolve returns a template with all of its inherited values applied
+func (validator *templateValidator) resolve(templateID string, visiting map[string]bool) (model.Template, bool) {
+
+	if result, ok := validator.resolved[templateID]; ok {
+		return result, true
+	}
+
+	item, ok := validator.templates[templateID]
+
+	if !ok || visiting[templateID] {
+		return model.Template{}, false
+	}
+
+	visiting[templateID] = true
+	result := item.template
+
+	for _, parentID := range result.Extends {
+		if parent, ok := validator.resolve(parentID, visiting); ok {
+			result.Inherit(&parent)
+		}
+	}
+
+	validator.resolved[templateID] = result
+	return result, true
+}
+
+// declaredRoles returns all roles declared by a template and the templates that it extends
+func (validator *templateValidator) declaredRoles(templateID string, result map[string]bool) {
+
+	item, ok := validator.templates[templateID]
+
+	if !ok || result["template:"+templateID] {
+		return
+	}
+
+	result["template:"+templateID] = true
+
+	for _, key := range []string{"roles", "accessRoles"} {
+		for roleID := range item.raw.GetMap(key) {
+			result[roleID] = true
+		}
+	}
+
+	for _, parentID := range item.template.Extends {
+		validator.declaredRoles(parentID, result)
+	}
+}
+
+// validateTemplates checks every target template against the full template library
+func (validator *templateValidator) validateTemplates() {
+
+	// Collect all known templateRoles that can contain other templates
+	containers := map[string]bool{"top": true, "outbox": true}
+
+	for templateID := range validator.templates {
+		if resolved, ok := validator.resolve(templateID, map[string]bool{}); ok && resolved.TemplateRole != "" {
+			containers[resolved.TemplateRole] = true
+		}
+	}
+
+	// Validate targets in a stable order
+	templateIDs := make([]string, 0, len(validator.templates))
+	for templateID, item := range validator.templates {
+		if item.isTarget {
+			templateIDs = append(templateIDs, templateID)
+		}
+	}
+	sort.Strings(templateIDs)
+
+	for _, templateID := range templateIDs {
+		validator.validateTemplate(validator.templates[templateID], containers)
+	}
+}
+
+// validateTemplate checks a single template's references and actions
+func (validator *templateValidator) validateTemplate(item *validatedTemplate, containers map[string]bool) {
+
+	resolved, _ := validator.resolve(item.template.TemplateID, map[string]bool{})
+
+	// Verify "extends" targets
+	for _, parentID := range item.template.Extends {
+		if _, ok := validator.templates[parentID]; !ok {
+			validator.report(TemplateDiagnosticError, item.file, validator.findValue(item.lines, 0, "extends", parentID), "extends unknown template %q", parentID)
+		}
+	}
+
+	// Verify "containedBy" targets
+	for _, containerRole := range item.template.ContainedBy {
+		if !containers[containerRole] {
+			validator.report(TemplateDiagnosticWarning, item.file, validator.findValue(item.lines, 0, "containedBy", containerRole), "containedBy %q does not match any templateRole", containerRole)
+		}
+	}
+
+	// Collect declared roles
+	roles := map[string]bool{
+		model.MagicRoleAnonymous:     true,
+		model.MagicRoleAuthenticated: true,
+		model.MagicRoleAuthor:        true,
+		model.MagicRoleMyself:        true,
+		model.MagicRoleOwner:         true,
+	}
+	validator.declaredRoles(item.template.TemplateID, roles)
+
+	// Validate each action in a stable order
+	actions := item.raw.GetMap("actions")
+	actionIDs := make([]string, 0, len(actions))
+	for actionID := range actions {
+		actionIDs = append(actionIDs, actionID)
+	}
+	sort.Strings(actionIDs)
+
+	actionsLine := validator.findKey(item.lines, 0, "actions")
+
+	for _, actionID := range actionIDs {
+		actionInfo := convert.MapOfAny(actions[actionID])
+		actionLine := validator.findKey(item.lines, actionsLine, actionID)
+		validator.validateAction(item, &resolved, roles, actionID, actionInfo, actionLine)
+	}
+
+	validator.validateStateHooks(item, &resolved)
+	validator.validateTransitions(item, &resolved, roles)
+}
+
+// validateStateHooks checks the onEnter/onExit steps of each state
+func (validator *templateValidator) validateStateHooks(item *validatedTemplate, resolved *model.Template) {
+
+	states := item.raw.GetMap("states")
+	stateIDs := make([]string, 0, len(states))
+	for stateID := range states {
+		stateIDs = append(stateIDs, stateID)
+	}
+	sort.Strings(stateIDs)
+
+	statesLine := validator.findKey(item.lines, 0, "states")
+
+	for _, stateID := range stateIDs {
+		stateInfo := convert.MapOfAny(states[stateID])
+		stateLine := validator.findKey(item.lines, statesLine, stateID)
+
+		for _, hook := range []string{"onEnter", "onExit"} {
+			cursor := stateLine
+			for _, stepInfo := range convert.SliceOfMap(stateInfo[hook]) {
+				validator.validateStep(item, resolved, resolved.Model, true, mapof.Any(stepInfo), stateLine, &cursor)
+			}
+		}
+	}
+}
+
+// validateTransitions checks that every transition moves between declared states, using declared roles
+func (validator *templateValidator) validateTransitions(item *validatedTemplate, resolved *model.Template, roles map[string]bool) {
+
+	transitionsLine := validator.findKey(item.lines, 0, "transitions")
+
+	for _, transitionInfo := range convert.SliceOfMap(item.raw["transitions"]) {
+
+		info := mapof.Any(transitionInfo)
+		to := info.GetString("to")
+		line := transitionsLine
+
+		if to == "" {
+			validator.report(TemplateDiagnosticError, item.file, line, "transition is missing a \"to\" state")
+			continue
+		}
+
+		if found := validator.findValue(item.lines, transitionsLine-1, "to", to); found > 0 {
+			line = found
+		}
+
+		if _, ok := resolved.States[to]; !ok {
+			validator.report(TemplateDiagnosticError, item.file, line, "transition uses undeclared state %q", to)
+		}
+
+		for _, from := range convert.SliceOfString(info["from"]) {
+			if _, ok := resolved.States[from]; !ok {
+				validator.report(TemplateDiagnosticError, item.file, line, "transition uses undeclared state %q", from)
+			}
+		}
+
+		for _, roleID := range convert.SliceOfString(info["roles"]) {
+			if !roles[roleID] {
+				validator.report(TemplateDiagnosticError, item.file, line, "transition uses undeclared role %q", roleID)
+			}
+		}
+
+		if guard := info.GetString("guard"); guard != "" {
+			if _, err := template.New("").Parse(guard); err != nil {
+				validator.report(TemplateDiagnosticError, item.file, line, "invalid guard: %s", err.Error())
+			}
+		}
+	}
+}
+
+// withoutStateHooks returns a copy of the "states" definition without any onEnter/onExit steps
+func withoutStateHooks(states mapof.Any) mapof.Any {
+
+	result := mapof.NewAny()
+
+	for stateID, value := range states {
+		state := mapof.NewAny()
+		for key, value := range convert.MapOfAny(value) {
+			if key != "onEnter" && key != "onExit" {
+				state[key] = value
+			}
+		}
+		result[stateID] = state
+	}
+
+	return result
+}
+
+// validateAction checks the roles, states, and steps of a single action
+func (validator *templateValidator) validateAction(item *validatedTemplate, resolved *model.Template, roles map[string]bool, actionID string, actionInfo mapof.Any, actionLine int) {
+
+	file := item.file
+	start := actionLine - 1
+
+	// Verify roles
+	for _, roleID := range convert.SliceOfString(actionInfo["roles"]) {
+		if !roles[roleID] {
+			validator.report(TemplateDiagnosticError, file, validator.findValue(item.lines, start, "roles", roleID), "action %q uses undeclared role %q", actionID, roleID)
+		}
+	}
+
+	// Verify states
+	for _, stateID := range convert.SliceOfString(actionInfo["states"]) {
+		if _, ok := resolved.States[stateID]; !ok {
+			validator.report(TemplateDiagnosticError, file, validator.findValue(item.lines, start, "states", stateID), "action %q uses undeclared state %q", actionID, stateID)
+		}
+	}
+
+	// Verify stateRoles
+	for stateID, stateRoles := range convert.MapOfAny(actionInfo["stateRoles"]) {
+
+		if _, ok := resolved.States[stateID]; !ok {
+			validator.report(TemplateDiagnosticError, file, validator.findKey(item.lines, start, stateID), "action %q has stateRoles for undeclared state %q", actionID, stateID)
+		}
+
+		for _, roleID := range convert.SliceOfString(stateRoles) {
+			if !roles[roleID] {
+				validator.report(TemplateDiagnosticError, file, validator.findKey(item.lines, start, stateID), "action %q uses undeclared role %q", actionID, roleID)
+			}
+		}
+	}
+
+	// Collect steps (using the "do" alias if there are no steps)
+	stepsInfo := convert.SliceOfMap(actionInfo["steps"])
+
+	if len(stepsInfo) == 0 {
+		if convert.String(actionInfo["do"]) == "" {
+			validator.report(TemplateDiagnosticError, file, actionLine, "action %q has no steps", actionID)
+			return
+		}
+		stepsInfo = []map[string]any{actionInfo}
+	}
+
+	cursor := actionLine
+	for _, stepInfo := range stepsInfo {
+		validator.validateStep(item, resolved, resolved.Model, true, mapof.Any(stepInfo), actionLine, &cursor)
+	}
+}
+
+// validateStep parses a single step (and all of its sub-steps) and verifies its requirements.
+// It returns the number of errors found.
+func (validator *templateValidator) validateStep(item *validatedTemplate, resolved *model.Template, modelType string, isTemplateModel bool, stepInfo mapof.Any, actionLine int, cursor *int) int {
+
+	name := stepInfo.GetString("do")
+	line := validator.findStep(item.lines, *cursor, actionLine, name)
+
+	if line > 0 {
+		*cursor = line
+	}
+
+	// Sub-steps inside of "with-*" steps work on a different model object
+	childModel := modelType
+	childIsTemplateModel := isTemplateModel
+	if strings.HasPrefix(name, "with-") {
+		childModel = ""
+		childIsTemplateModel = false
+	}
+
+	// Validate all sub-steps first, so that errors are reported where they occur
+	keys := make([]string, 0, len(stepInfo))
+	for key := range stepInfo {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	errorCount := 0
+	for _, key := range keys {
+		if children := convert.SliceOfMap(stepInfo[key]); isStepList(children) {
+			for _, child := range children {
+				errorCount += validator.validateStep(item, resolved, childModel, childIsTemplateModel, mapof.Any(child), actionLine, cursor)
+			}
+		}
+	}
+
+	parsed, err := step.New(stepInfo)
+
+	if err != nil {
+
+		// Errors in sub-steps have already been reported
+		if errorCount > 0 {
+			return errorCount
+		}
+
+		if name == "" {
+			validator.report(TemplateDiagnosticError, item.file, line, "step is missing a \"do\" value")
+		} else if derp.Message(derp.RootCause(err)) == "Unrecognized step type" {
+			validator.report(TemplateDiagnosticError, item.file, line, "unrecognized step %q", name)
+		} else {
+			validator.report(TemplateDiagnosticError, item.file, line, "invalid step %q: %s", name, derp.Message(derp.RootCause(err)))
+		}
+
+		return 1
+	}
+
+	// Verify TypeRequirer
+	if requirer, ok := parsed.(step.TypeRequirer); ok && requirer.RequireType() != "template" {
+		validator.report(TemplateDiagnosticError, item.file, line, "step %q can only be used in a %s definition", name, requirer.RequireType())
+		errorCount++
+	}
+
+	// Verify ModelRequirer
+	if requirer, ok := parsed.(step.ModelRequirer); ok && modelType != "" && requirer.RequireModel() != modelType {
+		validator.report(TemplateDiagnosticError, item.file, line, "step %q requires a %q model, but is used with %q", name, requirer.RequireModel(), modelType)
+		errorCount++
+	}
+
+	// Verify state references (sub-steps inside of "with-*" steps work on other models, with their own states)
+	if setState, ok := parsed.(step.SetState); ok && isTemplateModel && !strings.Contains(setState.State, "{{") {
+		if _, exists := resolved.States[setState.State]; !exists {
+			validator.report(TemplateDiagnosticError, item.file, line, "step %q uses undeclared state %q", name, setState.State)
+			errorCount++
+		}
+	}
+
+	return errorCount
+}
+
+// isStepList returns TRUE if every item in the slice looks like a pipeline step
+func isStepList(items []map[string]any) bool {
+
+	if len(items) == 0 {
+		return false
+	}
+
+	for _, item := range items {
+		if _, ok := item["do"]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// findKey returns the first line (after "start") that defines the provided key
+func (validator *templateValidator) findKey(lines []string, start int, key string) int {
+	pattern := regexp.MustCompile(`^\s*"?` + regexp.QuoteMeta(key) + `"?\s*:`)
+	return findLine(lines, start, pattern)
+}
+
+// findValue returns the first line (after "start") that contains the provided key and value
+func (validator *templateValidator) findValue(lines []string, start int, key string, value string) int {
+	pattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(key) + `"?\s*:.*["\s\[,]` + regexp.QuoteMeta(value) + `["\s\],]`)
+	if result := findLine(lines, start, pattern); result > 0 {
+		return result
+	}
+	return validator.findKey(lines, start, key)
+}
+
+// findStep returns the line that contains the provided step.  It searches from the
+// cursor first, then falls back to the beginning of the action.
+func (validator *templateValidator) findStep(lines []string, cursor int, actionLine int, name string) int {
+
+	if name == "" {
+		return cursor
+	}
+
+	pattern := regexp.MustCompile(`\bdo"?\s*:\s*"?` + regexp.QuoteMeta(name) + `("|\s|,|}|$)`)
+
+	if cursor > actionLine {
+		if result := findLine(lines, cursor, pattern); result > 0 {
+			return result
+		}
+	}
+
+	if result := findLine(lines, actionLine-1, pattern); result > 0 {
+		return result
+	}
+
+	return actionLine
+}
+
+// findLine returns the (1-based) number of the first line after "start" that matches the pattern.
+// It returns zero if no line matches.
+func findLine(lines []string, start int, pattern *regexp.Regexp) int {
+
+	if start < 0 {
+		start = 0
+	}
+
+	for index := start; index < len(lines); index++ {
+		if pattern.MatchString(lines[index]) {
+			return index + 1
+		}
+	}
+
+	return 0
+}
+
+// definitionFilename returns the name of the definition file for a definition type
+func definitionFilename(filesystem fs.FS, definitionType string) string {
+
+	var basename string
+
+	switch definitionType {
+	case DefinitionCatalog:
+		basename = "catalog"
+	case DefinitionEmail:
+		basename = "email"
+	case DefinitionRegistration:
+		basename = "registration"
+	case DefinitionTheme:
+		basename = "theme"
+	case DefinitionWidget:
+		basename = "widget"
+	default:
+		basename = "template"
+	}
+
+	if _, err := fs.Stat(filesystem, basename+".hjson"); err == nil {
+		return basename + ".hjson"
+	}
+
+	return basename + ".json"
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"html/template"
+	"testing"
+	"testing/fstest"
+
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+	"github.com/stretchr/testify/require"
+)
+
+func testTemplateValidator() *Template {
+
+	filesystem := fstest.MapFS{
+		"_embed/reference/base/template.hjson": {Data: []byte(`{
+	templateId:"base"
+	templateRole:"folder"
+	model:"stream"
+	states: {
+		default: {label:"Default"}
+	}
+	roles: {
+		viewer: {label:"Viewer"}
+	}
+}`)},
+		"_embed/target/good/template.hjson": {Data: []byte(`{
+	templateId:"good"
+	extends:["base"]
+	containedBy:["folder"]
+	actions: {
+		view: {roles:["viewer"], do:"view-html"}
+		publish: {
+			states:["default"]
+			steps:[
+				{do:"set-state", state:"default"}
+				{do:"save-and-publish"}
+			]
+		}
+	}
+	transitions: [
+		{from:["default"], to:"default", roles:["viewer"], guard:"{{.IsPublished}}"}
+	]
+}`)},
+		"_embed/target/good/view.html": {Data: []byte(`<div>{{.Label}}</div>`)},
+		"_embed/target/bad/template.hjson": {Data: []byte(`{
+	templateId:"bad"
+	extends:["base", "missing"]
+	containedBy:["nowhere"]
+	actions: {
+		view: {
+			roles:["ghost"]
+			stateRoles: {
+				archived: ["viewer"]
+			}
+			steps:[
+				{do:"view-htm"}
+				{do:"as-modal", steps:[
+					{do:"set-state", state:"archived"}
+					{do:"edit-connection"}
+				]}
+			]
+		}
+	}
+	states: {
+		review: {
+			onEnter: [{do:"send-emial"}]
+		}
+	}
+	transitions: [
+		{from:["default"], to:"archived", roles:["ghost"]}
+		{from:["review"]}
+		{to:"review", guard:"{{.Missing"}
+	]
+}`)},
+		"_embed/target/bad/view.html":         {Data: []byte("<div>\n{{nosuchfunc .Label}}\n</div>")},
+		"_embed/target/broken/template.hjson": {Data: []byte("{\n\ttemplateId:\"broken\"\n\tstates: {\n\t\t,\n\t}\n}")},
+	}
+
+	return NewTemplate(NewFilesystem(filesystem), nil, nil, nil, nil, nil, template.FuncMap{}, nil)
+}
+
+func TestTemplateValidate_Good(t *testing.T) {
+
+	service := testTemplateValidator()
+
+	diagnostics := service.Validate(
+		sliceof.Object[mapof.String]{{"adapter": "EMBED", "location": "reference"}},
+		sliceof.NewObject[mapof.String](),
+	)
+
+	require.Empty(t, diagnostics)
+}
+
+func TestTemplateValidate_Bad(t *testing.T) {
+
+	service := testTemplateValidator()
+
+	diagnostics := service.Validate(
+		sliceof.Object[mapof.String]{{"adapter": "EMBED", "location": "target"}},
+		sliceof.Object[mapof.String]{{"adapter": "EMBED", "location": "reference"}},
+	)
+
+	results := make([]string, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		results = append(results, diagnostic.String())
+	}
+
+	require.Equal(t, []string{
+		"target/bad/template.hjson:3: error: extends unknown template \"missing\"",
+		"target/bad/template.hjson:4: warning: containedBy \"nowhere\" does not match any templateRole",
+		"target/bad/template.hjson:7: error: action \"view\" uses undeclared role \"ghost\"",
+		"target/bad/template.hjson:9: error: action \"view\" has stateRoles for undeclared state \"archived\"",
+		"target/bad/template.hjson:12: error: unrecognized step \"view-htm\"",
+		"target/bad/template.hjson:14: error: step \"set-state\" uses undeclared state \"archived\"",
+		"target/bad/template.hjson:15: error: step \"edit-connection\" requires a \"domain\" model, but is used with \"stream\"",
+		"target/bad/template.hjson:22: error: unrecognized step \"send-emial\"",
+		"target/bad/template.hjson:25: error: transition is missing a \"to\" state",
+		"target/bad/template.hjson:26: error: transition uses undeclared state \"archived\"",
+		"target/bad/template.hjson:26: error: transition uses undeclared role \"ghost\"",
+		"target/bad/template.hjson:28: error: invalid guard: template: :1: unclosed action",
+		"target/bad/view.html:2: error: template: view:2: function \"nosuchfunc\" not defined",
+		"target/broken/template.hjson:4: error: syntax error: Found ',' where a key name was expected (check your syntax or use quotes if the key name includes {}[],: or whitespace) at line 4,3",
+	}, results)
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/EmissarySocial/emissary/tools/i18n"
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/hjson/hjson-go/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Translation service manages the message catalogs that translate templates,
+// emails, and system messages into each user's preferred language.
+// Catalogs are loaded by the Template service, along with all other template
+// definitions, so every template package can include its own catalog.
+type Translation struct {
+	translator     *i18n.Translator // Live set of translations
+	translatorPrep *i18n.Translator // Translations that are being loaded
+	mutex          sync.RWMutex     // Mutex that locks access to the live translations
+}
+
+// NewTranslation returns a fully initialized Translation service
+func NewTranslation() Translation {
+	return Translation{
+		translator:     i18n.NewTranslator(i18n.DefaultLocale),
+		translatorPrep: i18n.NewTranslator(i18n.DefaultLocale),
+	}
+}
+
+/******************************************
+ * Loading Catalogs
+ ******************************************/
+
+// prepare resets the prep area before all catalogs are (re)loaded
+func (service *Translation) prepare() {
+	service.translatorPrep = i18n.NewTranslator(i18n.DefaultLocale)
+}
+
+// Add loads a catalog into the prep area.  Every JSON/HJSON file in the catalog's
+// folder (other than the "catalog" definition) contains the messages for the
+// locale that is named by the file, such as "en.hjson" or "pt-BR.hjson".
+func (service *Translation) Add(filesystem fs.FS, definition []byte) error {
+
+	const location = "service.Translation.Add"
+
+	// Unmarshal the definition file
+	catalog := mapof.NewAny()
+	if err := hjson.Unmarshal(definition, &catalog); err != nil {
+		return derp.Wrap(err, location, "Error loading catalog definition")
+	}
+
+	catalogID := catalog.GetString("catalogId")
+	log.Debug().Msg("Translation Service: adding " + catalogID)
+
+	files, err := fs.ReadDir(filesystem, ".")
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error reading catalog folder", catalogID)
+	}
+
+	for _, file := range files {
+
+		locale, ok := catalogLocale(file)
+
+		if !ok {
+			continue
+		}
+
+		content, err := fs.ReadFile(filesystem, file.Name())
+
+		if err != nil {
+			return derp.Wrap(err, location, "Error reading catalog file", catalogID, file.Name())
+		}
+
+		messages := make(map[string]any)
+		if err := hjson.Unmarshal(content, &messages); err != nil {
+			return derp.Wrap(err, location, "Error parsing catalog file", catalogID, file.Name())
+		}
+
+		service.translatorPrep.AddMap(locale, messages)
+	}
+
+	return nil
+}
+
+// commit replaces the live translations with the prep area
+func (service *Translation) commit() {
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	service.translator = service.translatorPrep
+	service.translatorPrep = i18n.NewTranslator(i18n.DefaultLocale)
+}
+
+/******************************************
+ * Translation Methods
+ ******************************************/
+
+// Translate returns the text for a key in the requested locale.  Parameters are
+// alternating names and values (or a single map) that are used to fill in {name}
+// placeholders.  The "count" parameter also selects the correct plural form.
+func (service *Translation) Translate(locale string, key string, params ...any) string {
+	return service.get().Translate(locale, key, params...)
+}
+
+// Lookup returns the text for a key in the requested locale, and TRUE
+// if the key is defined in any catalog.
+func (service *Translation) Lookup(locale string, key string, params ...any) (string, bool) {
+
+	if _, ok := service.get().Lookup(locale, key); !ok {
+		return "", false
+	}
+
+	return service.Translate(locale, key, params...), true
+}
+
+// ErrorMessage returns the message that is shown to users for an error.  Messages that
+// are catalog keys (like "twoFactor.locked") are translated into the requested locale.
+// Errors without a message use the standard text for their status code, and all
+// other messages are returned unchanged so that specific errors still reach the user.
+func (service *Translation) ErrorMessage(locale string, err error) string {
+
+	message := derp.Message(err)
+
+	if message == "" {
+		message = "error." + strconv.Itoa(derp.ErrorCode(err))
+	}
+
+	if translated, ok := service.Lookup(locale, message); ok {
+		return translated
+	}
+
+	return message
+}
+
+// Negotiate returns the best available locale for a list of preferences, such
+// as a User's saved locale followed by the request's Accept-Language header.
+func (service *Translation) Negotiate(preferences ...string) string {
+	return service.get().Negotiate(preferences...)
+}
+
+// Locales returns all locales that have at least one translation
+func (service *Translation) Locales() []string {
+	return service.get().Locales()
+}
+
+// get returns the live translations
+func (service *Translation) get() *i18n.Translator {
+	service.mutex.RLock()
+	defer service.mutex.RUnlock()
+	return service.translator
+}
+
+// catalogLocale returns the locale that is defined by a file in a catalog folder
+func catalogLocale(file fs.DirEntry) (string, bool) {
+
+	if file.IsDir() {
+		return "", false
+	}
+
+	name := file.Name()
+	extension := ""
+
+	switch {
+	case strings.HasSuffix(name, ".hjson"):
+		extension = ".hjson"
+	case strings.HasSuffix(name, ".json"):
+		extension = ".json"
+	default:
+		return "", false
+	}
+
+	name = strings.TrimSuffix(name, extension)
+
+	if name == "catalog" {
+		return "", false
+	}
+
+	locale := i18n.NormalizeLocale(name)
+	return locale, locale != ""
+}
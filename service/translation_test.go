@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/benpate/derp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslation(t *testing.T) {
+
+	core := fstest.MapFS{
+		"catalog.hjson": {Data: []byte(`{catalogId:"core"}`)},
+		"en.hjson":      {Data: []byte(`{signin: {title: "Sign In"}, stream: {replies: {one: "{count} reply", other: "{count} replies"}}}`)},
+		"fr.hjson":      {Data: []byte(`{signin: {title: "Connexion"}}`)},
+		"notes.txt":     {Data: []byte(`not a locale`)},
+	}
+
+	custom := fstest.MapFS{
+		"catalog.hjson": {Data: []byte(`{catalogId:"custom"}`)},
+		"en.json":       {Data: []byte(`{"signin": {"title": "Log In"}}`)},
+		"pt_br.hjson":   {Data: []byte(`{signin: {title: "Entrar"}}`)},
+	}
+
+	translation := NewTranslation()
+	translation.prepare()
+	require.Nil(t, translation.Add(core, core["catalog.hjson"].Data))
+	require.Nil(t, translation.Add(custom, custom["catalog.hjson"].Data))
+
+	// Nothing is available until the catalogs are committed
+	require.Equal(t, "signin.title", translation.Translate("en", "signin.title"))
+
+	translation.commit()
+
+	// Later catalogs override earlier ones
+	require.Equal(t, "Log In", translation.Translate("en", "signin.title"))
+	require.Equal(t, "Connexion", translation.Translate("fr", "signin.title"))
+	require.Equal(t, "Entrar", translation.Translate("pt-BR", "signin.title"))
+
+	// Missing translations fall back to the default locale
+	require.Equal(t, "3 replies", translation.Translate("fr", "stream.replies", "count", 3))
+
+	// Lookup reports missing keys
+	_, ok := translation.Lookup("en", "missing.key")
+	require.False(t, ok)
+
+	require.Equal(t, []string{"en", "fr", "pt-BR"}, translation.Locales())
+	require.Equal(t, "fr", translation.Negotiate("", "fr-CA,fr;q=0.9,en;q=0.5"))
+}
+
+func TestTranslation_InvalidCatalog(t *testing.T) {
+
+	filesystem := fstest.MapFS{
+		"en.hjson": {Data: []byte(`{signin: `)},
+	}
+
+	translation := NewTranslation()
+	translation.prepare()
+	require.NotNil(t, translation.Add(filesystem, []byte(`{catalogId:"broken"}`)))
+}
+
+func TestTranslation_ErrorMessage(t *testing.T) {
+
+	core := fstest.MapFS{
+		"catalog.hjson": {Data: []byte(`{catalogId:"core"}`)},
+		"en.hjson":      {Data: []byte(`{error: {400: "Bad Request", 500: "Internal Server Error"}, twoFactor: {locked: "Too many attempts"}}`)},
+		"fr.hjson":      {Data: []byte(`{error: {400: "Requête invalide"}, twoFactor: {locked: "Trop de tentatives"}}`)},
+	}
+
+	translation := NewTranslation()
+	translation.prepare()
+	require.Nil(t, translation.Add(core, core["catalog.hjson"].Data))
+	translation.commit()
+
+	// Specific messages are shown to the user, unchanged
+	err := derp.NewBadRequestError("test", "Email address is already in use")
+	require.Equal(t, "Email address is already in use", translation.ErrorMessage("fr", err))
+
+	// Messages that are catalog keys are translated
+	err = derp.NewForbiddenError("test", "twoFactor.locked")
+	require.Equal(t, "Trop de tentatives", translation.ErrorMessage("fr", err))
+
+	// Errors without a message use the standard text for their status code
+	err = derp.NewBadRequestError("test", "")
+	require.Equal(t, "Requête invalide", translation.ErrorMessage("fr", err))
+
+	err = derp.NewInternalError("test", "")
+	require.Equal(t, "Internal Server Error", translation.ErrorMessage("fr", err))
+}
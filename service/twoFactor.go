@@ -0,0 +1,432 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/id"
+	"github.com/EmissarySocial/emissary/tools/secretbox"
+	"github.com/EmissarySocial/emissary/tools/totp"
+	"github.com/benpate/derp"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TwoFactor service manages second-factor authentication (TOTP codes, recovery
+// codes, and WebAuthn passkeys) and the short-lived challenges that track a
+// sign-in while it is in progress.
+type TwoFactor struct {
+	userService      *User
+	jwtService       *JWT
+	keyEncryptingKey string // Key used to seal TOTP secrets before they are stored in the database
+	host             string
+	hostname         string
+	label            string
+}
+
+// NewTwoFactor returns a fully initialized TwoFactor service
+func NewTwoFactor(userService *User, jwtService *JWT, keyEncryptingKey string, host string, hostname string, label string) TwoFactor {
+	return TwoFactor{
+		userService:      userService,
+		jwtService:       jwtService,
+		keyEncryptingKey: keyEncryptingKey,
+		host:             host,
+		hostname:         hostname,
+		label:            label,
+	}
+}
+
+// LoadUser loads the User referenced by a challenge
+func (service TwoFactor) LoadUser(userID primitive.ObjectID, user *model.User) error {
+
+	if err := service.userService.LoadByID(userID, user); err != nil {
+		return derp.Wrap(err, "service.TwoFactor.LoadUser", "Error loading user", userID)
+	}
+
+	return nil
+}
+
+/******************************************
+ * Challenge Methods
+ ******************************************/
+
+// NewChallengeToken returns a signed token for the provided challenge
+func (service TwoFactor) NewChallengeToken(challenge model.TwoFactorChallenge) (string, error) {
+
+	result, err := service.jwtService.NewToken(challenge)
+
+	if err != nil {
+		return "", derp.Wrap(err, "service.TwoFactor.NewChallengeToken", "Error signing challenge")
+	}
+
+	return result, nil
+}
+
+// ParseChallengeToken validates a signed challenge token, and confirms that
+// it was issued for the expected purpose.
+func (service TwoFactor) ParseChallengeToken(token string, purpose string) (model.TwoFactorChallenge, error) {
+
+	const location = "service.TwoFactor.ParseChallengeToken"
+
+	challenge := model.TwoFactorChallenge{}
+
+	if err := service.jwtService.ParseClaims(token, &challenge); err != nil {
+		return model.TwoFactorChallenge{}, derp.Wrap(err, location, "Invalid challenge token")
+	}
+
+	if challenge.Purpose != purpose {
+		return model.TwoFactorChallenge{}, derp.NewBadRequestError(location, "Challenge token has the wrong purpose", challenge.Purpose, purpose)
+	}
+
+	return challenge, nil
+}
+
+/******************************************
+ * TOTP Methods
+ ******************************************/
+
+// BeginTOTP returns the User's pending TOTP secret so that it can be displayed
+// during enrollment.  If the User does not have one yet, then a new secret is
+// generated, sealed, and saved.
+func (service TwoFactor) BeginTOTP(user *model.User) (string, error) {
+
+	const location = "service.TwoFactor.BeginTOTP"
+
+	if user.TwoFactor.TOTPSecret != "" {
+		return service.totpSecret(user)
+	}
+
+	secret, err := totp.NewSecret()
+
+	if err != nil {
+		return "", derp.Wrap(err, location, "Error generating TOTP secret")
+	}
+
+	sealed, err := secretbox.Seal(service.keyEncryptingKey, secret)
+
+	if err != nil {
+		return "", derp.Wrap(err, location, "Error sealing TOTP secret")
+	}
+
+	user.TwoFactor.BeginTOTP(sealed)
+
+	if err := service.userService.Save(user, "Two-factor enrollment started"); err != nil {
+		return "", derp.Wrap(err, location, "Error saving user")
+	}
+
+	return secret, nil
+}
+
+// EnableTOTP confirms the User's pending TOTP secret.  It returns TRUE if the
+// code was valid.  The caller is responsible for saving the User.
+func (service TwoFactor) EnableTOTP(user *model.User, code string) (bool, error) {
+
+	if user.TwoFactor.TOTPSecret == "" {
+		return false, nil
+	}
+
+	secret, err := service.totpSecret(user)
+
+	if err != nil {
+		return false, derp.Wrap(err, "service.TwoFactor.EnableTOTP", "Error reading TOTP secret")
+	}
+
+	return user.TwoFactor.EnableTOTP(secret, code, time.Now()), nil
+}
+
+// totpSecret returns the unsealed value of the User's TOTP secret
+func (service TwoFactor) totpSecret(user *model.User) (string, error) {
+
+	secret, err := secretbox.Open(service.keyEncryptingKey, user.TwoFactor.TOTPSecret)
+
+	if err != nil {
+		return "", derp.Wrap(err, "service.TwoFactor.totpSecret", "Error unsealing TOTP secret", user.UserID)
+	}
+
+	return secret, nil
+}
+
+/******************************************
+ * Code Methods
+ ******************************************/
+
+// ValidateCode checks a TOTP code or recovery code for the provided User.
+// Successful codes are consumed, and the User is saved.  After too many invalid
+// codes, all codes are rejected until the lockout period has passed.
+func (service TwoFactor) ValidateCode(user *model.User, code string) (bool, error) {
+
+	const location = "service.TwoFactor.ValidateCode"
+
+	now := time.Now()
+
+	// RULE: Stop accepting guesses after too many invalid codes
+	if user.TwoFactor.IsLocked(now) {
+		return false, derp.New(http.StatusTooManyRequests, location, "Too many invalid codes", user.UserID)
+	}
+
+	var secret string
+
+	if user.TwoFactor.TOTPEnabled {
+
+		var err error
+		secret, err = service.totpSecret(user)
+
+		if err != nil {
+			return false, derp.Wrap(err, location, "Error reading TOTP secret")
+		}
+	}
+
+	var note string
+
+	switch {
+
+	case user.TwoFactor.ValidateTOTP(secret, code, now):
+		note = "Two-factor code used"
+
+	case user.TwoFactor.UseRecoveryCode(code):
+		note = "Two-factor recovery code used"
+
+	default:
+		user.TwoFactor.AddFailure(now)
+
+		if err := service.userService.Save(user, "Invalid two-factor code"); err != nil {
+			return false, derp.Wrap(err, location, "Error saving user")
+		}
+
+		return false, nil
+	}
+
+	user.TwoFactor.ResetFailures()
+
+	if err := service.userService.Save(user, note); err != nil {
+		return false, derp.Wrap(err, location, "Error saving user")
+	}
+
+	return true, nil
+}
+
+/******************************************
+ * Passkey Methods
+ ******************************************/
+
+// BeginRegistration starts the registration of a new passkey for the provided User
+func (service TwoFactor) BeginRegistration(user *model.User) (*protocol.CredentialCreation, *webauthn.SessionData, error) {
+
+	const location = "service.TwoFactor.BeginRegistration"
+
+	webAuthn, err := service.webAuthn()
+
+	if err != nil {
+		return nil, nil, derp.Wrap(err, location, "Error configuring WebAuthn")
+	}
+
+	// Prevent the same authenticator from being registered twice
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.TwoFactor.Passkeys))
+
+	for _, credential := range user.WebAuthnCredentials() {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	// Passkeys must be discoverable so that they can be used without a username
+	creation, session, err := webAuthn.BeginRegistration(
+		user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+
+	if err != nil {
+		return nil, nil, derp.Wrap(err, location, "Error beginning registration")
+	}
+
+	return creation, session, nil
+}
+
+// FinishRegistration validates the authenticator's response, and saves the new passkey
+func (service TwoFactor) FinishRegistration(user *model.User, session webauthn.SessionData, request *http.Request, label string) error {
+
+	const location = "service.TwoFactor.FinishRegistration"
+
+	webAuthn, err := service.webAuthn()
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error configuring WebAuthn")
+	}
+
+	credential, err := webAuthn.FinishRegistration(user, session, request)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid passkey registration", derp.WithCode(http.StatusBadRequest))
+	}
+
+	if label == "" {
+		label = "Passkey"
+	}
+
+	user.TwoFactor.AddPasskey(model.NewPasskey(credential, label))
+
+	if err := service.userService.Save(user, "Passkey registered"); err != nil {
+		return derp.Wrap(err, location, "Error saving user")
+	}
+
+	return nil
+}
+
+// BeginLogin starts a passkey challenge for a User who has already entered their password
+func (service TwoFactor) BeginLogin(user *model.User) (*protocol.CredentialAssertion, *webauthn.SessionData, error) {
+
+	const location = "service.TwoFactor.BeginLogin"
+
+	webAuthn, err := service.webAuthn()
+
+	if err != nil {
+		return nil, nil, derp.Wrap(err, location, "Error configuring WebAuthn")
+	}
+
+	assertion, session, err := webAuthn.BeginLogin(user)
+
+	if err != nil {
+		return nil, nil, derp.Wrap(err, location, "Error beginning login")
+	}
+
+	return assertion, session, nil
+}
+
+// FinishLogin validates the authenticator's response for a User who has already entered their password
+func (service TwoFactor) FinishLogin(user *model.User, session webauthn.SessionData, request *http.Request) error {
+
+	const location = "service.TwoFactor.FinishLogin"
+
+	webAuthn, err := service.webAuthn()
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error configuring WebAuthn")
+	}
+
+	credential, err := webAuthn.FinishLogin(user, session, request)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid passkey", derp.WithCode(http.StatusForbidden))
+	}
+
+	return service.usePasskey(user, credential)
+}
+
+// BeginPasskeyLogin starts a passwordless sign-in using any passkey registered on this domain
+func (service TwoFactor) BeginPasskeyLogin() (*protocol.CredentialAssertion, *webauthn.SessionData, error) {
+
+	const location = "service.TwoFactor.BeginPasskeyLogin"
+
+	webAuthn, err := service.webAuthn()
+
+	if err != nil {
+		return nil, nil, derp.Wrap(err, location, "Error configuring WebAuthn")
+	}
+
+	// Passwordless sign-in replaces both factors, so user verification (PIN/biometric) is required
+	assertion, session, err := webAuthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+
+	if err != nil {
+		return nil, nil, derp.Wrap(err, location, "Error beginning login")
+	}
+
+	return assertion, session, nil
+}
+
+// FinishPasskeyLogin validates the authenticator's response for a passwordless sign-in,
+// and populates the User who owns the passkey.
+func (service TwoFactor) FinishPasskeyLogin(session webauthn.SessionData, request *http.Request, user *model.User) error {
+
+	const location = "service.TwoFactor.FinishPasskeyLogin"
+
+	webAuthn, err := service.webAuthn()
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error configuring WebAuthn")
+	}
+
+	// Load the User identified by the authenticator's user handle
+	findUser := func(_ []byte, userHandle []byte) (webauthn.User, error) {
+
+		userID := id.FromBytes(userHandle)
+
+		if userID.IsZero() {
+			return nil, derp.NewForbiddenError(location, "Invalid user handle")
+		}
+
+		if err := service.userService.LoadByID(userID, user); err != nil {
+			return nil, derp.Wrap(err, location, "Error loading user", userID)
+		}
+
+		return user, nil
+	}
+
+	credential, err := webAuthn.FinishDiscoverableLogin(findUser, session, request)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid passkey", derp.WithCode(http.StatusForbidden))
+	}
+
+	return service.usePasskey(user, credential)
+}
+
+// RemovePasskey removes a passkey from the provided User
+func (service TwoFactor) RemovePasskey(user *model.User, credentialID string) error {
+
+	const location = "service.TwoFactor.RemovePasskey"
+
+	if !user.TwoFactor.RemovePasskey(credentialID) {
+		return derp.NewNotFoundError(location, "Passkey not found", credentialID)
+	}
+
+	if err := service.userService.Save(user, "Passkey removed"); err != nil {
+		return derp.Wrap(err, location, "Error saving user")
+	}
+
+	return nil
+}
+
+// usePasskey records a successful passkey sign-in on the User's record
+func (service TwoFactor) usePasskey(user *model.User, credential *webauthn.Credential) error {
+
+	const location = "service.TwoFactor.usePasskey"
+
+	// RULE: Reject authenticators whose signature counter has gone backwards
+	if credential.Authenticator.CloneWarning {
+		return derp.NewForbiddenError(location, "Passkey may have been cloned", user.UserID)
+	}
+
+	passkey, ok := user.TwoFactor.Passkey(model.PasskeyCredentialID(credential.ID))
+
+	if !ok {
+		return derp.NewForbiddenError(location, "Passkey not found", user.UserID)
+	}
+
+	passkey.Update(credential)
+	user.TwoFactor.ResetFailures()
+
+	if err := service.userService.Save(user, "Passkey used"); err != nil {
+		return derp.Wrap(err, location, "Error saving user")
+	}
+
+	return nil
+}
+
+// webAuthn returns a WebAuthn relying party for this domain
+func (service TwoFactor) webAuthn() (*webauthn.WebAuthn, error) {
+
+	displayName := service.label
+
+	if displayName == "" {
+		displayName = service.hostname
+	}
+
+	return webauthn.New(&webauthn.Config{
+		RPID:          service.hostname,
+		RPDisplayName: displayName,
+		RPOrigins:     []string{service.host},
+	})
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/EmissarySocial/emissary/tools/totp"
+	"github.com/benpate/derp"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTwoFactor returns a TwoFactor service, and a User who has been saved in its (in-memory) collection
+func newTestTwoFactor(t *testing.T) (TwoFactor, *model.User) {
+
+	userService := &User{
+		collection:     newTestCollection(),
+		webhookService: &Webhook{collection: newTestCollection()},
+	}
+
+	user := model.NewUser()
+	user.Username = "alice"
+	user.EmailAddress = "alice@example.com"
+	require.Nil(t, userService.collection.Save(&user, "test"))
+
+	return NewTwoFactor(userService, nil, "12345678901234567890123456789012", "https://example.com", "example.com", "Example"), &user
+}
+
+func TestTwoFactor_SealedTOTP(t *testing.T) {
+
+	twoFactorService, user := newTestTwoFactor(t)
+
+	secret, err := twoFactorService.BeginTOTP(user)
+	require.Nil(t, err)
+	require.NotEmpty(t, secret)
+
+	// The secret is never stored in plaintext
+	require.NotEmpty(t, user.TwoFactor.TOTPSecret)
+	require.False(t, strings.Contains(user.TwoFactor.TOTPSecret, secret))
+
+	saved := model.NewUser()
+	require.Nil(t, twoFactorService.LoadUser(user.UserID, &saved))
+	require.Equal(t, user.TwoFactor.TOTPSecret, saved.TwoFactor.TOTPSecret)
+
+	// Pending secrets are displayed again (not replaced) until they are confirmed
+	again, err := twoFactorService.BeginTOTP(&saved)
+	require.Nil(t, err)
+	require.Equal(t, secret, again)
+
+	// Confirm the secret, then sign in with the next code
+	now := time.Now()
+	code, err := totp.Code(secret, totp.Step(now))
+	require.Nil(t, err)
+
+	ok, err := twoFactorService.EnableTOTP(&saved, code)
+	require.Nil(t, err)
+	require.True(t, ok)
+
+	next, err := totp.Code(secret, totp.Step(now)+1)
+	require.Nil(t, err)
+
+	ok, err = twoFactorService.ValidateCode(&saved, next)
+	require.Nil(t, err)
+	require.True(t, ok)
+
+	// Secrets sealed with a different key cannot be used
+	otherService := NewTwoFactor(twoFactorService.userService, nil, "a-different-key-encrypting-key!!", "https://example.com", "example.com", "Example")
+	_, err = otherService.ValidateCode(&saved, next)
+	require.NotNil(t, err)
+}
+
+func TestTwoFactor_Lockout(t *testing.T) {
+
+	twoFactorService, user := newTestTwoFactor(t)
+
+	codes, err := user.TwoFactor.NewRecoveryCodes()
+	require.Nil(t, err)
+
+	// Guess until the User is locked out
+	for range model.TwoFactorMaxAttempts {
+		ok, err := twoFactorService.ValidateCode(user, "00000-00000")
+		require.Nil(t, err)
+		require.False(t, ok)
+	}
+
+	// Even valid codes are rejected during the lockout
+	_, err = twoFactorService.ValidateCode(user, codes[0])
+	require.Equal(t, http.StatusTooManyRequests, derp.ErrorCode(err))
+
+	// The lockout is saved, so signing in again (with a valid password) does not reset it
+	saved := model.NewUser()
+	require.Nil(t, twoFactorService.LoadUser(user.UserID, &saved))
+	_, err = twoFactorService.ValidateCode(&saved, codes[0])
+	require.Equal(t, http.StatusTooManyRequests, derp.ErrorCode(err))
+
+	// After the lockout period, valid codes are accepted and the failures are cleared
+	saved.TwoFactor.FailedAt = time.Now().Add(-model.TwoFactorLockout - time.Second).Unix()
+
+	ok, err := twoFactorService.ValidateCode(&saved, codes[0])
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.Equal(t, 0, saved.TwoFactor.FailedCount)
+}
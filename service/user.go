@@ -26,6 +26,7 @@ import (
 	"github.com/benpate/rosetta/schema"
 	"github.com/benpate/rosetta/slice"
 	"github.com/benpate/rosetta/sliceof"
+	"github.com/benpate/steranko"
 	"github.com/benpate/turbine/queue"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -219,6 +220,26 @@ func (service *User) Save(user *model.User, note string) error {
 	return nil
 }
 
+// SetPassword hashes a new password for the provided User, and saves it to the database
+func (service *User) SetPassword(user *model.User, hasher steranko.PasswordHasher, password string) error {
+
+	const location = "service.User.SetPassword"
+
+	hashedValue, err := hasher.HashPassword(password)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Error hashing password")
+	}
+
+	user.SetPassword(hashedValue)
+
+	if err := service.Save(user, "Updated Password"); err != nil {
+		return derp.Wrap(err, location, "Error saving user")
+	}
+
+	return nil
+}
+
 // Delete removes an User from the database (virtual delete)
 func (service *User) Delete(user *model.User, note string) error {
 
@@ -512,6 +533,22 @@ func (service *User) CalcRuleCount(userID primitive.ObjectID) {
 	}
 }
 
+// AddStorageUsed adds (or subtracts) a number of bytes from the storage used by a User
+func (service *User) AddStorageUsed(userID primitive.ObjectID, delta int64) error {
+
+	if err := queries.IncrementStorageUsed(service.collection, exp.Equal("_id", userID), delta); err != nil {
+		return derp.Wrap(err, "service.User.AddStorageUsed", "Error updating storage used", userID, delta)
+	}
+
+	return nil
+}
+
+// QueryByStorageUsed returns the Users who are using the most storage, largest first
+func (service *User) QueryByStorageUsed(maxRows int64) ([]model.User, error) {
+	criteria := exp.GreaterThan("storageUsed", 0)
+	return service.Query(criteria, option.SortDesc("storageUsed"), option.MaxRows(maxRows))
+}
+
 func (service *User) SetOwner(owner config.Owner) error {
 
 	// If there is no owner data, then do not create/update an owner record.
@@ -86,8 +86,11 @@ func (service *User) ActivityPubActor(userID primitive.ObjectID, withFollowers b
 		return outbox.Actor{}, derp.Wrap(err, location, "Error extracting private key", encryptionKey)
 	}
 
+	// Deliveries are collapsed into each remote server's shared inbox, when available
+	client := NewSharedInboxClient(service.activityStream)
+
 	// Return the ActivityPub Actor
-	actor := outbox.NewActor(service.ActivityPubURL(userID), privateKey, outbox.WithClient(service.activityStream)) // TODO: Restore Queue:: , outbox.WithQueue(service.queue))
+	actor := outbox.NewActor(service.ActivityPubURL(userID), privateKey, outbox.WithClient(client)) // TODO: Restore Queue:: , outbox.WithQueue(service.queue))
 
 	// Populate the Actor's ActivityPub Followers, if requested
 	if withFollowers {
@@ -101,7 +104,8 @@ func (service *User) ActivityPubActor(userID primitive.ObjectID, withFollowers b
 
 		// Get a filter to prevent sending to "Blocked" followers
 		ruleFilter := service.ruleService.Filter(userID, WithBlocksOnly())
-		followerIDs := ruleFilter.ChannelSend(followers)
+		allowedFollowers := ruleFilter.ChannelSend(followers)
+		followerIDs := client.Followers(allowedFollowers)
 
 		// Add the channel of follower IDs to the Actor
 		actor.With(outbox.WithFollowers(followerIDs))
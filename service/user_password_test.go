@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/steranko/plugin/hash"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUser_SetPassword(t *testing.T) {
+
+	userService := &User{
+		collection:     newTestCollection(),
+		webhookService: &Webhook{collection: newTestCollection()},
+	}
+
+	user := model.NewUser()
+	user.Username = "alice"
+	user.EmailAddress = "alice@example.com"
+	user.SetPassword("old-hash")
+	require.Nil(t, userService.collection.Save(&user, "test"))
+
+	hasher := hash.BCrypt(4)
+	require.Nil(t, userService.SetPassword(&user, hasher, "correct horse battery staple"))
+
+	// The new password is hashed, and saved to the database
+	saved := model.NewUser()
+	require.Nil(t, userService.LoadByID(user.UserID, &saved))
+	require.NotEqual(t, "correct horse battery staple", saved.Password)
+
+	ok, _ := hasher.CompareHashedPassword(saved.Password, "correct horse battery staple")
+	require.True(t, ok)
+
+	ok, _ = hasher.CompareHashedPassword(saved.Password, "old-password")
+	require.False(t, ok)
+}
@@ -187,6 +187,9 @@ func loadHTMLTemplateFromFilesystem(filesystem fs.FS, t *template.Template, func
 	return nil
 }
 
+// DefinitionCatalog marks a filesystem that contains a message Catalog definition.
+const DefinitionCatalog = "CATALOG"
+
 // DefinitionEmail marks a filesystem that contains an Email definition.
 const DefinitionEmail = "EMAIL"
 
@@ -230,6 +233,11 @@ func findDefinition(filesystem fs.FS) (string, []byte, error) {
 		return DefinitionEmail, file, nil
 	}
 
+	// If this directory contains a "catalog.json" file, then it's a message catalog.
+	if file, err := readJSON(filesystem, "catalog"); err == nil {
+		return DefinitionCatalog, file, nil
+	}
+
 	// TODO: LOW: Add DefinitionEmail to this.  Will need a *.json file in the email directory.
 
 	return "", nil, derp.NewInternalError("service.findDefinition", "No definition file found")
@@ -40,5 +40,12 @@ func Actor(document streams.Document) map[string]any {
 		}
 	}
 
+	// Shared Inbox (used for batched delivery)
+	if sharedInbox := document.Endpoints().Get("sharedInbox").String(); sharedInbox != "" {
+		result[vocab.PropertyEndpoints] = map[string]any{
+			"sharedInbox": sharedInbox,
+		}
+	}
+
 	return result
 }
@@ -0,0 +1,24 @@
+package callurl
+
+import "time"
+
+// ContentTypeJSON is the default content type for request and response bodies
+const ContentTypeJSON = "application/json"
+
+// DefaultTimeout is used when a Request does not specify a timeout
+const DefaultTimeout = 10 * time.Second
+
+// MaxTimeout is the longest that any Request is allowed to wait
+const MaxTimeout = 60 * time.Second
+
+// MaxRedirects is the number of redirects that a Request will follow
+const MaxRedirects = 5
+
+// MaxResponseSize is the largest response body (in bytes) that will be read
+const MaxResponseSize = 1024 * 1024
+
+// PathStatus is a special path that returns the HTTP status code of the Response
+const PathStatus = "@status"
+
+// PathBody is a special path that returns the raw body of the Response
+const PathBody = "@body"
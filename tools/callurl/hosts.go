@@ -0,0 +1,40 @@
+package callurl
+
+import (
+	"strings"
+)
+
+// HostAllowed returns TRUE if the hostname matches an entry in the allowlist.
+// Entries may be exact hostnames ("api.example.com") or wildcards that match
+// any subdomain ("*.example.com").  Matching is case-insensitive.  An empty
+// allowlist does not allow any hosts.
+func HostAllowed(allowedHosts []string, hostname string) bool {
+
+	hostname = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+
+	if hostname == "" {
+		return false
+	}
+
+	for _, allowed := range allowedHosts {
+
+		allowed = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(allowed)), ".")
+
+		if allowed == "" {
+			continue
+		}
+
+		if suffix, isWildcard := strings.CutPrefix(allowed, "*."); isWildcard {
+			if strings.HasSuffix(hostname, "."+suffix) {
+				return true
+			}
+			continue
+		}
+
+		if hostname == allowed {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,174 @@
+// Package callurl sends HTTP requests on behalf of template pipelines.  Every request
+// is limited to an allowlist of hostnames (including redirects) and can only connect
+// to public IP addresses, so that templates cannot be used to reach internal services (SSRF).
+package callurl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/EmissarySocial/emissary/tools/dialer"
+	"github.com/benpate/derp"
+)
+
+// transport connects to public IP addresses only.  It is shared by all Requests.
+var transport http.RoundTripper = dialer.Transport()
+
+// Request describes a single outbound HTTP request
+type Request struct {
+	Method       string            // HTTP method to use (GET, POST, PUT, PATCH, DELETE)
+	URL          string            // Absolute URL to call
+	Body         string            // Request body (usually JSON)
+	Headers      map[string]string // Additional request headers
+	Timeout      time.Duration     // Maximum time to wait for a response
+	AllowedHosts []string          // Hostnames that this request is allowed to reach
+}
+
+// Send executes the Request and returns the parsed Response.  Responses with a
+// non-2xx status code are returned as errors.
+func (request Request) Send() (Response, error) {
+
+	const location = "callurl.Request.Send"
+
+	method := strings.ToUpper(request.Method)
+
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	if !isAllowedMethod(method) {
+		return Response{}, derp.NewBadRequestError(location, "Unsupported HTTP method", method)
+	}
+
+	// Validate the URL before sending anything
+	if err := request.validateURL(request.URL); err != nil {
+		return Response{}, derp.Wrap(err, location, "URL is not allowed", request.URL)
+	}
+
+	var body io.Reader
+
+	if request.Body != "" {
+		body = strings.NewReader(request.Body)
+	}
+
+	httpRequest, err := http.NewRequest(method, request.URL, body)
+
+	if err != nil {
+		return Response{}, derp.Wrap(err, location, "Error creating request", request.URL)
+	}
+
+	if (request.Body != "") && (request.Headers["Content-Type"] == "") {
+		httpRequest.Header.Set("Content-Type", ContentTypeJSON)
+	}
+
+	httpRequest.Header.Set("Accept", ContentTypeJSON)
+
+	for name, value := range request.Headers {
+		httpRequest.Header.Set(name, value)
+	}
+
+	// Send the request, re-validating every redirect against the allowlist
+	client := http.Client{
+		Transport: transport,
+		Timeout:   request.timeout(),
+		CheckRedirect: func(next *http.Request, via []*http.Request) error {
+
+			if len(via) >= MaxRedirects {
+				return derp.NewBadRequestError(location, "Too many redirects", request.URL)
+			}
+
+			return request.validateURL(next.URL.String())
+		},
+	}
+
+	httpResponse, err := client.Do(httpRequest)
+
+	if err != nil {
+		return Response{}, derp.Wrap(err, location, "Error sending request", request.URL, derp.WithCode(http.StatusBadGateway))
+	}
+
+	defer httpResponse.Body.Close()
+
+	// Read a limited response body
+	content, err := io.ReadAll(io.LimitReader(httpResponse.Body, MaxResponseSize))
+
+	if err != nil {
+		return Response{}, derp.Wrap(err, location, "Error reading response", request.URL, derp.WithCode(http.StatusBadGateway))
+	}
+
+	result := Response{
+		StatusCode: httpResponse.StatusCode,
+		Header:     httpResponse.Header,
+		Text:       string(content),
+	}
+
+	// Parse JSON responses (if possible)
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 {
+		var value any
+		if err := json.Unmarshal(trimmed, &value); err == nil {
+			result.Body = value
+		}
+	}
+
+	if (httpResponse.StatusCode < 200) || (httpResponse.StatusCode > 299) {
+		return result, derp.New(http.StatusBadGateway, location, "Remote server returned an error", request.URL, httpResponse.StatusCode)
+	}
+
+	return result, nil
+}
+
+// validateURL confirms that a URL uses HTTP(S) and points to an allowed host
+func (request Request) validateURL(value string) error {
+
+	const location = "callurl.Request.validateURL"
+
+	parsed, err := url.Parse(value)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid URL", value, derp.WithCode(http.StatusBadRequest))
+	}
+
+	if (parsed.Scheme != "http") && (parsed.Scheme != "https") {
+		return derp.NewBadRequestError(location, "URL must use http or https", value)
+	}
+
+	if parsed.User != nil {
+		return derp.NewBadRequestError(location, "URL must not include credentials", parsed.Redacted())
+	}
+
+	if !HostAllowed(request.AllowedHosts, parsed.Hostname()) {
+		return derp.NewForbiddenError(location, "Host is not in the allowlist for this domain", parsed.Hostname())
+	}
+
+	return nil
+}
+
+// timeout returns the request timeout, limited to a sane range
+func (request Request) timeout() time.Duration {
+
+	if request.Timeout <= 0 {
+		return DefaultTimeout
+	}
+
+	if request.Timeout > MaxTimeout {
+		return MaxTimeout
+	}
+
+	return request.Timeout
+}
+
+// isAllowedMethod returns TRUE if the HTTP method can be used by a template
+func isAllowedMethod(method string) bool {
+
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,160 @@
+package callurl
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostAllowed(t *testing.T) {
+
+	allowed := []string{"api.example.com", "*.trusted.org", ""}
+
+	require.True(t, HostAllowed(allowed, "api.example.com"))
+	require.True(t, HostAllowed(allowed, "API.Example.com."))
+	require.True(t, HostAllowed(allowed, "hooks.trusted.org"))
+	require.True(t, HostAllowed(allowed, "a.b.trusted.org"))
+
+	require.False(t, HostAllowed(allowed, "trusted.org"))
+	require.False(t, HostAllowed(allowed, "example.com"))
+	require.False(t, HostAllowed(allowed, "api.example.com.evil.net"))
+	require.False(t, HostAllowed(allowed, "eviltrusted.org"))
+	require.False(t, HostAllowed(allowed, ""))
+	require.False(t, HostAllowed(nil, "api.example.com"))
+}
+
+func TestRequest_Send(t *testing.T) {
+
+	allowLoopback(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "Bearer SECRET", r.Header.Get("Authorization"))
+		require.Equal(t, ContentTypeJSON, r.Header.Get("Content-Type"))
+		require.Equal(t, `{"name":"test"}`, string(body))
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_, _ = w.Write([]byte(`{"id":"abc123", "items":[{"name":"first"}]}`))
+	}))
+	defer server.Close()
+
+	request := Request{
+		Method:       "post",
+		URL:          server.URL + "/items",
+		Body:         `{"name":"test"}`,
+		Headers:      map[string]string{"Authorization": "Bearer SECRET"},
+		AllowedHosts: []string{hostname(server.URL)},
+	}
+
+	response, err := request.Send()
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.Get(PathStatus))
+	require.Equal(t, "abc123", response.Get("id"))
+	require.Equal(t, "first", response.Get("items.0.name"))
+	require.Nil(t, response.Get("items.1.name"))
+	require.Nil(t, response.Get("missing.value"))
+}
+
+func TestRequest_Send_NotAllowed(t *testing.T) {
+
+	allowLoopback(t)
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	// Hosts that are not in the allowlist are never contacted
+	_, err := Request{URL: server.URL, AllowedHosts: []string{"api.example.com"}}.Send()
+	require.NotNil(t, err)
+	require.False(t, called)
+
+	// Only http and https are allowed
+	_, err = Request{URL: "file:///etc/passwd", AllowedHosts: []string{""}}.Send()
+	require.NotNil(t, err)
+
+	// Only standard methods are allowed
+	_, err = Request{Method: "CONNECT", URL: server.URL, AllowedHosts: []string{hostname(server.URL)}}.Send()
+	require.NotNil(t, err)
+	require.False(t, called)
+}
+
+func TestRequest_Send_Redirect(t *testing.T) {
+
+	allowLoopback(t)
+
+	internal := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internal = true
+	}))
+	defer target.Close()
+
+	// Redirect to "localhost" so that the hostname is different from the allowed host
+	targetURL, _ := url.Parse(target.URL)
+	targetURL.Host = "localhost:" + targetURL.Port()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, targetURL.String(), http.StatusFound)
+	}))
+	defer server.Close()
+
+	// Redirects to hosts that are not in the allowlist are blocked
+	_, err := Request{URL: server.URL, AllowedHosts: []string{hostname(server.URL)}}.Send()
+	require.NotNil(t, err)
+	require.False(t, internal)
+}
+
+func TestRequest_Send_Error(t *testing.T) {
+
+	allowLoopback(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"error":"invalid"}`))
+	}))
+	defer server.Close()
+
+	response, err := Request{URL: server.URL, AllowedHosts: []string{hostname(server.URL)}}.Send()
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+	require.Equal(t, "invalid", response.Get("error"))
+}
+
+func TestRequest_Send_PrivateAddress(t *testing.T) {
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	// Allowlisted hosts cannot be reached if they resolve to a non-public address
+	_, err := Request{URL: server.URL, AllowedHosts: []string{hostname(server.URL)}}.Send()
+	require.NotNil(t, err)
+	require.False(t, called)
+
+	targetURL, _ := url.Parse(server.URL)
+	targetURL.Host = "localhost:" + targetURL.Port()
+
+	_, err = Request{URL: targetURL.String(), AllowedHosts: []string{"localhost"}}.Send()
+	require.NotNil(t, err)
+	require.False(t, called)
+}
+
+// allowLoopback lets a test connect to local httptest servers
+func allowLoopback(t *testing.T) {
+	original := transport
+	transport = http.DefaultTransport
+	t.Cleanup(func() { transport = original })
+}
+
+func hostname(value string) string {
+	parsed, _ := url.Parse(value)
+	return parsed.Hostname()
+}
@@ -0,0 +1,55 @@
+package callurl
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Response contains the result of a Request
+type Response struct {
+	StatusCode int         // HTTP status code returned by the remote server
+	Header     http.Header // HTTP headers returned by the remote server
+	Text       string      // Raw response body
+	Body       any         // Parsed response body (if it was valid JSON)
+}
+
+// Get returns a value from the response using a dot-separated path, such as "data.id"
+// or "items.0.name".  Two special paths are also available: "@status" returns the HTTP
+// status code, and "@body" returns the raw response body.  Missing values return nil.
+func (response Response) Get(path string) any {
+
+	switch path {
+	case PathStatus:
+		return response.StatusCode
+	case PathBody:
+		return response.Text
+	case "":
+		return response.Body
+	}
+
+	value := response.Body
+
+	for _, key := range strings.Split(path, ".") {
+
+		switch typed := value.(type) {
+
+		case map[string]any:
+			value = typed[key]
+
+		case []any:
+			index, err := strconv.Atoi(key)
+
+			if (err != nil) || (index < 0) || (index >= len(typed)) {
+				return nil
+			}
+
+			value = typed[index]
+
+		default:
+			return nil
+		}
+	}
+
+	return value
+}
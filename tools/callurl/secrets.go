@@ -0,0 +1,30 @@
+package callurl
+
+import (
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+)
+
+// SecretHeaders returns the values for a set of secret headers.  The headerSecrets
+// map contains header names => secret names, and each secret name must exist in
+// the Domain's secrets.  Any other name (including Domain data values) is refused,
+// so that templates cannot send arbitrary settings to a remote server.
+func SecretHeaders(headerSecrets map[string]string, secrets mapof.String) (map[string]string, error) {
+
+	const location = "callurl.SecretHeaders"
+
+	result := make(map[string]string, len(headerSecrets))
+
+	for header, name := range headerSecrets {
+
+		value, exists := secrets[name]
+
+		if !exists {
+			return nil, derp.NewForbiddenError(location, "Secret does not exist", header, name)
+		}
+
+		result[header] = value
+	}
+
+	return result, nil
+}
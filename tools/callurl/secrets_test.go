@@ -0,0 +1,26 @@
+package callurl
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/benpate/derp"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretHeaders(t *testing.T) {
+
+	secrets := mapof.String{"api_key": "SECRET"}
+
+	headers, err := SecretHeaders(map[string]string{"Authorization": "api_key"}, secrets)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"Authorization": "SECRET"}, headers)
+
+	// Names that are not secrets (like other domain settings) are refused
+	_, err = SecretHeaders(map[string]string{"Authorization": "sso_secret"}, secrets)
+	require.Equal(t, http.StatusForbidden, derp.ErrorCode(err))
+
+	_, err = SecretHeaders(map[string]string{"Authorization": "api_key"}, nil)
+	require.Equal(t, http.StatusForbidden, derp.ErrorCode(err))
+}
@@ -0,0 +1,85 @@
+package convert
+
+import (
+	"encoding/json"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/benpate/derp"
+	"github.com/gorilla/feeds"
+	"github.com/kr/jsonfeed"
+)
+
+// JsonFeedToGorillaFeed converts a JSONFeed into a gorilla/feeds Feed, which can then
+// be rendered as Atom or RSS.
+func JsonFeedToGorillaFeed(feed jsonfeed.Feed) *feeds.Feed {
+
+	result := &feeds.Feed{
+		Title:       feed.Title,
+		Description: feed.Description,
+		Link:        &feeds.Link{Href: feed.HomePageURL},
+		Items:       make([]*feeds.Item, 0, len(feed.Items)),
+	}
+
+	for _, item := range feed.Items {
+
+		resultItem := &feeds.Item{
+			Id:          item.ID,
+			Title:       item.Title,
+			Description: item.Summary,
+			Content:     item.ContentHTML,
+			Link:        &feeds.Link{Href: item.URL},
+			Created:     item.DatePublished,
+			Updated:     item.DateModified,
+		}
+
+		if item.Author != nil {
+			resultItem.Author = &feeds.Author{Name: item.Author.Name}
+		}
+
+		// Feeds are as old as their newest item
+		if item.DatePublished.After(result.Created) {
+			result.Created = item.DatePublished
+		}
+
+		result.Items = append(result.Items, resultItem)
+	}
+
+	return result
+}
+
+// MarshalFeed renders a JSONFeed into the requested format (JSONFeed, Atom, or RSS).
+// It returns the rendered bytes, along with the Content-Type that describes them.
+func MarshalFeed(feed jsonfeed.Feed, format string) ([]byte, string, error) {
+
+	const location = "convert.MarshalFeed"
+
+	switch format {
+
+	case model.MimeTypeAtom:
+		result, err := JsonFeedToGorillaFeed(feed).ToAtom()
+
+		if err != nil {
+			return nil, "", derp.Wrap(err, location, "Error generating Atom feed")
+		}
+
+		return []byte(result), "application/atom+xml; charset=UTF-8", nil
+
+	case model.MimeTypeRSS, model.MimeTypeXML, model.MimeTypeXMLText:
+		result, err := JsonFeedToGorillaFeed(feed).ToRss()
+
+		if err != nil {
+			return nil, "", derp.Wrap(err, location, "Error generating RSS feed")
+		}
+
+		return []byte(result), "application/rss+xml; charset=UTF-8", nil
+	}
+
+	// Default to JSONFeed for everything else
+	result, err := json.Marshal(feed)
+
+	if err != nil {
+		return nil, "", derp.Wrap(err, location, "Error generating JSONFeed")
+	}
+
+	return result, model.MimeTypeJSONFeed, nil
+}
@@ -0,0 +1,61 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmissarySocial/emissary/model"
+	"github.com/kr/jsonfeed"
+	"github.com/stretchr/testify/require"
+)
+
+func testFeed() jsonfeed.Feed {
+	return jsonfeed.Feed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       "My Blog",
+		HomePageURL: "https://example.com/blog",
+		FeedURL:     "https://example.com/blog/feed?format=json",
+		Items: []jsonfeed.Item{{
+			ID:            "first-post",
+			URL:           "https://example.com/first-post",
+			Title:         "First Post",
+			ContentHTML:   "<p>Hello World</p>",
+			DatePublished: time.Unix(1700000000, 0),
+			Author:        &jsonfeed.Author{Name: "Alice"},
+		}},
+	}
+}
+
+func TestJsonFeedToGorillaFeed(t *testing.T) {
+
+	result := JsonFeedToGorillaFeed(testFeed())
+
+	require.Equal(t, "My Blog", result.Title)
+	require.Equal(t, "https://example.com/blog", result.Link.Href)
+	require.Equal(t, time.Unix(1700000000, 0), result.Created)
+	require.Len(t, result.Items, 1)
+	require.Equal(t, "https://example.com/first-post", result.Items[0].Link.Href)
+	require.Equal(t, "<p>Hello World</p>", result.Items[0].Content)
+	require.Equal(t, "Alice", result.Items[0].Author.Name)
+}
+
+func TestMarshalFeed(t *testing.T) {
+
+	body, contentType, err := MarshalFeed(testFeed(), model.MimeTypeJSONFeed)
+	require.Nil(t, err)
+	require.Equal(t, model.MimeTypeJSONFeed, contentType)
+	require.Contains(t, string(body), `"url":"https://example.com/first-post"`)
+
+	body, contentType, err = MarshalFeed(testFeed(), model.MimeTypeAtom)
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(contentType, model.MimeTypeAtom))
+	require.Contains(t, string(body), "<feed")
+	require.Contains(t, string(body), "First Post")
+
+	body, contentType, err = MarshalFeed(testFeed(), model.MimeTypeRSS)
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(contentType, model.MimeTypeRSS))
+	require.Contains(t, string(body), "<rss")
+	require.Contains(t, string(body), "First Post")
+}
@@ -27,3 +27,23 @@ func StreamToGorillaFeed(stream model.Stream) *feeds.Item {
 
 	return result
 }
+
+func SearchResultToGorillaFeed(searchResult model.SearchResult) *feeds.Item {
+	result := &feeds.Item{
+		Id:          searchResult.URL,
+		Title:       searchResult.Name,
+		Description: searchResult.Summary,
+		Link: &feeds.Link{
+			Href: searchResult.URL,
+		},
+		Created: time.Unix(searchResult.CreateDate, 0),
+	}
+
+	if searchResult.AttributedTo != "" {
+		result.Author = &feeds.Author{
+			Name: searchResult.AttributedTo,
+		}
+	}
+
+	return result
+}
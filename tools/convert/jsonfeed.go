@@ -100,3 +100,27 @@ func JsonFeedToContentHTML(item jsonfeed.Item) string {
 
 	return SanitizeHTML(result)
 }
+
+func SearchResultToJsonFeed(searchResult model.SearchResult) jsonfeed.Item {
+
+	result := jsonfeed.Item{
+		ID:            searchResult.URL,
+		URL:           searchResult.URL,
+		Title:         searchResult.Name,
+		ContentHTML:   first.String(searchResult.Summary, " "),
+		Summary:       searchResult.Summary,
+		Image:         searchResult.IconURL,
+		Tags:          searchResult.TagNames,
+		DatePublished: time.Unix(searchResult.CreateDate, 0),
+		DateModified:  time.Unix(searchResult.UpdateDate, 0),
+	}
+
+	// Attach author if available
+	if searchResult.AttributedTo != "" {
+		result.Author = &jsonfeed.Author{
+			Name: searchResult.AttributedTo,
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,100 @@
+// Package dialer makes outbound connections that can only reach public IP addresses.
+// Addresses are checked after DNS resolution, immediately before each connection is
+// opened, so a hostname that resolves (or later re-resolves) to an internal address
+// cannot be used to reach private services (SSRF).
+package dialer
+
+import (
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/benpate/derp"
+)
+
+// blockedNetworks are special-use ranges that are not covered by the net.IP helpers
+var blockedNetworks = parseNetworks(
+	"0.0.0.0/8",     // "This" network
+	"100.64.0.0/10", // Carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // Benchmarking
+	"240.0.0.0/4",   // Reserved (including broadcast)
+	"64:ff9b::/96",  // IPv4/IPv6 translation
+	"fec0::/10",     // Deprecated site-local
+)
+
+// Transport returns an http.Transport that only connects to public IP addresses.
+// Proxies are not used, because the proxy (not the destination) would be checked.
+func Transport() *http.Transport {
+
+	dialer := net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   Control,
+	}
+
+	result := http.DefaultTransport.(*http.Transport).Clone()
+	result.Proxy = nil
+	result.DialContext = dialer.DialContext
+	return result
+}
+
+// Control is a net.Dialer.Control function that refuses to connect to
+// loopback, private, link-local, and other non-public addresses.
+func Control(network string, address string, _ syscall.RawConn) error {
+
+	const location = "dialer.Control"
+
+	host, _, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return derp.Wrap(err, location, "Invalid network address", network, address)
+	}
+
+	if !IsPublic(net.ParseIP(host)) {
+		return derp.NewForbiddenError(location, "Connections to non-public addresses are not allowed", network, address)
+	}
+
+	return nil
+}
+
+// IsPublic returns TRUE if the IP address can be reached on the public internet
+func IsPublic(ip net.IP) bool {
+
+	if ip == nil {
+		return false
+	}
+
+	if ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() {
+		return false
+	}
+
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseNetworks converts a list of CIDR strings into IP networks
+func parseNetworks(values ...string) []*net.IPNet {
+
+	result := make([]*net.IPNet, 0, len(values))
+
+	for _, value := range values {
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			result = append(result, network)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,53 @@
+package dialer
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublic(t *testing.T) {
+
+	require.True(t, IsPublic(net.ParseIP("93.184.216.34")))
+	require.True(t, IsPublic(net.ParseIP("2606:2800:220:1:248:1893:25c8:1946")))
+
+	require.False(t, IsPublic(nil))
+	require.False(t, IsPublic(net.ParseIP("127.0.0.1")))
+	require.False(t, IsPublic(net.ParseIP("::1")))
+	require.False(t, IsPublic(net.ParseIP("::ffff:127.0.0.1")))
+	require.False(t, IsPublic(net.ParseIP("0.0.0.0")))
+	require.False(t, IsPublic(net.ParseIP("10.1.2.3")))
+	require.False(t, IsPublic(net.ParseIP("172.16.0.1")))
+	require.False(t, IsPublic(net.ParseIP("192.168.1.1")))
+	require.False(t, IsPublic(net.ParseIP("169.254.169.254")))
+	require.False(t, IsPublic(net.ParseIP("100.100.100.200")))
+	require.False(t, IsPublic(net.ParseIP("fd00::1")))
+	require.False(t, IsPublic(net.ParseIP("fe80::1")))
+	require.False(t, IsPublic(net.ParseIP("255.255.255.255")))
+}
+
+func TestControl(t *testing.T) {
+
+	require.Nil(t, Control("tcp4", "93.184.216.34:443", nil))
+	require.NotNil(t, Control("tcp4", "127.0.0.1:80", nil))
+	require.NotNil(t, Control("tcp6", "[::1]:80", nil))
+	require.NotNil(t, Control("tcp4", "invalid", nil))
+}
+
+func TestTransport(t *testing.T) {
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	// Servers on the loopback interface cannot be reached
+	client := http.Client{Transport: Transport()}
+	_, err := client.Get(server.URL)
+	require.NotNil(t, err)
+	require.False(t, called)
+}
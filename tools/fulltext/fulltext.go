@@ -0,0 +1,5 @@
+// Package fulltext is a small, embedded full-text search engine.  It tokenizes and
+// stems text, stores it in an in-memory inverted index, and ranks matches using BM25
+// with per-field boosts.  Queries support "quoted phrases", prefix* matches for
+// typeahead, and -excluded words.
+package fulltext
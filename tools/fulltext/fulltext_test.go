@@ -0,0 +1,146 @@
+package fulltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStem(t *testing.T) {
+
+	test := func(words ...string) {
+		for _, word := range words[1:] {
+			require.Equal(t, Stem(words[0]), Stem(word), word)
+		}
+	}
+
+	test("run", "runs", "running")
+	test("hope", "hopes", "hoped", "hoping")
+	test("catalogue", "catalogues")
+	test("happy", "happiness")
+	test("fall", "falls", "falling")
+	test("class", "classes")
+}
+
+func TestParseQuery(t *testing.T) {
+
+	query := ParseQuery(`coffee "the French press" -decaf grind* and`)
+	require.Equal(t, 4, len(query.Terms))
+
+	require.Equal(t, "coffee", query.Terms[0].Text)
+
+	require.True(t, query.Terms[1].Phrase)
+	require.Equal(t, []string{"french", "press"}, query.Terms[1].Stems)
+	require.Equal(t, []int{0, 1}, query.Terms[1].Offsets)
+
+	require.True(t, query.Terms[2].Exclude)
+	require.True(t, query.Terms[3].Prefix)
+	require.Equal(t, "grind", query.Terms[3].Text)
+
+	require.True(t, ParseQuery("the and -coffee").IsEmpty())
+}
+
+func testIndex() *Index {
+
+	index := NewIndex(map[string]float64{"name": 10, "summary": 4, "fullText": 1})
+
+	index.Put("espresso", map[string]string{
+		"name":     "Espresso Machines",
+		"summary":  "Pressure brewing for home baristas",
+		"fullText": "Our espresso machines are tested for grinding and brewing.",
+	})
+
+	index.Put("press", map[string]string{
+		"name":     "French Press",
+		"summary":  "Immersion brewing made simple",
+		"fullText": "A French press is the easiest way to brew coffee at home.",
+	})
+
+	index.Put("grinder", map[string]string{
+		"name":     "Burr Grinders",
+		"summary":  "Consistent grinds for espresso and press coffee",
+		"fullText": "Grinders are the most important part of brewing coffee.",
+	})
+
+	return index
+}
+
+func TestIndex_Boosts(t *testing.T) {
+
+	index := testIndex()
+	require.Equal(t, 3, index.Len())
+
+	// Matches in the name outrank matches in the summary
+	result := index.Search(ParseQuery("espresso"), 0)
+	require.Equal(t, 2, len(result))
+	require.Equal(t, "espresso", result[0].ID)
+	require.Equal(t, "grinder", result[1].ID)
+}
+
+func TestIndex_Stemming(t *testing.T) {
+
+	index := testIndex()
+	result := index.Search(ParseQuery("brewed"), 0)
+	require.Equal(t, 3, len(result))
+}
+
+func TestIndex_Phrase(t *testing.T) {
+
+	index := testIndex()
+
+	// "press coffee" only appears together in one document
+	result := index.Search(ParseQuery(`"press coffee"`), 0)
+	require.Equal(t, 1, len(result))
+	require.Equal(t, "grinder", result[0].ID)
+
+	// Stop words are skipped, but still hold their position
+	result = index.Search(ParseQuery(`"the easiest way"`), 0)
+	require.Equal(t, 1, len(result))
+	require.Equal(t, "press", result[0].ID)
+}
+
+func TestIndex_Prefix(t *testing.T) {
+
+	index := testIndex()
+
+	result := index.Search(ParseQuery("gri*"), 0)
+	require.Equal(t, 2, len(result))
+	require.Equal(t, "grinder", result[0].ID)
+
+	// Prefixes are required, even when other words match
+	result = index.Search(ParseQuery("home gri*"), 0)
+	require.Equal(t, 2, len(result))
+	require.NotEqual(t, "press", result[0].ID)
+	require.NotEqual(t, "press", result[1].ID)
+}
+
+func TestIndex_Exclude(t *testing.T) {
+
+	index := testIndex()
+	result := index.Search(ParseQuery("coffee -french"), 0)
+	require.Equal(t, 1, len(result))
+	require.Equal(t, "grinder", result[0].ID)
+}
+
+func TestIndex_Delete(t *testing.T) {
+
+	index := testIndex()
+	index.Delete("grinder")
+	require.Equal(t, 2, index.Len())
+	require.Equal(t, 0, len(index.Search(ParseQuery("burr"), 0)))
+
+	// Replacing a document removes its old words
+	index.Put("press", map[string]string{"name": "Pour Over"})
+	require.Equal(t, 0, len(index.Search(ParseQuery("french"), 0)))
+	require.Equal(t, 1, len(index.Search(ParseQuery("pour"), 0)))
+}
+
+func TestHighlight(t *testing.T) {
+
+	query := ParseQuery("brewing <b>")
+	require.Equal(t, "Immersion <mark>brewing</mark> made &lt;simple&gt;", Highlight("Immersion brewing made <simple>", query, 0))
+	require.Equal(t, "", Highlight("Nothing to see here", query, 0))
+
+	snippet := Highlight("One two three four five six seven eight nine brewed ten eleven twelve thirteen fourteen", query, 30)
+	require.Equal(t, "&hellip;nine <mark>brewed</mark> ten eleven twelve&hellip;", snippet)
+}
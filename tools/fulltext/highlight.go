@@ -0,0 +1,84 @@
+package fulltext
+
+import (
+	"html"
+	"strings"
+)
+
+// Highlight returns an HTML snippet of the text that surrounds the first match for the Query.
+// Matching words are wrapped in <mark> tags, and all other text is escaped.  If maxLength is
+// greater than zero, the snippet is trimmed to approximately that many bytes.  If no words match,
+// then Highlight returns an empty string.
+func Highlight(text string, query Query, maxLength int) string {
+
+	tokens := Tokenize(text)
+	matches := make([]Token, 0)
+
+	for _, token := range tokens {
+		if query.Matches(token) {
+			matches = append(matches, token)
+		}
+	}
+
+	if len(matches) == 0 {
+		return ""
+	}
+
+	// Find the window of text to display
+	start, end := 0, len(text)
+
+	if (maxLength > 0) && (len(text) > maxLength) {
+
+		// Begin a few words before the first match
+		start = matches[0].Start
+		for index := matches[0].Position - 1; index >= 0; index-- {
+			if matches[0].Start-tokens[index].Start > maxLength/3 {
+				break
+			}
+			start = tokens[index].Start
+		}
+
+		// End at the last word that fits
+		end = start
+		for _, token := range tokens[matches[0].Position:] {
+			if token.End-start > maxLength {
+				break
+			}
+			end = token.End
+		}
+	}
+
+	// Write the snippet, marking each match
+	var result strings.Builder
+
+	if start > 0 {
+		result.WriteString("&hellip;")
+	}
+
+	cursor := start
+
+	for _, match := range matches {
+
+		if match.Start < start {
+			continue
+		}
+
+		if match.End > end {
+			break
+		}
+
+		result.WriteString(html.EscapeString(text[cursor:match.Start]))
+		result.WriteString("<mark>")
+		result.WriteString(html.EscapeString(text[match.Start:match.End]))
+		result.WriteString("</mark>")
+		cursor = match.End
+	}
+
+	result.WriteString(html.EscapeString(text[cursor:end]))
+
+	if end < len(text) {
+		result.WriteString("&hellip;")
+	}
+
+	return result.String()
+}
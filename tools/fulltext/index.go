@@ -0,0 +1,381 @@
+package fulltext
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BM25 tuning parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// phraseBoost increases the score of documents that match a complete phrase
+const phraseBoost = 1.5
+
+// Index is a thread-safe, in-memory inverted index
+type Index struct {
+	weights      map[string]float64             // Relative importance of each field
+	postings     map[string]map[string]*posting // stem => documentID => posting
+	vocabulary   map[string]string              // word => stem, used to expand prefix searches
+	documents    map[string]*document           // documentID => document
+	fieldLengths map[string]int                 // field name => total number of tokens in all documents
+	mutex        sync.RWMutex
+}
+
+// Match is a single document returned by a search
+type Match struct {
+	ID    string
+	Score float64
+}
+
+// document tracks the information needed to score and remove a document
+type document struct {
+	lengths map[string]int // field name => number of tokens
+	stems   []string       // unique stems in this document
+}
+
+// posting records where a stem appears in a single document
+type posting struct {
+	positions map[string][]int // field name => token positions
+}
+
+// NewIndex returns a fully initialized Index.  Weights determine the relative importance
+// of each field; fields that are not listed are not indexed.
+func NewIndex(weights map[string]float64) *Index {
+	return &Index{
+		weights:      weights,
+		postings:     make(map[string]map[string]*posting),
+		vocabulary:   make(map[string]string),
+		documents:    make(map[string]*document),
+		fieldLengths: make(map[string]int),
+	}
+}
+
+// Len returns the number of documents in the Index
+func (index *Index) Len() int {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+
+	return len(index.documents)
+}
+
+// Put adds a document to the Index, replacing any previous version with the same ID
+func (index *Index) Put(documentID string, fields map[string]string) {
+
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	index.delete(documentID)
+
+	doc := &document{
+		lengths: make(map[string]int),
+		stems:   make([]string, 0),
+	}
+
+	for field, text := range fields {
+
+		if _, ok := index.weights[field]; !ok {
+			continue
+		}
+
+		tokens := Tokenize(text)
+		doc.lengths[field] = len(tokens)
+		index.fieldLengths[field] += len(tokens)
+
+		for _, token := range tokens {
+
+			if token.Stop {
+				continue
+			}
+
+			index.vocabulary[token.Word] = token.Stem
+
+			documents, ok := index.postings[token.Stem]
+
+			if !ok {
+				documents = make(map[string]*posting)
+				index.postings[token.Stem] = documents
+			}
+
+			post, ok := documents[documentID]
+
+			if !ok {
+				post = &posting{positions: make(map[string][]int)}
+				documents[documentID] = post
+				doc.stems = append(doc.stems, token.Stem)
+			}
+
+			post.positions[field] = append(post.positions[field], token.Position)
+		}
+	}
+
+	index.documents[documentID] = doc
+}
+
+// Delete removes a document from the Index
+func (index *Index) Delete(documentID string) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	index.delete(documentID)
+}
+
+// delete removes a document from the Index.
+// CALLS TO THIS MUST BE LOCKED
+func (index *Index) delete(documentID string) {
+
+	doc, ok := index.documents[documentID]
+
+	if !ok {
+		return
+	}
+
+	for field, length := range doc.lengths {
+		index.fieldLengths[field] -= length
+	}
+
+	for _, stem := range doc.stems {
+		delete(index.postings[stem], documentID)
+
+		if len(index.postings[stem]) == 0 {
+			delete(index.postings, stem)
+		}
+	}
+
+	delete(index.documents, documentID)
+}
+
+// Search returns the documents that match the Query, sorted by relevance.
+// If limit is greater than zero, then only the top matches are returned.
+func (index *Index) Search(query Query, limit int) []Match {
+
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+
+	if query.IsEmpty() {
+		return make([]Match, 0)
+	}
+
+	var candidates map[string]float64
+
+	// Phrases and prefixes are required in every result
+	required := append(query.Phrases(), query.Prefixes()...)
+
+	for _, term := range required {
+		scores := index.scoreTerm(term)
+
+		if candidates == nil {
+			candidates = scores
+			continue
+		}
+
+		for documentID, score := range candidates {
+			if termScore, ok := scores[documentID]; ok {
+				candidates[documentID] = score + termScore
+			} else {
+				delete(candidates, documentID)
+			}
+		}
+	}
+
+	// Plain words match if any of them are found, and improve the score of required terms
+	words := query.Words()
+
+	if candidates == nil {
+		candidates = make(map[string]float64)
+
+		for _, term := range words {
+			for documentID, score := range index.scoreTerm(term) {
+				candidates[documentID] += score
+			}
+		}
+
+	} else {
+
+		for _, term := range words {
+			for documentID, score := range index.scoreTerm(term) {
+				if _, ok := candidates[documentID]; ok {
+					candidates[documentID] += score
+				}
+			}
+		}
+	}
+
+	// Exclusions remove documents from the results
+	for _, term := range query.Exclusions() {
+		for documentID := range index.scoreTerm(term) {
+			delete(candidates, documentID)
+		}
+	}
+
+	// Sort by score, then by ID so that results are stable
+	result := make([]Match, 0, len(candidates))
+
+	for documentID, score := range candidates {
+		result = append(result, Match{ID: documentID, Score: score})
+	}
+
+	sort.Slice(result, func(i int, j int) bool {
+		if result[i].Score == result[j].Score {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].Score > result[j].Score
+	})
+
+	if (limit > 0) && (len(result) > limit) {
+		result = result[:limit]
+	}
+
+	return result
+}
+
+// scoreTerm returns the score of every document that matches a single Term.
+// CALLS TO THIS MUST BE LOCKED
+func (index *Index) scoreTerm(term Term) map[string]float64 {
+
+	result := make(map[string]float64)
+
+	// Prefixes match every word that begins with the prefix, scored by the best match
+	if term.Prefix {
+
+		stems := make(map[string]struct{})
+
+		for word, stem := range index.vocabulary {
+			if strings.HasPrefix(word, term.Text) {
+				stems[stem] = struct{}{}
+			}
+		}
+
+		for stem := range stems {
+			for documentID, score := range index.scoreStem(stem, nil) {
+				result[documentID] = max(result[documentID], score)
+			}
+		}
+
+		return result
+	}
+
+	// Single words are scored directly
+	if !term.Phrase {
+		return index.scoreStem(term.Stems[0], nil)
+	}
+
+	// Phrases must appear in order, within the same field
+	fields := index.phraseFields(term)
+
+	for _, stem := range term.Stems {
+		for documentID, score := range index.scoreStem(stem, fields) {
+			result[documentID] += score * phraseBoost
+		}
+	}
+
+	return result
+}
+
+// scoreStem calculates the BM25F score for a single stem in every document that contains it.
+// If fields is not nil, then only the listed (document => field) matches are counted.
+// CALLS TO THIS MUST BE LOCKED
+func (index *Index) scoreStem(stem string, fields map[string]map[string]bool) map[string]float64 {
+
+	documents := index.postings[stem]
+	result := make(map[string]float64, len(documents))
+
+	if len(documents) == 0 {
+		return result
+	}
+
+	total := float64(len(index.documents))
+	frequency := float64(len(documents))
+	idf := math.Log(1 + (total-frequency+0.5)/(frequency+0.5))
+
+	for documentID, post := range documents {
+
+		if fields != nil && fields[documentID] == nil {
+			continue
+		}
+
+		doc := index.documents[documentID]
+		score := 0.0
+
+		for field, positions := range post.positions {
+
+			if fields != nil && !fields[documentID][field] {
+				continue
+			}
+
+			average := float64(index.fieldLengths[field]) / total
+			length := float64(doc.lengths[field])
+			tf := float64(len(positions))
+
+			normalized := tf / (tf + bm25K1*(1-bm25B+bm25B*length/max(average, 1)))
+			score += index.weights[field] * normalized
+		}
+
+		result[documentID] = score * idf * (bm25K1 + 1)
+	}
+
+	return result
+}
+
+// phraseFields finds every document and field where the complete phrase appears.
+// CALLS TO THIS MUST BE LOCKED
+func (index *Index) phraseFields(term Term) map[string]map[string]bool {
+
+	result := make(map[string]map[string]bool)
+	first := index.postings[term.Stems[0]]
+
+	for documentID, post := range first {
+
+		for field, positions := range post.positions {
+
+			for _, start := range positions {
+
+				if index.phraseAt(documentID, field, term, start) {
+
+					if result[documentID] == nil {
+						result[documentID] = make(map[string]bool)
+					}
+
+					result[documentID][field] = true
+					break
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// phraseAt returns TRUE if every word in the phrase appears in the correct position
+// CALLS TO THIS MUST BE LOCKED
+func (index *Index) phraseAt(documentID string, field string, term Term, start int) bool {
+
+	for wordIndex := 1; wordIndex < len(term.Stems); wordIndex++ {
+
+		post, ok := index.postings[term.Stems[wordIndex]][documentID]
+
+		if !ok {
+			return false
+		}
+
+		expected := start + term.Offsets[wordIndex]
+		found := false
+
+		for _, position := range post.positions[field] {
+			if position == expected {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,207 @@
+package fulltext
+
+import (
+	"strings"
+)
+
+// Query is a parsed full-text search query
+type Query struct {
+	Terms []Term
+}
+
+// Term is a single word, phrase, or prefix in a Query
+type Term struct {
+	Text    string   // Text is the lowercase text of the term as it was entered
+	Stems   []string // Stems is the list of stemmed (non-stop) words in this term
+	Offsets []int    // Offsets is the position of each stem, relative to the start of the phrase
+	Phrase  bool     // Phrase is TRUE if all words must appear together, in order
+	Prefix  bool     // Prefix is TRUE if the term matches any word that begins with Text
+	Exclude bool     // Exclude is TRUE if documents that match this term should be removed from the results
+}
+
+// ParseQuery converts a string into a Query. Plain words match if any of them are found.
+// "Quoted phrases" and prefix* terms are required, and -excluded words or phrases remove
+// documents from the results.
+func ParseQuery(text string) Query {
+
+	result := Query{Terms: make([]Term, 0)}
+	text = strings.TrimSpace(text)
+
+	for text != "" {
+
+		exclude := false
+
+		if text[0] == '-' {
+			exclude = true
+			text = text[1:]
+		}
+
+		// Quoted phrases continue until the next quote (or the end of the string)
+		if strings.HasPrefix(text, `"`) {
+
+			phrase, remainder, _ := strings.Cut(text[1:], `"`)
+			text = strings.TrimSpace(remainder)
+			result.add(newTerm(phrase, true, false, exclude))
+			continue
+		}
+
+		// Everything else is a single word
+		word, remainder, _ := strings.Cut(text, " ")
+		text = strings.TrimSpace(remainder)
+
+		if prefix, ok := strings.CutSuffix(word, "*"); ok {
+			result.add(newTerm(prefix, false, true, exclude))
+			continue
+		}
+
+		// Words with punctuation (like "e-mail") are treated as phrases
+		for _, token := range Tokenize(word) {
+			result.add(newTerm(token.Word, false, false, exclude))
+		}
+	}
+
+	return result
+}
+
+// newTerm creates a Term from a string of text
+func newTerm(text string, phrase bool, prefix bool, exclude bool) Term {
+
+	result := Term{
+		Text:    strings.ToLower(strings.TrimSpace(text)),
+		Phrase:  phrase,
+		Prefix:  prefix,
+		Exclude: exclude,
+	}
+
+	// Prefixes are matched against the original words, not the stems
+	if prefix {
+		tokens := Tokenize(result.Text)
+		if len(tokens) == 1 {
+			result.Text = tokens[0].Word
+			result.Stems = []string{tokens[0].Stem}
+			result.Offsets = []int{0}
+		} else {
+			result.Text = ""
+		}
+		return result
+	}
+
+	first := -1
+
+	for _, token := range Tokenize(result.Text) {
+
+		if token.Stop {
+			continue
+		}
+
+		if first == -1 {
+			first = token.Position
+		}
+
+		result.Stems = append(result.Stems, token.Stem)
+		result.Offsets = append(result.Offsets, token.Position-first)
+	}
+
+	// Single-word phrases are just words
+	if len(result.Stems) == 1 {
+		result.Phrase = false
+	}
+
+	return result
+}
+
+// add includes a Term in the Query, as long as it contains something to search for
+func (query *Query) add(term Term) {
+
+	if term.Prefix {
+		if term.Text != "" {
+			query.Terms = append(query.Terms, term)
+		}
+		return
+	}
+
+	if len(term.Stems) > 0 {
+		query.Terms = append(query.Terms, term)
+	}
+}
+
+// IsEmpty returns TRUE if the Query does not include any words to search for
+func (query Query) IsEmpty() bool {
+	for _, term := range query.Terms {
+		if !term.Exclude {
+			return false
+		}
+	}
+	return true
+}
+
+// Words returns the plain (not phrase, not prefix, not excluded) words in the Query
+func (query Query) Words() []Term {
+	return query.filter(func(term Term) bool {
+		return !term.Exclude && !term.Phrase && !term.Prefix
+	})
+}
+
+// Phrases returns the phrases that must be included in all results
+func (query Query) Phrases() []Term {
+	return query.filter(func(term Term) bool {
+		return !term.Exclude && term.Phrase
+	})
+}
+
+// Prefixes returns the prefix terms that must be included in all results
+func (query Query) Prefixes() []Term {
+	return query.filter(func(term Term) bool {
+		return !term.Exclude && term.Prefix
+	})
+}
+
+// Exclusions returns the terms that must NOT be included in any results
+func (query Query) Exclusions() []Term {
+	return query.filter(func(term Term) bool {
+		return term.Exclude
+	})
+}
+
+// Matches returns TRUE if a single token would be matched by any (non-excluded) Term in this Query
+func (query Query) Matches(token Token) bool {
+
+	if token.Stop {
+		return false
+	}
+
+	for _, term := range query.Terms {
+
+		if term.Exclude {
+			continue
+		}
+
+		if term.Prefix {
+			if strings.HasPrefix(token.Word, term.Text) {
+				return true
+			}
+			continue
+		}
+
+		for _, stem := range term.Stems {
+			if stem == token.Stem {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (query Query) filter(fn func(Term) bool) []Term {
+
+	result := make([]Term, 0, len(query.Terms))
+
+	for _, term := range query.Terms {
+		if fn(term) {
+			result = append(result, term)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,108 @@
+package fulltext
+
+import "strings"
+
+// Stem reduces an English word to its root form, so that "running", "runs",
+// and "run" all match each other.  This is a light version of the Porter
+// stemmer that only removes plural and verb suffixes.  Documents and queries
+// are stemmed with the same rules, so small differences from "real" English
+// roots do not affect matching.
+func Stem(word string) string {
+
+	// Short words (and words that are not plain ASCII) are left alone
+	if runeCount(word) <= 3 || !isASCII(word) {
+		return word
+	}
+
+	// Plurals
+	switch {
+	case strings.HasSuffix(word, "sses"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ies"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		// leave as-is
+	case strings.HasSuffix(word, "s"):
+		word = word[:len(word)-1]
+	}
+
+	// Past tense and gerunds
+	switch {
+	case strings.HasSuffix(word, "eed"):
+		word = word[:len(word)-1]
+	case strings.HasSuffix(word, "ed") && hasVowel(word[:len(word)-2]):
+		word = trimDoubleConsonant(word[:len(word)-2])
+	case strings.HasSuffix(word, "ing") && hasVowel(word[:len(word)-3]) && len(word) > 5:
+		word = trimDoubleConsonant(word[:len(word)-3])
+	}
+
+	// Common adverb and noun suffixes
+	for _, suffix := range []string{"ational", "fulness", "iveness", "ousness", "ization", "ation", "ment", "ness", "ly"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			word = word[:len(word)-len(suffix)]
+			break
+		}
+	}
+
+	// Trailing "y" and "e" are inconsistent in English suffixes (happy/happiness, hope/hoping)
+	if len(word) > 3 {
+		switch word[len(word)-1] {
+		case 'y':
+			word = word[:len(word)-1] + "i"
+		case 'e':
+			word = word[:len(word)-1]
+		}
+	}
+
+	return word
+}
+
+// trimDoubleConsonant removes the last letter of words like "runn" or "shopp"
+func trimDoubleConsonant(word string) string {
+
+	length := len(word)
+
+	if length < 2 {
+		return word
+	}
+
+	last := word[length-1]
+
+	if last != word[length-2] || isVowel(last) {
+		return word
+	}
+
+	// These letters are commonly doubled in root words ("fall", "pass", "buzz")
+	switch last {
+	case 'l', 's', 'z':
+		return word
+	}
+
+	return word[:length-1]
+}
+
+func hasVowel(word string) bool {
+	for index := 0; index < len(word); index++ {
+		if isVowel(word[index]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isVowel(letter byte) bool {
+	switch letter {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+	return false
+}
+
+func isASCII(word string) bool {
+	for index := 0; index < len(word); index++ {
+		if word[index] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
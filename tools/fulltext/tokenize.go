@@ -0,0 +1,82 @@
+package fulltext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is a single word found in a string of text
+type Token struct {
+	Word     string // Word is the lowercase text of the token
+	Stem     string // Stem is the stemmed version of the Word, which is used for matching
+	Position int    // Position is the index of this token within the original text (including stop words)
+	Start    int    // Start is the byte offset where this token begins in the original text
+	End      int    // End is the byte offset where this token ends in the original text
+	Stop     bool   // Stop is TRUE if this token is a stop word that should not be indexed
+}
+
+// Tokenize splits a string into lowercase, stemmed tokens.
+func Tokenize(text string) []Token {
+
+	result := make([]Token, 0)
+	start := -1
+
+	appendToken := func(end int) {
+		word := strings.ToLower(text[start:end])
+		result = append(result, Token{
+			Word:     word,
+			Stem:     Stem(word),
+			Position: len(result),
+			Start:    start,
+			End:      end,
+			Stop:     isStopWord(word),
+		})
+		start = -1
+	}
+
+	for index, r := range text {
+
+		if isWordRune(r) {
+			if start == -1 {
+				start = index
+			}
+			continue
+		}
+
+		if start != -1 {
+			appendToken(index)
+		}
+	}
+
+	if start != -1 {
+		appendToken(len(text))
+	}
+
+	return result
+}
+
+// isWordRune returns TRUE if the rune can be part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+// isStopWord returns TRUE if the word is too common to be worth indexing
+func isStopWord(word string) bool {
+	_, ok := stopWords[word]
+	return ok
+}
+
+// stopWords is the list of English words that are not indexed
+var stopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {}, "but": {},
+	"by": {}, "for": {}, "if": {}, "in": {}, "into": {}, "is": {}, "it": {}, "no": {},
+	"not": {}, "of": {}, "on": {}, "or": {}, "such": {}, "that": {}, "the": {}, "their": {},
+	"then": {}, "there": {}, "these": {}, "they": {}, "this": {}, "to": {}, "was": {},
+	"will": {}, "with": {},
+}
+
+// runeCount is a shortcut for counting the characters in a string
+func runeCount(value string) int {
+	return utf8.RuneCountInString(value)
+}
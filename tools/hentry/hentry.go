@@ -0,0 +1,278 @@
+// Package hentry reads the h-entry microformat from WebMention source documents,
+// and determines how the source relates to the target (reply, like, repost, etc.)
+// https://microformats.org/wiki/h-entry
+package hentry
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+	"willnorris.com/go/microformats"
+)
+
+// TypeMention is a generic link to the target
+const TypeMention = "mention"
+
+// TypeReply is a comment on the target (in-reply-to)
+const TypeReply = "reply"
+
+// TypeLike is a "like" or "favorite" of the target (like-of)
+const TypeLike = "like"
+
+// TypeRepost is a re-share of the target (repost-of)
+const TypeRepost = "repost"
+
+// TypeBookmark is a bookmark of the target (bookmark-of)
+const TypeBookmark = "bookmark"
+
+// TypeRSVP is a reply to an event that includes an "rsvp" value (yes, no, maybe, interested)
+const TypeRSVP = "rsvp"
+
+// Entry contains the values from an h-entry that are relevant to a WebMention
+type Entry struct {
+	Type        string    // How the entry relates to the target (mention, reply, like, repost, bookmark, rsvp)
+	URL         string    // Canonical URL of the entry
+	Name        string    // Name (title) of the entry
+	Content     string    // Sanitized HTML content of the entry
+	Published   time.Time // Date that the entry was published
+	RSVP        string    // RSVP value (yes, no, maybe, interested) if Type is "rsvp"
+	AuthorName  string    // Name of the entry's author
+	AuthorURL   string    // Profile URL of the entry's author
+	AuthorPhoto string    // Avatar URL of the entry's author
+}
+
+// Parse reads the first h-entry in an HTML document, and determines how it relates
+// to the target URL.  Documents without an h-entry are returned as plain mentions.
+func Parse(reader io.Reader, sourceURL string, targetURL string) Entry {
+
+	result := Entry{
+		Type: TypeMention,
+		URL:  sourceURL,
+	}
+
+	baseURL, err := url.Parse(sourceURL)
+
+	if err != nil {
+		return result
+	}
+
+	data := microformats.Parse(reader, baseURL)
+
+	if data == nil {
+		return result
+	}
+
+	entry := find(data.Items, "h-entry")
+
+	if entry == nil {
+		return result
+	}
+
+	// Basic values
+	if value := firstString(entry, "url"); value != "" {
+		result.URL = value
+	}
+
+	result.Name = strings.TrimSpace(firstString(entry, "name"))
+	result.Content = firstHTML(entry, "content")
+
+	if published := firstString(entry, "published"); published != "" {
+		result.Published = parseTime(published)
+	}
+
+	// Names are frequently implied from the content, so remove duplicates
+	if strings.HasPrefix(strings.Join(strings.Fields(stripTags(result.Content)), " "), strings.Join(strings.Fields(result.Name), " ")) {
+		result.Name = ""
+	}
+
+	// Author information
+	if author := firstMicroformat(entry, "author"); author != nil {
+		result.AuthorName = firstString(author, "name")
+		result.AuthorURL = firstString(author, "url")
+		result.AuthorPhoto = firstString(author, "photo")
+	} else if value := firstString(entry, "author"); value != "" {
+		if isURL(value) {
+			result.AuthorURL = value
+		} else {
+			result.AuthorName = value
+		}
+	}
+
+	// Relationship with the target
+	switch {
+
+	case references(entry, "in-reply-to", targetURL):
+		result.Type = TypeReply
+
+		if rsvp := strings.ToLower(firstString(entry, "rsvp")); rsvp != "" {
+			result.Type = TypeRSVP
+			result.RSVP = rsvp
+		}
+
+	case references(entry, "repost-of", targetURL):
+		result.Type = TypeRepost
+
+	case references(entry, "like-of", targetURL):
+		result.Type = TypeLike
+
+	case references(entry, "bookmark-of", targetURL):
+		result.Type = TypeBookmark
+	}
+
+	return result
+}
+
+// find returns the first microformat of the requested type, searching recursively
+func find(items []*microformats.Microformat, itemType string) *microformats.Microformat {
+
+	for _, item := range items {
+
+		for _, value := range item.Type {
+			if value == itemType {
+				return item
+			}
+		}
+
+		if result := find(item.Children, itemType); result != nil {
+			return result
+		}
+	}
+
+	return nil
+}
+
+// references returns TRUE if any value of the named property points to the target URL
+func references(item *microformats.Microformat, name string, targetURL string) bool {
+
+	for _, value := range item.Properties[name] {
+
+		switch typed := value.(type) {
+
+		case string:
+			if isSameURL(typed, targetURL) {
+				return true
+			}
+
+		// Nested h-cite microformats include the URL as a property
+		case *microformats.Microformat:
+			if isSameURL(typed.Value, targetURL) || isSameURL(firstString(typed, "url"), targetURL) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// firstString returns the first value of a microformat property as a string
+func firstString(item *microformats.Microformat, name string) string {
+
+	values := item.Properties[name]
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	switch value := values[0].(type) {
+
+	case string:
+		return value
+
+	// Images with alt text are returned as maps
+	case map[string]string:
+		return value["value"]
+
+	case map[string]any:
+		result, _ := value["value"].(string)
+		return result
+
+	case *microformats.Microformat:
+		return value.Value
+	}
+
+	return ""
+}
+
+// firstHTML returns the first value of an embedded (e-*) property as sanitized HTML
+func firstHTML(item *microformats.Microformat, name string) string {
+
+	values := item.Properties[name]
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	switch value := values[0].(type) {
+
+	case string:
+		return bluemonday.StrictPolicy().Sanitize(value)
+
+	case map[string]string:
+		return sanitize(value["html"], value["value"])
+
+	case map[string]any:
+		html, _ := value["html"].(string)
+		text, _ := value["value"].(string)
+		return sanitize(html, text)
+	}
+
+	return ""
+}
+
+// firstMicroformat returns the first value of a property if it is a nested microformat
+func firstMicroformat(item *microformats.Microformat, name string) *microformats.Microformat {
+
+	values := item.Properties[name]
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	result, _ := values[0].(*microformats.Microformat)
+	return result
+}
+
+// sanitize removes unsafe HTML from remote content, falling back to plain text
+func sanitize(html string, text string) string {
+
+	if html != "" {
+		return strings.TrimSpace(bluemonday.UGCPolicy().Sanitize(html))
+	}
+
+	return strings.TrimSpace(bluemonday.StrictPolicy().Sanitize(text))
+}
+
+// stripTags removes all HTML tags from a string
+func stripTags(html string) string {
+	return bluemonday.StrictPolicy().Sanitize(html)
+}
+
+// parseTime reads the common date formats used by dt-published
+func parseTime(value string) time.Time {
+
+	for _, format := range []string{time.RFC3339, "2006-01-02T15:04:05-0700", "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if result, err := time.Parse(format, value); err == nil {
+			return result
+		}
+	}
+
+	return time.Time{}
+}
+
+// isSameURL returns TRUE if two URLs are equal, ignoring trailing slashes
+func isSameURL(value string, other string) bool {
+
+	if (value == "") || (other == "") {
+		return false
+	}
+
+	return strings.TrimSuffix(value, "/") == strings.TrimSuffix(other, "/")
+}
+
+// isURL returns TRUE if the value is an absolute http(s) URL
+func isURL(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
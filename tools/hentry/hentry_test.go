@@ -0,0 +1,89 @@
+package hentry
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const target = "https://emissary.example/123456781234567812345678"
+
+func TestParse_Reply(t *testing.T) {
+
+	body := `<html><body>
+		<article class="h-entry">
+			<a class="u-url" href="/reply/1"></a>
+			<a class="u-in-reply-to" href="` + target + `">In reply to</a>
+			<div class="p-author h-card">
+				<img class="u-photo" src="/alice.jpg">
+				<a class="p-name u-url" href="https://source.example/">Alice</a>
+			</div>
+			<div class="e-content">Great post! <script>alert("nope")</script></div>
+			<time class="dt-published" datetime="2024-03-01T12:30:00Z">March 1</time>
+		</article>
+	</body></html>`
+
+	result := Parse(strings.NewReader(body), "https://source.example/reply/1", target)
+
+	require.Equal(t, TypeReply, result.Type)
+	require.Equal(t, "https://source.example/reply/1", result.URL)
+	require.Equal(t, "Great post!", result.Content)
+	require.Equal(t, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC), result.Published.UTC())
+	require.Equal(t, "Alice", result.AuthorName)
+	require.Equal(t, "https://source.example/", result.AuthorURL)
+	require.Equal(t, "https://source.example/alice.jpg", result.AuthorPhoto)
+}
+
+func TestParse_RSVP(t *testing.T) {
+
+	body := `<div class="h-entry">
+		<a class="u-in-reply-to" href="` + target + `/">Event</a>
+		<data class="p-rsvp" value="yes">I'll be there</data>
+	</div>`
+
+	result := Parse(strings.NewReader(body), "https://source.example/rsvp", target)
+	require.Equal(t, TypeRSVP, result.Type)
+	require.Equal(t, "yes", result.RSVP)
+}
+
+func TestParse_Types(t *testing.T) {
+
+	test := func(property string, expected string) {
+		body := `<div class="h-entry"><a class="u-` + property + `" href="` + target + `">Link</a></div>`
+		result := Parse(strings.NewReader(body), "https://source.example/entry", target)
+		require.Equal(t, expected, result.Type, property)
+	}
+
+	test("like-of", TypeLike)
+	test("repost-of", TypeRepost)
+	test("bookmark-of", TypeBookmark)
+	test("url", TypeMention)
+}
+
+func TestParse_NestedCitation(t *testing.T) {
+
+	body := `<div class="h-entry">
+		<div class="u-like-of h-cite"><a class="u-url" href="` + target + `">Original</a></div>
+	</div>`
+
+	result := Parse(strings.NewReader(body), "https://source.example/like", target)
+	require.Equal(t, TypeLike, result.Type)
+}
+
+func TestParse_OtherTarget(t *testing.T) {
+
+	body := `<div class="h-entry"><a class="u-like-of" href="https://other.example/">Link</a> <a href="` + target + `">Mention</a></div>`
+
+	result := Parse(strings.NewReader(body), "https://source.example/entry", target)
+	require.Equal(t, TypeMention, result.Type)
+}
+
+func TestParse_NoMicroformats(t *testing.T) {
+
+	result := Parse(strings.NewReader(`<html><body><a href="`+target+`">Link</a></body></html>`), "https://source.example/page", target)
+	require.Equal(t, TypeMention, result.Type)
+	require.Equal(t, "https://source.example/page", result.URL)
+	require.Empty(t, result.Content)
+}
@@ -0,0 +1,27 @@
+// Package i18n translates user-facing text using message catalogs that are
+// organized by locale, with CLDR plural rules and {name} placeholders.
+package i18n
+
+// DefaultLocale is used when no other locale can be negotiated
+const DefaultLocale = "en"
+
+// ParamCount is the parameter that selects a plural form
+const ParamCount = "count"
+
+// PluralZero is the CLDR plural category for zero items (in languages that distinguish it)
+const PluralZero = "zero"
+
+// PluralOne is the CLDR plural category for singular items
+const PluralOne = "one"
+
+// PluralTwo is the CLDR plural category for dual items (in languages that distinguish it)
+const PluralTwo = "two"
+
+// PluralFew is the CLDR plural category for small quantities (in languages that distinguish it)
+const PluralFew = "few"
+
+// PluralMany is the CLDR plural category for large quantities (in languages that distinguish it)
+const PluralMany = "many"
+
+// PluralOther is the CLDR plural category for everything else.  Every Message should define it.
+const PluralOther = "other"
@@ -0,0 +1,90 @@
+package i18n
+
+import (
+	"strings"
+
+	"github.com/benpate/rosetta/convert"
+)
+
+// Message is a single translated string.  Messages that change with a count
+// contain one form for each plural category (zero, one, two, few, many, other).
+// Messages that do not change only contain the "other" form.
+type Message map[string]string
+
+// NewMessage returns a Message that has the same text for every count
+func NewMessage(text string) Message {
+	return Message{PluralOther: text}
+}
+
+// Form returns the text to use for the provided plural category,
+// falling back to the "other" category if it is not defined.
+func (message Message) Form(category string) string {
+
+	if text, ok := message[category]; ok {
+		return text
+	}
+
+	return message[PluralOther]
+}
+
+// Format selects the correct plural form for the locale and count, then
+// replaces every {name} placeholder with the matching parameter.  A "zero"
+// form is always used for a count of zero, even in locales (like English)
+// whose plural rules do not include it.
+func (message Message) Format(locale string, params map[string]any) string {
+
+	category := PluralOther
+
+	if value, ok := params[ParamCount]; ok {
+		count := convert.Int(value)
+		category = PluralCategory(locale, count)
+
+		if _, hasZero := message[PluralZero]; hasZero && (count == 0) {
+			category = PluralZero
+		}
+	}
+
+	return interpolate(message.Form(category), params)
+}
+
+// interpolate replaces each {name} placeholder in the text with the matching parameter.
+// Placeholders that do not match a parameter are left unchanged.
+func interpolate(text string, params map[string]any) string {
+
+	if len(params) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+
+	var result strings.Builder
+	result.Grow(len(text))
+
+	for {
+		start := strings.IndexByte(text, '{')
+
+		if start < 0 {
+			break
+		}
+
+		end := strings.IndexByte(text[start:], '}')
+
+		if end < 0 {
+			break
+		}
+
+		end += start
+		name := text[start+1 : end]
+
+		result.WriteString(text[:start])
+
+		if value, ok := params[name]; ok {
+			result.WriteString(convert.String(value))
+		} else {
+			result.WriteString(text[start : end+1])
+		}
+
+		text = text[end+1:]
+	}
+
+	result.WriteString(text)
+	return result.String()
+}
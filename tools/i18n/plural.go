@@ -0,0 +1,111 @@
+package i18n
+
+import "strings"
+
+// PluralCategory returns the CLDR plural category (zero, one, two, few, many, other)
+// that a language uses for the provided (whole number) count.
+// https://www.unicode.org/cldr/charts/latest/supplemental/language_plural_rules.html
+func PluralCategory(locale string, count int) string {
+
+	if count < 0 {
+		count = -count
+	}
+
+	mod10 := count % 10
+	mod100 := count % 100
+
+	switch baseLanguage(locale) {
+
+	// Languages that never change with a count
+	case "ja", "zh", "ko", "vi", "th", "id", "ms", "lo", "my", "km":
+		return PluralOther
+
+	// Languages that treat zero as singular
+	case "fr", "pt", "hi", "bn", "fa", "gu", "kn", "mr", "zu", "am":
+		if count <= 1 {
+			return PluralOne
+		}
+		return PluralOther
+
+	// East Slavic languages
+	case "ru", "uk", "be":
+		if mod10 == 1 && mod100 != 11 {
+			return PluralOne
+		}
+		if mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14) {
+			return PluralFew
+		}
+		return PluralMany
+
+	case "pl":
+		if count == 1 {
+			return PluralOne
+		}
+		if mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14) {
+			return PluralFew
+		}
+		return PluralMany
+
+	case "cs", "sk":
+		if count == 1 {
+			return PluralOne
+		}
+		if count >= 2 && count <= 4 {
+			return PluralFew
+		}
+		return PluralOther
+
+	case "ar":
+		switch {
+		case count == 0:
+			return PluralZero
+		case count == 1:
+			return PluralOne
+		case count == 2:
+			return PluralTwo
+		case mod100 >= 3 && mod100 <= 10:
+			return PluralFew
+		case mod100 >= 11:
+			return PluralMany
+		}
+		return PluralOther
+
+	case "he":
+		switch count {
+		case 1:
+			return PluralOne
+		case 2:
+			return PluralTwo
+		}
+		return PluralOther
+	}
+
+	// Most other languages (including English, German, Spanish, Italian,
+	// and the Scandinavian languages) only distinguish "one" from "other"
+	if count == 1 {
+		return PluralOne
+	}
+
+	return PluralOther
+}
+
+// isPluralCategory returns TRUE if the value is one of the CLDR plural categories
+func isPluralCategory(value string) bool {
+	switch value {
+	case PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther:
+		return true
+	}
+	return false
+}
+
+// baseLanguage returns the language portion of a locale (e.g. "pt" for "pt-BR")
+func baseLanguage(locale string) string {
+
+	locale = strings.ToLower(locale)
+
+	if index := strings.IndexAny(locale, "-_"); index > 0 {
+		return locale[:index]
+	}
+
+	return locale
+}
@@ -0,0 +1,290 @@
+package i18n
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/benpate/rosetta/convert"
+	"golang.org/x/text/language"
+)
+
+// Translator contains the messages for every locale, and translates keys into text.
+// Translators are built once (by calling Add) and are then safe for concurrent reads.
+type Translator struct {
+	defaultLocale string
+	messages      map[string]map[string]Message // map of locale => key => message
+	locales       []string                      // all locales with messages, default locale first
+	matcher       language.Matcher              // negotiates between requested and available locales
+}
+
+// NewTranslator returns a fully initialized Translator that falls back to the provided locale
+func NewTranslator(defaultLocale string) *Translator {
+
+	defaultLocale = NormalizeLocale(defaultLocale)
+
+	if defaultLocale == "" {
+		defaultLocale = DefaultLocale
+	}
+
+	result := &Translator{
+		defaultLocale: defaultLocale,
+		messages:      make(map[string]map[string]Message),
+	}
+
+	result.compile()
+	return result
+}
+
+/******************************************
+ * Loading Messages
+ ******************************************/
+
+// Add merges a set of messages into a locale.  Messages that have already
+// been defined for the locale are replaced.
+func (translator *Translator) Add(locale string, messages map[string]Message) {
+
+	locale = NormalizeLocale(locale)
+
+	if locale == "" {
+		return
+	}
+
+	existing, ok := translator.messages[locale]
+
+	if !ok {
+		existing = make(map[string]Message, len(messages))
+		translator.messages[locale] = existing
+	}
+
+	for key, message := range messages {
+		existing[key] = message
+	}
+
+	translator.compile()
+}
+
+// AddMap merges a nested map of messages (usually read from a JSON/HJSON file) into a locale.
+// Nested objects are flattened into dot-separated keys, and objects whose keys are all
+// plural categories (zero, one, two, few, many, other) are treated as plural messages.
+func (translator *Translator) AddMap(locale string, value map[string]any) {
+	messages := make(map[string]Message)
+	flatten(messages, "", value)
+	translator.Add(locale, messages)
+}
+
+// compile updates the list of locales and the matcher after messages are added
+func (translator *Translator) compile() {
+
+	locales := make([]string, 0, len(translator.messages)+1)
+	locales = append(locales, translator.defaultLocale)
+
+	others := make([]string, 0, len(translator.messages))
+	for locale := range translator.messages {
+		if locale != translator.defaultLocale {
+			others = append(others, locale)
+		}
+	}
+
+	sort.Strings(others)
+	locales = append(locales, others...)
+
+	// The first tag is the fallback when nothing matches
+	tags := make([]language.Tag, 0, len(locales))
+	for _, locale := range locales {
+		tags = append(tags, language.Make(locale))
+	}
+
+	translator.locales = locales
+	translator.matcher = language.NewMatcher(tags)
+}
+
+/******************************************
+ * Translating Messages
+ ******************************************/
+
+// DefaultLocale returns the locale that is used when no other locale matches
+func (translator *Translator) DefaultLocale() string {
+	return translator.defaultLocale
+}
+
+// Locales returns all locales that have messages, beginning with the default locale
+func (translator *Translator) Locales() []string {
+	return translator.locales
+}
+
+// Lookup returns the message for a key, searching the requested locale, then
+// its base language (e.g. "pt" for "pt-BR"), then the default locale.
+func (translator *Translator) Lookup(locale string, key string) (Message, bool) {
+
+	for _, candidate := range translator.fallbacks(locale) {
+		if message, ok := translator.messages[candidate][key]; ok {
+			return message, true
+		}
+	}
+
+	return nil, false
+}
+
+// Translate returns the text for a key in the requested locale.  Parameters may be
+// a single map, or a list of name/value pairs.  The "count" parameter selects the
+// plural form, and every parameter replaces its matching {name} placeholder.
+// If the key is not defined in any locale, then the key itself is returned.
+func (translator *Translator) Translate(locale string, key string, params ...any) string {
+
+	message, ok := translator.Lookup(locale, key)
+
+	if !ok {
+		return key
+	}
+
+	return message.Format(locale, Params(params...))
+}
+
+// Negotiate returns the best available locale for a list of preferences.  Each preference
+// may be a single locale (like a User's saved setting) or an Accept-Language header.
+// Earlier preferences take priority over later ones.
+func (translator *Translator) Negotiate(preferences ...string) string {
+
+	for _, preference := range preferences {
+
+		if preference == "" {
+			continue
+		}
+
+		tags, _, err := language.ParseAcceptLanguage(preference)
+
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+
+		if _, index, confidence := translator.matcher.Match(tags...); confidence != language.No {
+			return translator.locales[index]
+		}
+	}
+
+	return translator.defaultLocale
+}
+
+// fallbacks returns the list of locales to search for a requested locale
+func (translator *Translator) fallbacks(locale string) []string {
+
+	locale = NormalizeLocale(locale)
+	result := make([]string, 0, 3)
+
+	if locale != "" {
+		result = append(result, locale)
+
+		if base := baseLanguage(locale); base != locale {
+			result = append(result, base)
+		}
+	}
+
+	if locale != translator.defaultLocale {
+		result = append(result, translator.defaultLocale)
+	}
+
+	return result
+}
+
+/******************************************
+ * Helper Functions
+ ******************************************/
+
+// NormalizeLocale converts a locale into its canonical BCP 47 form (e.g. "pt_br" => "pt-BR").
+// Invalid locales return an empty string.
+func NormalizeLocale(locale string) string {
+
+	locale = strings.TrimSpace(strings.ReplaceAll(locale, "_", "-"))
+
+	if locale == "" {
+		return ""
+	}
+
+	tag, err := language.Parse(locale)
+
+	if err != nil {
+		return ""
+	}
+
+	return tag.String()
+}
+
+// Params converts a list of template parameters into a map.  The list may contain
+// a single map, or alternating names and values.
+func Params(params ...any) map[string]any {
+
+	if len(params) == 0 {
+		return nil
+	}
+
+	if len(params) == 1 {
+		switch typed := params[0].(type) {
+		case map[string]any:
+			return typed
+		case map[string]string:
+			result := make(map[string]any, len(typed))
+			for key, value := range typed {
+				result[key] = value
+			}
+			return result
+		}
+	}
+
+	result := make(map[string]any, len(params)/2)
+
+	for index := 0; index+1 < len(params); index += 2 {
+		result[convert.String(params[index])] = params[index+1]
+	}
+
+	return result
+}
+
+// flatten converts a nested map into a flat map of dot-separated keys
+func flatten(result map[string]Message, prefix string, value map[string]any) {
+
+	for key, item := range value {
+
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch typed := item.(type) {
+
+		case string:
+			result[key] = NewMessage(typed)
+
+		case map[string]any:
+			if message, ok := pluralMessage(typed); ok {
+				result[key] = message
+			} else {
+				flatten(result, key, typed)
+			}
+
+		default:
+			result[key] = NewMessage(convert.String(typed))
+		}
+	}
+}
+
+// pluralMessage returns a Message if every key in the map is a plural category with a string value
+func pluralMessage(value map[string]any) (Message, bool) {
+
+	if len(value) == 0 {
+		return nil, false
+	}
+
+	result := make(Message, len(value))
+
+	for key, item := range value {
+
+		text, ok := item.(string)
+
+		if !ok || !isPluralCategory(key) {
+			return nil, false
+		}
+
+		result[key] = text
+	}
+
+	return result, true
+}
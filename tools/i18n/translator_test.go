@@ -0,0 +1,120 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testTranslator() *Translator {
+
+	translator := NewTranslator("en")
+
+	translator.AddMap("en", map[string]any{
+		"greeting": "Hello, {name}!",
+		"stream": map[string]any{
+			"replies": map[string]any{
+				"one":   "{count} reply",
+				"other": "{count} replies",
+			},
+			"edit": "Edit",
+		},
+	})
+
+	translator.AddMap("fr", map[string]any{
+		"greeting": "Bonjour, {name} !",
+		"stream": map[string]any{
+			"replies": map[string]any{
+				"one":   "{count} réponse",
+				"other": "{count} réponses",
+			},
+		},
+	})
+
+	translator.AddMap("pt_BR", map[string]any{
+		"greeting": "Olá, {name}!",
+	})
+
+	return translator
+}
+
+func TestTranslate(t *testing.T) {
+
+	translator := testTranslator()
+
+	require.Equal(t, "Hello, Alice!", translator.Translate("en", "greeting", "name", "Alice"))
+	require.Equal(t, "Bonjour, Alice !", translator.Translate("fr", "greeting", map[string]any{"name": "Alice"}))
+	require.Equal(t, "Olá, Alice!", translator.Translate("pt-BR", "greeting", "name", "Alice"))
+
+	// Missing placeholders are left in place
+	require.Equal(t, "Hello, {name}!", translator.Translate("en", "greeting"))
+
+	// Regional locales fall back to their base language
+	require.Equal(t, "Bonjour, Bob !", translator.Translate("fr-CA", "greeting", "name", "Bob"))
+
+	// Missing messages fall back to the default locale, then to the key itself
+	require.Equal(t, "Edit", translator.Translate("fr", "stream.edit"))
+	require.Equal(t, "missing.key", translator.Translate("fr", "missing.key"))
+}
+
+func TestTranslate_Plural(t *testing.T) {
+
+	translator := testTranslator()
+
+	require.Equal(t, "0 replies", translator.Translate("en", "stream.replies", "count", 0))
+	require.Equal(t, "1 reply", translator.Translate("en", "stream.replies", "count", 1))
+	require.Equal(t, "2 replies", translator.Translate("en", "stream.replies", "count", 2))
+
+	// French treats zero as singular
+	require.Equal(t, "0 réponse", translator.Translate("fr", "stream.replies", "count", 0))
+	require.Equal(t, "5 réponses", translator.Translate("fr", "stream.replies", "count", 5))
+
+	// An explicit "zero" form overrides the locale's plural rules
+	message := Message{PluralZero: "No replies", PluralOne: "{count} reply", PluralOther: "{count} replies"}
+	require.Equal(t, "No replies", message.Format("en", Params("count", 0)))
+	require.Equal(t, "1 reply", message.Format("en", Params("count", 1)))
+}
+
+func TestPluralCategory(t *testing.T) {
+
+	require.Equal(t, PluralOne, PluralCategory("en-US", 1))
+	require.Equal(t, PluralOther, PluralCategory("en", 0))
+	require.Equal(t, PluralOther, PluralCategory("ja", 1))
+
+	require.Equal(t, PluralOne, PluralCategory("ru", 21))
+	require.Equal(t, PluralFew, PluralCategory("ru", 22))
+	require.Equal(t, PluralMany, PluralCategory("ru", 11))
+	require.Equal(t, PluralMany, PluralCategory("ru", 25))
+
+	require.Equal(t, PluralOne, PluralCategory("pl", 1))
+	require.Equal(t, PluralMany, PluralCategory("pl", 21))
+
+	require.Equal(t, PluralZero, PluralCategory("ar", 0))
+	require.Equal(t, PluralTwo, PluralCategory("ar", 2))
+	require.Equal(t, PluralFew, PluralCategory("ar", 105))
+	require.Equal(t, PluralMany, PluralCategory("ar", 11))
+}
+
+func TestNegotiate(t *testing.T) {
+
+	translator := testTranslator()
+
+	require.Equal(t, []string{"en", "fr", "pt-BR"}, translator.Locales())
+
+	// User preference wins over the browser
+	require.Equal(t, "fr", translator.Negotiate("fr", "pt-BR,pt;q=0.9"))
+
+	// Unsupported preferences fall through to the Accept-Language header
+	require.Equal(t, "pt-BR", translator.Negotiate("de", "de-DE,pt-BR;q=0.8,en;q=0.5"))
+	require.Equal(t, "fr", translator.Negotiate("", "fr-CA,en;q=0.5"))
+
+	// Nothing matches, so use the default
+	require.Equal(t, "en", translator.Negotiate("", "xx"))
+	require.Equal(t, "en", translator.Negotiate())
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	require.Equal(t, "pt-BR", NormalizeLocale("pt_br"))
+	require.Equal(t, "en", NormalizeLocale(" en "))
+	require.Equal(t, "", NormalizeLocale("not a locale!"))
+}
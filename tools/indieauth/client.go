@@ -0,0 +1,137 @@
+package indieauth
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/EmissarySocial/emissary/tools/dialer"
+	"github.com/benpate/derp"
+	"github.com/benpate/remote"
+	"willnorris.com/go/microformats"
+)
+
+// Client contains the information that an IndieAuth client publishes about itself
+// https://indieauth.spec.indieweb.org/#client-information-discovery
+type Client struct {
+	ClientID     string   // URL that identifies this client
+	Name         string   // Human-friendly name of the client
+	URL          string   // Homepage of the client
+	LogoURL      string   // URL of the client's logo
+	RedirectURIs []string // Additional redirect URIs published by the client
+}
+
+// httpClient downloads client information.  It can only connect to public IP addresses,
+// so that client_id URLs cannot be used to reach internal services.
+var httpClient = &http.Client{
+	Transport: dialer.Transport(),
+	Timeout:   10 * time.Second,
+}
+
+// FetchClient downloads the client_id URL and parses the client information from it.
+// Additional options (such as response size limits) are applied to the request.
+func FetchClient(clientID string, options ...remote.Option) (Client, error) {
+
+	const location = "indieauth.FetchClient"
+
+	if err := ValidateClientID(clientID); err != nil {
+		return Client{}, derp.Wrap(err, location, "Invalid client_id", clientID)
+	}
+
+	var body string
+	txn := remote.Get(clientID).
+		Client(httpClient).
+		With(options...).
+		Result(&body)
+
+	if err := txn.Send(); err != nil {
+		return Client{}, derp.Wrap(err, location, "Error retrieving client information", clientID)
+	}
+
+	return ParseClient(strings.NewReader(body), clientID), nil
+}
+
+// ParseClient reads client information from the h-app (or h-x-app) microformats
+// in an HTML document.  Missing values default to the client_id URL.
+func ParseClient(reader io.Reader, clientID string) Client {
+
+	result := Client{
+		ClientID:     clientID,
+		RedirectURIs: make([]string, 0),
+	}
+
+	baseURL, _ := url.Parse(clientID)
+	data := microformats.Parse(reader, baseURL)
+
+	if data != nil {
+
+		result.RedirectURIs = append(result.RedirectURIs, data.Rels["redirect_uri"]...)
+
+		if app := findApp(data.Items); app != nil {
+			result.Name = firstProperty(app, "name")
+			result.URL = firstProperty(app, "url")
+			result.LogoURL = firstProperty(app, "logo")
+		}
+	}
+
+	// Default values use the client_id
+	if result.Name == "" {
+		result.Name = baseURL.Hostname()
+	}
+
+	if result.URL == "" {
+		result.URL = clientID
+	}
+
+	return result
+}
+
+// findApp returns the first h-app (or legacy h-x-app) microformat in a document
+func findApp(items []*microformats.Microformat) *microformats.Microformat {
+
+	for _, item := range items {
+
+		for _, itemType := range item.Type {
+			if (itemType == "h-app") || (itemType == "h-x-app") {
+				return item
+			}
+		}
+
+		if result := findApp(item.Children); result != nil {
+			return result
+		}
+	}
+
+	return nil
+}
+
+// firstProperty returns the first value of a microformat property as a string
+func firstProperty(item *microformats.Microformat, name string) string {
+
+	values := item.Properties[name]
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	switch value := values[0].(type) {
+
+	case string:
+		return value
+
+	// Images with alt text are returned as maps
+	case map[string]string:
+		return value["value"]
+
+	case map[string]any:
+		result, _ := value["value"].(string)
+		return result
+
+	case *microformats.Microformat:
+		return value.Value
+	}
+
+	return ""
+}
@@ -0,0 +1,125 @@
+// Package indieauth contains helpers for the IndieAuth extensions to OAuth 2.0,
+// which identify users and clients by URL instead of by pre-registered IDs.
+// https://indieauth.spec.indieweb.org/
+package indieauth
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/benpate/derp"
+)
+
+// IsClientID returns TRUE if the value looks like an IndieAuth client_id (which is a URL)
+func IsClientID(value string) bool {
+	return strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://")
+}
+
+// CanonicalURL normalizes a user-entered URL (like a profile URL or client_id).
+// It adds a missing scheme, lowercases the hostname, and adds a "/" path to bare domains.
+// https://indieauth.spec.indieweb.org/#url-canonicalization
+func CanonicalURL(value string) (string, error) {
+
+	const location = "indieauth.CanonicalURL"
+
+	value = strings.TrimSpace(value)
+
+	if value == "" {
+		return "", derp.NewBadRequestError(location, "URL is required")
+	}
+
+	if !IsClientID(value) {
+		value = "https://" + value
+	}
+
+	parsed, err := url.Parse(value)
+
+	if err != nil {
+		return "", derp.NewBadRequestError(location, "Invalid URL", value)
+	}
+
+	if parsed.Host == "" {
+		return "", derp.NewBadRequestError(location, "URL must include a hostname", value)
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+
+	return parsed.String(), nil
+}
+
+// ValidateClientID verifies that a client_id follows the rules in the IndieAuth spec.
+// https://indieauth.spec.indieweb.org/#client-identifier
+func ValidateClientID(clientID string) error {
+
+	const location = "indieauth.ValidateClientID"
+
+	parsed, err := url.Parse(clientID)
+
+	if err != nil {
+		return derp.NewBadRequestError(location, "Invalid client_id", clientID)
+	}
+
+	// RULE: Must use http or https
+	if (parsed.Scheme != "https") && (parsed.Scheme != "http") {
+		return derp.NewBadRequestError(location, "client_id must use http or https", clientID)
+	}
+
+	// RULE: Must have a path, and must not include dot segments
+	if (parsed.Path == "") || strings.Contains("/"+parsed.Path+"/", "/./") || strings.Contains("/"+parsed.Path+"/", "/../") {
+		return derp.NewBadRequestError(location, "client_id must have a valid path", clientID)
+	}
+
+	// RULE: Must not include a fragment, username, or password
+	if (parsed.Fragment != "") || (parsed.User != nil) {
+		return derp.NewBadRequestError(location, "client_id must not include a fragment or user info", clientID)
+	}
+
+	// RULE: Must be a domain name, not an IP address.  The spec also allows loopback
+	// addresses, but this server will not download client information from them.
+	if ip := net.ParseIP(parsed.Hostname()); ip != nil {
+		return derp.NewBadRequestError(location, "client_id must not be an IP address", clientID)
+	}
+
+	if hostname := strings.ToLower(parsed.Hostname()); (hostname == "localhost") || strings.HasSuffix(hostname, ".localhost") {
+		return derp.NewBadRequestError(location, "client_id must not be a local hostname", clientID)
+	}
+
+	return nil
+}
+
+// IsRedirectAllowed returns TRUE if the redirect_uri is allowed for this client.  Redirects
+// on the same scheme, host, and port as the client_id are always allowed.  Other redirects
+// must be published by the client (using <link rel="redirect_uri">).
+// https://indieauth.spec.indieweb.org/#redirect-url
+func IsRedirectAllowed(clientID string, redirectURI string, registered []string) bool {
+
+	client, err := url.Parse(clientID)
+
+	if err != nil {
+		return false
+	}
+
+	redirect, err := url.Parse(redirectURI)
+
+	if err != nil {
+		return false
+	}
+
+	if (client.Scheme == redirect.Scheme) && strings.EqualFold(client.Host, redirect.Host) {
+		return true
+	}
+
+	for _, value := range registered {
+		if value == redirectURI {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,41 @@
+package indieauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+// CodeChallengeMethodS256 is the only PKCE method that IndieAuth requires
+const CodeChallengeMethodS256 = "S256"
+
+// CodeChallengeMethodPlain sends the code verifier without hashing it
+const CodeChallengeMethodPlain = "plain"
+
+// IsChallengeMethod returns TRUE if the PKCE method is supported
+func IsChallengeMethod(method string) bool {
+	return (method == CodeChallengeMethodS256) || (method == CodeChallengeMethodPlain)
+}
+
+// CodeChallenge calculates the PKCE code challenge for a code verifier
+// https://www.rfc-editor.org/rfc/rfc7636#section-4.2
+func CodeChallenge(method string, verifier string) string {
+
+	if method == CodeChallengeMethodPlain {
+		return verifier
+	}
+
+	hash := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// VerifyCodeChallenge returns TRUE if the code verifier matches the code challenge
+func VerifyCodeChallenge(method string, challenge string, verifier string) bool {
+
+	if (challenge == "") || (verifier == "") || !IsChallengeMethod(method) {
+		return false
+	}
+
+	expected := CodeChallenge(method, verifier)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
@@ -0,0 +1,176 @@
+package mediaproc
+
+import (
+	"image"
+	"math"
+	"strings"
+
+	"github.com/benpate/derp"
+)
+
+// blurhashCharacters is the base83 alphabet used by the blurhash format
+const blurhashCharacters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// blurhashSamples is the maximum number of pixels (per side) that are sampled from
+// the image.  Blurhashes are very low resolution, so larger samples add nothing.
+const blurhashSamples = 64
+
+// Blurhash calculates the blurhash (https://blurha.sh) of an image, using the
+// provided number of horizontal and vertical components (between 1 and 9).
+func Blurhash(img image.Image, xComponents int, yComponents int) (string, error) {
+
+	const location = "mediaproc.Blurhash"
+
+	if (xComponents < 1) || (xComponents > 9) || (yComponents < 1) || (yComponents > 9) {
+		return "", derp.NewInternalError(location, "Blurhash components must be between 1 and 9", xComponents, yComponents)
+	}
+
+	pixels, width, height := samplePixels(img, blurhashSamples)
+
+	if (width == 0) || (height == 0) {
+		return "", derp.NewBadRequestError(location, "Image is empty")
+	}
+
+	// Calculate the DCT factors for each component
+	factors := make([][3]float64, 0, xComponents*yComponents)
+
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+
+			normalization := 2.0
+			if (i == 0) && (j == 0) {
+				normalization = 1.0
+			}
+
+			var r, g, b float64
+
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := math.Cos(math.Pi*float64(i)*float64(x)/float64(width)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(height))
+
+					pixel := pixels[y*width+x]
+					r += basis * pixel[0]
+					g += basis * pixel[1]
+					b += basis * pixel[2]
+				}
+			}
+
+			scale := normalization / float64(width*height)
+			factors = append(factors, [3]float64{r * scale, g * scale, b * scale})
+		}
+	}
+
+	// Encode the factors into a string
+	var result strings.Builder
+
+	result.WriteString(encode83((xComponents-1)+(yComponents-1)*9, 1))
+
+	maximumValue := 1.0
+
+	if len(factors) > 1 {
+
+		actualMaximum := 0.0
+		for _, factor := range factors[1:] {
+			for _, value := range factor {
+				actualMaximum = math.Max(actualMaximum, math.Abs(value))
+			}
+		}
+
+		quantisedMaximum := clampInt(int(math.Floor(actualMaximum*166-0.5)), 0, 82)
+		maximumValue = float64(quantisedMaximum+1) / 166
+		result.WriteString(encode83(quantisedMaximum, 1))
+
+	} else {
+		result.WriteString(encode83(0, 1))
+	}
+
+	result.WriteString(encode83(encodeDC(factors[0]), 4))
+
+	for _, factor := range factors[1:] {
+		result.WriteString(encode83(encodeAC(factor, maximumValue), 2))
+	}
+
+	return result.String(), nil
+}
+
+// samplePixels returns a grid of (at most) size x size pixels from the image,
+// converted into linear RGB values.
+func samplePixels(img image.Image, size int) ([][3]float64, int, int) {
+
+	bounds := img.Bounds()
+	width := min(bounds.Dx(), size)
+	height := min(bounds.Dy(), size)
+
+	result := make([][3]float64, 0, width*height)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sourceX := bounds.Min.X + (x*bounds.Dx())/width
+			sourceY := bounds.Min.Y + (y*bounds.Dy())/height
+			r, g, b, _ := img.At(sourceX, sourceY).RGBA()
+			result = append(result, [3]float64{
+				sRGBToLinear(int(r >> 8)),
+				sRGBToLinear(int(g >> 8)),
+				sRGBToLinear(int(b >> 8)),
+			})
+		}
+	}
+
+	return result, width, height
+}
+
+func encodeDC(value [3]float64) int {
+	return (linearToSRGB(value[0]) << 16) + (linearToSRGB(value[1]) << 8) + linearToSRGB(value[2])
+}
+
+func encodeAC(value [3]float64, maximumValue float64) int {
+
+	quantise := func(component float64) int {
+		return clampInt(int(math.Floor(signPow(component/maximumValue, 0.5)*9+9.5)), 0, 18)
+	}
+
+	return quantise(value[0])*19*19 + quantise(value[1])*19 + quantise(value[2])
+}
+
+func encode83(value int, length int) string {
+
+	result := make([]byte, length)
+
+	for index := length - 1; index >= 0; index-- {
+		result[index] = blurhashCharacters[value%83]
+		value = value / 83
+	}
+
+	return string(result)
+}
+
+func sRGBToLinear(value int) float64 {
+
+	v := float64(value) / 255
+
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(value float64) int {
+
+	v := math.Max(0, math.Min(1, value))
+
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(value float64, exponent float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(value), exponent), value)
+}
+
+func clampInt(value int, minimum int, maximum int) int {
+	return max(minimum, min(value, maximum))
+}
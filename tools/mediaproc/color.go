@@ -0,0 +1,67 @@
+package mediaproc
+
+import (
+	"fmt"
+	"image"
+)
+
+// DominantColor returns the most common color in an image, as a hex string (#rrggbb).
+// Colors are grouped into buckets (4 bits per channel) so that similar shades are
+// counted together, and the result is the average color of the largest bucket.
+func DominantColor(img image.Image) string {
+
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+
+	bounds := img.Bounds()
+	width := min(bounds.Dx(), blurhashSamples)
+	height := min(bounds.Dy(), blurhashSamples)
+
+	if (width == 0) || (height == 0) {
+		return ""
+	}
+
+	buckets := make(map[int]*bucket)
+	var best *bucket
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+
+			sourceX := bounds.Min.X + (x*bounds.Dx())/width
+			sourceY := bounds.Min.Y + (y*bounds.Dy())/height
+			r, g, b, a := img.At(sourceX, sourceY).RGBA()
+
+			// Ignore (mostly) transparent pixels
+			if a < 0x8000 {
+				continue
+			}
+
+			r, g, b = r>>8, g>>8, b>>8
+			key := int(r>>4)<<8 | int(g>>4)<<4 | int(b>>4)
+
+			current, exists := buckets[key]
+
+			if !exists {
+				current = &bucket{}
+				buckets[key] = current
+			}
+
+			current.count++
+			current.r += int(r)
+			current.g += int(g)
+			current.b += int(b)
+
+			if (best == nil) || (current.count > best.count) {
+				best = current
+			}
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
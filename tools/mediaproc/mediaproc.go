@@ -0,0 +1,64 @@
+// Package mediaproc contains the tools used to post-process uploaded media files.
+// It reads the real dimensions and duration of images, audio, and video files,
+// calculates blurhash placeholders and dominant colors for images, and removes
+// privacy-sensitive metadata (EXIF, GPS, XMP) from originals.
+package mediaproc
+
+import (
+	"bytes"
+	"image"
+
+	// Register image decoders that are used to read uploaded files
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"github.com/benpate/derp"
+)
+
+// Info contains the values that are calculated from a media file
+type Info struct {
+	Width    int    // Width of the image or video, in pixels
+	Height   int    // Height of the image or video, in pixels
+	Duration int    // Duration of the audio or video, in seconds
+	Blurhash string // Blurhash placeholder for the image
+	Color    string // Dominant color of the image, as a hex string (#rrggbb)
+}
+
+// AnalyzeImage decodes an image and returns its dimensions, blurhash, and dominant color.
+func AnalyzeImage(data []byte) (Info, error) {
+
+	const location = "mediaproc.AnalyzeImage"
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		return Info{}, derp.Wrap(err, location, "Error decoding image")
+	}
+
+	bounds := img.Bounds()
+
+	// Use 4x3 components (or 3x4 for portrait images) to match common client defaults
+	xComponents, yComponents := 4, 3
+
+	if bounds.Dy() > bounds.Dx() {
+		xComponents, yComponents = 3, 4
+	}
+
+	blurhash, err := Blurhash(img, xComponents, yComponents)
+
+	if err != nil {
+		return Info{}, derp.Wrap(err, location, "Error calculating blurhash")
+	}
+
+	result := Info{
+		Width:    bounds.Dx(),
+		Height:   bounds.Dy(),
+		Blurhash: blurhash,
+		Color:    DominantColor(img),
+	}
+
+	return result, nil
+}
@@ -0,0 +1,230 @@
+package mediaproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlurhash(t *testing.T) {
+
+	img := testImage(32, 16, color.RGBA{R: 255, A: 255})
+
+	result, err := Blurhash(img, 4, 3)
+	require.Nil(t, err)
+	require.Equal(t, 6+2*(4*3-1), len(result))
+
+	// A solid red image has no AC components, and a DC value of pure red
+	require.Equal(t, "L", result[0:1])
+	require.Equal(t, encode83(0xFF0000, 4), result[2:6])
+
+	_, err = Blurhash(img, 0, 10)
+	require.NotNil(t, err)
+}
+
+func TestDominantColor(t *testing.T) {
+
+	img := testImage(10, 10, color.RGBA{R: 0x20, G: 0x40, B: 0x80, A: 255})
+
+	// Paint a minority of pixels a different color
+	for x := 0; x < 10; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	}
+
+	require.Equal(t, "#204080", DominantColor(img))
+}
+
+func TestAnalyzeImage(t *testing.T) {
+
+	var buffer bytes.Buffer
+	require.Nil(t, png.Encode(&buffer, testImage(30, 20, color.RGBA{B: 255, A: 255})))
+
+	info, err := AnalyzeImage(buffer.Bytes())
+	require.Nil(t, err)
+	require.Equal(t, 30, info.Width)
+	require.Equal(t, 20, info.Height)
+	require.Equal(t, "#0000ff", info.Color)
+	require.NotEmpty(t, info.Blurhash)
+}
+
+func TestStripMetadata_JPEG(t *testing.T) {
+
+	var buffer bytes.Buffer
+	require.Nil(t, jpeg.Encode(&buffer, testImage(8, 8, color.RGBA{G: 255, A: 255}), nil))
+	original := buffer.Bytes()
+
+	// Insert EXIF (with GPS-like text) and a comment right after the SOI marker
+	exif := testJPEGSegment(0xE1, append([]byte("Exif\x00\x00"), testTIFF(1)...))
+	comment := testJPEGSegment(0xFE, []byte("GPS 45.0 -93.0"))
+
+	withMetadata := append([]byte{}, original[0:2]...)
+	withMetadata = append(withMetadata, exif...)
+	withMetadata = append(withMetadata, comment...)
+	withMetadata = append(withMetadata, original[2:]...)
+
+	result, err := StripMetadata(withMetadata)
+	require.Nil(t, err)
+	require.Equal(t, original, result)
+
+	// Result is still a valid image
+	_, err = jpeg.Decode(bytes.NewReader(result))
+	require.Nil(t, err)
+}
+
+func TestStripMetadata_JPEG_Orientation(t *testing.T) {
+
+	var buffer bytes.Buffer
+	require.Nil(t, jpeg.Encode(&buffer, testImage(16, 8, color.RGBA{G: 255, A: 255}), nil))
+	original := buffer.Bytes()
+
+	// Orientation 6 means "rotate 90 degrees clockwise"
+	exif := testJPEGSegment(0xE1, append([]byte("Exif\x00\x00"), testTIFF(6)...))
+
+	withMetadata := append([]byte{}, original[0:2]...)
+	withMetadata = append(withMetadata, exif...)
+	withMetadata = append(withMetadata, original[2:]...)
+
+	result, err := StripMetadata(withMetadata)
+	require.Nil(t, err)
+
+	config, err := jpeg.DecodeConfig(bytes.NewReader(result))
+	require.Nil(t, err)
+	require.Equal(t, 8, config.Width)
+	require.Equal(t, 16, config.Height)
+	require.False(t, bytes.Contains(result, []byte("Exif")))
+}
+
+func TestStripMetadata_PNG(t *testing.T) {
+
+	var buffer bytes.Buffer
+	require.Nil(t, png.Encode(&buffer, testImage(4, 4, color.RGBA{R: 255, A: 255})))
+	original := buffer.Bytes()
+
+	// Insert a text chunk after the IHDR chunk (8 byte signature + 25 byte IHDR)
+	text := testPNGChunk("tEXt", []byte("Location\x00Secret Lair"))
+	withMetadata := append([]byte{}, original[0:33]...)
+	withMetadata = append(withMetadata, text...)
+	withMetadata = append(withMetadata, original[33:]...)
+
+	result, err := StripMetadata(withMetadata)
+	require.Nil(t, err)
+	require.Equal(t, original, result)
+}
+
+func TestStripMetadata_WebP(t *testing.T) {
+
+	vp8x := testRIFFChunk("VP8X", []byte{0x08 | 0x04, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	bitstream := testRIFFChunk("VP8L", []byte{1, 2, 3})
+	exif := testRIFFChunk("EXIF", []byte("secret"))
+
+	body := append([]byte("WEBP"), vp8x...)
+	body = append(body, bitstream...)
+	body = append(body, exif...)
+
+	original := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	original = append(original, body...)
+
+	result, err := StripMetadata(original)
+	require.Nil(t, err)
+	require.False(t, bytes.Contains(result, []byte("secret")))
+	require.Equal(t, byte(0), result[20])
+	require.Equal(t, uint32(len(result)-8), binary.LittleEndian.Uint32(result[4:8]))
+}
+
+func TestStripMetadata_Other(t *testing.T) {
+	original := []byte("%PDF-1.4 not an image")
+	result, err := StripMetadata(original)
+	require.Nil(t, err)
+	require.Equal(t, original, result)
+}
+
+func TestOrient(t *testing.T) {
+
+	img := testImage(3, 2, color.RGBA{A: 255})
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	// Rotating clockwise moves the top-left pixel to the top-right
+	result := Orient(img, 6)
+	require.Equal(t, image.Rect(0, 0, 2, 3), result.Bounds())
+	r, _, _, _ := result.At(1, 0).RGBA()
+	require.Equal(t, uint32(0xFFFF), r)
+
+	// Rotating counter-clockwise moves the top-left pixel to the bottom-left
+	result = Orient(img, 8)
+	r, _, _, _ = result.At(0, 2).RGBA()
+	require.Equal(t, uint32(0xFFFF), r)
+}
+
+func TestParseProbe(t *testing.T) {
+
+	output := []byte(`{
+		"streams": [
+			{"codec_type": "audio"},
+			{"codec_type": "video", "width": 600, "height": 600, "disposition": {"attached_pic": 1}},
+			{"codec_type": "video", "width": 1920, "height": 1080, "disposition": {"attached_pic": 0}}
+		],
+		"format": {"duration": "63.600000"}
+	}`)
+
+	info, err := parseProbe(output)
+	require.Nil(t, err)
+	require.Equal(t, 1920, info.Width)
+	require.Equal(t, 1080, info.Height)
+	require.Equal(t, 64, info.Duration)
+}
+
+/******************************************
+ * Test Helpers
+ ******************************************/
+
+func testImage(width int, height int, fill color.Color) *image.RGBA {
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			result.Set(x, y, fill)
+		}
+	}
+	return result
+}
+
+// testTIFF returns a little-endian TIFF block with a single orientation tag
+func testTIFF(orientation uint16) []byte {
+	result := []byte("II*\x00")
+	result = binary.LittleEndian.AppendUint32(result, 8)           // offset of IFD0
+	result = binary.LittleEndian.AppendUint16(result, 1)           // number of entries
+	result = binary.LittleEndian.AppendUint16(result, 0x0112)      // tag: orientation
+	result = binary.LittleEndian.AppendUint16(result, 3)           // type: SHORT
+	result = binary.LittleEndian.AppendUint32(result, 1)           // count
+	result = binary.LittleEndian.AppendUint16(result, orientation) // value
+	result = binary.LittleEndian.AppendUint16(result, 0)           // padding
+	result = binary.LittleEndian.AppendUint32(result, 0)           // no next IFD
+	return result
+}
+
+func testJPEGSegment(marker byte, payload []byte) []byte {
+	result := []byte{0xFF, marker}
+	result = binary.BigEndian.AppendUint16(result, uint16(len(payload)+2))
+	return append(result, payload...)
+}
+
+func testPNGChunk(chunkType string, payload []byte) []byte {
+	result := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	result = append(result, chunkType...)
+	result = append(result, payload...)
+	return append(result, 0, 0, 0, 0) // CRC is not checked when stripping
+}
+
+func testRIFFChunk(chunkType string, payload []byte) []byte {
+	result := append([]byte(chunkType), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+	result = append(result, payload...)
+	if len(payload)%2 == 1 {
+		result = append(result, 0)
+	}
+	return result
+}
@@ -0,0 +1,345 @@
+package mediaproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/benpate/derp"
+)
+
+// StripMetadata removes privacy-sensitive metadata (EXIF, GPS, XMP, IPTC, and text comments)
+// from JPEG, PNG, and WebP images.  Pixel data is copied byte-for-byte, except for images
+// with an EXIF orientation, which are rotated (and re-encoded) so that they still display
+// correctly once the orientation tag is gone.  Other file types are returned unchanged.
+func StripMetadata(data []byte) ([]byte, error) {
+
+	const location = "mediaproc.StripMetadata"
+
+	switch {
+
+	case bytes.HasPrefix(data, jpegSignature):
+
+		result, orientation, err := stripJPEG(data)
+
+		if err != nil {
+			return nil, derp.Wrap(err, location, "Error removing metadata from JPEG")
+		}
+
+		return reorient(result, orientation, "jpeg")
+
+	case bytes.HasPrefix(data, pngSignature):
+
+		result, orientation, err := stripPNG(data)
+
+		if err != nil {
+			return nil, derp.Wrap(err, location, "Error removing metadata from PNG")
+		}
+
+		return reorient(result, orientation, "png")
+
+	case (len(data) >= 12) && bytes.Equal(data[0:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")):
+
+		result, err := stripWebP(data)
+
+		if err != nil {
+			return nil, derp.Wrap(err, location, "Error removing metadata from WebP")
+		}
+
+		return result, nil
+	}
+
+	return data, nil
+}
+
+var jpegSignature = []byte{0xFF, 0xD8}
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}
+
+// stripJPEG removes APP1 (EXIF/XMP), APP13 (IPTC), and COM segments from a JPEG file,
+// returning the remaining bytes and the EXIF orientation (if any).
+func stripJPEG(data []byte) ([]byte, int, error) {
+
+	const location = "mediaproc.stripJPEG"
+
+	result := make([]byte, 0, len(data))
+	result = append(result, jpegSignature...)
+	orientation := 0
+	position := 2
+
+	for {
+
+		if position+2 > len(data) {
+			return nil, 0, derp.NewBadRequestError(location, "Unexpected end of file")
+		}
+
+		if data[position] != 0xFF {
+			return nil, 0, derp.NewBadRequestError(location, "Invalid segment marker", position)
+		}
+
+		marker := data[position+1]
+
+		switch {
+
+		// Padding bytes between segments
+		case marker == 0xFF:
+			position++
+			continue
+
+		// Start of scan (or end of image) means that everything else is image data
+		case (marker == 0xDA) || (marker == 0xD9):
+			return append(result, data[position:]...), orientation, nil
+
+		// Standalone markers have no length
+		case (marker == 0x01) || ((marker >= 0xD0) && (marker <= 0xD7)):
+			result = append(result, data[position:position+2]...)
+			position += 2
+			continue
+		}
+
+		if position+4 > len(data) {
+			return nil, 0, derp.NewBadRequestError(location, "Unexpected end of file")
+		}
+
+		end := position + 2 + int(binary.BigEndian.Uint16(data[position+2:position+4]))
+
+		if end > len(data) {
+			return nil, 0, derp.NewBadRequestError(location, "Segment length exceeds file size", position)
+		}
+
+		switch marker {
+
+		case 0xE1: // APP1: EXIF and XMP
+			if payload := data[position+4 : end]; bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+				orientation = exifOrientation(payload[6:])
+			}
+
+		case 0xED: // APP13: Photoshop/IPTC
+		case 0xFE: // COM: Text comments
+
+		default:
+			result = append(result, data[position:end]...)
+		}
+
+		position = end
+	}
+}
+
+// stripPNG removes eXIf, tEXt, zTXt, iTXt, and tIME chunks from a PNG file,
+// returning the remaining bytes and the EXIF orientation (if any).
+func stripPNG(data []byte) ([]byte, int, error) {
+
+	const location = "mediaproc.stripPNG"
+
+	result := make([]byte, 0, len(data))
+	result = append(result, pngSignature...)
+	orientation := 0
+	position := len(pngSignature)
+
+	for position < len(data) {
+
+		if position+8 > len(data) {
+			return nil, 0, derp.NewBadRequestError(location, "Unexpected end of file")
+		}
+
+		length := int(binary.BigEndian.Uint32(data[position : position+4]))
+		chunkType := string(data[position+4 : position+8])
+		end := position + 12 + length // length + type + data + crc
+
+		if (length < 0) || (end > len(data)) {
+			return nil, 0, derp.NewBadRequestError(location, "Chunk length exceeds file size", position)
+		}
+
+		switch chunkType {
+
+		case "eXIf":
+			orientation = exifOrientation(data[position+8 : position+8+length])
+
+		case "tEXt", "zTXt", "iTXt", "tIME":
+
+		default:
+			result = append(result, data[position:end]...)
+		}
+
+		position = end
+	}
+
+	return result, orientation, nil
+}
+
+// stripWebP removes EXIF and XMP chunks from a WebP file, and clears the
+// corresponding flags in the VP8X header.
+func stripWebP(data []byte) ([]byte, error) {
+
+	const location = "mediaproc.stripWebP"
+
+	result := make([]byte, 0, len(data))
+	result = append(result, data[0:12]...)
+	position := 12
+
+	for position < len(data) {
+
+		if position+8 > len(data) {
+			return nil, derp.NewBadRequestError(location, "Unexpected end of file")
+		}
+
+		chunkType := string(data[position : position+4])
+		length := int(binary.LittleEndian.Uint32(data[position+4 : position+8]))
+		end := position + 8 + length + (length % 2) // chunks are padded to an even length
+
+		if (length < 0) || (end > len(data)) {
+			return nil, derp.NewBadRequestError(location, "Chunk length exceeds file size", position)
+		}
+
+		switch chunkType {
+
+		case "EXIF", "XMP ":
+
+		case "VP8X":
+			chunk := bytes.Clone(data[position:end])
+			if length > 0 {
+				chunk[8] &^= 0x08 | 0x04 // clear the EXIF and XMP flags
+			}
+			result = append(result, chunk...)
+
+		default:
+			result = append(result, data[position:end]...)
+		}
+
+		position = end
+	}
+
+	// Update the RIFF size to match the new contents
+	binary.LittleEndian.PutUint32(result[4:8], uint32(len(result)-8))
+
+	return result, nil
+}
+
+// exifOrientation reads the orientation tag (0x0112) from a TIFF-formatted EXIF block.
+// It returns zero if the orientation cannot be found.
+func exifOrientation(tiff []byte) int {
+
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+
+	if offset+2 > len(tiff) {
+		return 0
+	}
+
+	count := int(order.Uint16(tiff[offset : offset+2]))
+
+	for index := 0; index < count; index++ {
+
+		entry := offset + 2 + index*12
+
+		if entry+12 > len(tiff) {
+			return 0
+		}
+
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8 : entry+10]))
+		}
+	}
+
+	return 0
+}
+
+// reorient rotates/flips an encoded image according to its EXIF orientation,
+// then re-encodes it in the original format.  Images that are already
+// upright are returned unchanged.
+func reorient(data []byte, orientation int, format string) ([]byte, error) {
+
+	const location = "mediaproc.reorient"
+
+	if (orientation < 2) || (orientation > 8) {
+		return data, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, derp.Wrap(err, location, "Error decoding image")
+	}
+
+	img = Orient(img, orientation)
+
+	var buffer bytes.Buffer
+
+	switch format {
+
+	case "png":
+		err = png.Encode(&buffer, img)
+
+	default:
+		err = jpeg.Encode(&buffer, img, &jpeg.Options{Quality: 92})
+	}
+
+	if err != nil {
+		return nil, derp.Wrap(err, location, "Error encoding image", format)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Orient returns a copy of the image that has been rotated/flipped
+// according to an EXIF orientation value (1-8).
+func Orient(img image.Image, orientation int) image.Image {
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// Orientations 5-8 swap the width and height
+	resultBounds := image.Rect(0, 0, width, height)
+
+	if orientation >= 5 {
+		resultBounds = image.Rect(0, 0, height, width)
+	}
+
+	result := image.NewRGBA(resultBounds)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+
+			var destX, destY int
+
+			switch orientation {
+			case 2: // flip horizontal
+				destX, destY = width-1-x, y
+			case 3: // rotate 180
+				destX, destY = width-1-x, height-1-y
+			case 4: // flip vertical
+				destX, destY = x, height-1-y
+			case 5: // transpose
+				destX, destY = y, x
+			case 6: // rotate 90 clockwise
+				destX, destY = height-1-y, x
+			case 7: // transverse
+				destX, destY = height-1-y, width-1-x
+			case 8: // rotate 90 counter-clockwise
+				destX, destY = y, width-1-x
+			default:
+				destX, destY = x, y
+			}
+
+			result.Set(destX, destY, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+
+	return result
+}
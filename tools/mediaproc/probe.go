@@ -0,0 +1,81 @@
+package mediaproc
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os/exec"
+	"strconv"
+
+	"github.com/benpate/derp"
+)
+
+// ProbeIsInstalled is TRUE if the ffprobe executable is available on this server
+var ProbeIsInstalled = isProbeInstalled()
+
+func isProbeInstalled() bool {
+	_, err := exec.LookPath("ffprobe")
+	return err == nil
+}
+
+// Probe uses ffprobe to read the dimensions and duration of an audio or video file.
+func Probe(filename string) (Info, error) {
+
+	const location = "mediaproc.Probe"
+
+	if !ProbeIsInstalled {
+		return Info{}, derp.NewInternalError(location, "FFprobe is not installed on this server")
+	}
+
+	var output bytes.Buffer
+	var errors bytes.Buffer
+
+	command := exec.Command("ffprobe", "-v", "error", "-print_format", "json", "-show_format", "-show_streams", filename)
+	command.Stdout = &output
+	command.Stderr = &errors
+
+	if err := command.Run(); err != nil {
+		return Info{}, derp.Wrap(err, location, "Error running FFprobe", errors.String())
+	}
+
+	return parseProbe(output.Bytes())
+}
+
+// parseProbe reads the JSON output from ffprobe
+func parseProbe(data []byte) (Info, error) {
+
+	probe := struct {
+		Streams []struct {
+			CodecType   string `json:"codec_type"`
+			Width       int    `json:"width"`
+			Height      int    `json:"height"`
+			Disposition struct {
+				AttachedPic int `json:"attached_pic"`
+			} `json:"disposition"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}{}
+
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return Info{}, derp.Wrap(err, "mediaproc.parseProbe", "Error parsing FFprobe output")
+	}
+
+	result := Info{}
+
+	// Use the dimensions of the first video stream (cover art in audio files is ignored)
+	for _, stream := range probe.Streams {
+		if (stream.CodecType == "video") && (stream.Width > 0) && (stream.Disposition.AttachedPic == 0) {
+			result.Width = stream.Width
+			result.Height = stream.Height
+			break
+		}
+	}
+
+	if duration, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		result.Duration = int(math.Round(duration))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,229 @@
+// Package micropub normalizes requests sent to a Micropub endpoint.
+// Micropub clients can send either form-encoded or JSON requests,
+// and this package converts both into a single Request structure.
+// https://www.w3.org/TR/micropub/
+package micropub
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/benpate/derp"
+)
+
+// ActionCreate creates a new post
+const ActionCreate = "create"
+
+// ActionUpdate updates the properties of an existing post
+const ActionUpdate = "update"
+
+// ActionDelete deletes an existing post
+const ActionDelete = "delete"
+
+// ActionUndelete restores a previously deleted post
+const ActionUndelete = "undelete"
+
+// maxMemory is the largest multipart form that will be held in memory
+const maxMemory = 32 << 20
+
+// Request is a normalized Micropub request
+type Request struct {
+	Action     string     // Action to perform (create, update, delete, undelete)
+	URL        string     // URL of the post to update/delete/undelete
+	Type       string     // Microformats type of the new post, without the "h-" prefix (e.g. "entry")
+	Properties Properties // Properties of the new post (create only)
+	Replace    Properties // Properties to replace (update only)
+	Add        Properties // Values to add to existing properties (update only)
+	Delete     Properties // Values to remove from existing properties (update only)
+	DeleteAll  []string   // Properties to remove entirely (update only)
+}
+
+// Parse reads a Micropub request from an HTTP request
+func Parse(request *http.Request) (Request, error) {
+
+	const location = "micropub.Parse"
+
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+
+	switch mediaType {
+
+	case "application/json":
+		var body map[string]any
+
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			return Request{}, derp.NewBadRequestError(location, "Invalid JSON body", err.Error())
+		}
+
+		return ParseJSON(body)
+
+	case "multipart/form-data":
+		if err := request.ParseMultipartForm(maxMemory); err != nil {
+			return Request{}, derp.NewBadRequestError(location, "Invalid multipart form", err.Error())
+		}
+
+		return ParseForm(request.MultipartForm.Value)
+
+	default:
+		if err := request.ParseForm(); err != nil {
+			return Request{}, derp.NewBadRequestError(location, "Invalid form", err.Error())
+		}
+
+		return ParseForm(request.PostForm)
+	}
+}
+
+// ParseForm reads a form-encoded Micropub request.  Form requests can only create
+// new posts, or delete/undelete existing posts.
+func ParseForm(values url.Values) (Request, error) {
+
+	const location = "micropub.ParseForm"
+
+	result := Request{
+		Action:     strings.ToLower(values.Get("action")),
+		URL:        values.Get("url"),
+		Type:       values.Get("h"),
+		Properties: Properties{},
+	}
+
+	if result.Action == "" {
+		result.Action = ActionCreate
+	}
+
+	// Forms can only delete/undelete existing posts
+	if result.Action != ActionCreate {
+
+		if (result.Action != ActionDelete) && (result.Action != ActionUndelete) {
+			return Request{}, derp.NewBadRequestError(location, "Unsupported action for form requests", result.Action)
+		}
+
+		if result.URL == "" {
+			return Request{}, derp.NewBadRequestError(location, "URL is required", result.Action)
+		}
+
+		return result, nil
+	}
+
+	if result.Type == "" {
+		return Request{}, derp.NewBadRequestError(location, "Type (h) is required")
+	}
+
+	// Form fields are always strings, so convert them into Properties
+	for key, value := range values {
+
+		// Remove array suffixes (e.g. "category[]")
+		key = strings.TrimSuffix(key, "[]")
+
+		switch key {
+		case "h", "action", "url", "access_token":
+			continue
+		}
+
+		for _, item := range value {
+			result.Properties[key] = append(result.Properties[key], item)
+		}
+	}
+
+	return result, nil
+}
+
+// ParseJSON reads a JSON-encoded Micropub request
+func ParseJSON(body map[string]any) (Request, error) {
+
+	const location = "micropub.ParseJSON"
+
+	action, _ := body["action"].(string)
+	postURL, _ := body["url"].(string)
+
+	result := Request{
+		Action:     strings.ToLower(action),
+		URL:        postURL,
+		Properties: Properties{},
+	}
+
+	// Create requests use a microformats2 JSON object
+	if result.Action == "" {
+		result.Action = ActionCreate
+
+		if types, ok := body["type"].([]any); ok && len(types) > 0 {
+			result.Type, _ = types[0].(string)
+		}
+
+		if result.Type == "" {
+			return Request{}, derp.NewBadRequestError(location, "Type is required")
+		}
+
+		result.Type = strings.TrimPrefix(result.Type, "h-")
+		result.Properties = parseProperties(body["properties"])
+		return result, nil
+	}
+
+	if err := validateAction(result.Action); err != nil {
+		return Request{}, err
+	}
+
+	if result.URL == "" {
+		return Request{}, derp.NewBadRequestError(location, "URL is required", result.Action)
+	}
+
+	if result.Action != ActionUpdate {
+		return result, nil
+	}
+
+	// Update requests include replace/add/delete instructions
+	result.Replace = parseProperties(body["replace"])
+	result.Add = parseProperties(body["add"])
+
+	switch deleteValue := body["delete"].(type) {
+
+	// Remove properties entirely
+	case []any:
+		for _, name := range deleteValue {
+			if name, ok := name.(string); ok {
+				result.DeleteAll = append(result.DeleteAll, name)
+			}
+		}
+
+	// Remove individual values from properties
+	case map[string]any:
+		result.Delete = parseProperties(deleteValue)
+	}
+
+	return result, nil
+}
+
+// parseProperties converts a JSON properties object into a Properties map.
+// Single values are converted into single-item slices.
+func parseProperties(value any) Properties {
+
+	result := Properties{}
+
+	properties, ok := value.(map[string]any)
+
+	if !ok {
+		return result
+	}
+
+	for key, value := range properties {
+		if values, ok := value.([]any); ok {
+			result[key] = values
+		} else {
+			result[key] = []any{value}
+		}
+	}
+
+	return result
+}
+
+// validateAction returns an error if the action is not supported
+func validateAction(action string) error {
+
+	switch action {
+	case ActionCreate, ActionUpdate, ActionDelete, ActionUndelete:
+		return nil
+	}
+
+	return derp.NewBadRequestError("micropub.validateAction", "Unsupported action", action)
+}
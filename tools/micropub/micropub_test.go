@@ -0,0 +1,163 @@
+package micropub
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseForm_Create(t *testing.T) {
+
+	values := url.Values{
+		"h":                  {"entry"},
+		"content":            {"Hello <world>"},
+		"category[]":         {"foo", "bar"},
+		"mp-syndicate-to[]":  {"twitter"},
+		"access_token":       {"secret"},
+		"in-reply-to":        {"https://example.com/post"},
+		"photo":              {"https://example.com/photo.jpg"},
+		"extra-empty-values": {},
+	}
+
+	result, err := ParseForm(values)
+	require.Nil(t, err)
+	require.Equal(t, ActionCreate, result.Action)
+	require.Equal(t, "entry", result.Type)
+	require.Equal(t, []string{"foo", "bar"}, result.Properties.Strings("category"))
+	require.Equal(t, "twitter", result.Properties.First("mp-syndicate-to"))
+	require.Equal(t, "https://example.com/post", result.Properties.First("in-reply-to"))
+	require.Equal(t, "Hello &lt;world&gt;", result.Properties.HTML("content"))
+	require.False(t, result.Properties.Has("access_token"))
+	require.False(t, result.Properties.Has("extra-empty-values"))
+}
+
+func TestParseForm_Delete(t *testing.T) {
+
+	result, err := ParseForm(url.Values{"action": {"delete"}, "url": {"https://example.com/123"}})
+	require.Nil(t, err)
+	require.Equal(t, ActionDelete, result.Action)
+	require.Equal(t, "https://example.com/123", result.URL)
+
+	_, err = ParseForm(url.Values{"action": {"delete"}})
+	require.NotNil(t, err)
+
+	_, err = ParseForm(url.Values{"action": {"update"}, "url": {"https://example.com/123"}})
+	require.NotNil(t, err)
+
+	_, err = ParseForm(url.Values{"content": {"missing type"}})
+	require.NotNil(t, err)
+}
+
+func TestParse_JSONCreate(t *testing.T) {
+
+	body := `{
+		"type": ["h-entry"],
+		"properties": {
+			"name": ["My Article"],
+			"content": [{"html": "<p>Hello <b>World</b></p>"}],
+			"photo": [{"value": "https://example.com/photo.jpg", "alt": "A photo"}],
+			"published": "2024-01-01T00:00:00Z"
+		}
+	}`
+
+	request, _ := http.NewRequest(http.MethodPost, "/.micropub", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	result, err := Parse(request)
+	require.Nil(t, err)
+	require.Equal(t, ActionCreate, result.Action)
+	require.Equal(t, "entry", result.Type)
+	require.Equal(t, "My Article", result.Properties.First("name"))
+	require.Equal(t, "<p>Hello <b>World</b></p>", result.Properties.HTML("content"))
+	require.Equal(t, "https://example.com/photo.jpg", result.Properties.First("photo"))
+	require.Equal(t, "2024-01-01T00:00:00Z", result.Properties.First("published"))
+}
+
+func TestParse_FormCreate(t *testing.T) {
+
+	request, _ := http.NewRequest(http.MethodPost, "/.micropub", strings.NewReader("h=entry&content=Hello"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	result, err := Parse(request)
+	require.Nil(t, err)
+	require.Equal(t, "entry", result.Type)
+	require.Equal(t, "Hello", result.Properties.First("content"))
+}
+
+func TestParseJSON_Update(t *testing.T) {
+
+	result, err := ParseJSON(map[string]any{
+		"action":  "update",
+		"url":     "https://example.com/123",
+		"replace": map[string]any{"content": []any{"New content"}},
+		"add":     map[string]any{"category": []any{"new"}},
+		"delete":  map[string]any{"category": []any{"old"}},
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, ActionUpdate, result.Action)
+
+	properties := Properties{
+		"content":  {"Old content"},
+		"category": {"old", "keep"},
+	}
+
+	properties.Apply(result)
+	require.Equal(t, "New content", properties.First("content"))
+	require.Equal(t, []string{"keep", "new"}, properties.Strings("category"))
+
+	// Deleting entire properties
+	result, err = ParseJSON(map[string]any{
+		"action": "update",
+		"url":    "https://example.com/123",
+		"delete": []any{"category"},
+	})
+
+	require.Nil(t, err)
+	properties.Apply(result)
+	require.False(t, properties.Has("category"))
+	require.True(t, properties.Has("content"))
+}
+
+func TestParseJSON_Errors(t *testing.T) {
+
+	_, err := ParseJSON(map[string]any{"properties": map[string]any{}})
+	require.NotNil(t, err)
+
+	_, err = ParseJSON(map[string]any{"action": "update"})
+	require.NotNil(t, err)
+
+	_, err = ParseJSON(map[string]any{"action": "explode", "url": "https://example.com/123"})
+	require.NotNil(t, err)
+}
+
+func TestProperties_Filter(t *testing.T) {
+
+	properties := Properties{
+		"name":    {"Title"},
+		"content": {"Body"},
+	}
+
+	require.Equal(t, properties, properties.Filter())
+	require.Equal(t, Properties{"name": {"Title"}}, properties.Filter("name", "missing"))
+}
+
+func TestProperties_Setters(t *testing.T) {
+
+	properties := Properties{}
+	properties.SetString("name", "Title")
+	properties.SetStrings("category", []string{"a", "b"})
+	properties.SetHTML("content", "<p>Hi</p>")
+
+	require.Equal(t, "Title", properties.First("name"))
+	require.Equal(t, []string{"a", "b"}, properties.Strings("category"))
+	require.Equal(t, "<p>Hi</p>", properties.HTML("content"))
+
+	properties.SetString("name", "")
+	properties.SetStrings("category", nil)
+	properties.SetHTML("content", "")
+	require.Empty(t, properties)
+}
@@ -0,0 +1,171 @@
+package micropub
+
+import (
+	"html"
+	"strings"
+
+	"github.com/benpate/rosetta/convert"
+	"github.com/benpate/rosetta/slice"
+)
+
+// Properties is a set of microformats2 properties.  Every property is a list of values,
+// where each value is either a string or a nested object (like {"html": "..."}).
+type Properties map[string][]any
+
+// Has returns TRUE if the property exists and has at least one value
+func (properties Properties) Has(name string) bool {
+	return len(properties[name]) > 0
+}
+
+// First returns the first value of a property as a string.  Objects with a "value"
+// or "url" key (like photos with alt text) return that key instead.
+func (properties Properties) First(name string) string {
+
+	values := properties[name]
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	return stringValue(values[0])
+}
+
+// Strings returns all values of a property as strings
+func (properties Properties) Strings(name string) []string {
+
+	values := properties[name]
+	result := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if value := stringValue(value); value != "" {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+// SetString sets a property to a single string value.  Empty values remove the property.
+func (properties Properties) SetString(name string, value string) {
+
+	if value == "" {
+		delete(properties, name)
+		return
+	}
+
+	properties[name] = []any{value}
+}
+
+// SetStrings sets a property to a list of string values.  Empty lists remove the property.
+func (properties Properties) SetStrings(name string, values []string) {
+
+	if len(values) == 0 {
+		delete(properties, name)
+		return
+	}
+
+	result := make([]any, len(values))
+	for index, value := range values {
+		result[index] = value
+	}
+
+	properties[name] = result
+}
+
+// HTML returns the first value of a property as HTML.  Plain text values
+// are escaped, and HTML values (like {"html": "..."}) are returned as-is.
+func (properties Properties) HTML(name string) string {
+
+	values := properties[name]
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	if object, ok := values[0].(map[string]any); ok {
+		if value, ok := object["html"]; ok {
+			return convert.String(value)
+		}
+	}
+
+	text := html.EscapeString(stringValue(values[0]))
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+// SetHTML sets a property to an HTML value.  Empty values remove the property.
+func (properties Properties) SetHTML(name string, value string) {
+
+	if value == "" {
+		delete(properties, name)
+		return
+	}
+
+	properties[name] = []any{map[string]any{"html": value}}
+}
+
+// Apply updates these properties using the replace/add/delete
+// instructions in a Micropub update request.
+func (properties Properties) Apply(request Request) {
+
+	for name, values := range request.Replace {
+		properties[name] = values
+	}
+
+	for name, values := range request.Add {
+		properties[name] = append(properties[name], values...)
+	}
+
+	for name, values := range request.Delete {
+
+		remove := Properties{name: values}.Strings(name)
+		remaining := make([]any, 0, len(properties[name]))
+
+		for _, value := range properties[name] {
+			if !slice.Contains(remove, stringValue(value)) {
+				remaining = append(remaining, value)
+			}
+		}
+
+		properties[name] = remaining
+	}
+
+	for _, name := range request.DeleteAll {
+		delete(properties, name)
+	}
+}
+
+// Filter returns only the named properties.  If no names are provided,
+// then all properties are returned.
+func (properties Properties) Filter(names ...string) Properties {
+
+	if len(names) == 0 {
+		return properties
+	}
+
+	result := Properties{}
+
+	for _, name := range names {
+		if values, ok := properties[name]; ok {
+			result[name] = values
+		}
+	}
+
+	return result
+}
+
+// stringValue converts a single property value into a string
+func stringValue(value any) string {
+
+	if object, ok := value.(map[string]any); ok {
+
+		for _, key := range []string{"value", "url", "html"} {
+			if result, ok := object[key]; ok {
+				return convert.String(result)
+			}
+		}
+
+		return ""
+	}
+
+	return convert.String(value)
+}
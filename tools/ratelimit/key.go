@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// KeyFunc identifies the bucket that a request is counted against.  It returns the
+// type of key (like "ip") and its value.  Empty values are not counted.
+type KeyFunc func(request *http.Request) (keyType string, value string)
+
+// Key types that are recorded with each throttling event
+const (
+	KeyTypeIP      = "ip"
+	KeyTypeAccount = "account"
+	KeyTypeActor   = "actor"
+)
+
+// realIP trusts X-Forwarded-For headers only when they are added by proxies on
+// private networks, so that clients cannot choose their own IP address.
+var realIP = echo.ExtractIPFromXFFHeader()
+
+// ByIP counts requests from each client IP address
+func ByIP() KeyFunc {
+	return func(request *http.Request) (string, string) {
+		return KeyTypeIP, realIP(request)
+	}
+}
+
+// ByFormValue counts requests for each account named in a form field (like "username")
+func ByFormValue(name string) KeyFunc {
+	return func(request *http.Request) (string, string) {
+		return KeyTypeAccount, strings.ToLower(strings.TrimSpace(request.FormValue(name)))
+	}
+}
+
+// ActorFunc returns the ID of the Actor who signed a request, once the request's HTTP
+// signature has been verified.  It returns an empty string for all other requests.
+type ActorFunc func(request *http.Request) (actorID string)
+
+// ByActorDomain counts ActivityPub requests for each remote domain.  The domain is only
+// trusted after the request's HTTP signature has been verified, so that clients cannot
+// spend another server's limit by naming it in a forged keyId.  Unsigned requests (and
+// requests whose signatures cannot be verified) are counted by their IP address instead.
+// Verifying signatures is expensive, so routes should also be limited with ByIP before
+// signatures are verified.
+func ByActorDomain(verifiedActor ActorFunc) KeyFunc {
+	return func(request *http.Request) (string, string) {
+
+		if actorURL, err := url.Parse(verifiedActor(request)); err == nil && actorURL.Hostname() != "" {
+			return KeyTypeActor, strings.ToLower(actorURL.Hostname())
+		}
+
+		return KeyTypeIP, realIP(request)
+	}
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/benpate/derp"
+)
+
+// Limiter applies the configured Rule for each class of routes
+type Limiter struct {
+	store Store
+	rules map[string]Rule
+	now   func() time.Time
+}
+
+// Event describes a request that was counted against a single bucket
+type Event struct {
+	Class   string // Class of routes (like "signin")
+	KeyType string // Type of key (like "ip")
+	Key     string // Value of the key (like "192.0.2.1")
+	Result
+}
+
+// NewLimiter returns a Limiter that stores buckets in the provided Store.
+// Classes that are missing from `rules` are not limited.
+func NewLimiter(store Store, rules map[string]Rule) *Limiter {
+	return &Limiter{
+		store: store,
+		rules: rules,
+		now:   time.Now,
+	}
+}
+
+// Rule returns the Rule for a class of routes
+func (limiter *Limiter) Rule(class string) Rule {
+	return limiter.rules[class]
+}
+
+// Allow counts a request against every bucket that it belongs to.  Buckets are separated
+// by `scope` (like the hostname) so that servers with several domains limit each one
+// separately.  It returns the most restrictive Event, and whether or not any limit was found.
+func (limiter *Limiter) Allow(class string, scope string, request *http.Request, keys ...KeyFunc) (Event, bool, error) {
+
+	rule := limiter.Rule(class)
+
+	if rule.IsZero() {
+		return Event{}, false, nil
+	}
+
+	now := limiter.now()
+	var result Event
+	var found bool
+
+	for _, keyFunc := range keys {
+
+		keyType, key := keyFunc(request)
+
+		if key == "" {
+			continue
+		}
+
+		current, err := limiter.store.Take(class+":"+scope+":"+keyType+":"+key, rule, now)
+
+		if err != nil {
+			return Event{}, false, derp.Wrap(err, "ratelimit.Limiter.Allow", "Error checking rate limit", class, keyType)
+		}
+
+		event := Event{Class: class, KeyType: keyType, Key: key, Result: current}
+
+		if !found || current.isMoreRestrictive(result.Result) {
+			result, found = event, true
+		}
+	}
+
+	return result, found, nil
+}
+
+// Close releases the resources held by this Limiter's Store
+func (limiter *Limiter) Close() {
+	limiter.store.Close()
+}
@@ -0,0 +1,85 @@
+/*
+Package ratelimit implements token-bucket rate limits for classes of HTTP routes
+(like sign-in forms or ActivityPub inboxes).  Each bucket is identified by a key,
+such as the client's IP address, the account being signed into, or the domain of
+a remote ActivityPub actor.  Buckets can be stored in memory (for a single server)
+or in Redis (to share limits between several servers).
+*/
+package ratelimit
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benpate/derp"
+)
+
+// Rule defines the size of a token bucket and how quickly it refills.
+// A Rule of "10/5m" allows bursts of 10 requests, and refills completely in 5 minutes.
+type Rule struct {
+	Limit  int           // Maximum number of requests in each Period (also the largest burst)
+	Period time.Duration // Time it takes for an empty bucket to refill completely
+}
+
+// ParseRule reads a Rule from a string like "10/5m".  Empty strings, "0", and "off"
+// return a zero Rule, which disables rate limiting.
+func ParseRule(value string) (Rule, error) {
+
+	const location = "ratelimit.ParseRule"
+
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if value == "" || value == "0" || value == "off" {
+		return Rule{}, nil
+	}
+
+	limitString, periodString, found := strings.Cut(value, "/")
+
+	if !found {
+		return Rule{}, derp.NewBadRequestError(location, "Rate limits must look like 10/5m", value)
+	}
+
+	limit, err := strconv.Atoi(strings.TrimSpace(limitString))
+
+	if err != nil || limit < 0 {
+		return Rule{}, derp.NewBadRequestError(location, "Invalid number of requests", value)
+	}
+
+	period, err := time.ParseDuration(strings.TrimSpace(periodString))
+
+	if err != nil || period <= 0 {
+		return Rule{}, derp.NewBadRequestError(location, "Invalid time period", value)
+	}
+
+	// RULE: Buckets refill in whole milliseconds, so very short periods cannot be counted
+	if period < time.Second {
+		return Rule{}, derp.NewBadRequestError(location, "Time period must be at least one second", value)
+	}
+
+	if limit == 0 {
+		return Rule{}, nil
+	}
+
+	return Rule{Limit: limit, Period: period}, nil
+}
+
+// IsZero returns TRUE if this Rule does not limit anything
+func (rule Rule) IsZero() bool {
+	return rule.Limit <= 0 || rule.Period <= 0
+}
+
+// String returns the Rule in the same format that is read by ParseRule
+func (rule Rule) String() string {
+
+	if rule.IsZero() {
+		return "off"
+	}
+
+	return strconv.Itoa(rule.Limit) + "/" + rule.Period.String()
+}
+
+// refillRate returns the number of tokens added to the bucket each millisecond
+func (rule Rule) refillRate() float64 {
+	return float64(rule.Limit) / float64(rule.Period.Milliseconds())
+}
@@ -0,0 +1,251 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule(t *testing.T) {
+
+	rule, err := ParseRule("10/5m")
+	require.Nil(t, err)
+	require.Equal(t, Rule{Limit: 10, Period: 5 * time.Minute}, rule)
+	require.Equal(t, "10/5m0s", rule.String())
+
+	for _, value := range []string{"", "0", "off"} {
+		rule, err := ParseRule(value)
+		require.Nil(t, err)
+		require.True(t, rule.IsZero())
+	}
+
+	for _, value := range []string{"ten/5m", "10/forever", "10", "-1/1m", "10/0s", "10/500us", "10/999ms"} {
+		_, err := ParseRule(value)
+		require.NotNil(t, err, value)
+	}
+}
+
+func TestBucket(t *testing.T) {
+
+	rule := Rule{Limit: 2, Period: 10 * time.Second}
+	now := time.Unix(1_700_000_000, 0)
+	b := bucket{}
+
+	// New buckets start full
+	b, result := b.take(rule, now)
+	require.True(t, result.Allowed)
+	require.Equal(t, 1, result.Remaining)
+
+	b, result = b.take(rule, now)
+	require.True(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+
+	// Only the first denied request is marked as throttled
+	b, result = b.take(rule, now)
+	require.False(t, result.Allowed)
+	require.True(t, result.Throttled)
+	require.Equal(t, 5*time.Second, result.RetryAfter)
+
+	b, result = b.take(rule, now.Add(time.Second))
+	require.False(t, result.Allowed)
+	require.False(t, result.Throttled)
+	require.Equal(t, 4*time.Second, result.RetryAfter)
+
+	// One token is refilled every five seconds
+	b, result = b.take(rule, now.Add(5*time.Second))
+	require.True(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+
+	// Buckets never hold more than the limit
+	_, result = b.take(rule, now.Add(time.Hour))
+	require.True(t, result.Allowed)
+	require.Equal(t, 1, result.Remaining)
+}
+
+func TestSetHeaders(t *testing.T) {
+
+	rule := Rule{Limit: 5, Period: time.Minute}
+
+	header := http.Header{}
+	newResult(rule, 3.5, true, false).SetHeaders(header)
+	require.Equal(t, "5", header.Get("RateLimit-Limit"))
+	require.Equal(t, "3", header.Get("RateLimit-Remaining"))
+	require.Equal(t, "18", header.Get("RateLimit-Reset"))
+	require.Equal(t, "5;w=60", header.Get("RateLimit-Policy"))
+	require.Equal(t, "", header.Get("Retry-After"))
+
+	header = http.Header{}
+	newResult(rule, 0.5, false, true).SetHeaders(header)
+	require.Equal(t, "0", header.Get("RateLimit-Remaining"))
+	require.Equal(t, "6", header.Get("Retry-After"))
+}
+
+// testVerifier returns the Actor who owns each signing key, but only for the provided keyIds.
+// Signatures from any other key are treated as invalid.
+func testVerifier(actors map[string]string) ActorFunc {
+	return func(request *http.Request) string {
+		for keyID, actorID := range actors {
+			if strings.Contains(request.Header.Get("Signature")+request.Header.Get("Authorization"), `keyId="`+keyID+`"`) {
+				return actorID
+			}
+		}
+		return ""
+	}
+}
+
+func TestByActorDomain(t *testing.T) {
+
+	verifiedActor := testVerifier(map[string]string{
+		"https://Mastodon.Example/users/alice#main-key": "https://Mastodon.Example/users/alice",
+		"https://other.example/actor#key":               "https://other.example/actor",
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/.inbox", nil)
+	request.RemoteAddr = "192.0.2.1:1234"
+
+	// Unsigned requests are counted by IP address
+	keyType, key := ByActorDomain(verifiedActor)(request)
+	require.Equal(t, KeyTypeIP, keyType)
+	require.Equal(t, "192.0.2.1", key)
+
+	// Verified requests are counted by the domain of the Actor who signed them
+	request.Header.Set("Signature", `keyId="https://Mastodon.Example/users/alice#main-key",algorithm="rsa-sha256",headers="(request-target) host date",signature="abc=="`)
+	keyType, key = ByActorDomain(verifiedActor)(request)
+	require.Equal(t, KeyTypeActor, keyType)
+	require.Equal(t, "mastodon.example", key)
+
+	request.Header.Del("Signature")
+	request.Header.Set("Authorization", `Signature keyId="https://other.example/actor#key",signature="abc=="`)
+	keyType, key = ByActorDomain(verifiedActor)(request)
+	require.Equal(t, KeyTypeActor, keyType)
+	require.Equal(t, "other.example", key)
+
+	// Signatures that cannot be verified are counted by IP address
+	request.Header.Set("Authorization", `Signature keyId="https://forged.example/actor#key",signature="abc=="`)
+	keyType, key = ByActorDomain(verifiedActor)(request)
+	require.Equal(t, KeyTypeIP, keyType)
+	require.Equal(t, "192.0.2.1", key)
+}
+
+func TestByActorDomain_ForgedKeyID(t *testing.T) {
+
+	store, err := NewMemoryStore(100)
+	require.Nil(t, err)
+	defer store.Close()
+
+	limiter := NewLimiter(store, map[string]Rule{
+		"activitypubIP": {Limit: 4, Period: time.Minute},
+		"activitypub":   {Limit: 2, Period: time.Minute},
+	})
+
+	verifiedActor := testVerifier(map[string]string{
+		"https://mastodon.example/users/alice#main-key": "https://mastodon.example/users/alice",
+	})
+
+	// deliver counts the request by IP (before verifying its signature) and then by Actor domain
+	deliver := func(ip string, keyID string) (Event, bool) {
+		request := httptest.NewRequest(http.MethodPost, "/.inbox", nil)
+		request.RemoteAddr = ip + ":1234"
+		request.Header.Set("Signature", `keyId="`+keyID+`",signature="abc=="`)
+
+		event, found, err := limiter.Allow("activitypubIP", "example.com", request, ByIP())
+		require.Nil(t, err)
+		require.True(t, found)
+
+		if !event.Allowed {
+			return event, false
+		}
+
+		event, found, err = limiter.Allow("activitypub", "example.com", request, ByActorDomain(verifiedActor))
+		require.Nil(t, err)
+		require.True(t, found)
+		return event, true
+	}
+
+	// An attacker names a large server in a forged keyId...
+	for range 2 {
+		event, verified := deliver("203.0.113.66", "https://mastodon.example/users/bob#main-key")
+		require.True(t, verified)
+		require.True(t, event.Allowed)
+	}
+
+	// ...but is only counted (and throttled) by their own IP address
+	event, _ := deliver("203.0.113.66", "https://mastodon.example/users/bob#main-key")
+	require.False(t, event.Allowed)
+	require.Equal(t, KeyTypeIP, event.KeyType)
+	require.Equal(t, "203.0.113.66", event.Key)
+
+	// Changing the forged keyId does not get around the IP limit
+	event, _ = deliver("203.0.113.66", "https://random-1234.example/actor#key")
+	require.False(t, event.Allowed)
+	require.Equal(t, KeyTypeIP, event.KeyType)
+
+	// Floods are stopped by the IP limit before their signatures are verified
+	event, verified := deliver("203.0.113.66", "https://random-5678.example/actor#key")
+	require.False(t, verified)
+	require.False(t, event.Allowed)
+	require.Equal(t, "activitypubIP", event.Class)
+
+	// Real deliveries from the large server are not affected
+	event, verified = deliver("198.51.100.10", "https://mastodon.example/users/alice#main-key")
+	require.True(t, verified)
+	require.True(t, event.Allowed)
+	require.Equal(t, KeyTypeActor, event.KeyType)
+	require.Equal(t, "mastodon.example", event.Key)
+}
+
+func TestByIP_IgnoresSpoofedHeaders(t *testing.T) {
+
+	request := httptest.NewRequest(http.MethodPost, "/signin", nil)
+	request.RemoteAddr = "203.0.113.7:1234"
+	request.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	_, key := ByIP()(request)
+	require.Equal(t, "203.0.113.7", key)
+}
+
+func TestLimiter(t *testing.T) {
+
+	store, err := NewMemoryStore(100)
+	require.Nil(t, err)
+	defer store.Close()
+
+	now := time.Unix(1_700_000_000, 0)
+	limiter := NewLimiter(store, map[string]Rule{"signin": {Limit: 2, Period: time.Minute}})
+	limiter.now = func() time.Time { return now }
+
+	signIn := func(ip string, username string) Event {
+		form := url.Values{"username": {username}}
+		request := httptest.NewRequest(http.MethodPost, "/signin", strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.RemoteAddr = ip + ":1234"
+
+		event, found, err := limiter.Allow("signin", "example.com", request, ByIP(), ByFormValue("username"))
+		require.Nil(t, err)
+		require.True(t, found)
+		return event
+	}
+
+	require.True(t, signIn("192.0.2.1", "alice").Allowed)
+	require.True(t, signIn("192.0.2.2", "Alice").Allowed)
+
+	// The account is throttled, even though each IP address is still allowed
+	event := signIn("192.0.2.3", "alice")
+	require.False(t, event.Allowed)
+	require.True(t, event.Throttled)
+	require.Equal(t, KeyTypeAccount, event.KeyType)
+	require.Equal(t, "alice", event.Key)
+
+	// Other accounts are not affected
+	require.True(t, signIn("192.0.2.4", "bob").Allowed)
+
+	// Unknown classes are not limited
+	_, found, err := limiter.Allow("unknown", "example.com", httptest.NewRequest(http.MethodGet, "/", nil), ByIP())
+	require.Nil(t, err)
+	require.False(t, found)
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Result reports the state of a token bucket after a request was counted against it
+type Result struct {
+	Rule       Rule          // Rule that was applied
+	Allowed    bool          // TRUE if the request may continue
+	Remaining  int           // Number of requests that can still be made right now
+	Reset      time.Duration // Time until the bucket is completely refilled
+	RetryAfter time.Duration // Time until the next request will be allowed (zero if allowed)
+	Throttled  bool          // TRUE if this is the first request to be denied since the bucket ran out
+}
+
+// newResult calculates a Result from the number of tokens left in a bucket
+func newResult(rule Rule, tokens float64, allowed bool, throttled bool) Result {
+
+	rate := rule.refillRate()
+
+	result := Result{
+		Rule:      rule,
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(rule.Limit)-tokens)/rate) * time.Millisecond,
+		Throttled: throttled,
+	}
+
+	if !allowed {
+		result.RetryAfter = time.Duration(math.Ceil((1-tokens)/rate)) * time.Millisecond
+	}
+
+	return result
+}
+
+// SetHeaders writes the standard rate limit headers into an HTTP response.
+// https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/
+func (result Result) SetHeaders(header http.Header) {
+
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Rule.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.FormatInt(seconds(result.Reset), 10))
+	header.Set("RateLimit-Policy", strconv.Itoa(result.Rule.Limit)+";w="+strconv.FormatInt(seconds(result.Rule.Period), 10))
+
+	if !result.Allowed {
+		header.Set("Retry-After", strconv.FormatInt(max(seconds(result.RetryAfter), 1), 10))
+	}
+}
+
+// isMoreRestrictive returns TRUE if this Result leaves fewer requests available than another
+func (result Result) isMoreRestrictive(other Result) bool {
+
+	if result.Allowed != other.Allowed {
+		return !result.Allowed
+	}
+
+	if !result.Allowed {
+		return result.RetryAfter > other.RetryAfter
+	}
+
+	return result.Remaining < other.Remaining
+}
+
+// seconds rounds a duration up to the next whole second
+func seconds(duration time.Duration) int64 {
+	return int64(math.Ceil(duration.Seconds()))
+}
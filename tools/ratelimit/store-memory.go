@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/maypok86/otter"
+)
+
+// MemoryStore keeps token buckets in memory.  Limits are not shared
+// between servers, and are reset whenever the server restarts.
+type MemoryStore struct {
+	cache otter.CacheWithVariableTTL[string, bucket]
+	mutex *sync.Mutex
+}
+
+// NewMemoryStore returns a MemoryStore that holds up to `capacity` buckets.
+// When full, the least useful buckets are removed first.
+func NewMemoryStore(capacity int) (MemoryStore, error) {
+
+	cache, err := otter.MustBuilder[string, bucket](capacity).
+		WithVariableTTL().
+		Build()
+
+	if err != nil {
+		return MemoryStore{}, err
+	}
+
+	return MemoryStore{
+		cache: cache,
+		mutex: &sync.Mutex{},
+	}, nil
+}
+
+// Take removes one token from the bucket identified by key (if one is available)
+func (store MemoryStore) Take(key string, rule Rule, now time.Time) (Result, error) {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	current, _ := store.cache.Get(key)
+	next, result := current.take(rule, now)
+
+	// Buckets are completely refilled after one period, so they can be forgotten
+	store.cache.Set(key, next, rule.Period)
+
+	return result, nil
+}
+
+// Close releases the memory used by this Store
+func (store MemoryStore) Close() {
+	store.cache.Close()
+}
@@ -0,0 +1,49 @@
+package ratelimit
+
+import "time"
+
+// Store saves the state of every token bucket.  Implementations must count
+// each request atomically, so that concurrent requests cannot exceed the limit.
+type Store interface {
+
+	// Take removes one token from the bucket identified by key (if one is available)
+	Take(key string, rule Rule, now time.Time) (Result, error)
+
+	// Close releases any resources held by the Store
+	Close()
+}
+
+// bucket is the state of a single token bucket
+type bucket struct {
+	Tokens    float64 // Number of tokens remaining in the bucket
+	Updated   int64   // Time (in Unix milliseconds) that Tokens was last calculated
+	Throttled bool    // TRUE if the most recent request was denied
+}
+
+// take refills the bucket for the time that has passed since it was last
+// updated, then removes one token from it (if one is available).
+func (b bucket) take(rule Rule, now time.Time) (bucket, Result) {
+
+	nowMillis := now.UnixMilli()
+
+	// New buckets start full
+	if b.Updated == 0 {
+		b.Tokens = float64(rule.Limit)
+		b.Updated = nowMillis
+	}
+
+	if elapsed := nowMillis - b.Updated; elapsed > 0 {
+		b.Tokens = min(float64(rule.Limit), b.Tokens+float64(elapsed)*rule.refillRate())
+		b.Updated = nowMillis
+	}
+
+	if b.Tokens >= 1 {
+		b.Tokens = b.Tokens - 1
+		b.Throttled = false
+		return b, newResult(rule, b.Tokens, true, false)
+	}
+
+	throttled := !b.Throttled
+	b.Throttled = true
+	return b, newResult(rule, b.Tokens, false, throttled)
+}
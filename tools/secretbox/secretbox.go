@@ -0,0 +1,87 @@
+// Package secretbox encrypts small values (like TOTP secrets) before they are
+// stored in the database.  Values are sealed with AES-256-GCM using a key that
+// is derived from the domain's key-encrypting key, so a copy of the database
+// alone is not enough to read them.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/EmissarySocial/emissary/tools/random"
+	"github.com/benpate/derp"
+)
+
+// Seal encrypts the plaintext with the provided key, and returns a base64 encoded value
+// that includes a random nonce.  Sealing the same value twice returns different results.
+func Seal(key string, plaintext string) (string, error) {
+
+	const location = "secretbox.Seal"
+
+	aead, err := newAEAD(key)
+
+	if err != nil {
+		return "", derp.Wrap(err, location, "Error creating cipher")
+	}
+
+	nonce, err := random.GenerateBytes(aead.NonceSize())
+
+	if err != nil {
+		return "", derp.Wrap(err, location, "Error generating nonce")
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value that was created by Seal.  It returns an error if the
+// value was sealed with a different key, or has been modified.
+func Open(key string, sealed string) (string, error) {
+
+	const location = "secretbox.Open"
+
+	aead, err := newAEAD(key)
+
+	if err != nil {
+		return "", derp.Wrap(err, location, "Error creating cipher")
+	}
+
+	value, err := base64.StdEncoding.DecodeString(sealed)
+
+	if err != nil {
+		return "", derp.Wrap(err, location, "Sealed value is not valid base64")
+	}
+
+	if len(value) < aead.NonceSize() {
+		return "", derp.NewInternalError(location, "Sealed value is too short")
+	}
+
+	nonce, ciphertext := value[:aead.NonceSize()], value[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+
+	if err != nil {
+		return "", derp.Wrap(err, location, "Error decrypting value")
+	}
+
+	return string(plaintext), nil
+}
+
+// newAEAD returns an AES-256-GCM cipher.  The key is hashed so that keys of
+// any length can be used.
+func newAEAD(key string) (cipher.AEAD, error) {
+
+	if key == "" {
+		return nil, derp.NewInternalError("secretbox.newAEAD", "Encryption key is required")
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(hash[:])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,44 @@
+package secretbox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealOpen(t *testing.T) {
+
+	sealed, err := Seal("KEY", "JBSWY3DPEHPK3PXP")
+	require.Nil(t, err)
+	require.NotContains(t, sealed, "JBSWY3DPEHPK3PXP")
+
+	// Sealing the same value twice uses a different nonce
+	again, err := Seal("KEY", "JBSWY3DPEHPK3PXP")
+	require.Nil(t, err)
+	require.NotEqual(t, sealed, again)
+
+	plaintext, err := Open("KEY", sealed)
+	require.Nil(t, err)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", plaintext)
+}
+
+func TestOpen_Invalid(t *testing.T) {
+
+	sealed, err := Seal("KEY", "SECRET")
+	require.Nil(t, err)
+
+	// Wrong key
+	_, err = Open("OTHER-KEY", sealed)
+	require.NotNil(t, err)
+
+	// Plaintext values are not accepted
+	_, err = Open("KEY", "JBSWY3DPEHPK3PXP")
+	require.NotNil(t, err)
+
+	_, err = Open("KEY", "")
+	require.NotNil(t, err)
+
+	// Empty keys are not allowed
+	_, err = Seal("", "SECRET")
+	require.NotNil(t, err)
+}
@@ -0,0 +1,134 @@
+// Package totp implements Time-based One-Time Passwords (RFC 6238) that are
+// compatible with common authenticator apps.  Codes are six digits long, use
+// HMAC-SHA1, and change every 30 seconds.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // nolint:gosec // RFC 6238 and authenticator apps require SHA-1
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/EmissarySocial/emissary/tools/random"
+	"github.com/benpate/derp"
+)
+
+// Period is the number of seconds that each code is valid
+const Period = 30
+
+// Digits is the number of digits in each code
+const Digits = 6
+
+// Skew is the number of periods before/after the current time that are also accepted
+const Skew = 1
+
+// SecretSize is the number of random bytes in a new secret
+const SecretSize = 20
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns a new, randomly generated, base32 encoded secret
+func NewSecret() (string, error) {
+
+	secret, err := random.GenerateBytes(SecretSize)
+
+	if err != nil {
+		return "", derp.Wrap(err, "totp.NewSecret", "Error generating secret")
+	}
+
+	return encoding.EncodeToString(secret), nil
+}
+
+// Step returns the time step that contains the provided time
+func Step(now time.Time) int64 {
+	return now.Unix() / Period
+}
+
+// Code returns the code for a secret at the provided time step
+func Code(secret string, step int64) (string, error) {
+
+	key, err := decodeSecret(secret)
+
+	if err != nil {
+		return "", derp.Wrap(err, "totp.Code", "Invalid secret")
+	}
+
+	return code(key, step), nil
+}
+
+// Validate checks a code against the secret at the current time.  It returns
+// the matching time step, which callers should store and pass back as
+// "lastStep" so that each code can only be used once.
+func Validate(secret string, value string, now time.Time, lastStep int64) (int64, bool) {
+
+	key, err := decodeSecret(secret)
+
+	if err != nil {
+		return 0, false
+	}
+
+	value = strings.ReplaceAll(value, " ", "")
+
+	if len(value) != Digits {
+		return 0, false
+	}
+
+	current := Step(now)
+
+	for step := current - Skew; step <= current+Skew; step++ {
+
+		// RULE: Codes cannot be reused
+		if step <= lastStep {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(code(key, step)), []byte(value)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// URI returns an "otpauth://" URI that authenticator apps can read from a QR code
+func URI(issuer string, account string, secret string) string {
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(Period))
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// code calculates the HOTP value (RFC 4226) for a key and counter
+func code(key []byte, counter int64) string {
+
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+// decodeSecret converts a base32 secret into bytes, ignoring spaces and case
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+	return encoding.DecodeString(secret)
+}
@@ -0,0 +1,80 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA-1 test key from RFC 6238, Appendix B
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238(t *testing.T) {
+
+	// Last six digits of the RFC 6238 test vectors
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		require.Nil(t, err)
+		require.Equal(t, expected, code, unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+
+	now := time.Unix(1111111111, 0)
+
+	// Current code is accepted
+	step, ok := Validate(rfcSecret, "050471", now, 0)
+	require.True(t, ok)
+	require.Equal(t, Step(now), step)
+
+	// Codes from adjacent periods are accepted (clock drift)
+	previous, _ := Code(rfcSecret, Step(now)-1)
+	_, ok = Validate(rfcSecret, previous, now, 0)
+	require.True(t, ok)
+
+	// Codes cannot be replayed
+	_, ok = Validate(rfcSecret, "050471", now, step)
+	require.False(t, ok)
+
+	// Codes from far away periods are rejected
+	old, _ := Code(rfcSecret, Step(now)-5)
+	_, ok = Validate(rfcSecret, old, now, 0)
+	require.False(t, ok)
+
+	// Garbage is rejected
+	_, ok = Validate(rfcSecret, "12345", now, 0)
+	require.False(t, ok)
+	_, ok = Validate("not base32!", "050471", now, 0)
+	require.False(t, ok)
+}
+
+func TestNewSecret(t *testing.T) {
+
+	secret, err := NewSecret()
+	require.Nil(t, err)
+	require.Equal(t, 32, len(secret))
+
+	other, err := NewSecret()
+	require.Nil(t, err)
+	require.NotEqual(t, secret, other)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Example Site", "alice", "ABCDEF")
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/Example%20Site:alice?"))
+	require.Contains(t, uri, "secret=ABCDEF")
+	require.Contains(t, uri, "issuer=Example+Site")
+}
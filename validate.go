@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/EmissarySocial/emissary/build"
+	"github.com/EmissarySocial/emissary/config"
+	"github.com/EmissarySocial/emissary/service"
+	"github.com/benpate/rosetta/mapof"
+	"github.com/benpate/rosetta/sliceof"
+)
+
+// validateTemplates checks every template in the provided folder (or the built-in templates,
+// if the folder is "embed") and prints all problems that it finds.  It returns the exit code
+// for the process, which is non-zero if any errors were found.
+func validateTemplates(folder string) int {
+
+	builtIn := mapof.String{"adapter": config.FolderAdapterEmbed, "location": "templates"}
+
+	targets := sliceof.Object[mapof.String]{builtIn}
+	references := sliceof.NewObject[mapof.String]()
+
+	if folder != "embed" {
+		targets = sliceof.Object[mapof.String]{{"adapter": config.FolderAdapterFile, "location": folder}}
+		references = append(references, builtIn)
+	}
+
+	// Use a Template service that is not connected to any server resources
+	translationService := service.NewTranslation()
+	templateService := service.NewTemplate(
+		service.NewFilesystem(embeddedFiles),
+		nil,
+		nil,
+		nil,
+		nil,
+		&translationService,
+		build.FuncMap(service.Icons{}, &translationService),
+		sliceof.NewObject[mapof.String](),
+	)
+
+	errorCount := 0
+	diagnostics := templateService.Validate(targets, references)
+
+	for _, diagnostic := range diagnostics {
+		fmt.Println(diagnostic.String())
+
+		if diagnostic.IsError() {
+			errorCount++
+		}
+	}
+
+	fmt.Printf("%d problem(s) found, including %d error(s)\n", len(diagnostics), errorCount)
+
+	if errorCount > 0 {
+		return 1
+	}
+
+	return 0
+}